@@ -0,0 +1,239 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// ErrDatabaseUnavailable error stabil saat koneksi ke Postgres putus/ditolak
+// Cek dengan errors.Is, handler/middleware map ke 503
+var ErrDatabaseUnavailable = errors.New("database unavailable")
+
+// IsConnectionError true jika err disebabkan koneksi DB (bukan query/constraint error)
+func IsConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrDatabaseUnavailable) {
+		return true
+	}
+
+	// Request dibatalkan / timeout dari sisi client bukan berarti DB down
+	// Dicek sebelum net.Error: context.DeadlineExceeded juga memenuhi net.Error
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// Error dari server: class 08 (connection exception), 57P01-57P03 (shutdown / belum siap)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") ||
+			pgErr.Code == "57P01" || pgErr.Code == "57P02" || pgErr.Code == "57P03"
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// ========== MONITOR (READINESS) ==========
+
+// Pinger - cukup *pgxpool.Pool
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Monitor menyimpan status readiness DB
+// Di-set down oleh guard saat ada connection error, di-set up lagi oleh health check periodik
+type Monitor struct {
+	pinger Pinger
+	log    *zap.Logger
+	down   atomic.Bool
+}
+
+func NewMonitor(pinger Pinger, log *zap.Logger) *Monitor {
+	return &Monitor{pinger: pinger, log: log}
+}
+
+// Ready false selama DB dianggap tidak tersedia
+func (m *Monitor) Ready() bool {
+	return !m.down.Load()
+}
+
+func (m *Monitor) markDown(err error) {
+	if !m.down.Swap(true) {
+		m.log.Error("Database connection lost", zap.Error(err))
+	}
+}
+
+func (m *Monitor) markUp() {
+	if m.down.Swap(false) {
+		m.log.Info("Database connection restored")
+	}
+}
+
+// Check ping sekali dan update status readiness
+func (m *Monitor) Check(ctx context.Context) error {
+	pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if err := m.pinger.Ping(pingCtx); err != nil {
+		m.markDown(err)
+		return fmt.Errorf("%w: %v", ErrDatabaseUnavailable, err)
+	}
+
+	m.markUp()
+	return nil
+}
+
+// Run health check periodik sampai ctx selesai (jalankan di goroutine)
+// Pool pgx reconnect sendiri, Run hanya memantau kapan DB bisa dipakai lagi
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check(ctx)
+		}
+	}
+}
+
+// ========== REQUEST TRACKING ==========
+
+type connLostKey struct{}
+
+// TrackConnectionLoss pasang penanda di context request
+// Guard men-set penanda ini jika query request tersebut kena connection error
+func TrackConnectionLoss(ctx context.Context) context.Context {
+	return context.WithValue(ctx, connLostKey{}, new(atomic.Bool))
+}
+
+// ConnectionLost true jika request ini pernah kena connection error
+func ConnectionLost(ctx context.Context) bool {
+	flag, ok := ctx.Value(connLostKey{}).(*atomic.Bool)
+	return ok && flag.Load()
+}
+
+// ========== GUARD (PgxIface WRAPPER) ==========
+
+// availabilityGuard membungkus PgxIface, connection error diubah ke ErrDatabaseUnavailable
+// Error lain (no rows, constraint, dll) dikembalikan apa adanya
+type availabilityGuard struct {
+	db      PgxIface
+	monitor *Monitor
+}
+
+func NewAvailabilityGuard(db PgxIface, monitor *Monitor) PgxIface {
+	return &availabilityGuard{db: db, monitor: monitor}
+}
+
+func (g *availabilityGuard) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := g.db.Query(ctx, sql, args...)
+	return rows, g.check(ctx, err)
+}
+
+func (g *availabilityGuard) QueryRow(ctx context.Context, query string, args ...any) pgx.Row {
+	return &guardedRow{row: g.db.QueryRow(ctx, query, args...), guard: g, ctx: ctx}
+}
+
+func (g *availabilityGuard) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	tag, err := g.db.Exec(ctx, query, args...)
+	return tag, g.check(ctx, err)
+}
+
+// Begin mengembalikan transaction yang juga dibungkus, query di dalamnya dapat cek yang sama
+func (g *availabilityGuard) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := g.db.Begin(ctx)
+	if err != nil {
+		return nil, g.check(ctx, err)
+	}
+	return &guardedTx{Tx: tx, guard: g}, nil
+}
+
+func (g *availabilityGuard) check(ctx context.Context, err error) error {
+	if !IsConnectionError(err) {
+		return err
+	}
+
+	g.monitor.markDown(err)
+	if flag, ok := ctx.Value(connLostKey{}).(*atomic.Bool); ok {
+		flag.Store(true)
+	}
+
+	if errors.Is(err, ErrDatabaseUnavailable) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrDatabaseUnavailable, err)
+}
+
+// guardedRow - error QueryRow baru muncul saat Scan
+type guardedRow struct {
+	row   pgx.Row
+	guard *availabilityGuard
+	ctx   context.Context
+}
+
+func (r *guardedRow) Scan(dest ...any) error {
+	return r.guard.check(r.ctx, r.row.Scan(dest...))
+}
+
+// guardedTx - pgx.Tx dengan cek connection error di setiap query, commit & rollback
+type guardedTx struct {
+	pgx.Tx
+	guard *availabilityGuard
+}
+
+func (t *guardedTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := t.Tx.Query(ctx, sql, args...)
+	return rows, t.guard.check(ctx, err)
+}
+
+func (t *guardedTx) QueryRow(ctx context.Context, query string, args ...any) pgx.Row {
+	return &guardedRow{row: t.Tx.QueryRow(ctx, query, args...), guard: t.guard, ctx: ctx}
+}
+
+func (t *guardedTx) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	tag, err := t.Tx.Exec(ctx, query, args...)
+	return tag, t.guard.check(ctx, err)
+}
+
+// Begin di dalam transaction = savepoint, tetap dibungkus
+func (t *guardedTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := t.Tx.Begin(ctx)
+	if err != nil {
+		return nil, t.guard.check(ctx, err)
+	}
+	return &guardedTx{Tx: tx, guard: t.guard}, nil
+}
+
+func (t *guardedTx) Commit(ctx context.Context) error {
+	return t.guard.check(ctx, t.Tx.Commit(ctx))
+}
+
+func (t *guardedTx) Rollback(ctx context.Context) error {
+	return t.guard.check(ctx, t.Tx.Rollback(ctx))
+}
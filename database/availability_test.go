@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "no rows", err: pgx.ErrNoRows, want: false},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, want: false},
+		{name: "connection exception class", err: &pgconn.PgError{Code: "08006"}, want: true},
+		{name: "admin shutdown", err: &pgconn.PgError{Code: "57P01"}, want: true},
+		{name: "query canceled", err: &pgconn.PgError{Code: "57014"}, want: false},
+		{name: "unexpected eof wrapped", err: fmt.Errorf("scan: %w", io.ErrUnexpectedEOF), want: true},
+		{name: "connection refused", err: syscall.ECONNREFUSED, want: true},
+		{name: "already unavailable", err: fmt.Errorf("x: %w", ErrDatabaseUnavailable), want: true},
+		{name: "plain error", err: errors.New("boom"), want: false},
+		{name: "request canceled", err: context.Canceled, want: false},
+		{name: "request deadline", err: context.DeadlineExceeded, want: false},
+		{name: "deadline wrapped by query", err: fmt.Errorf("query failed: %w", context.DeadlineExceeded), want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsConnectionError(tt.err); got != tt.want {
+			t.Errorf("%s: IsConnectionError() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+type stubPinger struct {
+	err error
+}
+
+func (p *stubPinger) Ping(ctx context.Context) error {
+	return p.err
+}
+
+// execDB - Exec return err, method lain tidak dipakai
+type execDB struct {
+	PgxIface
+	err error
+}
+
+func (d execDB) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, d.err
+}
+
+func TestAvailabilityGuard(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		wantUnavailable bool
+		wantReady       bool
+		wantLost        bool
+	}{
+		{name: "success", err: nil, wantReady: true},
+		{name: "query error passes through", err: &pgconn.PgError{Code: "23505"}, wantReady: true},
+		{name: "connection error wrapped", err: io.EOF, wantUnavailable: true, wantLost: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := NewMonitor(&stubPinger{}, zap.NewNop())
+			guard := NewAvailabilityGuard(execDB{err: tt.err}, monitor)
+			ctx := TrackConnectionLoss(context.Background())
+
+			_, err := guard.Exec(ctx, "UPDATE x SET y = 1")
+			if got := errors.Is(err, ErrDatabaseUnavailable); got != tt.wantUnavailable {
+				t.Errorf("errors.Is(ErrDatabaseUnavailable) = %v, want %v (err %v)", got, tt.wantUnavailable, err)
+			}
+			if tt.err != nil && !errors.Is(err, ErrDatabaseUnavailable) && err != tt.err {
+				t.Errorf("error = %v, want original %v", err, tt.err)
+			}
+			if monitor.Ready() != tt.wantReady {
+				t.Errorf("Ready() = %v, want %v", monitor.Ready(), tt.wantReady)
+			}
+			if ConnectionLost(ctx) != tt.wantLost {
+				t.Errorf("ConnectionLost() = %v, want %v", ConnectionLost(ctx), tt.wantLost)
+			}
+		})
+	}
+}
+
+// txDB - Begin return stubTx yang semua operasinya gagal dengan err
+type txDB struct {
+	PgxIface
+	err error
+}
+
+func (d txDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return stubTx{err: d.err}, nil
+}
+
+type stubTx struct {
+	pgx.Tx
+	err error
+}
+
+func (t stubTx) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, t.err
+}
+
+func (t stubTx) QueryRow(ctx context.Context, query string, args ...any) pgx.Row {
+	return stubRow{err: t.err}
+}
+
+func (t stubTx) Commit(ctx context.Context) error { return t.err }
+
+type stubRow struct {
+	err error
+}
+
+func (r stubRow) Scan(dest ...any) error { return r.err }
+
+func TestAvailabilityGuardTransaction(t *testing.T) {
+	operations := map[string]func(ctx context.Context, tx pgx.Tx) error{
+		"exec": func(ctx context.Context, tx pgx.Tx) error {
+			_, err := tx.Exec(ctx, "UPDATE x SET y = 1")
+			return err
+		},
+		"query row": func(ctx context.Context, tx pgx.Tx) error {
+			var v int
+			return tx.QueryRow(ctx, "SELECT 1").Scan(&v)
+		},
+		"commit": func(ctx context.Context, tx pgx.Tx) error {
+			return tx.Commit(ctx)
+		},
+	}
+
+	tests := []struct {
+		name            string
+		err             error
+		wantUnavailable bool
+	}{
+		{name: "connection lost mid transaction", err: io.ErrUnexpectedEOF, wantUnavailable: true},
+		{name: "query error passes through", err: &pgconn.PgError{Code: "23505"}},
+		{name: "request canceled passes through", err: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		for op, run := range operations {
+			t.Run(tt.name+"/"+op, func(t *testing.T) {
+				monitor := NewMonitor(&stubPinger{}, zap.NewNop())
+				guard := NewAvailabilityGuard(txDB{err: tt.err}, monitor)
+				ctx := TrackConnectionLoss(context.Background())
+
+				tx, err := guard.Begin(ctx)
+				if err != nil {
+					t.Fatalf("Begin() error = %v", err)
+				}
+
+				err = run(ctx, tx)
+				if got := errors.Is(err, ErrDatabaseUnavailable); got != tt.wantUnavailable {
+					t.Errorf("errors.Is(ErrDatabaseUnavailable) = %v, want %v (err %v)", got, tt.wantUnavailable, err)
+				}
+				if !errors.Is(err, tt.err) && !tt.wantUnavailable {
+					t.Errorf("error = %v, want original %v", err, tt.err)
+				}
+				if monitor.Ready() == tt.wantUnavailable {
+					t.Errorf("Ready() = %v, want %v", monitor.Ready(), !tt.wantUnavailable)
+				}
+				if ConnectionLost(ctx) != tt.wantUnavailable {
+					t.Errorf("ConnectionLost() = %v, want %v", ConnectionLost(ctx), tt.wantUnavailable)
+				}
+			})
+		}
+	}
+}
+
+func TestMonitorCheck(t *testing.T) {
+	pinger := &stubPinger{err: io.EOF}
+	monitor := NewMonitor(pinger, zap.NewNop())
+
+	if err := monitor.Check(context.Background()); !errors.Is(err, ErrDatabaseUnavailable) {
+		t.Fatalf("Check() error = %v, want ErrDatabaseUnavailable", err)
+	}
+	if monitor.Ready() {
+		t.Error("Ready() = true after failed ping")
+	}
+
+	// Pool reconnect, health check berikutnya menandai DB siap lagi
+	pinger.err = nil
+	if err := monitor.Check(context.Background()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !monitor.Ready() {
+		t.Error("Ready() = false after successful ping")
+	}
+}
+
+func TestConnectionLostWithoutTracking(t *testing.T) {
+	if ConnectionLost(context.Background()) {
+		t.Error("ConnectionLost() = true for untracked context")
+	}
+}
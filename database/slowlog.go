@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"inventory-system/utils"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// slowQueryLogger membungkus PgxIface dan log query yang lebih lama dari threshold
+// Yang di-log hanya SQL (parameterized), value argumen tidak ikut di-log
+// Log pakai logger dari context supaya request_id ikut tercatat
+type slowQueryLogger struct {
+	db        PgxIface
+	threshold time.Duration
+}
+
+// NewSlowQueryLogger - threshold <= 0 = nonaktif (db dikembalikan apa adanya)
+func NewSlowQueryLogger(db PgxIface, threshold time.Duration) PgxIface {
+	if threshold <= 0 {
+		return db
+	}
+	return &slowQueryLogger{db: db, threshold: threshold}
+}
+
+// Query hanya mengukur sampai rows siap dibaca, iterasi rows tidak termasuk
+func (s *slowQueryLogger) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	start := time.Now()
+	rows, err := s.db.Query(ctx, sql, args...)
+	s.observe(ctx, "query", sql, time.Since(start))
+	return rows, err
+}
+
+func (s *slowQueryLogger) QueryRow(ctx context.Context, query string, args ...any) pgx.Row {
+	start := time.Now()
+	row := s.db.QueryRow(ctx, query, args...)
+	s.observe(ctx, "query_row", query, time.Since(start))
+	return row
+}
+
+func (s *slowQueryLogger) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	start := time.Now()
+	tag, err := s.db.Exec(ctx, query, args...)
+	s.observe(ctx, "exec", query, time.Since(start))
+	return tag, err
+}
+
+// Begin transaction dibungkus supaya query, commit & rollback di dalamnya ikut diukur
+func (s *slowQueryLogger) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &slowTx{Tx: tx, logger: s}, nil
+}
+
+func (s *slowQueryLogger) observe(ctx context.Context, op, sql string, elapsed time.Duration) {
+	if elapsed < s.threshold {
+		return
+	}
+
+	utils.LoggerFromContext(ctx).Warn("Slow query",
+		zap.String("op", op),
+		zap.String("sql", strings.Join(strings.Fields(sql), " ")), // rapikan whitespace multi-line
+		zap.Duration("duration", elapsed),
+		zap.Duration("threshold", s.threshold),
+	)
+}
+
+// slowTx - pgx.Tx yang mengukur setiap query, commit & rollback lewat observe
+type slowTx struct {
+	pgx.Tx
+	logger *slowQueryLogger
+}
+
+func (t *slowTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	start := time.Now()
+	rows, err := t.Tx.Query(ctx, sql, args...)
+	t.logger.observe(ctx, "tx_query", sql, time.Since(start))
+	return rows, err
+}
+
+func (t *slowTx) QueryRow(ctx context.Context, query string, args ...any) pgx.Row {
+	start := time.Now()
+	row := t.Tx.QueryRow(ctx, query, args...)
+	t.logger.observe(ctx, "tx_query_row", query, time.Since(start))
+	return row
+}
+
+func (t *slowTx) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	start := time.Now()
+	tag, err := t.Tx.Exec(ctx, query, args...)
+	t.logger.observe(ctx, "tx_exec", query, time.Since(start))
+	return tag, err
+}
+
+// Begin di dalam transaction = savepoint, tetap dibungkus
+func (t *slowTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := t.Tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &slowTx{Tx: tx, logger: t.logger}, nil
+}
+
+func (t *slowTx) Commit(ctx context.Context) error {
+	start := time.Now()
+	err := t.Tx.Commit(ctx)
+	t.logger.observe(ctx, "commit", "COMMIT", time.Since(start))
+	return err
+}
+
+func (t *slowTx) Rollback(ctx context.Context) error {
+	start := time.Now()
+	err := t.Tx.Rollback(ctx)
+	t.logger.observe(ctx, "rollback", "ROLLBACK", time.Since(start))
+	return err
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"inventory-system/utils"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// sleepDB - Exec tidur selama delay untuk simulasi query lambat
+type sleepDB struct {
+	PgxIface
+	delay time.Duration
+}
+
+func (d sleepDB) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	time.Sleep(d.delay)
+	return pgconn.CommandTag{}, nil
+}
+
+func (d sleepDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return sleepTx{delay: d.delay}, nil
+}
+
+// sleepTx - Exec & Commit di dalam transaction tidur selama delay
+type sleepTx struct {
+	pgx.Tx
+	delay time.Duration
+}
+
+func (tx sleepTx) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	time.Sleep(tx.delay)
+	return pgconn.CommandTag{}, nil
+}
+
+func (tx sleepTx) Commit(ctx context.Context) error {
+	time.Sleep(tx.delay)
+	return nil
+}
+
+func TestNewSlowQueryLoggerDisabled(t *testing.T) {
+	db := sleepDB{}
+	if got := NewSlowQueryLogger(db, 0); got != PgxIface(db) {
+		t.Error("threshold 0 should return db unwrapped")
+	}
+}
+
+func TestSlowQueryLogger(t *testing.T) {
+	tests := []struct {
+		name     string
+		delay    time.Duration
+		wantLogs int
+	}{
+		{name: "fast query not logged", delay: 0, wantLogs: 0},
+		{name: "slow query logged", delay: 20 * time.Millisecond, wantLogs: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.WarnLevel)
+			db := NewSlowQueryLogger(sleepDB{delay: tt.delay}, 10*time.Millisecond)
+			// Logger request-scoped seperti yang dipasang middleware.Logger
+			ctx := utils.WithLogger(context.Background(), zap.New(core).With(zap.String("request_id", "req-42")))
+
+			db.Exec(ctx, "UPDATE products\n\t\tSET stock_quantity = $1\n\t\tWHERE id = $2", 5, "secret-arg")
+
+			if logs.Len() != tt.wantLogs {
+				t.Fatalf("log entries = %d, want %d", logs.Len(), tt.wantLogs)
+			}
+			if tt.wantLogs == 0 {
+				return
+			}
+
+			fields := logs.All()[0].ContextMap()
+			if fields["sql"] != "UPDATE products SET stock_quantity = $1 WHERE id = $2" {
+				t.Errorf("sql = %q", fields["sql"])
+			}
+			if fields["request_id"] != "req-42" {
+				t.Errorf("request_id = %q, want req-42", fields["request_id"])
+			}
+			if fields["op"] != "exec" {
+				t.Errorf("op = %q, want exec", fields["op"])
+			}
+			for _, value := range fields {
+				if value == "secret-arg" {
+					t.Error("query arguments must not be logged")
+				}
+			}
+		})
+	}
+}
+
+func TestSlowQueryLoggerInsideTransaction(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	db := NewSlowQueryLogger(sleepDB{delay: 20 * time.Millisecond}, 10*time.Millisecond)
+	ctx := utils.WithLogger(context.Background(), zap.New(core))
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tx.Exec(ctx, "UPDATE products SET stock_quantity = $1 WHERE id = $2", 5, "secret-arg")
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("log entries = %d, want exec and commit", len(entries))
+	}
+	if op := entries[0].ContextMap()["op"]; op != "tx_exec" {
+		t.Errorf("op = %q, want tx_exec", op)
+	}
+	if op := entries[1].ContextMap()["op"]; op != "commit" {
+		t.Errorf("op = %q, want commit", op)
+	}
+}
@@ -0,0 +1,39 @@
+package alert
+
+import "time"
+
+// AlertLocation - rak & warehouse produk, null jika rak/warehouse sudah dihapus
+type AlertLocation struct {
+	ShelfID       *string `json:"shelf_id"`
+	ShelfCode     *string `json:"shelf_code"`
+	ShelfName     *string `json:"shelf_name"`
+	WarehouseID   *string `json:"warehouse_id"`
+	WarehouseCode *string `json:"warehouse_code"`
+	WarehouseName *string `json:"warehouse_name"`
+}
+
+// InventoryAlertItem - satu produk di digest
+type InventoryAlertItem struct {
+	ProductID     string        `json:"product_id"`
+	ProductName   string        `json:"product_name"`
+	SKU           string        `json:"sku,omitempty"`
+	CategoryID    string        `json:"category_id"`
+	CategoryName  *string       `json:"category_name"` // null jika category sudah dihapus
+	CurrentStock  int           `json:"current_stock"`
+	MinStockLevel int           `json:"min_stock_level"`
+	Deficit       int           `json:"deficit"` // min_stock_level - current_stock
+	Location      AlertLocation `json:"location"`
+}
+
+// AlertSection - satu kelompok alert, item urut paling parah dulu
+type AlertSection struct {
+	Count int                  `json:"count"`
+	Items []InventoryAlertItem `json:"items"`
+}
+
+// InventoryDigestResponse - digest harian low stock & out of stock
+type InventoryDigestResponse struct {
+	GeneratedAt time.Time    `json:"generated_at"`
+	LowStock    AlertSection `json:"low_stock"`
+	OutOfStock  AlertSection `json:"out_of_stock"`
+}
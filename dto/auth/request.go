@@ -3,6 +3,10 @@ package auth
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
+
+	// Metadata device, diisi handler dari request (bukan dari body)
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 type LogoutRequest struct {
@@ -17,6 +17,29 @@ type UserInfo struct {
 	IsActive bool   `json:"is_active"`
 }
 
+// ValidateTokenResponse - hasil cek token (GET /api/auth/validate)
+type ValidateTokenResponse struct {
+	Valid     bool      `json:"valid"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      UserInfo  `json:"user"`
+}
+
 type LogoutResponse struct {
 	Message string `json:"message"`
 }
+
+// ActiveSessionCount - user dengan session aktif di atas threshold
+type ActiveSessionCount struct {
+	UserID        string    `json:"user_id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	ActiveCount   int       `json:"active_count"`
+	LastCreatedAt time.Time `json:"last_created_at"`
+}
+
+// ActiveSessionCountsResponse - GET /api/admin/sessions/active-counts
+type ActiveSessionCountsResponse struct {
+	Threshold int                  `json:"threshold"` // hanya user dengan active_count > threshold
+	Users     []ActiveSessionCount `json:"users"`
+}
@@ -1,11 +1,18 @@
 package category
 
 type CreateCategoryRequest struct {
-	Name        string `json:"name" validate:"required,min=3,max=100"`
-	Description string `json:"description" validate:"max=500"`
+	ParentID    *string `json:"parent_id,omitempty" validate:"omitempty,uuid4"`
+	Name        string  `json:"name" validate:"required,min=3,max=100"`
+	Description string  `json:"description" validate:"max=500"`
 }
 
 type UpdateCategoryRequest struct {
+	ParentID    *string `json:"parent_id,omitempty"` // "" = jadikan root, dicek format & cycle di service
 	Name        *string `json:"name,omitempty" validate:"omitempty,min=3,max=100"`
 	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
 }
+
+// MergeCategoryRequest - source category ({id} di URL) digabung ke category "into"
+type MergeCategoryRequest struct {
+	Into string `json:"into" validate:"required,uuid4"`
+}
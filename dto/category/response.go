@@ -3,9 +3,43 @@ package category
 import "time"
 
 type CategoryResponse struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string     `json:"id"`
+	ParentID    *string    `json:"parent_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// MergeCategoryResponse - target category setelah merge
+type MergeCategoryResponse struct {
+	Category      CategoryResponse `json:"category"`
+	ProductCount  int              `json:"product_count"`  // total produk aktif di target
+	MovedProducts int              `json:"moved_products"` // produk yang dipindah dari source
+}
+
+// CategoryImpactProduct - produk yang masih menunjuk ke category
+type CategoryImpactProduct struct {
+	ID            string  `json:"id"`
+	SKU           *string `json:"sku"`
+	Name          string  `json:"name"`
+	StockQuantity int     `json:"stock_quantity"`
+	Status        string  `json:"status"`
+}
+
+// CategoryImpactResponse - preview sebelum delete: produk yang perlu dipindah dulu
+type CategoryImpactResponse struct {
+	Category     CategoryResponse        `json:"category"`
+	ChildCount   int                     `json:"child_count"` // > 0 = delete ditolak (409)
+	ProductCount int                     `json:"product_count"`
+	Products     []CategoryImpactProduct `json:"products"`
+}
+
+// CategoryTreeNode - category dengan children bersarang
+type CategoryTreeNode struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Children    []CategoryTreeNode `json:"children"`
 }
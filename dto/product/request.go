@@ -2,26 +2,94 @@ package product
 
 // CreateProductRequest - untuk create product baru
 type CreateProductRequest struct {
-	CategoryID    string  `json:"category_id" validate:"required,uuid4"`
-	ShelfID       string  `json:"shelf_id" validate:"required,uuid4"`
-	Name          string  `json:"name" validate:"required,min=3,max=200"`
-	Description   string  `json:"description,omitempty" validate:"max=1000"`
-	UnitPrice     float64 `json:"unit_price" validate:"required,min=0"`
-	CostPrice     float64 `json:"cost_price" validate:"required,min=0"`
-	StockQuantity int     `json:"stock_quantity" validate:"min=0"`
-	MinStockLevel int     `json:"min_stock_level" validate:"min=0"`
+	CategoryID      string  `json:"category_id" validate:"required,uuid4"`
+	ShelfID         string  `json:"shelf_id" validate:"required,uuid4"`
+	SKU             string  `json:"sku,omitempty" validate:"omitempty,max=50,printascii"` // kosong = tanpa SKU
+	Name            string  `json:"name" validate:"required,min=3,max=200"`
+	Description     string  `json:"description,omitempty" validate:"max=1000"`
+	UnitPrice       float64 `json:"unit_price" validate:"required,min=0"`
+	CostPrice       float64 `json:"cost_price" validate:"required,min=0"`
+	StockQuantity   int     `json:"stock_quantity" validate:"min=0"`
+	MinStockLevel   int     `json:"min_stock_level" validate:"min=0"`
+	ReorderQuantity int     `json:"reorder_quantity" validate:"min=0"` // 0 = tanpa saran order
+	ImageURL        string  `json:"image_url,omitempty" validate:"omitempty,image_url,max=500"`
+	ExpiryDate      string  `json:"expiry_date,omitempty" validate:"omitempty,datetime=2006-01-02"` // kosong = tidak kedaluwarsa
 }
 
 // UpdateProductRequest - untuk update product (semua field optional)
 type UpdateProductRequest struct {
-	CategoryID    *string  `json:"category_id,omitempty" validate:"omitempty,uuid4"`
-	ShelfID       *string  `json:"shelf_id,omitempty" validate:"omitempty,uuid4"`
-	Name          *string  `json:"name,omitempty" validate:"omitempty,min=3,max=200"`
-	Description   *string  `json:"description,omitempty" validate:"omitempty,max=1000"`
-	UnitPrice     *float64 `json:"unit_price,omitempty" validate:"omitempty,min=0"`
-	CostPrice     *float64 `json:"cost_price,omitempty" validate:"omitempty,min=0"`
-	StockQuantity *int     `json:"stock_quantity,omitempty" validate:"omitempty,min=0"`
-	MinStockLevel *int     `json:"min_stock_level,omitempty" validate:"omitempty,min=0"`
+	CategoryID      *string  `json:"category_id,omitempty" validate:"omitempty,uuid4"`
+	ShelfID         *string  `json:"shelf_id,omitempty" validate:"omitempty,uuid4"`
+	SKU             *string  `json:"sku,omitempty" validate:"omitempty,max=50,printascii"` // "" = hapus SKU
+	Name            *string  `json:"name,omitempty" validate:"omitempty,min=3,max=200"`
+	Description     *string  `json:"description,omitempty" validate:"omitempty,max=1000"`
+	UnitPrice       *float64 `json:"unit_price,omitempty" validate:"omitempty,min=0"`
+	CostPrice       *float64 `json:"cost_price,omitempty" validate:"omitempty,min=0"`
+	StockQuantity   *int     `json:"stock_quantity,omitempty" validate:"omitempty,min=0"`
+	MinStockLevel   *int     `json:"min_stock_level,omitempty" validate:"omitempty,min=0"`
+	ReorderQuantity *int     `json:"reorder_quantity,omitempty" validate:"omitempty,min=0"`
+	ImageURL        *string  `json:"image_url,omitempty" validate:"omitempty,image_url,max=500"`
+	ExpiryDate      *string  `json:"expiry_date,omitempty"` // YYYY-MM-DD, "" = hapus tanggal kedaluwarsa (dicek di service)
+}
+
+// DuplicateProductRequest - untuk clone product (body optional)
+type DuplicateProductRequest struct {
+	Name *string `json:"name,omitempty" validate:"omitempty,min=3,max=200"` // default: "<nama asal> (Copy)"
+}
+
+// DiscontinueProductRequest - body optional, default discontinued = true
+type DiscontinueProductRequest struct {
+	Discontinued *bool `json:"discontinued,omitempty"` // false = aktifkan kembali
+}
+
+// BulkMinStockItem - satu produk di bulk update min stock
+type BulkMinStockItem struct {
+	ProductID     string `json:"product_id" validate:"required,uuid4"`
+	MinStockLevel *int   `json:"min_stock_level" validate:"required,min=0"`
+}
+
+// BulkMinStockRequest - body berupa array, dibungkus supaya bisa divalidasi
+type BulkMinStockRequest struct {
+	Items []BulkMinStockItem `validate:"required,min=1,max=500,dive"`
+}
+
+// StockCheckItem - satu item keranjang yang mau dicek
+type StockCheckItem struct {
+	ProductID string `json:"product_id" validate:"required,uuid4"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"`
+}
+
+// CheckStockBatchRequest - body berupa array, dibungkus supaya bisa divalidasi
+type CheckStockBatchRequest struct {
+	Items []StockCheckItem `validate:"required,min=1,max=500,dive"`
+}
+
+// BatchProductsRequest - ambil banyak produk sekaligus (batas jumlah dari config)
+type BatchProductsRequest struct {
+	IDs []string `json:"ids" validate:"required,min=1,dive,uuid4"`
+}
+
+// StockCountItem - hasil hitung fisik satu produk
+type StockCountItem struct {
+	ProductID string `json:"product_id" validate:"required,uuid4"`
+	Counted   *int   `json:"counted" validate:"required,min=0"` // pointer: 0 valid (stok habis)
+}
+
+// StockCountRequest - body berupa array, dibungkus supaya bisa divalidasi
+type StockCountRequest struct {
+	Items []StockCountItem `validate:"required,min=1,max=500,dive"`
+}
+
+// RestockItem - jumlah barang diterima untuk satu produk
+type RestockItem struct {
+	ProductID string `json:"product_id" validate:"required,uuid4"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"`
+}
+
+// RestockRequest - terima barang (purchase order/surat jalan) untuk banyak produk sekaligus
+type RestockRequest struct {
+	Reference string        `json:"reference" validate:"required,max=100"` // contoh: PO-123, dicatat di notes movement
+	Items     []RestockItem `json:"items" validate:"required,min=1,max=500,dive"`
 }
 
 // UpdateStockRequest - khusus untuk update stock quantity saja
@@ -29,3 +97,77 @@ type UpdateStockRequest struct {
 	Quantity int    `json:"quantity" validate:"required,min=0"`
 	Notes    string `json:"notes,omitempty" validate:"max=500"` // catatan kenapa update stock
 }
+
+// SetLocationStockRequest - set stok produk di satu rak (multi warehouse)
+type SetLocationStockRequest struct {
+	ShelfID  string `json:"shelf_id" validate:"required,uuid4"`
+	Quantity *int   `json:"quantity" validate:"required,min=0"` // pointer: 0 valid (rak dikosongkan)
+	Notes    string `json:"notes,omitempty" validate:"max=500"`
+}
+
+// StockTransferRequest - pindah stok produk antar rak (boleh beda warehouse)
+type StockTransferRequest struct {
+	ProductID   string `json:"product_id" validate:"required,uuid4"`
+	FromShelfID string `json:"from_shelf_id" validate:"required,uuid4"`
+	ToShelfID   string `json:"to_shelf_id" validate:"required,uuid4,nefield=FromShelfID"`
+	Quantity    int    `json:"quantity" validate:"required,min=1"`
+	Notes       string `json:"notes,omitempty" validate:"max=500"`
+}
+
+// MovementSummaryRequest - periode summary ledger stok (end_date inclusive)
+type MovementSummaryRequest struct {
+	StartDate string `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"required,datetime=2006-01-02"`
+}
+
+// MovementFeedRequest - filter opsional activity feed movement semua produk
+type MovementFeedRequest struct {
+	StartDate string `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	Type      string `json:"type" validate:"omitempty,oneof=sale restock adjustment cancellation_restore transfer"`
+}
+
+// StockAtRequest - tanggal rekonstruksi stok (posisi akhir hari tersebut)
+type StockAtRequest struct {
+	Date string `json:"date" validate:"required,datetime=2006-01-02"`
+}
+
+// CriticalStockRequest - batas stok kritis sebagai fraksi min_stock_level (0.5 = setengah min)
+type CriticalStockRequest struct {
+	Percent float64 `json:"percent" validate:"gt=0,lte=1"`
+}
+
+// StaleStockRequest - produk yang tidak di-update sejak tanggal ini (exclusive)
+type StaleStockRequest struct {
+	Before string `json:"before" validate:"required,datetime=2006-01-02"`
+}
+
+// NewlyLowStockRequest - threshold min stock baru yang mau dicoba (sama untuk semua produk)
+type NewlyLowStockRequest struct {
+	Threshold *int `json:"threshold" validate:"required,min=0"`
+}
+
+// ExpiringProductsRequest - batas tanggal kedaluwarsa (inclusive)
+type ExpiringProductsRequest struct {
+	Before string `json:"before" validate:"required,datetime=2006-01-02"`
+}
+
+// BulkStatusRequest - ubah status banyak produk sekaligus (all-or-nothing)
+type BulkStatusRequest struct {
+	ProductIDs []string `json:"product_ids" validate:"required,min=1,max=500,dive,uuid4"`
+	Status     string   `json:"status" validate:"required,oneof=active discontinued"`
+}
+
+// PriceAdjustRequest - ubah harga semua produk satu category dalam persen (negatif = turun)
+// Minimal salah satu persen diisi, kosong = harga tersebut tidak berubah
+type PriceAdjustRequest struct {
+	CategoryID       string   `json:"category_id" validate:"required,uuid4"`
+	UnitPricePercent *float64 `json:"unit_price_percent,omitempty" validate:"omitempty,gt=-100,lte=1000"`
+	CostPricePercent *float64 `json:"cost_price_percent,omitempty" validate:"omitempty,gt=-100,lte=1000"`
+}
+
+// RecategorizeProductsRequest - pindahkan banyak produk ke satu category
+type RecategorizeProductsRequest struct {
+	ProductIDs []string `json:"product_ids" validate:"required,min=1,max=500,dive,uuid4"`
+	CategoryID string   `json:"category_id" validate:"required,uuid4"`
+}
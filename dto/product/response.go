@@ -5,23 +5,57 @@ import (
 )
 
 type ProductResponse struct {
-	ID            string    `json:"id"`
-	CategoryID    string    `json:"category_id"`
-	ShelfID       string    `json:"shelf_id"`
-	Name          string    `json:"name"`
-	Description   string    `json:"description"`
-	UnitPrice     float64   `json:"unit_price"`
-	CostPrice     float64   `json:"cost_price"`
-	StockQuantity int       `json:"stock_quantity"`
-	MinStockLevel int       `json:"min_stock_level"`
-	IsLowStock    bool      `json:"is_low_stock"` // calculated field
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID              string     `json:"id"`
+	CategoryID      string     `json:"category_id"`
+	ShelfID         string     `json:"shelf_id"`
+	SKU             string     `json:"sku,omitempty"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	UnitPrice       float64    `json:"unit_price"`
+	CostPrice       float64    `json:"cost_price"`
+	Currency        string     `json:"currency"`
+	StockQuantity   int        `json:"stock_quantity"`
+	MinStockLevel   int        `json:"min_stock_level"`
+	ReorderQuantity int        `json:"reorder_quantity"`
+	ImageURL        string     `json:"image_url,omitempty"`
+	ExpiryDate      *string    `json:"expiry_date,omitempty"`
+	Status          string     `json:"status"`       // active, discontinued
+	IsLowStock      bool       `json:"is_low_stock"` // calculated field
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ProductValidationResponse - hasil dry run create, errors per field (nama field struct)
+type ProductValidationResponse struct {
+	Valid  bool              `json:"valid"`
+	Errors map[string]string `json:"errors,omitempty"`
 }
 
 type LowStockProductResponse struct {
 	ProductResponse
-	StockDeficit int `json:"stock_deficit"` // berapa kekurangan dari min_stock_level
+	StockDeficit   int `json:"stock_deficit"`   // berapa kekurangan dari min_stock_level
+	SuggestedOrder int `json:"suggested_order"` // max(0, reorder_quantity - stock_quantity)
+}
+
+// ExpiringProductResponse - produk yang kedaluwarsa sebelum tanggal tertentu
+type ExpiringProductResponse struct {
+	ProductResponse
+	DaysUntilExpiry int  `json:"days_until_expiry"` // negatif = sudah lewat
+	IsExpired       bool `json:"is_expired"`
+}
+
+// NewlyLowStockProductResponse - produk yang belum low stock tapi jadi low dengan threshold baru
+type NewlyLowStockProductResponse struct {
+	ProductResponse
+	Threshold        int `json:"threshold"`
+	ThresholdDeficit int `json:"threshold_deficit"` // threshold - stock_quantity
+}
+
+// StaleStockProductResponse - produk yang lama tidak disentuh (potensi stok mati)
+type StaleStockProductResponse struct {
+	ProductResponse
+	DaysSinceUpdate int `json:"days_since_update"`
 }
 
 type ProductListResponse struct {
@@ -31,3 +65,206 @@ type ProductListResponse struct {
 	Limit      int               `json:"limit"`
 	TotalPages int               `json:"total_pages"`
 }
+
+// RecategorizeProductsResponse - jumlah produk yang dipindah category
+type RecategorizeProductsResponse struct {
+	CategoryID string `json:"category_id"`
+	Updated    int    `json:"updated"`
+}
+
+// PriceAdjustResponse - jumlah produk yang harganya berubah (tercatat di price history)
+type PriceAdjustResponse struct {
+	CategoryID       string  `json:"category_id"`
+	UnitPricePercent float64 `json:"unit_price_percent"`
+	CostPricePercent float64 `json:"cost_price_percent"`
+	Updated          int     `json:"updated"`
+}
+
+// BulkStatusResponse - updated = status berubah, unchanged = status sudah sama
+type BulkStatusResponse struct {
+	Status    string `json:"status"`
+	Requested int    `json:"requested"`
+	Updated   int    `json:"updated"`
+	Unchanged int    `json:"unchanged"`
+}
+
+// BulkMinStockResult - hasil per item, produk tidak ditemukan = gagal (tidak membatalkan item lain)
+type BulkMinStockResult struct {
+	ProductID     string `json:"product_id"`
+	MinStockLevel int    `json:"min_stock_level"`
+	Success       bool   `json:"success"`
+	Error         string `json:"error,omitempty"`
+}
+
+type BulkMinStockResponse struct {
+	Updated int                  `json:"updated"`
+	Failed  int                  `json:"failed"`
+	Results []BulkMinStockResult `json:"results"`
+}
+
+// StockCheckResult - availability per item (read-only, tidak reserve stok)
+type StockCheckResult struct {
+	ProductID    string `json:"product_id"`
+	ProductName  string `json:"product_name,omitempty"`
+	Requested    int    `json:"requested"`
+	CurrentStock int    `json:"current_stock"`
+	Available    bool   `json:"available"`
+	Reason       string `json:"reason,omitempty"` // not_found, discontinued, insufficient_stock
+}
+
+type CheckStockBatchResponse struct {
+	AllAvailable bool               `json:"all_available"`
+	Items        []StockCheckResult `json:"items"`
+}
+
+// BatchProductsResponse - produk sesuai urutan ids di request, ID tidak ditemukan di missing_ids
+type BatchProductsResponse struct {
+	Products   []ProductResponse `json:"products"`
+	MissingIDs []string          `json:"missing_ids"`
+}
+
+// StockCountResult - selisih stok sistem vs hitung fisik (variance = counted - expected)
+type StockCountResult struct {
+	ProductID     string  `json:"product_id"`
+	ProductName   string  `json:"product_name,omitempty"`
+	Expected      int     `json:"expected"`
+	Counted       int     `json:"counted"`
+	Variance      int     `json:"variance"`
+	VarianceValue float64 `json:"variance_value"` // variance x cost_price
+	Found         bool    `json:"found"`
+	Error         string  `json:"error,omitempty"`
+}
+
+// StockCountResponse - variance report stock opname
+// Applied = stok sistem sudah di-set ke hasil hitung (movement adjustment tercatat)
+type StockCountResponse struct {
+	Currency           string             `json:"currency"`
+	Applied            bool               `json:"applied"`
+	Adjusted           int                `json:"adjusted"` // produk yang stoknya berubah karena apply
+	TotalVariance      int                `json:"total_variance"`
+	TotalVarianceValue float64            `json:"total_variance_value"`
+	Items              []StockCountResult `json:"items"`
+}
+
+// RestockResult - stok satu produk sebelum & sesudah restock
+type RestockResult struct {
+	ProductID   string `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+	OldStock    int    `json:"old_stock"`
+	NewStock    int    `json:"new_stock"`
+}
+
+// RestockResponse - hasil restock per produk, urut sesuai request
+type RestockResponse struct {
+	Reference     string          `json:"reference"`
+	TotalQuantity int             `json:"total_quantity"`
+	Items         []RestockResult `json:"items"`
+}
+
+// MovementFeedEntry - satu baris activity feed stok (quantity bertanda, + masuk / - keluar)
+type MovementFeedEntry struct {
+	ID           string    `json:"id"`
+	ProductID    string    `json:"product_id"`
+	ProductName  string    `json:"product_name"`
+	ProductSKU   *string   `json:"product_sku"`
+	MovementType string    `json:"movement_type"`
+	Quantity     int       `json:"quantity"`
+	StockAfter   int       `json:"stock_after"`
+	ReferenceID  *string   `json:"reference_id"`
+	Notes        *string   `json:"notes"`
+	UserID       *string   `json:"user_id"` // null untuk movement sebelum user dicatat
+	Username     *string   `json:"username"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// StockLocationResponse - stok produk di satu rak
+type StockLocationResponse struct {
+	ShelfID       string  `json:"shelf_id"`
+	ShelfCode     *string `json:"shelf_code"`
+	ShelfName     *string `json:"shelf_name"`
+	WarehouseID   *string `json:"warehouse_id"`
+	WarehouseCode *string `json:"warehouse_code"`
+	WarehouseName *string `json:"warehouse_name"`
+	Quantity      int     `json:"quantity"`
+	IsPrimary     bool    `json:"is_primary"` // rak utama produk (shelf_id di product)
+}
+
+// ProductStockLocationsResponse - stok per rak + total (products.stock_quantity)
+type ProductStockLocationsResponse struct {
+	ProductID   string                  `json:"product_id"`
+	ProductName string                  `json:"product_name"`
+	TotalStock  int                     `json:"total_stock"`
+	Locations   []StockLocationResponse `json:"locations"`
+}
+
+// StockTransferResponse - hasil transfer + stok per rak setelah transfer
+type StockTransferResponse struct {
+	TransferID  string                        `json:"transfer_id"` // reference_id pasangan movement transfer
+	FromShelfID string                        `json:"from_shelf_id"`
+	ToShelfID   string                        `json:"to_shelf_id"`
+	Quantity    int                           `json:"quantity"`
+	Stock       ProductStockLocationsResponse `json:"stock"`
+}
+
+// MovementTypeSummary - total movement satu jenis (quantity_out positif)
+type MovementTypeSummary struct {
+	MovementType  string `json:"movement_type"`
+	MovementCount int    `json:"movement_count"`
+	QuantityIn    int    `json:"quantity_in"`
+	QuantityOut   int    `json:"quantity_out"`
+	NetQuantity   int    `json:"net_quantity"`
+}
+
+// MovementSummaryResponse - summary ledger stok produk per jenis movement
+// Semua jenis selalu ada (0 jika tidak ada movement) supaya chart trend tidak bolong
+type MovementSummaryResponse struct {
+	ProductID   string                `json:"product_id"`
+	ProductName string                `json:"product_name"`
+	StartDate   string                `json:"start_date"`
+	EndDate     string                `json:"end_date"`
+	Movements   []MovementTypeSummary `json:"movements"`
+	TotalIn     int                   `json:"total_in"`
+	TotalOut    int                   `json:"total_out"`
+	NetChange   int                   `json:"net_change"`
+}
+
+// SKUHistoryEntry - satu perubahan SKU, null = tidak ada SKU
+type SKUHistoryEntry struct {
+	OldSKU            *string   `json:"old_sku"`
+	NewSKU            *string   `json:"new_sku"`
+	ChangedBy         *string   `json:"changed_by"`
+	ChangedByUsername *string   `json:"changed_by_username"`
+	ChangedAt         time.Time `json:"changed_at"`
+}
+
+// SKUHistoryResponse - riwayat SKU produk, terbaru dulu
+type SKUHistoryResponse struct {
+	ProductID   string            `json:"product_id"`
+	ProductName string            `json:"product_name"`
+	CurrentSKU  *string           `json:"current_sku"`
+	History     []SKUHistoryEntry `json:"history"`
+}
+
+// StockAtResponse - stok produk di akhir tanggal tertentu, dihitung mundur dari ledger
+type StockAtResponse struct {
+	ProductID    string `json:"product_id"`
+	ProductName  string `json:"product_name"`
+	Date         string `json:"date"`
+	Quantity     int    `json:"quantity"`      // stok di akhir hari date
+	CurrentStock int    `json:"current_stock"` // stok sekarang (titik awal rekonstruksi)
+}
+
+// ProductLocationResponse - breadcrumb lokasi untuk picker
+type ProductLocationResponse struct {
+	ProductID     string  `json:"product_id"`
+	ProductName   string  `json:"product_name"`
+	WarehouseID   *string `json:"warehouse_id"`
+	WarehouseCode *string `json:"warehouse_code"`
+	WarehouseName *string `json:"warehouse_name"`
+	ShelfID       *string `json:"shelf_id"`
+	ShelfCode     *string `json:"shelf_code"`
+	ShelfName     *string `json:"shelf_name"`
+	Path          string  `json:"path"`     // contoh: "Warehouse A / Shelf B3"
+	Orphaned      bool    `json:"orphaned"` // true jika shelf/warehouse sudah dihapus
+}
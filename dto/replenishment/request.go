@@ -0,0 +1,12 @@
+package replenishment
+
+type CreateReplenishmentRequest struct {
+	RequestedQuantity int    `json:"requested_quantity" validate:"required,min=1,max=1000000"`
+	Notes             string `json:"notes" validate:"max=500"`
+}
+
+// ReviewReplenishmentRequest - hanya request pending yang bisa di-review
+type ReviewReplenishmentRequest struct {
+	Status string `json:"status" validate:"required,oneof=approved rejected"`
+	Notes  string `json:"notes" validate:"max=500"`
+}
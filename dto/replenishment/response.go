@@ -0,0 +1,17 @@
+package replenishment
+
+import "time"
+
+type ReplenishmentResponse struct {
+	ID                string     `json:"id"`
+	ProductID         string     `json:"product_id"`
+	RequestedBy       string     `json:"requested_by"`
+	RequestedQuantity int        `json:"requested_quantity"`
+	Notes             *string    `json:"notes,omitempty"`
+	Status            string     `json:"status"`
+	ReviewedBy        *string    `json:"reviewed_by,omitempty"`
+	ReviewedAt        *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNotes       *string    `json:"review_notes,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
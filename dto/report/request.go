@@ -10,13 +10,77 @@ type ProductReportRequest struct {
 
 // SalesReportRequest - Get sales transaction report
 type SalesReportRequest struct {
+	StartDate  string  `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate    string  `json:"end_date" validate:"required,datetime=2006-01-02"`
+	UserID     *string `json:"user_id,omitempty" validate:"omitempty,uuid4"`     // filter per kasir
+	CategoryID *string `json:"category_id,omitempty" validate:"omitempty,uuid4"` // filter per kategori produk
+}
+
+// MySalesStatsRequest - statistik penjualan user yang login, kosong = bulan berjalan
+type MySalesStatsRequest struct {
+	StartDate string `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// SalesByCategoryRequest - Get sales aggregated per product category
+type SalesByCategoryRequest struct {
+	StartDate    string `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate      string `json:"end_date" validate:"required,datetime=2006-01-02"`
+	IncludeEmpty bool   `json:"include_empty"` // true = kategori tanpa penjualan ikut ditampilkan
+}
+
+// InventoryTurnoverRequest - Turnover ratio per produk dalam date range
+type InventoryTurnoverRequest struct {
 	StartDate string `json:"start_date" validate:"required,datetime=2006-01-02"`
 	EndDate   string `json:"end_date" validate:"required,datetime=2006-01-02"`
 }
 
-// RevenueReportRequest - Get revenue analytics report
-type RevenueReportRequest struct {
+// SalesTrendRequest - Time series penjualan per bucket (day/week/month)
+type SalesTrendRequest struct {
+	StartDate string `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"required,datetime=2006-01-02"`
+	Interval  string `json:"interval" validate:"required,oneof=day week month"`
+}
+
+// SalesByHourRequest - Penjualan per jam (0-23) dalam date range
+type SalesByHourRequest struct {
+	StartDate string `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"required,datetime=2006-01-02"`
+}
+
+// RevenueByPaymentRequest - Revenue sale completed per cara bayar dalam date range
+type RevenueByPaymentRequest struct {
 	StartDate string `json:"start_date" validate:"required,datetime=2006-01-02"`
 	EndDate   string `json:"end_date" validate:"required,datetime=2006-01-02"`
-	GroupBy   string `json:"group_by,omitempty" validate:"omitempty,oneof=day week month"`
+}
+
+// TopCustomersRequest - Customer dengan belanja terbesar dalam date range
+type TopCustomersRequest struct {
+	StartDate string `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"required,datetime=2006-01-02"`
+	Limit     int    `json:"limit" validate:"min=1,max=100"`
+}
+
+// FulfillmentTimeRequest - Durasi pending -> completed dalam date range
+type FulfillmentTimeRequest struct {
+	StartDate string `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"required,datetime=2006-01-02"`
+}
+
+// MonthlySnapshotRequest - Stok akhir bulan per produk
+type MonthlySnapshotRequest struct {
+	Month string `json:"month" validate:"required,datetime=2006-01"`
+}
+
+// RevenueCompareRequest - Compare revenue periode berjalan vs sebelumnya
+type RevenueCompareRequest struct {
+	Period string `json:"period" validate:"required,oneof=day week month"`
+}
+
+// RevenueReportRequest - Get revenue analytics report
+type RevenueReportRequest struct {
+	StartDate     string `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate       string `json:"end_date" validate:"required,datetime=2006-01-02"`
+	GroupBy       string `json:"group_by,omitempty" validate:"omitempty,oneof=day week month"`
+	PaymentStatus string `json:"payment_status,omitempty" validate:"omitempty,oneof=unpaid partial paid"` // kosong = semua
 }
@@ -5,14 +5,40 @@ import "time"
 // ========== PRODUCT REPORT ==========
 // Product inventory summary
 type ProductReportResponse struct {
-	TotalProducts      int     `json:"total_products"`     // Active products count
-	TotalValue         float64 `json:"total_value"`        // Inventory total value (cost * stock)
+	TotalProducts      int     `json:"total_products"` // Active products count
+	TotalValue         float64 `json:"total_value"`    // Inventory total value (cost * stock)
+	Currency           string  `json:"currency"`
 	TotalStock         int     `json:"total_stock"`        // Total items in stock
 	LowStockCount      int     `json:"low_stock_count"`    // Products with stock <= min_stock_level
 	OutOfStockCount    int     `json:"out_of_stock_count"` // Products with zero stock
 	AvgStockPerProduct float64 `json:"avg_stock_per_product"`
 }
 
+// Ringkasan inventory satu warehouse, field sama dengan ProductReportResponse
+type WarehouseInventoryResponse struct {
+	WarehouseID   string `json:"warehouse_id"`
+	WarehouseName string `json:"warehouse_name"`
+	ProductReportResponse
+}
+
+// ShelfDistribution - jumlah produk & stok di satu rak (untuk heatmap)
+type ShelfDistribution struct {
+	ShelfID      string `json:"shelf_id"`
+	ShelfCode    string `json:"shelf_code"`
+	ShelfName    string `json:"shelf_name"`
+	ProductCount int    `json:"product_count"`
+	TotalStock   int    `json:"total_stock"`
+}
+
+// ShelfDistributionResponse - distribusi stok per rak dalam satu warehouse
+type ShelfDistributionResponse struct {
+	WarehouseID   string              `json:"warehouse_id"`
+	WarehouseName string              `json:"warehouse_name"`
+	TotalShelves  int                 `json:"total_shelves"`
+	EmptyShelves  int                 `json:"empty_shelves"`
+	Shelves       []ShelfDistribution `json:"shelves"`
+}
+
 // ========== SALES REPORT ==========
 // Sales transaction summary (moved from sale)
 type SalesReportResponse struct {
@@ -20,10 +46,34 @@ type SalesReportResponse struct {
 	TotalRevenue   float64   `json:"total_revenue"`    // Total income from sales
 	TotalItemsSold int       `json:"total_items_sold"` // Total products sold
 	AverageSale    float64   `json:"average_sale"`     // Average per transaction
+	Currency       string    `json:"currency"`
 	StartDate      time.Time `json:"start_date"`
 	EndDate        time.Time `json:"end_date"`
 }
 
+// MySalesStatsResponse - sales report untuk satu kasir (user yang login)
+type MySalesStatsResponse struct {
+	UserID string `json:"user_id"`
+	SalesReportResponse
+}
+
+// ========== SALES BY CATEGORY ==========
+// Penjualan per kategori produk
+type CategorySales struct {
+	CategoryID   string  `json:"category_id"`
+	CategoryName string  `json:"category_name"`
+	SalesCount   int     `json:"sales_count"`   // Transaksi yang memuat produk kategori ini
+	QuantitySold int     `json:"quantity_sold"` // Total item terjual
+	Revenue      float64 `json:"revenue"`       // Total line item revenue
+}
+
+type SalesByCategoryResponse struct {
+	Currency   string          `json:"currency"`
+	StartDate  time.Time       `json:"start_date"`
+	EndDate    time.Time       `json:"end_date"`
+	Categories []CategorySales `json:"categories"`
+}
+
 // ========== REVENUE REPORT ==========
 // Revenue data for time period
 type TimePeriodRevenue struct {
@@ -35,14 +85,195 @@ type TimePeriodRevenue struct {
 
 // Detailed revenue analytics
 type RevenueReportResponse struct {
-	TotalRevenue float64   `json:"total_revenue"`
-	TotalSales   int       `json:"total_sales"`
-	AverageSale  float64   `json:"average_sale"`
-	StartDate    time.Time `json:"start_date"`
-	EndDate      time.Time `json:"end_date"`
+	TotalRevenue  float64   `json:"total_revenue"`
+	Currency      string    `json:"currency"`
+	TotalSales    int       `json:"total_sales"`
+	AverageSale   float64   `json:"average_sale"`
+	StartDate     time.Time `json:"start_date"`
+	EndDate       time.Time `json:"end_date"`
+	PaymentStatus string    `json:"payment_status,omitempty"` // filter yang dipakai, kosong = semua
 
 	// Grouped data based on request
 	DailyRevenue   []TimePeriodRevenue `json:"daily_revenue,omitempty"`   // When group_by=day
 	WeeklyRevenue  []TimePeriodRevenue `json:"weekly_revenue,omitempty"`  // When group_by=week
 	MonthlyRevenue []TimePeriodRevenue `json:"monthly_revenue,omitempty"` // When group_by=month
 }
+
+// ========== SALES TREND ==========
+// Satu bucket time series, bucket tanpa penjualan tetap ada dengan nilai 0
+type SalesTrendBucket struct {
+	Period     string  `json:"period"` // awal bucket (YYYY-MM-DD), minggu mulai Senin
+	SalesCount int     `json:"sales_count"`
+	Revenue    float64 `json:"revenue"`
+}
+
+type SalesTrendResponse struct {
+	Currency  string             `json:"currency"`
+	Interval  string             `json:"interval"`
+	StartDate time.Time          `json:"start_date"`
+	EndDate   time.Time          `json:"end_date"`
+	Buckets   []SalesTrendBucket `json:"buckets"`
+}
+
+// ========== SALES BY HOUR ==========
+// Total penjualan completed di jam tersebut (semua hari dalam range)
+type HourlySales struct {
+	Hour       int     `json:"hour"` // 0-23, jam server
+	SalesCount int     `json:"sales_count"`
+	Revenue    float64 `json:"revenue"`
+}
+
+// Selalu 24 bucket, jam tanpa penjualan bernilai 0
+type SalesByHourResponse struct {
+	Currency  string        `json:"currency"`
+	StartDate time.Time     `json:"start_date"`
+	EndDate   time.Time     `json:"end_date"`
+	PeakHour  *int          `json:"peak_hour"` // jam dengan sales_count terbanyak, null jika tidak ada penjualan
+	Hours     []HourlySales `json:"hours"`
+}
+
+// ========== REVENUE BY PAYMENT METHOD ==========
+// Jumlah & revenue sale completed per cara bayar
+type PaymentMethodRevenue struct {
+	PaymentMethod string  `json:"payment_method"`
+	SalesCount    int     `json:"sales_count"`
+	Revenue       float64 `json:"revenue"`
+}
+
+// Semua cara bayar selalu muncul, yang tidak dipakai bernilai 0
+type RevenueByPaymentResponse struct {
+	Currency     string                 `json:"currency"`
+	StartDate    time.Time              `json:"start_date"`
+	EndDate      time.Time              `json:"end_date"`
+	TotalRevenue float64                `json:"total_revenue"`
+	Methods      []PaymentMethodRevenue `json:"methods"`
+}
+
+// ========== TOP CUSTOMERS ==========
+// Satu sale completed dengan customer (bahan agregasi top customers)
+type CustomerSale struct {
+	CustomerName  *string
+	CustomerPhone *string
+	TotalAmount   float64
+	CreatedAt     time.Time
+}
+
+// Total belanja satu customer (sale completed)
+// Dikelompokkan per nomor telepon, kalau kosong per nama (case-insensitive)
+type CustomerSpend struct {
+	CustomerName  string    `json:"customer_name"`
+	CustomerPhone *string   `json:"customer_phone,omitempty"`
+	SalesCount    int       `json:"sales_count"`
+	TotalSpent    float64   `json:"total_spent"`
+	LastPurchase  time.Time `json:"last_purchase"`
+}
+
+// Urut total_spent terbesar, sale walk-in (tanpa customer) tidak dihitung
+type TopCustomersResponse struct {
+	Currency  string          `json:"currency"`
+	StartDate time.Time       `json:"start_date"`
+	EndDate   time.Time       `json:"end_date"`
+	Customers []CustomerSpend `json:"customers"`
+}
+
+// ========== FULFILLMENT TIME ==========
+// Waktu dibuat & selesai satu sale completed (bahan rata-rata & median)
+type SaleFulfillment struct {
+	CreatedAt   time.Time
+	CompletedAt time.Time
+}
+
+// Durasi created_at -> completed_at untuk sale yang pernah pending lalu completed
+// Durasi dalam detik, null jika tidak ada sale yang memenuhi
+type FulfillmentTimeResponse struct {
+	StartDate      time.Time `json:"start_date"`
+	EndDate        time.Time `json:"end_date"`
+	SalesCount     int       `json:"sales_count"`
+	AverageSeconds *float64  `json:"average_seconds"`
+	MedianSeconds  *float64  `json:"median_seconds"`
+}
+
+// ========== MONTHLY SNAPSHOT ==========
+// Stok satu produk di akhir bulan
+type SnapshotProduct struct {
+	ProductID     string `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	StockQuantity int    `json:"stock_quantity"`
+}
+
+// Source: stored (snapshot tersimpan), computed (baru dihitung & disimpan), live (bulan berjalan, tidak disimpan)
+type MonthlySnapshotResponse struct {
+	Month        string            `json:"month"`
+	Source       string            `json:"source"`
+	TotalStock   int               `json:"total_stock"`
+	ProductCount int               `json:"product_count"`
+	Products     []SnapshotProduct `json:"products"`
+}
+
+// ========== REVENUE COMPARISON ==========
+// Ringkasan revenue satu periode
+type PeriodRevenue struct {
+	StartDate   time.Time `json:"start_date"`
+	EndDate     time.Time `json:"end_date"`
+	Revenue     float64   `json:"revenue"`
+	SalesCount  int       `json:"sales_count"`
+	AverageSale float64   `json:"average_sale"`
+}
+
+// Periode berjalan vs sebelumnya, delta null jika periode sebelumnya 0
+type RevenueComparisonResponse struct {
+	Period               string        `json:"period"` // day, week, month
+	Currency             string        `json:"currency"`
+	Current              PeriodRevenue `json:"current"`
+	Previous             PeriodRevenue `json:"previous"`
+	RevenueChangePct     *float64      `json:"revenue_change_pct"`
+	SalesCountChangePct  *float64      `json:"sales_count_change_pct"`
+	AverageSaleChangePct *float64      `json:"average_sale_change_pct"`
+}
+
+// ========== INVENTORY VALUATION ==========
+// Per-product valuation row (untuk export CSV/JSON)
+type InventoryValuationRow struct {
+	ProductID     string  `json:"product_id"`
+	SKU           *string `json:"sku"` // null = produk belum punya SKU
+	ProductName   string  `json:"product_name"`
+	CategoryName  string  `json:"category_name"`
+	StockQuantity int     `json:"stock_quantity"`
+	CostPrice     float64 `json:"cost_price"`
+	UnitPrice     float64 `json:"unit_price"`
+	CostValue     float64 `json:"cost_value"`   // cost_price * stock_quantity
+	RetailValue   float64 `json:"retail_value"` // unit_price * stock_quantity
+}
+
+// ========== INVENTORY TURNOVER ==========
+// Turnover per produk: units sold / average stock dalam periode
+// Stok awal/akhir direkonstruksi dari stok sekarang + penjualan completed (restock tidak tercatat historinya)
+type ProductTurnover struct {
+	ProductID    string   `json:"product_id"`
+	ProductName  string   `json:"product_name"`
+	UnitsSold    int      `json:"units_sold"`
+	OpeningStock int      `json:"opening_stock"`
+	ClosingStock int      `json:"closing_stock"`
+	AverageStock float64  `json:"average_stock"`
+	Turnover     *float64 `json:"turnover"` // null jika average stock 0
+}
+
+type InventoryTurnoverResponse struct {
+	StartDate time.Time         `json:"start_date"`
+	EndDate   time.Time         `json:"end_date"`
+	Products  []ProductTurnover `json:"products"`
+}
+
+// ========== DASHBOARD ==========
+// Ringkasan untuk home screen dalam satu call
+// Field nominal (pointer) di-redact (null) untuk user tanpa akses revenue
+type DashboardResponse struct {
+	TotalProducts   int      `json:"total_products"`
+	LowStockCount   int      `json:"low_stock_count"`
+	OutOfStockCount int      `json:"out_of_stock_count"`
+	TodaySalesCount int      `json:"today_sales_count"`
+	TodayRevenue    *float64 `json:"today_revenue"`
+	InventoryValue  *float64 `json:"inventory_value"`
+	Currency        string   `json:"currency"`
+	Redacted        bool     `json:"redacted"`
+}
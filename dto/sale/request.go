@@ -2,16 +2,74 @@ package sale
 
 // CreateSaleRequest contains data for creating a new sale
 type CreateSaleRequest struct {
-	Items []SaleItemRequest `json:"items" validate:"required,min=1,dive"`
+	Items         []SaleItemRequest `json:"items" validate:"required,min=1,dive"`
+	PaymentMethod string            `json:"payment_method,omitempty" validate:"omitempty,oneof=cash card transfer"` // default cash
+	CustomerName  string            `json:"customer_name,omitempty" validate:"max=100"`                             // opsional, walk-in boleh kosong
+	CustomerPhone string            `json:"customer_phone,omitempty" validate:"max=30"`
 }
 
 // SaleItemRequest represents a single product in sale
 type SaleItemRequest struct {
-	ProductID string `json:"product_id" validate:"required,uuid4"`
-	Quantity  int    `json:"quantity" validate:"required,min=1"`
+	ProductID string  `json:"product_id" validate:"required,uuid4"`
+	Quantity  int     `json:"quantity" validate:"required,min=1"`
+	ShelfID   *string `json:"shelf_id,omitempty" validate:"omitempty,uuid4"` // ambil stok dari rak ini, kosong = total stok
+	Notes     string  `json:"notes,omitempty" validate:"max=500"`            // catatan kasir per baris
+}
+
+// SaleListRequest optional filters for sales listing (query params)
+type SaleListRequest struct {
+	Status    string   `json:"status" validate:"omitempty,oneof=pending completed cancelled"`
+	StartDate string   `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string   `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	MinAmount *float64 `json:"min_amount,omitempty" validate:"omitempty,min=0"`
+	MaxAmount *float64 `json:"max_amount,omitempty" validate:"omitempty,min=0"`
+}
+
+// MyDailySalesRequest date range for the caller's daily series, empty = current month
+type MyDailySalesRequest struct {
+	StartDate string `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// ProductSalesHistoryRequest filters sales history of a single product
+type ProductSalesHistoryRequest struct {
+	StartDate        string `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate          string `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	IncludeCancelled bool   `json:"include_cancelled"`
+}
+
+// SaleFullExportRequest periode export semua sale + item (end_date inclusive, max 1 tahun)
+type SaleFullExportRequest struct {
+	StartDate string `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"required,datetime=2006-01-02"`
+}
+
+// SaleStatusBreakdownRequest periode breakdown status sale (end_date inclusive)
+type SaleStatusBreakdownRequest struct {
+	StartDate string `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"required,datetime=2006-01-02"`
+}
+
+// SaleItemLedgerRequest filters the flat sale item ledger (all sales)
+type SaleItemLedgerRequest struct {
+	StartDate        string `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate          string `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	IncludeCancelled bool   `json:"include_cancelled"`
+}
+
+// ReassignSaleRequest pindahkan sale ke kasir lain (koreksi admin)
+type ReassignSaleRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid4"`
+}
+
+// UpdateSalePaymentRequest catat total yang sudah dibayar (bukan cicilan tambahan)
+// payment_status dihitung dari amount_paid: 0 = unpaid, < total = partial, = total = paid
+type UpdateSalePaymentRequest struct {
+	AmountPaid *float64 `json:"amount_paid" validate:"required,min=0"`
 }
 
 // UpdateSaleStatusRequest for changing sale status
 type UpdateSaleStatusRequest struct {
-	Status string `json:"status" validate:"required,oneof=pending completed cancelled"`
+	Status string  `json:"status" validate:"required,oneof=pending completed cancelled"`
+	Reason *string `json:"reason,omitempty" validate:"omitempty,max=500"` // required when status = cancelled
 }
@@ -6,14 +6,22 @@ import (
 
 // SaleResponse represents sale data returned to client
 type SaleResponse struct {
-	ID            string             `json:"id"`
-	InvoiceNumber string             `json:"invoice_number"`
-	UserID        string             `json:"user_id"`
-	TotalAmount   float64            `json:"total_amount"`
-	Status        string             `json:"status"`
-	CreatedAt     time.Time          `json:"created_at"`
-	UpdatedAt     time.Time          `json:"updated_at"`
-	Items         []SaleItemResponse `json:"items,omitempty"`
+	ID              string             `json:"id"`
+	InvoiceNumber   string             `json:"invoice_number"`
+	UserID          string             `json:"user_id"`
+	TotalAmount     float64            `json:"total_amount"`
+	Currency        string             `json:"currency"`
+	Status          string             `json:"status"`
+	PaymentMethod   string             `json:"payment_method"`
+	CustomerName    *string            `json:"customer_name,omitempty"`
+	CustomerPhone   *string            `json:"customer_phone,omitempty"`
+	PaymentStatus   string             `json:"payment_status"`
+	AmountPaid      float64            `json:"amount_paid"`
+	CancelledReason *string            `json:"cancelled_reason,omitempty"`
+	CancelledAt     *time.Time         `json:"cancelled_at,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	Items           []SaleItemResponse `json:"items,omitempty"`
 }
 
 // SaleItemResponse represents sale item data for response
@@ -24,9 +32,150 @@ type SaleItemResponse struct {
 	Quantity    int       `json:"quantity"`
 	UnitPrice   float64   `json:"unit_price"`
 	TotalPrice  float64   `json:"total_price"`
+	Notes       string    `json:"notes,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// DailySalesResponse one day of the series (YYYY-MM-DD)
+type DailySalesResponse struct {
+	Date       string  `json:"date"`
+	SalesCount int     `json:"sales_count"`
+	ItemsSold  int     `json:"items_sold"`
+	Revenue    float64 `json:"revenue"`
+}
+
+// MyDailySalesResponse zero-filled daily series of the logged-in user
+type MyDailySalesResponse struct {
+	UserID    string               `json:"user_id"`
+	StartDate string               `json:"start_date"`
+	EndDate   string               `json:"end_date"`
+	Currency  string               `json:"currency"`
+	Days      []DailySalesResponse `json:"days"`
+}
+
+// ProductSaleHistoryResponse represents one sale line of a product
+type ProductSaleHistoryResponse struct {
+	SaleID        string    `json:"sale_id"`
+	InvoiceNumber string    `json:"invoice_number"`
+	Status        string    `json:"status"`
+	Quantity      int       `json:"quantity"`
+	UnitPrice     float64   `json:"unit_price"` // harga saat transaksi
+	TotalPrice    float64   `json:"total_price"`
+	SoldAt        time.Time `json:"sold_at"`
+}
+
+// SaleItemLedgerResponse represents one line of the flat sale item ledger
+type SaleItemLedgerResponse struct {
+	ID            string    `json:"id"`
+	SaleID        string    `json:"sale_id"`
+	InvoiceNumber string    `json:"invoice_number"`
+	Status        string    `json:"status"`
+	ProductID     string    `json:"product_id"`
+	ProductName   string    `json:"product_name"`
+	Quantity      int       `json:"quantity"`
+	UnitPrice     float64   `json:"unit_price"` // harga saat transaksi
+	TotalPrice    float64   `json:"total_price"`
+	SoldAt        time.Time `json:"sold_at"`
+}
+
+// RecalculateSaleResponse - hasil recalculate satu sale
+type RecalculateSaleResponse struct {
+	Sale          SaleResponse `json:"sale"`
+	PreviousTotal float64      `json:"previous_total"`
+	Changed       bool         `json:"changed"`
+}
+
+// ReassignSaleResponse - hasil ganti kasir satu sale
+type ReassignSaleResponse struct {
+	Sale           SaleResponse `json:"sale"`
+	PreviousUserID string       `json:"previous_user_id"`
+}
+
+// RecalculateAllSalesResponse - hasil recalculate semua sale
+type RecalculateAllSalesResponse struct {
+	Changed int `json:"changed"`
+}
+
+// SaleExportItem - satu baris item dengan nama produk & kategori saat export
+type SaleExportItem struct {
+	ItemID       string  `json:"item_id"`
+	ProductID    string  `json:"product_id"`
+	ProductName  string  `json:"product_name"`
+	CategoryID   string  `json:"category_id"`
+	CategoryName string  `json:"category_name"`
+	Quantity     int     `json:"quantity"`
+	UnitPrice    float64 `json:"unit_price"`
+	TotalPrice   float64 `json:"total_price"`
+	Notes        string  `json:"notes,omitempty"`
+}
+
+// SaleExportResponse - snapshot sale lengkap (denormalized) untuk arsip akuntansi
+// Nama produk/kategori/kasir adalah nilai saat export, bukan saat transaksi
+type SaleExportResponse struct {
+	ExportedAt      time.Time        `json:"exported_at"`
+	SaleID          string           `json:"sale_id"`
+	InvoiceNumber   string           `json:"invoice_number"`
+	Status          string           `json:"status"`
+	PaymentMethod   string           `json:"payment_method"`
+	CustomerName    *string          `json:"customer_name,omitempty"`
+	CustomerPhone   *string          `json:"customer_phone,omitempty"`
+	PaymentStatus   string           `json:"payment_status"`
+	AmountPaid      float64          `json:"amount_paid"`
+	CancelledReason *string          `json:"cancelled_reason,omitempty"`
+	CancelledAt     *time.Time       `json:"cancelled_at,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+	CashierID       string           `json:"cashier_id"`
+	CashierUsername string           `json:"cashier_username"`
+	CashierName     string           `json:"cashier_name"`
+	Currency        string           `json:"currency"`
+	ItemCount       int              `json:"item_count"`
+	TotalQuantity   int              `json:"total_quantity"`
+	ItemsTotal      float64          `json:"items_total"`  // jumlah total_price semua item
+	TotalAmount     float64          `json:"total_amount"` // total tersimpan di sale
+	Items           []SaleExportItem `json:"items"`
+}
+
+// SaleDryRunItem hasil simulasi satu item, reason: not_found, discontinued, insufficient_stock
+type SaleDryRunItem struct {
+	ProductID      string  `json:"product_id"`
+	ProductName    string  `json:"product_name,omitempty"`
+	ShelfID        *string `json:"shelf_id,omitempty"`
+	Quantity       int     `json:"quantity"`
+	UnitPrice      float64 `json:"unit_price"`
+	TotalPrice     float64 `json:"total_price"`
+	AvailableStock int     `json:"available_stock"` // stok rak jika shelf_id diisi, selain itu total stok
+	Available      bool    `json:"available"`
+	Reason         string  `json:"reason,omitempty"`
+}
+
+// SaleDryRunResponse total yang akan terjadi jika sale dibuat sekarang (tidak ada yang disimpan)
+type SaleDryRunResponse struct {
+	Fulfillable bool             `json:"fulfillable"`
+	Currency    string           `json:"currency"`
+	TotalAmount float64          `json:"total_amount"`
+	Items       []SaleDryRunItem `json:"items"`
+}
+
+// SaleCountResponse - jumlah sale saja (badge), tanpa data sale
+type SaleCountResponse struct {
+	Count int `json:"count"`
+}
+
+// SaleStatusCountResponse jumlah & persentase sale dengan satu status
+type SaleStatusCountResponse struct {
+	Status     string  `json:"status"`
+	Count      int     `json:"count"`
+	Percentage float64 `json:"percentage"` // dari total sale periode, 0 jika tidak ada sale
+}
+
+// SaleStatusBreakdownResponse semua status selalu ada (0 jika tidak ada sale)
+type SaleStatusBreakdownResponse struct {
+	StartDate string                    `json:"start_date"`
+	EndDate   string                    `json:"end_date"`
+	Total     int                       `json:"total"`
+	Statuses  []SaleStatusCountResponse `json:"statuses"`
+}
+
 // SaleListResponse includes pagination metadata
 type SaleListResponse struct {
 	Sales      []SaleResponse `json:"sales"`
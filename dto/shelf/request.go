@@ -2,10 +2,23 @@ package shelf
 
 type CreateShelfRequest struct {
 	WarehouseID string `json:"warehouse_id" validate:"required,uuid4"`
+	Code        string `json:"code" validate:"required,min=1,max=50"`
 	Name        string `json:"name" validate:"required,min=3,max=100"`
 }
 
 type UpdateShelfRequest struct {
-	WarehouseID *string `json:"warehouse_id,omitempty" validate:"required,uuid4"`
+	WarehouseID *string `json:"warehouse_id,omitempty" validate:"omitempty,uuid4"`
+	Code        *string `json:"code,omitempty" validate:"omitempty,min=1,max=50"`
 	Name        *string `json:"name,omitempty" validate:"omitempty,min=3,max=100"`
 }
+
+// BulkShelfItem - satu rak di bulk create (warehouse dari URL)
+type BulkShelfItem struct {
+	Code string `json:"code" validate:"required,min=1,max=50"`
+	Name string `json:"name" validate:"required,min=3,max=100"`
+}
+
+// BulkCreateShelfRequest - body berupa array, dibungkus supaya bisa divalidasi
+type BulkCreateShelfRequest struct {
+	Shelves []BulkShelfItem `validate:"required,min=1,max=200,dive"`
+}
@@ -3,9 +3,12 @@ package shelf
 import "time"
 
 type ShelfResponse struct {
-	ID          string    `json:"id"`
-	WarehouseID string    `json:"warehouse_id"`
-	Name        string    `json:"name"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           string     `json:"id"`
+	WarehouseID  string     `json:"warehouse_id"`
+	Code         string     `json:"code"`
+	Name         string     `json:"name"`
+	ProductCount int        `json:"product_count"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
 }
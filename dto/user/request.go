@@ -15,3 +15,8 @@ type UpdateUserRequest struct {
 	Role     *string `json:"role,omitempty" validate:"omitempty,oneof=super_admin admin staff"`
 	IsActive *bool   `json:"is_active,omitempty"`
 }
+
+// FindByEmailRequest - admin lookup user dari email (query param)
+type FindByEmailRequest struct {
+	Email string `json:"email" validate:"required,email,max=100"`
+}
@@ -13,6 +13,28 @@ type UserResponse struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// UserExportRow - satu baris roster export (tanpa password hash)
+type UserExportRow struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	FullName  string    `json:"full_name"`
+	Role      string    `json:"role"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginHistoryEntry - satu session login user (termasuk yang sudah revoked/expired)
+type LoginHistoryEntry struct {
+	SessionID string     `json:"session_id"`
+	IPAddress *string    `json:"ip_address"` // null untuk session sebelum metadata dicatat
+	UserAgent *string    `json:"user_agent"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	IsActive  bool       `json:"is_active"` // belum revoked & belum expired
+}
+
 type UserListResponse struct {
 	Users      []UserResponse `json:"users"`
 	Total      int            `json:"total"`
@@ -0,0 +1,15 @@
+package webhook
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=500"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=sale.created sale.status_changed low_stock"`
+	Secret     string   `json:"secret" validate:"required,min=16,max=255"`
+	IsActive   *bool    `json:"is_active,omitempty"` // default true
+}
+
+type UpdateWebhookRequest struct {
+	URL        *string  `json:"url,omitempty" validate:"omitempty,url,max=500"`
+	EventTypes []string `json:"event_types,omitempty" validate:"omitempty,min=1,dive,oneof=sale.created sale.status_changed low_stock"`
+	Secret     *string  `json:"secret,omitempty" validate:"omitempty,min=16,max=255"`
+	IsActive   *bool    `json:"is_active,omitempty"`
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"inventory-system/service"
+	"inventory-system/utils"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+type AlertHandler struct {
+	service *service.Service
+	log     *zap.Logger
+}
+
+func NewAlertHandler(service *service.Service, log *zap.Logger) *AlertHandler {
+	return &AlertHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// ========== GET INVENTORY ALERT DIGEST ==========
+// GET /api/admin/alerts/inventory
+// Hanya admin & super_admin
+func (ah *AlertHandler) GetInventoryDigest(w http.ResponseWriter, r *http.Request) {
+	digest, err := ah.service.Alert.GetInventoryDigest(r.Context())
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get inventory alert digest", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, "Failed to get inventory alerts", nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Inventory alerts retrieved", digest)
+}
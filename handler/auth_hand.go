@@ -5,6 +5,7 @@ import (
 	"inventory-system/dto/auth"
 	"inventory-system/service"
 	"inventory-system/utils"
+	"net"
 	"net/http"
 	"strings"
 
@@ -43,6 +44,13 @@ func (ah *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
+	// Metadata device untuk login history (RealIP middleware sudah set RemoteAddr)
+	req.IPAddress = r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		req.IPAddress = host
+	}
+	req.UserAgent = r.UserAgent()
+
 	// 2. Call auth service untuk proses login
 	resp, err := ah.authService.Auth.Login(r.Context(), req)
 	if err != nil {
@@ -57,7 +65,7 @@ func (ah *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 3. Log success dan return response
-	ah.log.Info("User logged in", zap.String("email", req.Email))
+	utils.LoggerFromContext(r.Context()).Info("User logged in", zap.String("email", req.Email))
 	utils.ResponseSuccess(w, http.StatusOK, "Login successful", resp)
 }
 
@@ -90,6 +98,49 @@ func (ah *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 4. Return success response
-	ah.log.Info("User logged out", zap.String("token", token.String()))
+	utils.LoggerFromContext(r.Context()).Info("User logged out", zap.String("token", token.String()))
 	utils.ResponseSuccess(w, http.StatusOK, "Logout successful", nil)
 }
+
+// ========== VALIDATE TOKEN ==========
+// GET /api/auth/validate - Header: Authorization: Bearer <token>
+// Tidak lewat Auth middleware supaya response 401 tetap JSON yang konsisten
+func (ah *AuthHandler) Validate(w http.ResponseWriter, r *http.Request) {
+	// 1. Extract token dari Authorization header
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		utils.ResponseError(w, http.StatusUnauthorized, "Authentication required: Bearer token missing", map[string]bool{"valid": false})
+		return
+	}
+
+	token, err := uuid.Parse(parts[1])
+	if err != nil {
+		utils.ResponseError(w, http.StatusUnauthorized, "Invalid token format", map[string]bool{"valid": false})
+		return
+	}
+
+	// 2. Cek session (revoked/expired/user inactive = invalid)
+	result, err := ah.authService.Auth.CheckToken(r.Context(), token)
+	if err != nil {
+		utils.ResponseError(w, http.StatusUnauthorized, "Invalid or expired token", map[string]bool{"valid": false})
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Token is valid", result)
+}
+
+// ============================================
+// ACTIVE SESSION COUNTS HANDLER
+// ============================================
+// GET /api/admin/sessions/active-counts (Admin & Super Admin)
+// User dengan session aktif > SESSION_ACTIVE_ALERT_THRESHOLD
+func (ah *AuthHandler) ActiveSessionCounts(w http.ResponseWriter, r *http.Request) {
+	resp, err := ah.authService.Auth.GetActiveSessionCounts(r.Context())
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get active session counts", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve active session counts", nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Active session counts retrieved successfully", resp)
+}
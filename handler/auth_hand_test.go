@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/dto/auth"
+	"inventory-system/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type fakeAuthService struct {
+	service.AuthService
+	err      error
+	loginReq *auth.LoginRequest
+	token    uuid.UUID
+}
+
+func (f *fakeAuthService) Login(ctx context.Context, req auth.LoginRequest) (*auth.LoginResponse, error) {
+	f.loginReq = &req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &auth.LoginResponse{}, nil
+}
+
+func (f *fakeAuthService) CheckToken(ctx context.Context, token uuid.UUID) (*auth.ValidateTokenResponse, error) {
+	f.token = token
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &auth.ValidateTokenResponse{Valid: true}, nil
+}
+
+func (f *fakeAuthService) GetActiveSessionCounts(ctx context.Context) (*auth.ActiveSessionCountsResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &auth.ActiveSessionCountsResponse{Users: []auth.ActiveSessionCount{}}, nil
+}
+
+func newTestAuthHandler(svc *fakeAuthService) *AuthHandler {
+	return NewAuthHandler(&service.Service{Auth: svc}, zap.NewNop())
+}
+
+func TestAuthLoginHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", body: `{"email":"a@example.com","password":"secret123"}`, wantStatus: http.StatusOK},
+		{name: "malformed json", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "validation", body: `{}`, err: fmt.Errorf("validation failed: Email is required"), wantStatus: http.StatusBadRequest},
+		{name: "bad credentials", body: `{}`, err: fmt.Errorf("invalid credentials"), wantStatus: http.StatusUnauthorized},
+		{name: "inactive account", body: `{}`, err: fmt.Errorf("account is inactive"), wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeAuthService{err: tt.err}
+			h := newTestAuthHandler(svc)
+			r := newRequest(http.MethodPost, "/api/auth/login", tt.body, nil, nil)
+			r.RemoteAddr = "10.1.2.3:5555"
+			r.Header.Set("User-Agent", "pos-terminal/1.0")
+			w := httptest.NewRecorder()
+			h.Login(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if svc.loginReq == nil {
+				return
+			}
+			// Port dibuang, hanya host yang dicatat di login history
+			if svc.loginReq.IPAddress != "10.1.2.3" || svc.loginReq.UserAgent != "pos-terminal/1.0" {
+				t.Errorf("device metadata = %q, %q", svc.loginReq.IPAddress, svc.loginReq.UserAgent)
+			}
+		})
+	}
+}
+
+func TestAuthValidateHandler(t *testing.T) {
+	token := uuid.New()
+
+	tests := []struct {
+		name       string
+		header     string
+		err        error
+		wantStatus int
+		wantCheck  bool
+	}{
+		{name: "valid token", header: "Bearer " + token.String(), wantStatus: http.StatusOK, wantCheck: true},
+		{name: "missing header", header: "", wantStatus: http.StatusUnauthorized},
+		{name: "wrong scheme", header: "Basic " + token.String(), wantStatus: http.StatusUnauthorized},
+		{name: "not a uuid", header: "Bearer abc", wantStatus: http.StatusUnauthorized},
+		{name: "expired or revoked", header: "Bearer " + token.String(), err: fmt.Errorf("invalid or expired token"), wantStatus: http.StatusUnauthorized, wantCheck: true},
+	}
+
+	for _, tt := range tests {
+		svc := &fakeAuthService{err: tt.err}
+		h := newTestAuthHandler(svc)
+		r := newRequest(http.MethodGet, "/api/auth/validate", "", nil, nil)
+		if tt.header != "" {
+			r.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		h.Validate(w, r)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+		if checked := svc.token == token; checked != tt.wantCheck {
+			t.Errorf("%s: CheckToken called = %v, want %v", tt.name, checked, tt.wantCheck)
+		}
+	}
+}
+
+func TestAuthActiveSessionCountsHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", wantStatus: http.StatusOK},
+		{name: "repository failure", err: fmt.Errorf("failed to count active sessions"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		h := newTestAuthHandler(&fakeAuthService{err: tt.err})
+		w := httptest.NewRecorder()
+		h.ActiveSessionCounts(w, newRequest(http.MethodGet, "/", "", nil, nil))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+}
@@ -39,7 +39,7 @@ func (ch *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// call service
 	createdCategory, err := ch.service.Category.Create(r.Context(), req)
 	if err != nil {
-		ch.log.Error("Failed to create category", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to create category", zap.Error(err))
 
 		statusCode := http.StatusBadRequest
 		if strings.Contains(err.Error(), "name already exists") {
@@ -101,10 +101,16 @@ func (ch *CategoryHandler) FindAll(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Optional: ?include_deleted=true (admin only)
+	includeDeleted, ok := parseIncludeDeleted(w, r)
+	if !ok {
+		return
+	}
+
 	// Call service
-	categories, pagination, err := ch.service.Category.FindAll(r.Context(), page, limit)
+	categories, pagination, err := ch.service.Category.FindAll(r.Context(), page, limit, includeDeleted)
 	if err != nil {
-		ch.log.Error("Failed to get categories", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to get categories", zap.Error(err))
 		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve categories", nil)
 		return
 	}
@@ -136,8 +142,16 @@ func (ch *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 	// Call service
 	updatedCategory, err := ch.service.Category.Update(r.Context(), categoryID, req)
 	if err != nil {
-		ch.log.Error("Failed to update category", zap.Error(err))
-		utils.ResponseError(w, http.StatusBadRequest, "Failed to update category", nil)
+		utils.LoggerFromContext(r.Context()).Error("Failed to update category", zap.Error(err))
+
+		statusCode := http.StatusBadRequest
+		if strings.Contains(err.Error(), "not found") {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "validation failed") {
+			statusCode = http.StatusUnprocessableEntity
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to update category", err.Error())
 		return
 	}
 
@@ -153,8 +167,115 @@ func (ch *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := ch.service.Category.Delete(r.Context(), categoryID); err != nil {
-		utils.ResponseError(w, http.StatusBadRequest, "Failed to delete category", nil)
+		statusCode := http.StatusBadRequest
+		if err.Error() == "category not found" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "category has child categories" {
+			statusCode = http.StatusConflict
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to delete category", err.Error())
+		return
 	}
 
 	utils.ResponseSuccess(w, http.StatusOK, "Category deleted successfully", nil)
 }
+
+// DeleteImpact handles GET /api/admin/categories/{id}/impact - produk yang terdampak jika category dihapus
+func (ch *CategoryHandler) DeleteImpact(w http.ResponseWriter, r *http.Request) {
+	categoryIDStr := chi.URLParam(r, "id")
+	categoryID, err := uuid.Parse(categoryIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid category ID", nil)
+		return
+	}
+
+	impact, err := ch.service.Category.GetDeleteImpact(r.Context(), categoryID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "category not found" {
+			statusCode = http.StatusNotFound
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to get category impact", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Category impact retrieved", impact)
+}
+
+// Merge handles POST /api/admin/categories/{id}/merge - body: { "into": "<target_id>" }
+func (ch *CategoryHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	categoryIDStr := chi.URLParam(r, "id")
+	categoryID, err := uuid.Parse(categoryIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid category ID", nil)
+		return
+	}
+
+	var req category.MergeCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Call service
+	result, err := ch.service.Category.Merge(r.Context(), categoryID, req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to merge category", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "category has child categories" {
+			statusCode = http.StatusConflict
+		} else if strings.Contains(err.Error(), "validation failed") {
+			statusCode = http.StatusUnprocessableEntity
+		} else if strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Category merged successfully", result)
+}
+
+// FindChildren handles GET /api/categories/{id}/children - child langsung (satu level)
+func (ch *CategoryHandler) FindChildren(w http.ResponseWriter, r *http.Request) {
+	categoryIDStr := chi.URLParam(r, "id")
+	categoryID, err := uuid.Parse(categoryIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid category ID", nil)
+		return
+	}
+
+	children, err := ch.service.Category.FindChildren(r.Context(), categoryID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "category not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Child categories retrieved", children)
+}
+
+// FindTree handles GET /api/categories/tree - semua category aktif nested
+func (ch *CategoryHandler) FindTree(w http.ResponseWriter, r *http.Request) {
+	tree, err := ch.service.Category.FindTree(r.Context())
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get category tree", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve category tree", nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Category tree retrieved", tree)
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/dto/category"
+	"inventory-system/model"
+	"inventory-system/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type fakeCategoryService struct {
+	service.CategoryService
+	err   error
+	calls int
+}
+
+func (f *fakeCategoryService) Delete(ctx context.Context, id uuid.UUID) error {
+	f.calls++
+	return f.err
+}
+
+func (f *fakeCategoryService) GetDeleteImpact(ctx context.Context, id uuid.UUID) (*category.CategoryImpactResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &category.CategoryImpactResponse{Products: []category.CategoryImpactProduct{}}, nil
+}
+
+func (f *fakeCategoryService) Merge(ctx context.Context, sourceID uuid.UUID, req category.MergeCategoryRequest) (*category.MergeCategoryResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &category.MergeCategoryResponse{}, nil
+}
+
+func newTestCategoryHandler(svc *fakeCategoryService) *CategoryHandler {
+	return NewCategoryHandler(&service.Service{Category: svc}, zap.NewNop())
+}
+
+func TestCategoryDeleteHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		err        error
+		wantStatus int
+	}{
+		{name: "deleted", id: uuid.NewString(), wantStatus: http.StatusOK},
+		{name: "invalid id", id: "abc", wantStatus: http.StatusBadRequest},
+		{name: "not found", id: uuid.NewString(), err: fmt.Errorf("category not found"), wantStatus: http.StatusNotFound},
+		{name: "has children", id: uuid.NewString(), err: fmt.Errorf("category has child categories"), wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		h := newTestCategoryHandler(&fakeCategoryService{err: tt.err})
+		w := httptest.NewRecorder()
+		h.Delete(w, newRequest(http.MethodDelete, "/", "", newUser(model.RoleSuperAdmin), map[string]string{"id": tt.id}))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+}
+
+func TestCategoryDeleteImpactHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		err        error
+		wantStatus int
+	}{
+		{name: "impact", id: uuid.NewString(), wantStatus: http.StatusOK},
+		{name: "invalid id", id: "abc", wantStatus: http.StatusBadRequest},
+		{name: "not found", id: uuid.NewString(), err: fmt.Errorf("category not found"), wantStatus: http.StatusNotFound},
+		{name: "repository failure", id: uuid.NewString(), err: fmt.Errorf("failed to get category products"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		h := newTestCategoryHandler(&fakeCategoryService{err: tt.err})
+		w := httptest.NewRecorder()
+		h.DeleteImpact(w, newRequest(http.MethodGet, "/", "", newUser(model.RoleAdmin), map[string]string{"id": tt.id}))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+}
+
+func TestCategoryMergeHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		body       string
+		err        error
+		wantStatus int
+		wantCalls  int
+	}{
+		{name: "merged", id: uuid.NewString(), body: `{"into":"x"}`, wantStatus: http.StatusOK, wantCalls: 1},
+		{name: "invalid id", id: "abc", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "malformed body", id: uuid.NewString(), body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "source not found", id: uuid.NewString(), body: `{}`, err: fmt.Errorf("category not found"), wantStatus: http.StatusNotFound, wantCalls: 1},
+		{name: "target not found", id: uuid.NewString(), body: `{}`, err: fmt.Errorf("target category not found"), wantStatus: http.StatusNotFound, wantCalls: 1},
+		{name: "source has children", id: uuid.NewString(), body: `{}`, err: fmt.Errorf("category has child categories"), wantStatus: http.StatusConflict, wantCalls: 1},
+		{name: "into itself", id: uuid.NewString(), body: `{}`, err: fmt.Errorf("validation failed: cannot merge category into itself"), wantStatus: http.StatusUnprocessableEntity, wantCalls: 1},
+		{name: "invalid target id", id: uuid.NewString(), body: `{}`, err: fmt.Errorf("invalid target category ID format"), wantStatus: http.StatusBadRequest, wantCalls: 1},
+		{name: "merge failed", id: uuid.NewString(), body: `{}`, err: fmt.Errorf("failed to merge category"), wantStatus: http.StatusInternalServerError, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		svc := &fakeCategoryService{err: tt.err}
+		h := newTestCategoryHandler(svc)
+		w := httptest.NewRecorder()
+		h.Merge(w, newRequest(http.MethodPost, "/", tt.body, newUser(model.RoleAdmin), map[string]string{"id": tt.id}))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+		if svc.calls != tt.wantCalls {
+			t.Errorf("%s: service calls = %d, want %d", tt.name, svc.calls, tt.wantCalls)
+		}
+	}
+}
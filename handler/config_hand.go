@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"inventory-system/utils"
+	"net/http"
+)
+
+// ConfigHandler expose config runtime yang aman untuk client (limit, mata uang, default stok)
+// Hanya field yang dipilih eksplisit, kredensial DB / secret tidak pernah ikut
+type ConfigHandler struct {
+	payload clientConfig
+}
+
+type clientConfig struct {
+	AppName    string             `json:"app_name"`
+	Pagination clientPagination   `json:"pagination"`
+	Money      clientMoney        `json:"money"`
+	Inventory  clientInventory    `json:"inventory"`
+	Upload     clientUploadLimits `json:"upload"`
+}
+
+type clientPagination struct {
+	DefaultLimit int `json:"default_limit"`
+	MaxLimit     int `json:"max_limit"`
+}
+
+type clientMoney struct {
+	Currency      string `json:"currency"`
+	DecimalPlaces int    `json:"decimal_places"`
+}
+
+type clientInventory struct {
+	DefaultMinStockLevel int `json:"default_min_stock_level"`
+	MaxMinStockLevel     int `json:"max_min_stock_level"` // 0 = tanpa batas
+	MaxBatchIDs          int `json:"max_batch_ids"`       // batas ids di POST /api/products/batch
+}
+
+type clientUploadLimits struct {
+	MaxSizeMB int `json:"max_size_mb"`
+}
+
+// NewConfigHandler payload dirakit sekali saat startup (money config sudah di-set di main.go)
+func NewConfigHandler(config utils.Configuration) *ConfigHandler {
+	return &ConfigHandler{payload: clientConfig{
+		AppName: config.AppName,
+		Pagination: clientPagination{
+			DefaultLimit: utils.DefaultPageLimit,
+			MaxLimit:     utils.MaxPageLimit,
+		},
+		Money: clientMoney{
+			Currency:      utils.Currency(),
+			DecimalPlaces: utils.DecimalPlaces(),
+		},
+		Inventory: clientInventory{
+			DefaultMinStockLevel: config.Inventory.DefaultMinStockLevel,
+			MaxMinStockLevel:     config.Inventory.MaxMinStockLevel,
+			MaxBatchIDs:          config.Inventory.MaxBatchIDs,
+		},
+		Upload: clientUploadLimits{
+			MaxSizeMB: config.Upload.MaxSizeMB,
+		},
+	}}
+}
+
+// ========== GET CLIENT CONFIG ==========
+// GET /api/config
+func (ch *ConfigHandler) Get(w http.ResponseWriter, r *http.Request) {
+	utils.ResponseSuccess(w, http.StatusOK, "Config retrieved", ch.payload)
+}
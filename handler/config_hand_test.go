@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+	"inventory-system/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfigGetOmitsSecrets(t *testing.T) {
+	config := utils.Configuration{
+		AppName: "Inventory",
+		DB: utils.DatabaseConfig{
+			Name:     "inventory_db",
+			Username: "inventory_user",
+			Password: "db-s3cret-pass",
+			Host:     "db.internal.example",
+			Port:     "5432",
+		},
+		Security:  utils.SecurityConfig{TrustedProxies: []string{"10.1.2.3"}},
+		Inventory: utils.InventoryConfig{DefaultMinStockLevel: 5, MaxMinStockLevel: 1000, MaxBatchIDs: 100},
+		Upload:    utils.UploadConfig{Dir: "/srv/private-uploads", MaxSizeMB: 5},
+	}
+
+	w := httptest.NewRecorder()
+	NewConfigHandler(config).Get(w, httptest.NewRequest(http.MethodGet, "/api/config", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	body := w.Body.String()
+	for _, secret := range []string{"db-s3cret-pass", "inventory_user", "inventory_db", "db.internal.example", "10.1.2.3", "/srv/private-uploads"} {
+		if strings.Contains(body, secret) {
+			t.Errorf("payload leaks %q: %s", secret, body)
+		}
+	}
+	for _, key := range []string{"password", "secret", "username", "host"} {
+		if strings.Contains(strings.ToLower(body), key) {
+			t.Errorf("payload contains %q field: %s", key, body)
+		}
+	}
+
+	var resp struct {
+		Data clientConfig `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if resp.Data.AppName != "Inventory" || resp.Data.Inventory.DefaultMinStockLevel != 5 || resp.Data.Upload.MaxSizeMB != 5 {
+		t.Errorf("payload = %+v, want client config fields", resp.Data)
+	}
+}
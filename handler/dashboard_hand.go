@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"inventory-system/service"
+	"inventory-system/utils"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+type DashboardHandler struct {
+	service *service.Service
+	log     *zap.Logger
+}
+
+func NewDashboardHandler(service *service.Service, log *zap.Logger) *DashboardHandler {
+	return &DashboardHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// ========== GET DASHBOARD SUMMARY ==========
+// GET /api/dashboard
+// Semua authenticated user bisa akses, nominal revenue hanya untuk admin
+func (dh *DashboardHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	currentUser := utils.GetUserFromContext(r.Context())
+	if currentUser == nil {
+		utils.ResponseError(w, http.StatusUnauthorized, "Authentication required", nil)
+		return
+	}
+
+	summary, err := dh.service.Dashboard.GetSummary(r.Context(), currentUser)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get dashboard summary", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, "Failed to get dashboard summary", nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Dashboard summary retrieved", summary)
+}
@@ -2,30 +2,73 @@ package handler
 
 import (
 	"inventory-system/service"
+	"inventory-system/utils"
+	"net/http"
+	"strconv"
 
 	"go.uber.org/zap"
 )
 
 type Handler struct {
-	Auth      *AuthHandler
-	User      *UserHandler
-	Warehouse *WarehouseHandler
-	Category  *CategoryHandler
-	Shelf     *ShelfHandler
-	Product   *ProductHandler
-	Sale      *SaleHandler
-	Report    *ReportHandler
+	Auth          *AuthHandler
+	User          *UserHandler
+	Warehouse     *WarehouseHandler
+	Category      *CategoryHandler
+	Shelf         *ShelfHandler
+	Product       *ProductHandler
+	Sale          *SaleHandler
+	Report        *ReportHandler
+	Dashboard     *DashboardHandler
+	Webhook       *WebhookHandler
+	Replenishment *ReplenishmentHandler
+	LogLevel      *LogLevelHandler
+	Config        *ConfigHandler
+	Role          *RoleHandler
+	Alert         *AlertHandler
 }
 
-func NewHandlers(svc *service.Service, log *zap.Logger) Handler {
+func NewHandlers(svc *service.Service, log *zap.Logger, config utils.Configuration) Handler {
 	return Handler{
-		Auth:      NewAuthHandler(svc, log),
-		User:      NewUserHandler(svc, log),
-		Warehouse: NewWarehouseHandler(svc, log),
-		Category:  NewCategoryHandler(svc, log),
-		Shelf:     NewShelfHandler(svc, log),
-		Product:   NewProductHandler(svc, log),
-		Sale:      NewSaleHandler(svc, log),
-		Report:    NewReportHandler(svc, log),
+		Auth:          NewAuthHandler(svc, log),
+		User:          NewUserHandler(svc, log),
+		Warehouse:     NewWarehouseHandler(svc, log),
+		Category:      NewCategoryHandler(svc, log),
+		Shelf:         NewShelfHandler(svc, log),
+		Product:       NewProductHandler(svc, log),
+		Sale:          NewSaleHandler(svc, log),
+		Report:        NewReportHandler(svc, log),
+		Dashboard:     NewDashboardHandler(svc, log),
+		Webhook:       NewWebhookHandler(svc, log),
+		Replenishment: NewReplenishmentHandler(svc, log),
+		LogLevel:      NewLogLevelHandler(log),
+		Config:        NewConfigHandler(config),
+		Role:          NewRoleHandler(),
+		Alert:         NewAlertHandler(svc, log),
 	}
 }
+
+// parseIncludeDeleted baca ?include_deleted=true (admin only)
+// Return ok=false jika response error sudah ditulis
+func parseIncludeDeleted(w http.ResponseWriter, r *http.Request) (includeDeleted bool, ok bool) {
+	value := r.URL.Query().Get("include_deleted")
+	if value == "" {
+		return false, true
+	}
+
+	includeDeleted, err := strconv.ParseBool(value)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid include_deleted parameter", nil)
+		return false, false
+	}
+
+	// Data terhapus hanya boleh dilihat admin/super_admin
+	if includeDeleted {
+		currentUser := utils.GetUserFromContext(r.Context())
+		if currentUser == nil || !currentUser.CanManageMasterData() {
+			utils.ResponseError(w, http.StatusForbidden, "Only admin can include deleted records", nil)
+			return false, false
+		}
+	}
+
+	return includeDeleted, true
+}
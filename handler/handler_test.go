@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+	"inventory-system/model"
+	"inventory-system/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// newRequest request dengan user di context dan URL param chi
+func newRequest(method, target, body string, user *model.User, params map[string]string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	for key, value := range params {
+		rctx.URLParams.Add(key, value)
+	}
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = utils.WithLogger(ctx, zap.NewNop())
+	if user != nil {
+		ctx = utils.SetUserToContext(ctx, user)
+	}
+	return r.WithContext(ctx)
+}
+
+func newUser(role model.UserRole) *model.User {
+	return &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: role, IsActive: true}
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
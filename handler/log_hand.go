@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"inventory-system/utils"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// LogLevelHandler ubah verbosity logger saat runtime (debug production issue)
+// Tidak lewat service karena hanya menyentuh state proses, bukan data
+type LogLevelHandler struct {
+	log *zap.Logger
+}
+
+func NewLogLevelHandler(log *zap.Logger) *LogLevelHandler {
+	return &LogLevelHandler{log: log}
+}
+
+type logLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// ========== GET LOG LEVEL ==========
+// GET /api/admin/log-level
+func (lh *LogLevelHandler) Get(w http.ResponseWriter, r *http.Request) {
+	utils.ResponseSuccess(w, http.StatusOK, "Log level retrieved", map[string]string{
+		"level": utils.CurrentLogLevel(),
+	})
+}
+
+// ========== SET LOG LEVEL ==========
+// POST /api/admin/log-level, body: { "level": "debug" }
+// Hanya berlaku untuk proses ini, kembali ke LOG_LEVEL setelah restart
+func (lh *LogLevelHandler) Set(w http.ResponseWriter, r *http.Request) {
+	var req logLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	previous := utils.CurrentLogLevel()
+	if err := utils.SetLogLevel(req.Level); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	current := utils.CurrentLogLevel()
+	utils.LoggerFromContext(r.Context()).Warn("Log level changed",
+		zap.String("from", previous),
+		zap.String("to", current),
+	)
+
+	utils.ResponseSuccess(w, http.StatusOK, "Log level updated", map[string]string{
+		"level":    current,
+		"previous": previous,
+	})
+}
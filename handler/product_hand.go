@@ -2,17 +2,24 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"inventory-system/dto/product"
+	"inventory-system/dto/sale"
 	"inventory-system/service"
 	"inventory-system/utils"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// Batas keras body multipart, batas per file (UPLOAD_MAX_SIZE_MB) dicek di service
+const maxUploadBodySize = 32 << 20
+
 type ProductHandler struct {
 	service *service.Service
 	log     *zap.Logger
@@ -39,13 +46,15 @@ func (ph *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// Call service
 	createdProduct, err := ph.service.Product.Create(r.Context(), req)
 	if err != nil {
-		ph.log.Error("Failed to create product", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to create product", zap.Error(err))
 
 		// Determine appropriate status code
 		statusCode := http.StatusBadRequest
 		if err.Error() == "category not found" || err.Error() == "shelf not found" {
 			statusCode = http.StatusNotFound
-		} else if err.Error() == "validation failed" {
+		} else if err.Error() == "product sku already exists" {
+			statusCode = http.StatusConflict
+		} else if strings.Contains(err.Error(), "validation failed") {
 			statusCode = http.StatusUnprocessableEntity
 		}
 
@@ -56,6 +65,28 @@ func (ph *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	utils.ResponseSuccess(w, http.StatusCreated, "Product created successfully", createdProduct)
 }
 
+// ========== VALIDATE PRODUCT (DRY RUN) ==========
+// POST /api/admin/products/validate - body sama dengan create, tidak ada yang disimpan
+// Payload invalid tetap 200 dengan valid=false, supaya form bisa tampilkan error per field
+func (ph *ProductHandler) Validate(w http.ResponseWriter, r *http.Request) {
+	var req product.CreateProductRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	result, err := ph.service.Product.Validate(r.Context(), req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to validate product", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, "Failed to validate product", nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Product validated", result)
+}
+
 // ========== GET PRODUCT BY ID ==========
 func (ph *ProductHandler) FindByID(w http.ResponseWriter, r *http.Request) {
 	productIDStr := chi.URLParam(r, "id")
@@ -75,6 +106,26 @@ func (ph *ProductHandler) FindByID(w http.ResponseWriter, r *http.Request) {
 	utils.ResponseSuccess(w, http.StatusOK, "Product retrieved", productData)
 }
 
+// ========== GET PRODUCT LOCATION ==========
+// GET /api/products/{id}/location - breadcrumb "Warehouse / Shelf"
+func (ph *ProductHandler) FindLocation(w http.ResponseWriter, r *http.Request) {
+	productIDStr := chi.URLParam(r, "id")
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID format", nil)
+		return
+	}
+
+	// Call service
+	location, err := ph.service.Product.FindLocation(r.Context(), productID)
+	if err != nil {
+		utils.ResponseError(w, http.StatusNotFound, "Product not found", nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Product location retrieved", location)
+}
+
 // ========== GET ALL PRODUCTS (WITH PAGINATION) ==========
 func (ph *ProductHandler) FindAll(w http.ResponseWriter, r *http.Request) {
 	// Get pagination parameters from query string
@@ -105,10 +156,16 @@ func (ph *ProductHandler) FindAll(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Optional: ?include_deleted=true (admin only)
+	includeDeleted, ok := parseIncludeDeleted(w, r)
+	if !ok {
+		return
+	}
+
 	// Call service
-	products, pagination, err := ph.service.Product.FindAll(r.Context(), page, limit)
+	products, pagination, err := ph.service.Product.FindAll(r.Context(), page, limit, includeDeleted)
 	if err != nil {
-		ph.log.Error("Failed to get products", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to get products", zap.Error(err))
 		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve products", nil)
 		return
 	}
@@ -122,29 +179,80 @@ func (ph *ProductHandler) FindAll(w http.ResponseWriter, r *http.Request) {
 	utils.ResponseSuccess(w, http.StatusOK, "Products retrieved successfully", response)
 }
 
-// ========== GET LOW STOCK PRODUCTS ==========
-func (ph *ProductHandler) FindLowStock(w http.ResponseWriter, r *http.Request) {
-	// Call service (without threshold parameter)
-	products, err := ph.service.Product.FindLowStock(r.Context())
+// ========== DUPLICATE PRODUCT ==========
+// POST /api/admin/products/{id}/duplicate - body optional: { "name": "..." }
+func (ph *ProductHandler) Duplicate(w http.ResponseWriter, r *http.Request) {
+	productIDStr := chi.URLParam(r, "id")
+	productID, err := uuid.Parse(productIDStr)
 	if err != nil {
-		ph.log.Error("Failed to get low stock products", zap.Error(err))
-		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve low stock products", nil)
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID format", nil)
 		return
 	}
 
-	utils.ResponseSuccess(w, http.StatusOK, "Low stock products retrieved", products)
+	// Body boleh kosong
+	var req product.DuplicateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Call service
+	createdProduct, err := ph.service.Product.Duplicate(r.Context(), productID, req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to duplicate product", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "product not found" || err.Error() == "shelf not found" {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "inactive warehouse") {
+			statusCode = http.StatusBadRequest
+		} else if strings.Contains(err.Error(), "validation failed") {
+			statusCode = http.StatusUnprocessableEntity
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "Product duplicated successfully", createdProduct)
 }
 
-// ========== UPDATE PRODUCT ==========
-func (ph *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
-	productIDStr := chi.URLParam(r, "id")
-	productID, err := uuid.Parse(productIDStr)
+// ========== RECATEGORIZE PRODUCTS ==========
+// POST /api/admin/products/recategorize - body: { "product_ids": [...], "category_id": "..." }
+func (ph *ProductHandler) Recategorize(w http.ResponseWriter, r *http.Request) {
+	var req product.RecategorizeProductsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Call service
+	result, err := ph.service.Product.Recategorize(r.Context(), req)
 	if err != nil {
-		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID format", nil)
+		utils.LoggerFromContext(r.Context()).Error("Failed to recategorize products", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "validation failed") {
+			statusCode = http.StatusUnprocessableEntity
+		} else if strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
 		return
 	}
 
-	var req product.UpdateProductRequest
+	utils.ResponseSuccess(w, http.StatusOK, "Products recategorized successfully", result)
+}
+
+// ========== PRICE ADJUST ==========
+// POST /api/admin/products/price-adjust, body: {"category_id": "...", "unit_price_percent": 5, "cost_price_percent": 3}
+func (ph *ProductHandler) AdjustPrices(w http.ResponseWriter, r *http.Request) {
+	var req product.PriceAdjustRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
@@ -152,67 +260,825 @@ func (ph *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	// Call service
-	updatedProduct, err := ph.service.Product.Update(r.Context(), productID, req)
+	result, err := ph.service.Product.AdjustPrices(r.Context(), req)
 	if err != nil {
-		ph.log.Error("Failed to update product", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to adjust product prices", zap.Error(err))
 
-		statusCode := http.StatusBadRequest
-		if err.Error() == "product not found" {
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
 			statusCode = http.StatusNotFound
-		} else if err.Error() == "category not found" || err.Error() == "shelf not found" {
+		} else if strings.Contains(err.Error(), "validation failed") {
+			statusCode = http.StatusUnprocessableEntity
+		} else if strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Product prices adjusted successfully", result)
+}
+
+// ========== BULK STATUS ==========
+// POST /api/admin/products/status/bulk, body: {"product_ids": [...], "status": "discontinued"}
+func (ph *ProductHandler) BulkUpdateStatus(w http.ResponseWriter, r *http.Request) {
+	var req product.BulkStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Call service
+	result, err := ph.service.Product.BulkUpdateStatus(r.Context(), req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to bulk update product status", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
 			statusCode = http.StatusNotFound
-		} else if err.Error() == "validation failed" {
+		} else if strings.Contains(err.Error(), "validation failed") {
 			statusCode = http.StatusUnprocessableEntity
+		} else if strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusBadRequest
 		}
 
 		utils.ResponseError(w, statusCode, err.Error(), nil)
 		return
 	}
 
-	utils.ResponseSuccess(w, http.StatusOK, "Product updated successfully", updatedProduct)
+	utils.ResponseSuccess(w, http.StatusOK, "Product statuses updated successfully", result)
 }
 
-// ========== UPDATE PRODUCT STOCK ========== (UNTUK STAFF)
-func (ph *ProductHandler) UpdateStock(w http.ResponseWriter, r *http.Request) {
-	productIDStr := chi.URLParam(r, "id")
-	productID, err := uuid.Parse(productIDStr)
+// ========== CHECK STOCK (BATCH) ==========
+// POST /api/products/check-stock, body: [{"product_id": "...", "quantity": 2}]
+func (ph *ProductHandler) CheckStockBatch(w http.ResponseWriter, r *http.Request) {
+	var items []product.StockCheckItem
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Call service
+	result, err := ph.service.Product.CheckStockBatch(r.Context(), product.CheckStockBatchRequest{Items: items})
 	if err != nil {
-		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID format", nil)
+		utils.LoggerFromContext(r.Context()).Error("Failed to check stock", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation failed") {
+			statusCode = http.StatusUnprocessableEntity
+		} else if strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
 		return
 	}
 
-	var req product.UpdateStockRequest
+	utils.ResponseSuccess(w, http.StatusOK, "Stock checked", result)
+}
+
+// ========== BATCH FETCH ==========
+// POST /api/products/batch, body: {"ids": ["...", "..."]}
+func (ph *ProductHandler) FindByIDs(w http.ResponseWriter, r *http.Request) {
+	var req product.BatchProductsRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 	defer r.Body.Close()
 
-	// Validate request menggunakan utils validator
-	if err := utils.ValidateStruct(req); err != nil {
-		utils.ResponseError(w, http.StatusBadRequest, "Validation failed", err.Error())
+	result, err := ph.service.Product.FindByIDs(r.Context(), req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if strings.HasPrefix(err.Error(), "too many ids") {
+			statusCode = http.StatusUnprocessableEntity
+		} else if strings.Contains(err.Error(), "validation failed") || strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusBadRequest
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to get products by ids", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Products retrieved", result)
+}
+
+// ========== STOCK COUNT (STOCK OPNAME) ==========
+// POST /api/admin/stock-count?apply=true, body: [{"product_id": "...", "counted": 10}]
+func (ph *ProductHandler) StockCount(w http.ResponseWriter, r *http.Request) {
+	// Default hanya variance report, stok tidak diubah
+	apply := false
+	if v := r.URL.Query().Get("apply"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid apply parameter", nil)
+			return
+		}
+		apply = b
+	}
+
+	var items []product.StockCountItem
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
+	defer r.Body.Close()
 
 	// Call service
-	updatedProduct, err := ph.service.Product.UpdateStock(r.Context(), productID, req)
+	result, err := ph.service.Product.StockCount(r.Context(), product.StockCountRequest{Items: items}, apply)
 	if err != nil {
-		ph.log.Error("Failed to update product stock", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to process stock count", zap.Error(err))
 
-		statusCode := http.StatusBadRequest
-		if err.Error() == "product not found" {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "shelf not found" {
 			statusCode = http.StatusNotFound
-		} else if err.Error() == "stock quantity cannot be negative" {
+		} else if strings.Contains(err.Error(), "validation failed") {
+			statusCode = http.StatusUnprocessableEntity
+		} else if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "inactive warehouse") {
 			statusCode = http.StatusBadRequest
-		} else if err.Error() == "validation failed" {
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	message := "Stock count variance calculated"
+	if apply {
+		message = "Stock count applied"
+	}
+	utils.ResponseSuccess(w, http.StatusOK, message, result)
+}
+
+// ========== RESTOCK (TERIMA BARANG) ==========
+// POST /api/admin/restock, body: {"reference": "PO-123", "items": [{"product_id": "...", "quantity": 10}]}
+func (ph *ProductHandler) Restock(w http.ResponseWriter, r *http.Request) {
+	var req product.RestockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Call service
+	result, err := ph.service.Product.Restock(r.Context(), req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to restock products", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "validation failed") {
 			statusCode = http.StatusUnprocessableEntity
+		} else if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "inactive warehouse") {
+			statusCode = http.StatusBadRequest
 		}
 
 		utils.ResponseError(w, statusCode, err.Error(), nil)
 		return
 	}
 
-	utils.ResponseSuccess(w, http.StatusOK, "Product stock updated successfully", updatedProduct)
+	utils.ResponseSuccess(w, http.StatusOK, "Products restocked successfully", result)
+}
+
+// ========== BULK UPDATE MIN STOCK ==========
+// POST /api/admin/products/min-stock/bulk, body: [{"product_id": "...", "min_stock_level": 10}]
+func (ph *ProductHandler) BulkUpdateMinStock(w http.ResponseWriter, r *http.Request) {
+	var items []product.BulkMinStockItem
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Call service
+	result, err := ph.service.Product.BulkUpdateMinStock(r.Context(), product.BulkMinStockRequest{Items: items})
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to bulk update min stock", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation failed") {
+			statusCode = http.StatusUnprocessableEntity
+		} else if strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Min stock levels updated", result)
+}
+
+// ========== UPLOAD PRODUCT IMAGE ==========
+// POST /api/admin/products/{id}/image (multipart/form-data, field "image")
+func (ph *ProductHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
+	productIDStr := chi.URLParam(r, "id")
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID format", nil)
+		return
+	}
+
+	// Batasi body supaya file besar tidak dibaca penuh, ukuran pasti dicek di service
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBodySize)
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			utils.ResponseError(w, http.StatusRequestEntityTooLarge, "Image too large", nil)
+			return
+		}
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid multipart form", nil)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("image")
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Field 'image' is required", nil)
+		return
+	}
+	defer file.Close()
+
+	// Call service
+	updatedProduct, err := ph.service.Product.UploadImage(r.Context(), productID, file, header.Size)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to upload product image", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "exceeds maximum size") {
+			statusCode = http.StatusRequestEntityTooLarge
+		} else if strings.Contains(err.Error(), "unsupported image type") {
+			statusCode = http.StatusUnsupportedMediaType
+		} else if strings.Contains(err.Error(), "validation failed") {
+			statusCode = http.StatusUnprocessableEntity
+		} else if strings.Contains(err.Error(), "not configured") {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Product image uploaded successfully", updatedProduct)
+}
+
+// ========== GET PRODUCT SALES HISTORY ==========
+// GET /api/products/{id}/sales?start_date=&end_date=&page=&limit=&include_cancelled=true
+func (ph *ProductHandler) FindSalesHistory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	productID, err := uuid.Parse(idStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID format", nil)
+		return
+	}
+
+	// Get pagination parameters from query string
+	pageStr := r.URL.Query().Get("page")
+	limitStr := r.URL.Query().Get("limit")
+
+	// Default values
+	page := 1
+	limit := 10
+
+	// Parse page parameter
+	if pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		} else {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid page parameter", nil)
+			return
+		}
+	}
+
+	// Parse limit parameter
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		} else {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid limit parameter (max 100)", nil)
+			return
+		}
+	}
+
+	// Cancelled sales excluded by default
+	includeCancelled := false
+	if v := r.URL.Query().Get("include_cancelled"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid include_cancelled parameter", nil)
+			return
+		}
+		includeCancelled = b
+	}
+
+	req := sale.ProductSalesHistoryRequest{
+		StartDate:        r.URL.Query().Get("start_date"),
+		EndDate:          r.URL.Query().Get("end_date"),
+		IncludeCancelled: includeCancelled,
+	}
+
+	history, pagination, err := ph.service.Sale.GetProductSalesHistory(r.Context(), productID, req, page, limit)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get product sales history", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "validation") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	response := map[string]interface{}{
+		"sales":      history,
+		"pagination": pagination,
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Product sales history retrieved", response)
+}
+
+// ========== GET LOW STOCK PRODUCTS ==========
+func (ph *ProductHandler) FindLowStock(w http.ResponseWriter, r *http.Request) {
+	// Call service (without threshold parameter)
+	products, err := ph.service.Product.FindLowStock(r.Context())
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get low stock products", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve low stock products", nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Low stock products retrieved", products)
+}
+
+// ========== GET CRITICAL STOCK PRODUCTS ==========
+// GET /api/products/critical-stock?percent=0.5 (default 0.5)
+func (ph *ProductHandler) FindCriticalStock(w http.ResponseWriter, r *http.Request) {
+	req := product.CriticalStockRequest{Percent: 0.5}
+	if v := r.URL.Query().Get("percent"); v != "" {
+		percent, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid percent parameter", nil)
+			return
+		}
+		req.Percent = percent
+	}
+
+	products, err := ph.service.Product.FindCriticalStock(r.Context(), req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") {
+			statusCode = http.StatusBadRequest
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to get critical stock products", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Critical stock products retrieved", products)
+}
+
+// ========== FIND EXPIRING PRODUCTS ==========
+// GET /api/products/expiring?before=2024-12-31
+func (ph *ProductHandler) FindExpiring(w http.ResponseWriter, r *http.Request) {
+	req := product.ExpiringProductsRequest{
+		Before: r.URL.Query().Get("before"),
+	}
+
+	products, err := ph.service.Product.FindExpiring(r.Context(), req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") || strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusBadRequest
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to get expiring products", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Expiring products retrieved", products)
+}
+
+// ========== FIND STALE STOCK ==========
+// GET /api/admin/products/stale?before=2024-01-01
+func (ph *ProductHandler) FindStaleStock(w http.ResponseWriter, r *http.Request) {
+	req := product.StaleStockRequest{
+		Before: r.URL.Query().Get("before"),
+	}
+
+	products, err := ph.service.Product.FindStaleStock(r.Context(), req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") || strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusBadRequest
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to get stale stock products", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Stale stock products retrieved", products)
+}
+
+// ========== FIND NEWLY LOW STOCK ==========
+// GET /api/admin/products/newly-low?threshold=20
+func (ph *ProductHandler) FindNewlyLowStock(w http.ResponseWriter, r *http.Request) {
+	var req product.NewlyLowStockRequest
+	if thresholdStr := r.URL.Query().Get("threshold"); thresholdStr != "" {
+		threshold, err := strconv.Atoi(thresholdStr)
+		if err != nil {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid threshold", nil)
+			return
+		}
+		req.Threshold = &threshold
+	}
+
+	products, err := ph.service.Product.FindNewlyLowStock(r.Context(), req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") || strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusBadRequest
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to get newly low stock products", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Newly low stock products retrieved", products)
+}
+
+// ========== UPDATE PRODUCT ==========
+func (ph *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
+	productIDStr := chi.URLParam(r, "id")
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID format", nil)
+		return
+	}
+
+	var req product.UpdateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Call service
+	updatedProduct, err := ph.service.Product.Update(r.Context(), productID, req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to update product", zap.Error(err))
+
+		statusCode := http.StatusBadRequest
+		if strings.HasPrefix(err.Error(), "permission denied") {
+			statusCode = http.StatusForbidden
+		} else if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "category not found" || err.Error() == "shelf not found" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "product sku already exists" {
+			statusCode = http.StatusConflict
+		} else if strings.Contains(err.Error(), "validation failed") {
+			statusCode = http.StatusUnprocessableEntity
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Product updated successfully", updatedProduct)
+}
+
+// ========== DISCONTINUE PRODUCT ==========
+// POST /api/admin/products/{id}/discontinue - body optional: { "discontinued": false } untuk aktifkan lagi
+func (ph *ProductHandler) Discontinue(w http.ResponseWriter, r *http.Request) {
+	productIDStr := chi.URLParam(r, "id")
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID format", nil)
+		return
+	}
+
+	// Body boleh kosong
+	var req product.DiscontinueProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Call service
+	updatedProduct, err := ph.service.Product.Discontinue(r.Context(), productID, req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to discontinue product", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Product status updated successfully", updatedProduct)
+}
+
+// ========== UPDATE PRODUCT STOCK ========== (UNTUK STAFF)
+func (ph *ProductHandler) UpdateStock(w http.ResponseWriter, r *http.Request) {
+	productIDStr := chi.URLParam(r, "id")
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID format", nil)
+		return
+	}
+
+	var req product.UpdateStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Validate request menggunakan utils validator
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Validation failed", err.Error())
+		return
+	}
+
+	// Call service
+	updatedProduct, err := ph.service.Product.UpdateStock(r.Context(), productID, req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to update product stock", zap.Error(err))
+
+		statusCode := http.StatusBadRequest
+		if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "stock quantity cannot be negative" {
+			statusCode = http.StatusBadRequest
+		} else if err.Error() == "validation failed" {
+			statusCode = http.StatusUnprocessableEntity
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Product stock updated successfully", updatedProduct)
+}
+
+// ========== STOCK LOCATIONS (MULTI WAREHOUSE) ==========
+func (ph *ProductHandler) FindStockLocations(w http.ResponseWriter, r *http.Request) {
+	productID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID format", nil)
+		return
+	}
+
+	locations, err := ph.service.Product.FindStockLocations(r.Context(), productID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to get stock locations", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Stock locations retrieved", locations)
+}
+
+// SetLocationStock - staff boleh set stok per rak (sama seperti update stock)
+func (ph *ProductHandler) SetLocationStock(w http.ResponseWriter, r *http.Request) {
+	productID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID format", nil)
+		return
+	}
+
+	var req product.SetLocationStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	locations, err := ph.service.Product.SetLocationStock(r.Context(), productID, req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to set location stock", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "product not found" || err.Error() == "shelf not found" {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "validation failed") || strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "inactive warehouse") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Location stock updated successfully", locations)
+}
+
+// ========== STOCK TRANSFER ==========
+// POST /api/admin/stock/transfer, body: {"product_id": "...", "from_shelf_id": "...", "to_shelf_id": "...", "quantity": 5}
+func (ph *ProductHandler) TransferStock(w http.ResponseWriter, r *http.Request) {
+	var req product.StockTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	result, err := ph.service.Product.TransferStock(r.Context(), req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to transfer stock", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.HasSuffix(err.Error(), "not found") {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "insufficient stock") {
+			statusCode = http.StatusConflict
+		} else if strings.Contains(err.Error(), "validation failed") || strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "inactive warehouse") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Stock transferred successfully", result)
+}
+
+// ========== STOCK MOVEMENT SUMMARY ==========
+func (ph *ProductHandler) GetMovementSummary(w http.ResponseWriter, r *http.Request) {
+	productID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID format", nil)
+		return
+	}
+
+	req := product.MovementSummaryRequest{
+		StartDate: r.URL.Query().Get("start_date"),
+		EndDate:   r.URL.Query().Get("end_date"),
+	}
+
+	summary, err := ph.service.Product.GetMovementSummary(r.Context(), productID, req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "validation") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") ||
+			strings.Contains(err.Error(), "date range") {
+			statusCode = http.StatusBadRequest
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to get stock movement summary", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Stock movement summary retrieved", summary)
+}
+
+// ========== STOCK MOVEMENT FEED ==========
+// GET /api/admin/stock-movements?start_date=&end_date=&type=&page=1&limit=10
+func (ph *ProductHandler) MovementFeed(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// Default values
+	page := 1
+	limit := 10
+
+	// Parse page parameter
+	if pageStr := query.Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		} else {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid page parameter", nil)
+			return
+		}
+	}
+
+	// Parse limit parameter
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		} else {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid limit parameter (max 100)", nil)
+			return
+		}
+	}
+
+	req := product.MovementFeedRequest{
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+		Type:      query.Get("type"),
+	}
+
+	movements, pagination, err := ph.service.Product.GetMovementFeed(r.Context(), req, page, limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "validation failed") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") {
+			utils.ResponseError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+
+		utils.LoggerFromContext(r.Context()).Error("Failed to get stock movement feed", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve stock movements", nil)
+		return
+	}
+
+	// Response with pagination
+	response := map[string]interface{}{
+		"movements":  movements,
+		"pagination": pagination,
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Stock movements retrieved successfully", response)
+}
+
+// ========== SKU HISTORY ==========
+// GET /api/products/{id}/sku-history
+func (ph *ProductHandler) GetSKUHistory(w http.ResponseWriter, r *http.Request) {
+	productID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID format", nil)
+		return
+	}
+
+	history, err := ph.service.Product.GetSKUHistory(r.Context(), productID)
+	if err != nil {
+		if err.Error() == "product not found" {
+			utils.ResponseError(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+
+		utils.LoggerFromContext(r.Context()).Error("Failed to get sku history", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve sku history", nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "SKU history retrieved", history)
+}
+
+// ========== STOCK AT DATE ==========
+// GET /api/products/{id}/stock-at?date=2024-01-31
+func (ph *ProductHandler) GetStockAt(w http.ResponseWriter, r *http.Request) {
+	productID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID format", nil)
+		return
+	}
+
+	req := product.StockAtRequest{
+		Date: r.URL.Query().Get("date"),
+	}
+
+	stock, err := ph.service.Product.GetStockAt(r.Context(), productID, req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "validation") || strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusBadRequest
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to get stock at date", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Stock at date retrieved", stock)
 }
 
 // ========== DELETE PRODUCT ==========
@@ -226,7 +1092,7 @@ func (ph *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	err = ph.service.Product.Delete(r.Context(), productID)
 	if err != nil {
-		ph.log.Error("Failed to delete product", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to delete product", zap.Error(err))
 
 		statusCode := http.StatusBadRequest
 		if err.Error() == "product not found" {
@@ -251,7 +1117,7 @@ func (ph *ProductHandler) FindByCategoryID(w http.ResponseWriter, r *http.Reques
 
 	products, err := ph.service.Product.FindByCategoryID(r.Context(), categoryID)
 	if err != nil {
-		ph.log.Error("Failed to get products by category", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to get products by category", zap.Error(err))
 
 		statusCode := http.StatusBadRequest
 		if err.Error() == "category not found" {
@@ -278,7 +1144,7 @@ func (ph *ProductHandler) FindByShelfID(w http.ResponseWriter, r *http.Request)
 
 	products, err := ph.service.Product.FindByShelfID(r.Context(), shelfID)
 	if err != nil {
-		ph.log.Error("Failed to get products by shelf", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to get products by shelf", zap.Error(err))
 
 		statusCode := http.StatusBadRequest
 		if err.Error() == "shelf not found" {
@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"inventory-system/dto/product"
+	"inventory-system/model"
+	"inventory-system/service"
+	"inventory-system/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKES ==========
+
+type fakeProductService struct {
+	service.ProductService
+	err          error
+	validation   *product.ProductValidationResponse
+	duplicateReq *product.DuplicateProductRequest
+	feedReq      *product.MovementFeedRequest
+	feedPage     int
+	feedLimit    int
+	calls        int
+}
+
+func (f *fakeProductService) Create(ctx context.Context, req product.CreateProductRequest) (*product.ProductResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &product.ProductResponse{}, nil
+}
+
+func (f *fakeProductService) Validate(ctx context.Context, req product.CreateProductRequest) (*product.ProductValidationResponse, error) {
+	f.calls++
+	return f.validation, f.err
+}
+
+func (f *fakeProductService) Duplicate(ctx context.Context, id uuid.UUID, req product.DuplicateProductRequest) (*product.ProductResponse, error) {
+	f.calls++
+	f.duplicateReq = &req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &product.ProductResponse{}, nil
+}
+
+func (f *fakeProductService) Restock(ctx context.Context, req product.RestockRequest) (*product.RestockResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &product.RestockResponse{}, nil
+}
+
+func (f *fakeProductService) GetMovementFeed(ctx context.Context, req product.MovementFeedRequest, page, limit int) ([]product.MovementFeedEntry, utils.Pagination, error) {
+	f.calls++
+	f.feedReq, f.feedPage, f.feedLimit = &req, page, limit
+	return []product.MovementFeedEntry{}, utils.Pagination{}, f.err
+}
+
+func newTestProductHandler(svc *fakeProductService) *ProductHandler {
+	return NewProductHandler(&service.Service{Product: svc}, zap.NewNop())
+}
+
+// ========== CREATE / VALIDATE ==========
+
+func TestProductCreateErrorMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "created", body: `{}`, wantStatus: http.StatusCreated},
+		{name: "invalid body", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "category not found", body: `{}`, err: fmt.Errorf("category not found"), wantStatus: http.StatusNotFound},
+		{name: "shelf not found", body: `{}`, err: fmt.Errorf("shelf not found"), wantStatus: http.StatusNotFound},
+		{name: "duplicate sku", body: `{}`, err: fmt.Errorf("product sku already exists"), wantStatus: http.StatusConflict},
+		{name: "validation", body: `{}`, err: fmt.Errorf("validation failed: Name is required"), wantStatus: http.StatusUnprocessableEntity},
+		{name: "min stock above cap", body: `{}`, err: fmt.Errorf("validation failed: min_stock_level must not exceed 100"), wantStatus: http.StatusUnprocessableEntity},
+		{name: "other business error", body: `{}`, err: fmt.Errorf("cannot place product on inactive warehouse"), wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		h := newTestProductHandler(&fakeProductService{err: tt.err})
+		w := httptest.NewRecorder()
+		h.Create(w, newRequest(http.MethodPost, "/", tt.body, newUser(model.RoleAdmin), nil))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+}
+
+func TestProductValidateHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		validation *product.ProductValidationResponse
+		err        error
+		wantStatus int
+		wantValid  bool
+	}{
+		{name: "valid payload", body: `{}`, validation: &product.ProductValidationResponse{Valid: true}, wantStatus: http.StatusOK, wantValid: true},
+		{name: "invalid payload still 200", body: `{}`, validation: &product.ProductValidationResponse{Errors: map[string]string{"name": "required"}}, wantStatus: http.StatusOK},
+		{name: "malformed json", body: `[`, wantStatus: http.StatusBadRequest},
+		{name: "lookup failure", body: `{}`, err: fmt.Errorf("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestProductHandler(&fakeProductService{validation: tt.validation, err: tt.err})
+			w := httptest.NewRecorder()
+			h.Validate(w, newRequest(http.MethodPost, "/", tt.body, nil, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.validation == nil {
+				return
+			}
+			var resp struct {
+				Data product.ProductValidationResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+			if resp.Data.Valid != tt.wantValid || len(resp.Data.Errors) != len(tt.validation.Errors) {
+				t.Errorf("data = %+v, want %+v", resp.Data, tt.validation)
+			}
+		})
+	}
+}
+
+// ========== DUPLICATE ==========
+
+func TestProductDuplicateHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		body       string
+		err        error
+		wantStatus int
+		wantName   string
+	}{
+		{name: "empty body allowed", id: uuid.NewString(), body: "", wantStatus: http.StatusCreated},
+		{name: "custom name", id: uuid.NewString(), body: `{"name":"Coffee Large"}`, wantStatus: http.StatusCreated, wantName: "Coffee Large"},
+		{name: "invalid id", id: "abc", wantStatus: http.StatusBadRequest},
+		{name: "malformed body", id: uuid.NewString(), body: `{"name":`, wantStatus: http.StatusBadRequest},
+		{name: "source not found", id: uuid.NewString(), err: fmt.Errorf("product not found"), wantStatus: http.StatusNotFound},
+		{name: "shelf gone", id: uuid.NewString(), err: fmt.Errorf("shelf not found"), wantStatus: http.StatusNotFound},
+		{name: "inactive warehouse", id: uuid.NewString(), err: fmt.Errorf("cannot place product on shelf in inactive warehouse"), wantStatus: http.StatusBadRequest},
+		{name: "validation", id: uuid.NewString(), err: fmt.Errorf("validation failed: Name too long"), wantStatus: http.StatusUnprocessableEntity},
+		{name: "unexpected", id: uuid.NewString(), err: fmt.Errorf("failed to duplicate product"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeProductService{err: tt.err}
+			h := newTestProductHandler(svc)
+			w := httptest.NewRecorder()
+			h.Duplicate(w, newRequest(http.MethodPost, "/", tt.body, newUser(model.RoleAdmin), map[string]string{"id": tt.id}))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantName != "" && (svc.duplicateReq.Name == nil || *svc.duplicateReq.Name != tt.wantName) {
+				t.Errorf("name override = %v, want %q", svc.duplicateReq.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+// ========== RESTOCK ==========
+
+func TestProductRestockHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "restocked", body: `{"items":[]}`, wantStatus: http.StatusOK},
+		{name: "malformed body", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "unknown product", body: `{}`, err: fmt.Errorf("product not found: 123"), wantStatus: http.StatusNotFound},
+		{name: "validation", body: `{}`, err: fmt.Errorf("validation failed: Items is required"), wantStatus: http.StatusUnprocessableEntity},
+		{name: "invalid id", body: `{}`, err: fmt.Errorf("invalid product ID format"), wantStatus: http.StatusBadRequest},
+		{name: "db failure", body: `{}`, err: fmt.Errorf("failed to restock products"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		h := newTestProductHandler(&fakeProductService{err: tt.err})
+		w := httptest.NewRecorder()
+		h.Restock(w, newRequest(http.MethodPost, "/", tt.body, newUser(model.RoleAdmin), nil))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+}
+
+// ========== MOVEMENT FEED ==========
+
+func TestProductMovementFeedHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		wantStatus int
+		wantPage   int
+		wantLimit  int
+		wantType   string
+	}{
+		{name: "defaults", query: "", wantStatus: http.StatusOK, wantPage: 1, wantLimit: 10},
+		{name: "filters passed through", query: "page=2&limit=50&type=sale&start_date=2026-01-01&end_date=2026-01-31", wantStatus: http.StatusOK, wantPage: 2, wantLimit: 50, wantType: "sale"},
+		{name: "invalid page", query: "page=0", wantStatus: http.StatusBadRequest},
+		{name: "limit over max", query: "limit=101", wantStatus: http.StatusBadRequest},
+		{name: "service validation", query: "type=gift", err: fmt.Errorf("validation failed: Type must be one of"), wantStatus: http.StatusBadRequest, wantPage: 1, wantLimit: 10, wantType: "gift"},
+		{name: "start after end", query: "", err: fmt.Errorf("start date cannot be after end date"), wantStatus: http.StatusBadRequest, wantPage: 1, wantLimit: 10},
+		{name: "db failure", query: "", err: fmt.Errorf("failed to get stock movements"), wantStatus: http.StatusInternalServerError, wantPage: 1, wantLimit: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeProductService{err: tt.err}
+			h := newTestProductHandler(svc)
+			w := httptest.NewRecorder()
+			h.MovementFeed(w, newRequest(http.MethodGet, "/api/admin/stock-movements?"+tt.query, "", newUser(model.RoleAdmin), nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantPage == 0 {
+				if svc.calls != 0 {
+					t.Error("service called for invalid paging")
+				}
+				return
+			}
+			if svc.feedPage != tt.wantPage || svc.feedLimit != tt.wantLimit || svc.feedReq.Type != tt.wantType {
+				t.Errorf("service got page=%d limit=%d type=%q, want %d %d %q",
+					svc.feedPage, svc.feedLimit, svc.feedReq.Type, tt.wantPage, tt.wantLimit, tt.wantType)
+			}
+		})
+	}
+}
+
+// ========== INCLUDE DELETED ==========
+
+func TestParseIncludeDeleted(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		user       *model.User
+		want       bool
+		wantOK     bool
+		wantStatus int
+	}{
+		{name: "absent", query: "", user: newUser(model.RoleStaff), want: false, wantOK: true},
+		{name: "false for staff", query: "include_deleted=false", user: newUser(model.RoleStaff), want: false, wantOK: true},
+		{name: "admin", query: "include_deleted=true", user: newUser(model.RoleAdmin), want: true, wantOK: true},
+		{name: "super admin", query: "include_deleted=1", user: newUser(model.RoleSuperAdmin), want: true, wantOK: true},
+		{name: "staff forbidden", query: "include_deleted=true", user: newUser(model.RoleStaff), wantStatus: http.StatusForbidden},
+		{name: "no user forbidden", query: "include_deleted=true", wantStatus: http.StatusForbidden},
+		{name: "invalid value", query: "include_deleted=maybe", user: newUser(model.RoleAdmin), wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		got, ok := parseIncludeDeleted(w, newRequest(http.MethodGet, "/?"+tt.query, "", tt.user, nil))
+
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: parseIncludeDeleted() = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+		if !tt.wantOK && w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+}
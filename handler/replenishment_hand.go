@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"encoding/json"
+	"inventory-system/dto/replenishment"
+	"inventory-system/middleware"
+	"inventory-system/service"
+	"inventory-system/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ReplenishmentHandler struct {
+	service *service.Service
+	log     *zap.Logger
+}
+
+func NewReplenishmentHandler(service *service.Service, log *zap.Logger) *ReplenishmentHandler {
+	return &ReplenishmentHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// Create staff minta restock untuk satu produk
+func (rh *ReplenishmentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	productIDStr := chi.URLParam(r, "id")
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+
+	var req replenishment.CreateReplenishmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Get authenticated user from context
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		utils.ResponseError(w, http.StatusUnauthorized, "Authentication required", nil)
+		return
+	}
+
+	// Call Service
+	createdRequest, err := rh.service.Replenishment.Create(r.Context(), productID, req, user.ID)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to create replenishment request", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation failed") {
+			statusCode = http.StatusBadRequest
+		} else if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "product is discontinued" {
+			statusCode = http.StatusUnprocessableEntity
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "Replenishment request created successfully", createdRequest)
+}
+
+func (rh *ReplenishmentHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	// Get pagination parameters
+	pageStr := r.URL.Query().Get("page")
+	limitStr := r.URL.Query().Get("limit")
+	status := r.URL.Query().Get("status")
+
+	// Default values
+	page := 1
+	limit := 10
+
+	// Parse page
+	if pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		} else {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid page parameter", nil)
+			return
+		}
+	}
+
+	// Parse limit
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		} else {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid limit parameter (max 100)", nil)
+			return
+		}
+	}
+
+	// Call service
+	requests, pagination, err := rh.service.Replenishment.FindAll(r.Context(), status, page, limit)
+	if err != nil {
+		if err.Error() == "invalid status filter" {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid status parameter (pending, approved, rejected)", nil)
+			return
+		}
+
+		utils.LoggerFromContext(r.Context()).Error("Failed to get replenishment requests", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve replenishment requests", nil)
+		return
+	}
+
+	// Response with pagination
+	response := map[string]interface{}{
+		"items":      requests,
+		"pagination": pagination,
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Replenishment requests retrieved successfully", response)
+}
+
+// Review admin approve/reject request yang masih pending
+func (rh *ReplenishmentHandler) Review(w http.ResponseWriter, r *http.Request) {
+	requestIDStr := chi.URLParam(r, "id")
+	requestID, err := uuid.Parse(requestIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid replenishment request ID", nil)
+		return
+	}
+
+	var req replenishment.ReviewReplenishmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		utils.ResponseError(w, http.StatusUnauthorized, "Authentication required", nil)
+		return
+	}
+
+	// Call Service
+	reviewedRequest, err := rh.service.Replenishment.Review(r.Context(), requestID, req, user.ID)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to review replenishment request", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation failed") {
+			statusCode = http.StatusBadRequest
+		} else if err.Error() == "replenishment request not found" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "replenishment request already reviewed" {
+			statusCode = http.StatusConflict
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Replenishment request reviewed successfully", reviewedRequest)
+}
@@ -1,12 +1,18 @@
 package handler
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"inventory-system/dto/report"
 	"inventory-system/service"
 	"inventory-system/utils"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -29,7 +35,7 @@ func (rh *ReportHandler) GetProductReport(w http.ResponseWriter, r *http.Request
 	// Panggil service
 	reportData, err := rh.service.Report.GetProductReport(r.Context())
 	if err != nil {
-		rh.log.Error("Failed to get product report", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to get product report", zap.Error(err))
 
 		statusCode := http.StatusInternalServerError
 		if strings.Contains(err.Error(), "validation") {
@@ -44,12 +50,14 @@ func (rh *ReportHandler) GetProductReport(w http.ResponseWriter, r *http.Request
 }
 
 // ========== 2. GET SALES REPORT ==========
-// GET /api/reports/sales?start_date=2024-01-01&end_date=2024-12-31
-// Semua authenticated user bisa akses
+// GET /api/reports/sales?start_date=2024-01-01&end_date=2024-12-31&user_id=xxx&category_id=xxx
+// Semua authenticated user bisa akses, staff selalu difilter ke penjualan sendiri
 func (rh *ReportHandler) GetSalesReport(w http.ResponseWriter, r *http.Request) {
 	// Ambil query parameters
 	startDate := r.URL.Query().Get("start_date")
 	endDate := r.URL.Query().Get("end_date")
+	userID := r.URL.Query().Get("user_id")         // optional
+	categoryID := r.URL.Query().Get("category_id") // optional
 
 	// Validasi required parameters
 	if startDate == "" || endDate == "" {
@@ -63,17 +71,25 @@ func (rh *ReportHandler) GetSalesReport(w http.ResponseWriter, r *http.Request)
 		StartDate: startDate,
 		EndDate:   endDate,
 	}
+	if userID != "" {
+		req.UserID = &userID
+	}
+	if categoryID != "" {
+		req.CategoryID = &categoryID
+	}
 
 	// Panggil service
 	reportData, err := rh.service.Report.GetSalesReport(r.Context(), req)
 	if err != nil {
-		rh.log.Error("Failed to get sales report", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to get sales report", zap.Error(err))
 
 		statusCode := http.StatusInternalServerError
 		if strings.Contains(err.Error(), "validation") ||
-			strings.Contains(err.Error(), "invalid date") ||
+			strings.Contains(err.Error(), "invalid") ||
 			strings.Contains(err.Error(), "date range") {
 			statusCode = http.StatusBadRequest
+		} else if strings.Contains(err.Error(), "not found") {
+			statusCode = http.StatusNotFound
 		}
 
 		utils.ResponseError(w, statusCode, "Failed to get sales report", err.Error())
@@ -90,7 +106,8 @@ func (rh *ReportHandler) GetRevenueReport(w http.ResponseWriter, r *http.Request
 	// Ambil query parameters
 	startDate := r.URL.Query().Get("start_date")
 	endDate := r.URL.Query().Get("end_date")
-	groupBy := r.URL.Query().Get("group_by") // optional: day, week, month
+	groupBy := r.URL.Query().Get("group_by")             // optional: day, week, month
+	paymentStatus := r.URL.Query().Get("payment_status") // optional: unpaid, partial, paid
 
 	// Validasi required parameters
 	if startDate == "" || endDate == "" {
@@ -101,19 +118,22 @@ func (rh *ReportHandler) GetRevenueReport(w http.ResponseWriter, r *http.Request
 
 	// Buat request DTO
 	req := report.RevenueReportRequest{
-		StartDate: startDate,
-		EndDate:   endDate,
-		GroupBy:   groupBy,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		GroupBy:       groupBy,
+		PaymentStatus: paymentStatus,
 	}
 
 	// Panggil service
 	reportData, err := rh.service.Report.GetRevenueReport(r.Context(), req)
 	if err != nil {
-		rh.log.Error("Failed to get revenue report", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to get revenue report", zap.Error(err))
 
 		statusCode := http.StatusInternalServerError
 		if strings.Contains(err.Error(), "validation") ||
-			strings.Contains(err.Error(), "invalid date") {
+			strings.Contains(err.Error(), "invalid date") ||
+			strings.Contains(err.Error(), "start date") ||
+			strings.Contains(err.Error(), "date range") {
 			statusCode = http.StatusBadRequest
 		}
 
@@ -123,3 +143,555 @@ func (rh *ReportHandler) GetRevenueReport(w http.ResponseWriter, r *http.Request
 
 	utils.ResponseSuccess(w, http.StatusOK, "Revenue report retrieved", reportData)
 }
+
+// ========== 4. EXPORT INVENTORY VALUATION ==========
+// GET /api/admin/reports/inventory?format=csv|json
+// Hanya admin & super_admin (diatur di middleware router)
+// Response di-stream per row, jadi katalog besar tidak di-load ke memory
+func (rh *ReportHandler) GetInventoryValuation(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		utils.ResponseError(w, http.StatusBadRequest,
+			"Invalid format parameter. Must be: csv or json", nil)
+		return
+	}
+
+	filename := "inventory-valuation-" + time.Now().Format("20060102")
+
+	// Header response baru ditulis saat row pertama datang,
+	// supaya error sebelum streaming masih bisa dikirim sebagai JSON biasa
+	started := false
+	var err error
+
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+
+		writeHeader := func() {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Disposition", "attachment; filename="+filename+".json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("["))
+			started = true
+		}
+
+		err = rh.service.Report.StreamInventoryValuation(r.Context(), func(row report.InventoryValuationRow) error {
+			if !started {
+				writeHeader()
+			} else {
+				w.Write([]byte(","))
+			}
+			return encoder.Encode(row)
+		})
+		if err == nil {
+			if !started {
+				writeHeader()
+			}
+			w.Write([]byte("]"))
+		}
+	} else {
+		writer := csv.NewWriter(w)
+		var totalStock int
+		var totalCost, totalRetail float64
+
+		writeHeader := func() {
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Disposition", "attachment; filename="+filename+".csv")
+			w.WriteHeader(http.StatusOK)
+			writer.Write([]string{
+				"product_id", "sku", "product_name", "category", "stock_quantity",
+				"cost_price", "unit_price", "cost_value", "retail_value",
+			})
+			started = true
+		}
+
+		err = rh.service.Report.StreamInventoryValuation(r.Context(), func(row report.InventoryValuationRow) error {
+			if !started {
+				writeHeader()
+			}
+
+			totalStock += row.StockQuantity
+			totalCost += row.CostValue
+			totalRetail += row.RetailValue
+
+			return writer.Write([]string{
+				row.ProductID,
+				optionalField(row.SKU),
+				row.ProductName,
+				row.CategoryName,
+				strconv.Itoa(row.StockQuantity),
+				formatAmount(row.CostPrice),
+				formatAmount(row.UnitPrice),
+				formatAmount(row.CostValue),
+				formatAmount(row.RetailValue),
+			})
+		})
+		if err == nil {
+			if !started {
+				writeHeader()
+			}
+			// Footer totals
+			writer.Write([]string{
+				"TOTAL", "", "", "", strconv.Itoa(totalStock),
+				"", "", formatAmount(totalCost), formatAmount(totalRetail),
+			})
+		}
+		writer.Flush()
+	}
+
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to export inventory valuation", zap.Error(err))
+		if !started {
+			utils.ResponseError(w, http.StatusInternalServerError,
+				"Failed to export inventory valuation", err.Error())
+		}
+		return
+	}
+}
+
+// formatAmount helper: format nilai uang dengan 2 desimal untuk export
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// ========== 5. GET SALES BY CATEGORY ==========
+// GET /api/admin/reports/sales-by-category?start_date=2024-01-01&end_date=2024-12-31&include_empty=true
+// Hanya admin & super_admin
+func (rh *ReportHandler) GetSalesByCategory(w http.ResponseWriter, r *http.Request) {
+	// Ambil query parameters
+	startDate := r.URL.Query().Get("start_date")
+	endDate := r.URL.Query().Get("end_date")
+
+	// Validasi required parameters
+	if startDate == "" || endDate == "" {
+		utils.ResponseError(w, http.StatusBadRequest,
+			"start_date and end_date are required", nil)
+		return
+	}
+
+	includeEmpty := false
+	if v := r.URL.Query().Get("include_empty"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid include_empty parameter", nil)
+			return
+		}
+		includeEmpty = b
+	}
+
+	// Buat request DTO
+	req := report.SalesByCategoryRequest{
+		StartDate:    startDate,
+		EndDate:      endDate,
+		IncludeEmpty: includeEmpty,
+	}
+
+	// Panggil service
+	reportData, err := rh.service.Report.GetSalesByCategory(r.Context(), req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get sales by category", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") ||
+			strings.Contains(err.Error(), "date range") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to get sales by category", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Sales by category retrieved", reportData)
+}
+
+// ========== 6. COMPARE REVENUE ==========
+// GET /api/admin/reports/revenue/compare?period=day|week|month (default month)
+// Hanya admin & super_admin
+func (rh *ReportHandler) CompareRevenue(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = "month"
+	}
+
+	// Panggil service
+	reportData, err := rh.service.Report.CompareRevenue(r.Context(), report.RevenueCompareRequest{Period: period})
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to compare revenue", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to compare revenue", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Revenue comparison retrieved", reportData)
+}
+
+// ========== 7. GET INVENTORY TURNOVER ==========
+// GET /api/admin/reports/turnover?start_date=2024-01-01&end_date=2024-12-31
+// Hanya admin & super_admin
+func (rh *ReportHandler) GetInventoryTurnover(w http.ResponseWriter, r *http.Request) {
+	// Ambil query parameters
+	startDate := r.URL.Query().Get("start_date")
+	endDate := r.URL.Query().Get("end_date")
+
+	// Validasi required parameters
+	if startDate == "" || endDate == "" {
+		utils.ResponseError(w, http.StatusBadRequest,
+			"start_date and end_date are required", nil)
+		return
+	}
+
+	// Panggil service
+	reportData, err := rh.service.Report.GetInventoryTurnover(r.Context(), report.InventoryTurnoverRequest{
+		StartDate: startDate,
+		EndDate:   endDate,
+	})
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get inventory turnover", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") ||
+			strings.Contains(err.Error(), "date range") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to get inventory turnover", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Inventory turnover retrieved", reportData)
+}
+
+// ========== 8. GET SALES TREND ==========
+// GET /api/admin/reports/trend?start_date=2024-01-01&end_date=2024-01-31&interval=day
+// Hanya admin & super_admin
+func (rh *ReportHandler) GetSalesTrend(w http.ResponseWriter, r *http.Request) {
+	// Ambil query parameters
+	startDate := r.URL.Query().Get("start_date")
+	endDate := r.URL.Query().Get("end_date")
+	interval := r.URL.Query().Get("interval")
+	if interval == "" {
+		interval = "day"
+	}
+
+	// Validasi required parameters
+	if startDate == "" || endDate == "" {
+		utils.ResponseError(w, http.StatusBadRequest,
+			"start_date and end_date are required", nil)
+		return
+	}
+
+	// Panggil service
+	reportData, err := rh.service.Report.GetSalesTrend(r.Context(), report.SalesTrendRequest{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Interval:  interval,
+	})
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get sales trend", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") ||
+			strings.Contains(err.Error(), "date range") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to get sales trend", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Sales trend retrieved", reportData)
+}
+
+// ========== 12. GET SALES BY HOUR ==========
+// GET /api/admin/reports/sales-by-hour?start_date=2024-01-01&end_date=2024-01-31
+// Hanya admin & super_admin
+func (rh *ReportHandler) GetSalesByHour(w http.ResponseWriter, r *http.Request) {
+	// Ambil query parameters
+	startDate := r.URL.Query().Get("start_date")
+	endDate := r.URL.Query().Get("end_date")
+
+	// Validasi required parameters
+	if startDate == "" || endDate == "" {
+		utils.ResponseError(w, http.StatusBadRequest,
+			"start_date and end_date are required", nil)
+		return
+	}
+
+	// Panggil service
+	reportData, err := rh.service.Report.GetSalesByHour(r.Context(), report.SalesByHourRequest{
+		StartDate: startDate,
+		EndDate:   endDate,
+	})
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get sales by hour", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") ||
+			strings.Contains(err.Error(), "date range") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to get sales by hour", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Sales by hour retrieved", reportData)
+}
+
+// ========== 13. GET REVENUE BY PAYMENT METHOD ==========
+// GET /api/admin/reports/revenue-by-payment?start_date=2024-01-01&end_date=2024-01-31
+// Hanya admin & super_admin
+func (rh *ReportHandler) GetRevenueByPaymentMethod(w http.ResponseWriter, r *http.Request) {
+	// Ambil query parameters
+	startDate := r.URL.Query().Get("start_date")
+	endDate := r.URL.Query().Get("end_date")
+
+	// Validasi required parameters
+	if startDate == "" || endDate == "" {
+		utils.ResponseError(w, http.StatusBadRequest,
+			"start_date and end_date are required", nil)
+		return
+	}
+
+	// Panggil service
+	reportData, err := rh.service.Report.GetRevenueByPaymentMethod(r.Context(), report.RevenueByPaymentRequest{
+		StartDate: startDate,
+		EndDate:   endDate,
+	})
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get revenue by payment method", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") ||
+			strings.Contains(err.Error(), "date range") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to get revenue by payment method", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Revenue by payment method retrieved", reportData)
+}
+
+// ========== 14. GET TOP CUSTOMERS ==========
+// GET /api/admin/reports/top-customers?start_date=2024-01-01&end_date=2024-01-31&limit=10
+// Hanya admin & super_admin
+func (rh *ReportHandler) GetTopCustomers(w http.ResponseWriter, r *http.Request) {
+	// Ambil query parameters
+	startDate := r.URL.Query().Get("start_date")
+	endDate := r.URL.Query().Get("end_date")
+
+	// Validasi required parameters
+	if startDate == "" || endDate == "" {
+		utils.ResponseError(w, http.StatusBadRequest,
+			"start_date and end_date are required", nil)
+		return
+	}
+
+	// Default 10 customer, max 100
+	limit := 10
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid limit", nil)
+			return
+		}
+		limit = parsed
+		if limit > 100 {
+			limit = 100
+		}
+	}
+
+	// Panggil service
+	reportData, err := rh.service.Report.GetTopCustomers(r.Context(), report.TopCustomersRequest{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Limit:     limit,
+	})
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get top customers", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") ||
+			strings.Contains(err.Error(), "date range") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to get top customers", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Top customers retrieved", reportData)
+}
+
+// ========== 15. GET FULFILLMENT TIME ==========
+// GET /api/admin/reports/fulfillment-time?start_date=2024-01-01&end_date=2024-01-31
+// Hanya admin & super_admin
+func (rh *ReportHandler) GetFulfillmentTime(w http.ResponseWriter, r *http.Request) {
+	// Ambil query parameters
+	startDate := r.URL.Query().Get("start_date")
+	endDate := r.URL.Query().Get("end_date")
+
+	// Validasi required parameters
+	if startDate == "" || endDate == "" {
+		utils.ResponseError(w, http.StatusBadRequest,
+			"start_date and end_date are required", nil)
+		return
+	}
+
+	// Panggil service
+	reportData, err := rh.service.Report.GetFulfillmentTime(r.Context(), report.FulfillmentTimeRequest{
+		StartDate: startDate,
+		EndDate:   endDate,
+	})
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get fulfillment time", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") ||
+			strings.Contains(err.Error(), "date range") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to get fulfillment time", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Fulfillment time retrieved", reportData)
+}
+
+// ========== 16. GET MONTHLY SNAPSHOT ==========
+// GET /api/admin/reports/monthly-snapshot?month=2024-01
+// Hanya admin & super_admin
+func (rh *ReportHandler) GetMonthlySnapshot(w http.ResponseWriter, r *http.Request) {
+	month := r.URL.Query().Get("month")
+	if month == "" {
+		utils.ResponseError(w, http.StatusBadRequest, "month is required", nil)
+		return
+	}
+
+	reportData, err := rh.service.Report.GetMonthlySnapshot(r.Context(), report.MonthlySnapshotRequest{Month: month})
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get monthly snapshot", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") ||
+			strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to get monthly snapshot", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Monthly snapshot retrieved", reportData)
+}
+
+// ========== 9. GET WAREHOUSE INVENTORY SUMMARY ==========
+// GET /api/warehouses/{id}/inventory-summary
+// Semua user bisa akses (sama seperti product report)
+func (rh *ReportHandler) GetWarehouseInventory(w http.ResponseWriter, r *http.Request) {
+	warehouseIDStr := chi.URLParam(r, "id")
+	warehouseID, err := uuid.Parse(warehouseIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid warehouse ID", nil)
+		return
+	}
+
+	reportData, err := rh.service.Report.GetWarehouseInventory(r.Context(), warehouseID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "warehouse not found" {
+			statusCode = http.StatusNotFound
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to get warehouse inventory", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to get warehouse inventory", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Warehouse inventory summary retrieved", reportData)
+}
+
+// ========== 10. GET MY SALES STATS ==========
+// GET /api/sales/my-stats?start_date=2024-01-01&end_date=2024-01-31
+// User selalu dari token, tidak ada param user_id
+func (rh *ReportHandler) GetMySalesStats(w http.ResponseWriter, r *http.Request) {
+	user := utils.GetUserFromContext(r.Context())
+	if user == nil {
+		utils.ResponseError(w, http.StatusUnauthorized, "Authentication required", nil)
+		return
+	}
+
+	req := report.MySalesStatsRequest{
+		StartDate: r.URL.Query().Get("start_date"),
+		EndDate:   r.URL.Query().Get("end_date"),
+	}
+
+	stats, err := rh.service.Report.GetMySalesStats(r.Context(), user.ID, req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") ||
+			strings.Contains(err.Error(), "date range") {
+			statusCode = http.StatusBadRequest
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to get sales stats", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to get sales stats", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Sales stats retrieved", stats)
+}
+
+// ========== 11. GET SHELF DISTRIBUTION ==========
+// GET /api/warehouses/{id}/shelf-distribution
+// Semua user bisa akses (sama seperti warehouse inventory summary)
+func (rh *ReportHandler) GetShelfDistribution(w http.ResponseWriter, r *http.Request) {
+	warehouseID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid warehouse ID", nil)
+		return
+	}
+
+	reportData, err := rh.service.Report.GetShelfDistribution(r.Context(), warehouseID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "warehouse not found" {
+			statusCode = http.StatusNotFound
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to get shelf distribution", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to get shelf distribution", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Shelf distribution retrieved", reportData)
+}
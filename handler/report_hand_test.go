@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"inventory-system/dto/report"
+	"inventory-system/model"
+	"inventory-system/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKES ==========
+
+type fakeReportService struct {
+	service.ReportService
+	rows      []report.InventoryValuationRow
+	streamErr error // dikembalikan setelah semua rows terkirim
+	err       error
+	salesReq  *report.SalesReportRequest
+	statsUser uuid.UUID
+}
+
+func (f *fakeReportService) StreamInventoryValuation(ctx context.Context, fn func(row report.InventoryValuationRow) error) error {
+	for _, row := range f.rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return f.streamErr
+}
+
+func (f *fakeReportService) GetSalesReport(ctx context.Context, req report.SalesReportRequest) (*report.SalesReportResponse, error) {
+	f.salesReq = &req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &report.SalesReportResponse{}, nil
+}
+
+func (f *fakeReportService) GetMySalesStats(ctx context.Context, userID uuid.UUID, req report.MySalesStatsRequest) (*report.MySalesStatsResponse, error) {
+	f.statsUser = userID
+	return &report.MySalesStatsResponse{UserID: userID.String()}, nil
+}
+
+func newTestReportHandler(svc *fakeReportService) *ReportHandler {
+	return NewReportHandler(&service.Service{Report: svc}, zap.NewNop())
+}
+
+// ========== INVENTORY VALUATION ==========
+
+func TestInventoryValuationCSV(t *testing.T) {
+	rows := []report.InventoryValuationRow{
+		{ProductID: "p-1", SKU: stringPtr("SKU-1"), ProductName: "Coffee", CategoryName: "Drinks", StockQuantity: 3, CostPrice: 8, UnitPrice: 10, CostValue: 24, RetailValue: 30},
+		{ProductID: "p-2", SKU: nil, ProductName: "Tea, Green", CategoryName: "Drinks", StockQuantity: 2, CostPrice: 1.25, UnitPrice: 2.5, CostValue: 2.5, RetailValue: 5},
+	}
+
+	tests := []struct {
+		name      string
+		rows      []report.InventoryValuationRow
+		wantLines [][]string
+	}{
+		{
+			name: "rows with totals footer",
+			rows: rows,
+			wantLines: [][]string{
+				{"product_id", "sku", "product_name", "category", "stock_quantity", "cost_price", "unit_price", "cost_value", "retail_value"},
+				{"p-1", "SKU-1", "Coffee", "Drinks", "3", "8.00", "10.00", "24.00", "30.00"},
+				{"p-2", "", "Tea, Green", "Drinks", "2", "1.25", "2.50", "2.50", "5.00"},
+				{"TOTAL", "", "", "", "5", "", "", "26.50", "35.00"},
+			},
+		},
+		{
+			name: "empty catalog",
+			wantLines: [][]string{
+				{"product_id", "sku", "product_name", "category", "stock_quantity", "cost_price", "unit_price", "cost_value", "retail_value"},
+				{"TOTAL", "", "", "", "0", "", "", "0.00", "0.00"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestReportHandler(&fakeReportService{rows: tt.rows})
+			w := httptest.NewRecorder()
+			h.GetInventoryValuation(w, newRequest(http.MethodGet, "/api/admin/reports/inventory", "", nil, nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); got != "text/csv" {
+				t.Errorf("Content-Type = %q, want text/csv", got)
+			}
+			lines, err := csv.NewReader(w.Body).ReadAll()
+			if err != nil {
+				t.Fatalf("invalid csv: %v", err)
+			}
+			if len(lines) != len(tt.wantLines) {
+				t.Fatalf("lines = %v, want %v", lines, tt.wantLines)
+			}
+			for i := range lines {
+				if strings.Join(lines[i], "|") != strings.Join(tt.wantLines[i], "|") {
+					t.Errorf("line %d = %v, want %v", i, lines[i], tt.wantLines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestInventoryValuationJSON(t *testing.T) {
+	h := newTestReportHandler(&fakeReportService{rows: []report.InventoryValuationRow{
+		{ProductID: "p-1", SKU: stringPtr("SKU-1")},
+		{ProductID: "p-2"},
+	}})
+	w := httptest.NewRecorder()
+	h.GetInventoryValuation(w, newRequest(http.MethodGet, "/?format=json", "", nil, nil))
+
+	var rows []report.InventoryValuationRow
+	if err := json.Unmarshal(w.Body.Bytes(), &rows); err != nil {
+		t.Fatalf("invalid json array: %v (%s)", err, w.Body.String())
+	}
+	if len(rows) != 2 || rows[0].SKU == nil || *rows[0].SKU != "SKU-1" || rows[1].SKU != nil {
+		t.Errorf("rows = %+v, want sku kept and null for missing", rows)
+	}
+}
+
+func TestInventoryValuationErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		svc        *fakeReportService
+		wantStatus int
+	}{
+		{name: "invalid format", query: "?format=xlsx", svc: &fakeReportService{}, wantStatus: http.StatusBadRequest},
+		{name: "error before first row", query: "?format=csv", svc: &fakeReportService{streamErr: fmt.Errorf("boom")}, wantStatus: http.StatusInternalServerError},
+		{name: "error mid stream keeps 200", query: "?format=csv", svc: &fakeReportService{rows: []report.InventoryValuationRow{{ProductID: "p-1"}}, streamErr: fmt.Errorf("boom")}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		h := newTestReportHandler(tt.svc)
+		w := httptest.NewRecorder()
+		h.GetInventoryValuation(w, newRequest(http.MethodGet, "/"+tt.query, "", nil, nil))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+
+	// Stream terputus: footer TOTAL tidak ditulis supaya file tidak terlihat lengkap
+	h := newTestReportHandler(&fakeReportService{rows: []report.InventoryValuationRow{{ProductID: "p-1"}}, streamErr: fmt.Errorf("boom")})
+	w := httptest.NewRecorder()
+	h.GetInventoryValuation(w, newRequest(http.MethodGet, "/", "", nil, nil))
+	if strings.Contains(w.Body.String(), "TOTAL") {
+		t.Error("interrupted export must not contain totals footer")
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := map[float64]string{
+		0:        "0.00",
+		10:       "10.00",
+		2.5:      "2.50",
+		1234.567: "1234.57",
+		-3.1:     "-3.10",
+	}
+
+	for value, want := range tests {
+		if got := formatAmount(value); got != want {
+			t.Errorf("formatAmount(%v) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+// ========== SALES REPORT ==========
+
+func TestGetSalesReportHandler(t *testing.T) {
+	staff := newUser(model.RoleStaff)
+
+	tests := []struct {
+		name         string
+		query        string
+		err          error
+		wantStatus   int
+		wantUser     string
+		wantCategory string
+	}{
+		{name: "dates only", query: "start_date=2026-01-01&end_date=2026-01-31", wantStatus: http.StatusOK},
+		{name: "user and category filters", query: "start_date=2026-01-01&end_date=2026-01-31&user_id=u-1&category_id=c-1", wantStatus: http.StatusOK, wantUser: "u-1", wantCategory: "c-1"},
+		{name: "missing dates", query: "start_date=2026-01-01", wantStatus: http.StatusBadRequest},
+		{name: "date range too wide", query: "start_date=2026-01-01&end_date=2027-06-01", err: fmt.Errorf("date range cannot exceed 365 days"), wantStatus: http.StatusBadRequest},
+		{name: "unknown cashier", query: "start_date=2026-01-01&end_date=2026-01-31&user_id=u-1", err: fmt.Errorf("user not found"), wantStatus: http.StatusNotFound, wantUser: "u-1"},
+		{name: "repository failure", query: "start_date=2026-01-01&end_date=2026-01-31", err: fmt.Errorf("failed to get sales report"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeReportService{err: tt.err}
+			h := newTestReportHandler(svc)
+			w := httptest.NewRecorder()
+			h.GetSalesReport(w, newRequest(http.MethodGet, "/api/reports/sales?"+tt.query, "", staff, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if svc.salesReq == nil {
+				return
+			}
+			if got := optionalField(svc.salesReq.UserID); got != tt.wantUser {
+				t.Errorf("user_id = %q, want %q", got, tt.wantUser)
+			}
+			if got := optionalField(svc.salesReq.CategoryID); got != tt.wantCategory {
+				t.Errorf("category_id = %q, want %q", got, tt.wantCategory)
+			}
+		})
+	}
+}
+
+// ========== MY SALES STATS ==========
+
+func TestGetMySalesStatsIgnoresUserParam(t *testing.T) {
+	caller := newUser(model.RoleStaff)
+	svc := &fakeReportService{}
+	h := newTestReportHandler(svc)
+
+	w := httptest.NewRecorder()
+	h.GetMySalesStats(w, newRequest(http.MethodGet, "/?user_id="+uuid.NewString(), "", caller, nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", w.Code, w.Body.String())
+	}
+	if svc.statsUser != caller.ID {
+		t.Errorf("stats user = %s, want caller %s", svc.statsUser, caller.ID)
+	}
+
+	w = httptest.NewRecorder()
+	h.GetMySalesStats(w, newRequest(http.MethodGet, "/", "", nil, nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated status = %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"inventory-system/model"
+	"inventory-system/utils"
+	"net/http"
+)
+
+// RoleHandler expose matrix role -> permission untuk role picker di front-end
+// Diturunkan dari method permission model.User, jadi front-end tidak perlu hard-code
+type RoleHandler struct {
+	payload []rolePermissions
+}
+
+type rolePermissions struct {
+	Role                   model.UserRole   `json:"role"`
+	CanManageUsers         bool             `json:"can_manage_users"`
+	CanManageMasterData    bool             `json:"can_manage_master_data"`
+	CanDeleteMasterData    bool             `json:"can_delete_master_data"`
+	CanAccessRevenueReport bool             `json:"can_access_revenue_report"`
+	CanUpdateStock         bool             `json:"can_update_stock"`
+	CanCreateSale          bool             `json:"can_create_sale"`
+	CanCreateUserRoles     []model.UserRole `json:"can_create_user_roles"` // role yang boleh dibuat/di-assign
+}
+
+// NewRoleHandler matrix dirakit sekali saat startup (permission tidak berubah saat runtime)
+func NewRoleHandler() *RoleHandler {
+	payload := make([]rolePermissions, 0, len(model.UserRoles))
+	for _, role := range model.UserRoles {
+		// User dummy per role, permission dibaca dari method yang sama dengan enforcement
+		u := &model.User{Role: role}
+
+		creatable := make([]model.UserRole, 0, len(model.UserRoles))
+		for _, target := range model.UserRoles {
+			if u.CanCreateUserWithRole(target) {
+				creatable = append(creatable, target)
+			}
+		}
+
+		payload = append(payload, rolePermissions{
+			Role:                   role,
+			CanManageUsers:         u.CanManageUsers(),
+			CanManageMasterData:    u.CanManageMasterData(),
+			CanDeleteMasterData:    u.CanDeleteMasterData(),
+			CanAccessRevenueReport: u.CanAccessRevenueReport(),
+			CanUpdateStock:         u.CanUpdateStock(),
+			CanCreateSale:          u.CanCreateSale(),
+			CanCreateUserRoles:     creatable,
+		})
+	}
+
+	return &RoleHandler{payload: payload}
+}
+
+// ========== LIST ROLES ==========
+// GET /api/roles
+func (rh *RoleHandler) List(w http.ResponseWriter, r *http.Request) {
+	utils.ResponseSuccess(w, http.StatusOK, "Roles retrieved", rh.payload)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"inventory-system/dto/sale"
 	"inventory-system/middleware"
@@ -8,6 +9,8 @@ import (
 	"inventory-system/utils"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -55,12 +58,15 @@ func (sh *SaleHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// Call service to create sale
 	createdSale, err := sh.service.Sale.CreateSale(r.Context(), req, user.ID)
 	if err != nil {
-		sh.log.Error("Failed to create sale", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to create sale", zap.Error(err))
 
 		// Determine appropriate HTTP status
 		statusCode := http.StatusBadRequest
 		if err.Error() == "insufficient stock" {
 			statusCode = http.StatusConflict
+		} else if strings.Contains(err.Error(), "is discontinued") || strings.HasPrefix(err.Error(), "duplicate product") ||
+			strings.HasPrefix(err.Error(), "too many items") {
+			statusCode = http.StatusUnprocessableEntity
 		} else if err.Error() == "not found" {
 			statusCode = http.StatusNotFound
 		}
@@ -73,6 +79,33 @@ func (sh *SaleHandler) Create(w http.ResponseWriter, r *http.Request) {
 	utils.ResponseSuccess(w, http.StatusCreated, "Sale created successfully", createdSale)
 }
 
+// DryRun handles POST /api/sales/dry-run - cek stok & total tanpa membuat sale
+// Keranjang yang tidak bisa dipenuhi tetap 200 dengan fulfillable=false
+func (sh *SaleHandler) DryRun(w http.ResponseWriter, r *http.Request) {
+	var req sale.CreateSaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	result, err := sh.service.Sale.DryRun(r.Context(), req)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if strings.HasPrefix(err.Error(), "duplicate product") || strings.HasPrefix(err.Error(), "too many items") {
+			statusCode = http.StatusUnprocessableEntity
+		} else if !strings.Contains(err.Error(), "validation failed") && !strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusInternalServerError
+			utils.LoggerFromContext(r.Context()).Error("Failed to dry run sale", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Sale dry run completed", result)
+}
+
 // FindByID handles GET /api/sales/{id} - gets sale by ID
 func (sh *SaleHandler) FindByID(w http.ResponseWriter, r *http.Request) {
 	// Get sale ID from URL parameter
@@ -86,7 +119,7 @@ func (sh *SaleHandler) FindByID(w http.ResponseWriter, r *http.Request) {
 	// Call service to get sale
 	saleData, err := sh.service.Sale.GetSaleByID(r.Context(), saleID)
 	if err != nil {
-		sh.log.Error("Failed to get sale", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to get sale", zap.Error(err))
 		utils.ResponseError(w, http.StatusNotFound, "Sale not found", nil)
 		return
 	}
@@ -94,6 +127,278 @@ func (sh *SaleHandler) FindByID(w http.ResponseWriter, r *http.Request) {
 	utils.ResponseSuccess(w, http.StatusOK, "Sale retrieved successfully", saleData)
 }
 
+// FindByInvoice handles GET /api/sales/invoice/{invoice_number} - gets sale by invoice number
+func (sh *SaleHandler) FindByInvoice(w http.ResponseWriter, r *http.Request) {
+	invoiceNumber := chi.URLParam(r, "invoice_number")
+	if invoiceNumber == "" {
+		utils.ResponseError(w, http.StatusBadRequest, "Invoice number is required", nil)
+		return
+	}
+
+	// Call service to get sale
+	saleData, err := sh.service.Sale.GetSaleByInvoice(r.Context(), invoiceNumber)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get sale by invoice", zap.Error(err))
+		utils.ResponseError(w, http.StatusNotFound, "Sale not found", nil)
+		return
+	}
+
+	// Ownership check: staff hanya boleh lihat sale miliknya sendiri
+	// (AllowSelfOrAdmin butuh UUID di {id}, jadi dicek di sini)
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || (user.IsStaff() && saleData.UserID != user.ID.String()) {
+		utils.ResponseError(w, http.StatusForbidden, "Cannot access other user's sale", nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Sale retrieved successfully", saleData)
+}
+
+// Reorder handles POST /api/sales/{id}/reorder - creates a new sale from an existing sale's items
+func (sh *SaleHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	saleIDStr := chi.URLParam(r, "id")
+	saleID, err := uuid.Parse(saleIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid sale ID format", nil)
+		return
+	}
+
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		utils.ResponseError(w, http.StatusUnauthorized, "Authentication required", nil)
+		return
+	}
+
+	// Ownership check: staff hanya boleh reorder sale miliknya sendiri
+	sourceSale, err := sh.service.Sale.GetSaleByID(r.Context(), saleID)
+	if err != nil {
+		utils.ResponseError(w, http.StatusNotFound, "Sale not found", nil)
+		return
+	}
+	if user.IsStaff() && sourceSale.UserID != user.ID.String() {
+		utils.ResponseError(w, http.StatusForbidden, "Cannot access other user's sale", nil)
+		return
+	}
+
+	// Call service, sale baru milik user yang request
+	newSale, err := sh.service.Sale.Reorder(r.Context(), saleID, user.ID)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to reorder sale", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "sale not found" {
+			statusCode = http.StatusNotFound
+		} else if strings.HasPrefix(err.Error(), "cannot reorder") {
+			statusCode = http.StatusConflict
+		} else if strings.Contains(err.Error(), "insufficient stock") {
+			statusCode = http.StatusConflict
+		} else if strings.Contains(err.Error(), "is discontinued") || strings.HasPrefix(err.Error(), "too many items") {
+			statusCode = http.StatusUnprocessableEntity
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "Sale reordered successfully", newSale)
+}
+
+// Export handles GET /api/sales/{id}/export - self-contained sale snapshot for archiving
+func (sh *SaleHandler) Export(w http.ResponseWriter, r *http.Request) {
+	saleIDStr := chi.URLParam(r, "id")
+	saleID, err := uuid.Parse(saleIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid sale ID format", nil)
+		return
+	}
+
+	export, err := sh.service.Sale.ExportSale(r.Context(), saleID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "sale not found" {
+			statusCode = http.StatusNotFound
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to export sale", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to export sale", err.Error())
+		return
+	}
+
+	// Ownership check: staff hanya boleh export sale miliknya sendiri
+	// (AllowSelfOrAdmin butuh user ID di {id}, jadi dicek di sini)
+	if !canAccessSale(r, export.CashierID) {
+		utils.ResponseError(w, http.StatusForbidden, "Cannot access other user's sale", nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Sale exported successfully", export)
+}
+
+// FullExport handles GET /api/admin/sales/full-export?start_date=&end_date=&format=csv|json
+// Semua sale + item (import ERP), di-stream supaya periode besar tidak di-load ke memory.
+// csv = satu baris per item (data sale diulang), json = array sale dengan items
+func (sh *SaleHandler) FullExport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		utils.ResponseError(w, http.StatusBadRequest,
+			"Invalid format parameter. Must be: csv or json", nil)
+		return
+	}
+
+	req := sale.SaleFullExportRequest{
+		StartDate: r.URL.Query().Get("start_date"),
+		EndDate:   r.URL.Query().Get("end_date"),
+	}
+	filename := "sales-export-" + req.StartDate + "-" + req.EndDate
+
+	// Header response baru ditulis saat sale pertama datang (atau setelah selesai kalau kosong),
+	// supaya error validasi masih bisa dikirim sebagai JSON biasa
+	started := false
+	var err error
+
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+
+		writeHeader := func() {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Disposition", "attachment; filename="+filename+".json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("["))
+			started = true
+		}
+
+		err = sh.service.Sale.StreamFullExport(r.Context(), req, func(export *sale.SaleExportResponse) error {
+			if !started {
+				writeHeader()
+			} else {
+				w.Write([]byte(","))
+			}
+			return encoder.Encode(export)
+		})
+		if err == nil {
+			if !started {
+				writeHeader()
+			}
+			w.Write([]byte("]"))
+		}
+	} else {
+		writer := csv.NewWriter(w)
+
+		writeHeader := func() {
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Disposition", "attachment; filename="+filename+".csv")
+			w.WriteHeader(http.StatusOK)
+			writer.Write([]string{
+				"sale_id", "invoice_number", "status", "payment_method", "customer_name", "customer_phone",
+				"created_at", "cashier_id", "cashier_username", "cashier_name", "currency", "total_amount",
+				"item_id", "product_id", "product_name", "category_id", "category_name",
+				"quantity", "unit_price", "total_price", "notes",
+			})
+			started = true
+		}
+
+		err = sh.service.Sale.StreamFullExport(r.Context(), req, func(export *sale.SaleExportResponse) error {
+			if !started {
+				writeHeader()
+			}
+			for _, item := range export.Items {
+				if err := writer.Write([]string{
+					export.SaleID,
+					export.InvoiceNumber,
+					export.Status,
+					export.PaymentMethod,
+					optionalField(export.CustomerName),
+					optionalField(export.CustomerPhone),
+					export.CreatedAt.Format(time.RFC3339),
+					export.CashierID,
+					export.CashierUsername,
+					export.CashierName,
+					export.Currency,
+					formatAmount(export.TotalAmount),
+					item.ItemID,
+					item.ProductID,
+					item.ProductName,
+					item.CategoryID,
+					item.CategoryName,
+					strconv.Itoa(item.Quantity),
+					formatAmount(item.UnitPrice),
+					formatAmount(item.TotalPrice),
+					item.Notes,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err == nil && !started {
+			writeHeader()
+		}
+		writer.Flush()
+	}
+
+	if err != nil {
+		if !started {
+			statusCode := http.StatusInternalServerError
+			if strings.Contains(err.Error(), "validation") ||
+				strings.Contains(err.Error(), "invalid") ||
+				strings.Contains(err.Error(), "start date") ||
+				strings.Contains(err.Error(), "date range") {
+				statusCode = http.StatusBadRequest
+			} else {
+				utils.LoggerFromContext(r.Context()).Error("Failed to export sales", zap.Error(err))
+			}
+			utils.ResponseError(w, statusCode, "Failed to export sales", err.Error())
+			return
+		}
+		// Stream sudah jalan, status 200 sudah terkirim: hanya bisa dicatat di log
+		utils.LoggerFromContext(r.Context()).Error("Sales export interrupted", zap.Error(err))
+	}
+}
+
+// optionalField helper: nil = kolom CSV kosong
+func optionalField(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// MyDailySales handles GET /api/sales/my-daily - daily series of the caller's own sales
+func (sh *SaleHandler) MyDailySales(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		utils.ResponseError(w, http.StatusUnauthorized, "Authentication required", nil)
+		return
+	}
+
+	req := sale.MyDailySalesRequest{
+		StartDate: r.URL.Query().Get("start_date"),
+		EndDate:   r.URL.Query().Get("end_date"),
+	}
+
+	result, err := sh.service.Sale.GetMyDailySales(r.Context(), user.ID, req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") ||
+			strings.Contains(err.Error(), "date range") {
+			statusCode = http.StatusBadRequest
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to get daily sales", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to get daily sales", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Daily sales retrieved", result)
+}
+
 // FindAll handles GET /api/sales - gets all sales with pagination
 func (sh *SaleHandler) FindAll(w http.ResponseWriter, r *http.Request) {
 	// Get pagination parameters from query string
@@ -138,10 +443,34 @@ func (sh *SaleHandler) FindAll(w http.ResponseWriter, r *http.Request) {
 		userID = &user.ID
 	}
 
+	// Optional filters: status, start_date, end_date, min_amount, max_amount
+	query := r.URL.Query()
+	filter := sale.SaleListRequest{
+		Status:    query.Get("status"),
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+	}
+	var err error
+	if filter.MinAmount, err = parseAmountParam(query.Get("min_amount")); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid min_amount parameter", nil)
+		return
+	}
+	if filter.MaxAmount, err = parseAmountParam(query.Get("max_amount")); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid max_amount parameter", nil)
+		return
+	}
+
 	// Call service to get sales
-	sales, pagination, err := sh.service.Sale.GetAllSales(r.Context(), userID, page, limit)
+	sales, pagination, err := sh.service.Sale.GetAllSales(r.Context(), userID, filter, page, limit)
 	if err != nil {
-		sh.log.Error("Failed to get sales", zap.Error(err))
+		if strings.Contains(err.Error(), "validation failed") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") {
+			utils.ResponseError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+
+		utils.LoggerFromContext(r.Context()).Error("Failed to get sales", zap.Error(err))
 		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve sales", nil)
 		return
 	}
@@ -155,6 +484,142 @@ func (sh *SaleHandler) FindAll(w http.ResponseWriter, r *http.Request) {
 	utils.ResponseSuccess(w, http.StatusOK, "Sales retrieved successfully", response)
 }
 
+// Count handles GET /api/sales/count - jumlah sale saja untuk badge
+// Filter sama dengan FindAll, staff hanya menghitung sale miliknya sendiri
+func (sh *SaleHandler) Count(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	var userID *uuid.UUID
+	if user != nil && user.IsStaff() {
+		userID = &user.ID
+	}
+
+	query := r.URL.Query()
+	filter := sale.SaleListRequest{
+		Status:    query.Get("status"),
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+	}
+	var err error
+	if filter.MinAmount, err = parseAmountParam(query.Get("min_amount")); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid min_amount parameter", nil)
+		return
+	}
+	if filter.MaxAmount, err = parseAmountParam(query.Get("max_amount")); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid max_amount parameter", nil)
+		return
+	}
+
+	count, err := sh.service.Sale.CountSales(r.Context(), userID, filter)
+	if err != nil {
+		if strings.Contains(err.Error(), "validation failed") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") {
+			utils.ResponseError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+
+		utils.LoggerFromContext(r.Context()).Error("Failed to count sales", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, "Failed to count sales", nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Sales counted successfully", count)
+}
+
+// StatusBreakdown handles GET /api/admin/sales/status-breakdown - jumlah sale per status (admin only)
+func (sh *SaleHandler) StatusBreakdown(w http.ResponseWriter, r *http.Request) {
+	req := sale.SaleStatusBreakdownRequest{
+		StartDate: r.URL.Query().Get("start_date"),
+		EndDate:   r.URL.Query().Get("end_date"),
+	}
+
+	breakdown, err := sh.service.Sale.GetStatusBreakdown(r.Context(), req)
+	if err != nil {
+		if strings.Contains(err.Error(), "validation failed") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") ||
+			strings.Contains(err.Error(), "date range") {
+			utils.ResponseError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+
+		utils.LoggerFromContext(r.Context()).Error("Failed to get sale status breakdown", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve sale status breakdown", nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Sale status breakdown retrieved", breakdown)
+}
+
+// SaleItems handles GET /api/admin/sale-items - flat ledger of every sale line (admin only)
+func (sh *SaleHandler) SaleItems(w http.ResponseWriter, r *http.Request) {
+	// Get pagination parameters from query string
+	pageStr := r.URL.Query().Get("page")
+	limitStr := r.URL.Query().Get("limit")
+
+	// Set default values
+	page := 1
+	limit := 10
+
+	// Parse page parameter
+	if pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		} else {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid page parameter", nil)
+			return
+		}
+	}
+
+	// Parse limit parameter
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		} else {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid limit parameter (max 100)", nil)
+			return
+		}
+	}
+
+	// Cancelled sales excluded by default
+	includeCancelled := false
+	if v := r.URL.Query().Get("include_cancelled"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid include_cancelled parameter", nil)
+			return
+		}
+		includeCancelled = b
+	}
+
+	req := sale.SaleItemLedgerRequest{
+		StartDate:        r.URL.Query().Get("start_date"),
+		EndDate:          r.URL.Query().Get("end_date"),
+		IncludeCancelled: includeCancelled,
+	}
+
+	items, pagination, err := sh.service.Sale.GetSaleItemLedger(r.Context(), req, page, limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "validation failed") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "start date") {
+			utils.ResponseError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+
+		utils.LoggerFromContext(r.Context()).Error("Failed to get sale items", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve sale items", nil)
+		return
+	}
+
+	response := map[string]interface{}{
+		"items":      items,
+		"pagination": pagination,
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Sale items retrieved successfully", response)
+}
+
 // UpdateStatus handles PUT /api/sales/{id}/status - updates sale status
 func (sh *SaleHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 	// Get sale ID from URL
@@ -182,11 +647,16 @@ func (sh *SaleHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 	// Call service to update status
 	updatedSale, err := sh.service.Sale.UpdateSaleStatus(r.Context(), saleID, req)
 	if err != nil {
-		sh.log.Error("Failed to update sale status", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to update sale status", zap.Error(err))
 
 		statusCode := http.StatusBadRequest
-		if err.Error() == "not found" {
+		if err.Error() == "sale not found" {
 			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "reason is required") {
+			statusCode = http.StatusUnprocessableEntity
+		} else if strings.HasPrefix(err.Error(), "failed to cancel sale") {
+			// Stok gagal dikembalikan, seluruh cancellation di-rollback
+			statusCode = http.StatusInternalServerError
 		}
 
 		utils.ResponseError(w, statusCode, err.Error(), nil)
@@ -195,3 +665,151 @@ func (sh *SaleHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 
 	utils.ResponseSuccess(w, http.StatusOK, "Sale status updated successfully", updatedSale)
 }
+
+// UpdatePayment handles PUT /api/sales/{id}/payment - records amount paid, payment_status derived
+func (sh *SaleHandler) UpdatePayment(w http.ResponseWriter, r *http.Request) {
+	saleIDStr := chi.URLParam(r, "id")
+	saleID, err := uuid.Parse(saleIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid sale ID format", nil)
+		return
+	}
+
+	var req sale.UpdateSalePaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Ownership check: kasir hanya boleh catat pembayaran sale miliknya sendiri
+	existingSale, err := sh.service.Sale.GetSaleByID(r.Context(), saleID)
+	if err != nil {
+		utils.ResponseError(w, http.StatusNotFound, "Sale not found", nil)
+		return
+	}
+	if !canAccessSale(r, existingSale.UserID) {
+		utils.ResponseError(w, http.StatusForbidden, "Cannot access other user's sale", nil)
+		return
+	}
+
+	updatedSale, err := sh.service.Sale.UpdatePayment(r.Context(), saleID, req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to update sale payment", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "sale not found" {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "validation failed") ||
+			strings.Contains(err.Error(), "cannot exceed") {
+			statusCode = http.StatusUnprocessableEntity
+		} else if strings.Contains(err.Error(), "cancelled sale") {
+			statusCode = http.StatusConflict
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Sale payment updated successfully", updatedSale)
+}
+
+// Recalculate handles POST /api/admin/sales/{id}/recalculate - fixes total_amount from items
+func (sh *SaleHandler) Recalculate(w http.ResponseWriter, r *http.Request) {
+	// Get sale ID from URL
+	saleIDStr := chi.URLParam(r, "id")
+	saleID, err := uuid.Parse(saleIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid sale ID format", nil)
+		return
+	}
+
+	result, err := sh.service.Sale.RecalculateTotal(r.Context(), saleID)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to recalculate sale total", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "sale not found" {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "cancelled sale") {
+			statusCode = http.StatusConflict
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Sale total recalculated successfully", result)
+}
+
+// Reassign handles PUT /api/admin/sales/{id}/reassign - ganti kasir sale (koreksi admin)
+func (sh *SaleHandler) Reassign(w http.ResponseWriter, r *http.Request) {
+	// Get sale ID from URL
+	saleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid sale ID format", nil)
+		return
+	}
+
+	currentUser := middleware.GetUserFromContext(r.Context())
+	if currentUser == nil {
+		utils.ResponseError(w, http.StatusUnauthorized, "Authentication required", nil)
+		return
+	}
+
+	var req sale.ReassignSaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	result, err := sh.service.Sale.ReassignUser(r.Context(), saleID, req, currentUser.ID)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to reassign sale", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "sale not found" || err.Error() == "user not found" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "user is inactive" {
+			statusCode = http.StatusUnprocessableEntity
+		} else if strings.Contains(err.Error(), "validation failed") || strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Sale reassigned successfully", result)
+}
+
+// RecalculateAll handles POST /api/admin/sales/recalculate-all - fixes all inconsistent totals
+func (sh *SaleHandler) RecalculateAll(w http.ResponseWriter, r *http.Request) {
+	result, err := sh.service.Sale.RecalculateAllTotals(r.Context())
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to recalculate sale totals", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Sale totals recalculated successfully", result)
+}
+
+// parseAmountParam parse query param nominal opsional (kosong = nil)
+func parseAmountParam(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &amount, nil
+}
+
+// canAccessSale staff hanya boleh akses sale miliknya, admin & super_admin semua sale
+func canAccessSale(r *http.Request, saleUserID string) bool {
+	user := middleware.GetUserFromContext(r.Context())
+	return user != nil && (!user.IsStaff() || saleUserID == user.ID.String())
+}
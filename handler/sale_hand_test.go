@@ -0,0 +1,427 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"inventory-system/dto/sale"
+	"inventory-system/model"
+	"inventory-system/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKES ==========
+
+// fakeSaleService - SaleService dengan hasil/error yang bisa diatur per test
+type fakeSaleService struct {
+	service.SaleService
+	sale        *sale.SaleResponse
+	export      *sale.SaleExportResponse
+	err         error
+	updateCalls int
+
+	// countUser & countReq argumen terakhir CountSales
+	countUser *uuid.UUID
+	countReq  sale.SaleListRequest
+}
+
+func (f *fakeSaleService) GetSaleByID(ctx context.Context, id uuid.UUID) (*sale.SaleResponse, error) {
+	if f.sale == nil {
+		return nil, fmt.Errorf("sale not found")
+	}
+	return f.sale, nil
+}
+
+func (f *fakeSaleService) GetSaleByInvoice(ctx context.Context, invoiceNumber string) (*sale.SaleResponse, error) {
+	return f.GetSaleByID(ctx, uuid.Nil)
+}
+
+func (f *fakeSaleService) ExportSale(ctx context.Context, id uuid.UUID) (*sale.SaleExportResponse, error) {
+	if f.export == nil {
+		return nil, fmt.Errorf("sale not found")
+	}
+	return f.export, nil
+}
+
+func (f *fakeSaleService) StreamFullExport(ctx context.Context, req sale.SaleFullExportRequest, fn func(export *sale.SaleExportResponse) error) error {
+	if f.err != nil {
+		return f.err
+	}
+	if f.export != nil {
+		return fn(f.export)
+	}
+	return nil
+}
+
+func (f *fakeSaleService) UpdateSaleStatus(ctx context.Context, id uuid.UUID, req sale.UpdateSaleStatusRequest) (*sale.SaleResponse, error) {
+	f.updateCalls++
+	return f.sale, f.err
+}
+
+func (f *fakeSaleService) UpdatePayment(ctx context.Context, id uuid.UUID, req sale.UpdateSalePaymentRequest) (*sale.SaleResponse, error) {
+	f.updateCalls++
+	return f.sale, f.err
+}
+
+func (f *fakeSaleService) CountSales(ctx context.Context, userID *uuid.UUID, req sale.SaleListRequest) (*sale.SaleCountResponse, error) {
+	f.countUser, f.countReq = userID, req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &sale.SaleCountResponse{Count: 7}, nil
+}
+
+func newTestSaleHandler(svc *fakeSaleService) *SaleHandler {
+	return NewSaleHandler(&service.Service{Sale: svc}, zap.NewNop())
+}
+
+// ========== OWNERSHIP ==========
+
+func TestCanAccessSale(t *testing.T) {
+	staff := newUser(model.RoleStaff)
+	admin := newUser(model.RoleAdmin)
+	superAdmin := newUser(model.RoleSuperAdmin)
+	otherID := uuid.NewString()
+
+	tests := []struct {
+		name       string
+		user       *model.User
+		saleUserID string
+		want       bool
+	}{
+		{name: "staff own sale", user: staff, saleUserID: staff.ID.String(), want: true},
+		{name: "staff other sale", user: staff, saleUserID: otherID, want: false},
+		{name: "admin other sale", user: admin, saleUserID: otherID, want: true},
+		{name: "super admin other sale", user: superAdmin, saleUserID: otherID, want: true},
+		{name: "no user", user: nil, saleUserID: otherID, want: false},
+	}
+
+	for _, tt := range tests {
+		r := newRequest(http.MethodGet, "/", "", tt.user, nil)
+		if got := canAccessSale(r, tt.saleUserID); got != tt.want {
+			t.Errorf("%s: canAccessSale() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSaleExportOwnership(t *testing.T) {
+	staff := newUser(model.RoleStaff)
+	admin := newUser(model.RoleAdmin)
+	export := &sale.SaleExportResponse{SaleID: uuid.NewString(), CashierID: staff.ID.String()}
+
+	tests := []struct {
+		name       string
+		user       *model.User
+		id         string
+		export     *sale.SaleExportResponse
+		wantStatus int
+	}{
+		{name: "cashier exports own sale", user: staff, id: export.SaleID, export: export, wantStatus: http.StatusOK},
+		{name: "admin exports any sale", user: admin, id: export.SaleID, export: export, wantStatus: http.StatusOK},
+		{name: "other staff forbidden", user: newUser(model.RoleStaff), id: export.SaleID, export: export, wantStatus: http.StatusForbidden},
+		{name: "unknown sale", user: admin, id: uuid.NewString(), wantStatus: http.StatusNotFound},
+		{name: "invalid id", user: admin, id: "abc", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestSaleHandler(&fakeSaleService{export: tt.export})
+			w := httptest.NewRecorder()
+			h.Export(w, newRequest(http.MethodGet, "/api/sales/"+tt.id+"/export", "", tt.user, map[string]string{"id": tt.id}))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSaleFindByInvoiceOwnership(t *testing.T) {
+	staff := newUser(model.RoleStaff)
+	found := &sale.SaleResponse{UserID: staff.ID.String()}
+
+	tests := []struct {
+		name       string
+		user       *model.User
+		sale       *sale.SaleResponse
+		wantStatus int
+	}{
+		{name: "own sale", user: staff, sale: found, wantStatus: http.StatusOK},
+		{name: "admin", user: newUser(model.RoleAdmin), sale: found, wantStatus: http.StatusOK},
+		{name: "other staff", user: newUser(model.RoleStaff), sale: found, wantStatus: http.StatusForbidden},
+		{name: "not found", user: staff, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		h := newTestSaleHandler(&fakeSaleService{sale: tt.sale})
+		w := httptest.NewRecorder()
+		h.FindByInvoice(w, newRequest(http.MethodGet, "/", "", tt.user, map[string]string{"invoice_number": "INV-1"}))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+}
+
+// ========== UPDATE STATUS / PAYMENT ==========
+
+func TestSaleUpdateStatusErrorMapping(t *testing.T) {
+	admin := newUser(model.RoleAdmin)
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+		wantCalls  int
+	}{
+		{name: "success", body: `{"status":"completed"}`, wantStatus: http.StatusOK, wantCalls: 1},
+		{name: "invalid body", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "invalid status", body: `{"status":"refunded"}`, wantStatus: http.StatusBadRequest},
+		{name: "not found", body: `{"status":"completed"}`, err: fmt.Errorf("sale not found"), wantStatus: http.StatusNotFound, wantCalls: 1},
+		{name: "cancel without reason", body: `{"status":"cancelled"}`, err: fmt.Errorf("validation failed: reason is required when cancelling a sale"), wantStatus: http.StatusUnprocessableEntity, wantCalls: 1},
+		{name: "reopen cancelled sale", body: `{"status":"pending"}`, err: fmt.Errorf("cannot change status of a cancelled sale"), wantStatus: http.StatusBadRequest, wantCalls: 1},
+		{name: "stock restore failed", body: `{"status":"cancelled","reason":"x"}`, err: fmt.Errorf("failed to cancel sale: boom"), wantStatus: http.StatusInternalServerError, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeSaleService{sale: &sale.SaleResponse{}, err: tt.err}
+			h := newTestSaleHandler(svc)
+			id := uuid.NewString()
+			w := httptest.NewRecorder()
+			h.UpdateStatus(w, newRequest(http.MethodPut, "/", tt.body, admin, map[string]string{"id": id}))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if svc.updateCalls != tt.wantCalls {
+				t.Errorf("service calls = %d, want %d", svc.updateCalls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestSaleUpdatePayment(t *testing.T) {
+	staff := newUser(model.RoleStaff)
+	ownSale := &sale.SaleResponse{UserID: staff.ID.String()}
+
+	tests := []struct {
+		name       string
+		user       *model.User
+		sale       *sale.SaleResponse
+		err        error
+		wantStatus int
+		wantCalls  int
+	}{
+		{name: "cashier own sale", user: staff, sale: ownSale, wantStatus: http.StatusOK, wantCalls: 1},
+		{name: "other cashier forbidden", user: newUser(model.RoleStaff), sale: ownSale, wantStatus: http.StatusForbidden},
+		{name: "admin any sale", user: newUser(model.RoleAdmin), sale: ownSale, wantStatus: http.StatusOK, wantCalls: 1},
+		{name: "unknown sale", user: staff, wantStatus: http.StatusNotFound},
+		{name: "overpayment", user: staff, sale: ownSale, err: fmt.Errorf("amount paid cannot exceed total amount (30.00)"), wantStatus: http.StatusUnprocessableEntity, wantCalls: 1},
+		{name: "cancelled sale", user: staff, sale: ownSale, err: fmt.Errorf("cannot record payment for a cancelled sale"), wantStatus: http.StatusConflict, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeSaleService{sale: tt.sale, err: tt.err}
+			h := newTestSaleHandler(svc)
+			id := uuid.NewString()
+			w := httptest.NewRecorder()
+			h.UpdatePayment(w, newRequest(http.MethodPut, "/", `{"amount_paid":10}`, tt.user, map[string]string{"id": id}))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if svc.updateCalls != tt.wantCalls {
+				t.Errorf("service calls = %d, want %d", svc.updateCalls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// ========== FULL EXPORT ==========
+
+func TestSaleFullExport(t *testing.T) {
+	export := &sale.SaleExportResponse{
+		SaleID:        "sale-1",
+		InvoiceNumber: "INV-1",
+		Status:        "completed",
+		PaymentMethod: "cash",
+		CustomerName:  stringPtr("Budi"),
+		CreatedAt:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Currency:      "IDR",
+		TotalAmount:   30,
+		Items: []sale.SaleExportItem{
+			{ItemID: "item-1", ProductName: "Coffee", Quantity: 2, UnitPrice: 10, TotalPrice: 20},
+			{ItemID: "item-2", ProductName: "Tea", Quantity: 1, UnitPrice: 10.5, TotalPrice: 10.5, Notes: "less sugar"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		export     *sale.SaleExportResponse
+		err        error
+		wantStatus int
+		wantRows   int
+	}{
+		{name: "csv one row per item", query: "format=csv", export: export, wantStatus: http.StatusOK, wantRows: 3},
+		{name: "csv default format", query: "", export: export, wantStatus: http.StatusOK, wantRows: 3},
+		{name: "csv empty period still has header", query: "format=csv", wantStatus: http.StatusOK, wantRows: 1},
+		{name: "invalid format", query: "format=xml", wantStatus: http.StatusBadRequest},
+		{name: "date range error", query: "format=csv", err: fmt.Errorf("date range cannot exceed 1 year"), wantStatus: http.StatusBadRequest},
+		{name: "repository error", query: "format=json", err: fmt.Errorf("failed to export sales"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestSaleHandler(&fakeSaleService{export: tt.export, err: tt.err})
+			w := httptest.NewRecorder()
+			h.FullExport(w, newRequest(http.MethodGet, "/?start_date=2026-01-01&end_date=2026-01-31&"+tt.query, "", nil, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantRows == 0 {
+				return
+			}
+
+			if got := w.Header().Get("Content-Disposition"); got != "attachment; filename=sales-export-2026-01-01-2026-01-31.csv" {
+				t.Errorf("Content-Disposition = %q", got)
+			}
+			rows, err := csv.NewReader(w.Body).ReadAll()
+			if err != nil {
+				t.Fatalf("invalid csv: %v", err)
+			}
+			if len(rows) != tt.wantRows {
+				t.Fatalf("rows = %d, want %d", len(rows), tt.wantRows)
+			}
+			if rows[0][0] != "sale_id" || rows[0][len(rows[0])-1] != "notes" {
+				t.Errorf("header = %v", rows[0])
+			}
+			if tt.wantRows == 3 {
+				last := rows[2]
+				if last[1] != "INV-1" || last[4] != "Budi" || last[5] != "" || last[14] != "Tea" || last[18] != "10.50" || last[20] != "less sugar" {
+					t.Errorf("item row = %v", last)
+				}
+			}
+		})
+	}
+}
+
+func TestSaleFullExportJSON(t *testing.T) {
+	h := newTestSaleHandler(&fakeSaleService{export: &sale.SaleExportResponse{SaleID: "sale-1"}})
+	w := httptest.NewRecorder()
+	h.FullExport(w, newRequest(http.MethodGet, "/?format=json&start_date=2026-01-01&end_date=2026-01-31", "", nil, nil))
+
+	var exports []sale.SaleExportResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &exports); err != nil {
+		t.Fatalf("invalid json array: %v (%s)", err, w.Body.String())
+	}
+	if len(exports) != 1 || exports[0].SaleID != "sale-1" {
+		t.Errorf("exports = %+v", exports)
+	}
+
+	// Periode kosong tetap array JSON valid
+	h = newTestSaleHandler(&fakeSaleService{})
+	w = httptest.NewRecorder()
+	h.FullExport(w, newRequest(http.MethodGet, "/?format=json&start_date=2026-01-01&end_date=2026-01-31", "", nil, nil))
+	if strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("empty export = %q, want []", w.Body.String())
+	}
+}
+
+// ========== HELPERS ==========
+
+// ========== COUNT ==========
+
+func TestSaleCountHandler(t *testing.T) {
+	staff := newUser(model.RoleStaff)
+	target := "/?status=completed&start_date=2024-03-01&end_date=2024-03-31"
+
+	tests := []struct {
+		name     string
+		user     *model.User
+		wantUser *uuid.UUID
+	}{
+		{name: "staff counts own sales", user: staff, wantUser: &staff.ID},
+		{name: "admin counts all sales", user: newUser(model.RoleAdmin)},
+		{name: "super admin counts all sales", user: newUser(model.RoleSuperAdmin)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeSaleService{}
+			w := httptest.NewRecorder()
+			newTestSaleHandler(svc).Count(w, newRequest(http.MethodGet, target, "", tt.user, nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200 (%s)", w.Code, w.Body.String())
+			}
+			if (svc.countUser == nil) != (tt.wantUser == nil) || (svc.countUser != nil && *svc.countUser != *tt.wantUser) {
+				t.Errorf("count user = %v, want %v", svc.countUser, tt.wantUser)
+			}
+			if svc.countReq.Status != "completed" || svc.countReq.StartDate != "2024-03-01" || svc.countReq.EndDate != "2024-03-31" {
+				t.Errorf("filter = %+v, want status and date range", svc.countReq)
+			}
+
+			var resp struct {
+				Data sale.SaleCountResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Data.Count != 7 {
+				t.Errorf("body = %s, want count 7", w.Body.String())
+			}
+		})
+	}
+
+	t.Run("invalid range", func(t *testing.T) {
+		svc := &fakeSaleService{err: fmt.Errorf("start date cannot be after end date")}
+		w := httptest.NewRecorder()
+		newTestSaleHandler(svc).Count(w, newRequest(http.MethodGet, "/?start_date=2024-03-31&end_date=2024-03-01", "", staff, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", w.Code)
+		}
+	})
+}
+
+func TestParseAmountParam(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    *float64
+		wantErr bool
+	}{
+		{value: "", want: nil},
+		{value: "150000", want: floatPtr(150000)},
+		{value: "99.5", want: floatPtr(99.5)},
+		{value: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAmountParam(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAmountParam(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("parseAmountParam(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestOptionalField(t *testing.T) {
+	if got := optionalField(nil); got != "" {
+		t.Errorf("optionalField(nil) = %q, want empty", got)
+	}
+	if got := optionalField(stringPtr("Budi")); got != "Budi" {
+		t.Errorf("optionalField(Budi) = %q", got)
+	}
+}
@@ -39,7 +39,7 @@ func (sh *ShelfHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// Call Service
 	createdShelf, err := sh.service.Shelf.Create(r.Context(), req)
 	if err != nil {
-		sh.log.Error("Failed to create shelf", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to create shelf", zap.Error(err))
 
 		statusCode := http.StatusBadRequest
 		if strings.Contains(err.Error(), "already exists") {
@@ -100,10 +100,16 @@ func (sh *ShelfHandler) FindAll(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Optional: ?include_deleted=true (admin only)
+	includeDeleted, ok := parseIncludeDeleted(w, r)
+	if !ok {
+		return
+	}
+
 	// Call service
-	shelves, pagination, err := sh.service.Shelf.FindAll(r.Context(), page, limit)
+	shelves, pagination, err := sh.service.Shelf.FindAll(r.Context(), page, limit, includeDeleted)
 	if err != nil {
-		sh.log.Error("Failed to get shelves", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to get shelves", zap.Error(err))
 		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve shelves", nil)
 		return
 	}
@@ -125,13 +131,75 @@ func (sh *ShelfHandler) FindByWarehouseID(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	shelves, err := sh.service.Shelf.FindByWarehouseID(r.Context(), warehouseID)
+	// ?all=true: semua rak tanpa pagination (untuk dropdown)
+	all := false
+	if v := r.URL.Query().Get("all"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid all parameter", nil)
+			return
+		}
+		all = b
+	}
+
+	if all {
+		shelves, err := sh.service.Shelf.FindByWarehouseID(r.Context(), warehouseID)
+		if err != nil {
+			utils.ResponseError(w, http.StatusInternalServerError, "Failed to get shelves", err.Error())
+			return
+		}
+
+		utils.ResponseSuccess(w, http.StatusOK, "Shelves retrivied", shelves)
+		return
+	}
+
+	// Get pagination parameters
+	pageStr := r.URL.Query().Get("page")
+	limitStr := r.URL.Query().Get("limit")
+
+	// Default values
+	page := 1
+	limit := 10
+
+	// Parse page
+	if pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		} else {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid page parameter", nil)
+			return
+		}
+	}
+
+	// Parse limit
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		} else {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid limit parameter (max 100)", nil)
+			return
+		}
+	}
+
+	search := r.URL.Query().Get("search")
+
+	shelves, pagination, err := sh.service.Shelf.FindByWarehouseIDPaginated(r.Context(), warehouseID, page, limit, search)
 	if err != nil {
+		if err.Error() == "warehouse not found" {
+			utils.ResponseError(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
 		utils.ResponseError(w, http.StatusInternalServerError, "Failed to get shelves", err.Error())
 		return
 	}
 
-	utils.ResponseSuccess(w, http.StatusOK, "Shelves retrivied", shelves)
+	// Response with pagination
+	response := map[string]interface{}{
+		"shelves":    shelves,
+		"pagination": pagination,
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Shelves retrivied", response)
 }
 
 func (sh *ShelfHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -152,8 +220,16 @@ func (sh *ShelfHandler) Update(w http.ResponseWriter, r *http.Request) {
 	// Call Service
 	updatedShelf, err := sh.service.Shelf.Update(r.Context(), shelfID, req)
 	if err != nil {
-		sh.log.Error("Failed to update shelf", zap.Error(err))
-		utils.ResponseError(w, http.StatusBadRequest, "Failed to update shelf", nil)
+		utils.LoggerFromContext(r.Context()).Error("Failed to update shelf", zap.Error(err))
+
+		statusCode := http.StatusBadRequest
+		if strings.Contains(err.Error(), "already exists") {
+			statusCode = http.StatusConflict
+		} else if strings.Contains(err.Error(), "not found") {
+			statusCode = http.StatusNotFound
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to update shelf", err.Error())
 		return
 	}
 
@@ -176,3 +252,42 @@ func (sh *ShelfHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	utils.ResponseSuccess(w, http.StatusOK, "Shelf deleted successfully", nil)
 }
+
+// ========== BULK CREATE SHELVES ==========
+// POST /api/admin/warehouses/{id}/shelves/bulk
+// Body: [{"code": "A-01", "name": "Rak A 01"}, ...]
+func (sh *ShelfHandler) BulkCreate(w http.ResponseWriter, r *http.Request) {
+	warehouseIDStr := chi.URLParam(r, "id")
+	warehouseID, err := uuid.Parse(warehouseIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid warehouse ID", nil)
+		return
+	}
+
+	var items []shelf.BulkShelfItem
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Call Service
+	createdShelves, err := sh.service.Shelf.BulkCreate(r.Context(), warehouseID, shelf.BulkCreateShelfRequest{Shelves: items})
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to bulk create shelves", zap.Error(err))
+
+		statusCode := http.StatusBadRequest
+		if err.Error() == "warehouse not found" {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "already exists") {
+			statusCode = http.StatusConflict
+		} else if err.Error() == "failed to create shelves" || err.Error() == "failed to get shelves" {
+			statusCode = http.StatusInternalServerError
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "Shelves created successfully", createdShelves)
+}
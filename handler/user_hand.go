@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"inventory-system/dto/user"
 	"inventory-system/middleware"
@@ -10,6 +11,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -48,19 +50,8 @@ func (uh *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Rule 1: Admin cannot create Super Admin
-	if currentUser.Role == model.RoleAdmin && req.Role == string(model.RoleSuperAdmin) {
-		uh.log.Warn("Admin attempted to create super_admin",
-			zap.String("admin_id", currentUser.ID.String()),
-			zap.String("email", req.Email),
-		)
-
-		utils.ResponseError(w, http.StatusForbidden,
-			"Admin cannot create super_admin user", nil)
-		return
-	}
-
-	// Rule 2: Validate requested role is valid
+	// Rule: Validate requested role is valid
+	// (Aturan siapa boleh membuat role apa di-enforce di service)
 	validRoles := map[string]bool{
 		string(model.RoleSuperAdmin): true,
 		string(model.RoleAdmin):      true,
@@ -73,14 +64,16 @@ func (uh *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 	// ========== END PERMISSION VALIDATION ==========
 
-	// Call service (pure business logic)
-	createdUser, err := uh.service.User.Create(r.Context(), req)
+	// Call service (business logic + role enforcement)
+	createdUser, err := uh.service.User.Create(r.Context(), req, currentUser)
 	if err != nil {
-		uh.log.Error("Failed to create user", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to create user", zap.Error(err))
 
 		statusCode := http.StatusBadRequest
 		if strings.Contains(err.Error(), "email already exists") {
 			statusCode = http.StatusConflict
+		} else if strings.Contains(err.Error(), "permission denied") {
+			statusCode = http.StatusForbidden
 		}
 
 		utils.ResponseError(w, statusCode, err.Error(), nil)
@@ -111,6 +104,24 @@ func (uh *UserHandler) FindByID(w http.ResponseWriter, r *http.Request) {
 	utils.ResponseSuccess(w, http.StatusOK, "User retrieved", userData)
 }
 
+// FIND USER BY EMAIL HANDLER
+// GET /api/admin/users/by-email?email=... (Admin & Super Admin only)
+func (uh *UserHandler) FindByEmail(w http.ResponseWriter, r *http.Request) {
+	req := user.FindByEmailRequest{Email: r.URL.Query().Get("email")}
+
+	userData, err := uh.service.User.FindByEmail(r.Context(), req)
+	if err != nil {
+		if strings.Contains(err.Error(), "validation failed") {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid email", err.Error())
+			return
+		}
+		utils.ResponseError(w, http.StatusNotFound, "User not found", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "User retrieved", userData)
+}
+
 // FIND ALL USERS HANDLER
 // GET /api/admin/users (Admin & Super Admin only)
 func (uh *UserHandler) FindAll(w http.ResponseWriter, r *http.Request) {
@@ -145,7 +156,7 @@ func (uh *UserHandler) FindAll(w http.ResponseWriter, r *http.Request) {
 	// Call service
 	users, pagination, err := uh.service.User.FindAll(r.Context(), page, limit)
 	if err != nil {
-		uh.log.Error("Failed to get users", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to get users", zap.Error(err))
 		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve users", nil)
 		return
 	}
@@ -159,6 +170,63 @@ func (uh *UserHandler) FindAll(w http.ResponseWriter, r *http.Request) {
 	utils.ResponseSuccess(w, http.StatusOK, "Users retrieved successfully", response)
 }
 
+// LOGIN HISTORY HANDLER
+// GET /api/admin/users/{id}/login-history?page=1&limit=10 (Admin & Super Admin)
+func (uh *UserHandler) LoginHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid user ID", nil)
+		return
+	}
+
+	// Get pagination parameters from query string
+	pageStr := r.URL.Query().Get("page")
+	limitStr := r.URL.Query().Get("limit")
+
+	// Default values
+	page := 1
+	limit := 10
+
+	// Parse page parameter
+	if pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		} else {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid page parameter", nil)
+			return
+		}
+	}
+
+	// Parse limit parameter
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		} else {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid limit parameter (max 100)", nil)
+			return
+		}
+	}
+
+	history, pagination, err := uh.service.User.GetLoginHistory(r.Context(), userID, page, limit)
+	if err != nil {
+		if err.Error() == "user not found" {
+			utils.ResponseError(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		utils.LoggerFromContext(r.Context()).Error("Failed to get login history", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve login history", nil)
+		return
+	}
+
+	// Response with pagination
+	response := map[string]interface{}{
+		"sessions":   history,
+		"pagination": pagination,
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Login history retrieved successfully", response)
+}
+
 // UPDATE USER HANDLER
 // PUT /api/users/{id} (All authenticated users)
 func (uh *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -190,23 +258,10 @@ func (uh *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Rule 1: Admin cannot change ANY user to Super Admin
-	if req.Role != nil && *req.Role == string(model.RoleSuperAdmin) {
-		if currentUser.Role == model.RoleAdmin {
-			uh.log.Warn("Admin attempted to update user to super_admin",
-				zap.String("admin_id", currentUser.ID.String()),
-				zap.String("target_user", targetUser.ID),
-			)
-
-			utils.ResponseError(w, http.StatusForbidden,
-				"Admin cannot change user role to super_admin", nil)
-			return
-		}
-	}
-
-	// Rule 2: Admin cannot modify Super Admin user at all
+	// Rule 1: Admin cannot modify Super Admin user at all
+	// (Perubahan role di-enforce di service via CanCreateUserWithRole)
 	if targetUser.Role == string(model.RoleSuperAdmin) && currentUser.Role == model.RoleAdmin {
-		uh.log.Warn("Admin attempted to modify super_admin",
+		utils.LoggerFromContext(r.Context()).Warn("Admin attempted to modify super_admin",
 			zap.String("admin_id", currentUser.ID.String()),
 			zap.String("super_admin_id", targetUser.ID),
 		)
@@ -216,7 +271,7 @@ func (uh *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Rule 3: Validate role if provided
+	// Rule 2: Validate role if provided
 	if req.Role != nil {
 		validRoles := map[string]bool{
 			string(model.RoleSuperAdmin): true,
@@ -231,11 +286,19 @@ func (uh *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	// ========== END PERMISSION VALIDATION ==========
 
-	// Call service (pure business logic)
-	updatedUser, err := uh.service.User.Update(r.Context(), userID, req)
+	// Call service (business logic + role enforcement)
+	updatedUser, err := uh.service.User.Update(r.Context(), userID, req, currentUser)
 	if err != nil {
-		uh.log.Error("Failed to update user", zap.Error(err))
-		utils.ResponseError(w, http.StatusBadRequest, "Failed to update user", err.Error())
+		utils.LoggerFromContext(r.Context()).Error("Failed to update user", zap.Error(err))
+
+		statusCode := http.StatusBadRequest
+		if strings.Contains(err.Error(), "permission denied") {
+			statusCode = http.StatusForbidden
+		} else if strings.Contains(err.Error(), "email already exists") {
+			statusCode = http.StatusConflict
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to update user", err.Error())
 		return
 	}
 
@@ -252,11 +315,145 @@ func (uh *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = uh.service.User.Delete(r.Context(), userID)
+	currentUser := middleware.GetUserFromContext(r.Context())
+	if currentUser == nil {
+		utils.ResponseError(w, http.StatusUnauthorized, "Authentication required", nil)
+		return
+	}
+
+	err = uh.service.User.Delete(r.Context(), userID, currentUser)
 	if err != nil {
-		utils.ResponseError(w, http.StatusBadRequest, "Failed to delete user", err.Error())
+		statusCode := http.StatusBadRequest
+		if strings.HasPrefix(err.Error(), "permission denied") {
+			statusCode = http.StatusForbidden
+		} else if err.Error() == "user not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to delete user", err.Error())
 		return
 	}
 
 	utils.ResponseSuccess(w, http.StatusOK, "User deleted successfully", nil)
 }
+
+// RESTORE USER HANDLER
+// POST /api/admin/users/{id}/restore (Super Admin only)
+func (uh *UserHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid user ID", nil)
+		return
+	}
+
+	currentUser := middleware.GetUserFromContext(r.Context())
+	if currentUser == nil {
+		utils.ResponseError(w, http.StatusUnauthorized, "Authentication required", nil)
+		return
+	}
+
+	restoredUser, err := uh.service.User.Restore(r.Context(), userID, currentUser)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if strings.HasPrefix(err.Error(), "permission denied") {
+			statusCode = http.StatusForbidden
+		} else if err.Error() == "deleted user not found" {
+			statusCode = http.StatusNotFound
+		} else {
+			utils.LoggerFromContext(r.Context()).Error("Failed to restore user", zap.Error(err))
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "User restored successfully", restoredUser)
+}
+
+// EXPORT USERS
+// GET /api/admin/users/export?format=csv|json
+// Response di-stream per row, password hash tidak pernah ikut
+func (uh *UserHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		utils.ResponseError(w, http.StatusBadRequest,
+			"Invalid format parameter. Must be: csv or json", nil)
+		return
+	}
+
+	filename := "users-" + time.Now().Format("20060102")
+
+	// Header response baru ditulis saat row pertama datang,
+	// supaya error sebelum streaming masih bisa dikirim sebagai JSON biasa
+	started := false
+	var err error
+
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+
+		writeHeader := func() {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Disposition", "attachment; filename="+filename+".json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("["))
+			started = true
+		}
+
+		err = uh.service.User.StreamExport(r.Context(), func(row user.UserExportRow) error {
+			if !started {
+				writeHeader()
+			} else {
+				w.Write([]byte(","))
+			}
+			return encoder.Encode(row)
+		})
+		if err == nil {
+			if !started {
+				writeHeader()
+			}
+			w.Write([]byte("]"))
+		}
+	} else {
+		writer := csv.NewWriter(w)
+
+		writeHeader := func() {
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Disposition", "attachment; filename="+filename+".csv")
+			w.WriteHeader(http.StatusOK)
+			writer.Write([]string{
+				"id", "username", "email", "full_name", "role", "is_active", "created_at",
+			})
+			started = true
+		}
+
+		err = uh.service.User.StreamExport(r.Context(), func(row user.UserExportRow) error {
+			if !started {
+				writeHeader()
+			}
+			return writer.Write([]string{
+				row.ID,
+				row.Username,
+				row.Email,
+				row.FullName,
+				row.Role,
+				strconv.FormatBool(row.IsActive),
+				row.CreatedAt.Format(time.RFC3339),
+			})
+		})
+		if err == nil && !started {
+			writeHeader()
+		}
+		writer.Flush()
+	}
+
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to export users", zap.Error(err))
+		if !started {
+			utils.ResponseError(w, http.StatusInternalServerError, "Failed to export users", err.Error())
+		}
+		return
+	}
+}
@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/dto/user"
+	"inventory-system/middleware"
+	"inventory-system/model"
+	"inventory-system/service"
+	"inventory-system/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type fakeUserService struct {
+	service.UserService
+	err        error
+	actor      *model.User
+	calls      int
+	targetRole model.UserRole
+}
+
+func (f *fakeUserService) Create(ctx context.Context, req user.CreateUserRequest, actor *model.User) (*user.UserResponse, error) {
+	f.calls++
+	f.actor = actor
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &user.UserResponse{}, nil
+}
+
+func (f *fakeUserService) Restore(ctx context.Context, id uuid.UUID, actor *model.User) (*user.UserResponse, error) {
+	f.calls++
+	f.actor = actor
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &user.UserResponse{}, nil
+}
+
+// FindByID target dengan role targetRole (kosong = staff)
+func (f *fakeUserService) FindByID(ctx context.Context, id uuid.UUID) (*user.UserResponse, error) {
+	role := f.targetRole
+	if role == "" {
+		role = model.RoleStaff
+	}
+	return &user.UserResponse{ID: id.String(), Role: string(role)}, nil
+}
+
+func (f *fakeUserService) Update(ctx context.Context, id uuid.UUID, req user.UpdateUserRequest, actor *model.User) (*user.UserResponse, error) {
+	f.calls++
+	f.actor = actor
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &user.UserResponse{ID: id.String()}, nil
+}
+
+func (f *fakeUserService) Delete(ctx context.Context, id uuid.UUID, actor *model.User) error {
+	f.calls++
+	f.actor = actor
+	return f.err
+}
+
+func (f *fakeUserService) StreamExport(ctx context.Context, fn func(row user.UserExportRow) error) error {
+	f.calls++
+	if f.err != nil {
+		return f.err
+	}
+	return fn(user.UserExportRow{ID: uuid.NewString(), Username: "kasir", Email: "kasir@example.com", FullName: "Kasir Satu", Role: "staff", IsActive: true})
+}
+
+func (f *fakeUserService) FindByEmail(ctx context.Context, req user.FindByEmailRequest) (*user.UserResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &user.UserResponse{Email: req.Email}, nil
+}
+
+func newTestUserHandler(svc *fakeUserService) *UserHandler {
+	return NewUserHandler(&service.Service{User: svc}, zap.NewNop())
+}
+
+func TestUserCreateHandler(t *testing.T) {
+	admin := newUser(model.RoleAdmin)
+
+	tests := []struct {
+		name       string
+		user       *model.User
+		body       string
+		err        error
+		wantStatus int
+		wantCalls  int
+	}{
+		{name: "created", user: admin, body: `{"role":"staff"}`, wantStatus: http.StatusCreated, wantCalls: 1},
+		{name: "malformed body", user: admin, body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "unauthenticated", user: nil, body: `{"role":"staff"}`, wantStatus: http.StatusUnauthorized},
+		{name: "unknown role", user: admin, body: `{"role":"owner"}`, wantStatus: http.StatusBadRequest},
+		{name: "role not allowed for actor", user: admin, body: `{"role":"super_admin"}`, err: fmt.Errorf("permission denied: cannot create user with role super_admin"), wantStatus: http.StatusForbidden, wantCalls: 1},
+		{name: "duplicate email", user: admin, body: `{"role":"staff"}`, err: fmt.Errorf("email already exists"), wantStatus: http.StatusConflict, wantCalls: 1},
+		{name: "validation", user: admin, body: `{"role":"staff"}`, err: fmt.Errorf("validation failed: Email is required"), wantStatus: http.StatusBadRequest, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeUserService{err: tt.err}
+			h := newTestUserHandler(svc)
+			w := httptest.NewRecorder()
+			h.Create(w, newRequest(http.MethodPost, "/", tt.body, tt.user, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if svc.calls != tt.wantCalls {
+				t.Fatalf("service calls = %d, want %d", svc.calls, tt.wantCalls)
+			}
+			// Role rule di-enforce service, jadi actor harus diteruskan
+			if tt.wantCalls > 0 && svc.actor != tt.user {
+				t.Error("actor not passed to service")
+			}
+		})
+	}
+}
+
+// Log dari handler harus lewat logger request-scoped yang dipasang middleware.Logger
+func TestUserCreateLogsRequestID(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	h := newTestUserHandler(&fakeUserService{err: fmt.Errorf("permission denied: cannot create user with role super_admin")})
+	chain := chimiddleware.RequestID(middleware.Logger(http.HandlerFunc(h.Create)))
+
+	r := newRequest(http.MethodPost, "/", `{"role":"super_admin"}`, newUser(model.RoleAdmin), nil)
+	r = r.WithContext(utils.WithLogger(r.Context(), zap.New(core)))
+	r.Header.Set(chimiddleware.RequestIDHeader, "req-create-1")
+	w := httptest.NewRecorder()
+	chain.ServeHTTP(w, r)
+
+	entries := logs.FilterMessage("Failed to create user").All()
+	if len(entries) != 1 {
+		t.Fatalf("log entries = %d, want 1", len(entries))
+	}
+	if got := entries[0].ContextMap()["request_id"]; got != "req-create-1" {
+		t.Errorf("request_id = %v, want req-create-1", got)
+	}
+}
+
+func TestUserUpdateHandler(t *testing.T) {
+	admin := newUser(model.RoleAdmin)
+
+	tests := []struct {
+		name       string
+		user       *model.User
+		targetRole model.UserRole
+		body       string
+		err        error
+		wantStatus int
+		wantCalls  int
+	}{
+		{name: "updated", user: admin, body: `{"full_name":"Kasir Dua"}`, wantStatus: http.StatusOK, wantCalls: 1},
+		{name: "admin demotes super admin", user: admin, targetRole: model.RoleSuperAdmin, body: `{"role":"staff"}`, wantStatus: http.StatusForbidden},
+		{name: "admin edits super admin email", user: admin, targetRole: model.RoleSuperAdmin, body: `{"email":"mine@example.com"}`, wantStatus: http.StatusForbidden},
+		{name: "service denies target role", user: admin, body: `{"is_active":false}`, err: fmt.Errorf("permission denied: cannot modify super_admin user"), wantStatus: http.StatusForbidden, wantCalls: 1},
+		{name: "unauthenticated", body: `{}`, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeUserService{err: tt.err, targetRole: tt.targetRole}
+			w := httptest.NewRecorder()
+			newTestUserHandler(svc).Update(w, newRequest(http.MethodPut, "/", tt.body, tt.user, map[string]string{"id": uuid.NewString()}))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if svc.calls != tt.wantCalls {
+				t.Errorf("service calls = %d, want %d", svc.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestUserDeleteHandler(t *testing.T) {
+	admin := newUser(model.RoleAdmin)
+
+	tests := []struct {
+		name       string
+		id         string
+		user       *model.User
+		err        error
+		wantStatus int
+	}{
+		{name: "deleted", id: uuid.NewString(), user: admin, wantStatus: http.StatusOK},
+		{name: "admin deletes super admin", id: uuid.NewString(), user: admin, err: fmt.Errorf("permission denied: cannot delete super_admin user"), wantStatus: http.StatusForbidden},
+		{name: "not found", id: uuid.NewString(), user: admin, err: fmt.Errorf("user not found"), wantStatus: http.StatusNotFound},
+		{name: "invalid id", id: "abc", user: admin, wantStatus: http.StatusBadRequest},
+		{name: "unauthenticated", id: uuid.NewString(), wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		svc := &fakeUserService{err: tt.err}
+		w := httptest.NewRecorder()
+		newTestUserHandler(svc).Delete(w, newRequest(http.MethodDelete, "/", "", tt.user, map[string]string{"id": tt.id}))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+		// Aturan role target di-enforce service, jadi actor harus diteruskan
+		if svc.calls > 0 && svc.actor != tt.user {
+			t.Errorf("%s: actor not passed to service", tt.name)
+		}
+	}
+}
+
+func TestUserRestoreHandler(t *testing.T) {
+	superAdmin := newUser(model.RoleSuperAdmin)
+
+	tests := []struct {
+		name       string
+		id         string
+		user       *model.User
+		err        error
+		wantStatus int
+	}{
+		{name: "restored", id: uuid.NewString(), user: superAdmin, wantStatus: http.StatusOK},
+		{name: "invalid id", id: "abc", user: superAdmin, wantStatus: http.StatusBadRequest},
+		{name: "unauthenticated", id: uuid.NewString(), wantStatus: http.StatusUnauthorized},
+		{name: "not super admin", id: uuid.NewString(), user: newUser(model.RoleAdmin), err: fmt.Errorf("permission denied: only super_admin can restore users"), wantStatus: http.StatusForbidden},
+		{name: "not deleted", id: uuid.NewString(), user: superAdmin, err: fmt.Errorf("deleted user not found"), wantStatus: http.StatusNotFound},
+		{name: "repository failure", id: uuid.NewString(), user: superAdmin, err: fmt.Errorf("failed to restore user"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		h := newTestUserHandler(&fakeUserService{err: tt.err})
+		w := httptest.NewRecorder()
+		h.Restore(w, newRequest(http.MethodPost, "/", "", tt.user, map[string]string{"id": tt.id}))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+}
+
+func TestUserFindByEmailHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "found", wantStatus: http.StatusOK},
+		{name: "not found", err: fmt.Errorf("user not found"), wantStatus: http.StatusNotFound},
+		{name: "invalid email", err: fmt.Errorf("validation failed: email"), wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		h := newTestUserHandler(&fakeUserService{err: tt.err})
+		w := httptest.NewRecorder()
+		h.FindByEmail(w, newRequest(http.MethodGet, "/?email=kasir@example.com", "", newUser(model.RoleAdmin), nil))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+		if tt.err == nil && !strings.Contains(w.Body.String(), `"email":"kasir@example.com"`) {
+			t.Errorf("%s: body = %s, want looked up email", tt.name, w.Body.String())
+		}
+	}
+}
+
+func TestUserExportOmitsPassword(t *testing.T) {
+	tests := []struct {
+		format      string
+		contentType string
+	}{
+		{format: "csv", contentType: "text/csv"},
+		{format: "json", contentType: "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			h := newTestUserHandler(&fakeUserService{})
+			w := httptest.NewRecorder()
+			h.Export(w, newRequest(http.MethodGet, "/?format="+tt.format, "", newUser(model.RoleAdmin), nil))
+
+			if w.Code != http.StatusOK || w.Header().Get("Content-Type") != tt.contentType {
+				t.Fatalf("status/content type = %d/%s, want 200/%s", w.Code, w.Header().Get("Content-Type"), tt.contentType)
+			}
+			body := w.Body.String()
+			if !strings.Contains(body, "kasir@example.com") {
+				t.Errorf("body missing exported row: %s", body)
+			}
+			if strings.Contains(strings.ToLower(body), "password") {
+				t.Errorf("body must not contain a password column: %s", body)
+			}
+		})
+	}
+}
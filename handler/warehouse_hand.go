@@ -2,9 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"inventory-system/dto/warehouse"
 	"inventory-system/service"
 	"inventory-system/utils"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -39,7 +41,7 @@ func (wh *WarehouseHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// Call Service
 	createdWarehouse, err := wh.service.Warehouse.Create(r.Context(), req)
 	if err != nil {
-		wh.log.Error("Failed to create warehouse", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to create warehouse", zap.Error(err))
 
 		statusCode := http.StatusBadRequest
 		if strings.Contains(err.Error(), "already exists") {
@@ -100,10 +102,16 @@ func (wh *WarehouseHandler) FindAll(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Optional: ?include_deleted=true (admin only)
+	includeDeleted, ok := parseIncludeDeleted(w, r)
+	if !ok {
+		return
+	}
+
 	// Call service
-	warehouses, pagination, err := wh.service.Warehouse.FindAll(r.Context(), page, limit)
+	warehouses, pagination, err := wh.service.Warehouse.FindAll(r.Context(), page, limit, includeDeleted)
 	if err != nil {
-		wh.log.Error("Failed to get warehouses", zap.Error(err))
+		utils.LoggerFromContext(r.Context()).Error("Failed to get warehouses", zap.Error(err))
 		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve warehouses", nil)
 		return
 	}
@@ -135,14 +143,55 @@ func (wh *WarehouseHandler) Update(w http.ResponseWriter, r *http.Request) {
 	// Call Service
 	updatedWarehouse, err := wh.service.Warehouse.Update(r.Context(), warehouseID, req)
 	if err != nil {
-		wh.log.Error("Failed to update warehouse", zap.Error(err))
-		utils.ResponseError(w, http.StatusBadRequest, "Failed to update warehouse", nil)
+		utils.LoggerFromContext(r.Context()).Error("Failed to update warehouse", zap.Error(err))
+
+		statusCode := http.StatusBadRequest
+		if strings.Contains(err.Error(), "already exists") {
+			statusCode = http.StatusConflict
+		} else if err.Error() == "warehouse not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to update warehouse", err.Error())
 		return
 	}
 
 	utils.ResponseSuccess(w, http.StatusOK, "Warehouse updated successfully", updatedWarehouse)
 }
 
+// DEACTIVATE WAREHOUSE
+// POST /api/admin/warehouses/{id}/deactivate - body optional: { "active": true } untuk aktifkan lagi
+func (wh *WarehouseHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	warehouseID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid warehouse ID", nil)
+		return
+	}
+
+	// Body boleh kosong
+	var req warehouse.DeactivateWarehouseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	updatedWarehouse, err := wh.service.Warehouse.Deactivate(r.Context(), warehouseID, req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to update warehouse status", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "warehouse not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to update warehouse status", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Warehouse status updated successfully", updatedWarehouse)
+}
+
 func (wh *WarehouseHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	warehouseIDStr := chi.URLParam(r, "id")
 	warehouseID, err := uuid.Parse(warehouseIDStr)
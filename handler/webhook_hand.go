@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"encoding/json"
+	"inventory-system/dto/webhook"
+	"inventory-system/service"
+	"inventory-system/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WebhookHandler struct {
+	service *service.Service
+	log     *zap.Logger
+}
+
+func NewWebhookHandler(service *service.Service, log *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+func (wh *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req webhook.CreateWebhookRequest
+
+	// Parse request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Call Service
+	createdWebhook, err := wh.service.Webhook.Create(r.Context(), req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to create webhook", zap.Error(err))
+
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "validation failed") {
+			statusCode = http.StatusBadRequest
+		}
+
+		utils.ResponseError(w, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "Webhook created successfully", createdWebhook)
+}
+
+func (wh *WebhookHandler) FindByID(w http.ResponseWriter, r *http.Request) {
+	webhookIDStr := chi.URLParam(r, "id")
+	webhookID, err := uuid.Parse(webhookIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid webhook ID", nil)
+		return
+	}
+
+	// Call service
+	webhookData, err := wh.service.Webhook.FindByID(r.Context(), webhookID)
+	if err != nil {
+		utils.ResponseError(w, http.StatusNotFound, "webhook not found", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Webhook retrieved", webhookData)
+}
+
+func (wh *WebhookHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	// Get pagination parameters
+	pageStr := r.URL.Query().Get("page")
+	limitStr := r.URL.Query().Get("limit")
+
+	// Default values
+	page := 1
+	limit := 10
+
+	// Parse page
+	if pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		} else {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid page parameter", nil)
+			return
+		}
+	}
+
+	// Parse limit
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		} else {
+			utils.ResponseError(w, http.StatusBadRequest, "Invalid limit parameter (max 100)", nil)
+			return
+		}
+	}
+
+	// Call service
+	webhooks, pagination, err := wh.service.Webhook.FindAll(r.Context(), page, limit)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to get webhooks", zap.Error(err))
+		utils.ResponseError(w, http.StatusInternalServerError, "Failed to retrieve webhooks", nil)
+		return
+	}
+
+	// Response with pagination
+	response := map[string]interface{}{
+		"webhooks":   webhooks,
+		"pagination": pagination,
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Webhooks retrieved successfully", response)
+}
+
+func (wh *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	webhookIDStr := chi.URLParam(r, "id")
+	webhookID, err := uuid.Parse(webhookIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid webhook ID", nil)
+		return
+	}
+
+	var req webhook.UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	defer r.Body.Close()
+
+	// Call Service
+	updatedWebhook, err := wh.service.Webhook.Update(r.Context(), webhookID, req)
+	if err != nil {
+		utils.LoggerFromContext(r.Context()).Error("Failed to update webhook", zap.Error(err))
+
+		statusCode := http.StatusBadRequest
+		if err.Error() == "webhook not found" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "failed to update webhook" {
+			statusCode = http.StatusInternalServerError
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to update webhook", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Webhook updated successfully", updatedWebhook)
+}
+
+func (wh *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	webhookIDStr := chi.URLParam(r, "id")
+	webhookID, err := uuid.Parse(webhookIDStr)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid webhook ID", nil)
+		return
+	}
+
+	if err := wh.service.Webhook.Delete(r.Context(), webhookID); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "webhook not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		utils.ResponseError(w, statusCode, "Failed to delete webhook", err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "Webhook deleted successfully", nil)
+}
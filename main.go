@@ -26,8 +26,8 @@ func main() {
 		log.Fatal("Failed to load config:", err)
 	}
 
-	// Initialize logger
-	logger, err := utils.InitLogger(config.PathLogging, config.Debug)
+	// Initialize logger (LOG_LEVEL & LOG_FORMAT, level bisa diubah runtime)
+	logger, err := utils.InitLogger(config.PathLogging, config.LogLevel, config.LogFormat)
 	if err != nil {
 		log.Fatal("Failed to init logger:", err)
 	}
@@ -36,6 +36,34 @@ func main() {
 	// Set global logger
 	utils.Logger = logger
 
+	// Mata uang & pembulatan nominal
+	utils.SetMoneyConfig(config.Money)
+
+	// Format nomor invoice per cabang (INVOICE_PREFIX, INVOICE_DATE_FORMAT), gagal start jika tidak valid
+	if err := utils.SetInvoiceConfig(config.Invoice); err != nil {
+		logger.Fatal("Invalid invoice config", zap.Error(err))
+	}
+
+	// Range tanggal maksimal sales & revenue report (REPORT_MAX_RANGE_DAYS), gagal start jika tidak valid
+	if err := config.Report.Validate(); err != nil {
+		logger.Fatal("Invalid report config", zap.Error(err))
+	}
+
+	// Masa berlaku & sliding session (SESSION_*), gagal start jika tidak valid
+	if err := config.Session.Validate(); err != nil {
+		logger.Fatal("Invalid session config", zap.Error(err))
+	}
+
+	// Trusted proxy untuk IP client (TRUSTED_PROXIES), gagal start jika tidak valid
+	if err := config.Security.Validate(); err != nil {
+		logger.Fatal("Invalid security config", zap.Error(err))
+	}
+
+	// Verbose log admin (LOG_VERBOSE_ADMIN*), gagal start jika tidak valid
+	if err := config.VerboseLog.Validate(); err != nil {
+		logger.Fatal("Invalid verbose log config", zap.Error(err))
+	}
+
 	// Connect to database
 	pool, err := database.InitDB(config.DB)
 	if err != nil {
@@ -48,13 +76,44 @@ func main() {
 		zap.String("database", config.DB.Name),
 	)
 
+	// Readiness DB: di-set down saat connection error, dicek ulang periodik (DATABASE_HEALTH_CHECK_SECONDS)
+	dbMonitor := database.NewMonitor(pool, logger)
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	defer stopMonitor()
+	go dbMonitor.Run(monitorCtx, config.DB.HealthCheckInterval)
+
 	// Initialize repository, service, & handler
-	repo := repository.NewRepository(pool, logger)
-	svc := service.NewService(repo, logger)
-	hdl := handler.NewHandlers(svc, logger)
+	// Connection error -> ErrDatabaseUnavailable (503), slow query log (DATABASE_SLOW_QUERY_MS, 0 = nonaktif)
+	db := database.NewSlowQueryLogger(database.NewAvailabilityGuard(pool, dbMonitor), config.DB.SlowQueryThreshold)
+	repo := repository.NewRepository(db, logger)
+	notifier := service.NewMultiNotifier(
+		service.NewLogNotifier(logger),
+		service.NewWebhookDispatcher(repo, logger),
+	)
+	productOpts := service.ProductOptions{
+		Storage:          service.NewLocalStorage(config.Upload.Dir, config.Upload.BaseURL),
+		MaxImageSize:     int64(config.Upload.MaxSizeMB) << 20,
+		MaxMinStockLevel: config.Inventory.MaxMinStockLevel,
+		DefaultMinStock:  config.Inventory.DefaultMinStockLevel,
+		MaxBatchIDs:      config.Inventory.MaxBatchIDs,
+	}
+	saleOpts := service.SaleOptions{
+		MaxItems: config.Sale.MaxItems,
+	}
+	reportOpts := service.ReportOptions{
+		MaxRangeDays: config.Report.MaxRangeDays,
+	}
+	authOpts := service.AuthOptions{
+		SessionTTL:     config.Session.TTL,
+		SlidingEnabled: config.Session.SlidingEnabled,
+		SlideThreshold: config.Session.SlideThreshold,
+		ActiveAlert:    config.Session.ActiveAlert,
+	}
+	svc := service.NewService(repo, logger, notifier, productOpts, saleOpts, reportOpts, authOpts)
+	hdl := handler.NewHandlers(svc, logger, config)
 
 	// Setup router
-	r := router.SetupRouter(svc, hdl)
+	r := router.SetupRouter(svc, hdl, config, dbMonitor)
 
 	// Create HTTP server
 	server := &http.Server{
@@ -68,6 +127,7 @@ func main() {
 			zap.String("port", config.Port),
 			zap.String("app", config.AppName),
 			zap.Bool("debug", config.Debug),
+			zap.String("log_level", config.LogLevel),
 		)
 
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -44,10 +44,10 @@ func Auth(authService service.AuthService) func(http.Handler) http.Handler {
 				return
 			}
 
-			// Validasi token
+			// Validasi token (+ perpanjang expiry jika sliding session aktif & mendekati expired)
 			user, err := authService.ValidateToken(r.Context(), token)
 			if err != nil {
-				utils.Logger.Warn("Invalid token",
+				utils.LoggerFromContext(r.Context()).Warn("Invalid token",
 					zap.String("token", tokenString),
 					zap.Error(err),
 				)
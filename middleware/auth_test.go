@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/model"
+	"inventory-system/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// fakeAuthService - hanya ValidateToken yang dipakai middleware
+type fakeAuthService struct {
+	service.AuthService
+	tokens map[uuid.UUID]*model.User
+}
+
+func (f fakeAuthService) ValidateToken(ctx context.Context, token uuid.UUID) (*model.User, error) {
+	if user, ok := f.tokens[token]; ok {
+		return user, nil
+	}
+	return nil, fmt.Errorf("session not found")
+}
+
+func TestAuth(t *testing.T) {
+	user := &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: model.RoleStaff}
+	token := uuid.New()
+	auth := Auth(fakeAuthService{tokens: map[uuid.UUID]*model.User{token: user}})
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{name: "missing header", wantStatus: http.StatusUnauthorized},
+		{name: "not bearer", header: "Basic " + token.String(), wantStatus: http.StatusUnauthorized},
+		{name: "token not uuid", header: "Bearer abc", wantStatus: http.StatusUnauthorized},
+		{name: "unknown token", header: "Bearer " + uuid.NewString(), wantStatus: http.StatusUnauthorized},
+		{name: "valid token", header: "Bearer " + token.String(), wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *model.User
+			handler := auth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = GetUserFromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && got != user {
+				t.Errorf("context user = %v, want %v", got, user)
+			}
+		})
+	}
+}
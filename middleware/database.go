@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"inventory-system/database"
+	"inventory-system/utils"
+	"net/http"
+)
+
+// DatabaseAvailability mengubah response error menjadi 503 jika request kena connection error DB
+// Handler tidak perlu tahu: mapping 400/404/500 apapun diganti 503 "Database unavailable"
+// Response sukses tidak disentuh
+func DatabaseAvailability(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(database.TrackConnectionLoss(r.Context()))
+		next.ServeHTTP(&dbAvailabilityWriter{ResponseWriter: w, r: r}, r)
+	})
+}
+
+type dbAvailabilityWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	wroteHeader bool
+	replaced    bool // body asli dibuang setelah diganti 503
+}
+
+func (w *dbAvailabilityWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if code >= http.StatusBadRequest && database.ConnectionLost(w.r.Context()) {
+		w.replaced = true
+		utils.ResponseError(w.ResponseWriter, http.StatusServiceUnavailable,
+			"Database unavailable, please try again later", database.ErrDatabaseUnavailable.Error())
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *dbAvailabilityWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.replaced {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush - streaming response (CSV export) tetap jalan
+func (w *dbAvailabilityWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
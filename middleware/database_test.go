@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"inventory-system/database"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// fakeDB - Exec return err, method lain tidak dipakai
+type fakeDB struct {
+	database.PgxIface
+	err error
+}
+
+func (f fakeDB) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, f.err
+}
+
+func TestDatabaseAvailability(t *testing.T) {
+	tests := []struct {
+		name        string
+		dbErr       error
+		handlerCode int
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "success untouched", handlerCode: http.StatusOK, wantStatus: http.StatusOK, wantMessage: "ok"},
+		{name: "query error keeps handler status", dbErr: errors.New("duplicate key"), handlerCode: http.StatusConflict, wantStatus: http.StatusConflict, wantMessage: "handler error"},
+		{name: "connection lost becomes 503", dbErr: io.ErrUnexpectedEOF, handlerCode: http.StatusNotFound, wantStatus: http.StatusServiceUnavailable, wantMessage: "Database unavailable, please try again later"},
+		{name: "connection lost but success untouched", dbErr: io.EOF, handlerCode: http.StatusOK, wantStatus: http.StatusOK, wantMessage: "ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := database.NewAvailabilityGuard(fakeDB{err: tt.dbErr}, database.NewMonitor(nil, zap.NewNop()))
+
+			handler := DatabaseAvailability(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				db.Exec(r.Context(), "SELECT 1")
+
+				message := "ok"
+				if tt.handlerCode >= http.StatusBadRequest {
+					message = "handler error"
+				}
+				w.WriteHeader(tt.handlerCode)
+				json.NewEncoder(w).Encode(map[string]string{"message": message})
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var body struct {
+				Message string `json:"message"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", body.Message, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -5,22 +5,31 @@ import (
 	"net/http"
 	"time"
 
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
 	"go.uber.org/zap"
 )
 
 // Logger middleware untuk log setiap HTTP request
+// Sekaligus simpan child logger dengan request_id ke context (utils.LoggerFromContext)
 func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Catat waktu mulai
 		start := time.Now()
 
+		// Child logger per request, dipakai handler/service/repo lewat context
+		log := utils.LoggerFromContext(r.Context())
+		if reqID := chimiddleware.GetReqID(r.Context()); reqID != "" {
+			log = log.With(zap.String("request_id", reqID))
+		}
+		r = r.WithContext(utils.WithLogger(r.Context(), log))
+
 		// Eksekusi handler
 		next.ServeHTTP(w, r)
 
 		// Hitung durasi & log
 		duration := time.Since(start)
 
-		utils.Logger.Info("HTTP Request",
+		log.Info("HTTP Request",
 			zap.String("method", r.Method),
 			zap.String("path", r.URL.Path),
 			zap.Duration("duration", duration),
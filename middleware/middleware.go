@@ -31,7 +31,7 @@ func AllowSelfOrAdmin(next http.Handler) http.Handler {
 
 		// Check: accessing own data OR is admin
 		if currentUser.ID != requestedUserID && !currentUser.CanManageUsers() {
-			utils.Logger.Warn("Access denied to user data",
+			utils.LoggerFromContext(r.Context()).Warn("Access denied to user data",
 				zap.String("current_user", currentUser.ID.String()),
 				zap.String("requested_user", requestedUserID.String()),
 				zap.String("role", string(currentUser.Role)),
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"inventory-system/utils"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RateLimiter - backend rate limit (in-memory sekarang, bisa diganti Redis nanti)
+// Allow return false + retryAfter jika key sudah melebihi limit
+type RateLimiter interface {
+	Allow(key string) (allowed bool, retryAfter time.Duration)
+}
+
+// ========== IN-MEMORY TOKEN BUCKET ==========
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+type memoryRateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	capacity  float64 // burst = requests per minute
+	rate      float64 // token per detik
+	lastSweep time.Time
+	now       func() time.Time // time.Now, bisa diganti di test
+}
+
+// NewMemoryRateLimiter token bucket per key, refill requestsPerMinute token per menit
+func NewMemoryRateLimiter(requestsPerMinute int) RateLimiter {
+	return &memoryRateLimiter{
+		buckets:   make(map[string]*bucket),
+		capacity:  float64(requestsPerMinute),
+		rate:      float64(requestsPerMinute) / 60,
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (m *memoryRateLimiter) Allow(key string) (bool, time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	b, exists := m.buckets[key]
+	if !exists {
+		b = &bucket{tokens: m.capacity, lastSeen: now}
+		m.buckets[key] = b
+	}
+
+	// Refill sesuai waktu yang berlalu
+	b.tokens = math.Min(m.capacity, b.tokens+now.Sub(b.lastSeen).Seconds()*m.rate)
+	b.lastSeen = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	// Waktu sampai 1 token tersedia
+	wait := time.Duration((1 - b.tokens) / m.rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep hapus bucket yang idle (sudah penuh lagi) supaya map tidak terus membesar
+func (m *memoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < time.Minute {
+		return
+	}
+	m.lastSweep = now
+
+	fullAfter := time.Duration(m.capacity / m.rate * float64(time.Second))
+	for key, b := range m.buckets {
+		if now.Sub(b.lastSeen) > fullAfter {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+// ========== MIDDLEWARE ==========
+
+// RateLimit middleware - key per user (jika dipasang setelah Auth) atau IP client
+// Limiter nil = rate limit nonaktif
+func RateLimit(limiter RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := rateLimitKey(r)
+
+			allowed, retryAfter := limiter.Allow(key)
+			if !allowed {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				if seconds < 1 {
+					seconds = 1
+				}
+
+				utils.LoggerFromContext(r.Context()).Warn("Rate limit exceeded",
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.String("ip", r.RemoteAddr),
+				)
+
+				w.Header().Set("Retry-After", strconv.Itoa(seconds))
+				utils.ResponseError(w, http.StatusTooManyRequests, "Too many requests, please try again later",
+					map[string]int{"retry_after_seconds": seconds})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey user ID dari context (sudah divalidasi Auth), fallback ke IP client
+// Header Authorization sengaja tidak dipakai: token random per request = bucket baru (bypass limit)
+// RemoteAddr = IP koneksi, kecuali di-resolve RealIP dari trusted proxy (header client tidak dipercaya)
+func rateLimitKey(r *http.Request) string {
+	if user := utils.GetUserFromContext(r.Context()); user != nil {
+		return "user:" + user.ID.String()
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"encoding/json"
+	"inventory-system/model"
+	"inventory-system/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRateLimitKey(t *testing.T) {
+	user := &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: model.RoleStaff}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     string
+		user       *model.User
+		want       string
+	}{
+		{name: "anonymous uses client ip", remoteAddr: "10.0.0.1:5555", want: "ip:10.0.0.1"},
+		{name: "remote addr without port", remoteAddr: "10.0.0.2", want: "ip:10.0.0.2"},
+		{name: "authorization header ignored", remoteAddr: "10.0.0.3:80", header: "Bearer " + uuid.NewString(), want: "ip:10.0.0.3"},
+		{name: "authenticated user keyed by id", remoteAddr: "10.0.0.4:80", header: "Bearer " + uuid.NewString(), user: user, want: "user:" + user.ID.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			if tt.user != nil {
+				r = r.WithContext(utils.SetUserToContext(r.Context(), tt.user))
+			}
+
+			if got := rateLimitKey(r); got != tt.want {
+				t.Errorf("rateLimitKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryRateLimiterAllow(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryRateLimiter(2).(*memoryRateLimiter)
+	limiter.now = func() time.Time { return now }
+	limiter.lastSweep = now
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		key       string
+		wantAllow bool
+		wantRetry time.Duration
+	}{
+		{name: "first request", key: "a", wantAllow: true},
+		{name: "burst used up", key: "a", wantAllow: true},
+		{name: "over limit", key: "a", wantAllow: false, wantRetry: 30 * time.Second},
+		{name: "other key has own bucket", key: "b", wantAllow: true},
+		{name: "partial refill still limited", advance: 15 * time.Second, key: "a", wantAllow: false, wantRetry: 15 * time.Second},
+		{name: "refilled one token", advance: 15 * time.Second, key: "a", wantAllow: true},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		allowed, retry := limiter.Allow(step.key)
+		if allowed != step.wantAllow {
+			t.Fatalf("%s: allowed = %v, want %v", step.name, allowed, step.wantAllow)
+		}
+		if retry != step.wantRetry {
+			t.Errorf("%s: retryAfter = %v, want %v", step.name, retry, step.wantRetry)
+		}
+	}
+}
+
+func TestMemoryRateLimiterSweep(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryRateLimiter(60).(*memoryRateLimiter)
+	limiter.now = func() time.Time { return now }
+	limiter.lastSweep = now
+
+	limiter.Allow("idle")
+	now = now.Add(30 * time.Second)
+	limiter.Allow("active")
+
+	// Sweep baru jalan setelah 1 menit, bucket idle (penuh lagi) dihapus
+	now = now.Add(40 * time.Second)
+	limiter.Allow("active")
+
+	if _, ok := limiter.buckets["idle"]; ok {
+		t.Error("idle bucket not swept")
+	}
+	if _, ok := limiter.buckets["active"]; !ok {
+		t.Error("active bucket swept")
+	}
+}
+
+type stubLimiter struct {
+	allowed bool
+	retry   time.Duration
+	keys    []string
+}
+
+func (s *stubLimiter) Allow(key string) (bool, time.Duration) {
+	s.keys = append(s.keys, key)
+	return s.allowed, s.retry
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		limiter        *stubLimiter
+		wantStatus     int
+		wantRetryAfter string
+		wantSeconds    int
+	}{
+		{name: "allowed", limiter: &stubLimiter{allowed: true}, wantStatus: http.StatusOK},
+		{name: "limited rounds retry up", limiter: &stubLimiter{retry: 1500 * time.Millisecond}, wantStatus: http.StatusTooManyRequests, wantRetryAfter: "2", wantSeconds: 2},
+		{name: "limited minimum one second", limiter: &stubLimiter{retry: 10 * time.Millisecond}, wantStatus: http.StatusTooManyRequests, wantRetryAfter: "1", wantSeconds: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RateLimit(tt.limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/auth/login", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			if tt.wantStatus != http.StatusTooManyRequests {
+				return
+			}
+
+			var body struct {
+				Errors map[string]int `json:"errors"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Errors["retry_after_seconds"] != tt.wantSeconds {
+				t.Errorf("retry_after_seconds = %d, want %d", body.Errors["retry_after_seconds"], tt.wantSeconds)
+			}
+		})
+	}
+}
+
+func TestRateLimitNilLimiterDisabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := RateLimit(nil)(next)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIP ganti r.RemoteAddr dengan IP client dari X-Forwarded-For / X-Real-IP,
+// tapi hanya jika koneksi datang dari trusted proxy (IP/CIDR, sudah divalidasi saat startup)
+// Tanpa trusted proxy header diabaikan: client bisa set header sendiri untuk bypass rate limit per IP
+func RealIP(trustedProxies []string) func(http.Handler) http.Handler {
+	var trusted []*net.IPNet
+	for _, proxy := range trustedProxies {
+		if ip := net.ParseIP(proxy); ip != nil {
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			trusted = append(trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			trusted = append(trusted, network)
+		}
+	}
+
+	isTrusted := func(addr string) bool {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			return false
+		}
+		for _, network := range trusted {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			peer, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				peer = r.RemoteAddr
+			}
+
+			if isTrusted(peer) {
+				if client := clientIPFromHeaders(r, isTrusted); client != "" {
+					r.RemoteAddr = client
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIPFromHeaders X-Forwarded-For dibaca dari kanan (hop terakhir), lewati proxy yang dipercaya
+// Entry paling kiri bisa diisi bebas oleh client, jadi tidak diambil begitu saja
+func clientIPFromHeaders(r *http.Request, isTrusted func(string) bool) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				return ""
+			}
+			if !isTrusted(hop) {
+				return hop
+			}
+		}
+		return ""
+	}
+
+	if xrip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(xrip) != nil {
+		return xrip
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRealIP(t *testing.T) {
+	trusted := []string{"10.0.0.1", "172.16.0.0/12"}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		xRealIP    string
+		want       string
+	}{
+		{name: "direct client header ignored", remoteAddr: "203.0.113.7:5555", xff: "1.2.3.4", want: "203.0.113.7:5555"},
+		{name: "direct client x-real-ip ignored", remoteAddr: "203.0.113.7:5555", xRealIP: "1.2.3.4", want: "203.0.113.7:5555"},
+		{name: "trusted proxy forwards client", remoteAddr: "10.0.0.1:443", xff: "198.51.100.9", want: "198.51.100.9"},
+		{name: "trusted cidr", remoteAddr: "172.20.1.1:443", xff: "198.51.100.9", want: "198.51.100.9"},
+		{name: "spoofed leftmost entry skipped", remoteAddr: "10.0.0.1:443", xff: "1.2.3.4, 198.51.100.9", want: "198.51.100.9"},
+		{name: "chain of trusted proxies", remoteAddr: "10.0.0.1:443", xff: "198.51.100.9, 172.16.0.5", want: "198.51.100.9"},
+		{name: "trusted proxy x-real-ip", remoteAddr: "10.0.0.1:443", xRealIP: "198.51.100.9", want: "198.51.100.9"},
+		{name: "garbage header keeps peer", remoteAddr: "10.0.0.1:443", xff: "not-an-ip", want: "10.0.0.1:443"},
+		{name: "trusted proxy without header", remoteAddr: "10.0.0.1:443", want: "10.0.0.1:443"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.xff != "" {
+				r.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if tt.xRealIP != "" {
+				r.Header.Set("X-Real-IP", tt.xRealIP)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Errorf("RemoteAddr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Ganti X-Forwarded-For tiap request tidak boleh dapat bucket baru
+func TestRealIPSpoofedHeaderKeepsRateLimitBucket(t *testing.T) {
+	limiter := NewMemoryRateLimiter(2).(*memoryRateLimiter)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	limiter.lastSweep = now
+
+	handler := RealIP(nil)(RateLimit(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	spoofed := []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"}
+	wantStatus := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+	for i, xff := range spoofed {
+		r := httptest.NewRequest(http.MethodPost, "/api/auth/login", nil)
+		r.RemoteAddr = "203.0.113.7:5555"
+		r.Header.Set("X-Forwarded-For", xff)
+		r.Header.Set("X-Real-IP", xff)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+
+		if rec.Code != wantStatus[i] {
+			t.Fatalf("attempt %d with X-Forwarded-For %s: status = %d, want %d", i+1, xff, rec.Code, wantStatus[i])
+		}
+	}
+
+	if len(limiter.buckets) != 1 {
+		t.Errorf("buckets = %d, want 1 (keyed on socket peer)", len(limiter.buckets))
+	}
+}
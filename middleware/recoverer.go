@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"inventory-system/utils"
+	"net/http"
+	"runtime/debug"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+)
+
+// Recoverer middleware untuk menangkap panic di handler
+// Panic dicatat ke Zap logger (beserta stack & request ID), client hanya menerima 500 generik
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+
+			// http.ErrAbortHandler dipakai untuk abort response, jangan di-recover
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			utils.Logger.Error("Panic recovered",
+				zap.Any("panic", rec),
+				zap.String("stack", string(debug.Stack())),
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.String("request_id", chimiddleware.GetReqID(r.Context())),
+			)
+
+			// Upgrade connection (websocket) tidak bisa ditulisi response
+			if r.Header.Get("Connection") != "Upgrade" {
+				utils.ResponseError(w, http.StatusInternalServerError, "Internal server error", nil)
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"inventory-system/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRecoverer(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	previous := utils.Logger
+	utils.Logger = zap.New(core)
+	t.Cleanup(func() { utils.Logger = previous })
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantLogs   int
+	}{
+		{name: "no panic", handler: func(w http.ResponseWriter, r *http.Request) {}, wantStatus: http.StatusOK},
+		{name: "panic becomes 500", handler: func(w http.ResponseWriter, r *http.Request) { panic("boom") }, wantStatus: http.StatusInternalServerError, wantLogs: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := logs.Len()
+			rec := httptest.NewRecorder()
+			Recoverer(tt.handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/products", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := logs.Len() - before; got != tt.wantLogs {
+				t.Errorf("panic logs = %d, want %d", got, tt.wantLogs)
+			}
+		})
+	}
+}
+
+func TestRecovererRepanicsAbortHandler(t *testing.T) {
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", rec)
+		}
+	}()
+
+	Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
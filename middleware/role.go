@@ -31,7 +31,7 @@ func RequireRole(allowedRoles ...model.UserRole) func(http.Handler) http.Handler
 
 			// Jika tidak diizinkan, return 403 Forbidden
 			if !hasPermission {
-				utils.Logger.Warn("Access denied",
+				utils.LoggerFromContext(r.Context()).Warn("Access denied",
 					zap.String("path", r.URL.Path),
 					zap.String("method", r.Method),
 					zap.String("user_role", string(user.Role)),
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"inventory-system/utils"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SecurityHeaders middleware untuk hardening header standar
+// Setiap header bisa di-toggle lewat config (utils.SecurityConfig)
+func SecurityHeaders(cfg utils.SecurityConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+
+			if cfg.NoSniff {
+				h.Set("X-Content-Type-Options", "nosniff")
+			}
+			if cfg.FrameDeny {
+				h.Set("X-Frame-Options", "DENY")
+			}
+			if cfg.ReferrerPolicy != "" {
+				h.Set("Referrer-Policy", cfg.ReferrerPolicy)
+			}
+			// HSTS hanya jika TLS di-terminate upstream (load balancer/proxy)
+			if cfg.HSTSEnabled && cfg.HSTSMaxAge > 0 {
+				h.Set("Strict-Transport-Security", "max-age="+strconv.Itoa(cfg.HSTSMaxAge)+"; includeSubDomains")
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CORS middleware berdasarkan daftar origin yang diizinkan
+// Daftar kosong = CORS nonaktif, "*" = semua origin
+func CORS(cfg utils.SecurityConfig) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(cfg.CORSAllowedOrigins))
+	for _, origin := range cfg.CORSAllowedOrigins {
+		allowed[origin] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" || len(allowed) == 0 || !(allowed["*"] || allowed[origin]) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Add("Vary", "Origin")
+
+			// Preflight request
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				h.Set("Access-Control-Allow-Methods", strings.Join([]string{
+					http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions,
+				}, ", "))
+				h.Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+				h.Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
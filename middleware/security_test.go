@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"inventory-system/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  utils.SecurityConfig
+		want map[string]string
+	}{
+		{
+			name: "all disabled",
+			cfg:  utils.SecurityConfig{},
+			want: map[string]string{"X-Content-Type-Options": "", "X-Frame-Options": "", "Referrer-Policy": "", "Strict-Transport-Security": ""},
+		},
+		{
+			name: "all enabled",
+			cfg:  utils.SecurityConfig{NoSniff: true, FrameDeny: true, ReferrerPolicy: "no-referrer", HSTSEnabled: true, HSTSMaxAge: 3600},
+			want: map[string]string{
+				"X-Content-Type-Options":    "nosniff",
+				"X-Frame-Options":           "DENY",
+				"Referrer-Policy":           "no-referrer",
+				"Strict-Transport-Security": "max-age=3600; includeSubDomains",
+			},
+		},
+		{
+			name: "hsts without max age",
+			cfg:  utils.SecurityConfig{HSTSEnabled: true},
+			want: map[string]string{"Strict-Transport-Security": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := SecurityHeaders(tt.cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			for header, want := range tt.want {
+				if got := rec.Header().Get(header); got != want {
+					t.Errorf("%s = %q, want %q", header, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	tests := []struct {
+		name        string
+		allowed     []string
+		origin      string
+		preflight   bool
+		wantOrigin  string
+		wantStatus  int
+		wantMethods bool
+	}{
+		{name: "disabled", allowed: nil, origin: "https://a.test", wantStatus: http.StatusOK},
+		{name: "origin not allowed", allowed: []string{"https://a.test"}, origin: "https://b.test", wantStatus: http.StatusOK},
+		{name: "origin allowed", allowed: []string{"https://a.test"}, origin: "https://a.test", wantOrigin: "https://a.test", wantStatus: http.StatusOK},
+		{name: "wildcard echoes origin", allowed: []string{"*"}, origin: "https://b.test", wantOrigin: "https://b.test", wantStatus: http.StatusOK},
+		{name: "no origin header", allowed: []string{"*"}, wantStatus: http.StatusOK},
+		{name: "preflight", allowed: []string{"*"}, origin: "https://a.test", preflight: true, wantOrigin: "https://a.test", wantStatus: http.StatusNoContent, wantMethods: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := CORS(utils.SecurityConfig{CORSAllowedOrigins: tt.allowed})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			method := http.MethodGet
+			if tt.preflight {
+				method = http.MethodOptions
+			}
+			r := httptest.NewRequest(method, "/api/products", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Methods") != ""; got != tt.wantMethods {
+				t.Errorf("Allow-Methods set = %v, want %v", got, tt.wantMethods)
+			}
+		})
+	}
+}
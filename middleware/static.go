@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// FileServer serve file statis dari dir tanpa directory listing
+// Path direktori (termasuk yang berakhiran "/") selalu 404, hanya file yang persis dilayani
+func FileServer(dir string) http.Handler {
+	files := http.FileServer(filesOnly{http.Dir(dir)})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+// filesOnly FileSystem yang menolak direktori (juga mencegah index.html & redirect ke "dir/")
+type filesOnly struct {
+	fs http.FileSystem
+}
+
+func (f filesOnly) Open(name string) (http.File, error) {
+	file, err := f.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, fs.ErrNotExist
+	}
+
+	return file, nil
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileServer(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "products"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "products", "a.png"), []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// index.html di direktori tidak boleh ikut dilayani lewat path direktori
+	if err := os.WriteFile(filepath.Join(dir, "products", "index.html"), []byte("index"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "file served", path: "/products/a.png", wantStatus: http.StatusOK, wantBody: "png"},
+		{name: "root listing hidden", path: "/", wantStatus: http.StatusNotFound},
+		{name: "directory with slash hidden", path: "/products/", wantStatus: http.StatusNotFound},
+		{name: "directory without slash hidden", path: "/products", wantStatus: http.StatusNotFound},
+		{name: "missing file", path: "/products/missing.png", wantStatus: http.StatusNotFound},
+	}
+
+	handler := FileServer(dir)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
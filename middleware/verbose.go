@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"inventory-system/utils"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+)
+
+// Key JSON yang mengandung salah satu kata ini selalu di-redact (case-insensitive)
+var sensitiveKeys = []string{"password", "token", "secret"}
+
+const redactedValue = "[REDACTED]"
+
+// VerboseLogger middleware untuk log body request & status response mutasi admin (POST/PUT/PATCH/DELETE)
+// Opt-in lewat config (LOG_VERBOSE_ADMIN), nonaktif = handler langsung tanpa overhead.
+// Body dibaca lalu dikembalikan ke r.Body, jadi handler tetap menerima body utuh.
+// Hanya body JSON yang di-log (field sensitif di-redact), selain itu/terlalu besar cuma ukurannya
+func VerboseLogger(cfg utils.VerboseLogConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !cfg.Enabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Baca maksimal MaxBodyBytes+1 untuk tahu body kepotong atau tidak
+			var captured []byte
+			if r.Body != nil {
+				var err error
+				captured, err = io.ReadAll(io.LimitReader(r.Body, int64(cfg.MaxBodyBytes)+1))
+				if err != nil {
+					utils.ResponseError(w, http.StatusBadRequest, "Failed to read request body", nil)
+					return
+				}
+				// Sisa body (kalau kepotong) tetap dibaca handler setelah bagian yang sudah di-capture
+				r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(captured), r.Body), Closer: r.Body}
+			}
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			// Handler yang tidak memanggil WriteHeader = 200 implisit
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			fields := []zap.Field{
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Int("status", status),
+			}
+			if user := utils.GetUserFromContext(r.Context()); user != nil {
+				fields = append(fields, zap.String("user_id", user.ID.String()))
+			}
+			fields = append(fields, bodyField(captured, cfg.MaxBodyBytes, r.Header.Get("Content-Type")))
+
+			utils.LoggerFromContext(r.Context()).Info("Admin request", fields...)
+		})
+	}
+}
+
+// bodyField body JSON yang sudah di-redact, atau hanya ukuran jika bukan JSON/terlalu besar
+// Body yang tidak bisa di-parse tidak pernah di-log mentah (bisa berisi password)
+func bodyField(body []byte, maxBytes int, contentType string) zap.Field {
+	if len(body) == 0 {
+		return zap.Skip()
+	}
+	if len(body) > maxBytes {
+		return zap.String("body", "[omitted: larger than "+strconv.Itoa(maxBytes)+" bytes]")
+	}
+	if contentType != "" && !strings.Contains(contentType, "json") {
+		return zap.String("body", "[omitted: "+contentType+"]")
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return zap.String("body", "[omitted: invalid json]")
+	}
+	return zap.Any("body", redact(decoded))
+}
+
+// redact ganti value field sensitif secara rekursif (object & array)
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isSensitiveKey(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redact(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redact(item)
+		}
+	}
+	return value
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// readCloser gabungkan reader baru dengan Close dari body asli
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
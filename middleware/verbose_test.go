@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"encoding/json"
+	"inventory-system/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "flat object", input: `{"email":"a@b.c","password":"x"}`, want: `{"email":"a@b.c","password":"[REDACTED]"}`},
+		{name: "case insensitive key match", input: `{"New_Password":"x","refreshToken":"y","ClientSecret":"z"}`, want: `{"New_Password":"[REDACTED]","refreshToken":"[REDACTED]","ClientSecret":"[REDACTED]"}`},
+		{name: "nested object and array", input: `{"users":[{"name":"a","password":"x"}],"meta":{"token":"y"}}`, want: `{"users":[{"name":"a","password":"[REDACTED]"}],"meta":{"token":"[REDACTED]"}}`},
+		{name: "sensitive object replaced whole", input: `{"secret":{"a":1}}`, want: `{"secret":"[REDACTED]"}`},
+		{name: "scalar untouched", input: `"password"`, want: `"password"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input, want interface{}
+			if err := json.Unmarshal([]byte(tt.input), &input); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := redact(input); !reflect.DeepEqual(got, want) {
+				t.Errorf("redact() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestBodyField(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		maxBytes    int
+		contentType string
+		wantSkip    bool
+		wantString  string
+	}{
+		{name: "empty body skipped", body: "", maxBytes: 100, wantSkip: true},
+		{name: "too large", body: `{"a":"0123456789"}`, maxBytes: 5, wantString: "[omitted: larger than 5 bytes]"},
+		{name: "non json content type", body: "a=b", maxBytes: 100, contentType: "application/x-www-form-urlencoded", wantString: "[omitted: application/x-www-form-urlencoded]"},
+		{name: "invalid json never logged raw", body: `{"password":`, maxBytes: 100, contentType: "application/json", wantString: "[omitted: invalid json]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := bodyField([]byte(tt.body), tt.maxBytes, tt.contentType)
+			if tt.wantSkip {
+				if field.Type != zapcore.SkipType {
+					t.Errorf("field type = %v, want skip", field.Type)
+				}
+				return
+			}
+			if field.String != tt.wantString {
+				t.Errorf("field = %q, want %q", field.String, tt.wantString)
+			}
+		})
+	}
+}
+
+func TestVerboseLogger(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		method   string
+		body     string
+		wantLogs int
+		wantBody map[string]interface{}
+	}{
+		{name: "disabled", enabled: false, method: http.MethodPost, body: `{"name":"a"}`},
+		{name: "get not logged", enabled: true, method: http.MethodGet},
+		{name: "mutation logged with redacted body", enabled: true, method: http.MethodPost, body: `{"name":"a","password":"x"}`, wantLogs: 1,
+			wantBody: map[string]interface{}{"name": "a", "password": "[REDACTED]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+
+			var received string
+			handler := VerboseLogger(utils.VerboseLogConfig{Enabled: tt.enabled, MaxBodyBytes: 1024})(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					b, _ := io.ReadAll(r.Body)
+					received = string(b)
+					w.WriteHeader(http.StatusCreated)
+				}))
+
+			r := httptest.NewRequest(tt.method, "/api/admin/users", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			r = r.WithContext(utils.WithLogger(r.Context(), zap.New(core)))
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			// Handler tetap menerima body utuh
+			if received != tt.body {
+				t.Errorf("handler body = %q, want %q", received, tt.body)
+			}
+			if logs.Len() != tt.wantLogs {
+				t.Fatalf("log entries = %d, want %d", logs.Len(), tt.wantLogs)
+			}
+			if tt.wantLogs == 0 {
+				return
+			}
+
+			fields := logs.All()[0].ContextMap()
+			if fields["status"] != int64(http.StatusCreated) {
+				t.Errorf("status field = %v, want %d", fields["status"], http.StatusCreated)
+			}
+			if !reflect.DeepEqual(fields["body"], tt.wantBody) {
+				t.Errorf("body field = %v, want %v", fields["body"], tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestVerboseLoggerTruncatedBodyStillForwarded(t *testing.T) {
+	body := strings.Repeat("x", 50)
+
+	var received string
+	handler := VerboseLogger(utils.VerboseLogConfig{Enabled: true, MaxBodyBytes: 10})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			received = string(b)
+		}))
+
+	r := httptest.NewRequest(http.MethodPut, "/api/admin/products/1", strings.NewReader(body))
+	r = r.WithContext(utils.WithLogger(r.Context(), zap.NewNop()))
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	if received != body {
+		t.Errorf("handler received %d bytes, want %d", len(received), len(body))
+	}
+}
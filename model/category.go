@@ -1,7 +1,10 @@
 package model
 
+import "github.com/google/uuid"
+
 type Category struct {
 	BaseModel
-	Name        string `db:"name" json:"name"`
-	Description string `db:"description" json:"description,omitempty"`
+	ParentID    *uuid.UUID `db:"parent_id" json:"parent_id,omitempty"` // nil = root
+	Name        string     `db:"name" json:"name"`
+	Description string     `db:"description" json:"description,omitempty"`
 }
@@ -1,15 +1,67 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProductStatus - discontinued tetap tampil di histori & report, tapi tidak bisa dijual
+type ProductStatus string
+
+const (
+	ProductStatusActive       ProductStatus = "active"
+	ProductStatusDiscontinued ProductStatus = "discontinued"
+)
 
 type Product struct {
 	BaseModel
-	CategoryID    uuid.UUID `db:"category_id" json:"category_id"`
-	ShelfID       uuid.UUID `db:"shelf_id" json:"shelf_id"`
-	Name          string    `db:"name" json:"name"`
-	Description   string    `db:"description" json:"description,omitempty"`
-	UnitPrice     float64   `db:"unit_price" json:"unit_price"`
-	CostPrice     float64   `db:"cost_price" json:"cost_price"`
-	StockQuantity int       `db:"stock_quantity" json:"stock_quantity"`
-	MinStockLevel int       `db:"min_stock_level" json:"min_stock_level"`
+	CategoryID      uuid.UUID     `db:"category_id" json:"category_id"`
+	ShelfID         uuid.UUID     `db:"shelf_id" json:"shelf_id"`
+	SKU             *string       `db:"sku" json:"sku,omitempty"` // nil = belum ada SKU
+	Name            string        `db:"name" json:"name"`
+	Description     string        `db:"description" json:"description,omitempty"`
+	UnitPrice       float64       `db:"unit_price" json:"unit_price"`
+	CostPrice       float64       `db:"cost_price" json:"cost_price"`
+	StockQuantity   int           `db:"stock_quantity" json:"stock_quantity"`
+	MinStockLevel   int           `db:"min_stock_level" json:"min_stock_level"`
+	ReorderQuantity int           `db:"reorder_quantity" json:"reorder_quantity"` // target stok setelah restock, 0 = tidak ada
+	ImageURL        string        `db:"image_url" json:"image_url,omitempty"`
+	ExpiryDate      *time.Time    `db:"expiry_date" json:"expiry_date,omitempty"` // nil = tidak kedaluwarsa
+	Status          ProductStatus `db:"status" json:"status"`
+}
+
+// ProductLocation - posisi produk (warehouse / shelf), nil jika shelf/warehouse sudah di-soft delete
+type ProductLocation struct {
+	ProductID     uuid.UUID  `db:"product_id" json:"product_id"`
+	ProductName   string     `db:"product_name" json:"product_name"`
+	ShelfID       *uuid.UUID `db:"shelf_id" json:"shelf_id"`
+	ShelfCode     *string    `db:"shelf_code" json:"shelf_code"`
+	ShelfName     *string    `db:"shelf_name" json:"shelf_name"`
+	WarehouseID   *uuid.UUID `db:"warehouse_id" json:"warehouse_id"`
+	WarehouseCode *string    `db:"warehouse_code" json:"warehouse_code"`
+	WarehouseName *string    `db:"warehouse_name" json:"warehouse_name"`
+}
+
+// ProductStockLocation - stok produk di satu rak, shelf/warehouse info nil jika sudah di-soft delete
+type ProductStockLocation struct {
+	ProductID     uuid.UUID  `db:"product_id" json:"product_id"`
+	ShelfID       uuid.UUID  `db:"shelf_id" json:"shelf_id"`
+	ShelfCode     *string    `db:"shelf_code" json:"shelf_code"`
+	ShelfName     *string    `db:"shelf_name" json:"shelf_name"`
+	WarehouseID   *uuid.UUID `db:"warehouse_id" json:"warehouse_id"`
+	WarehouseCode *string    `db:"warehouse_code" json:"warehouse_code"`
+	WarehouseName *string    `db:"warehouse_name" json:"warehouse_name"`
+	Quantity      int        `db:"quantity" json:"quantity"`
+}
+
+// SKUHistory - satu perubahan SKU produk, nil = tidak ada SKU
+type SKUHistory struct {
+	ID                uuid.UUID  `db:"id" json:"id"`
+	ProductID         uuid.UUID  `db:"product_id" json:"product_id"`
+	OldSKU            *string    `db:"old_sku" json:"old_sku"`
+	NewSKU            *string    `db:"new_sku" json:"new_sku"`
+	ChangedBy         *uuid.UUID `db:"changed_by" json:"changed_by"`
+	ChangedByUsername *string    `db:"changed_by_username" json:"changed_by_username"` // nil jika user tidak ditemukan
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
 }
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReplenishmentStatus - pending sampai di-review admin (approved/rejected)
+type ReplenishmentStatus string
+
+const (
+	ReplenishmentStatusPending  ReplenishmentStatus = "pending"
+	ReplenishmentStatusApproved ReplenishmentStatus = "approved"
+	ReplenishmentStatusRejected ReplenishmentStatus = "rejected"
+)
+
+// ReplenishmentRequest permintaan restock dari staff
+// Approve tidak menambah stok otomatis, stok tetap di-update lewat /stock saat barang datang
+type ReplenishmentRequest struct {
+	BaseModel
+	ProductID         uuid.UUID           `db:"product_id" json:"product_id"`
+	RequestedBy       uuid.UUID           `db:"requested_by" json:"requested_by"`
+	RequestedQuantity int                 `db:"requested_quantity" json:"requested_quantity"`
+	Notes             *string             `db:"notes" json:"notes,omitempty"`
+	Status            ReplenishmentStatus `db:"status" json:"status"`
+	ReviewedBy        *uuid.UUID          `db:"reviewed_by" json:"reviewed_by,omitempty"`
+	ReviewedAt        *time.Time          `db:"reviewed_at" json:"reviewed_at,omitempty"`
+	ReviewNotes       *string             `db:"review_notes" json:"review_notes,omitempty"`
+}
@@ -15,13 +15,44 @@ const (
 	SaleStatusCancelled SaleStatus = "cancelled"
 )
 
+// PaymentMethod cara bayar sale
+type PaymentMethod string
+
+const (
+	PaymentMethodCash     PaymentMethod = "cash"
+	PaymentMethodCard     PaymentMethod = "card"
+	PaymentMethodTransfer PaymentMethod = "transfer"
+)
+
+// PaymentMethods semua cara bayar, urutan tetap untuk report
+var PaymentMethods = []PaymentMethod{PaymentMethodCash, PaymentMethodCard, PaymentMethodTransfer}
+
+// PaymentStatus status pembayaran sale, terpisah dari SaleStatus (fulfillment)
+type PaymentStatus string
+
+const (
+	PaymentStatusUnpaid  PaymentStatus = "unpaid"
+	PaymentStatusPartial PaymentStatus = "partial"
+	PaymentStatusPaid    PaymentStatus = "paid"
+)
+
+// SaleStatuses semua status sale, urutan tetap untuk breakdown/report
+var SaleStatuses = []SaleStatus{SaleStatusPending, SaleStatusCompleted, SaleStatusCancelled}
+
 // Sale represents a sales transaction
 type Sale struct {
 	BaseModel
-	InvoiceNumber string     `db:"invoice_number" json:"invoice_number"`
-	UserID        uuid.UUID  `db:"user_id" json:"user_id"`
-	TotalAmount   float64    `db:"total_amount" json:"total_amount"`
-	Status        SaleStatus `db:"status" json:"status"`
+	InvoiceNumber   string        `db:"invoice_number" json:"invoice_number"`
+	UserID          uuid.UUID     `db:"user_id" json:"user_id"`
+	TotalAmount     float64       `db:"total_amount" json:"total_amount"`
+	Status          SaleStatus    `db:"status" json:"status"`
+	PaymentMethod   PaymentMethod `db:"payment_method" json:"payment_method"`
+	CustomerName    *string       `db:"customer_name" json:"customer_name,omitempty"`
+	CustomerPhone   *string       `db:"customer_phone" json:"customer_phone,omitempty"`
+	PaymentStatus   PaymentStatus `db:"payment_status" json:"payment_status"`
+	AmountPaid      float64       `db:"amount_paid" json:"amount_paid"`
+	CancelledReason *string       `db:"cancelled_reason" json:"cancelled_reason,omitempty"`
+	CancelledAt     *time.Time    `db:"cancelled_at" json:"cancelled_at,omitempty"`
 }
 
 // SaleItem represents individual product sold in a sale
@@ -32,6 +63,7 @@ type SaleItem struct {
 	Quantity   int       `db:"quantity" json:"quantity"`
 	UnitPrice  float64   `db:"unit_price" json:"unit_price"`
 	TotalPrice float64   `db:"total_price" json:"total_price"`
+	Notes      string    `db:"notes" json:"notes,omitempty"`
 }
 
 // SaleItemWithProduct combines sale item with product details for reporting
@@ -40,6 +72,53 @@ type SaleItemWithProduct struct {
 	ProductName string `db:"product_name" json:"product_name"`
 }
 
+// SaleWithCashier sale plus kasir yang membuat (untuk export/arsip)
+type SaleWithCashier struct {
+	Sale
+	CashierUsername string `db:"cashier_username" json:"cashier_username"`
+	CashierFullName string `db:"cashier_full_name" json:"cashier_full_name"`
+}
+
+// SaleItemDetail sale item dengan nama produk & kategori (untuk export/arsip)
+type SaleItemDetail struct {
+	SaleItemWithProduct
+	CategoryID   uuid.UUID `db:"category_id" json:"category_id"`
+	CategoryName string    `db:"category_name" json:"category_name"`
+}
+
+// ProductSaleHistory represents one sale line of a product (per invoice)
+type ProductSaleHistory struct {
+	SaleID        uuid.UUID  `db:"sale_id" json:"sale_id"`
+	InvoiceNumber string     `db:"invoice_number" json:"invoice_number"`
+	Status        SaleStatus `db:"status" json:"status"`
+	Quantity      int        `db:"quantity" json:"quantity"`
+	UnitPrice     float64    `db:"unit_price" json:"unit_price"`
+	TotalPrice    float64    `db:"total_price" json:"total_price"`
+	SoldAt        time.Time  `db:"sold_at" json:"sold_at"`
+}
+
+// SaleItemLedgerEntry one sale line with invoice & product name (flat ledger across sales)
+type SaleItemLedgerEntry struct {
+	ID            uuid.UUID  `db:"id" json:"id"`
+	SaleID        uuid.UUID  `db:"sale_id" json:"sale_id"`
+	InvoiceNumber string     `db:"invoice_number" json:"invoice_number"`
+	Status        SaleStatus `db:"status" json:"status"`
+	ProductID     uuid.UUID  `db:"product_id" json:"product_id"`
+	ProductName   string     `db:"product_name" json:"product_name"`
+	Quantity      int        `db:"quantity" json:"quantity"`
+	UnitPrice     float64    `db:"unit_price" json:"unit_price"`
+	TotalPrice    float64    `db:"total_price" json:"total_price"`
+	SoldAt        time.Time  `db:"sold_at" json:"sold_at"`
+}
+
+// DailySales aggregated sales of one day (completed sales only)
+type DailySales struct {
+	Date       time.Time `db:"date" json:"date"`
+	SalesCount int       `db:"sales_count" json:"sales_count"`
+	ItemsSold  int       `db:"items_sold" json:"items_sold"`
+	Revenue    float64   `db:"revenue" json:"revenue"`
+}
+
 // SalesReport contains aggregated sales data for reporting
 type SalesReport struct {
 	TotalSales     int       `json:"total_sales"`
@@ -49,3 +128,9 @@ type SalesReport struct {
 	StartDate      time.Time `json:"start_date"`
 	EndDate        time.Time `json:"end_date"`
 }
+
+// SaleStatusCount jumlah sale per status dalam satu periode
+type SaleStatusCount struct {
+	Status SaleStatus `db:"status" json:"status"`
+	Count  int        `db:"count" json:"count"`
+}
@@ -12,6 +12,8 @@ type Session struct {
 	Token     uuid.UUID  `db:"token" json:"token"`
 	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
 	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	IPAddress *string    `db:"ip_address" json:"ip_address,omitempty"` // nil untuk session lama / tidak diketahui
+	UserAgent *string    `db:"user_agent" json:"user_agent,omitempty"`
 	CreatedAt time.Time  `db:"created_at" json:"created_at"`
 }
 
@@ -19,3 +21,13 @@ func (s *Session) IsValid() bool {
 	now := time.Now()
 	return s.RevokedAt == nil && s.ExpiresAt.After(now)
 }
+
+// UserSessionCount - jumlah session aktif (belum revoked & belum expired) per user
+type UserSessionCount struct {
+	UserID        uuid.UUID `db:"user_id"`
+	Username      string    `db:"username"`
+	Email         string    `db:"email"`
+	Role          UserRole  `db:"role"`
+	ActiveCount   int       `db:"active_count"`
+	LastCreatedAt time.Time `db:"last_created_at"` // login terakhir yang masih aktif
+}
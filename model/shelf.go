@@ -5,5 +5,9 @@ import "github.com/google/uuid"
 type Shelf struct {
 	BaseModel
 	WarehouseID uuid.UUID `db:"warehouse_id" json:"warehouse_id"`
+	Code        string    `db:"code" json:"code"`
 	Name        string    `db:"name" json:"name"`
+
+	// Hasil agregasi (bukan kolom tabel), diisi oleh query list
+	ProductCount int `db:"-" json:"product_count"`
 }
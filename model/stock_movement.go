@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockMovementType - jenis perubahan stok di ledger
+type StockMovementType string
+
+const (
+	StockMovementSale                StockMovementType = "sale"       // stok keluar karena penjualan
+	StockMovementRestock             StockMovementType = "restock"    // update stok manual yang menambah stok
+	StockMovementAdjustment          StockMovementType = "adjustment" // koreksi manual lain (edit produk, stok per rak, stok berkurang)
+	StockMovementCancellationRestore StockMovementType = "cancellation_restore"
+	StockMovementTransfer            StockMovementType = "transfer" // pindah rak/warehouse, berpasangan (- di asal, + di tujuan)
+)
+
+// StockMovementTypes urutan tetap untuk response (summary selalu berisi semua jenis)
+var StockMovementTypes = []StockMovementType{
+	StockMovementSale,
+	StockMovementRestock,
+	StockMovementAdjustment,
+	StockMovementCancellationRestore,
+	StockMovementTransfer,
+}
+
+// StockMovement - satu baris ledger stok, Quantity bertanda (+ masuk / - keluar)
+type StockMovement struct {
+	ID           uuid.UUID         `db:"id" json:"id"`
+	ProductID    uuid.UUID         `db:"product_id" json:"product_id"`
+	MovementType StockMovementType `db:"movement_type" json:"movement_type"`
+	Quantity     int               `db:"quantity" json:"quantity"`
+	StockAfter   int               `db:"stock_after" json:"stock_after"`
+	ReferenceID  *uuid.UUID        `db:"reference_id" json:"reference_id,omitempty"`
+	Notes        *string           `db:"notes" json:"notes,omitempty"`
+	CreatedBy    *uuid.UUID        `db:"created_by" json:"created_by,omitempty"` // nil = movement lama / tanpa user
+	CreatedAt    time.Time         `db:"created_at" json:"created_at"`
+}
+
+// StockMovementFeedEntry - movement + nama produk & user untuk activity feed
+type StockMovementFeedEntry struct {
+	StockMovement
+	ProductName string  `db:"product_name" json:"product_name"`
+	ProductSKU  *string `db:"product_sku" json:"product_sku,omitempty"`
+	Username    *string `db:"username" json:"username,omitempty"`
+}
+
+// StockMovementSummary - total movement satu produk per jenis dalam satu periode
+type StockMovementSummary struct {
+	MovementType  StockMovementType `db:"movement_type" json:"movement_type"`
+	MovementCount int               `db:"movement_count" json:"movement_count"`
+	QuantityIn    int               `db:"quantity_in" json:"quantity_in"`
+	QuantityOut   int               `db:"quantity_out" json:"quantity_out"`
+}
@@ -8,6 +8,9 @@ const (
 	RoleStaff      UserRole = "staff"
 )
 
+// UserRoles semua role, urut dari hak akses tertinggi
+var UserRoles = []UserRole{RoleSuperAdmin, RoleAdmin, RoleStaff}
+
 type User struct {
 	BaseModel
 	Username     string   `db:"username" json:"username"`
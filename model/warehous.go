@@ -2,6 +2,8 @@ package model
 
 type Warehouse struct {
 	BaseModel
-	Name    string `db:"name" json:"name"`
-	Address string `db:"address" json:"address"`
+	Code     string `db:"code" json:"code"`
+	Name     string `db:"name" json:"name"`
+	Address  string `db:"address" json:"address"`
+	IsActive bool   `db:"is_active" json:"is_active"` // false = tutup sementara (bukan dihapus)
 }
@@ -0,0 +1,9 @@
+package model
+
+type Webhook struct {
+	BaseModel
+	URL        string   `db:"url" json:"url"`
+	EventTypes []string `db:"event_types" json:"event_types"` // sale.created, sale.status_changed, low_stock
+	Secret     string   `db:"secret" json:"-"`                // untuk HMAC signature, tidak pernah dikirim ke client
+	IsActive   bool     `db:"is_active" json:"is_active"`
+}
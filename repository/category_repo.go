@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"inventory-system/database"
 	"inventory-system/model"
+	"inventory-system/utils"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,10 +16,15 @@ type CategoryRepo interface {
 	Create(ctx context.Context, category *model.Category) error
 	FindByID(ctx context.Context, id uuid.UUID) (*model.Category, error)
 	FindByName(ctx context.Context, code string) (*model.Category, error)
-	FindAll(ctx context.Context, limit int, offset int) ([]model.Category, error)
-	CountAll(ctx context.Context) (int, error)
+	FindAll(ctx context.Context, limit int, offset int, includeDeleted bool) ([]model.Category, error)
+	CountAll(ctx context.Context, includeDeleted bool) (int, error)
 	Update(ctx context.Context, category *model.Category) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	CountProducts(ctx context.Context, id uuid.UUID) (int, error)
+	FindChildren(ctx context.Context, parentID uuid.UUID) ([]model.Category, error)
+	FindAllActive(ctx context.Context) ([]model.Category, error)
+	CountChildren(ctx context.Context, id uuid.UUID) (int, error)
+	Merge(ctx context.Context, sourceID, targetID uuid.UUID) (int, error)
 }
 
 type categoryRepo struct {
@@ -32,8 +38,8 @@ func NewCategoryRepo(db database.PgxIface, log *zap.Logger) CategoryRepo {
 
 func (cr *categoryRepo) Create(ctx context.Context, category *model.Category) error {
 	query := `
-		INSERT INTO categories (id, name, description, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO categories (id, parent_id, name, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 	// Generate metadata sebelum insert
 	now := time.Now()
@@ -44,13 +50,14 @@ func (cr *categoryRepo) Create(ctx context.Context, category *model.Category) er
 	// Execute INSERT statement
 	_, err := cr.db.Exec(ctx, query,
 		category.ID,
+		category.ParentID,
 		category.Name,
 		category.Description,
 		category.CreatedAt,
 		category.UpdatedAt,
 	)
 	if err != nil {
-		cr.log.Error("Failed to create category",
+		utils.LoggerFromContext(ctx).Error("Failed to create category",
 			zap.Error(err),
 			zap.String("name", category.Name),
 		)
@@ -58,7 +65,7 @@ func (cr *categoryRepo) Create(ctx context.Context, category *model.Category) er
 	}
 
 	// Log success untuk audit trail
-	cr.log.Info("Warehouse Created",
+	utils.LoggerFromContext(ctx).Info("Warehouse Created",
 		zap.String("id", category.ID.String()),
 		zap.String("name", category.Name),
 	)
@@ -68,7 +75,7 @@ func (cr *categoryRepo) Create(ctx context.Context, category *model.Category) er
 
 func (cr *categoryRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
 	query := `
-		SELECT id, name, description, created_at, updated_at, deleted_at
+		SELECT id, parent_id, name, description, created_at, updated_at, deleted_at
 		FROM categories WHERE id = $1 AND deleted_at IS NULL
 	`
 
@@ -77,6 +84,7 @@ func (cr *categoryRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Cate
 	// Query single row berdasarkan ID
 	if err := cr.db.QueryRow(ctx, query, id).Scan(
 		&category.ID,
+		&category.ParentID,
 		&category.Name,
 		&category.Description,
 		&category.CreatedAt,
@@ -91,7 +99,7 @@ func (cr *categoryRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Cate
 
 func (cr *categoryRepo) FindByName(ctx context.Context, name string) (*model.Category, error) {
 	query := `
-		SELECT id, name, description, created_at, updated_at, deleted_at
+		SELECT id, parent_id, name, description, created_at, updated_at, deleted_at
 		FROM categories WHERE name = $1 AND deleted_at IS NULL
 	`
 
@@ -100,13 +108,14 @@ func (cr *categoryRepo) FindByName(ctx context.Context, name string) (*model.Cat
 	// Query single row berdasarkan Name
 	if err := cr.db.QueryRow(ctx, query, name).Scan(
 		&category.ID,
+		&category.ParentID,
 		&category.Name,
 		&category.Description,
 		&category.CreatedAt,
 		&category.UpdatedAt,
 		&category.DeletedAt,
 	); err != nil {
-		cr.log.Warn("Category not found",
+		utils.LoggerFromContext(ctx).Warn("Category not found",
 			zap.String("name", name),
 			zap.Error(err),
 		)
@@ -117,18 +126,18 @@ func (cr *categoryRepo) FindByName(ctx context.Context, name string) (*model.Cat
 }
 
 // FindAll dengan pagination
-func (cr *categoryRepo) FindAll(ctx context.Context, limit int, offset int) ([]model.Category, error) {
-	query := `
-        SELECT id, name, description, created_at, updated_at, deleted_at
+func (cr *categoryRepo) FindAll(ctx context.Context, limit int, offset int, includeDeleted bool) ([]model.Category, error) {
+	query := fmt.Sprintf(`
+        SELECT id, parent_id, name, description, created_at, updated_at, deleted_at
         FROM categories 
-        WHERE deleted_at IS NULL
+        %s
         ORDER BY created_at DESC
         LIMIT $1 OFFSET $2
-    `
+    `, activeFilter(includeDeleted))
 
 	rows, err := cr.db.Query(ctx, query, limit, offset)
 	if err != nil {
-		cr.log.Error("Failed to query categories", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to query categories", zap.Error(err))
 		return nil, fmt.Errorf("query categories failed: %w", err)
 	}
 	defer rows.Close()
@@ -137,22 +146,22 @@ func (cr *categoryRepo) FindAll(ctx context.Context, limit int, offset int) ([]m
 	for rows.Next() {
 		var category model.Category
 		err := rows.Scan(
-			&category.ID, &category.Name, &category.Description,
+			&category.ID, &category.ParentID, &category.Name, &category.Description,
 			&category.CreatedAt, &category.UpdatedAt, &category.DeletedAt,
 		)
 		if err != nil {
-			cr.log.Error("Failed to scan category", zap.Error(err))
+			utils.LoggerFromContext(ctx).Error("Failed to scan category", zap.Error(err))
 			return nil, fmt.Errorf("scan category failed: %w", err)
 		}
 		categories = append(categories, category)
 	}
 
 	if err = rows.Err(); err != nil {
-		cr.log.Error("Rows iteration error", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Rows iteration error", zap.Error(err))
 		return nil, fmt.Errorf("rows iteration failed: %w", err)
 	}
 
-	cr.log.Info("Fetched categories with pagination",
+	utils.LoggerFromContext(ctx).Info("Fetched categories with pagination",
 		zap.Int("limit", limit),
 		zap.Int("offset", offset),
 		zap.Int("count", len(categories)))
@@ -161,13 +170,13 @@ func (cr *categoryRepo) FindAll(ctx context.Context, limit int, offset int) ([]m
 }
 
 // CountAll menghitung total categories aktif
-func (cr *categoryRepo) CountAll(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM categories WHERE deleted_at IS NULL`
+func (cr *categoryRepo) CountAll(ctx context.Context, includeDeleted bool) (int, error) {
+	query := `SELECT COUNT(*) FROM categories ` + activeFilter(includeDeleted)
 
 	var count int
 	err := cr.db.QueryRow(ctx, query).Scan(&count)
 	if err != nil {
-		cr.log.Error("Failed to count categories", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to count categories", zap.Error(err))
 		return 0, fmt.Errorf("count categories failed: %w", err)
 	}
 
@@ -177,20 +186,21 @@ func (cr *categoryRepo) CountAll(ctx context.Context) (int, error) {
 func (cr *categoryRepo) Update(ctx context.Context, category *model.Category) error {
 	query := `
 		UPDATE categories
-		SET name = $1, description = $2, updated_at = $3
-		WHERE id = $4 AND deleted_at IS NULL
+		SET parent_id = $1, name = $2, description = $3, updated_at = $4
+		WHERE id = $5 AND deleted_at IS NULL
 	`
 
 	category.UpdatedAt = time.Now()
 
 	result, err := cr.db.Exec(ctx, query,
+		category.ParentID,
 		category.Name,
 		category.Description,
 		category.UpdatedAt,
 		category.ID,
 	)
 	if err != nil {
-		cr.log.Error("Failed to update category",
+		utils.LoggerFromContext(ctx).Error("Failed to update category",
 			zap.Error(err),
 			zap.String("id", category.ID.String()),
 		)
@@ -201,7 +211,7 @@ func (cr *categoryRepo) Update(ctx context.Context, category *model.Category) er
 		return fmt.Errorf("warehouse not found")
 	}
 
-	cr.log.Info("Category updated", zap.String("id", category.ID.String()))
+	utils.LoggerFromContext(ctx).Info("Category updated", zap.String("id", category.ID.String()))
 	return nil
 }
 
@@ -215,7 +225,7 @@ func (cr *categoryRepo) Delete(ctx context.Context, id uuid.UUID) error {
 
 	result, err := cr.db.Exec(ctx, query, now, id)
 	if err != nil {
-		cr.log.Error("Failed to delete category",
+		utils.LoggerFromContext(ctx).Error("Failed to delete category",
 			zap.Error(err),
 			zap.String("id", id.String()),
 		)
@@ -226,6 +236,141 @@ func (cr *categoryRepo) Delete(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("category not found")
 	}
 
-	cr.log.Info("Category deleted", zap.String("id", id.String()))
+	utils.LoggerFromContext(ctx).Info("Category deleted", zap.String("id", id.String()))
 	return nil
 }
+
+// CountProducts menghitung produk aktif dalam category
+func (cr *categoryRepo) CountProducts(ctx context.Context, id uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM products WHERE category_id = $1 AND deleted_at IS NULL`
+
+	var count int
+	if err := cr.db.QueryRow(ctx, query, id).Scan(&count); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to count category products",
+			zap.Error(err),
+			zap.String("id", id.String()),
+		)
+		return 0, fmt.Errorf("count category products failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// FindChildren child langsung (satu level) dari parentID
+func (cr *categoryRepo) FindChildren(ctx context.Context, parentID uuid.UUID) ([]model.Category, error) {
+	query := `
+		SELECT id, parent_id, name, description, created_at, updated_at, deleted_at
+		FROM categories
+		WHERE parent_id = $1 AND deleted_at IS NULL
+		ORDER BY name
+	`
+
+	return cr.queryCategories(ctx, query, parentID)
+}
+
+// FindAllActive semua category aktif tanpa pagination, untuk susun tree
+func (cr *categoryRepo) FindAllActive(ctx context.Context) ([]model.Category, error) {
+	query := `
+		SELECT id, parent_id, name, description, created_at, updated_at, deleted_at
+		FROM categories
+		WHERE deleted_at IS NULL
+		ORDER BY name
+	`
+
+	return cr.queryCategories(ctx, query)
+}
+
+// CountChildren menghitung child aktif (untuk blokir delete parent)
+func (cr *categoryRepo) CountChildren(ctx context.Context, id uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM categories WHERE parent_id = $1 AND deleted_at IS NULL`
+
+	var count int
+	if err := cr.db.QueryRow(ctx, query, id).Scan(&count); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to count child categories",
+			zap.Error(err),
+			zap.String("id", id.String()),
+		)
+		return 0, fmt.Errorf("count child categories failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// queryCategories helper: jalankan query list & scan ke slice
+func (cr *categoryRepo) queryCategories(ctx context.Context, query string, args ...interface{}) ([]model.Category, error) {
+	rows, err := cr.db.Query(ctx, query, args...)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query categories", zap.Error(err))
+		return nil, fmt.Errorf("query categories failed: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make([]model.Category, 0)
+	for rows.Next() {
+		var category model.Category
+		err := rows.Scan(
+			&category.ID, &category.ParentID, &category.Name, &category.Description,
+			&category.CreatedAt, &category.UpdatedAt, &category.DeletedAt,
+		)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan category", zap.Error(err))
+			return nil, fmt.Errorf("scan category failed: %w", err)
+		}
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return categories, nil
+}
+
+// Merge pindahkan semua produk dari source ke target lalu soft delete source (satu transaction)
+// Produk yang sudah soft delete ikut dipindah supaya tidak ada yang menunjuk ke category terhapus
+func (cr *categoryRepo) Merge(ctx context.Context, sourceID, targetID uuid.UUID) (int, error) {
+	tx, err := cr.db.Begin(ctx)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to begin transaction", zap.Error(err))
+		return 0, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	// Rollback no-op jika sudah commit
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+
+	result, err := tx.Exec(ctx,
+		`UPDATE products SET category_id = $1, updated_at = $2 WHERE category_id = $3`,
+		targetID, now, sourceID,
+	)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to move products to target category", zap.Error(err))
+		return 0, fmt.Errorf("move products failed: %w", err)
+	}
+	moved := int(result.RowsAffected())
+
+	result, err = tx.Exec(ctx,
+		`UPDATE categories SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`,
+		now, sourceID,
+	)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to delete source category", zap.Error(err))
+		return 0, fmt.Errorf("delete category failed: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return 0, fmt.Errorf("category not found")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to commit category merge", zap.Error(err))
+		return 0, fmt.Errorf("commit category merge failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Category merged",
+		zap.String("source_id", sourceID.String()),
+		zap.String("target_id", targetID.String()),
+		zap.Int("moved_products", moved),
+	)
+
+	return moved, nil
+}
@@ -1,27 +1,51 @@
 package repository
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"inventory-system/database"
 	"inventory-system/model"
+	"inventory-system/utils"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
 type ProductRepo interface {
 	Create(ctx context.Context, product *model.Product) error
 	FindByID(ctx context.Context, id uuid.UUID) (*model.Product, error)
+	FindBySKU(ctx context.Context, sku string) (*model.Product, error)
 	FindByCategoryID(ctx context.Context, categoryID uuid.UUID) ([]model.Product, error)
 	FindByShelfID(ctx context.Context, shelfID uuid.UUID) ([]model.Product, error)
-	FindAll(ctx context.Context, limit int, offset int) ([]model.Product, error)
-	CountAll(ctx context.Context) (int, error)
+	FindAll(ctx context.Context, limit int, offset int, includeDeleted bool) ([]model.Product, error)
+	CountAll(ctx context.Context, includeDeleted bool) (int, error)
 	FindLowStock(ctx context.Context) ([]model.Product, error)
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]model.Product, error)
+	FindCriticalStock(ctx context.Context, percent float64) ([]model.Product, error)
+	FindExpiringBefore(ctx context.Context, date time.Time) ([]model.Product, error)
+	FindStaleStock(ctx context.Context, before time.Time) ([]model.Product, error)
+	FindStockUpTo(ctx context.Context, threshold int) ([]model.Product, error)
 	Update(ctx context.Context, product *model.Product) error
-	UpdateStock(ctx context.Context, id uuid.UUID, quantity int) error
+	UpdateWithChanges(ctx context.Context, u ProductUpdate) error
+	UpdateStock(ctx context.Context, id uuid.UUID, quantity int, movement model.StockMovement) error
+	ApplyStockCounts(ctx context.Context, counts map[uuid.UUID]int, movement model.StockMovement) (map[uuid.UUID]int, error)
+	RestockBatch(ctx context.Context, quantities map[uuid.UUID]int, movement model.StockMovement) (map[uuid.UUID]int, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status model.ProductStatus) error
+	UpdateSKU(ctx context.Context, id uuid.UUID, sku *string, changedBy *uuid.UUID) error
+	FindSKUHistory(ctx context.Context, productID uuid.UUID) ([]model.SKUHistory, error)
+	FindLocation(ctx context.Context, productID uuid.UUID) (*model.ProductLocation, error)
+	UpdateCategoryBatch(ctx context.Context, ids []uuid.UUID, categoryID uuid.UUID) (int, error)
+	UpdateMinStockBatch(ctx context.Context, levels map[uuid.UUID]int) (map[uuid.UUID]bool, error)
+	UpdateStatusBatch(ctx context.Context, ids []uuid.UUID, status model.ProductStatus) (int, error)
+	AdjustPricesByCategory(ctx context.Context, categoryID uuid.UUID, unitPercent, costPercent float64, changedBy *uuid.UUID) (int, error)
 	CheckStock(ctx context.Context, id uuid.UUID, requiredQuantity int) (*model.Product, error)
+	FindStockByIDs(ctx context.Context, ids []uuid.UUID) ([]model.Product, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
@@ -37,31 +61,38 @@ func NewProductRepo(db database.PgxIface, log *zap.Logger) ProductRepo {
 func (pr *productRepo) Create(ctx context.Context, product *model.Product) error {
 	query := `
 		INSERT INTO products (
-    		id, category_id, shelf_id, name, description, 
-    		unit_price, cost_price, stock_quantity, min_stock_level,
+    		id, category_id, shelf_id, sku, name, description, 
+    		unit_price, cost_price, stock_quantity, min_stock_level, reorder_quantity, image_url, expiry_date, status,
     		created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	`
 	// Generate metadata sebelum insert
 	now := time.Now()
 	product.ID = uuid.New()
 	product.CreatedAt = now
 	product.UpdatedAt = now
+	if product.Status == "" {
+		product.Status = model.ProductStatusActive
+	}
 
 	// Execute INSERT statement
 	_, err := pr.db.Exec(ctx, query,
-		product.ID, product.CategoryID, product.ShelfID, product.Name,
+		product.ID, product.CategoryID, product.ShelfID, product.SKU, product.Name,
 		product.Description, product.UnitPrice, product.CostPrice, product.StockQuantity,
-		product.MinStockLevel, product.CreatedAt, product.UpdatedAt,
+		product.MinStockLevel, product.ReorderQuantity, product.ImageURL, product.ExpiryDate, product.Status, product.CreatedAt, product.UpdatedAt,
 	)
 	if err != nil {
-		pr.log.Error("Failed to create product", zap.Error(err),
+		// Unique index jadi pengaman terakhir kalau ada create bersamaan
+		if isUniqueViolation(err) {
+			return fmt.Errorf("product sku already exists")
+		}
+		utils.LoggerFromContext(ctx).Error("Failed to create product", zap.Error(err),
 			zap.String("name", product.Name),
 		)
 		return fmt.Errorf("Create product failed: %w", err)
 	}
 
-	pr.log.Info("Product created",
+	utils.LoggerFromContext(ctx).Info("Product created",
 		zap.String("id", product.ID.String()),
 		zap.String("name", product.Name),
 	)
@@ -71,8 +102,8 @@ func (pr *productRepo) Create(ctx context.Context, product *model.Product) error
 func (pr *productRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Product, error) {
 	query := `
 		SELECT 
-			id, category_id, shelf_id, name, description,
-			unit_price, cost_price, stock_quantity, min_stock_level,
+			id, category_id, shelf_id, sku, name, description,
+			unit_price, cost_price, stock_quantity, min_stock_level, reorder_quantity, image_url, expiry_date, status,
 			created_at, updated_at, deleted_at
 		FROM products 
 		WHERE id = $1 AND deleted_at IS NULL
@@ -81,9 +112,34 @@ func (pr *productRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Produ
 	var product model.Product
 
 	err := pr.db.QueryRow(ctx, query, id).Scan(
-		&product.ID, &product.CategoryID, &product.ShelfID, &product.Name,
+		&product.ID, &product.CategoryID, &product.ShelfID, &product.SKU, &product.Name,
 		&product.Description, &product.UnitPrice, &product.CostPrice, &product.StockQuantity,
-		&product.MinStockLevel, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
+		&product.MinStockLevel, &product.ReorderQuantity, &product.ImageURL, &product.ExpiryDate, &product.Status, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Product not found: %w", err)
+	}
+
+	return &product, nil
+}
+
+// FindBySKU produk aktif dengan SKU tersebut (SKU unik di antara produk aktif)
+func (pr *productRepo) FindBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	query := `
+		SELECT 
+			id, category_id, shelf_id, sku, name, description,
+			unit_price, cost_price, stock_quantity, min_stock_level, reorder_quantity, image_url, expiry_date, status,
+			created_at, updated_at, deleted_at
+		FROM products 
+		WHERE sku = $1 AND deleted_at IS NULL
+	`
+
+	var product model.Product
+
+	err := pr.db.QueryRow(ctx, query, sku).Scan(
+		&product.ID, &product.CategoryID, &product.ShelfID, &product.SKU, &product.Name,
+		&product.Description, &product.UnitPrice, &product.CostPrice, &product.StockQuantity,
+		&product.MinStockLevel, &product.ReorderQuantity, &product.ImageURL, &product.ExpiryDate, &product.Status, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("Product not found: %w", err)
@@ -95,8 +151,8 @@ func (pr *productRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Produ
 func (pr *productRepo) FindByCategoryID(ctx context.Context, categoryID uuid.UUID) ([]model.Product, error) {
 	query := `
         SELECT 
-            id, category_id, shelf_id, name, description,
-            unit_price, cost_price, stock_quantity, min_stock_level,
+            id, category_id, shelf_id, sku, name, description,
+            unit_price, cost_price, stock_quantity, min_stock_level, reorder_quantity, image_url, expiry_date, status,
             created_at, updated_at, deleted_at
         FROM products 
         WHERE category_id = $1 AND deleted_at IS NULL
@@ -105,7 +161,7 @@ func (pr *productRepo) FindByCategoryID(ctx context.Context, categoryID uuid.UUI
 
 	rows, err := pr.db.Query(ctx, query, categoryID)
 	if err != nil {
-		pr.log.Error("Failed to query products by category", zap.Error(err),
+		utils.LoggerFromContext(ctx).Error("Failed to query products by category", zap.Error(err),
 			zap.String("category_id", categoryID.String()))
 		return nil, fmt.Errorf("query products by category failed: %w", err)
 	}
@@ -115,23 +171,23 @@ func (pr *productRepo) FindByCategoryID(ctx context.Context, categoryID uuid.UUI
 	for rows.Next() {
 		var product model.Product
 		err := rows.Scan(
-			&product.ID, &product.CategoryID, &product.ShelfID, &product.Name,
+			&product.ID, &product.CategoryID, &product.ShelfID, &product.SKU, &product.Name,
 			&product.Description, &product.UnitPrice, &product.CostPrice, &product.StockQuantity,
-			&product.MinStockLevel, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
+			&product.MinStockLevel, &product.ReorderQuantity, &product.ImageURL, &product.ExpiryDate, &product.Status, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
 		)
 		if err != nil {
-			pr.log.Error("Failed to scan product", zap.Error(err))
+			utils.LoggerFromContext(ctx).Error("Failed to scan product", zap.Error(err))
 			return nil, fmt.Errorf("scan product failed: %w", err)
 		}
 		products = append(products, product)
 	}
 
 	if err = rows.Err(); err != nil {
-		pr.log.Error("Rows iteration error", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Rows iteration error", zap.Error(err))
 		return nil, fmt.Errorf("rows iteration failed: %w", err)
 	}
 
-	pr.log.Info("Fetched products by category",
+	utils.LoggerFromContext(ctx).Info("Fetched products by category",
 		zap.String("category_id", categoryID.String()),
 		zap.Int("count", len(products)))
 
@@ -141,8 +197,8 @@ func (pr *productRepo) FindByCategoryID(ctx context.Context, categoryID uuid.UUI
 func (pr *productRepo) FindByShelfID(ctx context.Context, shelfID uuid.UUID) ([]model.Product, error) {
 	query := `
         SELECT 
-            id, category_id, shelf_id, name, description,
-            unit_price, cost_price, stock_quantity, min_stock_level,
+            id, category_id, shelf_id, sku, name, description,
+            unit_price, cost_price, stock_quantity, min_stock_level, reorder_quantity, image_url, expiry_date, status,
             created_at, updated_at, deleted_at
         FROM products 
         WHERE shelf_id = $1 AND deleted_at IS NULL
@@ -151,7 +207,7 @@ func (pr *productRepo) FindByShelfID(ctx context.Context, shelfID uuid.UUID) ([]
 
 	rows, err := pr.db.Query(ctx, query, shelfID)
 	if err != nil {
-		pr.log.Error("Failed to query products by shelf", zap.Error(err),
+		utils.LoggerFromContext(ctx).Error("Failed to query products by shelf", zap.Error(err),
 			zap.String("shelf_id", shelfID.String()))
 		return nil, fmt.Errorf("query products by shelf failed: %w", err)
 	}
@@ -161,44 +217,44 @@ func (pr *productRepo) FindByShelfID(ctx context.Context, shelfID uuid.UUID) ([]
 	for rows.Next() {
 		var product model.Product
 		err := rows.Scan(
-			&product.ID, &product.CategoryID, &product.ShelfID, &product.Name,
+			&product.ID, &product.CategoryID, &product.ShelfID, &product.SKU, &product.Name,
 			&product.Description, &product.UnitPrice, &product.CostPrice, &product.StockQuantity,
-			&product.MinStockLevel, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
+			&product.MinStockLevel, &product.ReorderQuantity, &product.ImageURL, &product.ExpiryDate, &product.Status, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
 		)
 		if err != nil {
-			pr.log.Error("Failed to scan product", zap.Error(err))
+			utils.LoggerFromContext(ctx).Error("Failed to scan product", zap.Error(err))
 			return nil, fmt.Errorf("scan product failed: %w", err)
 		}
 		products = append(products, product)
 	}
 
 	if err = rows.Err(); err != nil {
-		pr.log.Error("Rows iteration error", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Rows iteration error", zap.Error(err))
 		return nil, fmt.Errorf("rows iteration failed: %w", err)
 	}
 
-	pr.log.Info("Fetched products by shelf",
+	utils.LoggerFromContext(ctx).Info("Fetched products by shelf",
 		zap.String("shelf_id", shelfID.String()),
 		zap.Int("count", len(products)))
 
 	return products, nil
 }
 
-func (pr *productRepo) FindAll(ctx context.Context, limit int, offset int) ([]model.Product, error) {
-	query := `
+func (pr *productRepo) FindAll(ctx context.Context, limit int, offset int, includeDeleted bool) ([]model.Product, error) {
+	query := fmt.Sprintf(`
         SELECT 
-            id, category_id, shelf_id, name, description,
-            unit_price, cost_price, stock_quantity, min_stock_level,
+            id, category_id, shelf_id, sku, name, description,
+            unit_price, cost_price, stock_quantity, min_stock_level, reorder_quantity, image_url, expiry_date, status,
             created_at, updated_at, deleted_at
         FROM products 
-        WHERE deleted_at IS NULL
+        %s
         ORDER BY created_at DESC
         LIMIT $1 OFFSET $2
-    `
+    `, activeFilter(includeDeleted))
 
 	rows, err := pr.db.Query(ctx, query, limit, offset)
 	if err != nil {
-		pr.log.Error("Failed to query products", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to query products", zap.Error(err))
 		return nil, fmt.Errorf("query products failed: %w", err)
 	}
 	defer rows.Close()
@@ -207,23 +263,23 @@ func (pr *productRepo) FindAll(ctx context.Context, limit int, offset int) ([]mo
 	for rows.Next() {
 		var product model.Product
 		err := rows.Scan(
-			&product.ID, &product.CategoryID, &product.ShelfID, &product.Name,
+			&product.ID, &product.CategoryID, &product.ShelfID, &product.SKU, &product.Name,
 			&product.Description, &product.UnitPrice, &product.CostPrice, &product.StockQuantity,
-			&product.MinStockLevel, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
+			&product.MinStockLevel, &product.ReorderQuantity, &product.ImageURL, &product.ExpiryDate, &product.Status, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
 		)
 		if err != nil {
-			pr.log.Error("Failed to scan product", zap.Error(err))
+			utils.LoggerFromContext(ctx).Error("Failed to scan product", zap.Error(err))
 			return nil, fmt.Errorf("scan product failed: %w", err)
 		}
 		products = append(products, product)
 	}
 
 	if err = rows.Err(); err != nil {
-		pr.log.Error("Rows iteration error", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Rows iteration error", zap.Error(err))
 		return nil, fmt.Errorf("rows iteration failed: %w", err)
 	}
 
-	pr.log.Info("Fetched products with pagination",
+	utils.LoggerFromContext(ctx).Info("Fetched products with pagination",
 		zap.Int("limit", limit),
 		zap.Int("offset", offset),
 		zap.Int("count", len(products)))
@@ -231,13 +287,13 @@ func (pr *productRepo) FindAll(ctx context.Context, limit int, offset int) ([]mo
 	return products, nil
 }
 
-func (pr *productRepo) CountAll(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM products WHERE deleted_at IS NULL`
+func (pr *productRepo) CountAll(ctx context.Context, includeDeleted bool) (int, error) {
+	query := `SELECT COUNT(*) FROM products ` + activeFilter(includeDeleted)
 
 	var count int
 	err := pr.db.QueryRow(ctx, query).Scan(&count)
 	if err != nil {
-		pr.log.Error("Failed to count products", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to count products", zap.Error(err))
 		return 0, fmt.Errorf("count products failed: %w", err)
 	}
 
@@ -247,8 +303,8 @@ func (pr *productRepo) CountAll(ctx context.Context) (int, error) {
 func (pr *productRepo) FindLowStock(ctx context.Context) ([]model.Product, error) {
 	query := `
 		SELECT 
-			id, category_id, shelf_id, name, description,
-			unit_price, cost_price, stock_quantity, min_stock_level,
+			id, category_id, shelf_id, sku, name, description,
+			unit_price, cost_price, stock_quantity, min_stock_level, reorder_quantity, image_url, expiry_date, status,
 			created_at, updated_at, deleted_at
 		FROM products 
 		WHERE deleted_at IS NULL 
@@ -259,7 +315,7 @@ func (pr *productRepo) FindLowStock(ctx context.Context) ([]model.Product, error
 
 	rows, err := pr.db.Query(ctx, query)
 	if err != nil {
-		pr.log.Error("Failed to query low stock products", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to query low stock products", zap.Error(err))
 		return nil, fmt.Errorf("query low stock products failed: %w", err)
 	}
 	defer rows.Close()
@@ -268,11 +324,96 @@ func (pr *productRepo) FindLowStock(ctx context.Context) ([]model.Product, error
 	for rows.Next() {
 		var product model.Product
 		if err := rows.Scan(
-			&product.ID, &product.CategoryID, &product.ShelfID, &product.Name,
+			&product.ID, &product.CategoryID, &product.ShelfID, &product.SKU, &product.Name,
+			&product.Description, &product.UnitPrice, &product.CostPrice, &product.StockQuantity,
+			&product.MinStockLevel, &product.ReorderQuantity, &product.ImageURL, &product.ExpiryDate, &product.Status, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
+		); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan product", zap.Error(err))
+			return nil, fmt.Errorf("scan product failed: %w", err)
+		}
+		products = append(products, product)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Low stock products fetched", zap.Int("count", len(products)))
+	return products, nil
+}
+
+// FindCriticalStock produk dengan stock_quantity <= min_stock_level * percent (termasuk stok 0)
+// Produk tanpa min_stock_level (0) tidak punya threshold, tidak ikut. Urut rasio stok/min terkecil
+func (pr *productRepo) FindCriticalStock(ctx context.Context, percent float64) ([]model.Product, error) {
+	query := `
+		SELECT 
+			id, category_id, shelf_id, sku, name, description,
+			unit_price, cost_price, stock_quantity, min_stock_level, reorder_quantity, image_url, expiry_date, status,
+			created_at, updated_at, deleted_at
+		FROM products 
+		WHERE deleted_at IS NULL 
+			AND min_stock_level > 0
+			AND stock_quantity <= min_stock_level * $1::numeric
+		ORDER BY stock_quantity::numeric / min_stock_level ASC, stock_quantity ASC
+	`
+
+	rows, err := pr.db.Query(ctx, query, percent)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query critical stock products", zap.Error(err))
+		return nil, fmt.Errorf("query critical stock products failed: %w", err)
+	}
+	defer rows.Close()
+
+	var products []model.Product
+	for rows.Next() {
+		var product model.Product
+		if err := rows.Scan(
+			&product.ID, &product.CategoryID, &product.ShelfID, &product.SKU, &product.Name,
+			&product.Description, &product.UnitPrice, &product.CostPrice, &product.StockQuantity,
+			&product.MinStockLevel, &product.ReorderQuantity, &product.ImageURL, &product.ExpiryDate, &product.Status, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
+		); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan product", zap.Error(err))
+			return nil, fmt.Errorf("scan product failed: %w", err)
+		}
+		products = append(products, product)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return products, nil
+}
+
+// FindExpiringBefore produk dengan expiry_date <= date (inclusive), termasuk yang sudah lewat
+// Produk tanpa expiry_date tidak ikut, urut dari yang paling cepat kedaluwarsa
+func (pr *productRepo) FindExpiringBefore(ctx context.Context, date time.Time) ([]model.Product, error) {
+	query := `
+		SELECT 
+			id, category_id, shelf_id, sku, name, description,
+			unit_price, cost_price, stock_quantity, min_stock_level, reorder_quantity, image_url, expiry_date, status,
+			created_at, updated_at, deleted_at
+		FROM products 
+		WHERE deleted_at IS NULL 
+			AND expiry_date IS NOT NULL
+			AND expiry_date <= $1
+		ORDER BY expiry_date ASC, name ASC
+	`
+
+	rows, err := pr.db.Query(ctx, query, date)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query expiring products", zap.Error(err))
+		return nil, fmt.Errorf("query expiring products failed: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]model.Product, 0)
+	for rows.Next() {
+		var product model.Product
+		if err := rows.Scan(
+			&product.ID, &product.CategoryID, &product.ShelfID, &product.SKU, &product.Name,
 			&product.Description, &product.UnitPrice, &product.CostPrice, &product.StockQuantity,
-			&product.MinStockLevel, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
+			&product.MinStockLevel, &product.ReorderQuantity, &product.ImageURL, &product.ExpiryDate, &product.Status, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
 		); err != nil {
-			pr.log.Error("Failed to scan product", zap.Error(err))
+			utils.LoggerFromContext(ctx).Error("Failed to scan product", zap.Error(err))
 			return nil, fmt.Errorf("scan product failed: %w", err)
 		}
 		products = append(products, product)
@@ -281,11 +422,127 @@ func (pr *productRepo) FindLowStock(ctx context.Context) ([]model.Product, error
 		return nil, fmt.Errorf("rows iteration failed: %w", err)
 	}
 
-	pr.log.Info("Low stock products fetched", zap.Int("count", len(products)))
+	utils.LoggerFromContext(ctx).Info("Expiring products fetched",
+		zap.Time("before", date),
+		zap.Int("count", len(products)))
 	return products, nil
 }
 
+// FindStaleStock produk aktif (tidak terhapus) yang updated_at-nya sebelum before, paling lama dulu
+func (pr *productRepo) FindStaleStock(ctx context.Context, before time.Time) ([]model.Product, error) {
+	query := `
+		SELECT 
+			id, category_id, shelf_id, sku, name, description,
+			unit_price, cost_price, stock_quantity, min_stock_level, reorder_quantity, image_url, expiry_date, status,
+			created_at, updated_at, deleted_at
+		FROM products 
+		WHERE deleted_at IS NULL 
+			AND updated_at < $1
+		ORDER BY updated_at ASC, name ASC
+	`
+
+	rows, err := pr.db.Query(ctx, query, before)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query stale stock products", zap.Error(err))
+		return nil, fmt.Errorf("query stale stock products failed: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]model.Product, 0)
+	for rows.Next() {
+		var product model.Product
+		if err := rows.Scan(
+			&product.ID, &product.CategoryID, &product.ShelfID, &product.SKU, &product.Name,
+			&product.Description, &product.UnitPrice, &product.CostPrice, &product.StockQuantity,
+			&product.MinStockLevel, &product.ReorderQuantity, &product.ImageURL, &product.ExpiryDate, &product.Status, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
+		); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan product", zap.Error(err))
+			return nil, fmt.Errorf("scan product failed: %w", err)
+		}
+		products = append(products, product)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Stale stock products fetched",
+		zap.Time("before", before),
+		zap.Int("count", len(products)))
+	return products, nil
+}
+
+// FindStockUpTo produk dengan stok <= threshold, stok paling sedikit dulu
+// Kandidat newly low stock, perbandingan dengan min_stock_level dilakukan di service
+func (pr *productRepo) FindStockUpTo(ctx context.Context, threshold int) ([]model.Product, error) {
+	query := `
+		SELECT 
+			id, category_id, shelf_id, sku, name, description,
+			unit_price, cost_price, stock_quantity, min_stock_level, reorder_quantity, image_url, expiry_date, status,
+			created_at, updated_at, deleted_at
+		FROM products 
+		WHERE deleted_at IS NULL 
+			AND stock_quantity <= $1
+		ORDER BY stock_quantity ASC, name ASC
+	`
+
+	rows, err := pr.db.Query(ctx, query, threshold)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query newly low stock products", zap.Error(err))
+		return nil, fmt.Errorf("query newly low stock products failed: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]model.Product, 0)
+	for rows.Next() {
+		var product model.Product
+		if err := rows.Scan(
+			&product.ID, &product.CategoryID, &product.ShelfID, &product.SKU, &product.Name,
+			&product.Description, &product.UnitPrice, &product.CostPrice, &product.StockQuantity,
+			&product.MinStockLevel, &product.ReorderQuantity, &product.ImageURL, &product.ExpiryDate, &product.Status, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
+		); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan product", zap.Error(err))
+			return nil, fmt.Errorf("scan product failed: %w", err)
+		}
+		products = append(products, product)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return products, nil
+}
+
+// Update simpan detail produk, stock_quantity & sku sengaja tidak ikut di-set
+// Stok hanya lewat setProductStock (row lock + ledger), SKU lewat UpdateSKU (sku_history)
 func (pr *productRepo) Update(ctx context.Context, product *model.Product) error {
+	return pr.UpdateWithChanges(ctx, ProductUpdate{Product: product})
+}
+
+// ProductUpdate perubahan produk yang disimpan dalam satu transaction (lihat UpdateWithChanges)
+type ProductUpdate struct {
+	Product    *model.Product      // detail produk (tanpa stok & SKU)
+	Stock      *int                // nil = stok tidak diubah
+	Movement   model.StockMovement // jenis movement jika stok berubah
+	SKUChanged bool                // SKU di-set ke nilai SKU (nil = hapus SKU)
+	SKU        *string
+	ChangedBy  *uuid.UUID // dicatat di sku_history
+}
+
+// UpdateWithChanges simpan detail, stok & SKU produk all-or-nothing
+// Gagal di langkah mana pun = tidak ada yang berubah (tidak ada update setengah jadi)
+func (pr *productRepo) UpdateWithChanges(ctx context.Context, u ProductUpdate) error {
+	product := u.Product
+	if u.Stock != nil && *u.Stock < 0 {
+		return fmt.Errorf("stock quantity cannot be negative")
+	}
+
+	tx, err := pr.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	// Rollback no-op jika sudah commit
+	defer tx.Rollback(ctx)
+
 	query := `
 		UPDATE products 
 		SET 
@@ -295,29 +552,33 @@ func (pr *productRepo) Update(ctx context.Context, product *model.Product) error
 			description = $4,
 			unit_price = $5,
 			cost_price = $6,
-			stock_quantity = $7,
-			min_stock_level = $8,
-			updated_at = $9
-		WHERE id = $10 AND deleted_at IS NULL
+			min_stock_level = $7,
+			reorder_quantity = $8,
+			image_url = $9,
+			expiry_date = $10,
+			updated_at = $11
+		WHERE id = $12 AND deleted_at IS NULL
 	`
 
 	// Update timestamp
 	product.UpdatedAt = time.Now()
 
-	result, err := pr.db.Exec(ctx, query,
+	result, err := tx.Exec(ctx, query,
 		product.CategoryID,
 		product.ShelfID,
 		product.Name,
 		product.Description,
 		product.UnitPrice,
 		product.CostPrice,
-		product.StockQuantity,
 		product.MinStockLevel,
+		product.ReorderQuantity,
+		product.ImageURL,
+		product.ExpiryDate,
 		product.UpdatedAt,
 		product.ID,
 	)
 	if err != nil {
-		pr.log.Error("Failed to update product",
+		utils.LoggerFromContext(ctx).Error("Failed to update product",
 			zap.Error(err),
 			zap.String("id", product.ID.String()),
 		)
@@ -328,16 +589,170 @@ func (pr *productRepo) Update(ctx context.Context, product *model.Product) error
 		return fmt.Errorf("product not found")
 	}
 
-	pr.log.Info("product updated", zap.String("id", product.ID.String()))
+	if u.Stock != nil {
+		if _, found, err := setProductStock(ctx, tx, product.ID, *u.Stock, u.Movement); err != nil {
+			return err
+		} else if !found {
+			return fmt.Errorf("product not found")
+		}
+	}
+
+	if u.SKUChanged {
+		if err := setProductSKU(ctx, tx, product.ID, u.SKU, u.ChangedBy); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("product updated", zap.String("id", product.ID.String()))
 	return nil
 }
 
-func (pr *productRepo) UpdateStock(ctx context.Context, id uuid.UUID, quantity int) error {
+// UpdateStock set total stok produk
+// Produk multi location: selisihnya ikut disebar ke product_stock_locations (lihat applyLocationDelta)
+// Selisih dicatat ke ledger dengan jenis movement dari pemanggil
+func (pr *productRepo) UpdateStock(ctx context.Context, id uuid.UUID, quantity int, movement model.StockMovement) error {
 	// Validasi stok tidak negatif
 	if quantity < 0 {
 		return fmt.Errorf("stock quantity cannot be negative")
 	}
 
+	tx, err := pr.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, found, err := setProductStock(ctx, tx, id, quantity, movement)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("product not found")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("product stock updated", zap.String("id", id.String()))
+	return nil
+}
+
+// ApplyStockCounts set stok banyak produk hasil stock opname dalam satu transaction (all-or-nothing)
+// Return stok sebelum di-set (expected) per produk, produk tidak ditemukan tidak ada di map
+func (pr *productRepo) ApplyStockCounts(ctx context.Context, counts map[uuid.UUID]int, movement model.StockMovement) (map[uuid.UUID]int, error) {
+	if len(counts) == 0 {
+		return nil, fmt.Errorf("no products to update")
+	}
+
+	tx, err := pr.db.Begin(ctx)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to begin transaction", zap.Error(err))
+		return nil, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	// Rollback no-op jika sudah commit
+	defer tx.Rollback(ctx)
+
+	// Lock urut ID supaya dua stock opname yang berbagi produk tidak saling deadlock
+	expected := make(map[uuid.UUID]int, len(counts))
+	for _, id := range sortedProductIDs(counts) {
+		counted := counts[id]
+		if counted < 0 {
+			return nil, fmt.Errorf("stock quantity cannot be negative")
+		}
+
+		oldStock, found, err := setProductStock(ctx, tx, id, counted, movement)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			expected[id] = oldStock
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to commit stock count", zap.Error(err))
+		return nil, fmt.Errorf("commit transaction failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Stock count applied", zap.Int("products", len(expected)))
+	return expected, nil
+}
+
+// RestockBatch tambah stok banyak produk dalam satu transaction (all-or-nothing)
+// Ada produk tidak ditemukan = tidak ada yang berubah, return stok sebelum restock per produk
+func (pr *productRepo) RestockBatch(ctx context.Context, quantities map[uuid.UUID]int, movement model.StockMovement) (map[uuid.UUID]int, error) {
+	if len(quantities) == 0 {
+		return nil, fmt.Errorf("no products to restock")
+	}
+
+	tx, err := pr.db.Begin(ctx)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to begin transaction", zap.Error(err))
+		return nil, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	// Rollback no-op jika sudah commit
+	defer tx.Rollback(ctx)
+
+	// Lock urut ID supaya dua restock yang berbagi produk tidak saling deadlock
+	oldStocks := make(map[uuid.UUID]int, len(quantities))
+	for _, id := range sortedProductIDs(quantities) {
+		quantity := quantities[id]
+		if quantity <= 0 {
+			return nil, fmt.Errorf("restock quantity must be greater than 0")
+		}
+
+		// Stok baru dihitung dari nilai yang di-lock setProductStock
+		oldStock, found, err := changeProductStock(ctx, tx, id, func(current int) int { return current + quantity }, movement)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("one or more products not found")
+		}
+		oldStocks[id] = oldStock
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to commit restock", zap.Error(err))
+		return nil, fmt.Errorf("commit transaction failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Products restocked", zap.Int("products", len(oldStocks)))
+	return oldStocks, nil
+}
+
+// setProductStock lock produk lalu set total stok di dalam transaction pemanggil
+// Lokasi & ledger ikut diupdate, found=false jika produk tidak ada / sudah dihapus
+func setProductStock(ctx context.Context, tx pgx.Tx, id uuid.UUID, quantity int, movement model.StockMovement) (int, bool, error) {
+	return changeProductStock(ctx, tx, id, func(int) int { return quantity }, movement)
+}
+
+// changeProductStock seperti setProductStock, stok baru dihitung dari stok lama yang sudah di-lock
+func changeProductStock(ctx context.Context, tx pgx.Tx, id uuid.UUID, next func(oldStock int) int, movement model.StockMovement) (int, bool, error) {
+	// Lock row produk supaya perubahan stok per lokasi tidak balapan
+	var oldStock int
+	var shelfID uuid.UUID
+	var hasLocations bool
+	err := tx.QueryRow(ctx, `
+		SELECT stock_quantity, shelf_id,
+			EXISTS (SELECT 1 FROM product_stock_locations WHERE product_id = products.id)
+		FROM products
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, id).Scan(&oldStock, &shelfID, &hasLocations)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("lock product failed: %w", err)
+	}
+	quantity := next(oldStock)
+
 	query := `
 		UPDATE products 
 		SET 
@@ -346,26 +761,466 @@ func (pr *productRepo) UpdateStock(ctx context.Context, id uuid.UUID, quantity i
 		WHERE id = $3 AND deleted_at IS NULL
 	`
 
-	result, err := pr.db.Exec(ctx, query, quantity, time.Now(), id)
+	if _, err := tx.Exec(ctx, query, quantity, time.Now(), id); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to update stock product", zap.Error(err),
+			zap.String("id", id.String()),
+		)
+		return 0, false, fmt.Errorf("update product stock failed: %w", err)
+	}
+
+	if hasLocations && quantity != oldStock {
+		if err := applyLocationDelta(ctx, tx, id, shelfID, quantity-oldStock); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to update location stock", zap.Error(err),
+				zap.String("id", id.String()),
+			)
+			return 0, false, err
+		}
+	}
+
+	if err := recordStockChange(ctx, tx, id, oldStock, quantity, movement); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to record stock movement", zap.Error(err),
+			zap.String("id", id.String()),
+		)
+		return 0, false, err
+	}
+
+	return oldStock, true, nil
+}
+
+// sortedProductIDs key map urut (byte order UUID), dipakai supaya row lock selalu diambil dengan urutan yang sama
+func sortedProductIDs(values map[uuid.UUID]int) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(values))
+	for id := range values {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return bytes.Compare(ids[i][:], ids[j][:]) < 0 })
+	return ids
+}
+
+// deductProductStock kurangi stok produk di dalam transaction, gagal jika stok tidak cukup
+// Return stok setelah dipotong
+func deductProductStock(ctx context.Context, tx pgx.Tx, id uuid.UUID, quantity int, movement model.StockMovement) (int, error) {
+	var stock int
+	err := tx.QueryRow(ctx,
+		`SELECT stock_quantity FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id,
+	).Scan(&stock)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("product not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("lock product failed: %w", err)
+	}
+
+	if stock < quantity {
+		return 0, fmt.Errorf("insufficient stock for product %s", id)
+	}
+
+	if _, _, err := setProductStock(ctx, tx, id, stock-quantity, movement); err != nil {
+		return 0, err
+	}
+
+	return stock - quantity, nil
+}
+
+// FindLocation ambil warehouse & shelf produk via LEFT JOIN
+// Shelf/warehouse yang sudah di-soft delete dikembalikan sebagai NULL
+func (pr *productRepo) FindLocation(ctx context.Context, productID uuid.UUID) (*model.ProductLocation, error) {
+	query := `
+		SELECT p.id, p.name,
+			s.id, s.code, s.name,
+			w.id, w.code, w.name
+		FROM products p
+		LEFT JOIN shelves s ON s.id = p.shelf_id AND s.deleted_at IS NULL
+		LEFT JOIN warehouses w ON w.id = s.warehouse_id AND w.deleted_at IS NULL
+		WHERE p.id = $1 AND p.deleted_at IS NULL
+	`
+
+	var location model.ProductLocation
+	err := pr.db.QueryRow(ctx, query, productID).Scan(
+		&location.ProductID,
+		&location.ProductName,
+		&location.ShelfID,
+		&location.ShelfCode,
+		&location.ShelfName,
+		&location.WarehouseID,
+		&location.WarehouseCode,
+		&location.WarehouseName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	return &location, nil
+}
+
+// UpdateCategoryBatch pindahkan produk ke category lain dalam satu transaction
+// Semua ID harus produk aktif, kalau ada yang tidak ditemukan semua di-rollback
+func (pr *productRepo) UpdateCategoryBatch(ctx context.Context, ids []uuid.UUID, categoryID uuid.UUID) (int, error) {
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("no products to update")
+	}
+
+	query := `
+		UPDATE products
+		SET category_id = $1, updated_at = $2
+		WHERE id = ANY($3) AND deleted_at IS NULL
+	`
+
+	tx, err := pr.db.Begin(ctx)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to begin transaction", zap.Error(err))
+		return 0, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	// Rollback no-op jika sudah commit
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, categoryID, time.Now(), ids)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to update product categories",
+			zap.Error(err),
+			zap.String("category_id", categoryID.String()),
+		)
+		return 0, fmt.Errorf("update product categories failed: %w", err)
+	}
+
+	updated := int(result.RowsAffected())
+	if updated != len(ids) {
+		return 0, fmt.Errorf("one or more products not found")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to commit product categories", zap.Error(err))
+		return 0, fmt.Errorf("commit product categories failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Product categories updated",
+		zap.String("category_id", categoryID.String()),
+		zap.Int("count", updated),
+	)
+
+	return updated, nil
+}
+
+// UpdateMinStockBatch set min_stock_level banyak produk dalam satu transaction
+// Berbeda dengan UpdateCategoryBatch: produk yang tidak ditemukan hanya dilewati,
+// return map ID yang berhasil di-update
+// UpdateStatusBatch ubah status banyak produk dalam satu transaction (return jumlah yang berubah)
+// Semua ID harus produk aktif, kalau ada yang tidak ditemukan semua di-rollback
+func (pr *productRepo) UpdateStatusBatch(ctx context.Context, ids []uuid.UUID, status model.ProductStatus) (int, error) {
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("no products to update")
+	}
+
+	tx, err := pr.db.Begin(ctx)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to begin transaction", zap.Error(err))
+		return 0, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	// Rollback no-op jika sudah commit
+	defer tx.Rollback(ctx)
+
+	// Lock semua produk dulu, sekaligus cek semuanya ada
+	var found int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT id FROM products WHERE id = ANY($1) AND deleted_at IS NULL FOR UPDATE
+		) locked
+	`, ids).Scan(&found)
+	if err != nil {
+		return 0, fmt.Errorf("lock products failed: %w", err)
+	}
+	if found != len(ids) {
+		return 0, fmt.Errorf("one or more products not found")
+	}
+
+	// Produk yang status-nya sudah sama tidak disentuh (updated_at tetap)
+	result, err := tx.Exec(ctx, `
+		UPDATE products
+		SET status = $1, updated_at = $2
+		WHERE id = ANY($3) AND deleted_at IS NULL AND status <> $1
+	`, status, time.Now(), ids)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to update product statuses",
+			zap.Error(err),
+			zap.String("status", string(status)),
+		)
+		return 0, fmt.Errorf("update product statuses failed: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to commit product statuses", zap.Error(err))
+		return 0, fmt.Errorf("commit product statuses failed: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// AdjustPricesByCategory naikkan/turunkan harga semua produk satu category (persen, dibulatkan 2 desimal)
+// Update harga & insert price_history dalam satu transaction, return jumlah produk yang harganya berubah
+func (pr *productRepo) AdjustPricesByCategory(ctx context.Context, categoryID uuid.UUID, unitPercent, costPercent float64, changedBy *uuid.UUID) (int, error) {
+	tx, err := pr.db.Begin(ctx)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to begin transaction", zap.Error(err))
+		return 0, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	// Rollback no-op jika sudah commit
+	defer tx.Rollback(ctx)
+
+	// Lock produk dulu supaya harga lama di history selalu akurat
+	rows, err := tx.Query(ctx, `
+		SELECT id, unit_price, cost_price
+		FROM products
+		WHERE category_id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, categoryID)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to lock category products",
+			zap.Error(err),
+			zap.String("category_id", categoryID.String()),
+		)
+		return 0, fmt.Errorf("lock category products failed: %w", err)
+	}
+
+	type lockedPrice struct {
+		id        uuid.UUID
+		unitPrice float64
+		costPrice float64
+	}
+	var locked []lockedPrice
+	for rows.Next() {
+		var lp lockedPrice
+		if err := rows.Scan(&lp.id, &lp.unitPrice, &lp.costPrice); err != nil {
+			rows.Close()
+			utils.LoggerFromContext(ctx).Error("Failed to scan product price", zap.Error(err))
+			return 0, fmt.Errorf("scan product price failed: %w", err)
+		}
+		locked = append(locked, lp)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate product prices failed: %w", err)
+	}
+
+	now := time.Now()
+	updated := 0
+	for _, lp := range locked {
+		newUnit := adjustPrice(lp.unitPrice, unitPercent)
+		newCost := adjustPrice(lp.costPrice, costPercent)
+		// Produk yang harganya tidak berubah (mis. harga 0) tidak disentuh
+		if newUnit == lp.unitPrice && newCost == lp.costPrice {
+			continue
+		}
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE products
+			SET unit_price = $1, cost_price = $2, updated_at = $3
+			WHERE id = $4
+		`, newUnit, newCost, now, lp.id); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to adjust product price",
+				zap.Error(err),
+				zap.String("id", lp.id.String()),
+			)
+			return 0, fmt.Errorf("adjust product prices failed: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO price_history (id, product_id, old_unit_price, new_unit_price, old_cost_price, new_cost_price, changed_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, uuid.New(), lp.id, lp.unitPrice, newUnit, lp.costPrice, newCost, changedBy, now); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to record price history",
+				zap.Error(err),
+				zap.String("id", lp.id.String()),
+			)
+			return 0, fmt.Errorf("record price history failed: %w", err)
+		}
+		updated++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to commit price adjustment", zap.Error(err))
+		return 0, fmt.Errorf("commit price adjustment failed: %w", err)
+	}
+
+	return updated, nil
+}
+
+// adjustPrice harga setelah naik/turun persen, dibulatkan 2 desimal
+func adjustPrice(price, percent float64) float64 {
+	return math.Round(price*(1+percent/100)*100) / 100
+}
+
+func (pr *productRepo) UpdateMinStockBatch(ctx context.Context, levels map[uuid.UUID]int) (map[uuid.UUID]bool, error) {
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("no products to update")
+	}
+
+	query := `
+		UPDATE products
+		SET min_stock_level = $1, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+
+	tx, err := pr.db.Begin(ctx)
 	if err != nil {
-		pr.log.Error("Failed to update stock product", zap.Error(err),
+		utils.LoggerFromContext(ctx).Error("Failed to begin transaction", zap.Error(err))
+		return nil, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	// Rollback no-op jika sudah commit
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	updated := make(map[uuid.UUID]bool, len(levels))
+	for id, level := range levels {
+		result, err := tx.Exec(ctx, query, level, now, id)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to update min stock level",
+				zap.Error(err),
+				zap.String("id", id.String()),
+			)
+			return nil, fmt.Errorf("update min stock level failed: %w", err)
+		}
+		if result.RowsAffected() > 0 {
+			updated[id] = true
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to commit min stock levels", zap.Error(err))
+		return nil, fmt.Errorf("commit min stock levels failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Product min stock levels updated",
+		zap.Int("requested", len(levels)),
+		zap.Int("updated", len(updated)),
+	)
+
+	return updated, nil
+}
+
+// UpdateStatus ubah status produk (active / discontinued)
+func (pr *productRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status model.ProductStatus) error {
+	query := `UPDATE products SET status = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL`
+
+	result, err := pr.db.Exec(ctx, query, status, time.Now(), id)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to update product status", zap.Error(err),
 			zap.String("id", id.String()),
 		)
-		return fmt.Errorf("update product stock failed: %w", err)
+		return fmt.Errorf("update product status failed: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
 		return fmt.Errorf("product not found")
 	}
 
-	pr.log.Info("product stock updated", zap.String("id", id.String()))
+	utils.LoggerFromContext(ctx).Info("Product status updated",
+		zap.String("id", id.String()),
+		zap.String("status", string(status)))
 	return nil
 }
 
+// UpdateSKU ganti SKU produk & catat ke sku_history dalam satu transaction
+// No-op jika SKU sama. sku nil = hapus SKU, changedBy nil = tidak diketahui (sistem)
+func (pr *productRepo) UpdateSKU(ctx context.Context, id uuid.UUID, sku *string, changedBy *uuid.UUID) error {
+	tx, err := pr.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := setProductSKU(ctx, tx, id, sku, changedBy); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Product sku updated", zap.String("id", id.String()))
+	return nil
+}
+
+// setProductSKU set SKU & catat sku_history di dalam transaction pemanggil, no-op jika SKU sama
+func setProductSKU(ctx context.Context, tx pgx.Tx, id uuid.UUID, sku *string, changedBy *uuid.UUID) error {
+	// Lock baris produk supaya old_sku di history selalu akurat
+	var oldSKU *string
+	err := tx.QueryRow(ctx,
+		`SELECT sku FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id,
+	).Scan(&oldSKU)
+	if err != nil {
+		return fmt.Errorf("product not found")
+	}
+
+	if (oldSKU == nil && sku == nil) || (oldSKU != nil && sku != nil && *oldSKU == *sku) {
+		return nil
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(ctx, `UPDATE products SET sku = $1, updated_at = $2 WHERE id = $3`, sku, now, id); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("product sku already exists")
+		}
+		utils.LoggerFromContext(ctx).Error("Failed to update product sku", zap.Error(err),
+			zap.String("id", id.String()))
+		return fmt.Errorf("update product sku failed: %w", err)
+	}
+
+	query := `
+		INSERT INTO sku_history (id, product_id, old_sku, new_sku, changed_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	if _, err := tx.Exec(ctx, query, uuid.New(), id, oldSKU, sku, changedBy, now); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to record sku history", zap.Error(err),
+			zap.String("id", id.String()))
+		return fmt.Errorf("record sku history failed: %w", err)
+	}
+
+	return nil
+}
+
+// FindSKUHistory riwayat perubahan SKU produk, terbaru dulu
+// User yang sudah di-soft delete tetap ditampilkan username-nya
+func (pr *productRepo) FindSKUHistory(ctx context.Context, productID uuid.UUID) ([]model.SKUHistory, error) {
+	query := `
+		SELECT h.id, h.product_id, h.old_sku, h.new_sku, h.changed_by, u.username, h.created_at
+		FROM sku_history h
+		LEFT JOIN users u ON u.id = h.changed_by
+		WHERE h.product_id = $1
+		ORDER BY h.created_at DESC
+	`
+
+	rows, err := pr.db.Query(ctx, query, productID)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query sku history", zap.Error(err))
+		return nil, fmt.Errorf("query sku history failed: %w", err)
+	}
+	defer rows.Close()
+
+	history := make([]model.SKUHistory, 0)
+	for rows.Next() {
+		var entry model.SKUHistory
+		if err := rows.Scan(
+			&entry.ID, &entry.ProductID, &entry.OldSKU, &entry.NewSKU,
+			&entry.ChangedBy, &entry.ChangedByUsername, &entry.CreatedAt,
+		); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan sku history", zap.Error(err))
+			return nil, fmt.Errorf("scan sku history failed: %w", err)
+		}
+		history = append(history, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return history, nil
+}
+
+// CheckStock cek total stok semua lokasi (products.stock_quantity)
 func (pr *productRepo) CheckStock(ctx context.Context, id uuid.UUID, requiredQuantity int) (*model.Product, error) {
 	query := `
         SELECT 
-            id, name, stock_quantity, unit_price, min_stock_level
+            id, name, stock_quantity, unit_price, min_stock_level, status
         FROM products 
         WHERE id = $1 
             AND deleted_at IS NULL 
@@ -379,19 +1234,98 @@ func (pr *productRepo) CheckStock(ctx context.Context, id uuid.UUID, requiredQua
 		&product.StockQuantity,
 		&product.UnitPrice,
 		&product.MinStockLevel,
+		&product.Status,
 	)
 
 	if err != nil {
-		pr.log.Error("Insufficient stock or product not found",
+		utils.LoggerFromContext(ctx).Error("Insufficient stock or product not found",
 			zap.Error(err),
 			zap.String("product_id", id.String()),
 			zap.Int("required", requiredQuantity))
 		return nil, fmt.Errorf("insufficient stock or product not found")
 	}
 
+	// Produk discontinued tidak boleh masuk sale baru
+	if product.Status == model.ProductStatusDiscontinued {
+		return nil, fmt.Errorf("product is discontinued")
+	}
+
 	return &product, nil
 }
 
+// FindByIDs ambil banyak produk aktif dalam satu query (urutan tidak dijamin)
+// ID yang tidak ada / sudah dihapus tidak dikembalikan
+func (pr *productRepo) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]model.Product, error) {
+	query := `
+		SELECT 
+			id, category_id, shelf_id, sku, name, description,
+			unit_price, cost_price, stock_quantity, min_stock_level, reorder_quantity, image_url, expiry_date, status,
+			created_at, updated_at, deleted_at
+		FROM products
+		WHERE id = ANY($1) AND deleted_at IS NULL
+	`
+
+	rows, err := pr.db.Query(ctx, query, ids)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query products by ids", zap.Error(err))
+		return nil, fmt.Errorf("query products failed: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]model.Product, 0, len(ids))
+	for rows.Next() {
+		var product model.Product
+		if err := rows.Scan(
+			&product.ID, &product.CategoryID, &product.ShelfID, &product.SKU, &product.Name,
+			&product.Description, &product.UnitPrice, &product.CostPrice, &product.StockQuantity,
+			&product.MinStockLevel, &product.ReorderQuantity, &product.ImageURL, &product.ExpiryDate, &product.Status, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
+		); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan product", zap.Error(err))
+			return nil, fmt.Errorf("scan product failed: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return products, nil
+}
+
+// FindStockByIDs ambil stok banyak produk aktif dalam satu query
+// Hanya kolom yang dibutuhkan untuk cek availability & stock count, ID yang tidak ada tidak dikembalikan
+func (pr *productRepo) FindStockByIDs(ctx context.Context, ids []uuid.UUID) ([]model.Product, error) {
+	query := `
+		SELECT id, name, stock_quantity, cost_price, status, shelf_id
+		FROM products
+		WHERE id = ANY($1) AND deleted_at IS NULL
+	`
+
+	rows, err := pr.db.Query(ctx, query, ids)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query product stock", zap.Error(err))
+		return nil, fmt.Errorf("query product stock failed: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]model.Product, 0, len(ids))
+	for rows.Next() {
+		var product model.Product
+		if err := rows.Scan(&product.ID, &product.Name, &product.StockQuantity, &product.CostPrice, &product.Status, &product.ShelfID); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan product stock", zap.Error(err))
+			return nil, fmt.Errorf("scan product stock failed: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return products, nil
+}
+
 func (pr *productRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `
 		UPDATE products 
@@ -401,7 +1335,7 @@ func (pr *productRepo) Delete(ctx context.Context, id uuid.UUID) error {
 
 	result, err := pr.db.Exec(ctx, query, time.Now(), id)
 	if err != nil {
-		pr.log.Error("Failed to delete product",
+		utils.LoggerFromContext(ctx).Error("Failed to delete product",
 			zap.Error(err),
 			zap.String("id", id.String()),
 		)
@@ -412,6 +1346,6 @@ func (pr *productRepo) Delete(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("product not found")
 	}
 
-	pr.log.Info("Product deleted", zap.String("id", id.String()))
+	utils.LoggerFromContext(ctx).Info("Product deleted", zap.String("id", id.String()))
 	return nil
 }
@@ -0,0 +1,508 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"inventory-system/model"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== CHECK STOCK ==========
+
+func TestCheckStockRejectsDiscontinued(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  model.ProductStatus
+		stock   int
+		wantErr string
+	}{
+		{name: "active product", status: model.ProductStatusActive, stock: 10},
+		{name: "discontinued product", status: model.ProductStatusDiscontinued, stock: 10, wantErr: "product is discontinued"},
+		{name: "insufficient stock", status: model.ProductStatusActive, stock: 1, wantErr: "insufficient stock or product not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.New()
+			db := newFakeDB(t)
+			db.on("AND stock_quantity >= $2", func(args []any) ([][]any, error) {
+				if tt.stock < args[1].(int) {
+					return nil, nil
+				}
+				return [][]any{{id, "Coffee", tt.stock, 10.0, 2, string(tt.status)}}, nil
+			})
+
+			product, err := NewProductRepo(db, zap.NewNop()).CheckStock(context.Background(), id, 3)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || product.ID != id {
+				t.Fatalf("product/err = %v/%v, want product", product, err)
+			}
+		})
+	}
+}
+
+// ========== LOCATION ==========
+
+func TestFindLocation(t *testing.T) {
+	productID, shelfID := uuid.New(), uuid.New()
+
+	t.Run("soft deleted warehouse scanned as null", func(t *testing.T) {
+		db := newFakeDB(t)
+		db.on("LEFT JOIN warehouses w ON w.id = s.warehouse_id AND w.deleted_at IS NULL", func(args []any) ([][]any, error) {
+			return [][]any{{productID, "Coffee", shelfID, "B3", "Shelf B3", nil, nil, nil}}, nil
+		})
+
+		location, err := NewProductRepo(db, zap.NewNop()).FindLocation(context.Background(), productID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if location.ShelfID == nil || *location.ShelfID != shelfID || *location.ShelfName != "Shelf B3" {
+			t.Errorf("shelf = %v/%v, want Shelf B3", location.ShelfID, location.ShelfName)
+		}
+		if location.WarehouseID != nil || location.WarehouseName != nil {
+			t.Errorf("warehouse = %v/%v, want nil", location.WarehouseID, location.WarehouseName)
+		}
+	})
+
+	t.Run("missing product", func(t *testing.T) {
+		db := newFakeDB(t)
+		db.on("FROM products p", func(args []any) ([][]any, error) { return nil, nil })
+
+		if _, err := NewProductRepo(db, zap.NewNop()).FindLocation(context.Background(), productID); err == nil {
+			t.Fatal("expected not found error")
+		}
+	})
+}
+
+// ========== RECATEGORIZE ==========
+
+func TestUpdateCategoryBatch(t *testing.T) {
+	existing := map[uuid.UUID]bool{uuid.New(): true, uuid.New(): true}
+	ids := make([]uuid.UUID, 0, len(existing))
+	for id := range existing {
+		ids = append(ids, id)
+	}
+	categoryID := uuid.New()
+
+	newDB := func() *fakeDB {
+		db := newFakeDB(t)
+		db.on("UPDATE products SET category_id = $1", func(args []any) ([][]any, error) {
+			if args[0] != categoryID {
+				t.Errorf("category arg = %v, want %v", args[0], categoryID)
+			}
+			// Emulasi WHERE id = ANY($3) AND deleted_at IS NULL
+			var rows [][]any
+			for _, id := range args[2].([]uuid.UUID) {
+				if existing[id] {
+					rows = append(rows, []any{})
+				}
+			}
+			return rows, nil
+		})
+		return db
+	}
+
+	t.Run("all products moved", func(t *testing.T) {
+		db := newDB()
+		updated, err := NewProductRepo(db, zap.NewNop()).UpdateCategoryBatch(context.Background(), ids, categoryID)
+		if err != nil || updated != 2 {
+			t.Fatalf("updated/err = %d/%v, want 2/nil", updated, err)
+		}
+		if db.begins != 1 || db.commits != 1 {
+			t.Errorf("begins/commits = %d/%d, want 1/1", db.begins, db.commits)
+		}
+	})
+
+	t.Run("missing product rolls back", func(t *testing.T) {
+		db := newDB()
+		_, err := NewProductRepo(db, zap.NewNop()).UpdateCategoryBatch(context.Background(), append(ids, uuid.New()), categoryID)
+		if err == nil || err.Error() != "one or more products not found" {
+			t.Fatalf("error = %v, want one or more products not found", err)
+		}
+		if db.commits != 0 || db.rollbacks != 1 {
+			t.Errorf("commits/rollbacks = %d/%d, want 0/1", db.commits, db.rollbacks)
+		}
+	})
+}
+
+// ========== PRICE ADJUST ==========
+
+func TestAdjustPricesByCategory(t *testing.T) {
+	categoryID, actor := uuid.New(), uuid.New()
+	tea, coffee, free := uuid.New(), uuid.New(), uuid.New()
+
+	type price struct{ unit, cost float64 }
+	type history struct {
+		productID uuid.UUID
+		old, new  price
+		changedBy *uuid.UUID
+	}
+
+	// Emulasi tabel products category + price_history
+	newDB := func(prices map[uuid.UUID]price, failHistory bool) (*fakeDB, *[]history) {
+		histories := &[]history{}
+		db := newFakeDB(t)
+		db.on("FOR UPDATE", func(args []any) ([][]any, error) {
+			if args[0] != categoryID {
+				t.Errorf("category arg = %v, want %v", args[0], categoryID)
+			}
+			var rows [][]any
+			for _, id := range []uuid.UUID{tea, coffee, free} {
+				if p, ok := prices[id]; ok {
+					rows = append(rows, []any{id, p.unit, p.cost})
+				}
+			}
+			return rows, nil
+		})
+		db.on("SET unit_price = $1, cost_price = $2", func(args []any) ([][]any, error) {
+			prices[args[3].(uuid.UUID)] = price{args[0].(float64), args[1].(float64)}
+			return [][]any{{}}, nil
+		})
+		db.on("INSERT INTO price_history", func(args []any) ([][]any, error) {
+			if failHistory {
+				return nil, fmt.Errorf("insert failed")
+			}
+			*histories = append(*histories, history{
+				productID: args[1].(uuid.UUID),
+				old:       price{args[2].(float64), args[4].(float64)},
+				new:       price{args[3].(float64), args[5].(float64)},
+				changedBy: args[6].(*uuid.UUID),
+			})
+			return [][]any{{}}, nil
+		})
+		return db, histories
+	}
+
+	t.Run("prices and history", func(t *testing.T) {
+		prices := map[uuid.UUID]price{
+			tea:    {unit: 19.99, cost: 12.5},
+			coffee: {unit: 100, cost: 80},
+			free:   {unit: 0, cost: 0},
+		}
+		db, histories := newDB(prices, false)
+
+		updated, err := NewProductRepo(db, zap.NewNop()).AdjustPricesByCategory(context.Background(), categoryID, 5, -3, &actor)
+		if err != nil || updated != 2 {
+			t.Fatalf("updated/err = %d/%v, want 2/nil", updated, err)
+		}
+
+		// Dibulatkan 2 desimal, harga 0 tidak berubah dan tidak tercatat
+		want := map[uuid.UUID]price{
+			tea:    {unit: 20.99, cost: 12.13},
+			coffee: {unit: 105, cost: 77.6},
+			free:   {unit: 0, cost: 0},
+		}
+		for id, p := range want {
+			if prices[id] != p {
+				t.Errorf("price %v = %+v, want %+v", id, prices[id], p)
+			}
+		}
+
+		wantHistory := []history{
+			{productID: tea, old: price{19.99, 12.5}, new: price{20.99, 12.13}},
+			{productID: coffee, old: price{100, 80}, new: price{105, 77.6}},
+		}
+		if len(*histories) != len(wantHistory) {
+			t.Fatalf("history rows = %+v, want %d", *histories, len(wantHistory))
+		}
+		for i, h := range *histories {
+			if h.productID != wantHistory[i].productID || h.old != wantHistory[i].old || h.new != wantHistory[i].new {
+				t.Errorf("history[%d] = %+v, want %+v", i, h, wantHistory[i])
+			}
+			if h.changedBy == nil || *h.changedBy != actor {
+				t.Errorf("history[%d] changed_by = %v, want %v", i, h.changedBy, actor)
+			}
+		}
+		if db.commits != 1 {
+			t.Errorf("commits = %d, want 1", db.commits)
+		}
+	})
+
+	t.Run("history failure rolls back", func(t *testing.T) {
+		db, _ := newDB(map[uuid.UUID]price{tea: {unit: 10, cost: 5}}, true)
+
+		if _, err := NewProductRepo(db, zap.NewNop()).AdjustPricesByCategory(context.Background(), categoryID, 10, 0, nil); err == nil {
+			t.Fatal("expected error")
+		}
+		if db.commits != 0 || db.rollbacks != 1 {
+			t.Errorf("commits/rollbacks = %d/%d, want 0/1", db.commits, db.rollbacks)
+		}
+	})
+}
+
+// ========== BATCH FETCH ==========
+
+func TestFindByIDs(t *testing.T) {
+	coffee, tea, deleted, missing := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	table := map[uuid.UUID]string{coffee: "Coffee", tea: "Tea", deleted: "Deleted"}
+
+	// Emulasi WHERE id = ANY($1) AND deleted_at IS NULL, urutan hasil mengikuti tabel bukan request
+	db := newFakeDB(t)
+	db.on("WHERE id = ANY($1) AND deleted_at IS NULL", func(args []any) ([][]any, error) {
+		requested := map[uuid.UUID]bool{}
+		for _, id := range args[0].([]uuid.UUID) {
+			requested[id] = true
+		}
+		var rows [][]any
+		for _, id := range []uuid.UUID{tea, coffee, deleted} {
+			if requested[id] && id != deleted {
+				rows = append(rows, []any{id, uuid.New(), uuid.New(), nil, table[id], "", 10.0, 5.0, 3, 1, 0, "", nil, "active", time.Now(), time.Now(), nil})
+			}
+		}
+		return rows, nil
+	})
+
+	products, err := NewProductRepo(db, zap.NewNop()).FindByIDs(context.Background(), []uuid.UUID{coffee, missing, deleted, tea})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if db.executed("FROM products") != 1 {
+		t.Errorf("queries = %d, want a single query", db.executed("FROM products"))
+	}
+	names := make([]string, 0, len(products))
+	for _, p := range products {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, ","); got != "Tea,Coffee" {
+		t.Errorf("products = %s, want only existing, non-deleted products", got)
+	}
+}
+
+// ========== STALE STOCK ==========
+
+func TestFindStaleStock(t *testing.T) {
+	cutoff := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	type row struct {
+		name      string
+		updatedAt time.Time
+		deleted   bool
+	}
+	table := []row{
+		{name: "Recent", updatedAt: cutoff.Add(time.Hour)},
+		{name: "Cutoff", updatedAt: cutoff},
+		{name: "Day before", updatedAt: cutoff.Add(-time.Minute)},
+		{name: "Deleted", updatedAt: cutoff.AddDate(0, -3, 0), deleted: true},
+		{name: "Oldest", updatedAt: cutoff.AddDate(0, -2, 0)},
+	}
+
+	// Emulasi WHERE deleted_at IS NULL AND updated_at < $1 ORDER BY updated_at ASC
+	db := newFakeDB(t)
+	db.on("FROM products WHERE deleted_at IS NULL AND updated_at < $1 ORDER BY updated_at ASC", func(args []any) ([][]any, error) {
+		before := args[0].(time.Time)
+		var matched []row
+		for _, r := range table {
+			if !r.deleted && r.updatedAt.Before(before) {
+				matched = append(matched, r)
+			}
+		}
+		sort.Slice(matched, func(i, j int) bool { return matched[i].updatedAt.Before(matched[j].updatedAt) })
+
+		rows := make([][]any, 0, len(matched))
+		for _, r := range matched {
+			rows = append(rows, []any{uuid.New(), uuid.New(), uuid.New(), nil, r.name, "", 10.0, 5.0, 3, 1, 0, "", nil, "active", r.updatedAt, r.updatedAt, nil})
+		}
+		return rows, nil
+	})
+
+	products, err := NewProductRepo(db, zap.NewNop()).FindStaleStock(context.Background(), cutoff)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	names := make([]string, 0, len(products))
+	for _, p := range products {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, ","); got != "Oldest,Day before" {
+		t.Errorf("products = %s, want Oldest,Day before", got)
+	}
+}
+
+// ========== BULK STATUS ==========
+
+func TestUpdateStatusBatch(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+
+	// Emulasi tabel products: lock menghitung baris yang ada, update hanya status yang beda
+	newDB := func(statuses map[uuid.UUID]model.ProductStatus) *fakeDB {
+		db := newFakeDB(t)
+		db.on("FOR UPDATE", func(args []any) ([][]any, error) {
+			found := 0
+			for _, id := range args[0].([]uuid.UUID) {
+				if _, ok := statuses[id]; ok {
+					found++
+				}
+			}
+			return [][]any{{found}}, nil
+		})
+		db.on("SET status = $1, updated_at = $2", func(args []any) ([][]any, error) {
+			status := args[0].(model.ProductStatus)
+			var rows [][]any
+			for _, id := range args[2].([]uuid.UUID) {
+				if current, ok := statuses[id]; ok && current != status {
+					statuses[id] = status
+					rows = append(rows, []any{})
+				}
+			}
+			return rows, nil
+		})
+		return db
+	}
+
+	t.Run("toggle persists", func(t *testing.T) {
+		statuses := map[uuid.UUID]model.ProductStatus{first: model.ProductStatusActive, second: model.ProductStatusDiscontinued}
+		db := newDB(statuses)
+		repo := NewProductRepo(db, zap.NewNop())
+
+		updated, err := repo.UpdateStatusBatch(context.Background(), []uuid.UUID{first, second}, model.ProductStatusDiscontinued)
+		if err != nil || updated != 1 {
+			t.Fatalf("updated/err = %d/%v, want 1/nil", updated, err)
+		}
+		if statuses[first] != model.ProductStatusDiscontinued || statuses[second] != model.ProductStatusDiscontinued {
+			t.Errorf("statuses = %v, want all discontinued", statuses)
+		}
+
+		updated, err = repo.UpdateStatusBatch(context.Background(), []uuid.UUID{first, second}, model.ProductStatusActive)
+		if err != nil || updated != 2 {
+			t.Fatalf("updated/err = %d/%v, want 2/nil", updated, err)
+		}
+		if db.begins != 2 || db.commits != 2 {
+			t.Errorf("begins/commits = %d/%d, want 2/2", db.begins, db.commits)
+		}
+	})
+
+	t.Run("missing product rolls back", func(t *testing.T) {
+		statuses := map[uuid.UUID]model.ProductStatus{first: model.ProductStatusActive}
+		db := newDB(statuses)
+
+		_, err := NewProductRepo(db, zap.NewNop()).UpdateStatusBatch(context.Background(), []uuid.UUID{first, second}, model.ProductStatusDiscontinued)
+		if err == nil || err.Error() != "one or more products not found" {
+			t.Fatalf("error = %v, want one or more products not found", err)
+		}
+		if statuses[first] != model.ProductStatusActive || db.executed("SET status") != 0 {
+			t.Error("status updated despite missing product")
+		}
+		if db.commits != 0 || db.rollbacks != 1 {
+			t.Errorf("commits/rollbacks = %d/%d, want 0/1", db.commits, db.rollbacks)
+		}
+	})
+}
+
+// ========== RESTOCK BATCH ==========
+
+// lockedProducts urutan product_id yang di-lock (SELECT ... FOR UPDATE) selama test
+func lockedProducts(db *fakeDB) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, call := range db.calls {
+		if strings.Contains(call.sql, "FOR UPDATE") {
+			ids = append(ids, call.args[0].(uuid.UUID))
+		}
+	}
+	return ids
+}
+
+// isSortedIDs urutan byte UUID naik, sama dengan sortedProductIDs
+func isSortedIDs(ids []uuid.UUID) bool {
+	return sort.SliceIsSorted(ids, func(i, j int) bool { return bytes.Compare(ids[i][:], ids[j][:]) < 0 })
+}
+
+func TestRestockBatchLocksInIDOrder(t *testing.T) {
+	inventory := &fakeInventory{products: map[uuid.UUID]*fakeStockProduct{}}
+	quantities := map[uuid.UUID]int{}
+	for i := 1; i <= 8; i++ {
+		id := uuid.New()
+		inventory.products[id] = &fakeStockProduct{stock: i, shelfID: uuid.New(), locations: map[uuid.UUID]int{}}
+		quantities[id] = 10
+	}
+	db, _ := newInventoryDB(t, inventory)
+
+	oldStocks, err := NewProductRepo(db, zap.NewNop()).RestockBatch(context.Background(), quantities, model.StockMovement{MovementType: model.StockMovementRestock})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Satu lock per produk (lock di setProductStock), diambil urut ID
+	locked := lockedProducts(db)
+	if len(locked) != len(quantities) {
+		t.Fatalf("locks = %d, want one per product (%d)", len(locked), len(quantities))
+	}
+	if !isSortedIDs(locked) {
+		t.Errorf("lock order = %v, want sorted by id", locked)
+	}
+
+	for id, p := range inventory.products {
+		if p.stock != oldStocks[id]+10 {
+			t.Errorf("stock %v = %d, want %d", id, p.stock, oldStocks[id]+10)
+		}
+	}
+	if len(inventory.movements) != len(quantities) || inventory.movements[0].quantity != 10 || inventory.movements[0].movementType != model.StockMovementRestock {
+		t.Errorf("movements = %+v, want one +10 restock per product", inventory.movements)
+	}
+}
+
+// ========== STOCK COUNT ==========
+
+func TestApplyStockCountsLocksInIDOrder(t *testing.T) {
+	inventory := &fakeInventory{products: map[uuid.UUID]*fakeStockProduct{}}
+	counts := map[uuid.UUID]int{}
+	for i := 1; i <= 8; i++ {
+		id := uuid.New()
+		inventory.products[id] = &fakeStockProduct{stock: 20, shelfID: uuid.New(), locations: map[uuid.UUID]int{}}
+		counts[id] = i
+	}
+	db, _ := newInventoryDB(t, inventory)
+
+	expected, err := NewProductRepo(db, zap.NewNop()).ApplyStockCounts(context.Background(), counts, model.StockMovement{MovementType: model.StockMovementAdjustment})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	locked := lockedProducts(db)
+	if len(locked) != len(counts) || !isSortedIDs(locked) {
+		t.Errorf("lock order = %v, want one lock per product sorted by id", locked)
+	}
+	for id, counted := range counts {
+		if expected[id] != 20 || inventory.products[id].stock != counted {
+			t.Errorf("%v expected/stock = %d/%d, want 20/%d", id, expected[id], inventory.products[id].stock, counted)
+		}
+	}
+}
+
+// ========== BULK MIN STOCK ==========
+
+func TestUpdateMinStockBatch(t *testing.T) {
+	existing, missing := uuid.New(), uuid.New()
+	levels := map[uuid.UUID]int{}
+
+	db := newFakeDB(t)
+	db.on("SET min_stock_level = $1", func(args []any) ([][]any, error) {
+		if args[2] != existing {
+			return nil, nil
+		}
+		levels[existing] = args[0].(int)
+		return [][]any{{}}, nil
+	})
+
+	updated, err := NewProductRepo(db, zap.NewNop()).UpdateMinStockBatch(context.Background(), map[uuid.UUID]int{existing: 7, missing: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !updated[existing] || updated[missing] || len(updated) != 1 {
+		t.Errorf("updated = %v, want only existing", updated)
+	}
+	// Produk yang tidak ada tidak membatalkan yang lain
+	if db.commits != 1 || levels[existing] != 7 {
+		t.Errorf("commits/level = %d/%d, want 1/7", db.commits, levels[existing])
+	}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/database"
+	"inventory-system/model"
+	"inventory-system/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type ReplenishmentRepo interface {
+	Create(ctx context.Context, request *model.ReplenishmentRequest) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.ReplenishmentRequest, error)
+	FindAll(ctx context.Context, status string, limit int, offset int) ([]model.ReplenishmentRequest, error)
+	CountAll(ctx context.Context, status string) (int, error)
+	Review(ctx context.Context, request *model.ReplenishmentRequest) error
+}
+
+type replenishmentRepo struct {
+	db  database.PgxIface
+	log *zap.Logger
+}
+
+func NewReplenishmentRepo(db database.PgxIface, log *zap.Logger) ReplenishmentRepo {
+	return &replenishmentRepo{db: db, log: log}
+}
+
+const replenishmentColumns = `
+	id, product_id, requested_by, requested_quantity, notes, status,
+	reviewed_by, reviewed_at, review_notes, created_at, updated_at, deleted_at
+`
+
+func (rr *replenishmentRepo) Create(ctx context.Context, request *model.ReplenishmentRequest) error {
+	query := `
+		INSERT INTO replenishment_requests (id, product_id, requested_by, requested_quantity, notes, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	// Generate metadata sebelum insert
+	now := time.Now()
+	request.ID = uuid.New()
+	request.Status = model.ReplenishmentStatusPending
+	request.CreatedAt = now
+	request.UpdatedAt = now
+
+	_, err := rr.db.Exec(ctx, query,
+		request.ID,
+		request.ProductID,
+		request.RequestedBy,
+		request.RequestedQuantity,
+		request.Notes,
+		request.Status,
+		request.CreatedAt,
+		request.UpdatedAt,
+	)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to create replenishment request",
+			zap.Error(err),
+			zap.String("product_id", request.ProductID.String()),
+		)
+		return fmt.Errorf("create replenishment request failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Replenishment request created",
+		zap.String("id", request.ID.String()),
+		zap.String("product_id", request.ProductID.String()),
+		zap.Int("requested_quantity", request.RequestedQuantity),
+	)
+
+	return nil
+}
+
+func (rr *replenishmentRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.ReplenishmentRequest, error) {
+	query := `SELECT ` + replenishmentColumns + ` FROM replenishment_requests WHERE id = $1 AND deleted_at IS NULL`
+
+	var request model.ReplenishmentRequest
+	if err := scanReplenishment(rr.db.QueryRow(ctx, query, id), &request); err != nil {
+		return nil, fmt.Errorf("replenishment request not found: %w", err)
+	}
+
+	return &request, nil
+}
+
+// FindAll dengan pagination, status kosong = semua status
+// Pending paling lama di atas supaya antrian review urut
+func (rr *replenishmentRepo) FindAll(ctx context.Context, status string, limit int, offset int) ([]model.ReplenishmentRequest, error) {
+	query := `
+		SELECT ` + replenishmentColumns + `
+		FROM replenishment_requests
+		WHERE deleted_at IS NULL AND ($1 = '' OR status = $1)
+		ORDER BY (status = 'pending') DESC, created_at ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := rr.db.Query(ctx, query, status, limit, offset)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query replenishment requests", zap.Error(err))
+		return nil, fmt.Errorf("query replenishment requests failed: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []model.ReplenishmentRequest
+	for rows.Next() {
+		var request model.ReplenishmentRequest
+		if err := scanReplenishment(rows, &request); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan replenishment request", zap.Error(err))
+			return nil, fmt.Errorf("scan replenishment request failed: %w", err)
+		}
+		requests = append(requests, request)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return requests, nil
+}
+
+func (rr *replenishmentRepo) CountAll(ctx context.Context, status string) (int, error) {
+	query := `SELECT COUNT(*) FROM replenishment_requests WHERE deleted_at IS NULL AND ($1 = '' OR status = $1)`
+
+	var count int
+	if err := rr.db.QueryRow(ctx, query, status).Scan(&count); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to count replenishment requests", zap.Error(err))
+		return 0, fmt.Errorf("count replenishment requests failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// Review simpan keputusan admin, hanya berlaku untuk request yang masih pending
+// Kondisi status = 'pending' di WHERE mencegah dua admin me-review request yang sama
+func (rr *replenishmentRepo) Review(ctx context.Context, request *model.ReplenishmentRequest) error {
+	query := `
+		UPDATE replenishment_requests
+		SET status = $1, reviewed_by = $2, reviewed_at = $3, review_notes = $4, updated_at = $3
+		WHERE id = $5 AND status = 'pending' AND deleted_at IS NULL
+	`
+
+	now := time.Now()
+
+	result, err := rr.db.Exec(ctx, query,
+		request.Status,
+		request.ReviewedBy,
+		now,
+		request.ReviewNotes,
+		request.ID,
+	)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to review replenishment request",
+			zap.Error(err),
+			zap.String("id", request.ID.String()),
+		)
+		return fmt.Errorf("review replenishment request failed: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("replenishment request already reviewed")
+	}
+
+	request.ReviewedAt = &now
+	request.UpdatedAt = now
+
+	utils.LoggerFromContext(ctx).Info("Replenishment request reviewed",
+		zap.String("id", request.ID.String()),
+		zap.String("status", string(request.Status)),
+	)
+	return nil
+}
+
+// scanReplenishment helper: urutan kolom sama dengan replenishmentColumns
+func scanReplenishment(row pgx.Row, request *model.ReplenishmentRequest) error {
+	return row.Scan(
+		&request.ID, &request.ProductID, &request.RequestedBy, &request.RequestedQuantity,
+		&request.Notes, &request.Status, &request.ReviewedBy, &request.ReviewedAt,
+		&request.ReviewNotes, &request.CreatedAt, &request.UpdatedAt, &request.DeletedAt,
+	)
+}
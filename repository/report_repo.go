@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"inventory-system/database"
 	"inventory-system/dto/report"
+	"inventory-system/utils"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
@@ -15,11 +18,50 @@ type ReportRepo interface {
 	// 1. Product inventory report (total barang)
 	GetProductInventoryReport(ctx context.Context) (*report.ProductReportResponse, error)
 
-	// 2. Sales report (penjualan)
-	GetSalesReport(ctx context.Context, startDate, endDate time.Time) (*report.SalesReportResponse, error)
+	// 2. Sales report (penjualan) - userID & categoryID optional (nil = semua)
+	GetSalesReport(ctx context.Context, startDate, endDate time.Time, userID, categoryID *uuid.UUID) (*report.SalesReportResponse, error)
 
-	// 3. Revenue report (pendapatan) - untuk admin/super_admin saja
-	GetRevenueReport(ctx context.Context, startDate, endDate time.Time, groupBy string) (*report.RevenueReportResponse, error)
+	// 3. Revenue report (pendapatan) - untuk admin/super_admin saja, paymentStatus kosong = semua
+	GetRevenueReport(ctx context.Context, startDate, endDate time.Time, groupBy, paymentStatus string) (*report.RevenueReportResponse, error)
+
+	// 4. Inventory valuation - stream per row supaya tidak load semua ke memory
+	GetInventoryValuation(ctx context.Context, fn func(row report.InventoryValuationRow) error) error
+
+	// 5. Sales per category (urut revenue terbesar)
+	GetSalesByCategory(ctx context.Context, startDate, endDate time.Time, includeEmpty bool) ([]report.CategorySales, error)
+
+	// 6. Unit terjual & stok opening/closing per product (urut nama), rasio dihitung di service
+	GetInventoryTurnover(ctx context.Context, startDate, endDate time.Time) ([]report.ProductTurnover, error)
+
+	// 7. Sales time series per bucket, hanya bucket yang ada penjualannya
+	GetSalesTimeSeries(ctx context.Context, startDate, endDate time.Time, interval string) ([]report.SalesTrendBucket, error)
+
+	// 8. Product inventory report untuk satu warehouse (lewat shelf)
+	GetWarehouseInventory(ctx context.Context, warehouseID uuid.UUID) (*report.ProductReportResponse, error)
+
+	// 9. Jumlah produk & stok per rak dalam satu warehouse (rak kosong tetap muncul)
+	GetShelfDistribution(ctx context.Context, warehouseID uuid.UUID) ([]report.ShelfDistribution, error)
+
+	// 10. Jumlah & revenue sale completed per jam (0-23, hanya jam yang ada penjualan)
+	GetSalesByHour(ctx context.Context, startDate, endDate time.Time) ([]report.HourlySales, error)
+
+	// 11. Jumlah & revenue sale completed per cara bayar (hanya yang ada penjualan)
+	GetRevenueByPaymentMethod(ctx context.Context, startDate, endDate time.Time) ([]report.PaymentMethodRevenue, error)
+
+	// 12. Sale completed yang punya customer (urut waktu), dikelompokkan per customer di service
+	FindCustomerSales(ctx context.Context, startDate, endDate time.Time) ([]report.CustomerSale, error)
+
+	// 13. Pasangan created_at & completed_at sale completed, rata-rata & median dihitung di service
+	FindFulfillmentTimes(ctx context.Context, startDate, endDate time.Time) ([]report.SaleFulfillment, error)
+
+	// 14. Stok semua produk pada waktu at (dari ledger), tanpa disimpan
+	ComputeStockSnapshot(ctx context.Context, at time.Time) ([]report.SnapshotProduct, error)
+
+	// 15. Hitung stok pada waktu at & simpan sebagai snapshot bulan month (sudah ada = tidak ditimpa)
+	SaveMonthlySnapshot(ctx context.Context, month, at time.Time) error
+
+	// 16. Snapshot tersimpan untuk bulan month, kosong = belum pernah disimpan
+	FindMonthlySnapshot(ctx context.Context, month time.Time) ([]report.SnapshotProduct, error)
 }
 
 type reportRepo struct {
@@ -54,7 +96,7 @@ func (rr *reportRepo) GetProductInventoryReport(ctx context.Context) (*report.Pr
 	)
 
 	if err != nil {
-		rr.log.Error("Failed to get product inventory report", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to get product inventory report", zap.Error(err))
 		return nil, fmt.Errorf("failed to get product report: %w", err)
 	}
 
@@ -68,37 +110,67 @@ func (rr *reportRepo) GetProductInventoryReport(ctx context.Context) (*report.Pr
 
 // ========== 2. SALES REPORT ==========
 // (SAMA dengan yang di sale_repo.go, kita pindahkan ke sini)
-func (rr *reportRepo) GetSalesReport(ctx context.Context, startDate, endDate time.Time) (*report.SalesReportResponse, error) {
-	query := `
-		SELECT 
-			COUNT(*) as total_sales,
-			COALESCE(SUM(total_amount), 0) as total_revenue,
-			COALESCE(SUM(
-				(SELECT SUM(quantity) FROM sale_items WHERE sale_id = sales.id)
-			), 0) as total_items_sold,
-			CASE 
-				WHEN COUNT(*) > 0 THEN COALESCE(SUM(total_amount), 0) / COUNT(*) 
-				ELSE 0 
-			END as average_sale
-		FROM sales 
-		WHERE deleted_at IS NULL 
-			AND status = 'completed'
-			AND created_at BETWEEN $1 AND $2
-	`
+// Filter category join lewat sale_items -> products, sehingga revenue & items
+// yang dihitung hanya baris item dari kategori tersebut
+func (rr *reportRepo) GetSalesReport(ctx context.Context, startDate, endDate time.Time, userID, categoryID *uuid.UUID) (*report.SalesReportResponse, error) {
+	var query string
+	args := []interface{}{startDate, endDate}
+
+	if categoryID != nil {
+		args = append(args, *categoryID)
+		query = `
+			SELECT 
+				COUNT(DISTINCT s.id) as total_sales,
+				COALESCE(SUM(si.total_price), 0) as total_revenue,
+				COALESCE(SUM(si.quantity), 0) as total_items_sold
+			FROM sales s
+			JOIN sale_items si ON si.sale_id = s.id
+			JOIN products p ON p.id = si.product_id
+			WHERE s.deleted_at IS NULL 
+				AND s.status = 'completed'
+				AND s.created_at BETWEEN $1 AND $2
+				AND p.category_id = $3
+		`
+		if userID != nil {
+			args = append(args, *userID)
+			query += fmt.Sprintf(" AND s.user_id = $%d", len(args))
+		}
+	} else {
+		query = `
+			SELECT 
+				COUNT(*) as total_sales,
+				COALESCE(SUM(total_amount), 0) as total_revenue,
+				COALESCE(SUM(
+					(SELECT SUM(quantity) FROM sale_items WHERE sale_id = sales.id)
+				), 0) as total_items_sold
+			FROM sales 
+			WHERE deleted_at IS NULL 
+				AND status = 'completed'
+				AND created_at BETWEEN $1 AND $2
+		`
+		if userID != nil {
+			args = append(args, *userID)
+			query += fmt.Sprintf(" AND user_id = $%d", len(args))
+		}
+	}
 
 	var result report.SalesReportResponse
-	err := rr.db.QueryRow(ctx, query, startDate, endDate).Scan(
+	err := rr.db.QueryRow(ctx, query, args...).Scan(
 		&result.TotalSales,
 		&result.TotalRevenue,
 		&result.TotalItemsSold,
-		&result.AverageSale,
 	)
 
 	if err != nil {
-		rr.log.Error("Failed to get sales report", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to get sales report", zap.Error(err))
 		return nil, fmt.Errorf("failed to get sales report: %w", err)
 	}
 
+	// Calculate average
+	if result.TotalSales > 0 {
+		result.AverageSale = result.TotalRevenue / float64(result.TotalSales)
+	}
+
 	result.StartDate = startDate
 	result.EndDate = endDate
 
@@ -106,19 +178,37 @@ func (rr *reportRepo) GetSalesReport(ctx context.Context, startDate, endDate tim
 }
 
 // ========== 3. REVENUE REPORT ==========
-func (rr *reportRepo) GetRevenueReport(ctx context.Context, startDate, endDate time.Time, groupBy string) (*report.RevenueReportResponse, error) {
+func (rr *reportRepo) GetRevenueReport(ctx context.Context, startDate, endDate time.Time, groupBy, paymentStatus string) (*report.RevenueReportResponse, error) {
 	// Get total summary
-	summary, err := rr.GetSalesReport(ctx, startDate, endDate)
-	if err != nil {
-		return nil, err
-	}
-
 	response := &report.RevenueReportResponse{
-		TotalRevenue: summary.TotalRevenue,
-		TotalSales:   summary.TotalSales,
-		AverageSale:  summary.AverageSale,
-		StartDate:    startDate,
-		EndDate:      endDate,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		PaymentStatus: paymentStatus,
+	}
+	if paymentStatus == "" {
+		summary, err := rr.GetSalesReport(ctx, startDate, endDate, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		response.TotalRevenue = summary.TotalRevenue
+		response.TotalSales = summary.TotalSales
+		response.AverageSale = summary.AverageSale
+	} else {
+		err := rr.db.QueryRow(ctx, `
+			SELECT COUNT(*), COALESCE(SUM(total_amount), 0)
+			FROM sales
+			WHERE deleted_at IS NULL
+				AND status = 'completed'
+				AND payment_status = $3
+				AND created_at BETWEEN $1 AND $2
+		`, startDate, endDate, paymentStatus).Scan(&response.TotalSales, &response.TotalRevenue)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to get revenue summary", zap.Error(err))
+			return nil, fmt.Errorf("failed to get revenue summary: %w", err)
+		}
+		if response.TotalSales > 0 {
+			response.AverageSale = response.TotalRevenue / float64(response.TotalSales)
+		}
 	}
 
 	// Jika groupBy diminta, ambil data per period
@@ -135,6 +225,7 @@ func (rr *reportRepo) GetRevenueReport(ctx context.Context, startDate, endDate t
 				WHERE deleted_at IS NULL 
 					AND status = 'completed'
 					AND created_at BETWEEN $1 AND $2
+					AND ($3 = '' OR payment_status = $3)
 				GROUP BY DATE(created_at)
 				ORDER BY period_date ASC
 			`
@@ -149,6 +240,7 @@ func (rr *reportRepo) GetRevenueReport(ctx context.Context, startDate, endDate t
 				WHERE deleted_at IS NULL 
 					AND status = 'completed'
 					AND created_at BETWEEN $1 AND $2
+					AND ($3 = '' OR payment_status = $3)
 				GROUP BY TO_CHAR(created_at, 'YYYY-MM'), TO_CHAR(created_at, 'Month YYYY')
 				ORDER BY period_month ASC
 			`
@@ -156,9 +248,9 @@ func (rr *reportRepo) GetRevenueReport(ctx context.Context, startDate, endDate t
 			return response, nil // return summary saja tanpa grouping
 		}
 
-		rows, err := rr.db.Query(ctx, periodQuery, startDate, endDate)
+		rows, err := rr.db.Query(ctx, periodQuery, startDate, endDate, paymentStatus)
 		if err != nil {
-			rr.log.Warn("Failed to get grouped revenue", zap.Error(err))
+			utils.LoggerFromContext(ctx).Warn("Failed to get grouped revenue", zap.Error(err))
 			return response, nil // return summary meskipun grouping gagal
 		}
 		defer rows.Close()
@@ -187,3 +279,582 @@ func (rr *reportRepo) GetRevenueReport(ctx context.Context, startDate, endDate t
 
 	return response, nil
 }
+
+// ========== 4. INVENTORY VALUATION ==========
+// Rows dibaca satu per satu dari cursor pgx lalu diteruskan ke callback,
+// jadi katalog besar tidak pernah di-load seluruhnya ke memory
+func (rr *reportRepo) GetInventoryValuation(ctx context.Context, fn func(row report.InventoryValuationRow) error) error {
+	query := `
+		SELECT
+			p.id,
+			p.sku,
+			p.name,
+			COALESCE(c.name, '') as category_name,
+			p.stock_quantity,
+			p.cost_price,
+			p.unit_price,
+			p.cost_price * p.stock_quantity as cost_value,
+			p.unit_price * p.stock_quantity as retail_value
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE p.deleted_at IS NULL
+		ORDER BY p.name
+	`
+
+	rows, err := rr.db.Query(ctx, query)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query inventory valuation", zap.Error(err))
+		return fmt.Errorf("failed to get inventory valuation: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var row report.InventoryValuationRow
+		var productID uuid.UUID
+		if err := rows.Scan(
+			&productID,
+			&row.SKU,
+			&row.ProductName,
+			&row.CategoryName,
+			&row.StockQuantity,
+			&row.CostPrice,
+			&row.UnitPrice,
+			&row.CostValue,
+			&row.RetailValue,
+		); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan inventory valuation row", zap.Error(err))
+			return fmt.Errorf("scan inventory valuation failed: %w", err)
+		}
+		row.ProductID = productID.String()
+
+		if err := fn(row); err != nil {
+			return err
+		}
+		count++
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.LoggerFromContext(ctx).Error("Rows iteration error", zap.Error(err))
+		return fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Inventory valuation streamed", zap.Int("count", count))
+	return nil
+}
+
+// ========== 5. SALES BY CATEGORY ==========
+// Agregasi sale_items -> products -> categories untuk sales completed
+// includeEmpty: kategori aktif tanpa penjualan ikut ditampilkan (nilai 0)
+func (rr *reportRepo) GetSalesByCategory(ctx context.Context, startDate, endDate time.Time, includeEmpty bool) ([]report.CategorySales, error) {
+	joinType := "JOIN"
+	if includeEmpty {
+		joinType = "LEFT JOIN"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT 
+			c.id,
+			c.name,
+			COALESCE(agg.sales_count, 0) as sales_count,
+			COALESCE(agg.quantity_sold, 0) as quantity_sold,
+			COALESCE(agg.revenue, 0) as revenue
+		FROM categories c
+		%s (
+			SELECT 
+				p.category_id,
+				COUNT(DISTINCT s.id) as sales_count,
+				SUM(si.quantity) as quantity_sold,
+				SUM(si.total_price) as revenue
+			FROM sale_items si
+			JOIN sales s ON s.id = si.sale_id
+			JOIN products p ON p.id = si.product_id
+			WHERE s.deleted_at IS NULL
+				AND s.status = 'completed'
+				AND s.created_at >= $1
+				AND s.created_at < $2::timestamp + INTERVAL '1 day'
+			GROUP BY p.category_id
+		) agg ON agg.category_id = c.id
+		WHERE c.deleted_at IS NULL OR agg.category_id IS NOT NULL
+		ORDER BY revenue DESC, c.name
+	`, joinType)
+
+	rows, err := rr.db.Query(ctx, query, startDate, endDate)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get sales by category", zap.Error(err))
+		return nil, fmt.Errorf("failed to get sales by category: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]report.CategorySales, 0)
+	for rows.Next() {
+		var (
+			row        report.CategorySales
+			categoryID uuid.UUID
+		)
+		if err := rows.Scan(&categoryID, &row.CategoryName, &row.SalesCount, &row.QuantitySold, &row.Revenue); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan category sales", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan category sales: %w", err)
+		}
+		row.CategoryID = categoryID.String()
+		results = append(results, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return results, nil
+}
+
+// ========== 6. INVENTORY TURNOVER ==========
+// Opening/closing stock direkonstruksi dari ledger stock_movements (sama dengan StockAtTime):
+// stok pada waktu t = stok sekarang - total movement sejak t
+// opening pada startDate, closing pada akhir endDate, units sold dari sale completed dalam periode
+// Average stock & rasio turnover dihitung di service (inventoryTurnover)
+func (rr *reportRepo) GetInventoryTurnover(ctx context.Context, startDate, endDate time.Time) ([]report.ProductTurnover, error) {
+	query := `
+		SELECT
+			p.id,
+			p.name,
+			COALESCE(sold.units_sold, 0) as units_sold,
+			p.stock_quantity - COALESCE(mv.since_start, 0) as opening_stock,
+			p.stock_quantity - COALESCE(mv.since_end, 0) as closing_stock
+		FROM products p
+		LEFT JOIN (
+			SELECT si.product_id, SUM(si.quantity) as units_sold
+			FROM sale_items si
+			JOIN sales s ON s.id = si.sale_id
+			WHERE s.deleted_at IS NULL
+				AND s.status = 'completed'
+				AND s.created_at >= $1
+				AND s.created_at < $2::timestamp + INTERVAL '1 day'
+			GROUP BY si.product_id
+		) sold ON sold.product_id = p.id
+		LEFT JOIN (
+			SELECT
+				m.product_id,
+				SUM(m.quantity) as since_start,
+				SUM(m.quantity) FILTER (WHERE m.created_at >= $2::timestamp + INTERVAL '1 day') as since_end
+			FROM stock_movements m
+			WHERE m.created_at >= $1
+			GROUP BY m.product_id
+		) mv ON mv.product_id = p.id
+		WHERE p.deleted_at IS NULL
+		ORDER BY p.name
+	`
+
+	rows, err := rr.db.Query(ctx, query, startDate, endDate)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get inventory turnover", zap.Error(err))
+		return nil, fmt.Errorf("failed to get inventory turnover: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]report.ProductTurnover, 0)
+	for rows.Next() {
+		var (
+			row       report.ProductTurnover
+			productID uuid.UUID
+		)
+		err := rows.Scan(&productID, &row.ProductName, &row.UnitsSold, &row.OpeningStock, &row.ClosingStock)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan product turnover", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan product turnover: %w", err)
+		}
+		row.ProductID = productID.String()
+		results = append(results, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return results, nil
+}
+
+// trendIntervals interval yang valid (unit date_trunc)
+var trendIntervals = map[string]bool{
+	"day":   true,
+	"week":  true,
+	"month": true,
+}
+
+// ========== 7. SALES TIME SERIES ==========
+// Hanya bucket yang ada penjualannya, urut dari yang paling awal
+// Bucket kosong diisi 0 di service (fillTrendBuckets) supaya chart tidak bolong
+// endDate inklusif (sampai akhir hari)
+func (rr *reportRepo) GetSalesTimeSeries(ctx context.Context, startDate, endDate time.Time, interval string) ([]report.SalesTrendBucket, error) {
+	if !trendIntervals[interval] {
+		return nil, fmt.Errorf("invalid interval: %s", interval)
+	}
+
+	query := `
+		SELECT
+			date_trunc($3, s.created_at) as bucket,
+			COUNT(s.id) as sales_count,
+			COALESCE(SUM(s.total_amount), 0) as revenue
+		FROM sales s
+		WHERE s.deleted_at IS NULL
+			AND s.status = 'completed'
+			AND s.created_at >= $1
+			AND s.created_at < $2::timestamp + INTERVAL '1 day'
+		GROUP BY bucket
+		ORDER BY bucket ASC
+	`
+
+	rows, err := rr.db.Query(ctx, query, startDate, endDate, interval)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get sales time series", zap.Error(err))
+		return nil, fmt.Errorf("failed to get sales time series: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]report.SalesTrendBucket, 0)
+	for rows.Next() {
+		var (
+			bucket report.SalesTrendBucket
+			period time.Time
+		)
+		if err := rows.Scan(&period, &bucket.SalesCount, &bucket.Revenue); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan sales trend bucket", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan sales trend bucket: %w", err)
+		}
+		bucket.Period = period.Format("2006-01-02")
+		results = append(results, bucket)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return results, nil
+}
+
+// ========== 8. WAREHOUSE INVENTORY REPORT ==========
+// Sama dengan GetProductInventoryReport tapi hanya produk di shelf aktif milik warehouse
+func (rr *reportRepo) GetWarehouseInventory(ctx context.Context, warehouseID uuid.UUID) (*report.ProductReportResponse, error) {
+	query := `
+		SELECT 
+			COUNT(*) as total_products,
+			COALESCE(SUM(p.cost_price * p.stock_quantity), 0) as total_value,
+			COALESCE(SUM(p.stock_quantity), 0) as total_stock,
+			COUNT(CASE WHEN p.stock_quantity <= p.min_stock_level AND p.stock_quantity > 0 THEN 1 END) as low_stock_count,
+			COUNT(CASE WHEN p.stock_quantity = 0 THEN 1 END) as out_of_stock_count
+		FROM products p
+		JOIN shelves s ON s.id = p.shelf_id AND s.deleted_at IS NULL
+		WHERE p.deleted_at IS NULL AND s.warehouse_id = $1
+	`
+
+	var result report.ProductReportResponse
+	err := rr.db.QueryRow(ctx, query, warehouseID).Scan(
+		&result.TotalProducts,
+		&result.TotalValue,
+		&result.TotalStock,
+		&result.LowStockCount,
+		&result.OutOfStockCount,
+	)
+
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get warehouse inventory report",
+			zap.Error(err),
+			zap.String("warehouse_id", warehouseID.String()))
+		return nil, fmt.Errorf("failed to get warehouse inventory report: %w", err)
+	}
+
+	// Calculate average
+	if result.TotalProducts > 0 {
+		result.AvgStockPerProduct = float64(result.TotalStock) / float64(result.TotalProducts)
+	}
+
+	return &result, nil
+}
+
+// GetShelfDistribution product count & total stock per rak aktif di warehouse
+// Produk multi location dihitung per baris lokasi, produk single location di rak utamanya
+func (rr *reportRepo) GetShelfDistribution(ctx context.Context, warehouseID uuid.UUID) ([]report.ShelfDistribution, error) {
+	query := `
+		WITH shelf_stock AS (
+			SELECT l.shelf_id, l.product_id, l.quantity
+			FROM product_stock_locations l
+			JOIN products p ON p.id = l.product_id AND p.deleted_at IS NULL
+			UNION ALL
+			SELECT p.shelf_id, p.id, p.stock_quantity
+			FROM products p
+			WHERE p.deleted_at IS NULL
+				AND NOT EXISTS (SELECT 1 FROM product_stock_locations l WHERE l.product_id = p.id)
+		)
+		SELECT 
+			s.id, s.code, s.name,
+			COUNT(ss.product_id) as product_count,
+			COALESCE(SUM(ss.quantity), 0) as total_stock
+		FROM shelves s
+		LEFT JOIN shelf_stock ss ON ss.shelf_id = s.id
+		WHERE s.warehouse_id = $1 AND s.deleted_at IS NULL
+		GROUP BY s.id, s.code, s.name
+		ORDER BY s.code
+	`
+
+	rows, err := rr.db.Query(ctx, query, warehouseID)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get shelf distribution",
+			zap.Error(err),
+			zap.String("warehouse_id", warehouseID.String()))
+		return nil, fmt.Errorf("failed to get shelf distribution: %w", err)
+	}
+	defer rows.Close()
+
+	shelves := make([]report.ShelfDistribution, 0)
+	for rows.Next() {
+		var shelf report.ShelfDistribution
+		if err := rows.Scan(&shelf.ShelfID, &shelf.ShelfCode, &shelf.ShelfName, &shelf.ProductCount, &shelf.TotalStock); err != nil {
+			return nil, fmt.Errorf("failed to scan shelf distribution: %w", err)
+		}
+		shelves = append(shelves, shelf)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return shelves, nil
+}
+
+// ========== 10. SALES BY HOUR ==========
+// endDate inclusive (sampai akhir hari tersebut), jam dari created_at (waktu server)
+// Hanya jam yang ada penjualannya, 24 bucket diisi di service
+func (rr *reportRepo) GetSalesByHour(ctx context.Context, startDate, endDate time.Time) ([]report.HourlySales, error) {
+	query := `
+		SELECT
+			EXTRACT(HOUR FROM s.created_at)::int as hour,
+			COUNT(s.id) as sales_count,
+			COALESCE(SUM(s.total_amount), 0) as revenue
+		FROM sales s
+		WHERE s.deleted_at IS NULL
+			AND s.status = 'completed'
+			AND s.created_at >= $1
+			AND s.created_at < $2::timestamp + INTERVAL '1 day'
+		GROUP BY hour
+		ORDER BY hour ASC
+	`
+
+	rows, err := rr.db.Query(ctx, query, startDate, endDate)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get sales by hour", zap.Error(err))
+		return nil, fmt.Errorf("failed to get sales by hour: %w", err)
+	}
+	defer rows.Close()
+
+	hours := make([]report.HourlySales, 0)
+	for rows.Next() {
+		var hour report.HourlySales
+		if err := rows.Scan(&hour.Hour, &hour.SalesCount, &hour.Revenue); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan hourly sales", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan hourly sales: %w", err)
+		}
+		hours = append(hours, hour)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return hours, nil
+}
+
+// ========== 11. REVENUE BY PAYMENT METHOD ==========
+// endDate inclusive (sampai akhir hari tersebut), cara bayar tanpa penjualan diisi di service
+func (rr *reportRepo) GetRevenueByPaymentMethod(ctx context.Context, startDate, endDate time.Time) ([]report.PaymentMethodRevenue, error) {
+	query := `
+		SELECT
+			payment_method,
+			COUNT(*) as sales_count,
+			COALESCE(SUM(total_amount), 0) as revenue
+		FROM sales
+		WHERE deleted_at IS NULL
+			AND status = 'completed'
+			AND created_at >= $1
+			AND created_at < $2::timestamp + INTERVAL '1 day'
+		GROUP BY payment_method
+	`
+
+	rows, err := rr.db.Query(ctx, query, startDate, endDate)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get revenue by payment method", zap.Error(err))
+		return nil, fmt.Errorf("failed to get revenue by payment method: %w", err)
+	}
+	defer rows.Close()
+
+	methods := make([]report.PaymentMethodRevenue, 0)
+	for rows.Next() {
+		var method report.PaymentMethodRevenue
+		if err := rows.Scan(&method.PaymentMethod, &method.SalesCount, &method.Revenue); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan payment method revenue", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan payment method revenue: %w", err)
+		}
+		methods = append(methods, method)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return methods, nil
+}
+
+// ========== 12. CUSTOMER SALES ==========
+// endDate inclusive (sampai akhir hari tersebut), sale walk-in (tanpa nama & telepon) tidak diambil
+func (rr *reportRepo) FindCustomerSales(ctx context.Context, startDate, endDate time.Time) ([]report.CustomerSale, error) {
+	query := `
+		SELECT customer_name, customer_phone, total_amount, created_at
+		FROM sales
+		WHERE deleted_at IS NULL
+			AND status = 'completed'
+			AND (customer_phone IS NOT NULL OR customer_name IS NOT NULL)
+			AND created_at >= $1
+			AND created_at < $2::timestamp + INTERVAL '1 day'
+		ORDER BY created_at ASC
+	`
+
+	rows, err := rr.db.Query(ctx, query, startDate, endDate)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get customer sales", zap.Error(err))
+		return nil, fmt.Errorf("failed to get customer sales: %w", err)
+	}
+	defer rows.Close()
+
+	sales := make([]report.CustomerSale, 0)
+	for rows.Next() {
+		var sale report.CustomerSale
+		if err := rows.Scan(&sale.CustomerName, &sale.CustomerPhone, &sale.TotalAmount, &sale.CreatedAt); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan customer sale", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan customer sale: %w", err)
+		}
+		sales = append(sales, sale)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return sales, nil
+}
+
+// ========== 13. FULFILLMENT TIME ==========
+// Hanya sale completed yang punya completed_at (pernah lewat pending), filter by created_at
+func (rr *reportRepo) FindFulfillmentTimes(ctx context.Context, startDate, endDate time.Time) ([]report.SaleFulfillment, error) {
+	query := `
+		SELECT created_at, completed_at
+		FROM sales
+		WHERE deleted_at IS NULL
+			AND status = 'completed'
+			AND completed_at IS NOT NULL
+			AND created_at >= $1
+			AND created_at < $2::timestamp + INTERVAL '1 day'
+	`
+
+	rows, err := rr.db.Query(ctx, query, startDate, endDate)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get fulfillment time", zap.Error(err))
+		return nil, fmt.Errorf("failed to get fulfillment time: %w", err)
+	}
+	defer rows.Close()
+
+	sales := make([]report.SaleFulfillment, 0)
+	for rows.Next() {
+		var sale report.SaleFulfillment
+		if err := rows.Scan(&sale.CreatedAt, &sale.CompletedAt); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan fulfillment time", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan fulfillment time: %w", err)
+		}
+		sales = append(sales, sale)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return sales, nil
+}
+
+// ========== 14-16. MONTHLY INVENTORY SNAPSHOT ==========
+// Stok pada waktu $1 = stok sekarang dikurangi movement sejak $1 (sama dengan StockAtTime)
+// Produk yang dibuat sesudah $1 tidak ikut, produk yang dihapus sesudah $1 tetap ikut
+const snapshotStockQuery = `
+	SELECT p.id, p.name,
+	       p.stock_quantity - COALESCE((
+	           SELECT SUM(m.quantity) FROM stock_movements m
+	           WHERE m.product_id = p.id AND m.created_at >= $1
+	       ), 0) as stock_quantity
+	FROM products p
+	WHERE p.created_at < $1
+		AND (p.deleted_at IS NULL OR p.deleted_at >= $1)
+`
+
+func (rr *reportRepo) ComputeStockSnapshot(ctx context.Context, at time.Time) ([]report.SnapshotProduct, error) {
+	rows, err := rr.db.Query(ctx, snapshotStockQuery+" ORDER BY p.name", at)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to compute stock snapshot", zap.Error(err))
+		return nil, fmt.Errorf("failed to compute stock snapshot: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSnapshotProducts(ctx, rows)
+}
+
+// Satu statement INSERT ... SELECT, jadi snapshot tersimpan utuh atau tidak sama sekali
+func (rr *reportRepo) SaveMonthlySnapshot(ctx context.Context, month, at time.Time) error {
+	query := `
+		INSERT INTO inventory_snapshots (month, product_id, stock_quantity, created_at)
+		SELECT $2, s.id, s.stock_quantity, $3
+		FROM (` + snapshotStockQuery + `) s
+		ON CONFLICT (month, product_id) DO NOTHING
+	`
+
+	if _, err := rr.db.Exec(ctx, query, at, month, time.Now()); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to save monthly snapshot", zap.Error(err))
+		return fmt.Errorf("failed to save monthly snapshot: %w", err)
+	}
+
+	return nil
+}
+
+func (rr *reportRepo) FindMonthlySnapshot(ctx context.Context, month time.Time) ([]report.SnapshotProduct, error) {
+	query := `
+		SELECT s.product_id, p.name, s.stock_quantity
+		FROM inventory_snapshots s
+		JOIN products p ON p.id = s.product_id
+		WHERE s.month = $1
+		ORDER BY p.name
+	`
+
+	rows, err := rr.db.Query(ctx, query, month)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get monthly snapshot", zap.Error(err))
+		return nil, fmt.Errorf("failed to get monthly snapshot: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSnapshotProducts(ctx, rows)
+}
+
+// scanSnapshotProducts helper: baris (product_id, name, stock_quantity)
+func scanSnapshotProducts(ctx context.Context, rows pgx.Rows) ([]report.SnapshotProduct, error) {
+	products := make([]report.SnapshotProduct, 0)
+	for rows.Next() {
+		var productID uuid.UUID
+		var item report.SnapshotProduct
+		if err := rows.Scan(&productID, &item.ProductName, &item.StockQuantity); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan snapshot row", zap.Error(err))
+			return nil, fmt.Errorf("scan snapshot failed: %w", err)
+		}
+		item.ProductID = productID.String()
+		products = append(products, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return products, nil
+}
@@ -1,31 +1,55 @@
 package repository
 
 import (
+	"errors"
 	"inventory-system/database"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"go.uber.org/zap"
 )
 
 type Repository struct {
-	Session   SessionRepo
-	User      UserRepo
-	Warehouse WarehouseRepo
-	Category  CategoryRepo
-	Shelf     ShelfRepo
-	Product   ProductRepo
-	Sale      SaleRepo
-	Report    ReportRepo
+	Session       SessionRepo
+	User          UserRepo
+	Warehouse     WarehouseRepo
+	Category      CategoryRepo
+	Shelf         ShelfRepo
+	Product       ProductRepo
+	Sale          SaleRepo
+	Report        ReportRepo
+	Webhook       WebhookRepo
+	Replenishment ReplenishmentRepo
+	StockLocation StockLocationRepo
+	StockMovement StockMovementRepo
 }
 
 func NewRepository(db database.PgxIface, log *zap.Logger) *Repository {
 	return &Repository{
-		Session:   NewSessionRepo(db, log),
-		User:      NewUserRepo(db, log),
-		Warehouse: NewWarehouseRepo(db, log),
-		Category:  NewCategoryRepo(db, log),
-		Shelf:     NewShelfRepo(db, log),
-		Product:   NewProductRepo(db, log),
-		Sale:      NewSaleRepo(db, log),
-		Report:    NewReportRepo(db, log),
+		Session:       NewSessionRepo(db, log),
+		User:          NewUserRepo(db, log),
+		Warehouse:     NewWarehouseRepo(db, log),
+		Category:      NewCategoryRepo(db, log),
+		Shelf:         NewShelfRepo(db, log),
+		Product:       NewProductRepo(db, log),
+		Sale:          NewSaleRepo(db, log),
+		Report:        NewReportRepo(db, log),
+		Webhook:       NewWebhookRepo(db, log),
+		Replenishment: NewReplenishmentRepo(db, log),
+		StockLocation: NewStockLocationRepo(db, log),
+		StockMovement: NewStockMovementRepo(db, log),
 	}
 }
+
+// isUniqueViolation cek apakah error dari postgres adalah pelanggaran unique constraint
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// activeFilter WHERE clause soft delete, includeDeleted = tampilkan juga data terhapus
+func activeFilter(includeDeleted bool) string {
+	if includeDeleted {
+		return ""
+	}
+	return "WHERE deleted_at IS NULL"
+}
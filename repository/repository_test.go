@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ========== FAKE DB ==========
+
+// fakeHandler dipanggil untuk query yang SQL-nya mengandung match, hasil berupa baris kolom
+// Untuk Exec jumlah baris dipakai sebagai rows affected
+type fakeHandler struct {
+	match string
+	fn    func(args []any) ([][]any, error)
+}
+
+type fakeCall struct {
+	sql  string
+	args []any
+}
+
+// fakeDB implementasi database.PgxIface + pgx.Tx tanpa Postgres, query dicocokkan lewat potongan SQL
+type fakeDB struct {
+	pgx.Tx
+	t        *testing.T
+	handlers []fakeHandler
+	calls    []fakeCall
+
+	begins    int
+	commits   int
+	rollbacks int
+	committed bool
+	onBegin   func()
+	onCommit  func()
+}
+
+func newFakeDB(t *testing.T) *fakeDB {
+	return &fakeDB{t: t}
+}
+
+// on mendaftarkan handler, yang didaftarkan lebih dulu menang jika beberapa cocok
+func (f *fakeDB) on(match string, fn func(args []any) ([][]any, error)) {
+	f.handlers = append(f.handlers, fakeHandler{match: match, fn: fn})
+}
+
+func (f *fakeDB) run(query string, args []any) ([][]any, error) {
+	normalized := strings.Join(strings.Fields(query), " ")
+	f.calls = append(f.calls, fakeCall{sql: normalized, args: args})
+	for _, h := range f.handlers {
+		if strings.Contains(normalized, h.match) {
+			return h.fn(args)
+		}
+	}
+	f.t.Errorf("unexpected query: %s", normalized)
+	return nil, fmt.Errorf("unexpected query")
+}
+
+// executed menghitung query yang SQL-nya mengandung match
+func (f *fakeDB) executed(match string) int {
+	count := 0
+	for _, c := range f.calls {
+		if strings.Contains(c.sql, match) {
+			count++
+		}
+	}
+	return count
+}
+
+func (f *fakeDB) Query(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+	rows, err := f.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{rows: rows}, nil
+}
+
+func (f *fakeDB) QueryRow(ctx context.Context, query string, args ...any) pgx.Row {
+	rows, err := f.run(query, args)
+	return &fakeRow{rows: rows, err: err}
+}
+
+func (f *fakeDB) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	rows, err := f.run(query, args)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return pgconn.NewCommandTag(fmt.Sprintf("UPDATE %d", len(rows))), nil
+}
+
+func (f *fakeDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	f.begins++
+	f.committed = false
+	if f.onBegin != nil {
+		f.onBegin()
+	}
+	return f, nil
+}
+
+func (f *fakeDB) Commit(ctx context.Context) error {
+	f.commits++
+	f.committed = true
+	if f.onCommit != nil {
+		f.onCommit()
+	}
+	return nil
+}
+
+func (f *fakeDB) Rollback(ctx context.Context) error {
+	// Sama seperti pgx: rollback setelah commit tidak mengubah apa-apa
+	if !f.committed {
+		f.rollbacks++
+	}
+	return nil
+}
+
+// ========== FAKE ROWS ==========
+
+type fakeRows struct {
+	pgx.Rows
+	rows [][]any
+	i    int
+}
+
+func (r *fakeRows) Next() bool {
+	r.i++
+	return r.i <= len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	return scanValues(r.rows[r.i-1], dest)
+}
+
+func (r *fakeRows) Values() ([]any, error) { return r.rows[r.i-1], nil }
+func (r *fakeRows) Err() error             { return nil }
+func (r *fakeRows) Close()                 {}
+
+type fakeRow struct {
+	rows [][]any
+	err  error
+}
+
+func (r *fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.rows) == 0 {
+		return pgx.ErrNoRows
+	}
+	return scanValues(r.rows[0], dest)
+}
+
+// scanValues meniru konversi pgx secukupnya: assign langsung, ke pointer, atau konversi tipe
+func scanValues(row []any, dest []any) error {
+	if len(row) != len(dest) {
+		return fmt.Errorf("scan: %d columns into %d targets", len(row), len(dest))
+	}
+	for i, d := range dest {
+		target := reflect.ValueOf(d).Elem()
+		if row[i] == nil {
+			target.Set(reflect.Zero(target.Type()))
+			continue
+		}
+
+		value := reflect.ValueOf(row[i])
+		scanner, isScanner := d.(sql.Scanner)
+		switch {
+		case value.Type().AssignableTo(target.Type()):
+			target.Set(value)
+		case isScanner:
+			if err := scanner.Scan(row[i]); err != nil {
+				return err
+			}
+		case target.Kind() == reflect.Ptr && value.Type().ConvertibleTo(target.Type().Elem()):
+			ptr := reflect.New(target.Type().Elem())
+			ptr.Elem().Set(value.Convert(target.Type().Elem()))
+			target.Set(ptr)
+		case value.Type().ConvertibleTo(target.Type()):
+			target.Set(value.Convert(target.Type()))
+		default:
+			return fmt.Errorf("scan: cannot assign %T to %s", row[i], target.Type())
+		}
+	}
+	return nil
+}
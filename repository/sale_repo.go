@@ -2,13 +2,16 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"inventory-system/database"
 	"inventory-system/model"
+	"inventory-system/utils"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
@@ -16,15 +19,41 @@ import (
 type SaleRepo interface {
 	// Sale operations
 	CreateSale(ctx context.Context, sale *model.Sale) error
+	CreateSaleWithItems(ctx context.Context, sale *model.Sale, items []model.SaleItem, shelves map[uuid.UUID]uuid.UUID) (map[uuid.UUID]int, error)
 	FindSaleByID(ctx context.Context, id uuid.UUID) (*model.Sale, error)
-	FindAllSales(ctx context.Context, userID *uuid.UUID, limit, offset int) ([]model.Sale, error)
-	CountAllSales(ctx context.Context, userID *uuid.UUID) (int, error)
-	UpdateSaleStatus(ctx context.Context, id uuid.UUID, status model.SaleStatus) error
+	FindByInvoiceNumber(ctx context.Context, invoiceNumber string) (*model.Sale, error)
+	FindAllSales(ctx context.Context, filter SaleListFilter, limit, offset int) ([]model.Sale, error)
+	CountAllSales(ctx context.Context, filter SaleListFilter) (int, error)
+	UpdateSaleStatus(ctx context.Context, id uuid.UUID, status model.SaleStatus, reason *string) error
+	UpdateSaleUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+	UpdatePayment(ctx context.Context, id uuid.UUID, amountPaid float64, status model.PaymentStatus) error
+	CancelSale(ctx context.Context, id uuid.UUID, reason string) (model.SaleStatus, error)
+	RecalculateSaleTotal(ctx context.Context, id uuid.UUID) (bool, error)
+	RecalculateAllSaleTotals(ctx context.Context) (int, error)
 
 	// Sale items operations
 	CreateSaleItems(ctx context.Context, items []model.SaleItem) error
 	FindSaleItems(ctx context.Context, saleID uuid.UUID) ([]model.SaleItem, error)
 	FindSaleItemsWithProduct(ctx context.Context, saleID uuid.UUID) ([]model.SaleItemWithProduct, error)
+
+	// Export (snapshot denormalized)
+	FindSaleWithCashier(ctx context.Context, id uuid.UUID) (*model.SaleWithCashier, error)
+	FindSaleItemDetails(ctx context.Context, saleID uuid.UUID) ([]model.SaleItemDetail, error)
+	StreamSalesWithItems(ctx context.Context, startDate, endDate time.Time, fn func(s *model.SaleWithCashier, item model.SaleItemDetail) error) error
+
+	// Product sales history
+	FindSalesByProduct(ctx context.Context, productID uuid.UUID, startDate, endDate *time.Time, includeCancelled bool, limit, offset int) ([]model.ProductSaleHistory, error)
+	CountSalesByProduct(ctx context.Context, productID uuid.UUID, startDate, endDate *time.Time, includeCancelled bool) (int, error)
+
+	// Flat ledger semua sale item (accounting)
+	FindAllSaleItems(ctx context.Context, startDate, endDate *time.Time, includeCancelled bool, limit, offset int) ([]model.SaleItemLedgerEntry, error)
+	CountAllSaleItems(ctx context.Context, startDate, endDate *time.Time, includeCancelled bool) (int, error)
+
+	// Jumlah sale per status (conversion breakdown)
+	CountByStatus(ctx context.Context, startDate, endDate time.Time) ([]model.SaleStatusCount, error)
+
+	// Daily time series per kasir (hari tanpa penjualan diisi 0)
+	GetUserDailySales(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]model.DailySales, error)
 }
 
 type saleRepo struct {
@@ -39,9 +68,87 @@ func NewSaleRepo(db database.PgxIface, log *zap.Logger) SaleRepo {
 
 // CreateSale inserts new sale record
 func (sr *saleRepo) CreateSale(ctx context.Context, sale *model.Sale) error {
+	if err := insertSale(ctx, sr.db, sale); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to create sale", zap.Error(err))
+		return err
+	}
+
+	utils.LoggerFromContext(ctx).Info("Sale created", zap.String("invoice", sale.InvoiceNumber))
+	return nil
+}
+
+// CreateSaleItems inserts multiple sale items in batch
+func (sr *saleRepo) CreateSaleItems(ctx context.Context, items []model.SaleItem) error {
+	if err := insertSaleItems(ctx, sr.db, items); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to create sale items", zap.Error(err))
+		return err
+	}
+
+	utils.LoggerFromContext(ctx).Info("Sale items created", zap.Int("count", len(items)))
+	return nil
+}
+
+// CreateSaleWithItems simpan sale, item & potong stok per item dalam satu transaction (all-or-nothing)
+// Produk di shelves dipotong dari rak tersebut, sisanya lewat total produk (multi location: rak utama dulu)
+// Return stok produk setelah dipotong
+func (sr *saleRepo) CreateSaleWithItems(ctx context.Context, sale *model.Sale, items []model.SaleItem, shelves map[uuid.UUID]uuid.UUID) (map[uuid.UUID]int, error) {
+	tx, err := sr.db.Begin(ctx)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to begin transaction", zap.Error(err))
+		return nil, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	// Rollback no-op jika sudah commit
+	defer tx.Rollback(ctx)
+
+	if err := insertSale(ctx, tx, sale); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to create sale", zap.Error(err))
+		return nil, err
+	}
+
+	for i := range items {
+		items[i].SaleID = sale.ID
+	}
+	if err := insertSaleItems(ctx, tx, items); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to create sale items", zap.Error(err))
+		return nil, err
+	}
+
+	// Dicatat di ledger sebagai movement sale, dipakai CancelSale untuk restore
+	movement := model.StockMovement{MovementType: model.StockMovementSale, ReferenceID: &sale.ID}
+	stockAfter := make(map[uuid.UUID]int, len(items))
+	for _, item := range items {
+		var total int
+		if shelfID, ok := shelves[item.ProductID]; ok {
+			total, err = deductLocationStock(ctx, tx, item.ProductID, shelfID, item.Quantity, movement)
+		} else {
+			total, err = deductProductStock(ctx, tx, item.ProductID, item.Quantity, movement)
+		}
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to deduct sale stock, sale rolled back",
+				zap.Error(err),
+				zap.String("product_id", item.ProductID.String()))
+			return nil, err
+		}
+		stockAfter[item.ProductID] = total
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to commit sale", zap.Error(err))
+		return nil, fmt.Errorf("commit sale failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Sale created",
+		zap.String("invoice", sale.InvoiceNumber),
+		zap.Int("items", len(items)))
+	return stockAfter, nil
+}
+
+// insertSale INSERT satu sale, db bisa pool atau transaction
+func insertSale(ctx context.Context, db database.PgxIface, sale *model.Sale) error {
 	query := `
-		INSERT INTO sales (id, invoice_number, user_id, total_amount, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO sales (id, invoice_number, user_id, total_amount, status, payment_method, customer_name, customer_phone,
+		                   payment_status, amount_paid, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 
 	// Generate sale metadata
@@ -54,29 +161,34 @@ func (sr *saleRepo) CreateSale(ctx context.Context, sale *model.Sale) error {
 	if sale.Status == "" {
 		sale.Status = model.SaleStatusCompleted
 	}
+	if sale.PaymentMethod == "" {
+		sale.PaymentMethod = model.PaymentMethodCash
+	}
+	if sale.PaymentStatus == "" {
+		sale.PaymentStatus = model.PaymentStatusUnpaid
+	}
 
-	_, err := sr.db.Exec(ctx, query,
+	_, err := db.Exec(ctx, query,
 		sale.ID, sale.InvoiceNumber, sale.UserID, sale.TotalAmount,
-		sale.Status, sale.CreatedAt, sale.UpdatedAt,
+		sale.Status, sale.PaymentMethod, sale.CustomerName, sale.CustomerPhone,
+		sale.PaymentStatus, sale.AmountPaid, sale.CreatedAt, sale.UpdatedAt,
 	)
 	if err != nil {
-		sr.log.Error("Failed to create sale", zap.Error(err))
 		return fmt.Errorf("create sale failed: %w", err)
 	}
 
-	sr.log.Info("Sale created", zap.String("invoice", sale.InvoiceNumber))
 	return nil
 }
 
-// CreateSaleItems inserts multiple sale items in batch
-func (sr *saleRepo) CreateSaleItems(ctx context.Context, items []model.SaleItem) error {
+// insertSaleItems batch INSERT sale items, db bisa pool atau transaction
+func insertSaleItems(ctx context.Context, db database.PgxIface, items []model.SaleItem) error {
 	if len(items) == 0 {
 		return fmt.Errorf("no items to insert")
 	}
 
 	// Build batch insert query
 	query := `
-		INSERT INTO sale_items (id, sale_id, product_id, quantity, unit_price, total_price, created_at)
+		INSERT INTO sale_items (id, sale_id, product_id, quantity, unit_price, total_price, notes, created_at)
 		VALUES `
 
 	args := make([]interface{}, 0)
@@ -91,42 +203,60 @@ func (sr *saleRepo) CreateSaleItems(ctx context.Context, items []model.SaleItem)
 		item.UpdatedAt = now
 
 		// Build position parameters
-		pos := i * 7
+		pos := i * 8
 		valueStrings = append(valueStrings,
-			fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)",
-				pos+1, pos+2, pos+3, pos+4, pos+5, pos+6, pos+7))
+			fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+				pos+1, pos+2, pos+3, pos+4, pos+5, pos+6, pos+7, pos+8))
 
 		// Add values to args slice
 		args = append(args,
 			item.ID, item.SaleID, item.ProductID, item.Quantity,
-			item.UnitPrice, item.TotalPrice, item.CreatedAt)
+			item.UnitPrice, item.TotalPrice, item.Notes, item.CreatedAt)
 	}
 
 	// Combine all value strings
 	query += strings.Join(valueStrings, ", ")
 
 	// Execute batch insert
-	_, err := sr.db.Exec(ctx, query, args...)
-	if err != nil {
-		sr.log.Error("Failed to create sale items", zap.Error(err))
+	if _, err := db.Exec(ctx, query, args...); err != nil {
 		return fmt.Errorf("create sale items failed: %w", err)
 	}
 
-	sr.log.Info("Sale items created", zap.Int("count", len(items)))
 	return nil
 }
 
 // FindSaleByID retrieves sale by ID
 func (sr *saleRepo) FindSaleByID(ctx context.Context, id uuid.UUID) (*model.Sale, error) {
 	query := `
-		SELECT id, invoice_number, user_id, total_amount, status, created_at, updated_at, deleted_at
+		SELECT id, invoice_number, user_id, total_amount, status, payment_method, customer_name, customer_phone, payment_status, amount_paid, cancelled_reason, cancelled_at, created_at, updated_at, deleted_at
 		FROM sales WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var sale model.Sale
 	err := sr.db.QueryRow(ctx, query, id).Scan(
 		&sale.ID, &sale.InvoiceNumber, &sale.UserID, &sale.TotalAmount,
-		&sale.Status, &sale.CreatedAt, &sale.UpdatedAt, &sale.DeletedAt,
+		&sale.Status, &sale.PaymentMethod, &sale.CustomerName, &sale.CustomerPhone, &sale.PaymentStatus, &sale.AmountPaid, &sale.CancelledReason, &sale.CancelledAt,
+		&sale.CreatedAt, &sale.UpdatedAt, &sale.DeletedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("sale not found: %w", err)
+	}
+
+	return &sale, nil
+}
+
+// FindByInvoiceNumber retrieves sale by invoice number (dari struk customer)
+func (sr *saleRepo) FindByInvoiceNumber(ctx context.Context, invoiceNumber string) (*model.Sale, error) {
+	query := `
+		SELECT id, invoice_number, user_id, total_amount, status, payment_method, customer_name, customer_phone, payment_status, amount_paid, cancelled_reason, cancelled_at, created_at, updated_at, deleted_at
+		FROM sales WHERE invoice_number = $1 AND deleted_at IS NULL
+	`
+
+	var sale model.Sale
+	err := sr.db.QueryRow(ctx, query, invoiceNumber).Scan(
+		&sale.ID, &sale.InvoiceNumber, &sale.UserID, &sale.TotalAmount,
+		&sale.Status, &sale.PaymentMethod, &sale.CustomerName, &sale.CustomerPhone, &sale.PaymentStatus, &sale.AmountPaid, &sale.CancelledReason, &sale.CancelledAt,
+		&sale.CreatedAt, &sale.UpdatedAt, &sale.DeletedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("sale not found: %w", err)
@@ -138,13 +268,13 @@ func (sr *saleRepo) FindSaleByID(ctx context.Context, id uuid.UUID) (*model.Sale
 // FindSaleItems retrieves all items for a sale
 func (sr *saleRepo) FindSaleItems(ctx context.Context, saleID uuid.UUID) ([]model.SaleItem, error) {
 	query := `
-		SELECT id, sale_id, product_id, quantity, unit_price, total_price, created_at, updated_at
+		SELECT id, sale_id, product_id, quantity, unit_price, total_price, notes, created_at, updated_at
 		FROM sale_items WHERE sale_id = $1 ORDER BY created_at
 	`
 
 	rows, err := sr.db.Query(ctx, query, saleID)
 	if err != nil {
-		sr.log.Error("Failed to query sale items", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to query sale items", zap.Error(err))
 		return nil, fmt.Errorf("query sale items failed: %w", err)
 	}
 	defer rows.Close()
@@ -154,10 +284,10 @@ func (sr *saleRepo) FindSaleItems(ctx context.Context, saleID uuid.UUID) ([]mode
 		var item model.SaleItem
 		err := rows.Scan(
 			&item.ID, &item.SaleID, &item.ProductID, &item.Quantity,
-			&item.UnitPrice, &item.TotalPrice, &item.CreatedAt, &item.UpdatedAt,
+			&item.UnitPrice, &item.TotalPrice, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
 		)
 		if err != nil {
-			sr.log.Error("Failed to scan sale item", zap.Error(err))
+			utils.LoggerFromContext(ctx).Error("Failed to scan sale item", zap.Error(err))
 			return nil, fmt.Errorf("scan sale item failed: %w", err)
 		}
 		items = append(items, item)
@@ -170,7 +300,7 @@ func (sr *saleRepo) FindSaleItems(ctx context.Context, saleID uuid.UUID) ([]mode
 func (sr *saleRepo) FindSaleItemsWithProduct(ctx context.Context, saleID uuid.UUID) ([]model.SaleItemWithProduct, error) {
 	query := `
 		SELECT si.id, si.sale_id, si.product_id, si.quantity, si.unit_price, 
-		       si.total_price, si.created_at, si.updated_at, p.name as product_name
+		       si.total_price, si.notes, si.created_at, si.updated_at, p.name as product_name
 		FROM sale_items si
 		JOIN products p ON si.product_id = p.id
 		WHERE si.sale_id = $1
@@ -179,7 +309,7 @@ func (sr *saleRepo) FindSaleItemsWithProduct(ctx context.Context, saleID uuid.UU
 
 	rows, err := sr.db.Query(ctx, query, saleID)
 	if err != nil {
-		sr.log.Error("Failed to query sale items with product", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to query sale items with product", zap.Error(err))
 		return nil, fmt.Errorf("query sale items failed: %w", err)
 	}
 	defer rows.Close()
@@ -189,46 +319,209 @@ func (sr *saleRepo) FindSaleItemsWithProduct(ctx context.Context, saleID uuid.UU
 		var item model.SaleItemWithProduct
 		err := rows.Scan(
 			&item.ID, &item.SaleID, &item.ProductID, &item.Quantity,
-			&item.UnitPrice, &item.TotalPrice, &item.CreatedAt, &item.UpdatedAt,
+			&item.UnitPrice, &item.TotalPrice, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
 			&item.ProductName,
 		)
 		if err != nil {
-			sr.log.Error("Failed to scan sale item", zap.Error(err))
+			utils.LoggerFromContext(ctx).Error("Failed to scan sale item", zap.Error(err))
+			return nil, fmt.Errorf("scan sale item failed: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// FindSaleWithCashier sale + username & nama kasir dalam satu query
+// User yang sudah di-soft delete tetap ikut (arsip butuh nama kasir)
+func (sr *saleRepo) FindSaleWithCashier(ctx context.Context, id uuid.UUID) (*model.SaleWithCashier, error) {
+	query := `
+		SELECT s.id, s.invoice_number, s.user_id, s.total_amount, s.status, s.payment_method, s.customer_name, s.customer_phone, s.payment_status, s.amount_paid, s.cancelled_reason, s.cancelled_at,
+		       s.created_at, s.updated_at, s.deleted_at,
+		       COALESCE(u.username, ''), COALESCE(u.full_name, '')
+		FROM sales s
+		LEFT JOIN users u ON u.id = s.user_id
+		WHERE s.id = $1 AND s.deleted_at IS NULL
+	`
+
+	var sale model.SaleWithCashier
+	err := sr.db.QueryRow(ctx, query, id).Scan(
+		&sale.ID, &sale.InvoiceNumber, &sale.UserID, &sale.TotalAmount,
+		&sale.Status, &sale.PaymentMethod, &sale.CustomerName, &sale.CustomerPhone, &sale.PaymentStatus, &sale.AmountPaid, &sale.CancelledReason, &sale.CancelledAt,
+		&sale.CreatedAt, &sale.UpdatedAt, &sale.DeletedAt,
+		&sale.CashierUsername, &sale.CashierFullName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("sale not found: %w", err)
+	}
+
+	return &sale, nil
+}
+
+// FindSaleItemDetails item + nama produk & kategori dalam satu query
+// Produk/kategori yang sudah di-soft delete tetap ikut
+func (sr *saleRepo) FindSaleItemDetails(ctx context.Context, saleID uuid.UUID) ([]model.SaleItemDetail, error) {
+	query := `
+		SELECT si.id, si.sale_id, si.product_id, si.quantity, si.unit_price,
+		       si.total_price, si.notes, si.created_at, si.updated_at, p.name as product_name,
+		       c.id as category_id, c.name as category_name
+		FROM sale_items si
+		JOIN products p ON si.product_id = p.id
+		JOIN categories c ON p.category_id = c.id
+		WHERE si.sale_id = $1
+		ORDER BY si.created_at
+	`
+
+	rows, err := sr.db.Query(ctx, query, saleID)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query sale item details", zap.Error(err))
+		return nil, fmt.Errorf("query sale items failed: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]model.SaleItemDetail, 0)
+	for rows.Next() {
+		var item model.SaleItemDetail
+		err := rows.Scan(
+			&item.ID, &item.SaleID, &item.ProductID, &item.Quantity,
+			&item.UnitPrice, &item.TotalPrice, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
+			&item.ProductName, &item.CategoryID, &item.CategoryName,
+		)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan sale item detail", zap.Error(err))
 			return nil, fmt.Errorf("scan sale item failed: %w", err)
 		}
 		items = append(items, item)
 	}
 
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
 	return items, nil
 }
 
-// FindAllSales retrieves sales with optional user filter and pagination
-func (sr *saleRepo) FindAllSales(ctx context.Context, userID *uuid.UUID, limit, offset int) ([]model.Sale, error) {
-	var query string
+// StreamSalesWithItems semua sale (semua status) + item + produk + kasir dalam satu query,
+// di-stream per baris item supaya export besar tidak di-load ke memory.
+// Urut created_at, id sale lalu item, jadi item satu sale selalu berurutan.
+// Pointer sale sama untuk semua item dari sale yang sama
+func (sr *saleRepo) StreamSalesWithItems(ctx context.Context, startDate, endDate time.Time, fn func(s *model.SaleWithCashier, item model.SaleItemDetail) error) error {
+	query := `
+		SELECT s.id, s.invoice_number, s.user_id, s.total_amount, s.status, s.payment_method, s.customer_name, s.customer_phone,
+		       s.payment_status, s.amount_paid, s.cancelled_reason, s.cancelled_at, s.created_at, s.updated_at,
+		       COALESCE(u.username, ''), COALESCE(u.full_name, ''),
+		       si.id, si.product_id, si.quantity, si.unit_price, si.total_price, si.notes, si.created_at, si.updated_at,
+		       p.name, c.id, c.name
+		FROM sales s
+		JOIN sale_items si ON si.sale_id = s.id
+		JOIN products p ON si.product_id = p.id
+		JOIN categories c ON p.category_id = c.id
+		LEFT JOIN users u ON u.id = s.user_id
+		WHERE s.deleted_at IS NULL
+			AND s.created_at >= $1
+			AND s.created_at < $2::timestamp + INTERVAL '1 day'
+		ORDER BY s.created_at, s.id, si.created_at, si.id
+	`
+
+	rows, err := sr.db.Query(ctx, query, startDate, endDate)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query sales export", zap.Error(err))
+		return fmt.Errorf("query sales export failed: %w", err)
+	}
+	defer rows.Close()
+
+	var current *model.SaleWithCashier
+	for rows.Next() {
+		var row model.SaleWithCashier
+		var item model.SaleItemDetail
+		err := rows.Scan(
+			&row.ID, &row.InvoiceNumber, &row.UserID, &row.TotalAmount,
+			&row.Status, &row.PaymentMethod, &row.CustomerName, &row.CustomerPhone,
+			&row.PaymentStatus, &row.AmountPaid,
+			&row.CancelledReason, &row.CancelledAt, &row.CreatedAt, &row.UpdatedAt,
+			&row.CashierUsername, &row.CashierFullName,
+			&item.ID, &item.ProductID, &item.Quantity, &item.UnitPrice, &item.TotalPrice,
+			&item.Notes, &item.CreatedAt, &item.UpdatedAt,
+			&item.ProductName, &item.CategoryID, &item.CategoryName,
+		)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan sales export row", zap.Error(err))
+			return fmt.Errorf("scan sales export failed: %w", err)
+		}
+		if current == nil || current.ID != row.ID {
+			current = &row
+		}
+		item.SaleID = current.ID
+
+		if err := fn(current, item); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return nil
+}
+
+// SaleListFilter filter opsional untuk list sales (nil = tidak difilter)
+type SaleListFilter struct {
+	UserID    *uuid.UUID
+	Status    *model.SaleStatus
+	StartDate *time.Time // inclusive
+	EndDate   *time.Time // inclusive (sampai akhir hari)
+	MinAmount *float64
+	MaxAmount *float64
+}
+
+// where builds WHERE clause, placeholder mulai dari $1
+func (f SaleListFilter) where() (string, []interface{}) {
+	where := "deleted_at IS NULL"
 	var args []interface{}
 
-	// Build query based on user filter
-	if userID != nil {
-		// Filter by specific user
-		query = `
-			SELECT id, invoice_number, user_id, total_amount, status, created_at, updated_at, deleted_at
-			FROM sales WHERE user_id = $1 AND deleted_at IS NULL
-			ORDER BY created_at DESC LIMIT $2 OFFSET $3
-		`
-		args = []interface{}{*userID, limit, offset}
-	} else {
-		// Get all sales
-		query = `
-			SELECT id, invoice_number, user_id, total_amount, status, created_at, updated_at, deleted_at
-			FROM sales WHERE deleted_at IS NULL
-			ORDER BY created_at DESC LIMIT $1 OFFSET $2
-		`
-		args = []interface{}{limit, offset}
+	if f.UserID != nil {
+		args = append(args, *f.UserID)
+		where += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if f.Status != nil {
+		args = append(args, *f.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if f.StartDate != nil {
+		args = append(args, *f.StartDate)
+		where += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if f.EndDate != nil {
+		args = append(args, f.EndDate.AddDate(0, 0, 1))
+		where += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	if f.MinAmount != nil {
+		args = append(args, *f.MinAmount)
+		where += fmt.Sprintf(" AND total_amount >= $%d", len(args))
+	}
+	if f.MaxAmount != nil {
+		args = append(args, *f.MaxAmount)
+		where += fmt.Sprintf(" AND total_amount <= $%d", len(args))
 	}
 
+	return where, args
+}
+
+// FindAllSales retrieves sales with optional filters and pagination
+func (sr *saleRepo) FindAllSales(ctx context.Context, filter SaleListFilter, limit, offset int) ([]model.Sale, error) {
+	where, args := filter.where()
+	args = append(args, limit, offset)
+
+	query := fmt.Sprintf(`
+		SELECT id, invoice_number, user_id, total_amount, status, payment_method, customer_name, customer_phone, payment_status, amount_paid, cancelled_reason, cancelled_at, created_at, updated_at, deleted_at
+		FROM sales WHERE %s
+		ORDER BY created_at DESC LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args))
+
 	rows, err := sr.db.Query(ctx, query, args...)
 	if err != nil {
-		sr.log.Error("Failed to query sales", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to query sales", zap.Error(err))
 		return nil, fmt.Errorf("query sales failed: %w", err)
 	}
 	defer rows.Close()
@@ -238,56 +531,589 @@ func (sr *saleRepo) FindAllSales(ctx context.Context, userID *uuid.UUID, limit,
 		var sale model.Sale
 		err := rows.Scan(
 			&sale.ID, &sale.InvoiceNumber, &sale.UserID, &sale.TotalAmount,
-			&sale.Status, &sale.CreatedAt, &sale.UpdatedAt, &sale.DeletedAt,
+			&sale.Status, &sale.PaymentMethod, &sale.CustomerName, &sale.CustomerPhone, &sale.PaymentStatus, &sale.AmountPaid, &sale.CancelledReason, &sale.CancelledAt,
+			&sale.CreatedAt, &sale.UpdatedAt, &sale.DeletedAt,
 		)
 		if err != nil {
-			sr.log.Error("Failed to scan sale", zap.Error(err))
+			utils.LoggerFromContext(ctx).Error("Failed to scan sale", zap.Error(err))
 			return nil, fmt.Errorf("scan sale failed: %w", err)
 		}
 		sales = append(sales, sale)
 	}
 
-	sr.log.Info("Fetched sales", zap.Int("count", len(sales)))
+	utils.LoggerFromContext(ctx).Info("Fetched sales", zap.Int("count", len(sales)))
 	return sales, nil
 }
 
-// CountAllSales counts total sales with optional user filter
-func (sr *saleRepo) CountAllSales(ctx context.Context, userID *uuid.UUID) (int, error) {
-	var query string
-	var args []interface{}
-
-	if userID != nil {
-		query = `SELECT COUNT(*) FROM sales WHERE user_id = $1 AND deleted_at IS NULL`
-		args = []interface{}{*userID}
-	} else {
-		query = `SELECT COUNT(*) FROM sales WHERE deleted_at IS NULL`
-		args = []interface{}{}
-	}
+// CountAllSales counts total sales with the same filters as FindAllSales
+func (sr *saleRepo) CountAllSales(ctx context.Context, filter SaleListFilter) (int, error) {
+	where, args := filter.where()
+	query := `SELECT COUNT(*) FROM sales WHERE ` + where
 
 	var count int
 	err := sr.db.QueryRow(ctx, query, args...).Scan(&count)
 	if err != nil {
-		sr.log.Error("Failed to count sales", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to count sales", zap.Error(err))
 		return 0, fmt.Errorf("count sales failed: %w", err)
 	}
 
 	return count, nil
 }
 
+// saleLinesFilter builds WHERE clause for sale line queries (sale_items si JOIN sales s)
+// productID nil = semua produk, endDate inclusive (sampai akhir hari tersebut)
+func saleLinesFilter(productID *uuid.UUID, startDate, endDate *time.Time, includeCancelled bool) (string, []interface{}) {
+	where := "s.deleted_at IS NULL"
+	args := []interface{}{}
+
+	if productID != nil {
+		args = append(args, *productID)
+		where += fmt.Sprintf(" AND si.product_id = $%d", len(args))
+	}
+	if !includeCancelled {
+		args = append(args, model.SaleStatusCancelled)
+		where += fmt.Sprintf(" AND s.status <> $%d", len(args))
+	}
+	if startDate != nil {
+		args = append(args, *startDate)
+		where += fmt.Sprintf(" AND s.created_at >= $%d", len(args))
+	}
+	if endDate != nil {
+		args = append(args, endDate.AddDate(0, 0, 1))
+		where += fmt.Sprintf(" AND s.created_at < $%d", len(args))
+	}
+
+	return where, args
+}
+
+// FindSalesByProduct retrieves sale lines of a product, newest first
+func (sr *saleRepo) FindSalesByProduct(ctx context.Context, productID uuid.UUID, startDate, endDate *time.Time, includeCancelled bool, limit, offset int) ([]model.ProductSaleHistory, error) {
+	where, args := saleLinesFilter(&productID, startDate, endDate, includeCancelled)
+	args = append(args, limit, offset)
+
+	query := fmt.Sprintf(`
+		SELECT s.id, s.invoice_number, s.status, si.quantity, si.unit_price, si.total_price, s.created_at
+		FROM sale_items si
+		JOIN sales s ON si.sale_id = s.id
+		WHERE %s
+		ORDER BY s.created_at DESC LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args))
+
+	rows, err := sr.db.Query(ctx, query, args...)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query product sales", zap.Error(err))
+		return nil, fmt.Errorf("query product sales failed: %w", err)
+	}
+	defer rows.Close()
+
+	var history []model.ProductSaleHistory
+	for rows.Next() {
+		var h model.ProductSaleHistory
+		err := rows.Scan(
+			&h.SaleID, &h.InvoiceNumber, &h.Status, &h.Quantity,
+			&h.UnitPrice, &h.TotalPrice, &h.SoldAt,
+		)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan product sale", zap.Error(err))
+			return nil, fmt.Errorf("scan product sale failed: %w", err)
+		}
+		history = append(history, h)
+	}
+
+	return history, nil
+}
+
+// CountSalesByProduct counts sale lines of a product with the same filter
+func (sr *saleRepo) CountSalesByProduct(ctx context.Context, productID uuid.UUID, startDate, endDate *time.Time, includeCancelled bool) (int, error) {
+	where, args := saleLinesFilter(&productID, startDate, endDate, includeCancelled)
+
+	query := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM sale_items si
+		JOIN sales s ON si.sale_id = s.id
+		WHERE %s
+	`, where)
+
+	var count int
+	if err := sr.db.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to count product sales", zap.Error(err))
+		return 0, fmt.Errorf("count product sales failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// FindAllSaleItems retrieves sale lines across all sales, newest first
+// Produk yang sudah di-soft delete tetap tampil namanya (histori)
+func (sr *saleRepo) FindAllSaleItems(ctx context.Context, startDate, endDate *time.Time, includeCancelled bool, limit, offset int) ([]model.SaleItemLedgerEntry, error) {
+	where, args := saleLinesFilter(nil, startDate, endDate, includeCancelled)
+	args = append(args, limit, offset)
+
+	query := fmt.Sprintf(`
+		SELECT si.id, s.id, s.invoice_number, s.status, si.product_id, p.name,
+			si.quantity, si.unit_price, si.total_price, s.created_at
+		FROM sale_items si
+		JOIN sales s ON si.sale_id = s.id
+		JOIN products p ON si.product_id = p.id
+		WHERE %s
+		ORDER BY s.created_at DESC, s.invoice_number, si.id LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args))
+
+	rows, err := sr.db.Query(ctx, query, args...)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query sale items", zap.Error(err))
+		return nil, fmt.Errorf("query sale items failed: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]model.SaleItemLedgerEntry, 0, limit)
+	for rows.Next() {
+		var e model.SaleItemLedgerEntry
+		err := rows.Scan(
+			&e.ID, &e.SaleID, &e.InvoiceNumber, &e.Status, &e.ProductID, &e.ProductName,
+			&e.Quantity, &e.UnitPrice, &e.TotalPrice, &e.SoldAt,
+		)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan sale item", zap.Error(err))
+			return nil, fmt.Errorf("scan sale item failed: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return entries, nil
+}
+
+// CountAllSaleItems counts sale lines across all sales with the same filter
+func (sr *saleRepo) CountAllSaleItems(ctx context.Context, startDate, endDate *time.Time, includeCancelled bool) (int, error) {
+	where, args := saleLinesFilter(nil, startDate, endDate, includeCancelled)
+
+	query := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM sale_items si
+		JOIN sales s ON si.sale_id = s.id
+		WHERE %s
+	`, where)
+
+	var count int
+	if err := sr.db.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to count sale items", zap.Error(err))
+		return 0, fmt.Errorf("count sale items failed: %w", err)
+	}
+
+	return count, nil
+}
+
 // UpdateSaleStatus changes sale status
-func (sr *saleRepo) UpdateSaleStatus(ctx context.Context, id uuid.UUID, status model.SaleStatus) error {
-	query := `UPDATE sales SET status = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL`
+// completed_at diisi saat transisi ke completed (status lama bukan completed)
+// Saat cancelled: simpan alasan & waktu, status lain: kosongkan keduanya
+// Sale yang sudah cancelled tidak bisa diubah lagi, stoknya sudah dikembalikan dan tidak dipotong ulang
+func (sr *saleRepo) UpdateSaleStatus(ctx context.Context, id uuid.UUID, status model.SaleStatus, reason *string) error {
+	tx, err := sr.db.Begin(ctx)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to begin transaction", zap.Error(err))
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	// Rollback no-op jika sudah commit
+	defer tx.Rollback(ctx)
 
-	result, err := sr.db.Exec(ctx, query, status, time.Now(), id)
+	// Lock sale supaya tidak balapan dengan CancelSale
+	var previous model.SaleStatus
+	err = tx.QueryRow(ctx,
+		`SELECT status FROM sales WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id,
+	).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("sale not found")
+	}
 	if err != nil {
-		sr.log.Error("Failed to update sale status", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to lock sale", zap.Error(err))
+		return fmt.Errorf("lock sale failed: %w", err)
+	}
+
+	if previous == model.SaleStatusCancelled && status != model.SaleStatusCancelled {
+		return fmt.Errorf("cannot change status of a cancelled sale")
+	}
+
+	query := `
+		UPDATE sales SET status = $1, cancelled_reason = $2, cancelled_at = $3, updated_at = $4,
+			completed_at = COALESCE($5, completed_at)
+		WHERE id = $6
+	`
+
+	now := time.Now()
+	var cancelledAt *time.Time
+	if status == model.SaleStatusCancelled {
+		cancelledAt = &now
+	} else {
+		reason = nil
+	}
+
+	// nil = completed_at lama dipertahankan
+	var completedAt *time.Time
+	if status == model.SaleStatusCompleted && previous != model.SaleStatusCompleted {
+		completedAt = &now
+	}
+
+	if _, err := tx.Exec(ctx, query, status, reason, cancelledAt, now, completedAt, id); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to update sale status", zap.Error(err))
 		return fmt.Errorf("update sale status failed: %w", err)
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to commit sale status", zap.Error(err))
+		return fmt.Errorf("commit sale status failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Sale status updated", zap.String("status", string(status)))
+	return nil
+}
+
+// UpdateSaleUser ganti kasir (user_id) sale, koreksi admin
+func (sr *saleRepo) UpdateSaleUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	query := `
+		UPDATE sales SET user_id = $1, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+
+	result, err := sr.db.Exec(ctx, query, userID, time.Now(), id)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to update sale user", zap.Error(err))
+		return fmt.Errorf("update sale user failed: %w", err)
+	}
+
 	if result.RowsAffected() == 0 {
 		return fmt.Errorf("sale not found")
 	}
 
-	sr.log.Info("Sale status updated", zap.String("status", string(status)))
 	return nil
 }
+
+// UpdatePayment set amount_paid & payment_status
+// Cek amount_paid <= total_amount di service, kondisi di query hanya pengaman (total bisa berubah lewat recalculate)
+// Total dibandingkan setelah dibulatkan 2 desimal, sama dengan pembulatan di service
+func (sr *saleRepo) UpdatePayment(ctx context.Context, id uuid.UUID, amountPaid float64, status model.PaymentStatus) error {
+	query := `
+		UPDATE sales SET amount_paid = $1, payment_status = $2, updated_at = $3
+		WHERE id = $4 AND deleted_at IS NULL AND $1 <= ROUND(total_amount, 2)
+	`
+
+	result, err := sr.db.Exec(ctx, query, amountPaid, status, time.Now(), id)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to update sale payment", zap.Error(err))
+		return fmt.Errorf("update sale payment failed: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		// Bedakan sale tidak ada dengan pembayaran yang melebihi total
+		var total float64
+		err := sr.db.QueryRow(ctx,
+			`SELECT ROUND(total_amount, 2) FROM sales WHERE id = $1 AND deleted_at IS NULL`, id,
+		).Scan(&total)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("sale not found")
+		}
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to check sale total", zap.Error(err))
+			return fmt.Errorf("check sale total failed: %w", err)
+		}
+		return fmt.Errorf("amount paid cannot exceed total amount (%.2f)", total)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Sale payment updated",
+		zap.String("id", id.String()),
+		zap.String("payment_status", string(status)))
+	return nil
+}
+
+// CancelSale set status cancelled, stok yang dipotong sale (pending maupun completed) dikembalikan
+// Status, stok (termasuk stok per lokasi) & ledger cancellation_restore dalam satu transaction
+// Return status sebelum dibatalkan
+func (sr *saleRepo) CancelSale(ctx context.Context, id uuid.UUID, reason string) (model.SaleStatus, error) {
+	tx, err := sr.db.Begin(ctx)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to begin transaction", zap.Error(err))
+		return "", fmt.Errorf("begin transaction failed: %w", err)
+	}
+	// Rollback no-op jika sudah commit
+	defer tx.Rollback(ctx)
+
+	// Lock sale supaya dua cancel bersamaan tidak restore stok dua kali
+	var previous model.SaleStatus
+	err = tx.QueryRow(ctx,
+		`SELECT status FROM sales WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id,
+	).Scan(&previous)
+	if err != nil {
+		return "", fmt.Errorf("sale not found")
+	}
+
+	// Sudah cancelled: no-op, alasan & waktu cancel pertama tidak ditimpa dan stok tidak di-restore lagi
+	if previous == model.SaleStatusCancelled {
+		return previous, nil
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(ctx, `
+		UPDATE sales SET status = $1, cancelled_reason = $2, cancelled_at = $3, updated_at = $3
+		WHERE id = $4
+	`, model.SaleStatusCancelled, reason, now, id)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to cancel sale", zap.Error(err))
+		return "", fmt.Errorf("update sale status failed: %w", err)
+	}
+
+	// Sale dipotong stoknya saat dibuat, jadi semua status selain cancelled perlu restore
+	if err := restoreSaleStock(ctx, tx, id, reason); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to restore stock, cancellation rolled back",
+			zap.Error(err),
+			zap.String("sale_id", id.String()))
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to commit sale cancellation", zap.Error(err))
+		return "", fmt.Errorf("commit sale cancellation failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Sale cancelled",
+		zap.String("sale_id", id.String()),
+		zap.String("previous_status", string(previous)))
+	return previous, nil
+}
+
+// restoreSaleStock kembalikan stok yang benar-benar dipotong sale di dalam transaction pemanggil
+// Jumlah diambil dari ledger (sale + cancellation_restore per produk), bukan dari sale_items,
+// karena potongan bisa lebih kecil dari quantity item (stok di-clamp ke 0 / item gagal dipotong)
+// Sale lama tanpa movement sale di ledger fallback ke sale_items
+// Produk yang sudah di-soft delete dilewati (sama seperti sebelumnya)
+func restoreSaleStock(ctx context.Context, tx pgx.Tx, saleID uuid.UUID, reason string) error {
+	var hasLedger bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM stock_movements WHERE reference_id = $1 AND movement_type = $2)
+	`, saleID, model.StockMovementSale).Scan(&hasLedger)
+	if err != nil {
+		return fmt.Errorf("check sale movements failed: %w", err)
+	}
+
+	query := `
+		SELECT product_id, SUM(quantity) FROM sale_items
+		WHERE sale_id = $1
+		GROUP BY product_id
+	`
+	args := []interface{}{saleID}
+	if hasLedger {
+		query = `
+			SELECT product_id, -SUM(quantity) FROM stock_movements
+			WHERE reference_id = $1 AND movement_type IN ($2, $3)
+			GROUP BY product_id
+			HAVING SUM(quantity) < 0
+		`
+		args = append(args, model.StockMovementSale, model.StockMovementCancellationRestore)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("query sale items failed: %w", err)
+	}
+
+	// Kumpulkan dulu, rows harus ditutup sebelum Exec di transaction yang sama
+	quantities := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var productID uuid.UUID
+		var quantity int
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan sale item failed: %w", err)
+		}
+		quantities[productID] = quantity
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	for productID, quantity := range quantities {
+		var stockAfter int
+		var shelfID uuid.UUID
+		var hasLocations bool
+		err := tx.QueryRow(ctx, `
+			UPDATE products SET stock_quantity = stock_quantity + $2, updated_at = $3
+			WHERE id = $1 AND deleted_at IS NULL
+			RETURNING stock_quantity, shelf_id,
+				EXISTS (SELECT 1 FROM product_stock_locations WHERE product_id = products.id)
+		`, productID, quantity, time.Now()).Scan(&stockAfter, &shelfID, &hasLocations)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("restore product stock failed: %w", err)
+		}
+
+		if hasLocations {
+			if err := applyLocationDelta(ctx, tx, productID, shelfID, quantity); err != nil {
+				return err
+			}
+		}
+
+		notes := reason
+		err = insertStockMovement(ctx, tx, &model.StockMovement{
+			ProductID:    productID,
+			MovementType: model.StockMovementCancellationRestore,
+			Quantity:     quantity,
+			StockAfter:   stockAfter,
+			ReferenceID:  &saleID,
+			Notes:        &notes,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recalculatedPaymentStatus CASE yang sama dengan paymentStatusFor di service (urutan cabang penting):
+// amount_paid >= total -> paid, amount_paid <= 0 -> unpaid, selain itu partial
+// amount_paid tidak diubah, kelebihan bayar setelah koreksi tetap tercatat sebagai paid
+const recalculatedPaymentStatus = `
+	CASE
+		WHEN s.amount_paid >= t.item_total THEN 'paid'
+		WHEN s.amount_paid <= 0 THEN 'unpaid'
+		ELSE 'partial'
+	END`
+
+// RecalculateSaleTotal set total_amount = SUM(sale_items.total_price) & hitung ulang payment_status
+// Sale cancelled dilewati: total-nya arsip saat pembatalan dan stoknya sudah dikembalikan
+// Return true jika total sebelumnya berbeda (row ter-update)
+func (sr *saleRepo) RecalculateSaleTotal(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE sales s SET total_amount = t.item_total,
+			payment_status = ` + recalculatedPaymentStatus + `,
+			updated_at = $2
+		FROM (
+			SELECT COALESCE(SUM(total_price), 0) AS item_total
+			FROM sale_items WHERE sale_id = $1
+		) t
+		WHERE s.id = $1 AND s.deleted_at IS NULL AND s.status <> 'cancelled'
+			AND s.total_amount <> t.item_total
+	`
+
+	result, err := sr.db.Exec(ctx, query, id, time.Now())
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to recalculate sale total",
+			zap.Error(err),
+			zap.String("sale_id", id.String()),
+		)
+		return false, fmt.Errorf("recalculate sale total failed: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// RecalculateAllSaleTotals perbaiki semua sale yang total-nya tidak sama dengan jumlah item
+// payment_status ikut dihitung ulang, sale cancelled dilewati (lihat RecalculateSaleTotal)
+// Satu statement UPDATE = atomic (all or nothing)
+func (sr *saleRepo) RecalculateAllSaleTotals(ctx context.Context) (int, error) {
+	query := `
+		UPDATE sales s SET total_amount = t.item_total,
+			payment_status = ` + recalculatedPaymentStatus + `,
+			updated_at = $1
+		FROM (
+			SELECT s2.id, COALESCE(SUM(si.total_price), 0) AS item_total
+			FROM sales s2
+			LEFT JOIN sale_items si ON si.sale_id = s2.id
+			WHERE s2.deleted_at IS NULL AND s2.status <> 'cancelled'
+			GROUP BY s2.id
+		) t
+		WHERE s.id = t.id AND s.total_amount <> t.item_total
+	`
+
+	result, err := sr.db.Exec(ctx, query, time.Now())
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to recalculate all sale totals", zap.Error(err))
+		return 0, fmt.Errorf("recalculate sale totals failed: %w", err)
+	}
+
+	changed := int(result.RowsAffected())
+	utils.LoggerFromContext(ctx).Info("Sale totals recalculated", zap.Int("changed", changed))
+	return changed, nil
+}
+
+// GetUserDailySales jumlah sale, item terjual & revenue per hari untuk satu kasir, endDate inclusive
+// Hanya hari yang ada penjualannya, hari kosong diisi 0 di service
+func (sr *saleRepo) GetUserDailySales(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]model.DailySales, error) {
+	query := `
+		SELECT
+			date_trunc('day', s.created_at) as day,
+			COUNT(s.id) as sales_count,
+			COALESCE(SUM((SELECT COALESCE(SUM(si.quantity), 0) FROM sale_items si WHERE si.sale_id = s.id)), 0) as items_sold,
+			COALESCE(SUM(s.total_amount), 0) as revenue
+		FROM sales s
+		WHERE s.user_id = $1
+			AND s.deleted_at IS NULL
+			AND s.status = 'completed'
+			AND s.created_at >= $2
+			AND s.created_at < $3::timestamp + INTERVAL '1 day'
+		GROUP BY day
+		ORDER BY day ASC
+	`
+
+	rows, err := sr.db.Query(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query user daily sales", zap.Error(err))
+		return nil, fmt.Errorf("query user daily sales failed: %w", err)
+	}
+	defer rows.Close()
+
+	days := make([]model.DailySales, 0)
+	for rows.Next() {
+		var day model.DailySales
+		if err := rows.Scan(&day.Date, &day.SalesCount, &day.ItemsSold, &day.Revenue); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan user daily sales", zap.Error(err))
+			return nil, fmt.Errorf("scan user daily sales failed: %w", err)
+		}
+		days = append(days, day)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return days, nil
+}
+
+// CountByStatus jumlah sale per status, endDate inclusive (sampai akhir hari tersebut)
+// Hanya status yang punya sale di periode tersebut yang dikembalikan
+func (sr *saleRepo) CountByStatus(ctx context.Context, startDate, endDate time.Time) ([]model.SaleStatusCount, error) {
+	query := `
+		SELECT status, COUNT(*)
+		FROM sales
+		WHERE deleted_at IS NULL AND created_at >= $1 AND created_at < $2
+		GROUP BY status
+	`
+
+	rows, err := sr.db.Query(ctx, query, startDate, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to count sales by status", zap.Error(err))
+		return nil, fmt.Errorf("count sales by status failed: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]model.SaleStatusCount, 0)
+	for rows.Next() {
+		var count model.SaleStatusCount
+		if err := rows.Scan(&count.Status, &count.Count); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan sale status count", zap.Error(err))
+			return nil, fmt.Errorf("scan sale status count failed: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,781 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/model"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== CANCEL SALE ==========
+
+// cancelState snapshot data yang disentuh CancelSale, staged baru menjadi committed saat Commit
+type cancelState struct {
+	status    string
+	reason    *string
+	stock     map[uuid.UUID]int
+	movements int
+}
+
+func (s cancelState) clone() cancelState {
+	stock := make(map[uuid.UUID]int, len(s.stock))
+	for id, qty := range s.stock {
+		stock[id] = qty
+	}
+	s.stock = stock
+	return s
+}
+
+// newCancelDB menyiapkan sale dengan dua produk yang masing-masing terjual 3 & 2 (tercatat di ledger)
+// failOnUpdate > 0 membuat UPDATE products ke-n gagal
+func newCancelDB(t *testing.T, status string, failOnUpdate int) (*fakeDB, *cancelState, *cancelState) {
+	productA, productB := uuid.New(), uuid.New()
+	committed := &cancelState{status: status, stock: map[uuid.UUID]int{productA: 7, productB: 8}, movements: 2}
+	staged := &cancelState{}
+	*staged = committed.clone()
+
+	db := newFakeDB(t)
+	db.onCommit = func() { *committed = staged.clone() }
+
+	db.on("SELECT status FROM sales", func(args []any) ([][]any, error) {
+		return [][]any{{staged.status}}, nil
+	})
+	db.on("UPDATE sales SET status", func(args []any) ([][]any, error) {
+		reason := args[1].(string)
+		staged.status, staged.reason = string(args[0].(model.SaleStatus)), &reason
+		return [][]any{{}}, nil
+	})
+	db.on("SELECT EXISTS (SELECT 1 FROM stock_movements", func(args []any) ([][]any, error) {
+		return [][]any{{true}}, nil
+	})
+	db.on("FROM stock_movements WHERE reference_id", func(args []any) ([][]any, error) {
+		return [][]any{{productA, 3}, {productB, 2}}, nil
+	})
+	updates := 0
+	db.on("UPDATE products SET stock_quantity", func(args []any) ([][]any, error) {
+		updates++
+		if updates == failOnUpdate {
+			return nil, fmt.Errorf("connection reset")
+		}
+		id := args[0].(uuid.UUID)
+		staged.stock[id] += args[1].(int)
+		return [][]any{{staged.stock[id], uuid.New(), false}}, nil
+	})
+	db.on("INSERT INTO stock_movements", func(args []any) ([][]any, error) {
+		staged.movements++
+		return [][]any{{}}, nil
+	})
+
+	return db, committed, staged
+}
+
+func TestCancelSale(t *testing.T) {
+	db, committed, _ := newCancelDB(t, "completed", 0)
+	repo := NewSaleRepo(db, zap.NewNop())
+
+	previous, err := repo.CancelSale(context.Background(), uuid.New(), "customer batal")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if previous != model.SaleStatusCompleted {
+		t.Errorf("previous = %q, want completed", previous)
+	}
+	if db.commits != 1 {
+		t.Fatalf("commits = %d, want 1", db.commits)
+	}
+	if committed.status != "cancelled" || committed.reason == nil || *committed.reason != "customer batal" {
+		t.Errorf("sale = %q/%v, want cancelled with reason", committed.status, committed.reason)
+	}
+	total := 0
+	for _, qty := range committed.stock {
+		total += qty
+	}
+	if total != 20 {
+		t.Errorf("stock total = %d, want 20 (15 + 5 restored)", total)
+	}
+	if committed.movements != 4 {
+		t.Errorf("movements = %d, want 4 (one cancellation_restore per product)", committed.movements)
+	}
+}
+
+func TestCancelSaleRestoreFailureRollsBack(t *testing.T) {
+	db, committed, staged := newCancelDB(t, "completed", 2)
+	before := committed.clone()
+	repo := NewSaleRepo(db, zap.NewNop())
+
+	_, err := repo.CancelSale(context.Background(), uuid.New(), "customer batal")
+	if err == nil {
+		t.Fatal("expected error when second stock update fails")
+	}
+
+	// Pastikan kegagalan benar-benar terjadi di tengah restore: produk pertama sudah di-update di tx
+	if staged.movements != before.movements+1 || staged.status != "cancelled" {
+		t.Fatalf("staged = %+v, want partial restore before failure", staged)
+	}
+
+	if db.commits != 0 || db.rollbacks != 1 {
+		t.Errorf("commits/rollbacks = %d/%d, want 0/1", db.commits, db.rollbacks)
+	}
+	if committed.status != before.status || committed.reason != nil {
+		t.Errorf("status = %q, reason = %v, want unchanged", committed.status, committed.reason)
+	}
+	for id, qty := range before.stock {
+		if committed.stock[id] != qty {
+			t.Errorf("stock[%s] = %d, want %d", id, committed.stock[id], qty)
+		}
+	}
+	if committed.movements != before.movements {
+		t.Errorf("movements = %d, want %d", committed.movements, before.movements)
+	}
+}
+
+func TestCancelSaleAlreadyCancelled(t *testing.T) {
+	db, committed, _ := newCancelDB(t, "cancelled", 0)
+	original := "salah input"
+	committed.reason = &original
+	repo := NewSaleRepo(db, zap.NewNop())
+
+	previous, err := repo.CancelSale(context.Background(), uuid.New(), "alasan baru")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if previous != model.SaleStatusCancelled {
+		t.Errorf("previous = %q, want cancelled", previous)
+	}
+	if n := db.executed("UPDATE sales"); n != 0 {
+		t.Errorf("UPDATE sales executed %d times, want 0", n)
+	}
+	if n := db.executed("UPDATE products"); n != 0 {
+		t.Errorf("stock restored %d times, want 0", n)
+	}
+	if db.commits != 0 {
+		t.Errorf("commits = %d, want 0", db.commits)
+	}
+	if committed.reason == nil || *committed.reason != original {
+		t.Errorf("reason = %v, want %q kept", committed.reason, original)
+	}
+}
+
+func TestCancelSaleNotFound(t *testing.T) {
+	db := newFakeDB(t)
+	db.on("SELECT status FROM sales", func(args []any) ([][]any, error) {
+		return nil, nil
+	})
+	repo := NewSaleRepo(db, zap.NewNop())
+
+	_, err := repo.CancelSale(context.Background(), uuid.New(), "x")
+	if err == nil || err.Error() != "sale not found" {
+		t.Errorf("error = %v, want sale not found", err)
+	}
+}
+
+// ========== UPDATE SALE STATUS ==========
+
+func TestUpdateSaleStatusCompletedAt(t *testing.T) {
+	tests := []struct {
+		name          string
+		previous      model.SaleStatus
+		status        model.SaleStatus
+		wantCompleted bool
+		wantErr       string
+	}{
+		{name: "pending to completed", previous: model.SaleStatusPending, status: model.SaleStatusCompleted, wantCompleted: true},
+		{name: "completed again keeps timestamp", previous: model.SaleStatusCompleted, status: model.SaleStatusCompleted},
+		{name: "completed back to pending keeps timestamp", previous: model.SaleStatusCompleted, status: model.SaleStatusPending},
+		{name: "pending stays pending", previous: model.SaleStatusPending, status: model.SaleStatusPending},
+		{name: "pending to cancelled", previous: model.SaleStatusPending, status: model.SaleStatusCancelled},
+		{name: "cancelled cannot complete", previous: model.SaleStatusCancelled, status: model.SaleStatusCompleted, wantErr: "cannot change status of a cancelled sale"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var completedAt *time.Time
+			db := newFakeDB(t)
+			db.on("SELECT status FROM sales WHERE id = $1", func(args []any) ([][]any, error) {
+				return [][]any{{string(tt.previous)}}, nil
+			})
+			db.on("UPDATE sales SET status", func(args []any) ([][]any, error) {
+				completedAt = args[4].(*time.Time)
+				return [][]any{{}}, nil
+			})
+
+			before := time.Now()
+			err := NewSaleRepo(db, zap.NewNop()).UpdateSaleStatus(context.Background(), uuid.New(), tt.status, nil)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if db.executed("UPDATE sales") != 0 {
+					t.Error("sale updated despite error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// nil = COALESCE mempertahankan completed_at lama
+			if tt.wantCompleted {
+				if completedAt == nil || completedAt.Before(before) {
+					t.Errorf("completed_at = %v, want set to now", completedAt)
+				}
+			} else if completedAt != nil {
+				t.Errorf("completed_at = %v, want unchanged", completedAt)
+			}
+		})
+	}
+}
+
+// ========== UPDATE PAYMENT ==========
+
+func TestUpdatePayment(t *testing.T) {
+	existing := uuid.New()
+
+	tests := []struct {
+		name       string
+		id         uuid.UUID
+		total      float64
+		amountPaid float64
+		wantErr    string
+	}{
+		{name: "exact total", id: existing, total: 100, amountPaid: 100},
+		{name: "unrounded total rounds up", id: existing, total: 99.996, amountPaid: 100},
+		{name: "over total", id: existing, total: 100, amountPaid: 100.5, wantErr: "amount paid cannot exceed total amount (100.00)"},
+		{name: "missing sale", id: uuid.New(), total: 100, amountPaid: 10, wantErr: "sale not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paid *float64
+			round := func(v float64) float64 { return math.Round(v*100) / 100 }
+
+			// Emulasi tabel sales satu baris, guard total mengikuti bentuk SQL yang dipakai
+			db := newFakeDB(t)
+			db.on("UPDATE sales SET amount_paid = $1", func(args []any) ([][]any, error) {
+				query := db.calls[len(db.calls)-1].sql
+				total := tt.total
+				if strings.Contains(query, "$1 <= ROUND(total_amount, 2)") {
+					total = round(total)
+				}
+				amount := args[0].(float64)
+				if args[3] != existing || amount > total {
+					return nil, nil
+				}
+				paid = &amount
+				return [][]any{{}}, nil
+			})
+			db.on("SELECT ROUND(total_amount, 2) FROM sales", func(args []any) ([][]any, error) {
+				if args[0] != existing {
+					return nil, nil
+				}
+				return [][]any{{round(tt.total)}}, nil
+			})
+
+			err := NewSaleRepo(db, zap.NewNop()).UpdatePayment(context.Background(), tt.id, tt.amountPaid, model.PaymentStatusPaid)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if paid != nil {
+					t.Error("payment stored despite error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if paid == nil || *paid != tt.amountPaid {
+				t.Errorf("amount_paid = %v, want %v", paid, tt.amountPaid)
+			}
+		})
+	}
+}
+
+// ========== CREATE SALE ==========
+
+func TestCreateSaleWithItems(t *testing.T) {
+	single, multi := uuid.New(), uuid.New()
+	shelfA, shelfB := uuid.New(), uuid.New()
+	newInventory := func() *fakeInventory {
+		return &fakeInventory{products: map[uuid.UUID]*fakeStockProduct{
+			single: {stock: 10, shelfID: shelfA, locations: map[uuid.UUID]int{}},
+			multi:  {stock: 10, shelfID: shelfA, locations: map[uuid.UUID]int{shelfA: 4, shelfB: 6}},
+		}}
+	}
+	items := func(multiQty int) []model.SaleItem {
+		return []model.SaleItem{
+			{ProductID: single, Quantity: 3},
+			{ProductID: multi, Quantity: multiQty},
+		}
+	}
+	shelves := map[uuid.UUID]uuid.UUID{multi: shelfB}
+
+	t.Run("all items deducted in one transaction", func(t *testing.T) {
+		inventory := newInventory()
+		db, _ := newInventoryDB(t, inventory)
+		repo := NewSaleRepo(db, zap.NewNop())
+
+		sale := &model.Sale{InvoiceNumber: "INV-1"}
+		stockAfter, err := repo.CreateSaleWithItems(context.Background(), sale, items(5), shelves)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if db.begins != 1 || db.commits != 1 {
+			t.Errorf("begins/commits = %d/%d, want 1/1", db.begins, db.commits)
+		}
+		if stockAfter[single] != 7 || stockAfter[multi] != 5 {
+			t.Errorf("stock after = %v, want single 7, multi 5", stockAfter)
+		}
+		if p := inventory.products[multi]; p.locations[shelfB] != 1 || p.locations[shelfA] != 4 || p.shelfSum() != p.stock {
+			t.Errorf("multi location = %v (total %d), want shelf B deducted only", p.locations, p.stock)
+		}
+		if inventory.sales != 1 || inventory.saleItems != 2 {
+			t.Errorf("sales/items = %d/%d, want 1/2", inventory.sales, inventory.saleItems)
+		}
+		if len(inventory.movements) != 2 {
+			t.Fatalf("movements = %d, want 2", len(inventory.movements))
+		}
+		for _, m := range inventory.movements {
+			if m.movementType != model.StockMovementSale || m.referenceID == nil || *m.referenceID != sale.ID {
+				t.Errorf("movement = %+v, want sale movement referencing sale", m)
+			}
+		}
+	})
+
+	t.Run("failed deduction stores nothing", func(t *testing.T) {
+		inventory := newInventory()
+		before := inventory.clone()
+		db, staged := newInventoryDB(t, inventory)
+		repo := NewSaleRepo(db, zap.NewNop())
+
+		_, err := repo.CreateSaleWithItems(context.Background(), &model.Sale{InvoiceNumber: "INV-2"}, items(7), shelves)
+		if err == nil || err.Error() != "insufficient stock at location" {
+			t.Fatalf("error = %v, want insufficient stock at location", err)
+		}
+		// Produk pertama sudah dipotong di dalam tx sebelum produk kedua gagal
+		if staged().products[single].stock != 7 {
+			t.Fatalf("staged single stock = %d, want partial deduction 7", staged().products[single].stock)
+		}
+
+		if db.commits != 0 || db.rollbacks != 1 {
+			t.Errorf("commits/rollbacks = %d/%d, want 0/1", db.commits, db.rollbacks)
+		}
+		if inventory.sales != 0 || inventory.saleItems != 0 || len(inventory.movements) != 0 {
+			t.Errorf("sales/items/movements = %d/%d/%d, want none", inventory.sales, inventory.saleItems, len(inventory.movements))
+		}
+		for id, p := range before.products {
+			if inventory.products[id].stock != p.stock {
+				t.Errorf("stock[%s] = %d, want %d", id, inventory.products[id].stock, p.stock)
+			}
+		}
+	})
+}
+
+func TestSaleItemNotesRoundTrip(t *testing.T) {
+	saleID, productID := uuid.New(), uuid.New()
+
+	// Tabel sale_items diemulasikan: INSERT menyimpan baris, SELECT join products mengembalikannya
+	var stored [][]any
+	db := newFakeDB(t)
+	db.on("INSERT INTO sale_items", func(args []any) ([][]any, error) {
+		for i := 0; i+8 <= len(args); i += 8 {
+			row := args[i : i+8]
+			stored = append(stored, []any{row[0], row[1], row[2], row[3], row[4], row[5], row[6], row[7], row[7], "Coffee"})
+		}
+		return [][]any{{}}, nil
+	})
+	db.on("FROM sale_items si JOIN products p", func(args []any) ([][]any, error) {
+		var rows [][]any
+		for _, row := range stored {
+			if row[1] == args[0] {
+				rows = append(rows, row)
+			}
+		}
+		return rows, nil
+	})
+	repo := NewSaleRepo(db, zap.NewNop())
+
+	items := []model.SaleItem{
+		{SaleID: saleID, ProductID: productID, Quantity: 1, UnitPrice: 9000, TotalPrice: 9000, Notes: "damaged box, discounted"},
+		{SaleID: saleID, ProductID: productID, Quantity: 2, UnitPrice: 10000, TotalPrice: 20000},
+	}
+	if err := repo.CreateSaleItems(context.Background(), items); err != nil {
+		t.Fatalf("create items: %v", err)
+	}
+
+	got, err := repo.FindSaleItemsWithProduct(context.Background(), saleID)
+	if err != nil {
+		t.Fatalf("find items: %v", err)
+	}
+	if len(got) != len(items) {
+		t.Fatalf("items = %d, want %d", len(got), len(items))
+	}
+	for i, item := range got {
+		if item.Notes != items[i].Notes {
+			t.Errorf("item %d notes = %q, want %q", i, item.Notes, items[i].Notes)
+		}
+		if item.Quantity != items[i].Quantity || item.ProductName != "Coffee" {
+			t.Errorf("item %d = %+v, want quantity %d with product name", i, item, items[i].Quantity)
+		}
+	}
+}
+
+// ========== RECALCULATE TOTALS ==========
+
+type fakeSaleTotal struct {
+	status        string
+	total         float64
+	itemTotal     float64
+	amountPaid    float64
+	paymentStatus string
+}
+
+// applyRecalculate emulasi UPDATE recalculate: filter cancelled & payment_status hanya berlaku
+// jika SQL-nya memang memuat klausa tersebut
+func applyRecalculate(query string, sales map[uuid.UUID]*fakeSaleTotal, ids []uuid.UUID) int {
+	skipCancelled := strings.Contains(query, "status <> 'cancelled'")
+	derivesPayment := strings.Contains(query, "payment_status = CASE WHEN s.amount_paid >= t.item_total THEN 'paid' WHEN s.amount_paid <= 0 THEN 'unpaid' ELSE 'partial' END")
+
+	changed := 0
+	for _, id := range ids {
+		s := sales[id]
+		if s == nil || (skipCancelled && s.status == "cancelled") || s.total == s.itemTotal {
+			continue
+		}
+		s.total = s.itemTotal
+		if derivesPayment {
+			switch {
+			case s.amountPaid >= s.total:
+				s.paymentStatus = "paid"
+			case s.amountPaid <= 0:
+				s.paymentStatus = "unpaid"
+			default:
+				s.paymentStatus = "partial"
+			}
+		}
+		changed++
+	}
+	return changed
+}
+
+func TestRecalculateSaleTotals(t *testing.T) {
+	overcounted, underpaid, unpaid, consistent, cancelled := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	newSales := func() map[uuid.UUID]*fakeSaleTotal {
+		return map[uuid.UUID]*fakeSaleTotal{
+			// Total dirusak ke atas: sudah bayar penuh tapi tercatat partial
+			overcounted: {status: "completed", total: 999, itemTotal: 30, amountPaid: 30, paymentStatus: "partial"},
+			// Total dirusak ke bawah: tercatat paid padahal kurang bayar
+			underpaid:  {status: "completed", total: 20, itemTotal: 50, amountPaid: 20, paymentStatus: "paid"},
+			unpaid:     {status: "pending", total: 0, itemTotal: 40, amountPaid: 0, paymentStatus: "paid"},
+			consistent: {status: "completed", total: 10, itemTotal: 10, amountPaid: 10, paymentStatus: "paid"},
+			cancelled:  {status: "cancelled", total: 999, itemTotal: 10, amountPaid: 0, paymentStatus: "unpaid"},
+		}
+	}
+	newDB := func(sales map[uuid.UUID]*fakeSaleTotal) *fakeDB {
+		db := newFakeDB(t)
+		var query string
+		db.on("UPDATE sales s SET total_amount", func(args []any) ([][]any, error) {
+			query = db.calls[len(db.calls)-1].sql
+			ids := []uuid.UUID{overcounted, underpaid, unpaid, consistent, cancelled}
+			if id, ok := args[0].(uuid.UUID); ok {
+				ids = []uuid.UUID{id}
+			}
+			return make([][]any, applyRecalculate(query, sales, ids)), nil
+		})
+		return db
+	}
+	wantFixed := func(t *testing.T, sales map[uuid.UUID]*fakeSaleTotal, id uuid.UUID, total float64, paymentStatus string) {
+		t.Helper()
+		if s := sales[id]; s.total != total || s.paymentStatus != paymentStatus {
+			t.Errorf("sale = total %v / %s, want %v / %s", s.total, s.paymentStatus, total, paymentStatus)
+		}
+	}
+
+	t.Run("all sales", func(t *testing.T) {
+		sales := newSales()
+		repo := NewSaleRepo(newDB(sales), zap.NewNop())
+
+		changed, err := repo.RecalculateAllSaleTotals(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if changed != 3 {
+			t.Errorf("changed = %d, want 3", changed)
+		}
+		wantFixed(t, sales, overcounted, 30, "paid")
+		wantFixed(t, sales, underpaid, 50, "partial")
+		wantFixed(t, sales, unpaid, 40, "unpaid")
+		wantFixed(t, sales, consistent, 10, "paid")
+		wantFixed(t, sales, cancelled, 999, "unpaid")
+	})
+
+	t.Run("single sale", func(t *testing.T) {
+		sales := newSales()
+		repo := NewSaleRepo(newDB(sales), zap.NewNop())
+
+		changed, err := repo.RecalculateSaleTotal(context.Background(), underpaid)
+		if err != nil || !changed {
+			t.Fatalf("changed/err = %v/%v, want true/nil", changed, err)
+		}
+		wantFixed(t, sales, underpaid, 50, "partial")
+		wantFixed(t, sales, overcounted, 999, "partial")
+	})
+
+	t.Run("cancelled sale untouched", func(t *testing.T) {
+		sales := newSales()
+		repo := NewSaleRepo(newDB(sales), zap.NewNop())
+
+		changed, err := repo.RecalculateSaleTotal(context.Background(), cancelled)
+		if err != nil || changed {
+			t.Fatalf("changed/err = %v/%v, want false/nil", changed, err)
+		}
+		wantFixed(t, sales, cancelled, 999, "unpaid")
+	})
+}
+
+// ========== USER DAILY SALES ==========
+
+func TestGetUserDailySalesScopedToUser(t *testing.T) {
+	me, other := uuid.New(), uuid.New()
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	type fakeSale struct {
+		userID   uuid.UUID
+		day      time.Time
+		status   string
+		total    float64
+		quantity int
+	}
+	sales := []fakeSale{
+		{userID: me, day: day(2), status: "completed", total: 10, quantity: 1},
+		{userID: me, day: day(2), status: "completed", total: 30, quantity: 4},
+		{userID: me, day: day(4), status: "cancelled", total: 99, quantity: 9},
+		{userID: other, day: day(3), status: "completed", total: 500, quantity: 5},
+	}
+
+	db := newFakeDB(t)
+	db.on("FROM sales s WHERE s.user_id = $1", func(args []any) ([][]any, error) {
+		query := db.calls[len(db.calls)-1].sql
+		if !strings.Contains(query, "s.status = 'completed'") || !strings.Contains(query, "GROUP BY day") {
+			t.Errorf("query not grouped per day on completed sales: %s", query)
+		}
+		// Emulasi WHERE + GROUP BY: hanya hari yang ada penjualan kasir args[0]
+		var rows [][]any
+		index := map[time.Time]int{}
+		for _, s := range sales {
+			if s.userID != args[0] || s.status != "completed" {
+				continue
+			}
+			i, ok := index[s.day]
+			if !ok {
+				i = len(rows)
+				index[s.day] = i
+				rows = append(rows, []any{s.day, 0, 0, 0.0})
+			}
+			rows[i][1] = rows[i][1].(int) + 1
+			rows[i][2] = rows[i][2].(int) + s.quantity
+			rows[i][3] = rows[i][3].(float64) + s.total
+		}
+		return rows, nil
+	})
+
+	days, err := NewSaleRepo(db, zap.NewNop()).GetUserDailySales(context.Background(), me, day(1), day(5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(days) != 1 || !days[0].Date.Equal(day(2)) || days[0].SalesCount != 2 || days[0].ItemsSold != 5 || days[0].Revenue != 40 {
+		t.Errorf("days = %+v, want only 2024-03-02 with 2 sales / 5 items / 40", days)
+	}
+	if args := db.calls[0].args; args[0] != me {
+		t.Errorf("user arg = %v, want %v", args[0], me)
+	}
+}
+
+// ========== SALE ITEM LEDGER ==========
+
+// placeholderArg nilai arg untuk klausa "<clause> $N" di SQL, ok=false jika klausa tidak ada
+func placeholderArg(query, clause string, args []any) (any, bool) {
+	m := regexp.MustCompile(regexp.QuoteMeta(clause) + ` \$(\d+)`).FindStringSubmatch(query)
+	if m == nil {
+		return nil, false
+	}
+	n, _ := strconv.Atoi(m[1])
+	return args[n-1], true
+}
+
+// fakeLedgerLine satu sale_items JOIN sales, urut terbaru dulu
+type fakeLedgerLine struct {
+	invoice string
+	status  model.SaleStatus
+	soldAt  time.Time
+}
+
+// filterLedger emulasi WHERE (status & tanggal) + LIMIT/OFFSET dari SQL dan args yang dikirim repo
+func filterLedger(query string, args []any, lines []fakeLedgerLine) []fakeLedgerLine {
+	var matched []fakeLedgerLine
+	for _, l := range lines {
+		if v, ok := placeholderArg(query, "s.status <>", args); ok && l.status == v.(model.SaleStatus) {
+			continue
+		}
+		if v, ok := placeholderArg(query, "s.created_at >=", args); ok && l.soldAt.Before(v.(time.Time)) {
+			continue
+		}
+		if v, ok := placeholderArg(query, "s.created_at <", args); ok && !l.soldAt.Before(v.(time.Time)) {
+			continue
+		}
+		matched = append(matched, l)
+	}
+	if v, ok := placeholderArg(query, "OFFSET", args); ok {
+		matched = matched[min(v.(int), len(matched)):]
+	}
+	if v, ok := placeholderArg(query, "LIMIT", args); ok {
+		matched = matched[:min(v.(int), len(matched))]
+	}
+	return matched
+}
+
+func TestFindAllSaleItems(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2024, 3, d, hour, 0, 0, 0, time.UTC) }
+	lines := []fakeLedgerLine{
+		{invoice: "INV-6", status: model.SaleStatusCompleted, soldAt: day(6, 9)},
+		{invoice: "INV-5", status: model.SaleStatusCompleted, soldAt: day(5, 23)},
+		{invoice: "INV-4", status: model.SaleStatusCancelled, soldAt: day(4, 12)},
+		{invoice: "INV-3", status: model.SaleStatusPending, soldAt: day(3, 12)},
+		{invoice: "INV-2", status: model.SaleStatusCompleted, soldAt: day(2, 12)},
+		{invoice: "INV-1", status: model.SaleStatusCompleted, soldAt: day(1, 0)},
+	}
+
+	db := newFakeDB(t)
+	db.on("JOIN products p ON si.product_id = p.id", func(args []any) ([][]any, error) {
+		var rows [][]any
+		for _, l := range filterLedger(db.calls[len(db.calls)-1].sql, args, lines) {
+			rows = append(rows, []any{uuid.New(), uuid.New(), l.invoice, string(l.status), uuid.New(), "Coffee", 2, 10.0, 20.0, l.soldAt})
+		}
+		return rows, nil
+	})
+	db.on("SELECT COUNT(*) FROM sale_items si", func(args []any) ([][]any, error) {
+		return [][]any{{len(filterLedger(db.calls[len(db.calls)-1].sql, args, lines))}}, nil
+	})
+	repo := NewSaleRepo(db, zap.NewNop())
+
+	invoices := func(entries []model.SaleItemLedgerEntry) string {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.InvoiceNumber)
+		}
+		return strings.Join(names, ",")
+	}
+	start, end := day(2, 0), day(5, 0)
+
+	tests := []struct {
+		name             string
+		start, end       *time.Time
+		includeCancelled bool
+		limit, offset    int
+		want             string
+		wantCount        int
+	}{
+		{name: "first page without filter", limit: 2, want: "INV-6,INV-5", wantCount: 5},
+		{name: "second page", limit: 2, offset: 2, want: "INV-3,INV-2", wantCount: 5},
+		{name: "last partial page", limit: 2, offset: 4, want: "INV-1", wantCount: 5},
+		{name: "end date inclusive", start: &start, end: &end, limit: 10, want: "INV-5,INV-3,INV-2", wantCount: 3},
+		{name: "include cancelled", start: &start, end: &end, includeCancelled: true, limit: 10, want: "INV-5,INV-4,INV-3,INV-2", wantCount: 4},
+		{name: "start only", start: &end, limit: 10, want: "INV-6,INV-5", wantCount: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := repo.FindAllSaleItems(context.Background(), tt.start, tt.end, tt.includeCancelled, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := invoices(entries); got != tt.want {
+				t.Errorf("invoices = %s, want %s", got, tt.want)
+			}
+
+			count, err := repo.CountAllSaleItems(context.Background(), tt.start, tt.end, tt.includeCancelled)
+			if err != nil || count != tt.wantCount {
+				t.Errorf("count/err = %d/%v, want %d", count, err, tt.wantCount)
+			}
+		})
+	}
+}
+
+// ========== COUNT SALES ==========
+
+func TestCountAllSalesFiltered(t *testing.T) {
+	cashier, other := uuid.New(), uuid.New()
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC) }
+	type fakeSale struct {
+		userID uuid.UUID
+		status model.SaleStatus
+		total  float64
+		at     time.Time
+	}
+	sales := []fakeSale{
+		{userID: cashier, status: model.SaleStatusCompleted, total: 10, at: day(1)},
+		{userID: cashier, status: model.SaleStatusCompleted, total: 50, at: day(3)},
+		{userID: cashier, status: model.SaleStatusPending, total: 20, at: day(3)},
+		{userID: cashier, status: model.SaleStatusCancelled, total: 30, at: day(5)},
+		{userID: other, status: model.SaleStatusCompleted, total: 90, at: day(3)},
+	}
+
+	db := newFakeDB(t)
+	db.on("SELECT COUNT(*) FROM sales WHERE", func(args []any) ([][]any, error) {
+		query := db.calls[len(db.calls)-1].sql
+		count := 0
+		for _, s := range sales {
+			if v, ok := placeholderArg(query, "user_id =", args); ok && s.userID != v.(uuid.UUID) {
+				continue
+			}
+			if v, ok := placeholderArg(query, "status =", args); ok && s.status != v.(model.SaleStatus) {
+				continue
+			}
+			if v, ok := placeholderArg(query, "created_at >=", args); ok && s.at.Before(v.(time.Time)) {
+				continue
+			}
+			if v, ok := placeholderArg(query, "created_at <", args); ok && !s.at.Before(v.(time.Time)) {
+				continue
+			}
+			if v, ok := placeholderArg(query, "total_amount >=", args); ok && s.total < v.(float64) {
+				continue
+			}
+			count++
+		}
+		return [][]any{{count}}, nil
+	})
+	repo := NewSaleRepo(db, zap.NewNop())
+
+	status := func(s model.SaleStatus) *model.SaleStatus { return &s }
+	date := func(d int) *time.Time { t := time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC); return &t }
+	amount := 15.0
+
+	tests := []struct {
+		name   string
+		filter SaleListFilter
+		want   int
+	}{
+		{name: "no filter", want: 5},
+		{name: "own sales only", filter: SaleListFilter{UserID: &cashier}, want: 4},
+		{name: "status", filter: SaleListFilter{Status: status(model.SaleStatusCompleted)}, want: 3},
+		{name: "own completed", filter: SaleListFilter{UserID: &cashier, Status: status(model.SaleStatusCompleted)}, want: 2},
+		{name: "end date inclusive", filter: SaleListFilter{StartDate: date(3), EndDate: date(3)}, want: 3},
+		{name: "own with dates and min amount", filter: SaleListFilter{UserID: &cashier, StartDate: date(2), EndDate: date(5), MinAmount: &amount}, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := repo.CountAllSales(context.Background(), tt.filter)
+			if err != nil || count != tt.want {
+				t.Errorf("count/err = %d/%v, want %d", count, err, tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"inventory-system/database"
 	"inventory-system/model"
+	"inventory-system/utils"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +18,10 @@ type SessionRepo interface {
 	DeleteByToken(ctx context.Context, token uuid.UUID) error
 	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
 	DeleteExpired(ctx context.Context) error
+	ExtendExpiry(ctx context.Context, token uuid.UUID, newExpiry time.Time) error
+	FindHistoryByUserID(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]model.Session, error)
+	CountByUserID(ctx context.Context, userID uuid.UUID) (int, error)
+	CountActiveByUser(ctx context.Context) ([]model.UserSessionCount, error)
 }
 
 type sessionRepo struct {
@@ -31,8 +36,8 @@ func NewSessionRepo(db database.PgxIface, log *zap.Logger) SessionRepo {
 // Create - Buat session baru (saat login)
 func (sr *sessionRepo) Create(ctx context.Context, session *model.Session) error {
 	query := `
-		INSERT INTO sessions (id, user_id, token, expires_at, created_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO sessions (id, user_id, token, expires_at, ip_address, user_agent, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
 	// Generate metadata sebelum insert
@@ -45,10 +50,12 @@ func (sr *sessionRepo) Create(ctx context.Context, session *model.Session) error
 		session.UserID,
 		session.Token,
 		session.ExpiresAt,
+		session.IPAddress,
+		session.UserAgent,
 		session.CreatedAt,
 	)
 	if err != nil {
-		sr.log.Error("Failed to create session",
+		utils.LoggerFromContext(ctx).Error("Failed to create session",
 			zap.Error(err),
 			zap.String("user_id", session.UserID.String()),
 		)
@@ -56,7 +63,7 @@ func (sr *sessionRepo) Create(ctx context.Context, session *model.Session) error
 	}
 
 	// Log success untuk audit trail
-	sr.log.Info("Session created",
+	utils.LoggerFromContext(ctx).Info("Session created",
 		zap.String("session_id", session.ID.String()),
 		zap.String("user_id", session.UserID.String()),
 	)
@@ -89,7 +96,7 @@ func (sr *sessionRepo) FindByToken(ctx context.Context, token uuid.UUID) (*model
 
 	// Cek apakah session sudah expired
 	if time.Now().After(session.ExpiresAt) {
-		sr.log.Warn("Session expired",
+		utils.LoggerFromContext(ctx).Warn("Session expired",
 			zap.String("token", token.String()),
 			zap.Time("expires_at", session.ExpiresAt),
 		)
@@ -112,7 +119,7 @@ func (sr *sessionRepo) DeleteByToken(ctx context.Context, token uuid.UUID) error
 	// Execute delete
 	result, err := sr.db.Exec(ctx, query, now, token)
 	if err != nil {
-		sr.log.Error("Failed to revoke session",
+		utils.LoggerFromContext(ctx).Error("Failed to revoke session",
 			zap.Error(err),
 			zap.String("token", token.String()),
 		)
@@ -124,7 +131,7 @@ func (sr *sessionRepo) DeleteByToken(ctx context.Context, token uuid.UUID) error
 		return fmt.Errorf("session not found or already revoked")
 	}
 
-	sr.log.Info("Session revoked",
+	utils.LoggerFromContext(ctx).Info("Session revoked",
 		zap.String("token", token.String()),
 	)
 	return nil
@@ -141,20 +148,40 @@ func (sr *sessionRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) err
 	now := time.Now()
 	result, err := sr.db.Exec(ctx, query, now, userID)
 	if err != nil {
-		sr.log.Error("Failed to revoke all user sessions",
+		utils.LoggerFromContext(ctx).Error("Failed to revoke all user sessions",
 			zap.Error(err),
 			zap.String("user_id", userID.String()),
 		)
 		return fmt.Errorf("revoke user sessions failed: %w", err)
 	}
 
-	sr.log.Info("All user sessions revoked",
+	utils.LoggerFromContext(ctx).Info("All user sessions revoked",
 		zap.String("user_id", userID.String()),
 		zap.Int64("sessions_revoked", result.RowsAffected()),
 	)
 	return nil
 }
 
+// ExtendExpiry - Perpanjang expiry session aktif (sliding session)
+// Hanya maju, tidak pernah memperpendek expiry yang sudah ada
+func (sr *sessionRepo) ExtendExpiry(ctx context.Context, token uuid.UUID, newExpiry time.Time) error {
+	query := `
+		UPDATE sessions 
+		SET expires_at = $1 
+		WHERE token = $2 AND revoked_at IS NULL AND expires_at < $1
+	`
+
+	if _, err := sr.db.Exec(ctx, query, newExpiry, token); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to extend session",
+			zap.Error(err),
+			zap.String("token", token.String()),
+		)
+		return fmt.Errorf("extend session failed: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteExpired - Hapus session yang udah expired (cleanup job)
 func (sr *sessionRepo) DeleteExpired(ctx context.Context) error {
 	query := `
@@ -165,14 +192,112 @@ func (sr *sessionRepo) DeleteExpired(ctx context.Context) error {
 	now := time.Now()
 	result, err := sr.db.Exec(ctx, query, now)
 	if err != nil {
-		sr.log.Error("Failed to delete expired sessions",
+		utils.LoggerFromContext(ctx).Error("Failed to delete expired sessions",
 			zap.Error(err),
 		)
 		return fmt.Errorf("delete expired sessions failed: %w", err)
 	}
 
-	sr.log.Info("Expired sessions cleaned up",
+	utils.LoggerFromContext(ctx).Info("Expired sessions cleaned up",
 		zap.Int64("sessions_deleted", result.RowsAffected()),
 	)
 	return nil
 }
+
+// FindHistoryByUserID - Riwayat login user (termasuk session revoked/expired), terbaru dulu
+// Session expired yang sudah dibersihkan DeleteExpired tidak muncul lagi
+func (sr *sessionRepo) FindHistoryByUserID(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]model.Session, error) {
+	query := `
+		SELECT id, user_id, token, expires_at, revoked_at, ip_address, user_agent, created_at
+		FROM sessions
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := sr.db.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query login history",
+			zap.Error(err),
+			zap.String("user_id", userID.String()),
+		)
+		return nil, fmt.Errorf("query login history failed: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]model.Session, 0)
+	for rows.Next() {
+		var session model.Session
+		err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.Token,
+			&session.ExpiresAt,
+			&session.RevokedAt,
+			&session.IPAddress,
+			&session.UserAgent,
+			&session.CreatedAt,
+		)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan session", zap.Error(err))
+			return nil, fmt.Errorf("scan session failed: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// CountByUserID - Total session user (untuk pagination login history)
+func (sr *sessionRepo) CountByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM sessions WHERE user_id = $1`
+
+	var count int
+	if err := sr.db.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to count sessions", zap.Error(err))
+		return 0, fmt.Errorf("count sessions failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountActiveByUser - Jumlah session aktif per user (security monitoring), terbanyak dulu
+// User yang sudah soft delete tidak ikut
+func (sr *sessionRepo) CountActiveByUser(ctx context.Context) ([]model.UserSessionCount, error) {
+	query := `
+		SELECT s.user_id, u.username, u.email, u.role,
+		       COUNT(*) AS active_count, MAX(s.created_at) AS last_created_at
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id AND u.deleted_at IS NULL
+		WHERE s.revoked_at IS NULL AND s.expires_at > $1
+		GROUP BY s.user_id, u.username, u.email, u.role
+		ORDER BY active_count DESC, u.username ASC
+	`
+
+	rows, err := sr.db.Query(ctx, query, time.Now())
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to count active sessions", zap.Error(err))
+		return nil, fmt.Errorf("count active sessions failed: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]model.UserSessionCount, 0)
+	for rows.Next() {
+		var c model.UserSessionCount
+		if err := rows.Scan(&c.UserID, &c.Username, &c.Email, &c.Role, &c.ActiveCount, &c.LastCreatedAt); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan active session count", zap.Error(err))
+			return nil, fmt.Errorf("scan active session count failed: %w", err)
+		}
+		counts = append(counts, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return counts, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"inventory-system/database"
 	"inventory-system/model"
+	"inventory-system/utils"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,9 +15,14 @@ import (
 type ShelfRepo interface {
 	Create(ctx context.Context, shelf *model.Shelf) error
 	FindByID(ctx context.Context, id uuid.UUID) (*model.Shelf, error)
-	FindAll(ctx context.Context, limit int, offset int) ([]model.Shelf, error)
-	CountAll(ctx context.Context) (int, error)
+	FindAll(ctx context.Context, limit int, offset int, includeDeleted bool) ([]model.Shelf, error)
+	CountAll(ctx context.Context, includeDeleted bool) (int, error)
 	FindByWarehouseID(ctx context.Context, warehouseID uuid.UUID) ([]model.Shelf, error)
+	FindByWarehouseIDPaginated(ctx context.Context, warehouseID uuid.UUID, search string, limit int, offset int) ([]model.Shelf, error)
+	CountByWarehouseID(ctx context.Context, warehouseID uuid.UUID, search string) (int, error)
+	FindByWarehouseAndCode(ctx context.Context, warehouseID uuid.UUID, code string) (*model.Shelf, error)
+	CountProducts(ctx context.Context, shelfID uuid.UUID) (int, error)
+	CreateBatch(ctx context.Context, shelves []model.Shelf) error
 	Update(ctx context.Context, shelf *model.Shelf) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -30,10 +36,19 @@ func NewShelfRepo(db database.PgxIface, log *zap.Logger) ShelfRepo {
 	return &shelfRepo{db: db, log: log}
 }
 
+// Jumlah produk aktif per rak, di-join ke query list (satu query, tanpa N+1)
+const shelfProductCountJoin = `
+	LEFT JOIN (
+		SELECT shelf_id, COUNT(*) AS product_count
+		FROM products
+		WHERE deleted_at IS NULL
+		GROUP BY shelf_id
+	) pc ON pc.shelf_id = shelves.id`
+
 func (sr *shelfRepo) Create(ctx context.Context, shelf *model.Shelf) error {
 	query := `
-		INSERT INTO shelves (id, warehouse_id, name, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO shelves (id, warehouse_id, code, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 
 	// Generate metadata sebelum insert
@@ -46,12 +61,16 @@ func (sr *shelfRepo) Create(ctx context.Context, shelf *model.Shelf) error {
 	_, err := sr.db.Exec(ctx, query,
 		shelf.ID,
 		shelf.WarehouseID,
+		shelf.Code,
 		shelf.Name,
 		shelf.CreatedAt,
 		shelf.UpdatedAt,
 	)
 	if err != nil {
-		sr.log.Error("Failed to create shelf",
+		if isUniqueViolation(err) {
+			return fmt.Errorf("shelf code already exists in warehouse")
+		}
+		utils.LoggerFromContext(ctx).Error("Failed to create shelf",
 			zap.Error(err),
 			zap.String("name", shelf.Name),
 		)
@@ -59,7 +78,7 @@ func (sr *shelfRepo) Create(ctx context.Context, shelf *model.Shelf) error {
 	}
 
 	// Log success untuk audit trail
-	sr.log.Info("Shelf Created",
+	utils.LoggerFromContext(ctx).Info("Shelf Created",
 		zap.String("id", shelf.ID.String()),
 		zap.String("name", shelf.Name),
 	)
@@ -69,7 +88,7 @@ func (sr *shelfRepo) Create(ctx context.Context, shelf *model.Shelf) error {
 
 func (sr *shelfRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Shelf, error) {
 	query := `
-		SELECT id, warehouse_id, name, created_at, updated_at, deleted_at
+		SELECT id, warehouse_id, code, name, created_at, updated_at, deleted_at
 		FROM shelves WHERE id = $1 AND deleted_at IS NULL
 	`
 
@@ -79,6 +98,7 @@ func (sr *shelfRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Shelf,
 	err := sr.db.QueryRow(ctx, query, id).Scan(
 		&shelf.ID,
 		&shelf.WarehouseID,
+		&shelf.Code,
 		&shelf.Name,
 		&shelf.CreatedAt,
 		&shelf.UpdatedAt,
@@ -92,18 +112,19 @@ func (sr *shelfRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Shelf,
 }
 
 // FindAll dengan pagination
-func (sr *shelfRepo) FindAll(ctx context.Context, limit int, offset int) ([]model.Shelf, error) {
-	query := `
-        SELECT id, warehouse_id, name, created_at, updated_at, deleted_at
-        FROM shelves 
-        WHERE deleted_at IS NULL
+func (sr *shelfRepo) FindAll(ctx context.Context, limit int, offset int, includeDeleted bool) ([]model.Shelf, error) {
+	query := fmt.Sprintf(`
+        SELECT id, warehouse_id, code, name, created_at, updated_at, deleted_at,
+            COALESCE(pc.product_count, 0)
+        FROM shelves %s
+        %s
         ORDER BY created_at DESC
         LIMIT $1 OFFSET $2
-    `
+    `, shelfProductCountJoin, activeFilter(includeDeleted))
 
 	rows, err := sr.db.Query(ctx, query, limit, offset)
 	if err != nil {
-		sr.log.Error("Failed to query shelves", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to query shelves", zap.Error(err))
 		return nil, fmt.Errorf("query shelves failed: %w", err)
 	}
 	defer rows.Close()
@@ -112,22 +133,23 @@ func (sr *shelfRepo) FindAll(ctx context.Context, limit int, offset int) ([]mode
 	for rows.Next() {
 		var shelf model.Shelf
 		err := rows.Scan(
-			&shelf.ID, &shelf.WarehouseID, &shelf.Name,
+			&shelf.ID, &shelf.WarehouseID, &shelf.Code, &shelf.Name,
 			&shelf.CreatedAt, &shelf.UpdatedAt, &shelf.DeletedAt,
+			&shelf.ProductCount,
 		)
 		if err != nil {
-			sr.log.Error("Failed to scan shelf", zap.Error(err))
+			utils.LoggerFromContext(ctx).Error("Failed to scan shelf", zap.Error(err))
 			return nil, fmt.Errorf("scan shelf failed: %w", err)
 		}
 		shelves = append(shelves, shelf)
 	}
 
 	if err = rows.Err(); err != nil {
-		sr.log.Error("Rows iteration error", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Rows iteration error", zap.Error(err))
 		return nil, fmt.Errorf("rows iteration failed: %w", err)
 	}
 
-	sr.log.Info("Fetched shelves with pagination",
+	utils.LoggerFromContext(ctx).Info("Fetched shelves with pagination",
 		zap.Int("limit", limit),
 		zap.Int("offset", offset),
 		zap.Int("count", len(shelves)))
@@ -136,13 +158,13 @@ func (sr *shelfRepo) FindAll(ctx context.Context, limit int, offset int) ([]mode
 }
 
 // CountAll menghitung total shelves aktif
-func (sr *shelfRepo) CountAll(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM shelves WHERE deleted_at IS NULL`
+func (sr *shelfRepo) CountAll(ctx context.Context, includeDeleted bool) (int, error) {
+	query := `SELECT COUNT(*) FROM shelves ` + activeFilter(includeDeleted)
 
 	var count int
 	err := sr.db.QueryRow(ctx, query).Scan(&count)
 	if err != nil {
-		sr.log.Error("Failed to count shelves", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to count shelves", zap.Error(err))
 		return 0, fmt.Errorf("count shelves failed: %w", err)
 	}
 
@@ -151,14 +173,64 @@ func (sr *shelfRepo) CountAll(ctx context.Context) (int, error) {
 
 func (sr *shelfRepo) FindByWarehouseID(ctx context.Context, warehouseID uuid.UUID) ([]model.Shelf, error) {
 	query := `
-		SELECT id, warehouse_id, name, created_at, updated_at, deleted_at
-		FROM shelves WHERE warehouse_id = $1 AND deleted_at IS NULL
+		SELECT id, warehouse_id, code, name, created_at, updated_at, deleted_at,
+			COALESCE(pc.product_count, 0)
+		FROM shelves` + shelfProductCountJoin + `
+		WHERE warehouse_id = $1 AND deleted_at IS NULL
 		ORDER BY code
 	`
 
 	rows, err := sr.db.Query(ctx, query, warehouseID)
 	if err != nil {
-		sr.log.Error("Failed to query shelves by warehouse",
+		utils.LoggerFromContext(ctx).Error("Failed to query shelves by warehouse",
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("query shelves failed: %w", err)
+	}
+	defer rows.Close()
+
+	var shelves []model.Shelf
+	for rows.Next() {
+		var shelf model.Shelf
+		err := rows.Scan(
+			&shelf.ID,
+			&shelf.WarehouseID,
+			&shelf.Code,
+			&shelf.Name,
+			&shelf.CreatedAt,
+			&shelf.UpdatedAt,
+			&shelf.DeletedAt,
+			&shelf.ProductCount,
+		)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan shelf", zap.Error(err))
+			return nil, fmt.Errorf("scan shelf failed: %w", err)
+		}
+		shelves = append(shelves, shelf)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return shelves, nil
+}
+
+// FindByWarehouseIDPaginated dengan pagination, search = filter nama (case-insensitive, kosong = semua)
+func (sr *shelfRepo) FindByWarehouseIDPaginated(ctx context.Context, warehouseID uuid.UUID, search string, limit int, offset int) ([]model.Shelf, error) {
+	query := `
+		SELECT id, warehouse_id, code, name, created_at, updated_at, deleted_at,
+			COALESCE(pc.product_count, 0)
+		FROM shelves` + shelfProductCountJoin + `
+		WHERE warehouse_id = $1 AND deleted_at IS NULL
+			AND ($2 = '' OR name ILIKE '%' || $2 || '%')
+		ORDER BY code
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := sr.db.Query(ctx, query, warehouseID, search, limit, offset)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query shelves by warehouse",
 			zap.Error(err),
 		)
 		return nil, fmt.Errorf("query shelves failed: %w", err)
@@ -171,13 +243,15 @@ func (sr *shelfRepo) FindByWarehouseID(ctx context.Context, warehouseID uuid.UUI
 		err := rows.Scan(
 			&shelf.ID,
 			&shelf.WarehouseID,
+			&shelf.Code,
 			&shelf.Name,
 			&shelf.CreatedAt,
 			&shelf.UpdatedAt,
 			&shelf.DeletedAt,
+			&shelf.ProductCount,
 		)
 		if err != nil {
-			sr.log.Error("Failed to scan shelf", zap.Error(err))
+			utils.LoggerFromContext(ctx).Error("Failed to scan shelf", zap.Error(err))
 			return nil, fmt.Errorf("scan shelf failed: %w", err)
 		}
 		shelves = append(shelves, shelf)
@@ -190,11 +264,130 @@ func (sr *shelfRepo) FindByWarehouseID(ctx context.Context, warehouseID uuid.UUI
 	return shelves, nil
 }
 
+// CountByWarehouseID menghitung rak aktif di warehouse (filter search sama dengan FindByWarehouseIDPaginated)
+func (sr *shelfRepo) CountByWarehouseID(ctx context.Context, warehouseID uuid.UUID, search string) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM shelves
+		WHERE warehouse_id = $1 AND deleted_at IS NULL
+			AND ($2 = '' OR name ILIKE '%' || $2 || '%')
+	`
+
+	var count int
+	err := sr.db.QueryRow(ctx, query, warehouseID, search).Scan(&count)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to count shelves by warehouse", zap.Error(err))
+		return 0, fmt.Errorf("count shelves failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// FindByWarehouseAndCode cari rak aktif berdasarkan kode di warehouse tertentu
+func (sr *shelfRepo) FindByWarehouseAndCode(ctx context.Context, warehouseID uuid.UUID, code string) (*model.Shelf, error) {
+	query := `
+		SELECT id, warehouse_id, code, name, created_at, updated_at, deleted_at
+		FROM shelves WHERE warehouse_id = $1 AND code = $2 AND deleted_at IS NULL
+	`
+
+	var shelf model.Shelf
+	err := sr.db.QueryRow(ctx, query, warehouseID, code).Scan(
+		&shelf.ID,
+		&shelf.WarehouseID,
+		&shelf.Code,
+		&shelf.Name,
+		&shelf.CreatedAt,
+		&shelf.UpdatedAt,
+		&shelf.DeletedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("shelf not found: %w", err)
+	}
+
+	return &shelf, nil
+}
+
+// CountProducts menghitung produk aktif di satu rak
+func (sr *shelfRepo) CountProducts(ctx context.Context, shelfID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM products WHERE shelf_id = $1 AND deleted_at IS NULL`
+
+	var count int
+	err := sr.db.QueryRow(ctx, query, shelfID).Scan(&count)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to count shelf products",
+			zap.Error(err),
+			zap.String("shelf_id", shelfID.String()),
+		)
+		return 0, fmt.Errorf("count shelf products failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// CreateBatch insert banyak rak dalam satu transaction (all or nothing)
+// Metadata (ID, timestamp) di-set langsung ke slice milik caller
+func (sr *shelfRepo) CreateBatch(ctx context.Context, shelves []model.Shelf) error {
+	if len(shelves) == 0 {
+		return fmt.Errorf("no shelves to insert")
+	}
+
+	query := `
+		INSERT INTO shelves (id, warehouse_id, code, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	tx, err := sr.db.Begin(ctx)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to begin transaction", zap.Error(err))
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	// Rollback no-op jika sudah commit
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	for i := range shelves {
+		shelves[i].ID = uuid.New()
+		shelves[i].CreatedAt = now
+		shelves[i].UpdatedAt = now
+
+		_, err := tx.Exec(ctx, query,
+			shelves[i].ID,
+			shelves[i].WarehouseID,
+			shelves[i].Code,
+			shelves[i].Name,
+			shelves[i].CreatedAt,
+			shelves[i].UpdatedAt,
+		)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return fmt.Errorf("shelf code already exists in warehouse: %s", shelves[i].Code)
+			}
+			utils.LoggerFromContext(ctx).Error("Failed to insert shelf in batch",
+				zap.Error(err),
+				zap.String("code", shelves[i].Code),
+			)
+			return fmt.Errorf("create shelf batch failed: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to commit shelf batch", zap.Error(err))
+		return fmt.Errorf("commit shelf batch failed: %w", err)
+	}
+
+	// Log success untuk audit trail
+	utils.LoggerFromContext(ctx).Info("Shelves created in batch",
+		zap.String("warehouse_id", shelves[0].WarehouseID.String()),
+		zap.Int("count", len(shelves)),
+	)
+
+	return nil
+}
+
 func (sr *shelfRepo) Update(ctx context.Context, shelf *model.Shelf) error {
 	query := `
 		UPDATE shelves
-		SET warehouse_id = $1, name = $2, updated_at = $3
-		WHERE id = $4 AND deleted_at IS NULL
+		SET warehouse_id = $1, code = $2, name = $3, updated_at = $4
+		WHERE id = $5 AND deleted_at IS NULL
 	`
 
 	// Update timestamp
@@ -203,12 +396,16 @@ func (sr *shelfRepo) Update(ctx context.Context, shelf *model.Shelf) error {
 	// Execute UPDATE statement
 	result, err := sr.db.Exec(ctx, query,
 		shelf.WarehouseID,
+		shelf.Code,
 		shelf.Name,
 		shelf.UpdatedAt,
 		shelf.ID,
 	)
 	if err != nil {
-		sr.log.Error("Failed to update shelf",
+		if isUniqueViolation(err) {
+			return fmt.Errorf("shelf code already exists in warehouse")
+		}
+		utils.LoggerFromContext(ctx).Error("Failed to update shelf",
 			zap.Error(err),
 			zap.String("id", shelf.ID.String()),
 		)
@@ -220,7 +417,7 @@ func (sr *shelfRepo) Update(ctx context.Context, shelf *model.Shelf) error {
 		return fmt.Errorf("shelf not found")
 	}
 
-	sr.log.Info("shelf updated", zap.String("id", shelf.ID.String()))
+	utils.LoggerFromContext(ctx).Info("shelf updated", zap.String("id", shelf.ID.String()))
 	return nil
 }
 
@@ -230,7 +427,7 @@ func (sr *shelfRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	// Execute delete
 	result, err := sr.db.Exec(ctx, query, time.Now(), id)
 	if err != nil {
-		sr.log.Error("Failed to delete shelf",
+		utils.LoggerFromContext(ctx).Error("Failed to delete shelf",
 			zap.Error(err),
 			zap.String("id", id.String()),
 		)
@@ -242,6 +439,6 @@ func (sr *shelfRepo) Delete(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("shelf not found")
 	}
 
-	sr.log.Info("Shelf deleted", zap.String("id", id.String()))
+	utils.LoggerFromContext(ctx).Info("Shelf deleted", zap.String("id", id.String()))
 	return nil
 }
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKE SHELVES ==========
+
+type fakeShelfRow struct {
+	id           uuid.UUID
+	code         string
+	createdAt    time.Time
+	deletedAt    *time.Time
+	productCount int
+}
+
+// newShelfDB tabel shelves di memori untuk FindAll/CountAll, urut created_at DESC seperti query
+func newShelfDB(t *testing.T, rows []fakeShelfRow) *fakeDB {
+	db := newFakeDB(t)
+
+	// Filter deleted_at dari activeFilter ada setelah join product count
+	visible := func() []fakeShelfRow {
+		query := db.calls[len(db.calls)-1].sql
+		includeDeleted := !strings.Contains(query, "shelves.id WHERE deleted_at IS NULL") &&
+			!strings.HasSuffix(query, "FROM shelves WHERE deleted_at IS NULL")
+		var result []fakeShelfRow
+		for _, row := range rows {
+			if includeDeleted || row.deletedAt == nil {
+				result = append(result, row)
+			}
+		}
+		return result
+	}
+
+	db.on("LIMIT $1 OFFSET $2", func(args []any) ([][]any, error) {
+		page := visible()
+		limit, offset := args[0].(int), args[1].(int)
+		page = page[min(offset, len(page)):min(offset+limit, len(page))]
+
+		result := make([][]any, 0, len(page))
+		for _, row := range page {
+			result = append(result, []any{row.id, uuid.Nil, row.code, row.code, row.createdAt, row.createdAt, row.deletedAt, row.productCount})
+		}
+		return result, nil
+	})
+	db.on("SELECT COUNT(*) FROM shelves", func(args []any) ([][]any, error) {
+		return [][]any{{len(visible())}}, nil
+	})
+
+	return db
+}
+
+// ========== FIND ALL ==========
+
+func TestShelfFindAllPagination(t *testing.T) {
+	now := time.Now()
+	deletedAt := now
+	var rows []fakeShelfRow
+	for i := 0; i < 25; i++ {
+		row := fakeShelfRow{id: uuid.New(), code: string(rune('A' + i)), createdAt: now.Add(-time.Duration(i) * time.Hour)}
+		// Dua rak terakhir sudah dihapus
+		if i >= 23 {
+			row.deletedAt = &deletedAt
+		}
+		rows = append(rows, row)
+	}
+
+	tests := []struct {
+		name           string
+		limit, offset  int
+		includeDeleted bool
+		wantCodes      string
+		wantTotal      int
+	}{
+		{name: "first page", limit: 10, offset: 0, wantCodes: "ABCDEFGHIJ", wantTotal: 23},
+		{name: "last partial page", limit: 10, offset: 20, wantCodes: "UVW", wantTotal: 23},
+		{name: "past the end", limit: 10, offset: 30, wantCodes: "", wantTotal: 23},
+		{name: "include deleted", limit: 10, offset: 20, includeDeleted: true, wantCodes: "UVWXY", wantTotal: 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newShelfDB(t, rows)
+			repo := NewShelfRepo(db, zap.NewNop())
+
+			shelves, err := repo.FindAll(context.Background(), tt.limit, tt.offset, tt.includeDeleted)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// limit & offset diteruskan apa adanya sebagai parameter query
+			args := db.calls[0].args
+			if args[0] != tt.limit || args[1] != tt.offset {
+				t.Errorf("query args = %v, want limit %d offset %d", args, tt.limit, tt.offset)
+			}
+
+			var codes strings.Builder
+			for _, s := range shelves {
+				codes.WriteString(s.Code)
+			}
+			if codes.String() != tt.wantCodes {
+				t.Errorf("codes = %q, want %q", codes.String(), tt.wantCodes)
+			}
+
+			total, err := repo.CountAll(context.Background(), tt.includeDeleted)
+			if err != nil {
+				t.Fatalf("unexpected count error: %v", err)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+// ========== PRODUCT COUNT ==========
+
+type fakeShelfProduct struct {
+	shelfID uuid.UUID
+	deleted bool
+}
+
+// countShelfProducts meniru subquery product count: produk soft-deleted hanya dihitung jika filter deleted_at hilang
+func countShelfProducts(query string, products []fakeShelfProduct, shelfID uuid.UUID) int {
+	excludeDeleted := strings.Contains(query, "FROM products WHERE deleted_at IS NULL GROUP BY shelf_id") ||
+		strings.Contains(query, "shelf_id = $1 AND deleted_at IS NULL")
+	count := 0
+	for _, p := range products {
+		if p.shelfID == shelfID && (!excludeDeleted || !p.deleted) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestShelfProductCounts(t *testing.T) {
+	warehouseID := uuid.New()
+	shelfA, shelfB, shelfEmpty := uuid.New(), uuid.New(), uuid.New()
+	products := []fakeShelfProduct{
+		{shelfID: shelfA}, {shelfID: shelfA}, {shelfID: shelfA, deleted: true},
+		{shelfID: shelfB}, {shelfID: shelfB, deleted: true},
+	}
+	want := map[uuid.UUID]int{shelfA: 2, shelfB: 1, shelfEmpty: 0}
+
+	t.Run("list counts in one query", func(t *testing.T) {
+		db := newFakeDB(t)
+		db.on("FROM shelves LEFT JOIN", func(args []any) ([][]any, error) {
+			query := db.calls[len(db.calls)-1].sql
+			now := time.Now()
+			var rows [][]any
+			for _, id := range []uuid.UUID{shelfA, shelfB, shelfEmpty} {
+				rows = append(rows, []any{id, warehouseID, "C", "N", now, now, nil, countShelfProducts(query, products, id)})
+			}
+			return rows, nil
+		})
+		repo := NewShelfRepo(db, zap.NewNop())
+
+		shelves, err := repo.FindByWarehouseID(context.Background(), warehouseID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(db.calls) != 1 {
+			t.Errorf("queries = %d, want 1 (no N+1)", len(db.calls))
+		}
+		for _, s := range shelves {
+			if s.ProductCount != want[s.ID] {
+				t.Errorf("shelf %s product_count = %d, want %d", s.ID, s.ProductCount, want[s.ID])
+			}
+		}
+	})
+
+	t.Run("single shelf count", func(t *testing.T) {
+		for id, wantCount := range want {
+			db := newFakeDB(t)
+			db.on("SELECT COUNT(*) FROM products", func(args []any) ([][]any, error) {
+				return [][]any{{countShelfProducts(db.calls[0].sql, products, args[0].(uuid.UUID))}}, nil
+			})
+			repo := NewShelfRepo(db, zap.NewNop())
+
+			count, err := repo.CountProducts(context.Background(), id)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if count != wantCount {
+				t.Errorf("CountProducts(%s) = %d, want %d", id, count, wantCount)
+			}
+		}
+	})
+}
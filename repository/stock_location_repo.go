@@ -0,0 +1,359 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/database"
+	"inventory-system/model"
+	"inventory-system/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// StockLocationRepo stok produk per rak (product_stock_locations)
+// Produk tanpa baris lokasi = single location, baris rak utama (products.shelf_id)
+// dibuat otomatis saat pertama kali stok per lokasi diubah
+type StockLocationRepo interface {
+	FindByProduct(ctx context.Context, productID uuid.UUID) ([]model.ProductStockLocation, error)
+	SetQuantity(ctx context.Context, productID, shelfID uuid.UUID, quantity int, movement model.StockMovement) (int, error)
+	Deduct(ctx context.Context, productID, shelfID uuid.UUID, quantity int, movement model.StockMovement) (int, error)
+	Transfer(ctx context.Context, productID, fromShelfID, toShelfID uuid.UUID, quantity int, movement model.StockMovement) (int, error)
+}
+
+type stockLocationRepo struct {
+	db  database.PgxIface
+	log *zap.Logger
+}
+
+func NewStockLocationRepo(db database.PgxIface, log *zap.Logger) StockLocationRepo {
+	return &stockLocationRepo{db: db, log: log}
+}
+
+// FindByProduct list stok per rak, kosong jika produk masih single location
+func (slr *stockLocationRepo) FindByProduct(ctx context.Context, productID uuid.UUID) ([]model.ProductStockLocation, error) {
+	query := `
+		SELECT l.product_id, l.shelf_id, s.code, s.name, w.id, w.code, w.name, l.quantity
+		FROM product_stock_locations l
+		LEFT JOIN shelves s ON s.id = l.shelf_id AND s.deleted_at IS NULL
+		LEFT JOIN warehouses w ON w.id = s.warehouse_id AND w.deleted_at IS NULL
+		WHERE l.product_id = $1
+		ORDER BY w.name NULLS LAST, s.code NULLS LAST
+	`
+
+	rows, err := slr.db.Query(ctx, query, productID)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query stock locations", zap.Error(err))
+		return nil, fmt.Errorf("query stock locations failed: %w", err)
+	}
+	defer rows.Close()
+
+	locations := make([]model.ProductStockLocation, 0)
+	for rows.Next() {
+		var location model.ProductStockLocation
+		err := rows.Scan(
+			&location.ProductID,
+			&location.ShelfID,
+			&location.ShelfCode,
+			&location.ShelfName,
+			&location.WarehouseID,
+			&location.WarehouseCode,
+			&location.WarehouseName,
+			&location.Quantity,
+		)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan stock location", zap.Error(err))
+			return nil, fmt.Errorf("scan stock location failed: %w", err)
+		}
+		locations = append(locations, location)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return locations, nil
+}
+
+// SetQuantity set stok di satu rak, total di products dihitung ulang (return total baru)
+// Perubahan total dicatat ke ledger
+func (slr *stockLocationRepo) SetQuantity(ctx context.Context, productID, shelfID uuid.UUID, quantity int, movement model.StockMovement) (int, error) {
+	if quantity < 0 {
+		return 0, fmt.Errorf("stock quantity cannot be negative")
+	}
+
+	tx, err := slr.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	oldTotal, err := seedPrimaryLocation(ctx, tx, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO product_stock_locations (id, product_id, shelf_id, quantity, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (product_id, shelf_id) DO UPDATE SET quantity = EXCLUDED.quantity, updated_at = EXCLUDED.updated_at
+	`
+	if _, err := tx.Exec(ctx, query, uuid.New(), productID, shelfID, quantity, time.Now()); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to set location stock", zap.Error(err))
+		return 0, fmt.Errorf("set location stock failed: %w", err)
+	}
+
+	total, err := syncProductTotal(ctx, tx, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := recordStockChange(ctx, tx, productID, oldTotal, total, movement); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to record stock movement", zap.Error(err))
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit transaction failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Location stock updated",
+		zap.String("product_id", productID.String()),
+		zap.String("shelf_id", shelfID.String()),
+		zap.Int("quantity", quantity),
+		zap.Int("total", total))
+	return total, nil
+}
+
+// Deduct kurangi stok di rak tertentu, total di products ikut berkurang
+// Pengurangan dicatat ke ledger
+func (slr *stockLocationRepo) Deduct(ctx context.Context, productID, shelfID uuid.UUID, quantity int, movement model.StockMovement) (int, error) {
+	tx, err := slr.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	total, err := deductLocationStock(ctx, tx, productID, shelfID, quantity, movement)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit transaction failed: %w", err)
+	}
+
+	return total, nil
+}
+
+// Transfer pindahkan stok dari satu rak ke rak lain (boleh beda warehouse), total produk tetap
+// Dicatat dua baris ledger transfer (- di asal, + di tujuan) dengan ReferenceID yang sama
+func (slr *stockLocationRepo) Transfer(ctx context.Context, productID, fromShelfID, toShelfID uuid.UUID, quantity int, movement model.StockMovement) (int, error) {
+	tx, err := slr.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := seedPrimaryLocation(ctx, tx, productID); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	result, err := tx.Exec(ctx, `
+		UPDATE product_stock_locations
+		SET quantity = quantity - $3, updated_at = $4
+		WHERE product_id = $1 AND shelf_id = $2 AND quantity >= $3
+	`, productID, fromShelfID, quantity, now)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to deduct source location stock", zap.Error(err))
+		return 0, fmt.Errorf("deduct location stock failed: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return 0, fmt.Errorf("insufficient stock at source shelf")
+	}
+
+	query := `
+		INSERT INTO product_stock_locations (id, product_id, shelf_id, quantity, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (product_id, shelf_id) DO UPDATE
+		SET quantity = product_stock_locations.quantity + EXCLUDED.quantity, updated_at = EXCLUDED.updated_at
+	`
+	if _, err := tx.Exec(ctx, query, uuid.New(), productID, toShelfID, quantity, now); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to add destination location stock", zap.Error(err))
+		return 0, fmt.Errorf("update location stock failed: %w", err)
+	}
+
+	total, err := syncProductTotal(ctx, tx, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Pasangan ledger: keluar dari rak asal lalu masuk ke rak tujuan
+	for _, delta := range []int{-quantity, quantity} {
+		entry := movement
+		entry.ProductID = productID
+		entry.MovementType = model.StockMovementTransfer
+		entry.Quantity = delta
+		entry.StockAfter = total
+		if err := insertStockMovement(ctx, tx, &entry); err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to record stock movement", zap.Error(err))
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit transaction failed: %w", err)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Stock transferred",
+		zap.String("product_id", productID.String()),
+		zap.String("from_shelf_id", fromShelfID.String()),
+		zap.String("to_shelf_id", toShelfID.String()),
+		zap.Int("quantity", quantity))
+	return total, nil
+}
+
+// ========== HELPER (dipakai juga oleh productRepo.UpdateStock) ==========
+
+// seedPrimaryLocation lock produk, lalu pindahkan stok produk single location ke baris rak utama
+// No-op jika produk sudah punya baris lokasi. Return total stok saat di-lock
+func seedPrimaryLocation(ctx context.Context, tx pgx.Tx, productID uuid.UUID) (int, error) {
+	var stock int
+	var shelfID uuid.UUID
+	err := tx.QueryRow(ctx,
+		`SELECT stock_quantity, shelf_id FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
+		productID,
+	).Scan(&stock, &shelfID)
+	if err != nil {
+		return 0, fmt.Errorf("product not found")
+	}
+
+	query := `
+		INSERT INTO product_stock_locations (id, product_id, shelf_id, quantity, created_at, updated_at)
+		SELECT $1, $2, $3, $4, $5, $5
+		WHERE NOT EXISTS (SELECT 1 FROM product_stock_locations WHERE product_id = $2)
+	`
+	if _, err := tx.Exec(ctx, query, uuid.New(), productID, shelfID, stock, time.Now()); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to seed primary stock location", zap.Error(err))
+		return 0, fmt.Errorf("seed stock location failed: %w", err)
+	}
+
+	return stock, nil
+}
+
+// syncProductTotal set products.stock_quantity = SUM(quantity) semua lokasi
+func syncProductTotal(ctx context.Context, tx pgx.Tx, productID uuid.UUID) (int, error) {
+	query := `
+		UPDATE products
+		SET stock_quantity = (SELECT COALESCE(SUM(quantity), 0) FROM product_stock_locations WHERE product_id = $1),
+			updated_at = $2
+		WHERE id = $1
+		RETURNING stock_quantity
+	`
+
+	var total int
+	if err := tx.QueryRow(ctx, query, productID, time.Now()).Scan(&total); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to sync product stock total", zap.Error(err))
+		return 0, fmt.Errorf("sync stock total failed: %w", err)
+	}
+
+	return total, nil
+}
+
+// deductLocationStock kurangi stok satu rak di dalam transaction (dipakai Deduct & sale)
+// Return total stok produk setelah dipotong
+func deductLocationStock(ctx context.Context, tx pgx.Tx, productID, shelfID uuid.UUID, quantity int, movement model.StockMovement) (int, error) {
+	oldTotal, err := seedPrimaryLocation(ctx, tx, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		UPDATE product_stock_locations
+		SET quantity = quantity - $3, updated_at = $4
+		WHERE product_id = $1 AND shelf_id = $2 AND quantity >= $3
+	`
+	result, err := tx.Exec(ctx, query, productID, shelfID, quantity, time.Now())
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to deduct location stock", zap.Error(err))
+		return 0, fmt.Errorf("deduct location stock failed: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return 0, fmt.Errorf("insufficient stock at location")
+	}
+
+	total, err := syncProductTotal(ctx, tx, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := recordStockChange(ctx, tx, productID, oldTotal, total, movement); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to record stock movement", zap.Error(err))
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// applyLocationDelta sebar perubahan total ke lokasi (produk multi location)
+// Tambah masuk ke rak utama, kurang diambil dari rak utama dulu lalu rak dengan stok terbanyak
+func applyLocationDelta(ctx context.Context, tx pgx.Tx, productID, primaryShelfID uuid.UUID, delta int) error {
+	now := time.Now()
+
+	if delta > 0 {
+		query := `
+			INSERT INTO product_stock_locations (id, product_id, shelf_id, quantity, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $5)
+			ON CONFLICT (product_id, shelf_id) DO UPDATE
+			SET quantity = product_stock_locations.quantity + EXCLUDED.quantity, updated_at = EXCLUDED.updated_at
+		`
+		if _, err := tx.Exec(ctx, query, uuid.New(), productID, primaryShelfID, delta, now); err != nil {
+			return fmt.Errorf("update location stock failed: %w", err)
+		}
+		return nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT shelf_id, quantity FROM product_stock_locations
+		WHERE product_id = $1 AND quantity > 0
+		ORDER BY (shelf_id = $2) DESC, quantity DESC
+		FOR UPDATE
+	`, productID, primaryShelfID)
+	if err != nil {
+		return fmt.Errorf("query stock locations failed: %w", err)
+	}
+
+	// Kumpulkan dulu, rows harus ditutup sebelum Exec di transaction yang sama
+	remaining := -delta
+	deductions := make(map[uuid.UUID]int)
+	for rows.Next() && remaining > 0 {
+		var shelfID uuid.UUID
+		var quantity int
+		if err := rows.Scan(&shelfID, &quantity); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan stock location failed: %w", err)
+		}
+		take := min(quantity, remaining)
+		deductions[shelfID] = take
+		remaining -= take
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	for shelfID, take := range deductions {
+		_, err := tx.Exec(ctx,
+			`UPDATE product_stock_locations SET quantity = quantity - $3, updated_at = $4 WHERE product_id = $1 AND shelf_id = $2`,
+			productID, shelfID, take, now,
+		)
+		if err != nil {
+			return fmt.Errorf("update location stock failed: %w", err)
+		}
+	}
+
+	return nil
+}
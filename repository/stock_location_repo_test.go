@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"inventory-system/model"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKE INVENTORY ==========
+
+type fakeStockProduct struct {
+	stock     int
+	shelfID   uuid.UUID
+	locations map[uuid.UUID]int
+}
+
+type fakeMovement struct {
+	productID    uuid.UUID
+	movementType model.StockMovementType
+	quantity     int
+	referenceID  *uuid.UUID
+	createdAt    time.Time
+}
+
+// fakeInventory tabel products, product_stock_locations, stock_movements (+ sales) di memori
+type fakeInventory struct {
+	products  map[uuid.UUID]*fakeStockProduct
+	movements []fakeMovement
+	sales     int
+	saleItems int
+}
+
+func (inv *fakeInventory) clone() *fakeInventory {
+	copied := &fakeInventory{
+		products:  make(map[uuid.UUID]*fakeStockProduct, len(inv.products)),
+		movements: append([]fakeMovement(nil), inv.movements...),
+		sales:     inv.sales,
+		saleItems: inv.saleItems,
+	}
+	for id, p := range inv.products {
+		locations := make(map[uuid.UUID]int, len(p.locations))
+		for shelfID, qty := range p.locations {
+			locations[shelfID] = qty
+		}
+		copied.products[id] = &fakeStockProduct{stock: p.stock, shelfID: p.shelfID, locations: locations}
+	}
+	return copied
+}
+
+// shelfSum total stok semua rak, harus sama dengan stock produk untuk produk multi location
+func (p *fakeStockProduct) shelfSum() int {
+	sum := 0
+	for _, qty := range p.locations {
+		sum += qty
+	}
+	return sum
+}
+
+// newInventoryDB pasang handler SQL stok ke fakeDB, setiap transaction mulai dari state committed
+func newInventoryDB(t *testing.T, committed *fakeInventory) (*fakeDB, func() *fakeInventory) {
+	db := newFakeDB(t)
+	staged := committed.clone()
+	db.onBegin = func() { staged = committed.clone() }
+	db.onCommit = func() { *committed = *staged.clone() }
+
+	product := func(arg any) *fakeStockProduct {
+		p, ok := staged.products[arg.(uuid.UUID)]
+		if !ok {
+			t.Fatalf("unknown product %v", arg)
+		}
+		return p
+	}
+
+	db.on("SELECT stock_quantity, shelf_id, EXISTS", func(args []any) ([][]any, error) {
+		p := product(args[0])
+		return [][]any{{p.stock, p.shelfID, len(p.locations) > 0}}, nil
+	})
+	db.on("SELECT stock_quantity, shelf_id FROM products", func(args []any) ([][]any, error) {
+		p := product(args[0])
+		return [][]any{{p.stock, p.shelfID}}, nil
+	})
+	db.on("SELECT stock_quantity FROM products", func(args []any) ([][]any, error) {
+		return [][]any{{product(args[0]).stock}}, nil
+	})
+	db.on("WHERE NOT EXISTS (SELECT 1 FROM product_stock_locations", func(args []any) ([][]any, error) {
+		p := product(args[1])
+		if len(p.locations) > 0 {
+			return nil, nil
+		}
+		p.locations[args[2].(uuid.UUID)] = args[3].(int)
+		return [][]any{{}}, nil
+	})
+	db.on("SET quantity = EXCLUDED.quantity", func(args []any) ([][]any, error) {
+		product(args[1]).locations[args[2].(uuid.UUID)] = args[3].(int)
+		return [][]any{{}}, nil
+	})
+	db.on("SET quantity = product_stock_locations.quantity + EXCLUDED.quantity", func(args []any) ([][]any, error) {
+		product(args[1]).locations[args[2].(uuid.UUID)] += args[3].(int)
+		return [][]any{{}}, nil
+	})
+	db.on("AND quantity >= $3", func(args []any) ([][]any, error) {
+		p, shelfID, qty := product(args[0]), args[1].(uuid.UUID), args[2].(int)
+		current, ok := p.locations[shelfID]
+		if !ok || current < qty {
+			return nil, nil
+		}
+		p.locations[shelfID] = current - qty
+		return [][]any{{}}, nil
+	})
+	db.on("UPDATE product_stock_locations SET quantity = quantity - $3", func(args []any) ([][]any, error) {
+		product(args[0]).locations[args[1].(uuid.UUID)] -= args[2].(int)
+		return [][]any{{}}, nil
+	})
+	db.on("SELECT shelf_id, quantity FROM product_stock_locations", func(args []any) ([][]any, error) {
+		p, primary := product(args[0]), args[1].(uuid.UUID)
+		var rows [][]any
+		for shelfID, qty := range p.locations {
+			if qty > 0 {
+				rows = append(rows, []any{shelfID, qty})
+			}
+		}
+		// ORDER BY (shelf_id = primary) DESC, quantity DESC
+		sort.Slice(rows, func(i, j int) bool {
+			if (rows[i][0] == primary) != (rows[j][0] == primary) {
+				return rows[i][0] == primary
+			}
+			return rows[i][1].(int) > rows[j][1].(int)
+		})
+		return rows, nil
+	})
+	db.on("SET stock_quantity = (SELECT COALESCE(SUM(quantity), 0)", func(args []any) ([][]any, error) {
+		p := product(args[0])
+		p.stock = p.shelfSum()
+		return [][]any{{p.stock}}, nil
+	})
+	db.on("UPDATE products SET stock_quantity = $1", func(args []any) ([][]any, error) {
+		product(args[2]).stock = args[0].(int)
+		return [][]any{{}}, nil
+	})
+	db.on("INSERT INTO stock_movements", func(args []any) ([][]any, error) {
+		staged.movements = append(staged.movements, fakeMovement{
+			productID:    args[1].(uuid.UUID),
+			movementType: args[2].(model.StockMovementType),
+			quantity:     args[3].(int),
+			referenceID:  args[5].(*uuid.UUID),
+		})
+		return [][]any{{}}, nil
+	})
+	db.on("INSERT INTO sales", func(args []any) ([][]any, error) {
+		staged.sales++
+		return [][]any{{}}, nil
+	})
+	db.on("INSERT INTO sale_items", func(args []any) ([][]any, error) {
+		staged.saleItems += len(args) / 8
+		return [][]any{{}}, nil
+	})
+
+	return db, func() *fakeInventory { return staged }
+}
+
+// ========== MULTI LOCATION TOTALS ==========
+
+func TestStockLocationTotalsStayInSync(t *testing.T) {
+	productID, shelfA, shelfB := uuid.New(), uuid.New(), uuid.New()
+	inventory := &fakeInventory{products: map[uuid.UUID]*fakeStockProduct{
+		productID: {stock: 10, shelfID: shelfA, locations: map[uuid.UUID]int{}},
+	}}
+	db, _ := newInventoryDB(t, inventory)
+	locations := NewStockLocationRepo(db, zap.NewNop())
+	products := NewProductRepo(db, zap.NewNop())
+	ctx := context.Background()
+	movement := model.StockMovement{MovementType: model.StockMovementAdjustment}
+
+	steps := []struct {
+		name      string
+		run       func() error
+		wantErr   bool
+		wantTotal int
+		wantA     int
+		wantB     int
+	}{
+		{
+			name:      "set second shelf seeds primary",
+			run:       func() error { _, err := locations.SetQuantity(ctx, productID, shelfB, 6, movement); return err },
+			wantTotal: 16, wantA: 10, wantB: 6,
+		},
+		{
+			name:      "deduct from primary shelf",
+			run:       func() error { _, err := locations.Deduct(ctx, productID, shelfA, 4, movement); return err },
+			wantTotal: 12, wantA: 6, wantB: 6,
+		},
+		{
+			name:      "set product total takes primary first",
+			run:       func() error { return products.UpdateStock(ctx, productID, 5, movement) },
+			wantTotal: 5, wantA: 0, wantB: 5,
+		},
+		{
+			name:      "deduct more than shelf holds",
+			run:       func() error { _, err := locations.Deduct(ctx, productID, shelfB, 9, movement); return err },
+			wantErr:   true,
+			wantTotal: 5, wantA: 0, wantB: 5,
+		},
+	}
+
+	for _, step := range steps {
+		err := step.run()
+		if (err != nil) != step.wantErr {
+			t.Fatalf("%s: error = %v, want error %v", step.name, err, step.wantErr)
+		}
+
+		p := inventory.products[productID]
+		if p.stock != step.wantTotal || p.locations[shelfA] != step.wantA || p.locations[shelfB] != step.wantB {
+			t.Errorf("%s: total = %d, shelves = %v, want %d (A %d, B %d)", step.name, p.stock, p.locations, step.wantTotal, step.wantA, step.wantB)
+		}
+		if p.shelfSum() != p.stock {
+			t.Errorf("%s: sum of shelves = %d, product total = %d", step.name, p.shelfSum(), p.stock)
+		}
+	}
+}
+
+// ========== TRANSFER ==========
+
+func TestStockLocationTransfer(t *testing.T) {
+	productID, shelfA, shelfB := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name     string
+		quantity int
+		wantErr  string
+		wantA    int
+		wantB    int
+	}{
+		{name: "cross warehouse transfer", quantity: 4, wantA: 6, wantB: 4},
+		{name: "whole source stock", quantity: 10, wantA: 0, wantB: 10},
+		{name: "more than source holds", quantity: 11, wantErr: "insufficient stock at source shelf", wantA: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Shelf A & B di warehouse berbeda, repo tidak membedakan: cukup product_stock_locations
+			inventory := &fakeInventory{products: map[uuid.UUID]*fakeStockProduct{
+				productID: {stock: 10, shelfID: shelfA, locations: map[uuid.UUID]int{}},
+			}}
+			db, _ := newInventoryDB(t, inventory)
+			repo := NewStockLocationRepo(db, zap.NewNop())
+
+			transferID := uuid.New()
+			total, err := repo.Transfer(context.Background(), productID, shelfA, shelfB, tt.quantity, model.StockMovement{ReferenceID: &transferID})
+
+			p := inventory.products[productID]
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if db.commits != 0 || len(inventory.movements) != 0 || p.stock != 10 || len(p.locations) != 0 {
+					t.Errorf("commits = %d, movements = %d, stock = %d, locations = %v, want nothing written",
+						db.commits, len(inventory.movements), p.stock, p.locations)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if total != 10 || p.stock != 10 || p.shelfSum() != 10 {
+				t.Errorf("total = %d, product = %d, shelves = %d, want 10 unchanged", total, p.stock, p.shelfSum())
+			}
+			if p.locations[shelfA] != tt.wantA || p.locations[shelfB] != tt.wantB {
+				t.Errorf("shelves = %v, want A %d, B %d", p.locations, tt.wantA, tt.wantB)
+			}
+
+			if len(inventory.movements) != 2 {
+				t.Fatalf("movements = %d, want paired 2", len(inventory.movements))
+			}
+			net := 0
+			for _, m := range inventory.movements {
+				if m.movementType != model.StockMovementTransfer || m.referenceID == nil || *m.referenceID != transferID {
+					t.Errorf("movement = %+v, want transfer with shared reference", m)
+				}
+				net += m.quantity
+			}
+			if inventory.movements[0].quantity != -tt.quantity || net != 0 {
+				t.Errorf("movement quantities = %d/%d, want -%d then +%d", inventory.movements[0].quantity, inventory.movements[1].quantity, tt.quantity, tt.quantity)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inventory-system/database"
+	"inventory-system/model"
+	"inventory-system/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// StockMovementRepo baca ledger stok (stock_movements)
+// Penulisan ledger selalu lewat transaction perubahan stok (lihat recordStockChange)
+type StockMovementRepo interface {
+	SummaryByType(ctx context.Context, productID uuid.UUID, startDate, endDate time.Time) ([]model.StockMovementSummary, error)
+	StockAtTime(ctx context.Context, productID uuid.UUID, at time.Time) (int, error)
+	FindAll(ctx context.Context, startDate, endDate *time.Time, movementType string, limit, offset int) ([]model.StockMovementFeedEntry, error)
+	CountAll(ctx context.Context, startDate, endDate *time.Time, movementType string) (int, error)
+}
+
+type stockMovementRepo struct {
+	db  database.PgxIface
+	log *zap.Logger
+}
+
+func NewStockMovementRepo(db database.PgxIface, log *zap.Logger) StockMovementRepo {
+	return &stockMovementRepo{db: db, log: log}
+}
+
+// SummaryByType total movement produk per jenis, endDate inclusive (sampai akhir hari tersebut)
+// Hanya jenis yang punya movement di periode tersebut yang dikembalikan
+func (smr *stockMovementRepo) SummaryByType(ctx context.Context, productID uuid.UUID, startDate, endDate time.Time) ([]model.StockMovementSummary, error) {
+	query := `
+		SELECT movement_type,
+			COUNT(*) as movement_count,
+			COALESCE(SUM(quantity) FILTER (WHERE quantity > 0), 0) as quantity_in,
+			COALESCE(-SUM(quantity) FILTER (WHERE quantity < 0), 0) as quantity_out
+		FROM stock_movements
+		WHERE product_id = $1 AND created_at >= $2 AND created_at < $3
+		GROUP BY movement_type
+	`
+
+	rows, err := smr.db.Query(ctx, query, productID, startDate, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query stock movement summary", zap.Error(err))
+		return nil, fmt.Errorf("query stock movement summary failed: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := make([]model.StockMovementSummary, 0)
+	for rows.Next() {
+		var summary model.StockMovementSummary
+		err := rows.Scan(
+			&summary.MovementType,
+			&summary.MovementCount,
+			&summary.QuantityIn,
+			&summary.QuantityOut,
+		)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan stock movement summary", zap.Error(err))
+			return nil, fmt.Errorf("scan stock movement summary failed: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// StockAtTime rekonstruksi stok produk pada waktu at:
+// stok sekarang dikurangi total movement sejak at (movement tepat di at dianggap sesudahnya)
+func (smr *stockMovementRepo) StockAtTime(ctx context.Context, productID uuid.UUID, at time.Time) (int, error) {
+	query := `
+		SELECT p.stock_quantity - COALESCE((
+			SELECT SUM(m.quantity) FROM stock_movements m
+			WHERE m.product_id = p.id AND m.created_at >= $2
+		), 0)
+		FROM products p
+		WHERE p.id = $1 AND p.deleted_at IS NULL
+	`
+
+	var stock int
+	err := smr.db.QueryRow(ctx, query, productID, at).Scan(&stock)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("product not found")
+	}
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to reconstruct stock", zap.Error(err))
+		return 0, fmt.Errorf("reconstruct stock failed: %w", err)
+	}
+
+	return stock, nil
+}
+
+// FindAll activity feed movement semua produk, terbaru dulu
+// startDate/endDate nil = tidak difilter (endDate inclusive), movementType kosong = semua jenis
+func (smr *stockMovementRepo) FindAll(ctx context.Context, startDate, endDate *time.Time, movementType string, limit, offset int) ([]model.StockMovementFeedEntry, error) {
+	where, args := movementFeedWhere(startDate, endDate, movementType)
+	args = append(args, limit, offset)
+
+	query := fmt.Sprintf(`
+		SELECT m.id, m.product_id, m.movement_type, m.quantity, m.stock_after, m.reference_id, m.notes, m.created_by, m.created_at,
+		       p.name, p.sku, u.username
+		FROM stock_movements m
+		JOIN products p ON p.id = m.product_id
+		LEFT JOIN users u ON u.id = m.created_by
+		WHERE %s
+		ORDER BY m.created_at DESC, m.id
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args))
+
+	rows, err := smr.db.Query(ctx, query, args...)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query stock movements", zap.Error(err))
+		return nil, fmt.Errorf("query stock movements failed: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]model.StockMovementFeedEntry, 0)
+	for rows.Next() {
+		var entry model.StockMovementFeedEntry
+		err := rows.Scan(
+			&entry.ID, &entry.ProductID, &entry.MovementType, &entry.Quantity, &entry.StockAfter,
+			&entry.ReferenceID, &entry.Notes, &entry.CreatedBy, &entry.CreatedAt,
+			&entry.ProductName, &entry.ProductSKU, &entry.Username,
+		)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan stock movement", zap.Error(err))
+			return nil, fmt.Errorf("scan stock movement failed: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return entries, nil
+}
+
+// CountAll total movement dengan filter yang sama seperti FindAll (untuk pagination)
+func (smr *stockMovementRepo) CountAll(ctx context.Context, startDate, endDate *time.Time, movementType string) (int, error) {
+	where, args := movementFeedWhere(startDate, endDate, movementType)
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM stock_movements m WHERE %s`, where)
+
+	var count int
+	if err := smr.db.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to count stock movements", zap.Error(err))
+		return 0, fmt.Errorf("count stock movements failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// movementFeedWhere builds WHERE clause feed movement, placeholder mulai dari $1
+func movementFeedWhere(startDate, endDate *time.Time, movementType string) (string, []interface{}) {
+	where := "TRUE"
+	var args []interface{}
+
+	if startDate != nil {
+		args = append(args, *startDate)
+		where += fmt.Sprintf(" AND m.created_at >= $%d", len(args))
+	}
+	if endDate != nil {
+		args = append(args, endDate.AddDate(0, 0, 1))
+		where += fmt.Sprintf(" AND m.created_at < $%d", len(args))
+	}
+	if movementType != "" {
+		args = append(args, movementType)
+		where += fmt.Sprintf(" AND m.movement_type = $%d", len(args))
+	}
+
+	return where, args
+}
+
+// ========== HELPER (dipakai repo yang mengubah stok) ==========
+
+// insertStockMovement catat satu baris ledger di dalam transaction pemanggil
+// Supaya perubahan stok & catatannya selalu commit/rollback bersama
+func insertStockMovement(ctx context.Context, tx pgx.Tx, movement *model.StockMovement) error {
+	query := `
+		INSERT INTO stock_movements (id, product_id, movement_type, quantity, stock_after, reference_id, notes, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	movement.ID = uuid.New()
+	movement.CreatedAt = time.Now()
+
+	// Default user dari request (semua route yang mengubah stok butuh login)
+	if movement.CreatedBy == nil {
+		if actor := utils.GetUserFromContext(ctx); actor != nil {
+			movement.CreatedBy = &actor.ID
+		}
+	}
+
+	_, err := tx.Exec(ctx, query,
+		movement.ID, movement.ProductID, movement.MovementType, movement.Quantity,
+		movement.StockAfter, movement.ReferenceID, movement.Notes, movement.CreatedBy, movement.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert stock movement failed: %w", err)
+	}
+
+	return nil
+}
+
+// recordStockChange catat perubahan total stok oldStock -> newStock, no-op jika tidak berubah
+// Pemanggil cukup isi MovementType (+ ReferenceID/Notes), quantity & stock_after dihitung di sini
+func recordStockChange(ctx context.Context, tx pgx.Tx, productID uuid.UUID, oldStock, newStock int, movement model.StockMovement) error {
+	if newStock == oldStock {
+		return nil
+	}
+
+	movement.ProductID = productID
+	movement.Quantity = newStock - oldStock
+	movement.StockAfter = newStock
+	return insertStockMovement(ctx, tx, &movement)
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"inventory-system/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== SUMMARY BY TYPE ==========
+
+func TestSummaryByType(t *testing.T) {
+	productID := uuid.New()
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 10, 0, 0, 0, time.UTC) }
+	movements := []fakeMovement{
+		{productID: productID, movementType: model.StockMovementRestock, quantity: 20, createdAt: day(1)},
+		{productID: productID, movementType: model.StockMovementRestock, quantity: 5, createdAt: day(2)},
+		{productID: productID, movementType: model.StockMovementSale, quantity: -3, createdAt: day(2)},
+		{productID: productID, movementType: model.StockMovementSale, quantity: -4, createdAt: day(3)},
+		{productID: productID, movementType: model.StockMovementAdjustment, quantity: 2, createdAt: day(3)},
+		{productID: productID, movementType: model.StockMovementAdjustment, quantity: -6, createdAt: day(3)},
+		// Di luar periode / produk lain
+		{productID: productID, movementType: model.StockMovementSale, quantity: -50, createdAt: day(6)},
+		{productID: uuid.New(), movementType: model.StockMovementRestock, quantity: 70, createdAt: day(2)},
+	}
+
+	db := newFakeDB(t)
+	db.on("GROUP BY movement_type", func(args []any) ([][]any, error) {
+		// Emulasi WHERE product_id/created_at + GROUP BY movement_type dengan FILTER in/out
+		var rows [][]any
+		index := map[model.StockMovementType]int{}
+		for _, m := range movements {
+			if m.productID != args[0] || m.createdAt.Before(args[1].(time.Time)) || !m.createdAt.Before(args[2].(time.Time)) {
+				continue
+			}
+			i, ok := index[m.movementType]
+			if !ok {
+				i = len(rows)
+				index[m.movementType] = i
+				rows = append(rows, []any{string(m.movementType), 0, 0, 0})
+			}
+			rows[i][1] = rows[i][1].(int) + 1
+			if m.quantity > 0 {
+				rows[i][2] = rows[i][2].(int) + m.quantity
+			} else {
+				rows[i][3] = rows[i][3].(int) - m.quantity
+			}
+		}
+		return rows, nil
+	})
+
+	start, end := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	summaries, err := NewStockMovementRepo(db, zap.NewNop()).SummaryByType(context.Background(), productID, start, end)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[model.StockMovementType]model.StockMovementSummary{
+		model.StockMovementRestock:    {MovementCount: 2, QuantityIn: 25},
+		model.StockMovementSale:       {MovementCount: 2, QuantityOut: 7},
+		model.StockMovementAdjustment: {MovementCount: 2, QuantityIn: 2, QuantityOut: 6},
+	}
+	if len(summaries) != len(want) {
+		t.Fatalf("summaries = %+v, want %d types", summaries, len(want))
+	}
+	for _, s := range summaries {
+		w := want[s.MovementType]
+		if s.MovementCount != w.MovementCount || s.QuantityIn != w.QuantityIn || s.QuantityOut != w.QuantityOut {
+			t.Errorf("%s = %d/%d/%d, want %d/%d/%d", s.MovementType, s.MovementCount, s.QuantityIn, s.QuantityOut, w.MovementCount, w.QuantityIn, w.QuantityOut)
+		}
+	}
+
+	// endDate inclusive: batas atas query = awal hari sesudahnya
+	if upper := db.calls[0].args[2].(time.Time); !upper.Equal(end.AddDate(0, 0, 1)) {
+		t.Errorf("upper bound = %v, want %v", upper, end.AddDate(0, 0, 1))
+	}
+}
+
+// ========== STOCK AT TIME ==========
+
+func TestStockAtTime(t *testing.T) {
+	productID := uuid.New()
+	day := func(d, hour int) time.Time { return time.Date(2024, 3, d, hour, 0, 0, 0, time.UTC) }
+
+	// Stok awal 10 saat create (tidak di ledger), lalu: +20 restock, -5 sale, -3 sale, +3 cancel restore, -7 adjustment
+	// Stok sekarang = 10 + 20 - 5 - 3 + 3 - 7 = 18
+	movements := []fakeMovement{
+		{productID: productID, movementType: model.StockMovementRestock, quantity: 20, createdAt: day(2, 9)},
+		{productID: productID, movementType: model.StockMovementSale, quantity: -5, createdAt: day(3, 10)},
+		{productID: productID, movementType: model.StockMovementSale, quantity: -3, createdAt: day(3, 15)},
+		{productID: productID, movementType: model.StockMovementCancellationRestore, quantity: 3, createdAt: day(4, 8)},
+		{productID: productID, movementType: model.StockMovementAdjustment, quantity: -7, createdAt: day(6, 12)},
+		{productID: uuid.New(), movementType: model.StockMovementRestock, quantity: 100, createdAt: day(3, 12)},
+	}
+	currentStock := 18
+
+	db := newFakeDB(t)
+	db.on("SELECT p.stock_quantity - COALESCE", func(args []any) ([][]any, error) {
+		if args[0] != productID {
+			return nil, nil
+		}
+		// Emulasi stok sekarang - SUM(movement dengan created_at >= at)
+		stock := currentStock
+		for _, m := range movements {
+			if m.productID == productID && !m.createdAt.Before(args[1].(time.Time)) {
+				stock -= m.quantity
+			}
+		}
+		return [][]any{{stock}}, nil
+	})
+	repo := NewStockMovementRepo(db, zap.NewNop())
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want int
+	}{
+		{name: "before any movement", at: day(2, 0), want: 10},
+		{name: "after restock", at: day(3, 0), want: 30},
+		{name: "between two sales", at: day(3, 12), want: 25},
+		{name: "movement exactly at time counts as after", at: day(3, 15), want: 25},
+		{name: "after both sales", at: day(4, 0), want: 22},
+		{name: "after cancellation restore", at: day(5, 0), want: 25},
+		{name: "after last movement", at: day(7, 0), want: 18},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stock, err := repo.StockAtTime(context.Background(), productID, tt.at)
+			if err != nil || stock != tt.want {
+				t.Errorf("stock/err = %d/%v, want %d", stock, err, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing product", func(t *testing.T) {
+		if _, err := repo.StockAtTime(context.Background(), uuid.New(), day(3, 0)); err == nil || err.Error() != "product not found" {
+			t.Errorf("error = %v, want product not found", err)
+		}
+	})
+}
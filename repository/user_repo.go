@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"inventory-system/database"
 	"inventory-system/model"
+	"inventory-system/utils"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,6 +20,8 @@ type UserRepo interface {
 	CountAll(ctx context.Context) (int, error)
 	Update(ctx context.Context, user *model.User) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	FindAllForExport(ctx context.Context, fn func(user model.User) error) error
 }
 
 type userRepo struct {
@@ -58,7 +61,7 @@ func (ur *userRepo) Create(ctx context.Context, user *model.User) error {
 		user.UpdatedAt,
 	)
 	if err != nil {
-		ur.log.Error("Failed to create user",
+		utils.LoggerFromContext(ctx).Error("Failed to create user",
 			zap.Error(err),
 			zap.String("email", user.Email),
 		)
@@ -66,7 +69,7 @@ func (ur *userRepo) Create(ctx context.Context, user *model.User) error {
 	}
 
 	// Log success untuk audit trail
-	ur.log.Info("User Created",
+	utils.LoggerFromContext(ctx).Info("User Created",
 		zap.String("id", user.ID.String()),
 		zap.String("email", user.Email),
 	)
@@ -108,7 +111,7 @@ func (ur *userRepo) FindByEmail(ctx context.Context, email string) (*model.User,
 	query := `
 		SELECT id, username, email, password_hash, full_name, role, is_active,
 		       created_at, updated_at, deleted_at
-		FROM users WHERE email = $1 AND deleted_at IS NULL
+		FROM users WHERE LOWER(email) = LOWER($1) AND deleted_at IS NULL
 	`
 
 	var user model.User
@@ -147,7 +150,7 @@ func (ur *userRepo) FindAll(ctx context.Context, limit int, offset int) ([]model
 
 	rows, err := ur.db.Query(ctx, query, limit, offset)
 	if err != nil {
-		ur.log.Error("Failed to query users", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to query users", zap.Error(err))
 		return nil, fmt.Errorf("query users failed: %w", err)
 	}
 	defer rows.Close()
@@ -161,18 +164,18 @@ func (ur *userRepo) FindAll(ctx context.Context, limit int, offset int) ([]model
 			&user.CreatedAt, &user.UpdatedAt, &user.DeletedAt,
 		)
 		if err != nil {
-			ur.log.Error("Failed to scan user", zap.Error(err))
+			utils.LoggerFromContext(ctx).Error("Failed to scan user", zap.Error(err))
 			return nil, fmt.Errorf("scan user failed: %w", err)
 		}
 		users = append(users, user)
 	}
 
 	if err = rows.Err(); err != nil {
-		ur.log.Error("Rows iteration error", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Rows iteration error", zap.Error(err))
 		return nil, fmt.Errorf("rows iteration failed: %w", err)
 	}
 
-	ur.log.Info("Fetched users with pagination",
+	utils.LoggerFromContext(ctx).Info("Fetched users with pagination",
 		zap.Int("limit", limit),
 		zap.Int("offset", offset),
 		zap.Int("count", len(users)))
@@ -187,7 +190,7 @@ func (ur *userRepo) CountAll(ctx context.Context) (int, error) {
 	var count int
 	err := ur.db.QueryRow(ctx, query).Scan(&count)
 	if err != nil {
-		ur.log.Error("Failed to count users", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to count users", zap.Error(err))
 		return 0, fmt.Errorf("count users failed: %w", err)
 	}
 
@@ -217,7 +220,7 @@ func (ur *userRepo) Update(ctx context.Context, user *model.User) error {
 		user.ID,
 	)
 	if err != nil {
-		ur.log.Error("Failed to update user",
+		utils.LoggerFromContext(ctx).Error("Failed to update user",
 			zap.Error(err),
 			zap.String("id", user.ID.String()))
 
@@ -229,7 +232,7 @@ func (ur *userRepo) Update(ctx context.Context, user *model.User) error {
 		return fmt.Errorf("user not found")
 	}
 
-	ur.log.Info("User updated", zap.String("id", user.ID.String()))
+	utils.LoggerFromContext(ctx).Info("User updated", zap.String("id", user.ID.String()))
 	return nil
 }
 
@@ -242,7 +245,7 @@ func (ur *userRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	// Execute delete
 	result, err := ur.db.Exec(ctx, query, now, id)
 	if err != nil {
-		ur.log.Error("Failed to delete user", zap.Error(err), zap.String("id", id.String()))
+		utils.LoggerFromContext(ctx).Error("Failed to delete user", zap.Error(err), zap.String("id", id.String()))
 		return fmt.Errorf("delete user failed: %w", err)
 	}
 
@@ -251,6 +254,88 @@ func (ur *userRepo) Delete(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("user not found")
 	}
 
-	ur.log.Info("User soft deleted", zap.String("id", id.String()))
+	utils.LoggerFromContext(ctx).Info("User soft deleted", zap.String("id", id.String()))
+	return nil
+}
+
+// Restore kosongkan deleted_at, hanya untuk user yang memang sudah di-soft delete
+func (ur *userRepo) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE users SET deleted_at = NULL, updated_at = $1 WHERE id = $2 AND deleted_at IS NOT NULL`
+
+	result, err := ur.db.Exec(ctx, query, time.Now(), id)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to restore user", zap.Error(err), zap.String("id", id.String()))
+		return fmt.Errorf("restore user failed: %w", err)
+	}
+
+	// Tidak ada / belum dihapus
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("deleted user not found")
+	}
+
+	utils.LoggerFromContext(ctx).Info("User restored", zap.String("id", id.String()))
+	return nil
+}
+
+// Ukuran batch export, supaya roster besar tidak di-load sekaligus
+const userExportBatchSize = 500
+
+// FindAllForExport iterasi semua user aktif per batch (keyset created_at, id)
+// password_hash sengaja tidak di-select
+func (ur *userRepo) FindAllForExport(ctx context.Context, fn func(user model.User) error) error {
+	query := `
+		SELECT id, username, email, full_name, role, is_active, created_at
+		FROM users
+		WHERE deleted_at IS NULL AND (created_at, id) > ($1, $2)
+		ORDER BY created_at, id
+		LIMIT $3
+	`
+
+	// Cursor awal: sebelum semua data
+	lastCreatedAt := time.Time{}
+	lastID := uuid.Nil
+	total := 0
+
+	for {
+		rows, err := ur.db.Query(ctx, query, lastCreatedAt, lastID, userExportBatchSize)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to query users for export", zap.Error(err))
+			return fmt.Errorf("query users for export failed: %w", err)
+		}
+
+		batch := make([]model.User, 0, userExportBatchSize)
+		for rows.Next() {
+			var user model.User
+			if err := rows.Scan(
+				&user.ID, &user.Username, &user.Email, &user.FullName,
+				&user.Role, &user.IsActive, &user.CreatedAt,
+			); err != nil {
+				rows.Close()
+				utils.LoggerFromContext(ctx).Error("Failed to scan user for export", zap.Error(err))
+				return fmt.Errorf("scan user failed: %w", err)
+			}
+			batch = append(batch, user)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows iteration failed: %w", err)
+		}
+
+		// Callback dipanggil setelah rows ditutup, koneksi tidak ditahan saat menulis response
+		for _, user := range batch {
+			if err := fn(user); err != nil {
+				return err
+			}
+		}
+
+		total += len(batch)
+		if len(batch) < userExportBatchSize {
+			break
+		}
+		lastCreatedAt = batch[len(batch)-1].CreatedAt
+		lastID = batch[len(batch)-1].ID
+	}
+
+	utils.LoggerFromContext(ctx).Info("Users exported", zap.Int("count", total))
 	return nil
 }
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"inventory-system/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== EXPORT ==========
+
+func TestFindAllForExport(t *testing.T) {
+	// Satu batch penuh + 1 sisa, harus dua query dengan cursor (created_at, id) maju
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	users := make([][]any, userExportBatchSize+1)
+	for i := range users {
+		users[i] = []any{uuid.New(), "user", "user@example.com", "User", "staff", true, base.Add(time.Duration(i) * time.Minute)}
+	}
+
+	db := newFakeDB(t)
+	db.on("FROM users WHERE deleted_at IS NULL AND (created_at, id) > ($1, $2)", func(args []any) ([][]any, error) {
+		cursor := args[0].(time.Time)
+		var page [][]any
+		for _, u := range users {
+			if u[6].(time.Time).After(cursor) || cursor.IsZero() {
+				page = append(page, u)
+			}
+			if len(page) == args[2].(int) {
+				break
+			}
+		}
+		return page, nil
+	})
+
+	var exported []model.User
+	err := NewUserRepo(db, zap.NewNop()).FindAllForExport(context.Background(), func(u model.User) error {
+		exported = append(exported, u)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(exported) != len(users) {
+		t.Fatalf("exported = %d, want %d", len(exported), len(users))
+	}
+	if len(db.calls) != 2 {
+		t.Fatalf("queries = %d, want 2 batches", len(db.calls))
+	}
+	if last := users[userExportBatchSize-1]; db.calls[1].args[0] != last[6] || db.calls[1].args[1] != last[0] {
+		t.Errorf("second batch cursor = %v, want last row of first batch", db.calls[1].args[:2])
+	}
+	if strings.Contains(db.calls[0].sql, "password") {
+		t.Error("export query must not select the password hash")
+	}
+}
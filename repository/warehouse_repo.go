@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"inventory-system/database"
 	"inventory-system/model"
+	"inventory-system/utils"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,9 +15,11 @@ import (
 type WarehouseRepo interface {
 	Create(ctx context.Context, warehouse *model.Warehouse) error
 	FindByID(ctx context.Context, id uuid.UUID) (*model.Warehouse, error)
-	FindAll(ctx context.Context, limit int, offset int) ([]model.Warehouse, error)
-	CountAll(ctx context.Context) (int, error)
+	FindByCode(ctx context.Context, code string) (*model.Warehouse, error)
+	FindAll(ctx context.Context, limit int, offset int, includeDeleted bool) ([]model.Warehouse, error)
+	CountAll(ctx context.Context, includeDeleted bool) (int, error)
 	Update(ctx context.Context, warehouse *model.Warehouse) error
+	SetActive(ctx context.Context, id uuid.UUID, active bool) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
@@ -31,8 +34,8 @@ func NewWarehouseRepo(db database.PgxIface, log *zap.Logger) WarehouseRepo {
 
 func (wr *warehouseRepo) Create(ctx context.Context, warehouse *model.Warehouse) error {
 	query := `
-		INSERT INTO warehouses (id, name, address, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO warehouses (id, code, name, address, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
 	// Generate metadata sebelum insert
@@ -44,13 +47,19 @@ func (wr *warehouseRepo) Create(ctx context.Context, warehouse *model.Warehouse)
 	// Execute INSERT statement
 	_, err := wr.db.Exec(ctx, query,
 		warehouse.ID,
+		warehouse.Code,
 		warehouse.Name,
 		warehouse.Address,
+		warehouse.IsActive,
 		warehouse.CreatedAt,
 		warehouse.UpdatedAt,
 	)
 	if err != nil {
-		wr.log.Error("Failed to create warehouse",
+		// Unique index jadi pengaman terakhir kalau ada create bersamaan
+		if isUniqueViolation(err) {
+			return fmt.Errorf("warehouse code already exists")
+		}
+		utils.LoggerFromContext(ctx).Error("Failed to create warehouse",
 			zap.Error(err),
 			zap.String("name", warehouse.Name),
 		)
@@ -58,7 +67,7 @@ func (wr *warehouseRepo) Create(ctx context.Context, warehouse *model.Warehouse)
 	}
 
 	// Log success untuk audit trail
-	wr.log.Info("Warehouse Created",
+	utils.LoggerFromContext(ctx).Info("Warehouse Created",
 		zap.String("id", warehouse.ID.String()),
 		zap.String("name", warehouse.Name),
 	)
@@ -68,7 +77,7 @@ func (wr *warehouseRepo) Create(ctx context.Context, warehouse *model.Warehouse)
 
 func (wr *warehouseRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Warehouse, error) {
 	query := `
-		SELECT id, name, address, created_at, updated_at, deleted_at
+		SELECT id, code, name, address, is_active, created_at, updated_at, deleted_at
 		FROM warehouses WHERE id = $1 AND deleted_at IS NULL
 	`
 
@@ -77,8 +86,10 @@ func (wr *warehouseRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.War
 	// Query single row berdasarkan ID
 	err := wr.db.QueryRow(ctx, query, id).Scan(
 		&warehouse.ID,
+		&warehouse.Code,
 		&warehouse.Name,
 		&warehouse.Address,
+		&warehouse.IsActive,
 		&warehouse.CreatedAt,
 		&warehouse.UpdatedAt,
 		&warehouse.DeletedAt,
@@ -91,19 +102,45 @@ func (wr *warehouseRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.War
 	return &warehouse, nil
 }
 
-// FindAll dengan pagination
-func (wr *warehouseRepo) FindAll(ctx context.Context, limit int, offset int) ([]model.Warehouse, error) {
+func (wr *warehouseRepo) FindByCode(ctx context.Context, code string) (*model.Warehouse, error) {
 	query := `
-        SELECT id, name, address, created_at, updated_at, deleted_at
+		SELECT id, code, name, address, is_active, created_at, updated_at, deleted_at
+		FROM warehouses WHERE code = $1 AND deleted_at IS NULL
+	`
+
+	var warehouse model.Warehouse
+
+	// Query single row berdasarkan code
+	err := wr.db.QueryRow(ctx, query, code).Scan(
+		&warehouse.ID,
+		&warehouse.Code,
+		&warehouse.Name,
+		&warehouse.Address,
+		&warehouse.IsActive,
+		&warehouse.CreatedAt,
+		&warehouse.UpdatedAt,
+		&warehouse.DeletedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Warehouse not found: %w", err)
+	}
+
+	return &warehouse, nil
+}
+
+// FindAll dengan pagination
+func (wr *warehouseRepo) FindAll(ctx context.Context, limit int, offset int, includeDeleted bool) ([]model.Warehouse, error) {
+	query := fmt.Sprintf(`
+        SELECT id, code, name, address, is_active, created_at, updated_at, deleted_at
         FROM warehouses 
-        WHERE deleted_at IS NULL
+        %s
         ORDER BY created_at DESC
         LIMIT $1 OFFSET $2
-    `
+    `, activeFilter(includeDeleted))
 
 	rows, err := wr.db.Query(ctx, query, limit, offset)
 	if err != nil {
-		wr.log.Error("Failed to query warehouses", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to query warehouses", zap.Error(err))
 		return nil, fmt.Errorf("query warehouses failed: %w", err)
 	}
 	defer rows.Close()
@@ -112,22 +149,22 @@ func (wr *warehouseRepo) FindAll(ctx context.Context, limit int, offset int) ([]
 	for rows.Next() {
 		var warehouse model.Warehouse
 		err := rows.Scan(
-			&warehouse.ID, &warehouse.Name, &warehouse.Address,
+			&warehouse.ID, &warehouse.Code, &warehouse.Name, &warehouse.Address, &warehouse.IsActive,
 			&warehouse.CreatedAt, &warehouse.UpdatedAt, &warehouse.DeletedAt,
 		)
 		if err != nil {
-			wr.log.Error("Failed to scan warehouse", zap.Error(err))
+			utils.LoggerFromContext(ctx).Error("Failed to scan warehouse", zap.Error(err))
 			return nil, fmt.Errorf("scan warehouse failed: %w", err)
 		}
 		warehouses = append(warehouses, warehouse)
 	}
 
 	if err = rows.Err(); err != nil {
-		wr.log.Error("Rows iteration error", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Rows iteration error", zap.Error(err))
 		return nil, fmt.Errorf("rows iteration failed: %w", err)
 	}
 
-	wr.log.Info("Fetched warehouses with pagination",
+	utils.LoggerFromContext(ctx).Info("Fetched warehouses with pagination",
 		zap.Int("limit", limit),
 		zap.Int("offset", offset),
 		zap.Int("count", len(warehouses)))
@@ -136,13 +173,13 @@ func (wr *warehouseRepo) FindAll(ctx context.Context, limit int, offset int) ([]
 }
 
 // CountAll menghitung total warehouses aktif
-func (wr *warehouseRepo) CountAll(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM warehouses WHERE deleted_at IS NULL`
+func (wr *warehouseRepo) CountAll(ctx context.Context, includeDeleted bool) (int, error) {
+	query := `SELECT COUNT(*) FROM warehouses ` + activeFilter(includeDeleted)
 
 	var count int
 	err := wr.db.QueryRow(ctx, query).Scan(&count)
 	if err != nil {
-		wr.log.Error("Failed to count warehouses", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to count warehouses", zap.Error(err))
 		return 0, fmt.Errorf("count warehouses failed: %w", err)
 	}
 
@@ -152,8 +189,8 @@ func (wr *warehouseRepo) CountAll(ctx context.Context) (int, error) {
 func (wr *warehouseRepo) Update(ctx context.Context, warehouse *model.Warehouse) error {
 	query := `
 		UPDATE warehouses
-		SET name = $1, address = $2, updated_at = $3
-		WHERE id = $4 AND deleted_at IS NULL
+		SET code = $1, name = $2, address = $3, updated_at = $4
+		WHERE id = $5 AND deleted_at IS NULL
 	`
 
 	// Update timestamp
@@ -161,13 +198,17 @@ func (wr *warehouseRepo) Update(ctx context.Context, warehouse *model.Warehouse)
 
 	// Execute UPDATE statement
 	result, err := wr.db.Exec(ctx, query,
+		warehouse.Code,
 		warehouse.Name,
 		warehouse.Address,
 		warehouse.UpdatedAt,
 		warehouse.ID,
 	)
 	if err != nil {
-		wr.log.Error("Failed to update warehouse",
+		if isUniqueViolation(err) {
+			return fmt.Errorf("warehouse code already exists")
+		}
+		utils.LoggerFromContext(ctx).Error("Failed to update warehouse",
 			zap.Error(err),
 			zap.String("id", warehouse.ID.String()),
 		)
@@ -179,7 +220,30 @@ func (wr *warehouseRepo) Update(ctx context.Context, warehouse *model.Warehouse)
 		return fmt.Errorf("warehouse not found")
 	}
 
-	wr.log.Info("Warehouse updated", zap.String("id", warehouse.ID.String()))
+	utils.LoggerFromContext(ctx).Info("Warehouse updated", zap.String("id", warehouse.ID.String()))
+	return nil
+}
+
+// SetActive buka/tutup sementara warehouse (data & shelf tetap ada)
+func (wr *warehouseRepo) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	query := `UPDATE warehouses SET is_active = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL`
+
+	result, err := wr.db.Exec(ctx, query, active, time.Now(), id)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to update warehouse status",
+			zap.Error(err),
+			zap.String("id", id.String()),
+		)
+		return fmt.Errorf("update warehouse status failed: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("warehouse not found")
+	}
+
+	utils.LoggerFromContext(ctx).Info("Warehouse status updated",
+		zap.String("id", id.String()),
+		zap.Bool("is_active", active))
 	return nil
 }
 
@@ -191,7 +255,7 @@ func (wr *warehouseRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	// Execute delete
 	result, err := wr.db.Exec(ctx, query, now, id)
 	if err != nil {
-		wr.log.Error("Failed to delete warehouse",
+		utils.LoggerFromContext(ctx).Error("Failed to delete warehouse",
 			zap.Error(err),
 			zap.String("id", id.String()),
 		)
@@ -203,6 +267,6 @@ func (wr *warehouseRepo) Delete(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("warehouse not found")
 	}
 
-	wr.log.Info("Warehouse deleted", zap.String("id", id.String()))
+	utils.LoggerFromContext(ctx).Info("Warehouse deleted", zap.String("id", id.String()))
 	return nil
 }
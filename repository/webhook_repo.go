@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/database"
+	"inventory-system/model"
+	"inventory-system/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WebhookRepo interface {
+	Create(ctx context.Context, webhook *model.Webhook) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Webhook, error)
+	FindAll(ctx context.Context, limit int, offset int) ([]model.Webhook, error)
+	CountAll(ctx context.Context) (int, error)
+	FindActiveByEvent(ctx context.Context, eventType string) ([]model.Webhook, error)
+	Update(ctx context.Context, webhook *model.Webhook) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type webhookRepo struct {
+	db  database.PgxIface
+	log *zap.Logger
+}
+
+func NewWebhookRepo(db database.PgxIface, log *zap.Logger) WebhookRepo {
+	return &webhookRepo{db: db, log: log}
+}
+
+func (wr *webhookRepo) Create(ctx context.Context, webhook *model.Webhook) error {
+	query := `
+		INSERT INTO webhooks (id, url, event_types, secret, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	// Generate metadata sebelum insert
+	now := time.Now()
+	webhook.ID = uuid.New()
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+
+	_, err := wr.db.Exec(ctx, query,
+		webhook.ID,
+		webhook.URL,
+		webhook.EventTypes,
+		webhook.Secret,
+		webhook.IsActive,
+		webhook.CreatedAt,
+		webhook.UpdatedAt,
+	)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to create webhook",
+			zap.Error(err),
+			zap.String("url", webhook.URL),
+		)
+		return fmt.Errorf("create webhook failed: %w", err)
+	}
+
+	// Log success untuk audit trail
+	utils.LoggerFromContext(ctx).Info("Webhook created",
+		zap.String("id", webhook.ID.String()),
+		zap.String("url", webhook.URL),
+	)
+
+	return nil
+}
+
+func (wr *webhookRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Webhook, error) {
+	query := `
+		SELECT id, url, event_types, secret, is_active, created_at, updated_at, deleted_at
+		FROM webhooks WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	var webhook model.Webhook
+	err := wr.db.QueryRow(ctx, query, id).Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.EventTypes,
+		&webhook.Secret,
+		&webhook.IsActive,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+		&webhook.DeletedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("webhook not found: %w", err)
+	}
+
+	return &webhook, nil
+}
+
+// FindAll dengan pagination
+func (wr *webhookRepo) FindAll(ctx context.Context, limit int, offset int) ([]model.Webhook, error) {
+	query := `
+		SELECT id, url, event_types, secret, is_active, created_at, updated_at, deleted_at
+		FROM webhooks
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	return wr.queryWebhooks(ctx, query, limit, offset)
+}
+
+// CountAll menghitung total webhooks aktif (belum dihapus)
+func (wr *webhookRepo) CountAll(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM webhooks WHERE deleted_at IS NULL`
+
+	var count int
+	if err := wr.db.QueryRow(ctx, query).Scan(&count); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to count webhooks", zap.Error(err))
+		return 0, fmt.Errorf("count webhooks failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// FindActiveByEvent ambil subscriber aktif untuk event tertentu
+func (wr *webhookRepo) FindActiveByEvent(ctx context.Context, eventType string) ([]model.Webhook, error) {
+	query := `
+		SELECT id, url, event_types, secret, is_active, created_at, updated_at, deleted_at
+		FROM webhooks
+		WHERE deleted_at IS NULL AND is_active = true AND $1 = ANY(event_types)
+	`
+
+	return wr.queryWebhooks(ctx, query, eventType)
+}
+
+func (wr *webhookRepo) Update(ctx context.Context, webhook *model.Webhook) error {
+	query := `
+		UPDATE webhooks
+		SET url = $1, event_types = $2, secret = $3, is_active = $4, updated_at = $5
+		WHERE id = $6 AND deleted_at IS NULL
+	`
+
+	webhook.UpdatedAt = time.Now()
+
+	result, err := wr.db.Exec(ctx, query,
+		webhook.URL,
+		webhook.EventTypes,
+		webhook.Secret,
+		webhook.IsActive,
+		webhook.UpdatedAt,
+		webhook.ID,
+	)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to update webhook",
+			zap.Error(err),
+			zap.String("id", webhook.ID.String()),
+		)
+		return fmt.Errorf("update webhook failed: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+
+	utils.LoggerFromContext(ctx).Info("Webhook updated", zap.String("id", webhook.ID.String()))
+	return nil
+}
+
+func (wr *webhookRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE webhooks SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`
+
+	result, err := wr.db.Exec(ctx, query, time.Now(), id)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to delete webhook",
+			zap.Error(err),
+			zap.String("id", id.String()),
+		)
+		return fmt.Errorf("delete webhook failed: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+
+	utils.LoggerFromContext(ctx).Info("Webhook deleted", zap.String("id", id.String()))
+	return nil
+}
+
+// queryWebhooks helper: jalankan query list & scan ke slice
+func (wr *webhookRepo) queryWebhooks(ctx context.Context, query string, args ...interface{}) ([]model.Webhook, error) {
+	rows, err := wr.db.Query(ctx, query, args...)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to query webhooks", zap.Error(err))
+		return nil, fmt.Errorf("query webhooks failed: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []model.Webhook
+	for rows.Next() {
+		var webhook model.Webhook
+		err := rows.Scan(
+			&webhook.ID, &webhook.URL, &webhook.EventTypes, &webhook.Secret,
+			&webhook.IsActive, &webhook.CreatedAt, &webhook.UpdatedAt, &webhook.DeletedAt,
+		)
+		if err != nil {
+			utils.LoggerFromContext(ctx).Error("Failed to scan webhook", zap.Error(err))
+			return nil, fmt.Errorf("scan webhook failed: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return webhooks, nil
+}
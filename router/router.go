@@ -1,10 +1,13 @@
 package router
 
 import (
+	"fmt"
+	"inventory-system/database"
 	"inventory-system/handler"
 	"inventory-system/middleware"
 	"inventory-system/model"
 	"inventory-system/service"
+	"inventory-system/utils"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -13,28 +16,66 @@ import (
 
 // SetupRouter configures all HTTP routes with proper middleware and authorization
 // Routes are organized by access level: Public → Authenticated → Admin-only
-func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
+func SetupRouter(svc *service.Service, hdl handler.Handler, config utils.Configuration, dbMonitor *database.Monitor) *chi.Mux {
 	router := chi.NewRouter()
 
 	// ==================== GLOBAL MIDDLEWARE (Applied to all routes) ====================
 	router.Use(chimiddleware.RequestID) // Adds unique ID to each request for tracing
-	router.Use(chimiddleware.RealIP)    // Gets real client IP behind proxies
-	router.Use(chimiddleware.Recoverer) // Recovers from panics and returns 500
-	router.Use(middleware.Logger)       // Logs all HTTP requests with Zap logger
+
+	// Real client IP behind proxies: X-Forwarded-For/X-Real-IP hanya dipercaya dari TRUSTED_PROXIES
+	// (header dari client langsung diabaikan, supaya rate limit per IP tidak bisa di-bypass)
+	router.Use(middleware.RealIP(config.Security.TrustedProxies))
+
+	router.Use(middleware.Recoverer) // Recovers from panics, logs with Zap and returns 500
+	router.Use(middleware.Logger)    // Logs all HTTP requests with Zap logger
+
+	// Error response apapun diganti 503 jika request kena connection error DB (ErrDatabaseUnavailable)
+	router.Use(middleware.DatabaseAvailability)
+
+	router.Use(middleware.SecurityHeaders(config.Security)) // nosniff, frame deny, referrer, HSTS (toggle via config)
+	router.Use(middleware.CORS(config.Security))            // Allowed origins from CORS_ALLOWED_ORIGINS
+
+	// Rate limiter (in-memory), authenticated limiter dipakai bersama oleh route user & admin
+	var publicLimiter, authLimiter middleware.RateLimiter
+	if config.RateLimit.PublicRPM > 0 {
+		publicLimiter = middleware.NewMemoryRateLimiter(config.RateLimit.PublicRPM)
+	}
+	if config.RateLimit.AuthenticatedRPM > 0 {
+		authLimiter = middleware.NewMemoryRateLimiter(config.RateLimit.AuthenticatedRPM)
+	}
 
 	// ==================== PUBLIC ROUTES (No authentication required) ====================
 	router.Group(func(r chi.Router) {
 		// POST /api/auth/login - User authentication endpoint
 		// Returns: JWT token, user info, and token expiry
-		r.Post("/api/auth/login", hdl.Auth.Login)
+		// Rate limited per IP (lower limit, RATE_LIMIT_PUBLIC_RPM)
+		r.With(middleware.RateLimit(publicLimiter)).Post("/api/auth/login", hdl.Auth.Login)
+
+		// GET /api/auth/validate - Cheap "is my token still valid" check
+		// Token tetap wajib di header, tapi tanpa Auth middleware (clean JSON 401)
+		// Rate limited per IP (no user in context yet), authenticated RPM
+		r.With(middleware.RateLimit(authLimiter)).Get("/api/auth/validate", hdl.Auth.Validate)
+
+		// GET /api/config - Non-sensitive runtime config for clients
+		// Page limits, currency & decimal places, min stock defaults, upload size (no secrets)
+		r.With(middleware.RateLimit(publicLimiter)).Get("/api/config", hdl.Config.Get)
+
+		// GET /uploads/* - Serve uploaded files (product images) from local storage
+		// Hanya relevan jika UPLOAD_BASE_URL menunjuk ke server ini, tanpa directory listing (404)
+		r.Handle("/uploads/*", http.StripPrefix("/uploads/", middleware.FileServer(config.Upload.Dir)))
 
 		// GET / - API root endpoint (health check/info)
 		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 			w.Write([]byte("Inventory Management System API v1.0"))
 		})
 
-		// Optional: Add health check endpoint
+		// GET /health - Readiness check, 503 while the database is unavailable
+		// Status di-update oleh health check periodik & connection error saat query
 		r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+			if !dbMonitor.Ready() {
+				utils.ResponseError(w, http.StatusServiceUnavailable, "Database unavailable", nil)
+				return
+			}
 			w.Write([]byte("OK"))
 		})
 	})
@@ -42,7 +83,9 @@ func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
 	// ==================== AUTHENTICATED ROUTES (Requires valid Bearer token) ====================
 	// Accessible to: staff, admin, super_admin (all logged-in users)
 	router.Group(func(r chi.Router) {
-		r.Use(middleware.Auth(svc.Auth)) // Validates Authorization: Bearer <token>
+		r.Use(middleware.RateLimit(publicLimiter)) // Per IP limit sebelum Auth, token random tidak lolos tanpa batas
+		r.Use(middleware.Auth(svc.Auth))           // Validates Authorization: Bearer <token>
+		r.Use(middleware.RateLimit(authLimiter))   // Per user limit (RATE_LIMIT_AUTHENTICATED_RPM), after Auth
 
 		// ========== AUTH MANAGEMENT ==========
 		// POST /api/auth/logout - Invalidates current session token
@@ -66,10 +109,19 @@ func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
 		r.Route("/api/warehouses", func(r chi.Router) {
 			// GET /api/warehouses - List all warehouses with pagination
 			// Query params: ?page=1&limit=10
+			// Admin only: &include_deleted=true (termasuk data soft-deleted)
 			r.Get("/", hdl.Warehouse.FindAll)
 
 			// GET /api/warehouses/{id} - Get specific warehouse details
 			r.Get("/{id}", hdl.Warehouse.FindByID)
+
+			// GET /api/warehouses/{id}/inventory-summary - Product report scoped to one warehouse
+			// Products on soft-deleted shelves are not counted
+			r.Get("/{id}/inventory-summary", hdl.Report.GetWarehouseInventory)
+
+			// GET /api/warehouses/{id}/shelf-distribution - Product count & stock per shelf (heatmap)
+			// Empty shelves are included with zero counts
+			r.Get("/{id}/shelf-distribution", hdl.Report.GetShelfDistribution)
 		})
 
 		// ========== CATEGORY READ ROUTES ==========
@@ -77,10 +129,17 @@ func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
 		r.Route("/api/categories", func(r chi.Router) {
 			// GET /api/categories - List all categories with pagination
 			// Query params: ?page=1&limit=10
+			// Admin only: &include_deleted=true (termasuk data soft-deleted)
 			r.Get("/", hdl.Category.FindAll)
 
+			// GET /api/categories/tree - All active categories nested by parent_id
+			r.Get("/tree", hdl.Category.FindTree)
+
 			// GET /api/categories/{id} - Get specific category details
 			r.Get("/{id}", hdl.Category.FindByID)
+
+			// GET /api/categories/{id}/children - Direct child categories
+			r.Get("/{id}/children", hdl.Category.FindChildren)
 		})
 
 		// ========== SHELF READ ROUTES ==========
@@ -88,12 +147,15 @@ func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
 		r.Route("/api/shelves", func(r chi.Router) {
 			// GET /api/shelves - List all shelves with pagination
 			// Query params: ?page=1&limit=10
+			// Admin only: &include_deleted=true (termasuk data soft-deleted)
 			r.Get("/", hdl.Shelf.FindAll)
 
 			// GET /api/shelves/{id} - Get specific shelf details
 			r.Get("/{id}", hdl.Shelf.FindByID)
 
-			// GET /api/shelves/warehouse/{warehouse_id} - List shelves by warehouse
+			// GET /api/shelves/warehouse/{warehouse_id} - List shelves by warehouse with pagination
+			// Query params: ?page=1&limit=10&search=rak (case-insensitive name filter)
+			// ?all=true returns every shelf as a plain list (no pagination, for dropdowns)
 			r.Get("/warehouse/{warehouse_id}", hdl.Shelf.FindByWarehouseID)
 		})
 
@@ -102,25 +164,82 @@ func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
 		r.Route("/api/products", func(r chi.Router) {
 			// GET /api/products - List all products with pagination
 			// Query params: ?page=1&limit=10&category_id=xxx&shelf_id=xxx
+			// Admin only: &include_deleted=true (termasuk data soft-deleted)
 			r.Get("/", hdl.Product.FindAll)
 
 			// GET /api/products/{id} - Get specific product details
 			r.Get("/{id}", hdl.Product.FindByID)
 
+			// POST /api/products/check-stock - Check availability of many items before checkout
+			// Body: [{ "product_id": "...", "quantity": 2 }], read-only (stock is not reserved)
+			r.Post("/check-stock", hdl.Product.CheckStockBatch)
+
+			// POST /api/products/batch - Fetch several products in one request (cart)
+			// Body: { "ids": ["uuid", ...] }, max INVENTORY_MAX_BATCH_IDS ids (default 100, 422 if exceeded)
+			// Products follow request order (duplicates once), unknown/deleted ids listed in missing_ids
+			r.Post("/batch", hdl.Product.FindByIDs)
+
 			// GET /api/products/low-stock - Get products below minimum stock level
-			// FEATURE REQUIREMENT: Check minimum stock (threshold: 5)
+			// FEATURE REQUIREMENT: Check minimum stock (per-product min_stock_level, default INVENTORY_DEFAULT_MIN_STOCK_LEVEL)
+			// Each item includes stock_deficit (min_stock_level - stock_quantity, 0 when stock == min)
+			// and suggested_order (reorder_quantity - stock_quantity, min 0)
 			r.Get("/low-stock", hdl.Product.FindLowStock)
 
+			// GET /api/products/critical-stock - Stricter than low stock: stock <= min_stock_level * percent
+			// Query params: ?percent=0.5 (default 0.5, must be > 0 and <= 1). Out-of-stock included, most critical first
+			// Products with min_stock_level 0 have no threshold and are excluded
+			r.Get("/critical-stock", hdl.Product.FindCriticalStock)
+
+			// GET /api/products/expiring - Products expiring on or before a date, soonest first
+			// Query params: ?before=2024-12-31 (required). Already expired products included (is_expired: true)
+			// Products without expiry_date are excluded
+			r.Get("/expiring", hdl.Product.FindExpiring)
+
 			// GET /api/products/category/{category_id} - Filter products by category
 			r.Get("/category/{category_id}", hdl.Product.FindByCategoryID)
 
 			// GET /api/products/shelf/{shelf_id} - Filter products by shelf
 			r.Get("/shelf/{shelf_id}", hdl.Product.FindByShelfID)
 
+			// GET /api/products/{id}/sales - Sales history of a product
+			// Query params: ?start_date=2024-01-01&end_date=2024-12-31&page=1&limit=10
+			// Cancelled sales excluded unless &include_cancelled=true
+			r.Get("/{id}/sales", hdl.Product.FindSalesHistory)
+
+			// GET /api/products/{id}/location - Warehouse / shelf breadcrumb for pickers
+			// Shelf/warehouse yang sudah dihapus = null + "orphaned": true
+			r.Get("/{id}/location", hdl.Product.FindLocation)
+
 			// PUT /api/products/{id}/stock - Update product stock quantity
 			// Staff permission: Can update stock (restock/adjustment)
 			// Request body: { "quantity": 50, "notes": "restock from supplier" }
 			r.Put("/{id}/stock", hdl.Product.UpdateStock)
+
+			// GET /api/products/{id}/stock-locations - Stock per shelf/warehouse + total
+			// Single location product returns its primary shelf holding the whole stock
+			r.Get("/{id}/stock-locations", hdl.Product.FindStockLocations)
+
+			// PUT /api/products/{id}/stock-locations - Set stock on one shelf (total = sum of shelves)
+			// Request body: { "shelf_id": "...", "quantity": 20, "notes": "transfer in" }
+			r.Put("/{id}/stock-locations", hdl.Product.SetLocationStock)
+
+			// GET /api/products/{id}/movements/summary - Stock ledger totals per movement type
+			// Query params: ?start_date=2024-01-01&end_date=2024-01-31 (required, max 1 year)
+			// Types: sale, restock, adjustment, cancellation_restore, transfer (always present, 0 if none)
+			r.Get("/{id}/movements/summary", hdl.Product.GetMovementSummary)
+
+			// GET /api/products/{id}/sku-history - SKU changes (old/new, who, when), newest first
+			// Sales & stock movements reference the product id, so renaming a SKU never breaks them
+			r.Get("/{id}/sku-history", hdl.Product.GetSKUHistory)
+
+			// GET /api/products/{id}/stock-at - Stock at the end of a past date (audit)
+			// Query params: ?date=2024-01-31 (required), reconstructed from the stock movement ledger
+			// Dates before the product existed return 0
+			r.Get("/{id}/stock-at", hdl.Product.GetStockAt)
+
+			// POST /api/products/{id}/replenish-request - Ask admin to restock a product
+			// Request body: { "requested_quantity": 100, "notes": "stok menipis" }
+			r.Post("/{id}/replenish-request", hdl.Replenishment.Create)
 		})
 
 		// ========== SALE TRANSACTION ROUTES ==========
@@ -134,8 +253,51 @@ func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
 			// POST /api/sales - Create new sale transaction
 			// Validates stock availability, updates inventory, generates invoice
 			// Request body: { "items": [{"product_id": "uuid", "quantity": 2}] }
+			// Optional per item "shelf_id": deduct from that shelf instead of the total stock
+			// Optional per item "notes" (max 500 chars), e.g. "damaged box, discounted"
+			// Optional "payment_method": cash (default) | card | transfer
+			// Optional "customer_name" (max 100) & "customer_phone" (max 30), kosong = walk-in
 			r.Post("/", hdl.Sale.Create)
 
+			// POST /api/sales/dry-run - Check stock & compute total without creating a sale
+			// Same body as POST /api/sales. Nothing is saved and stock is not reserved
+			// Returns fulfillable, total_amount and per item available/reason
+			r.Post("/dry-run", hdl.Sale.DryRun)
+
+			// GET /api/sales/count - Number of sales only (badge), same filters as the list
+			// Query params: ?status=completed&start_date=2024-01-01&end_date=2024-01-31
+			// Staff: only their own sales are counted
+			r.Get("/count", hdl.Sale.Count)
+
+			// GET /api/sales/my-stats - Sales count, revenue & average of the caller
+			// Query params: ?start_date=2024-01-01&end_date=2024-01-31 (default: current month)
+			// Always scoped to the logged-in user, no user_id param
+			r.Get("/my-stats", hdl.Report.GetMySalesStats)
+
+			// GET /api/sales/my-daily - Caller's sales count, items sold & revenue per day
+			// Query params: ?start_date=2024-01-01&end_date=2024-01-31 (default: current month)
+			// Days without sales are returned with zeros
+			r.Get("/my-daily", hdl.Sale.MyDailySales)
+
+			// GET /api/sales/invoice/{invoice_number} - Get sale details by invoice number
+			// Staff can only retrieve their own sales (ownership checked in handler)
+			r.Get("/invoice/{invoice_number}", hdl.Sale.FindByInvoice)
+
+			// POST /api/sales/{id}/reorder - Create a new sale with the same items
+			// New invoice, current prices, fresh stock check; staff can only reorder their own sales
+			r.Post("/{id}/reorder", hdl.Sale.Reorder)
+
+			// GET /api/sales/{id}/export - Full sale snapshot for archiving
+			// Items with product & category names, cashier name, computed totals
+			// Staff can only export their own sales (checked in handler, {id} is a sale ID)
+			r.Get("/{id}/export", hdl.Sale.Export)
+
+			// PUT /api/sales/{id}/payment - Record amount paid (separate from fulfillment status)
+			// Body: { "amount_paid": 50000 }, total paid so far (not an increment), max = total_amount (422)
+			// payment_status derived: 0 = unpaid, < total = partial, = total = paid; cancelled sale = 409
+			// Staff can only record payment on their own sales (checked in handler)
+			r.Put("/{id}/payment", hdl.Sale.UpdatePayment)
+
 			// Protected endpoints with ownership checking
 			// Staff can only access their own sales, admins can access any
 			r.With(middleware.AllowSelfOrAdmin).Group(func(r chi.Router) {
@@ -144,7 +306,9 @@ func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
 
 				// PUT /api/sales/{id}/status - Update sale status
 				// Allowed statuses: pending, completed, cancelled
-				// Cancelling a completed sale restores product stock
+				// Cancelling a pending/completed sale restores the stock it actually deducted in the same transaction (all or nothing)
+				// A cancelled sale is final, changing it to pending/completed returns 400
+				// Cancellation requires body: { "status": "cancelled", "reason": "..." }
 				r.Put("/{id}/status", hdl.Sale.UpdateStatus)
 			})
 		})
@@ -158,15 +322,30 @@ func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
 
 			// GET /api/reports/sales - Sales report dengan date range
 			// Query params: ?start_date=2024-01-01&end_date=2024-12-31
+			// Optional filter: &user_id=xxx&category_id=xxx (staff: user_id forced to own ID)
+			// Max range REPORT_MAX_RANGE_DAYS (default 365 hari, end_date inclusive)
 			r.Get("/sales", hdl.Report.GetSalesReport)
 		})
+
+		// ==================== DASHBOARD ROUTES ====================
+		// GET /api/dashboard - Ringkasan home screen dalam satu call
+		// Staff: nominal (revenue & inventory value) di-redact
+		r.Get("/api/dashboard", hdl.Dashboard.GetSummary)
+
+		// ==================== ROLE ROUTES ====================
+		// GET /api/roles - Every role with its permissions (for role pickers)
+		// Derived from the same model.User permission methods used for enforcement
+		r.Get("/api/roles", hdl.Role.List)
 	})
 
 	// ==================== ADMIN ROUTES (Admin & Super Admin only) ====================
 	// Accessible to: admin, super_admin (requires elevated privileges)
 	router.Group(func(r chi.Router) {
+		r.Use(middleware.RateLimit(publicLimiter))                           // Per IP limit (shared), before Auth
 		r.Use(middleware.Auth(svc.Auth))                                     // Requires authentication
+		r.Use(middleware.RateLimit(authLimiter))                             // Per user limit (shared)
 		r.Use(middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin)) // Role check
+		r.Use(middleware.VerboseLogger(config.VerboseLog))                   // Body mutasi admin (opt-in LOG_VERBOSE_ADMIN)
 
 		// ========== USER MANAGEMENT ROUTES ==========
 		// Full CRUD operations for user management
@@ -175,6 +354,14 @@ func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
 			// Query params: ?page=1&limit=10
 			r.Get("/", hdl.User.FindAll)
 
+			// GET /api/admin/users/by-email - Look up one user by exact email
+			// Query params: ?email=user@example.com, 404 if not found
+			r.Get("/by-email", hdl.User.FindByEmail)
+
+			// GET /api/admin/users/export - Roster export (no password hash)
+			// Query params: ?format=csv (default) | json
+			r.Get("/export", hdl.User.Export)
+
 			// POST /api/admin/users - Create new user account
 			// Admin can create admin/staff, Super Admin can create any role
 			// Request body includes: username, email, password, role, etc.
@@ -182,8 +369,22 @@ func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
 
 			// DELETE /api/admin/users/{id} - Soft delete user account
 			r.Delete("/{id}", hdl.User.Delete)
+
+			// GET /api/admin/users/{id}/login-history - Sessions of a user, newest first
+			// Query params: ?page=1&limit=10. Includes revoked & expired sessions (is_active false)
+			// ip_address/user_agent are null for sessions created before they were recorded
+			r.Get("/{id}/login-history", hdl.User.LoginHistory)
+
+			// POST /api/admin/users/{id}/restore - Undo soft delete of a user (Super Admin only)
+			// 404 if the user is not deleted. Old sessions stay revoked, user must log in again
+			r.With(middleware.RequireRole(model.RoleSuperAdmin)).Post("/{id}/restore", hdl.User.Restore)
 		})
 
+		// ========== SESSION MONITORING ROUTES ==========
+		// GET /api/admin/sessions/active-counts - Users with more active sessions than SESSION_ACTIVE_ALERT_THRESHOLD
+		// Most active sessions first, helps spot shared or compromised accounts
+		r.Get("/api/admin/sessions/active-counts", hdl.Auth.ActiveSessionCounts)
+
 		// ========== WAREHOUSE MANAGEMENT ROUTES ==========
 		// Full CRUD for warehouse master data
 		r.Route("/api/admin/warehouses", func(r chi.Router) {
@@ -195,6 +396,15 @@ func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
 
 			// DELETE /api/admin/warehouses/{id} - Delete warehouse (soft delete)
 			r.Delete("/{id}", hdl.Warehouse.Delete)
+
+			// POST /api/admin/warehouses/{id}/deactivate - Temporarily close warehouse (not deleted)
+			// Optional body: { "active": true } to reopen. Shelves of inactive warehouses can't take new products
+			r.Post("/{id}/deactivate", hdl.Warehouse.Deactivate)
+
+			// POST /api/admin/warehouses/{id}/shelves/bulk - Create many shelves at once
+			// Body: [{"code": "A-01", "name": "Rak A 01"}], all-or-nothing (transaction)
+			// Shelf code must be unique within the warehouse
+			r.Post("/{id}/shelves/bulk", hdl.Shelf.BulkCreate)
 		})
 
 		// ========== CATEGORY MANAGEMENT ROUTES ==========
@@ -204,10 +414,20 @@ func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
 			r.Post("/", hdl.Category.Create)
 
 			// PUT /api/admin/categories/{id} - Update category details
+			// "parent_id": "" moves category to root, parent cannot be itself or a descendant
 			r.Put("/{id}", hdl.Category.Update)
 
 			// DELETE /api/admin/categories/{id} - Delete category (soft delete)
+			// 409 jika masih punya child category (pindahkan/hapus child dulu)
 			r.Delete("/{id}", hdl.Category.Delete)
+
+			// GET /api/admin/categories/{id}/impact - Preview before delete
+			// Active products still in the category (reassign first) + child category count, 404 if missing
+			r.Get("/{id}/impact", hdl.Category.DeleteImpact)
+
+			// POST /api/admin/categories/{id}/merge - Merge duplicate category into another
+			// Body: { "into": "<target_id>" }, products moved & source soft-deleted in one transaction
+			r.Post("/{id}/merge", hdl.Category.Merge)
 		})
 
 		// ========== SHELF MANAGEMENT ROUTES ==========
@@ -230,8 +450,51 @@ func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
 			// Requires: category_id, shelf_id, name, prices, stock info
 			r.Post("/", hdl.Product.Create)
 
+			// POST /api/admin/products/validate - Dry run of create (same body), nothing is saved
+			// Returns { "valid": true } or { "valid": false, "errors": { "Field": "message" } }
+			r.Post("/validate", hdl.Product.Validate)
+
+			// POST /api/admin/products/recategorize - Move many products to one category
+			// Body: { "product_ids": [...], "category_id": "..." }, all-or-nothing
+			r.Post("/recategorize", hdl.Product.Recategorize)
+
+			// POST /api/admin/products/price-adjust - Change prices of all products in a category by percentage
+			// Body: { "category_id": "...", "unit_price_percent": 5, "cost_price_percent": 3 } (negative = decrease)
+			// Satu transaction, tiap produk yang berubah tercatat di price_history
+			r.Post("/price-adjust", hdl.Product.AdjustPrices)
+
+			// POST /api/admin/products/status/bulk - Set status (active/discontinued) for many products
+			// Body: { "product_ids": [...], "status": "discontinued" }, all-or-nothing
+			r.Post("/status/bulk", hdl.Product.BulkUpdateStatus)
+
+			// POST /api/admin/products/min-stock/bulk - Set min stock level for many products
+			// Body: [{ "product_id": "...", "min_stock_level": 10 }], per-item results (missing product = failed)
+			r.Post("/min-stock/bulk", hdl.Product.BulkUpdateMinStock)
+
+			// GET /api/admin/products/stale - Products not updated since a date (potential dead stock)
+			// Query params: ?before=2024-01-01 (required), oldest updated_at first, deleted excluded
+			r.Get("/stale", hdl.Product.FindStaleStock)
+
+			// GET /api/admin/products/newly-low - Preview which products become low stock under a new threshold
+			// Query params: ?threshold=20 (required, 0..INVENTORY_MAX_MIN_STOCK_LEVEL), read-only
+			// Hanya produk yang sekarang belum low (stock > min_stock_level) tapi stock <= threshold
+			r.Get("/newly-low", hdl.Product.FindNewlyLowStock)
+
+			// POST /api/admin/products/{id}/duplicate - Clone product (stock 0, name "+ (Copy)")
+			// Optional body: { "name": "custom name" }
+			r.Post("/{id}/duplicate", hdl.Product.Duplicate)
+
+			// POST /api/admin/products/{id}/discontinue - Stop selling product (tetap ada di report)
+			// Optional body: { "discontinued": false } to reactivate
+			r.Post("/{id}/discontinue", hdl.Product.Discontinue)
+
+			// POST /api/admin/products/{id}/image - Upload product image
+			// multipart/form-data field "image" (jpeg/png/gif/webp, max UPLOAD_MAX_SIZE_MB)
+			r.Post("/{id}/image", hdl.Product.UploadImage)
+
 			// PUT /api/admin/products/{id} - Update product details
 			// Staff cannot access this - only product stock update
+			// "sku" must be unique among active products (409), changes are kept in sku history
 			r.Put("/{id}", hdl.Product.Update)
 
 			// DELETE /api/admin/products/{id} - Delete product (soft delete)
@@ -239,34 +502,186 @@ func SetupRouter(svc *service.Service, hdl handler.Handler) *chi.Mux {
 			r.Delete("/{id}", hdl.Product.Delete)
 		})
 
+		// ========== STOCK COUNT (STOCK OPNAME) ==========
+		// POST /api/admin/stock-count - Variance report: expected (system) vs counted (physical)
+		// Body: [{ "product_id": "...", "counted": 10 }], missing product = found false
+		// ?apply=true sets stock to counted (all-or-nothing) and records adjustment movements
+		r.Post("/api/admin/stock-count", hdl.Product.StockCount)
+
+		// ========== RESTOCK (RECEIVE SHIPMENT) ==========
+		// POST /api/admin/restock - Add received quantities to many products at once
+		// Body: { "reference": "PO-123", "items": [{ "product_id": "...", "quantity": 10 }] }
+		// All-or-nothing (404 if any product is missing); movements are "restock" with notes = reference
+		r.Post("/api/admin/restock", hdl.Product.Restock)
+
+		// ========== STOCK MOVEMENT FEED ==========
+		// GET /api/admin/stock-movements - Store-wide stock ledger, newest first, with product & user names
+		// Query params: ?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&type=restock&page=1&limit=10 (all optional)
+		// user_id/username are null for movements recorded before the user was tracked
+		r.Get("/api/admin/stock-movements", hdl.Product.MovementFeed)
+
+		// ========== STOCK TRANSFER ==========
+		// POST /api/admin/stock/transfer - Move stock between shelves (can be different warehouses)
+		// Body: { "product_id": "...", "from_shelf_id": "...", "to_shelf_id": "...", "quantity": 5, "notes": "..." }
+		// One transaction, total stock unchanged; records paired transfer movements (-source, +destination)
+		// Quantity above the source shelf stock is rejected (409)
+		r.Post("/api/admin/stock/transfer", hdl.Product.TransferStock)
+
+		// ========== INVENTORY ALERTS ==========
+		// GET /api/admin/alerts/inventory - Daily digest: low_stock & out_of_stock sections
+		// Each item: product, category, current stock, min level, deficit, shelf/warehouse location
+		// low_stock sorted by stock/min ratio, out_of_stock by deficit (most severe first)
+		r.Get("/api/admin/alerts/inventory", hdl.Alert.GetInventoryDigest)
+
+		// ========== WEBHOOK MANAGEMENT ROUTES ==========
+		// Outbound webhook subscribers (sale.created, sale.status_changed, low_stock)
+		// Payload di-sign HMAC-SHA256 di header X-Signature
+		r.Route("/api/admin/webhooks", func(r chi.Router) {
+			// POST /api/admin/webhooks - Register webhook
+			// Request body: { "url": "https://...", "event_types": ["sale.created"], "secret": "..." }
+			r.Post("/", hdl.Webhook.Create)
+
+			// GET /api/admin/webhooks - List webhooks with pagination
+			r.Get("/", hdl.Webhook.FindAll)
+
+			// GET /api/admin/webhooks/{id} - Get webhook details
+			r.Get("/{id}", hdl.Webhook.FindByID)
+
+			// PUT /api/admin/webhooks/{id} - Update url/events/secret/active
+			r.Put("/{id}", hdl.Webhook.Update)
+
+			// DELETE /api/admin/webhooks/{id} - Delete webhook (soft delete)
+			r.Delete("/{id}", hdl.Webhook.Delete)
+		})
+
+		// ========== REPLENISHMENT REVIEW ROUTES ==========
+		// Restock requests submitted by staff
+		r.Route("/api/admin/replenishment-requests", func(r chi.Router) {
+			// GET /api/admin/replenishment-requests - List requests (pending first, oldest first)
+			// Query params: ?page=1&limit=10&status=pending|approved|rejected
+			r.Get("/", hdl.Replenishment.FindAll)
+
+			// PUT /api/admin/replenishment-requests/{id} - Approve or reject a pending request
+			// Request body: { "status": "approved", "notes": "..." }, approving does not change stock
+			r.Put("/{id}", hdl.Replenishment.Review)
+		})
+
 		// ========== SALE ADMINISTRATION ROUTES ==========
 		// Admin-only sale features (view all sales, reports)
 		r.Route("/api/admin/sales", func(r chi.Router) {
 			// GET /api/admin/sales - View ALL sales (no ownership filter)
 			// Admin can see sales from all users, not just their own
 			// Query params: ?page=1&limit=10
+			// Optional filters: &status=completed&start_date=2024-01-01&end_date=2024-01-31&min_amount=100000&max_amount=500000
 			r.Get("/", hdl.Sale.FindAll)
+
+			// GET /api/admin/sales/status-breakdown - Number of pending/completed/cancelled sales
+			// Query params: ?start_date=2024-01-01&end_date=2024-01-31 (required, max 1 year)
+			// All statuses always present (0 if none) with percentage of the period total
+			r.Get("/status-breakdown", hdl.Sale.StatusBreakdown)
+
+			// GET /api/admin/sales/full-export - Every sale with its line items, product names & cashier (ERP import)
+			// Query params: ?start_date=2024-01-01&end_date=2024-01-31 (required, max 1 year)&format=csv (default) | json
+			// csv = one row per item, json = array of sales with items; streamed, all statuses included
+			r.Get("/full-export", hdl.Sale.FullExport)
+
+			// POST /api/admin/sales/recalculate-all - Fix all totals that differ from item sum
+			// Returns number of sales changed
+			r.Post("/recalculate-all", hdl.Sale.RecalculateAll)
+
+			// POST /api/admin/sales/{id}/recalculate - Re-sum items into total_amount
+			// Returns corrected sale + previous_total
+			r.Post("/{id}/recalculate", hdl.Sale.Recalculate)
+
+			// PUT /api/admin/sales/{id}/reassign - Move a sale to another cashier (wrong account correction)
+			// Body: { "user_id": "..." }, target user must exist and be active (422 if inactive)
+			// Change is recorded in the log with previous/new user and the admin who made it
+			r.Put("/{id}/reassign", hdl.Sale.Reassign)
 		})
 
+		// GET /api/admin/sale-items - Flat ledger of every sale line (accounting), newest first
+		// Query params: ?page=1&limit=10&start_date=2024-01-01&end_date=2024-01-31
+		// Cancelled sales excluded unless &include_cancelled=true
+		r.Get("/api/admin/sale-items", hdl.Sale.SaleItems)
+
 		// ==================== ADMIN REPORT ROUTES ====================
 		// Revenue report hanya untuk admin & super_admin
 		r.Route("/api/admin/reports", func(r chi.Router) {
 			// GET /api/admin/reports/revenue - Revenue analytics report
 			// Query params: ?start_date=2024-01-01&end_date=2024-12-31&group_by=month
+			// Optional &payment_status=unpaid|partial|paid (e.g. paid = revenue actually collected)
 			// Staff tidak boleh akses report revenue (sesuai requirement)
+			// Max range REPORT_MAX_RANGE_DAYS (default 365 hari, end_date inclusive)
 			r.Get("/revenue", hdl.Report.GetRevenueReport)
+
+			// GET /api/admin/reports/revenue/compare - Current vs previous period
+			// Query params: ?period=day|week|month (default month), delta null jika periode lalu 0
+			r.Get("/revenue/compare", hdl.Report.CompareRevenue)
+
+			// GET /api/admin/reports/sales-by-category - Sales aggregated per category
+			// Query params: ?start_date=2024-01-01&end_date=2024-12-31&include_empty=true
+			r.Get("/sales-by-category", hdl.Report.GetSalesByCategory)
+
+			// GET /api/admin/reports/inventory - Inventory valuation export per product
+			// Query params: ?format=csv (default) | json
+			r.Get("/inventory", hdl.Report.GetInventoryValuation)
+
+			// GET /api/admin/reports/turnover - Inventory turnover ratio per product
+			// Query params: ?start_date=2024-01-01&end_date=2024-12-31, turnover null jika average stock 0
+			r.Get("/turnover", hdl.Report.GetInventoryTurnover)
+
+			// GET /api/admin/reports/trend - Sales count & revenue time series for charts
+			// Query params: ?start_date=2024-01-01&end_date=2024-01-31&interval=day|week|month (default day)
+			// Bucket tanpa penjualan tetap dikembalikan dengan nilai 0
+			r.Get("/trend", hdl.Report.GetSalesTrend)
+
+			// GET /api/admin/reports/sales-by-hour - Sales count & revenue per hour of day (0-23)
+			// Query params: ?start_date=2024-01-01&end_date=2024-01-31 (max 1 year)
+			// Selalu 24 bucket (jam kosong = 0) + peak_hour
+			r.Get("/sales-by-hour", hdl.Report.GetSalesByHour)
+
+			// GET /api/admin/reports/revenue-by-payment - Completed sales count & revenue per payment method
+			// Query params: ?start_date=2024-01-01&end_date=2024-01-31 (max 1 year)
+			// Semua cara bayar (cash, card, transfer) selalu muncul, yang tidak dipakai = 0
+			r.Get("/revenue-by-payment", hdl.Report.GetRevenueByPaymentMethod)
+
+			// GET /api/admin/reports/top-customers - Customers ranked by total completed spend
+			// Query params: ?start_date=2024-01-01&end_date=2024-01-31&limit=10 (limit max 100)
+			// Dikelompokkan per customer_phone (fallback nama), sale walk-in tidak dihitung
+			r.Get("/top-customers", hdl.Report.GetTopCustomers)
+
+			// GET /api/admin/reports/fulfillment-time - Average & median seconds from created to completed
+			// Query params: ?start_date=2024-01-01&end_date=2024-01-31 (filter by created_at)
+			// Hanya sale yang completed lewat status update (pending -> completed), null jika kosong
+			r.Get("/fulfillment-time", hdl.Report.GetFulfillmentTime)
+
+			// GET /api/admin/reports/monthly-snapshot - Month-end stock per product (from the stock ledger)
+			// Query params: ?month=2024-01 (required, not in the future)
+			// Bulan selesai: disimpan di inventory_snapshots saat pertama diminta (source stored/computed)
+			// Bulan berjalan: posisi sekarang, tidak disimpan (source live)
+			r.Get("/monthly-snapshot", hdl.Report.GetMonthlySnapshot)
 		})
+
+		// ==================== ADMIN LOG LEVEL ROUTES ====================
+		// GET /api/admin/log-level - Current logger level
+		r.Get("/api/admin/log-level", hdl.LogLevel.Get)
+
+		// POST /api/admin/log-level - Change logger level at runtime (not persisted)
+		// Request body: { "level": "debug" | "info" | "warn" | "error" }
+		r.Post("/api/admin/log-level", hdl.LogLevel.Set)
 	})
 
 	// ==================== ERROR HANDLERS ====================
 	// Handle non-existent routes
 	router.NotFound(func(w http.ResponseWriter, r *http.Request) {
-		http.Error(w, "404 - Not Found", http.StatusNotFound)
+		utils.ResponseError(w, http.StatusNotFound,
+			fmt.Sprintf("Route not found: %s %s", r.Method, r.URL.Path), nil)
 	})
 
 	// Handle unsupported HTTP methods
 	router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
-		http.Error(w, "405 - Method Not Allowed", http.StatusMethodNotAllowed)
+		utils.ResponseError(w, http.StatusMethodNotAllowed,
+			fmt.Sprintf("Method %s not allowed for %s", r.Method, r.URL.Path), nil)
 	})
 
 	return router
@@ -0,0 +1,301 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"inventory-system/database"
+	"inventory-system/handler"
+	"inventory-system/model"
+	"inventory-system/service"
+	"inventory-system/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKES ==========
+
+// fakeAuthService token -> user, token lain ditolak seperti session tidak ditemukan
+type fakeAuthService struct {
+	service.AuthService
+	users map[uuid.UUID]*model.User
+}
+
+func (f *fakeAuthService) ValidateToken(ctx context.Context, token uuid.UUID) (*model.User, error) {
+	if u, ok := f.users[token]; ok {
+		return u, nil
+	}
+	return nil, fmt.Errorf("invalid session")
+}
+
+// testRouter router lengkap dengan satu token per role
+// Service selain Auth nil: handler yang sampai ke service panic dan jadi 500 lewat Recoverer,
+// cukup untuk membedakan lolos/ditolak role check
+type testRouter struct {
+	mux    *chi.Mux
+	tokens map[model.UserRole]uuid.UUID
+}
+
+func newTestRouter(t *testing.T) *testRouter {
+	t.Helper()
+	return newTestRouterWithConfig(t, utils.Configuration{})
+}
+
+// newTestRouterWithConfig sama seperti newTestRouter, dengan config sendiri (mis. rate limit)
+func newTestRouterWithConfig(t *testing.T, config utils.Configuration) *testRouter {
+	t.Helper()
+
+	// Recoverer menulis ke logger global saat handler tanpa service panic
+	previous := utils.Logger
+	utils.Logger = zap.NewNop()
+	t.Cleanup(func() { utils.Logger = previous })
+
+	auth := &fakeAuthService{users: map[uuid.UUID]*model.User{}}
+	tokens := map[model.UserRole]uuid.UUID{}
+	for _, role := range []model.UserRole{model.RoleStaff, model.RoleAdmin, model.RoleSuperAdmin} {
+		token := uuid.New()
+		auth.users[token] = &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: role, IsActive: true}
+		tokens[role] = token
+	}
+
+	svc := &service.Service{Auth: auth}
+	hdl := handler.NewHandlers(svc, zap.NewNop(), config)
+	return &testRouter{
+		mux:    SetupRouter(svc, hdl, config, database.NewMonitor(nil, zap.NewNop())),
+		tokens: tokens,
+	}
+}
+
+// do kirim request sebagai role (kosong = tanpa token)
+func (tr *testRouter) do(method, path, body string, role model.UserRole) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if role != "" {
+		r.Header.Set("Authorization", "Bearer "+tr.tokens[role].String())
+	}
+	w := httptest.NewRecorder()
+	tr.mux.ServeHTTP(w, r)
+	return w
+}
+
+// ========== LOG LEVEL ==========
+
+func TestLogLevelRoutesRoles(t *testing.T) {
+	tr := newTestRouter(t)
+
+	tests := []struct {
+		name       string
+		role       model.UserRole
+		wantStatus int
+	}{
+		{name: "no token", wantStatus: http.StatusUnauthorized},
+		{name: "staff", role: model.RoleStaff, wantStatus: http.StatusForbidden},
+		{name: "admin", role: model.RoleAdmin, wantStatus: http.StatusOK},
+		{name: "super admin", role: model.RoleSuperAdmin, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := tr.do(http.MethodGet, "/api/admin/log-level", "", tt.role); w.Code != tt.wantStatus {
+				t.Errorf("GET status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			// Level yang sedang aktif dikirim ulang supaya state global tidak berubah
+			body := fmt.Sprintf(`{"level":%q}`, utils.CurrentLogLevel())
+			if w := tr.do(http.MethodPost, "/api/admin/log-level", body, tt.role); w.Code != tt.wantStatus {
+				t.Errorf("POST status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// ========== RATE LIMIT ==========
+
+// doWithToken kirim request dengan token apapun (tidak harus terdaftar)
+func (tr *testRouter) doWithToken(method, path string, token uuid.UUID) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	r.Header.Set("Authorization", "Bearer "+token.String())
+	w := httptest.NewRecorder()
+	tr.mux.ServeHTTP(w, r)
+	return w
+}
+
+// TestRateLimitBeforeAuth token random per request tetap kena limit per IP sebelum Auth
+func TestRateLimitBeforeAuth(t *testing.T) {
+	for _, path := range []string{"/api/roles", "/api/admin/log-level"} {
+		t.Run(path, func(t *testing.T) {
+			tr := newTestRouterWithConfig(t, utils.Configuration{RateLimit: utils.RateLimitConfig{PublicRPM: 2}})
+			for i := 0; i < 2; i++ {
+				if w := tr.doWithToken(http.MethodGet, path, uuid.New()); w.Code != http.StatusUnauthorized {
+					t.Fatalf("request %d status = %d, want %d", i+1, w.Code, http.StatusUnauthorized)
+				}
+			}
+			w := tr.doWithToken(http.MethodGet, path, uuid.New())
+			if w.Code != http.StatusTooManyRequests {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+			}
+			if w.Header().Get("Retry-After") == "" {
+				t.Error("Retry-After header missing")
+			}
+		})
+	}
+
+	// Bucket per IP sama dengan route publik (/api/config, login)
+	tr := newTestRouterWithConfig(t, utils.Configuration{RateLimit: utils.RateLimitConfig{PublicRPM: 2}})
+	if w := tr.do(http.MethodGet, "/api/config", "", ""); w.Code == http.StatusTooManyRequests {
+		t.Fatalf("first public request got %d", w.Code)
+	}
+	tr.doWithToken(http.MethodGet, "/api/roles", uuid.New())
+	if w := tr.do(http.MethodGet, "/api/config", "", ""); w.Code != http.StatusTooManyRequests {
+		t.Errorf("status after invalid tokens = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+}
+
+// TestRateLimitPerUserAfterAuth limit per user tetap jalan setelah Auth, user lain dari IP yang sama tidak ikut kena
+func TestRateLimitPerUserAfterAuth(t *testing.T) {
+	tr := newTestRouterWithConfig(t, utils.Configuration{RateLimit: utils.RateLimitConfig{PublicRPM: 100, AuthenticatedRPM: 1}})
+
+	if w := tr.do(http.MethodGet, "/api/admin/log-level", "", model.RoleAdmin); w.Code != http.StatusOK {
+		t.Fatalf("first request status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w := tr.do(http.MethodGet, "/api/admin/log-level", "", model.RoleAdmin); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second request status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w := tr.do(http.MethodGet, "/api/admin/log-level", "", model.RoleSuperAdmin); w.Code != http.StatusOK {
+		t.Errorf("other user status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+// ========== NOT FOUND / METHOD NOT ALLOWED ==========
+
+func TestFallbackResponsesAreJSON(t *testing.T) {
+	tr := newTestRouter(t)
+	id := uuid.NewString()
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		role        model.UserRole
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "unknown route", method: http.MethodGet, path: "/api/nope", wantStatus: http.StatusNotFound, wantMessage: "Route not found: GET /api/nope"},
+		{name: "unknown route in subrouter", method: http.MethodGet, path: "/api/admin/reports/nope", role: model.RoleAdmin, wantStatus: http.StatusNotFound, wantMessage: "Route not found: GET /api/admin/reports/nope"},
+		{name: "wrong method", method: http.MethodDelete, path: "/api/auth/login", wantStatus: http.StatusMethodNotAllowed, wantMessage: "Method DELETE not allowed for /api/auth/login"},
+		{name: "wrong method in subrouter", method: http.MethodPatch, path: "/api/admin/categories/" + id, role: model.RoleAdmin, wantStatus: http.StatusMethodNotAllowed, wantMessage: "Method PATCH not allowed for /api/admin/categories/" + id},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := tr.do(tt.method, tt.path, "", tt.role)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("content type = %q, want application/json", ct)
+			}
+
+			var resp utils.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("body %q is not JSON: %v", w.Body.String(), err)
+			}
+			if resp.Status || resp.Message != tt.wantMessage {
+				t.Errorf("status/message = %v/%q, want false/%q", resp.Status, resp.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+// ========== ROLE MATRIX ==========
+
+// TestRoleMatrixMatchesRoutes matrix dari GET /api/roles harus sama dengan yang di-enforce route
+// Allowed = lolos auth & role check (status apapun selain 401/403, handler tanpa service boleh 500)
+func TestRoleMatrixMatchesRoutes(t *testing.T) {
+	tr := newTestRouter(t)
+	id := uuid.NewString()
+
+	type permissions struct {
+		Role                   model.UserRole   `json:"role"`
+		CanManageUsers         bool             `json:"can_manage_users"`
+		CanManageMasterData    bool             `json:"can_manage_master_data"`
+		CanDeleteMasterData    bool             `json:"can_delete_master_data"`
+		CanAccessRevenueReport bool             `json:"can_access_revenue_report"`
+		CanUpdateStock         bool             `json:"can_update_stock"`
+		CanCreateSale          bool             `json:"can_create_sale"`
+		CanCreateUserRoles     []model.UserRole `json:"can_create_user_roles"`
+	}
+
+	w := tr.do(http.MethodGet, "/api/roles", "", model.RoleStaff)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /api/roles status = %d, want 200", w.Code)
+	}
+	var resp struct {
+		Data []permissions `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	matrix := make(map[model.UserRole]permissions, len(resp.Data))
+	for _, p := range resp.Data {
+		matrix[p.Role] = p
+	}
+
+	routes := []struct {
+		name    string
+		method  string
+		path    string
+		allowed func(p permissions) bool
+	}{
+		{name: "list users", method: http.MethodGet, path: "/api/admin/users", allowed: func(p permissions) bool { return p.CanManageUsers }},
+		{name: "create user", method: http.MethodPost, path: "/api/admin/users", allowed: func(p permissions) bool { return p.CanManageUsers }},
+		{name: "restore user", method: http.MethodPost, path: "/api/admin/users/" + id + "/restore", allowed: func(p permissions) bool { return p.Role == model.RoleSuperAdmin }},
+		{name: "create category", method: http.MethodPost, path: "/api/admin/categories", allowed: func(p permissions) bool { return p.CanManageMasterData }},
+		{name: "update product", method: http.MethodPut, path: "/api/admin/products/" + id, allowed: func(p permissions) bool { return p.CanManageMasterData }},
+		{name: "delete warehouse", method: http.MethodDelete, path: "/api/admin/warehouses/" + id, allowed: func(p permissions) bool { return p.CanDeleteMasterData }},
+		{name: "revenue report", method: http.MethodGet, path: "/api/admin/reports/revenue", allowed: func(p permissions) bool { return p.CanAccessRevenueReport }},
+		{name: "update stock", method: http.MethodPut, path: "/api/products/" + id + "/stock", allowed: func(p permissions) bool { return p.CanUpdateStock }},
+		{name: "create sale", method: http.MethodPost, path: "/api/sales", allowed: func(p permissions) bool { return p.CanCreateSale }},
+	}
+
+	for _, role := range []model.UserRole{model.RoleStaff, model.RoleAdmin, model.RoleSuperAdmin} {
+		p, ok := matrix[role]
+		if !ok {
+			t.Fatalf("role %s missing from matrix", role)
+		}
+
+		// Matrix harus sama dengan method permission model.User
+		u := &model.User{Role: role}
+		want := permissions{
+			Role:                   role,
+			CanManageUsers:         u.CanManageUsers(),
+			CanManageMasterData:    u.CanManageMasterData(),
+			CanDeleteMasterData:    u.CanDeleteMasterData(),
+			CanAccessRevenueReport: u.CanAccessRevenueReport(),
+			CanUpdateStock:         u.CanUpdateStock(),
+			CanCreateSale:          u.CanCreateSale(),
+		}
+		for _, target := range model.UserRoles {
+			if u.CanCreateUserWithRole(target) {
+				want.CanCreateUserRoles = append(want.CanCreateUserRoles, target)
+			}
+		}
+		if fmt.Sprint(p) != fmt.Sprint(want) {
+			t.Errorf("%s matrix = %+v, want %+v", role, p, want)
+		}
+
+		for _, rt := range routes {
+			t.Run(string(role)+"/"+rt.name, func(t *testing.T) {
+				w := tr.do(rt.method, rt.path, "", role)
+				denied := w.Code == http.StatusUnauthorized || w.Code == http.StatusForbidden
+				if allowed := rt.allowed(p); denied == allowed {
+					t.Errorf("%s %s status = %d, want allowed=%v", rt.method, rt.path, w.Code, allowed)
+				}
+			})
+		}
+	}
+}
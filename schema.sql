@@ -19,14 +19,18 @@ CREATE TABLE sessions (
     token UUID NOT NULL UNIQUE,
     expires_at TIMESTAMP NOT NULL,
     revoked_at TIMESTAMP,
+    ip_address VARCHAR(45), -- IP client saat login (IPv6 max 45 char)
+    user_agent TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
 -- WAREHOUSES: gudang
 CREATE TABLE warehouses (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(50) NOT NULL,
     name VARCHAR(100) NOT NULL,
     address TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE, -- false = tutup sementara, shelf tidak bisa dipakai produk baru
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP
@@ -35,6 +39,7 @@ CREATE TABLE warehouses (
 -- CATEGORIES: kategori barang
 CREATE TABLE categories (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    parent_id UUID REFERENCES categories(id), -- NULL = kategori root
     name VARCHAR(100) NOT NULL,
     description TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -46,6 +51,7 @@ CREATE TABLE categories (
 CREATE TABLE shelves (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+    code VARCHAR(50) NOT NULL,
     name VARCHAR(100) NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -57,12 +63,17 @@ CREATE TABLE products (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     category_id UUID NOT NULL REFERENCES categories(id),
     shelf_id UUID NOT NULL REFERENCES shelves(id),
+    sku VARCHAR(50), -- kode barang, NULL = belum ada SKU. Relasi lain tetap pakai id, bukan SKU
     name VARCHAR(200) NOT NULL,
     description TEXT,
     unit_price DECIMAL(15,2) NOT NULL DEFAULT 0,
     cost_price DECIMAL(15,2) NOT NULL DEFAULT 0,
     stock_quantity INT NOT NULL DEFAULT 0,
     min_stock_level INT DEFAULT 5, -- untuk fitur cek stok minimum
+    reorder_quantity INT NOT NULL DEFAULT 0 CHECK (reorder_quantity >= 0), -- target stok saat restock, 0 = tanpa saran order
+    image_url VARCHAR(500) NOT NULL DEFAULT '', -- URL eksternal atau path hasil upload
+    expiry_date DATE, -- NULL = tidak ada tanggal kedaluwarsa (barang non-perishable)
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'discontinued')), -- discontinued = tidak bisa dijual lagi
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP
@@ -75,6 +86,14 @@ CREATE TABLE sales (
     user_id UUID NOT NULL REFERENCES users(id), -- kasir/yg input
     total_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
     status VARCHAR(20) DEFAULT 'completed' CHECK (status IN ('pending', 'completed', 'cancelled')),
+    payment_method VARCHAR(20) NOT NULL DEFAULT 'cash' CHECK (payment_method IN ('cash', 'card', 'transfer')),
+    customer_name VARCHAR(100), -- opsional, walk-in boleh kosong
+    customer_phone VARCHAR(30),
+    payment_status VARCHAR(20) NOT NULL DEFAULT 'unpaid' CHECK (payment_status IN ('unpaid', 'partial', 'paid')), -- terpisah dari status (fulfillment)
+    amount_paid DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (amount_paid >= 0),
+    cancelled_reason TEXT, -- wajib diisi saat status jadi cancelled
+    cancelled_at TIMESTAMP,
+    completed_at TIMESTAMP, -- diisi saat status berubah jadi completed (bukan saat create)
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP
@@ -88,16 +107,116 @@ CREATE TABLE sale_items (
     quantity INT NOT NULL,
     unit_price DECIMAL(15,2) NOT NULL,
     total_price DECIMAL(15,2) NOT NULL,
+    notes VARCHAR(500) NOT NULL DEFAULT '', -- catatan kasir per baris, contoh "dus rusak, diskon"
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- WEBHOOKS: subscriber outbound event
+CREATE TABLE webhooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url VARCHAR(500) NOT NULL,
+    event_types TEXT[] NOT NULL, -- sale.created, sale.status_changed, low_stock
+    secret VARCHAR(255) NOT NULL, -- HMAC-SHA256 key untuk header X-Signature
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+-- REPLENISHMENT_REQUESTS: permintaan restock dari staff, di-review admin
+CREATE TABLE replenishment_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID REFERENCES products(id),
+    requested_by UUID REFERENCES users(id),
+    requested_quantity INT NOT NULL CHECK (requested_quantity > 0),
+    notes TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    reviewed_by UUID REFERENCES users(id),
+    reviewed_at TIMESTAMP,
+    review_notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+-- PRODUCT_STOCK_LOCATIONS: stok produk per rak (multi warehouse)
+-- products.stock_quantity tetap total (denormalized) = SUM(quantity)
+-- Produk tanpa baris di sini = single location, semua stok ada di products.shelf_id
+CREATE TABLE product_stock_locations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id),
+    shelf_id UUID NOT NULL REFERENCES shelves(id),
+    quantity INT NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, shelf_id)
+);
+
+-- STOCK_MOVEMENTS: ledger perubahan stok (quantity bertanda, + masuk / - keluar)
+CREATE TABLE stock_movements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id),
+    movement_type VARCHAR(30) NOT NULL CHECK (movement_type IN ('sale', 'restock', 'adjustment', 'cancellation_restore', 'transfer')),
+    quantity INT NOT NULL,
+    stock_after INT NOT NULL, -- total stok produk setelah movement
+    reference_id UUID, -- sale_id untuk sale & cancellation_restore, transfer_id (sama untuk pasangan keluar/masuk) untuk transfer
+    notes TEXT,
+    created_by UUID REFERENCES users(id), -- user yang mengubah stok, NULL untuk movement lama / tanpa login
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- SKU HISTORY: riwayat perubahan SKU produk
+CREATE TABLE sku_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id),
+    old_sku VARCHAR(50), -- NULL = sebelumnya belum ada SKU
+    new_sku VARCHAR(50), -- NULL = SKU dihapus
+    changed_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- PRICE HISTORY: riwayat perubahan harga produk
+CREATE TABLE price_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id),
+    old_unit_price DECIMAL(15,2) NOT NULL,
+    new_unit_price DECIMAL(15,2) NOT NULL,
+    old_cost_price DECIMAL(15,2) NOT NULL,
+    new_cost_price DECIMAL(15,2) NOT NULL,
+    changed_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- INVENTORY SNAPSHOTS: stok akhir bulan per produk, direkonstruksi dari stock_movements
+-- Disimpan saat pertama diminta setelah bulan selesai, sesudahnya tidak berubah
+CREATE TABLE inventory_snapshots (
+    month DATE NOT NULL, -- tanggal 1 bulan snapshot
+    product_id UUID NOT NULL REFERENCES products(id),
+    stock_quantity INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (month, product_id)
+);
+
 -- INDEX penting aja
-CREATE INDEX idx_users_email ON users(email);
+CREATE UNIQUE INDEX idx_users_email ON users(LOWER(email)); -- email unik case-insensitive
 CREATE INDEX idx_sessions_token ON sessions(token);
 CREATE INDEX idx_sessions_active ON sessions(token) WHERE revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP;
+CREATE INDEX idx_sessions_user ON sessions(user_id, created_at); -- login history per user
 CREATE INDEX idx_products_stock ON products(stock_quantity);
 CREATE INDEX idx_products_min_stock ON products(stock_quantity) WHERE stock_quantity < min_stock_level;
+CREATE INDEX idx_products_expiry_date ON products(expiry_date) WHERE expiry_date IS NOT NULL AND deleted_at IS NULL;
 CREATE INDEX idx_sales_user_id ON sales(user_id);
+CREATE INDEX idx_sales_customer_phone ON sales(customer_phone) WHERE customer_phone IS NOT NULL;
+CREATE INDEX idx_categories_parent_id ON categories(parent_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_replenishment_status ON replenishment_requests(status, created_at);
+CREATE INDEX idx_stock_locations_shelf ON product_stock_locations(shelf_id);
+CREATE INDEX idx_stock_movements_product ON stock_movements(product_id, created_at);
+CREATE INDEX idx_stock_movements_created ON stock_movements(created_at); -- activity feed semua produk
+CREATE INDEX idx_sku_history_product ON sku_history(product_id, created_at);
+CREATE INDEX idx_price_history_product ON price_history(product_id, created_at);
+CREATE UNIQUE INDEX idx_products_sku ON products(sku) WHERE deleted_at IS NULL AND sku IS NOT NULL; -- SKU unik untuk produk aktif
+CREATE UNIQUE INDEX idx_warehouses_code ON warehouses(code) WHERE deleted_at IS NULL; -- kode unik untuk warehouse aktif
+CREATE UNIQUE INDEX idx_shelves_warehouse_code ON shelves(warehouse_id, code) WHERE deleted_at IS NULL; -- kode rak unik per warehouse
 
 -- DATA DEFAULT: untuk testing
 INSERT INTO users (username, email, password_hash, full_name, role) VALUES
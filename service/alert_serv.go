@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/dto/alert"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"inventory-system/utils"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+type AlertService interface {
+	GetInventoryDigest(ctx context.Context) (*alert.InventoryDigestResponse, error)
+}
+
+type alertService struct {
+	repo *repository.Repository
+	log  *zap.Logger
+}
+
+func NewAlertService(repo *repository.Repository, log *zap.Logger) AlertService {
+	return &alertService{repo: repo, log: log}
+}
+
+// GET INVENTORY DIGEST
+// Reuse query low stock (stok > 0) & critical stock percent 0 (stok habis), dijalankan paralel
+// Produk dengan min_stock_level 0 tidak dipantau, jadi tidak muncul di kedua section
+func (as *alertService) GetInventoryDigest(ctx context.Context) (*alert.InventoryDigestResponse, error) {
+	var (
+		lowStock   []model.Product
+		outOfStock []model.Product
+		categories []model.Category
+	)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		lowStock, err = as.repo.Product.FindLowStock(gctx)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		outOfStock, err = as.repo.Product.FindCriticalStock(gctx, 0)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		categories, err = as.repo.Category.FindAllActive(gctx)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get inventory alerts", zap.Error(err))
+		return nil, fmt.Errorf("failed to get inventory alerts")
+	}
+
+	categoryNames := make(map[uuid.UUID]string, len(categories))
+	for _, c := range categories {
+		categoryNames[c.ID] = c.Name
+	}
+
+	locations := newAlertLocationCache(as.repo)
+	response := &alert.InventoryDigestResponse{
+		GeneratedAt: time.Now(),
+		LowStock:    as.buildSection(ctx, lowStock, categoryNames, locations),
+		OutOfStock:  as.buildSection(ctx, outOfStock, categoryNames, locations),
+	}
+
+	// Low stock: rasio stok/min terkecil dulu. Out of stock: deficit (min level) terbesar dulu
+	sortAlertItems(response.LowStock.Items, func(a, b alert.InventoryAlertItem) bool {
+		left := a.CurrentStock * b.MinStockLevel
+		right := b.CurrentStock * a.MinStockLevel
+		if left != right {
+			return left < right
+		}
+		return a.Deficit > b.Deficit
+	})
+	sortAlertItems(response.OutOfStock.Items, func(a, b alert.InventoryAlertItem) bool {
+		return a.Deficit > b.Deficit
+	})
+
+	utils.LoggerFromContext(ctx).Info("Inventory alert digest generated",
+		zap.Int("low_stock", response.LowStock.Count),
+		zap.Int("out_of_stock", response.OutOfStock.Count))
+	return response, nil
+}
+
+func (as *alertService) buildSection(ctx context.Context, products []model.Product, categoryNames map[uuid.UUID]string, locations *alertLocationCache) alert.AlertSection {
+	section := alert.AlertSection{
+		Count: len(products),
+		Items: make([]alert.InventoryAlertItem, 0, len(products)),
+	}
+
+	for _, p := range products {
+		item := alert.InventoryAlertItem{
+			ProductID:     p.ID.String(),
+			ProductName:   p.Name,
+			SKU:           formatSKU(p.SKU),
+			CategoryID:    p.CategoryID.String(),
+			CurrentStock:  p.StockQuantity,
+			MinStockLevel: p.MinStockLevel,
+			Deficit:       stockDeficit(p.MinStockLevel, p.StockQuantity),
+			Location:      locations.get(ctx, p.ShelfID),
+		}
+		if name, ok := categoryNames[p.CategoryID]; ok {
+			item.CategoryName = &name
+		}
+		section.Items = append(section.Items, item)
+	}
+
+	return section
+}
+
+// sortAlertItems stable supaya urutan dari query (stok terkecil) tetap jadi tie-breaker
+func sortAlertItems(items []alert.InventoryAlertItem, less func(a, b alert.InventoryAlertItem) bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return less(items[i], items[j])
+	})
+}
+
+// alertLocationCache lookup rak & warehouse sekali per rak (banyak produk biasanya di rak yang sama)
+type alertLocationCache struct {
+	repo       *repository.Repository
+	shelves    map[uuid.UUID]alert.AlertLocation
+	warehouses map[uuid.UUID]*model.Warehouse // nil = sudah dicari tapi tidak ditemukan
+}
+
+func newAlertLocationCache(repo *repository.Repository) *alertLocationCache {
+	return &alertLocationCache{
+		repo:       repo,
+		shelves:    make(map[uuid.UUID]alert.AlertLocation),
+		warehouses: make(map[uuid.UUID]*model.Warehouse),
+	}
+}
+
+// get lokasi rak, rak/warehouse yang sudah dihapus dikembalikan sebagai null
+func (c *alertLocationCache) get(ctx context.Context, shelfID uuid.UUID) alert.AlertLocation {
+	if location, ok := c.shelves[shelfID]; ok {
+		return location
+	}
+
+	var location alert.AlertLocation
+	if shelf, err := c.repo.Shelf.FindByID(ctx, shelfID); err == nil {
+		id := shelf.ID.String()
+		location.ShelfID, location.ShelfCode, location.ShelfName = &id, &shelf.Code, &shelf.Name
+
+		warehouse, ok := c.warehouses[shelf.WarehouseID]
+		if !ok {
+			warehouse, _ = c.repo.Warehouse.FindByID(ctx, shelf.WarehouseID)
+			c.warehouses[shelf.WarehouseID] = warehouse
+		}
+		if warehouse != nil {
+			whID := warehouse.ID.String()
+			location.WarehouseID, location.WarehouseCode, location.WarehouseName = &whID, &warehouse.Code, &warehouse.Name
+		}
+	}
+
+	c.shelves[shelfID] = location
+	return location
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKES ==========
+
+// fakeAlertProductRepo - fakeProductRepo plus query low stock (stok > 0 & <= min, stok terkecil dulu)
+type fakeAlertProductRepo struct {
+	*fakeProductRepo
+}
+
+func (f fakeAlertProductRepo) FindLowStock(ctx context.Context) ([]model.Product, error) {
+	low := make([]model.Product, 0)
+	for _, p := range f.products {
+		if p.StockQuantity > 0 && p.StockQuantity <= p.MinStockLevel {
+			low = append(low, *p)
+		}
+	}
+	sort.Slice(low, func(i, j int) bool { return low[i].StockQuantity < low[j].StockQuantity })
+	return low, nil
+}
+
+// fakeAlertCategoryRepo - fakeCategoryRepo plus daftar category aktif
+type fakeAlertCategoryRepo struct {
+	fakeCategoryRepo
+}
+
+func (f *fakeAlertCategoryRepo) FindAllActive(ctx context.Context) ([]model.Category, error) {
+	categories := make([]model.Category, 0, len(f.categories))
+	for _, c := range f.categories {
+		categories = append(categories, *c)
+	}
+	return categories, nil
+}
+
+// ========== INVENTORY DIGEST ==========
+
+func TestGetInventoryDigest(t *testing.T) {
+	warehouse := &model.Warehouse{BaseModel: model.BaseModel{ID: uuid.New()}, Code: "WH-A", Name: "Warehouse A"}
+	shelf := &model.Shelf{BaseModel: model.BaseModel{ID: uuid.New()}, WarehouseID: warehouse.ID, Code: "B3", Name: "Shelf B3"}
+	drinks := &model.Category{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Drinks"}
+	deletedShelf, deletedCategory := uuid.New(), uuid.New()
+
+	product := func(name string, stock, minLevel int) *model.Product {
+		return &model.Product{
+			BaseModel:     model.BaseModel{ID: uuid.New()},
+			CategoryID:    drinks.ID,
+			ShelfID:       shelf.ID,
+			Name:          name,
+			StockQuantity: stock,
+			MinStockLevel: minLevel,
+		}
+	}
+	orphan := product("Orphan", 0, 3)
+	orphan.CategoryID, orphan.ShelfID = deletedCategory, deletedShelf
+
+	products := newFakeProductRepo(
+		product("Ratio 0.2 small deficit", 2, 10),
+		product("Ratio 0.25", 1, 4),
+		product("Ratio 0.2 big deficit", 3, 15),
+		product("At min", 5, 5),
+		product("Empty big min", 0, 20),
+		orphan,
+		product("Unmonitored", 0, 0),
+		product("Healthy", 50, 10),
+	)
+	repo := &repository.Repository{
+		Product:   fakeAlertProductRepo{products},
+		Category:  &fakeAlertCategoryRepo{fakeCategoryRepo{categories: map[uuid.UUID]*model.Category{drinks.ID: drinks}}},
+		Shelf:     &fakeShelfRepo{shelves: map[uuid.UUID]*model.Shelf{shelf.ID: shelf}},
+		Warehouse: &fakeWarehouseRepo{warehouses: map[uuid.UUID]*model.Warehouse{warehouse.ID: warehouse}},
+	}
+
+	resp, err := NewAlertService(repo, zap.NewNop()).GetInventoryDigest(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var low, out []string
+	for _, item := range resp.LowStock.Items {
+		low = append(low, item.ProductName)
+	}
+	for _, item := range resp.OutOfStock.Items {
+		out = append(out, item.ProductName)
+	}
+
+	// Low stock: rasio stok/min terkecil dulu, rasio sama = deficit terbesar dulu
+	if got, want := strings.Join(low, ","), "Ratio 0.2 big deficit,Ratio 0.2 small deficit,Ratio 0.25,At min"; got != want || resp.LowStock.Count != 4 {
+		t.Fatalf("low stock = %s (count %d), want %s", got, resp.LowStock.Count, want)
+	}
+	// Out of stock: deficit terbesar dulu, produk tanpa min level tidak dipantau
+	if got, want := strings.Join(out, ","), "Empty big min,Orphan"; got != want || resp.OutOfStock.Count != 2 {
+		t.Fatalf("out of stock = %s (count %d), want %s", got, resp.OutOfStock.Count, want)
+	}
+
+	first := resp.LowStock.Items[0]
+	if first.CurrentStock != 3 || first.MinStockLevel != 15 || first.Deficit != 12 {
+		t.Errorf("item = %+v, want stock 3, min 15, deficit 12", first)
+	}
+	if first.CategoryName == nil || *first.CategoryName != "Drinks" {
+		t.Errorf("category = %v, want Drinks", first.CategoryName)
+	}
+	location := first.Location
+	if location.ShelfCode == nil || *location.ShelfCode != "B3" || location.WarehouseName == nil || *location.WarehouseName != "Warehouse A" {
+		t.Errorf("location = %+v, want Shelf B3 in Warehouse A", location)
+	}
+
+	orphaned := resp.OutOfStock.Items[1]
+	if orphaned.CategoryName != nil || orphaned.Location.ShelfID != nil || orphaned.Location.WarehouseID != nil {
+		t.Errorf("orphan = %+v, want null category and location", orphaned)
+	}
+}
@@ -26,8 +26,14 @@ type AuthService interface {
 	// ValidateToken - cek validitas token dan ambil user data
 	ValidateToken(ctx context.Context, token uuid.UUID) (*model.User, error)
 
+	// CheckToken - sama dengan ValidateToken, plus expiry session (untuk endpoint validate)
+	CheckToken(ctx context.Context, token uuid.UUID) (*auth.ValidateTokenResponse, error)
+
 	// LogoutAllUserSessions - force logout semua session user (admin feature)
 	LogoutAllUserSessions(ctx context.Context, userID uuid.UUID) error
+
+	// GetActiveSessionCounts - user dengan session aktif terlalu banyak (security monitoring)
+	GetActiveSessionCounts(ctx context.Context) (*auth.ActiveSessionCountsResponse, error)
 }
 
 // ============================================
@@ -36,12 +42,28 @@ type AuthService interface {
 type authService struct {
 	repo *repository.Repository
 	log  *zap.Logger
+	opts AuthOptions
+}
+
+// AuthOptions - pengaturan session dari config
+type AuthOptions struct {
+	SessionTTL     time.Duration // masa berlaku token, 0 = fallback defaultSessionTTL
+	SlidingEnabled bool          // perpanjang expiry saat user masih aktif
+	SlideThreshold time.Duration // perpanjang hanya jika sisa waktu < threshold (hindari write tiap request)
+	ActiveAlert    int           // user dengan session aktif > nilai ini dianggap mencurigakan
 }
 
-func NewAuthService(repo *repository.Repository, log *zap.Logger) AuthService {
+// Fallback masa berlaku token sesuai requirement awal
+const defaultSessionTTL = 24 * time.Hour
+
+func NewAuthService(repo *repository.Repository, log *zap.Logger, opts AuthOptions) AuthService {
+	if opts.SessionTTL <= 0 {
+		opts.SessionTTL = defaultSessionTTL
+	}
 	return &authService{
 		repo: repo,
 		log:  log,
+		opts: opts,
 	}
 }
 
@@ -50,6 +72,8 @@ func NewAuthService(repo *repository.Repository, log *zap.Logger) AuthService {
 // ============================================
 // Flow: Validate input → Find user → Check password → Create session → Return token
 func (as *authService) Login(ctx context.Context, req auth.LoginRequest) (*auth.LoginResponse, error) {
+	req.Email = normalizeEmail(req.Email)
+
 	// 1. Validate input format
 	if err := utils.ValidateStruct(req); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
@@ -58,25 +82,25 @@ func (as *authService) Login(ctx context.Context, req auth.LoginRequest) (*auth.
 	// 2. Find user by email
 	user, err := as.repo.User.FindByEmail(ctx, req.Email)
 	if err != nil {
-		as.log.Warn("Login failed: user not found", zap.String("email", req.Email))
+		utils.LoggerFromContext(ctx).Warn("Login failed: user not found", zap.String("email", req.Email))
 		return nil, fmt.Errorf("invalid credentials") // Generic error untuk security
 	}
 
 	// 3. Verify password
 	if !utils.CheckPassword(req.Password, user.PasswordHash) {
-		as.log.Warn("Login failed: invalid password", zap.String("email", req.Email))
+		utils.LoggerFromContext(ctx).Warn("Login failed: invalid password", zap.String("email", req.Email))
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
 	// 4. Check if user is active
 	if !user.IsActive {
-		as.log.Warn("Login failed: user inactive", zap.String("user_id", user.ID.String()))
+		utils.LoggerFromContext(ctx).Warn("Login failed: user inactive", zap.String("user_id", user.ID.String()))
 		return nil, fmt.Errorf("account is inactive")
 	}
 
 	// 5. Generate session token
 	token := uuid.New()
-	expiresAt := time.Now().Add(24 * time.Hour) // Token berlaku 24 jam
+	expiresAt := time.Now().Add(as.opts.SessionTTL) // Token berlaku SESSION_TTL_HOURS (default 24 jam)
 
 	// 6. Create session record
 	session := &model.Session{
@@ -84,9 +108,15 @@ func (as *authService) Login(ctx context.Context, req auth.LoginRequest) (*auth.
 		Token:     token,
 		ExpiresAt: expiresAt,
 	}
+	if req.IPAddress != "" {
+		session.IPAddress = &req.IPAddress
+	}
+	if req.UserAgent != "" {
+		session.UserAgent = &req.UserAgent
+	}
 
 	if err := as.repo.Session.Create(ctx, session); err != nil {
-		as.log.Error("Failed to create session", zap.Error(err), zap.String("user_id", user.ID.String()))
+		utils.LoggerFromContext(ctx).Error("Failed to create session", zap.Error(err), zap.String("user_id", user.ID.String()))
 		return nil, fmt.Errorf("failed to create session")
 	}
 
@@ -104,7 +134,7 @@ func (as *authService) Login(ctx context.Context, req auth.LoginRequest) (*auth.
 		},
 	}
 
-	as.log.Info("User logged in",
+	utils.LoggerFromContext(ctx).Info("User logged in",
 		zap.String("user_id", user.ID.String()),
 		zap.String("role", string(user.Role)),
 	)
@@ -119,11 +149,11 @@ func (as *authService) Login(ctx context.Context, req auth.LoginRequest) (*auth.
 func (as *authService) Logout(ctx context.Context, token uuid.UUID) error {
 	// Mark session as revoked (soft delete)
 	if err := as.repo.Session.DeleteByToken(ctx, token); err != nil {
-		as.log.Error("Failed to logout", zap.Error(err), zap.String("token", token.String()))
+		utils.LoggerFromContext(ctx).Error("Failed to logout", zap.Error(err), zap.String("token", token.String()))
 		return fmt.Errorf("failed to logout")
 	}
 
-	as.log.Info("User logged out", zap.String("token", token.String()))
+	utils.LoggerFromContext(ctx).Info("User logged out", zap.String("token", token.String()))
 	return nil
 }
 
@@ -132,31 +162,97 @@ func (as *authService) Logout(ctx context.Context, token uuid.UUID) error {
 // ============================================
 // Flow: Cek session valid → Cek expired → Cek user aktif
 // Digunakan oleh middleware untuk validasi Authorization header
+// Sliding session: expiry diperpanjang jika sisa waktunya di bawah SlideThreshold
 func (as *authService) ValidateToken(ctx context.Context, token uuid.UUID) (*model.User, error) {
+	session, user, err := as.validateSession(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	as.slideSession(ctx, session)
+	return user, nil
+}
+
+// slideSession perpanjang expiry ke now + TTL saat mendekati expired
+// Gagal extend tidak menggagalkan request (token masih valid sampai expiry lama)
+func (as *authService) slideSession(ctx context.Context, session *model.Session) {
+	if !as.opts.SlidingEnabled {
+		return
+	}
+
+	now := time.Now()
+	if session.ExpiresAt.Sub(now) >= as.opts.SlideThreshold {
+		return
+	}
+
+	newExpiry := now.Add(as.opts.SessionTTL)
+	if err := as.repo.Session.ExtendExpiry(ctx, session.Token, newExpiry); err != nil {
+		utils.LoggerFromContext(ctx).Warn("Failed to slide session expiry",
+			zap.String("user_id", session.UserID.String()),
+			zap.Error(err))
+		return
+	}
+
+	session.ExpiresAt = newExpiry
+	utils.LoggerFromContext(ctx).Debug("Session expiry extended",
+		zap.String("user_id", session.UserID.String()),
+		zap.Time("expires_at", newExpiry))
+}
+
+// ============================================
+// CHECK TOKEN - LIGHTWEIGHT VALIDATION ENDPOINT
+// ============================================
+// Dipakai front-end untuk cek "token masih valid?" tanpa lewat Auth middleware
+func (as *authService) CheckToken(ctx context.Context, token uuid.UUID) (*auth.ValidateTokenResponse, error) {
+	session, user, err := as.validateSession(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.ValidateTokenResponse{
+		Valid:     true,
+		ExpiresAt: session.ExpiresAt,
+		User: auth.UserInfo{
+			ID:       user.ID.String(),
+			Username: user.Username,
+			Email:    user.Email,
+			FullName: user.FullName,
+			Role:     string(user.Role),
+			IsActive: user.IsActive,
+		},
+	}, nil
+}
+
+// validateSession helper: session aktif (belum revoked/expired) + user aktif
+func (as *authService) validateSession(ctx context.Context, token uuid.UUID) (*model.Session, *model.User, error) {
 	// 1. Find active session by token
 	session, err := as.repo.Session.FindByToken(ctx, token)
 	if err != nil {
-		as.log.Warn("Invalid token", zap.String("token", token.String()), zap.Error(err))
-		return nil, fmt.Errorf("invalid or expired token")
+		utils.LoggerFromContext(ctx).Warn("Invalid token", zap.String("token", token.String()), zap.Error(err))
+		return nil, nil, fmt.Errorf("invalid or expired token")
 	}
 
 	// 2. Get user data from session
 	user, err := as.repo.User.FindByID(ctx, session.UserID)
 	if err != nil {
-		as.log.Error("User not found for valid session",
+		utils.LoggerFromContext(ctx).Error("User not found for valid session",
 			zap.String("user_id", session.UserID.String()),
 			zap.String("token", token.String()),
 		)
-		return nil, fmt.Errorf("user not found")
+		return nil, nil, fmt.Errorf("user not found")
 	}
 
-	// 3. Check if user account is active
+	// 3. Check if user account is active (dan belum di-soft delete)
+	if user.DeletedAt != nil {
+		utils.LoggerFromContext(ctx).Warn("User deleted", zap.String("user_id", user.ID.String()))
+		return nil, nil, fmt.Errorf("user not found")
+	}
 	if !user.IsActive {
-		as.log.Warn("User inactive", zap.String("user_id", user.ID.String()))
-		return nil, fmt.Errorf("user account is inactive")
+		utils.LoggerFromContext(ctx).Warn("User inactive", zap.String("user_id", user.ID.String()))
+		return nil, nil, fmt.Errorf("user account is inactive")
 	}
 
-	return user, nil
+	return session, user, nil
 }
 
 // ============================================
@@ -165,13 +261,55 @@ func (as *authService) ValidateToken(ctx context.Context, token uuid.UUID) (*mod
 // Force logout semua session user (contoh: saat reset password)
 func (as *authService) LogoutAllUserSessions(ctx context.Context, userID uuid.UUID) error {
 	if err := as.repo.Session.DeleteByUserID(ctx, userID); err != nil {
-		as.log.Error("Failed to logout all sessions",
+		utils.LoggerFromContext(ctx).Error("Failed to logout all sessions",
 			zap.Error(err),
 			zap.String("user_id", userID.String()),
 		)
 		return fmt.Errorf("failed to logout all sessions")
 	}
 
-	as.log.Info("All sessions logged out", zap.String("user_id", userID.String()))
+	utils.LoggerFromContext(ctx).Info("All sessions logged out", zap.String("user_id", userID.String()))
 	return nil
 }
+
+// ============================================
+// ACTIVE SESSION COUNTS - ADMIN FEATURE
+// ============================================
+// User dengan session aktif > threshold (indikasi akun dipakai bersama / bocor)
+func (as *authService) GetActiveSessionCounts(ctx context.Context) (*auth.ActiveSessionCountsResponse, error) {
+	counts, err := as.repo.Session.CountActiveByUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count active sessions")
+	}
+
+	users := filterActiveSessionCounts(counts, as.opts.ActiveAlert)
+
+	utils.LoggerFromContext(ctx).Info("Active session counts checked",
+		zap.Int("threshold", as.opts.ActiveAlert),
+		zap.Int("users_flagged", len(users)),
+	)
+
+	return &auth.ActiveSessionCountsResponse{
+		Threshold: as.opts.ActiveAlert,
+		Users:     users,
+	}, nil
+}
+
+// filterActiveSessionCounts ambil user dengan active count > threshold (urutan dari repo dipertahankan)
+func filterActiveSessionCounts(counts []model.UserSessionCount, threshold int) []auth.ActiveSessionCount {
+	users := make([]auth.ActiveSessionCount, 0)
+	for _, c := range counts {
+		if c.ActiveCount <= threshold {
+			continue
+		}
+		users = append(users, auth.ActiveSessionCount{
+			UserID:        c.UserID.String(),
+			Username:      c.Username,
+			Email:         c.Email,
+			Role:          string(c.Role),
+			ActiveCount:   c.ActiveCount,
+			LastCreatedAt: c.LastCreatedAt,
+		})
+	}
+	return users
+}
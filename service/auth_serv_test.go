@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/dto/auth"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"inventory-system/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKES ==========
+
+type fakeSessionRepo struct {
+	repository.SessionRepo
+	sessions map[uuid.UUID]*model.Session
+	counts   []model.UserSessionCount
+	created  []*model.Session
+	extended map[uuid.UUID]time.Time
+}
+
+func (f *fakeSessionRepo) Create(ctx context.Context, session *model.Session) error {
+	f.created = append(f.created, session)
+	return nil
+}
+
+func (f *fakeSessionRepo) FindByToken(ctx context.Context, token uuid.UUID) (*model.Session, error) {
+	if s, ok := f.sessions[token]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("session not found")
+}
+
+func (f *fakeSessionRepo) ExtendExpiry(ctx context.Context, token uuid.UUID, newExpiry time.Time) error {
+	f.extended[token] = newExpiry
+	return nil
+}
+
+func (f *fakeSessionRepo) CountActiveByUser(ctx context.Context) ([]model.UserSessionCount, error) {
+	return f.counts, nil
+}
+
+func newTestUser(email string, active bool) *model.User {
+	return &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Username: "user", Email: email, Role: model.RoleStaff, IsActive: active}
+}
+
+// ========== LOGIN ==========
+
+func TestAuthLogin(t *testing.T) {
+	active := newTestUser("kasir@example.com", true)
+	active.PasswordHash = utils.HashPassword("secret123")
+	inactive := newTestUser("old@example.com", false)
+	inactive.PasswordHash = active.PasswordHash
+
+	tests := []struct {
+		name     string
+		req      auth.LoginRequest
+		wantErr  string
+		wantUser uuid.UUID
+	}{
+		{name: "valid credentials", req: auth.LoginRequest{Email: "kasir@example.com", Password: "secret123"}, wantUser: active.ID},
+		{name: "email normalized", req: auth.LoginRequest{Email: "  Kasir@Example.COM ", Password: "secret123"}, wantUser: active.ID},
+		{name: "wrong password", req: auth.LoginRequest{Email: "kasir@example.com", Password: "wrong-pass"}, wantErr: "invalid credentials"},
+		{name: "unknown email", req: auth.LoginRequest{Email: "nobody@example.com", Password: "secret123"}, wantErr: "invalid credentials"},
+		{name: "inactive account", req: auth.LoginRequest{Email: "old@example.com", Password: "secret123"}, wantErr: "account is inactive"},
+		{name: "invalid email format", req: auth.LoginRequest{Email: "kasir", Password: "secret123"}, wantErr: "validation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &fakeSessionRepo{}
+			repo := &repository.Repository{
+				User:    &fakeUserRepo{users: map[uuid.UUID]*model.User{active.ID: active, inactive.ID: inactive}},
+				Session: sessions,
+			}
+			svc := NewAuthService(repo, zap.NewNop(), AuthOptions{SessionTTL: 2 * time.Hour})
+
+			req := tt.req
+			req.IPAddress = "10.0.0.1"
+			resp, err := svc.Login(context.Background(), req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if len(sessions.created) != 0 {
+					t.Error("session created on failed login")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp.User.ID != tt.wantUser.String() {
+				t.Errorf("user = %s, want %s", resp.User.ID, tt.wantUser)
+			}
+			if len(sessions.created) != 1 {
+				t.Fatalf("sessions created = %d, want 1", len(sessions.created))
+			}
+			session := sessions.created[0]
+			if session.Token.String() != resp.Token || session.IPAddress == nil || *session.IPAddress != "10.0.0.1" || session.UserAgent != nil {
+				t.Errorf("session = %+v, want token from response and device metadata", session)
+			}
+			if ttl := time.Until(resp.ExpiresAt); ttl < time.Hour || ttl > 2*time.Hour {
+				t.Errorf("expires in %v, want configured 2h TTL", ttl)
+			}
+		})
+	}
+}
+
+// ========== VALIDATE TOKEN ==========
+
+func TestAuthValidateToken(t *testing.T) {
+	active := newTestUser("a@example.com", true)
+	inactive := newTestUser("b@example.com", false)
+	deleted := newTestUser("c@example.com", true)
+	deletedAt := time.Now()
+	deleted.DeletedAt = &deletedAt
+
+	sessionFor := func(u *model.User) *model.Session {
+		return &model.Session{UserID: u.ID, Token: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)}
+	}
+	activeSession, inactiveSession, deletedSession := sessionFor(active), sessionFor(inactive), sessionFor(deleted)
+	orphanSession := &model.Session{UserID: uuid.New(), Token: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)}
+
+	tests := []struct {
+		name    string
+		token   uuid.UUID
+		wantErr string
+	}{
+		{name: "active user", token: activeSession.Token},
+		{name: "unknown token", token: uuid.New(), wantErr: "invalid or expired token"},
+		{name: "user missing", token: orphanSession.Token, wantErr: "user not found"},
+		{name: "user soft deleted", token: deletedSession.Token, wantErr: "user not found"},
+		{name: "user inactive", token: inactiveSession.Token, wantErr: "user account is inactive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repository.Repository{
+				User: &fakeUserRepo{users: map[uuid.UUID]*model.User{active.ID: active, inactive.ID: inactive, deleted.ID: deleted}},
+				Session: &fakeSessionRepo{sessions: map[uuid.UUID]*model.Session{
+					activeSession.Token: activeSession, inactiveSession.Token: inactiveSession,
+					deletedSession.Token: deletedSession, orphanSession.Token: orphanSession,
+				}},
+			}
+			svc := NewAuthService(repo, zap.NewNop(), AuthOptions{})
+
+			user, err := svc.ValidateToken(context.Background(), tt.token)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ValidateToken error = %v, want %q", err, tt.wantErr)
+				}
+				if _, err := svc.CheckToken(context.Background(), tt.token); err == nil || err.Error() != tt.wantErr {
+					t.Errorf("CheckToken error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if user.ID != active.ID {
+				t.Errorf("user = %s, want %s", user.ID, active.ID)
+			}
+
+			resp, err := svc.CheckToken(context.Background(), tt.token)
+			if err != nil || !resp.Valid || !resp.ExpiresAt.Equal(activeSession.ExpiresAt) {
+				t.Errorf("CheckToken = %+v, %v, want valid with session expiry", resp, err)
+			}
+		})
+	}
+}
+
+func TestAuthSlideSession(t *testing.T) {
+	tests := []struct {
+		name       string
+		sliding    bool
+		remaining  time.Duration
+		wantExtend bool
+	}{
+		{name: "disabled", sliding: false, remaining: time.Minute},
+		{name: "plenty of time left", sliding: true, remaining: 3 * time.Hour},
+		{name: "near expiry", sliding: true, remaining: time.Minute, wantExtend: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &fakeSessionRepo{extended: map[uuid.UUID]time.Time{}}
+			svc := NewAuthService(&repository.Repository{Session: sessions}, zap.NewNop(), AuthOptions{
+				SessionTTL:     4 * time.Hour,
+				SlidingEnabled: tt.sliding,
+				SlideThreshold: time.Hour,
+			}).(*authService)
+
+			oldExpiry := time.Now().Add(tt.remaining)
+			session := &model.Session{Token: uuid.New(), ExpiresAt: oldExpiry}
+			svc.slideSession(context.Background(), session)
+
+			newExpiry, extended := sessions.extended[session.Token]
+			if extended != tt.wantExtend {
+				t.Fatalf("extended = %v, want %v", extended, tt.wantExtend)
+			}
+			if !tt.wantExtend {
+				if !session.ExpiresAt.Equal(oldExpiry) {
+					t.Error("expiry changed without extend")
+				}
+				return
+			}
+			if !session.ExpiresAt.Equal(newExpiry) || time.Until(newExpiry) < 3*time.Hour {
+				t.Errorf("expiry = %v, want now + TTL", session.ExpiresAt)
+			}
+		})
+	}
+}
+
+// ========== ACTIVE SESSION COUNTS ==========
+
+func TestFilterActiveSessionCounts(t *testing.T) {
+	counts := []model.UserSessionCount{
+		{UserID: uuid.New(), Username: "a", ActiveCount: 7, Role: model.RoleStaff},
+		{UserID: uuid.New(), Username: "b", ActiveCount: 3},
+		{UserID: uuid.New(), Username: "c", ActiveCount: 5},
+		{UserID: uuid.New(), Username: "d", ActiveCount: 4},
+	}
+
+	tests := []struct {
+		name      string
+		threshold int
+		want      []string
+	}{
+		{name: "only above threshold, order kept", threshold: 4, want: []string{"a", "c"}},
+		{name: "equal to threshold excluded", threshold: 5, want: []string{"a"}},
+		{name: "none flagged", threshold: 10, want: []string{}},
+		{name: "zero threshold flags everyone", threshold: 0, want: []string{"a", "b", "c", "d"}},
+	}
+
+	for _, tt := range tests {
+		got := filterActiveSessionCounts(counts, tt.threshold)
+		if got == nil {
+			t.Errorf("%s: got nil, want empty list", tt.name)
+			continue
+		}
+		names := make([]string, 0, len(got))
+		for _, u := range got {
+			names = append(names, u.Username)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: users = %v, want %v", tt.name, names, tt.want)
+		}
+	}
+
+	got := filterActiveSessionCounts(counts[:1], 0)
+	if got[0].UserID != counts[0].UserID.String() || got[0].Role != "staff" || got[0].ActiveCount != 7 {
+		t.Errorf("converted entry = %+v", got[0])
+	}
+}
+
+func TestAuthGetActiveSessionCounts(t *testing.T) {
+	sessions := &fakeSessionRepo{counts: []model.UserSessionCount{
+		{UserID: uuid.New(), Username: "a", ActiveCount: 2},
+		{UserID: uuid.New(), Username: "b", ActiveCount: 9},
+	}}
+	svc := NewAuthService(&repository.Repository{Session: sessions}, zap.NewNop(), AuthOptions{ActiveAlert: 5})
+
+	resp, err := svc.GetActiveSessionCounts(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Threshold != 5 || len(resp.Users) != 1 || resp.Users[0].Username != "b" {
+		t.Errorf("response = %+v, want threshold 5 with user b only", resp)
+	}
+}
@@ -15,9 +15,13 @@ import (
 type CategoryService interface {
 	Create(ctx context.Context, req category.CreateCategoryRequest) (*category.CategoryResponse, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*category.CategoryResponse, error)
-	FindAll(ctx context.Context, page int, limit int) ([]category.CategoryResponse, utils.Pagination, error)
+	FindAll(ctx context.Context, page int, limit int, includeDeleted bool) ([]category.CategoryResponse, utils.Pagination, error)
 	Update(ctx context.Context, id uuid.UUID, req category.UpdateCategoryRequest) (*category.CategoryResponse, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	GetDeleteImpact(ctx context.Context, id uuid.UUID) (*category.CategoryImpactResponse, error)
+	Merge(ctx context.Context, sourceID uuid.UUID, req category.MergeCategoryRequest) (*category.MergeCategoryResponse, error)
+	FindChildren(ctx context.Context, id uuid.UUID) ([]category.CategoryResponse, error)
+	FindTree(ctx context.Context) ([]category.CategoryTreeNode, error)
 }
 
 type categoryService struct {
@@ -46,16 +50,28 @@ func (cs *categoryService) Create(ctx context.Context, req category.CreateCatego
 		Description: req.Description,
 	}
 
+	// Parent optional, harus category aktif
+	if req.ParentID != nil {
+		parentID, err := uuid.Parse(*req.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent category ID format")
+		}
+		if _, err := cs.repo.Category.FindByID(ctx, parentID); err != nil {
+			return nil, fmt.Errorf("parent category not found")
+		}
+		newCategory.ParentID = &parentID
+	}
+
 	// Save to db
 	if err := cs.repo.Category.Create(ctx, newCategory); err != nil {
-		cs.log.Error("Failed to create category", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to create category", zap.Error(err))
 		return nil, fmt.Errorf("Failed to create category")
 	}
 
 	// Prepare response
 	response := cs.convertToResponse(newCategory)
 
-	cs.log.Info("Category created", zap.String("category_id", newCategory.ID.String()))
+	utils.LoggerFromContext(ctx).Info("Category created", zap.String("category_id", newCategory.ID.String()))
 	return response, nil
 }
 
@@ -68,18 +84,18 @@ func (cs *categoryService) FindByID(ctx context.Context, id uuid.UUID) (*categor
 	return cs.convertToResponse(foundCategory), nil
 }
 
-func (cs *categoryService) FindAll(ctx context.Context, page int, limit int) ([]category.CategoryResponse, utils.Pagination, error) {
+func (cs *categoryService) FindAll(ctx context.Context, page int, limit int, includeDeleted bool) ([]category.CategoryResponse, utils.Pagination, error) {
 	// Setup pagination
 	pagination := utils.NewPagination(page, limit)
 
 	// Get data with pagination
-	categories, err := cs.repo.Category.FindAll(ctx, pagination.Limit, pagination.Offset())
+	categories, err := cs.repo.Category.FindAll(ctx, pagination.Limit, pagination.Offset(), includeDeleted)
 	if err != nil {
 		return nil, pagination, fmt.Errorf("failed to get categories")
 	}
 
 	// Get total count
-	total, err := cs.repo.Category.CountAll(ctx)
+	total, err := cs.repo.Category.CountAll(ctx, includeDeleted)
 	if err != nil {
 		return nil, pagination, fmt.Errorf("failed to count categories")
 	}
@@ -93,7 +109,7 @@ func (cs *categoryService) FindAll(ctx context.Context, page int, limit int) ([]
 		responses = append(responses, *cs.convertToResponse(&c))
 	}
 
-	cs.log.Info("Categories fetched with pagination",
+	utils.LoggerFromContext(ctx).Info("Categories fetched with pagination",
 		zap.Int("page", page),
 		zap.Int("limit", limit),
 		zap.Int("total", total))
@@ -120,6 +136,29 @@ func (cs *categoryService) Update(ctx context.Context, id uuid.UUID, req categor
 		updated = true
 	}
 
+	// parent_id "" = pindah ke root
+	if req.ParentID != nil {
+		var parentID *uuid.UUID
+		if *req.ParentID != "" {
+			parsed, err := uuid.Parse(*req.ParentID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid parent category ID format")
+			}
+			if _, err := cs.repo.Category.FindByID(ctx, parsed); err != nil {
+				return nil, fmt.Errorf("parent category not found")
+			}
+			if err := cs.checkParentCycle(ctx, id, parsed); err != nil {
+				return nil, err
+			}
+			parentID = &parsed
+		}
+
+		if !sameParent(categoryToUpdate.ParentID, parentID) {
+			categoryToUpdate.ParentID = parentID
+			updated = true
+		}
+	}
+
 	if updated {
 		if err := cs.repo.Category.Update(ctx, categoryToUpdate); err != nil {
 			return nil, fmt.Errorf("failed to update category")
@@ -134,20 +173,223 @@ func (cs *categoryService) Delete(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("category not found")
 	}
 
+	// Child harus dipindah/dihapus dulu supaya tidak menunjuk ke parent terhapus
+	children, err := cs.repo.Category.CountChildren(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to count child categories")
+	}
+	if children > 0 {
+		return fmt.Errorf("category has child categories")
+	}
+
 	if err := cs.repo.Category.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to detele category")
 	}
 
-	cs.log.Info("Category deleted", zap.String("category_id", id.String()))
+	utils.LoggerFromContext(ctx).Info("Category deleted", zap.String("category_id", id.String()))
 	return nil
 }
 
+// GetDeleteImpact - preview delete: produk aktif & child yang masih menunjuk ke category
+func (cs *categoryService) GetDeleteImpact(ctx context.Context, id uuid.UUID) (*category.CategoryImpactResponse, error) {
+	existing, err := cs.repo.Category.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	children, err := cs.repo.Category.CountChildren(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count child categories")
+	}
+
+	products, err := cs.repo.Product.FindByCategoryID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category products")
+	}
+
+	response := &category.CategoryImpactResponse{
+		Category:     *cs.convertToResponse(existing),
+		ChildCount:   children,
+		ProductCount: len(products),
+		Products:     make([]category.CategoryImpactProduct, 0, len(products)),
+	}
+	for _, p := range products {
+		response.Products = append(response.Products, category.CategoryImpactProduct{
+			ID:            p.ID.String(),
+			SKU:           p.SKU,
+			Name:          p.Name,
+			StockQuantity: p.StockQuantity,
+			Status:        string(p.Status),
+		})
+	}
+
+	return response, nil
+}
+
+// Merge - gabungkan category duplikat: semua produk source pindah ke target, source di-soft delete
+func (cs *categoryService) Merge(ctx context.Context, sourceID uuid.UUID, req category.MergeCategoryRequest) (*category.MergeCategoryResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	targetID, err := uuid.Parse(req.Into)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target category ID format")
+	}
+	if targetID == sourceID {
+		return nil, fmt.Errorf("validation failed: cannot merge category into itself")
+	}
+
+	if _, err := cs.repo.Category.FindByID(ctx, sourceID); err != nil {
+		return nil, fmt.Errorf("category not found")
+	}
+	children, err := cs.repo.Category.CountChildren(ctx, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count child categories")
+	}
+	if children > 0 {
+		return nil, fmt.Errorf("category has child categories")
+	}
+	target, err := cs.repo.Category.FindByID(ctx, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("target category not found")
+	}
+
+	moved, err := cs.repo.Category.Merge(ctx, sourceID, targetID)
+	if err != nil {
+		if err.Error() == "category not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to merge category")
+	}
+
+	count, err := cs.repo.Category.CountProducts(ctx, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count category products")
+	}
+
+	utils.LoggerFromContext(ctx).Info("Category merged",
+		zap.String("source_id", sourceID.String()),
+		zap.String("target_id", targetID.String()),
+		zap.Int("moved_products", moved))
+
+	return &category.MergeCategoryResponse{
+		Category:      *cs.convertToResponse(target),
+		ProductCount:  count,
+		MovedProducts: moved,
+	}, nil
+}
+
+// FindChildren child langsung dari category
+func (cs *categoryService) FindChildren(ctx context.Context, id uuid.UUID) ([]category.CategoryResponse, error) {
+	if _, err := cs.repo.Category.FindByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	children, err := cs.repo.Category.FindChildren(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child categories")
+	}
+
+	responses := make([]category.CategoryResponse, 0, len(children))
+	for _, c := range children {
+		responses = append(responses, *cs.convertToResponse(&c))
+	}
+
+	return responses, nil
+}
+
+// FindTree seluruh category aktif dalam bentuk nested
+func (cs *categoryService) FindTree(ctx context.Context) ([]category.CategoryTreeNode, error) {
+	categories, err := cs.repo.Category.FindAllActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories")
+	}
+
+	return buildCategoryTree(categories), nil
+}
+
+// checkParentCycle tolak parent yang adalah category itu sendiri atau turunannya
+// Naik dari calon parent sampai root, kalau ketemu id berarti akan terjadi cycle
+func (cs *categoryService) checkParentCycle(ctx context.Context, id, parentID uuid.UUID) error {
+	visited := make(map[uuid.UUID]bool)
+	current := &parentID
+	for current != nil {
+		if *current == id {
+			return fmt.Errorf("validation failed: category cannot be its own ancestor")
+		}
+		// Jaga-jaga data lama yang sudah cycle, supaya tidak infinite loop
+		if visited[*current] {
+			return fmt.Errorf("validation failed: category hierarchy contains a cycle")
+		}
+		visited[*current] = true
+
+		ancestor, err := cs.repo.Category.FindByID(ctx, *current)
+		if err != nil {
+			// Ancestor terhapus = rantai putus di sini
+			return nil
+		}
+		current = ancestor.ParentID
+	}
+	return nil
+}
+
+// buildCategoryTree susun list flat jadi nested, urutan input dipertahankan
+// Category dengan parent yang tidak ada di list (terhapus) jadi root
+func buildCategoryTree(categories []model.Category) []category.CategoryTreeNode {
+	exists := make(map[uuid.UUID]bool, len(categories))
+	for _, c := range categories {
+		exists[c.ID] = true
+	}
+
+	childrenOf := make(map[uuid.UUID][]model.Category)
+	var roots []model.Category
+	for _, c := range categories {
+		if c.ParentID == nil || !exists[*c.ParentID] {
+			roots = append(roots, c)
+			continue
+		}
+		childrenOf[*c.ParentID] = append(childrenOf[*c.ParentID], c)
+	}
+
+	var build func(nodes []model.Category) []category.CategoryTreeNode
+	build = func(nodes []model.Category) []category.CategoryTreeNode {
+		result := make([]category.CategoryTreeNode, 0, len(nodes))
+		for _, c := range nodes {
+			result = append(result, category.CategoryTreeNode{
+				ID:          c.ID.String(),
+				Name:        c.Name,
+				Description: c.Description,
+				Children:    build(childrenOf[c.ID]),
+			})
+		}
+		return result
+	}
+
+	return build(roots)
+}
+
+func sameParent(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
 func (cs *categoryService) convertToResponse(c *model.Category) *category.CategoryResponse {
+	var parentID *string
+	if c.ParentID != nil {
+		id := c.ParentID.String()
+		parentID = &id
+	}
+
 	return &category.CategoryResponse{
 		ID:          c.ID.String(),
+		ParentID:    parentID,
 		Name:        c.Name,
 		Description: c.Description,
 		CreatedAt:   c.CreatedAt,
 		UpdatedAt:   c.UpdatedAt,
+		DeletedAt:   c.DeletedAt,
 	}
 }
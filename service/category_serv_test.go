@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"inventory-system/dto/category"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKES ==========
+
+// fakeCategoryTreeRepo - fakeCategoryRepo plus child count & merge
+type fakeCategoryTreeRepo struct {
+	fakeCategoryRepo
+	children      map[uuid.UUID]int
+	productCounts map[uuid.UUID]int
+	moved         int
+	mergeCalls    int
+}
+
+func (f *fakeCategoryTreeRepo) CountChildren(ctx context.Context, id uuid.UUID) (int, error) {
+	return f.children[id], nil
+}
+
+func (f *fakeCategoryTreeRepo) Merge(ctx context.Context, sourceID, targetID uuid.UUID) (int, error) {
+	f.mergeCalls++
+	f.productCounts[targetID] += f.moved
+	return f.moved, nil
+}
+
+func (f *fakeCategoryTreeRepo) CountProducts(ctx context.Context, id uuid.UUID) (int, error) {
+	return f.productCounts[id], nil
+}
+
+type fakeCategoryProductRepo struct {
+	repository.ProductRepo
+	byCategory map[uuid.UUID][]model.Product
+}
+
+func (f *fakeCategoryProductRepo) FindByCategoryID(ctx context.Context, categoryID uuid.UUID) ([]model.Product, error) {
+	return f.byCategory[categoryID], nil
+}
+
+func newCategory(name string, parentID *uuid.UUID) *model.Category {
+	return &model.Category{BaseModel: model.BaseModel{ID: uuid.New()}, Name: name, ParentID: parentID}
+}
+
+// ========== DELETE IMPACT ==========
+
+func TestCategoryGetDeleteImpact(t *testing.T) {
+	parent := newCategory("Beverages", nil)
+	empty := newCategory("Snacks", nil)
+	products := []model.Product{
+		{BaseModel: model.BaseModel{ID: uuid.New()}, SKU: stringPtr("SKU-1"), Name: "Coffee", StockQuantity: 20, Status: model.ProductStatusActive},
+		{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Tea", StockQuantity: 0, Status: model.ProductStatusDiscontinued},
+	}
+
+	tests := []struct {
+		name         string
+		id           uuid.UUID
+		wantErr      string
+		wantChildren int
+		wantProducts []string
+	}{
+		{name: "category with children and products", id: parent.ID, wantChildren: 2, wantProducts: []string{"Coffee", "Tea"}},
+		{name: "empty category", id: empty.ID, wantProducts: []string{}},
+		{name: "unknown category", id: uuid.New(), wantErr: "category not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repository.Repository{
+				Category: &fakeCategoryTreeRepo{
+					fakeCategoryRepo: fakeCategoryRepo{categories: map[uuid.UUID]*model.Category{parent.ID: parent, empty.ID: empty}},
+					children:         map[uuid.UUID]int{parent.ID: 2},
+				},
+				Product: &fakeCategoryProductRepo{byCategory: map[uuid.UUID][]model.Product{parent.ID: products}},
+			}
+			svc := NewCategoryService(repo, zap.NewNop())
+
+			resp, err := svc.GetDeleteImpact(context.Background(), tt.id)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp.Category.ID != tt.id.String() {
+				t.Errorf("category id = %s, want %s", resp.Category.ID, tt.id)
+			}
+			if resp.ChildCount != tt.wantChildren {
+				t.Errorf("child count = %d, want %d", resp.ChildCount, tt.wantChildren)
+			}
+			if resp.Products == nil {
+				t.Fatal("products must be an empty list, not nil")
+			}
+			if resp.ProductCount != len(tt.wantProducts) || len(resp.Products) != len(tt.wantProducts) {
+				t.Fatalf("products = %d (count %d), want %d", len(resp.Products), resp.ProductCount, len(tt.wantProducts))
+			}
+			for i, name := range tt.wantProducts {
+				if resp.Products[i].Name != name {
+					t.Errorf("products[%d] = %s, want %s", i, resp.Products[i].Name, name)
+				}
+			}
+		})
+	}
+
+	// Status & SKU ikut tampil supaya admin tahu produk mana yang harus dipindah
+	repo := &repository.Repository{
+		Category: &fakeCategoryTreeRepo{fakeCategoryRepo: fakeCategoryRepo{categories: map[uuid.UUID]*model.Category{parent.ID: parent}}},
+		Product:  &fakeCategoryProductRepo{byCategory: map[uuid.UUID][]model.Product{parent.ID: products}},
+	}
+	resp, _ := NewCategoryService(repo, zap.NewNop()).GetDeleteImpact(context.Background(), parent.ID)
+	if got := resp.Products[1]; got.Status != "discontinued" || got.SKU != nil || got.ID != products[1].ID.String() {
+		t.Errorf("products[1] = %+v, want discontinued product without SKU", got)
+	}
+}
+
+// ========== MERGE ==========
+
+func TestCategoryMerge(t *testing.T) {
+	source := newCategory("Drinks", nil)
+	target := newCategory("Beverages", nil)
+	parent := newCategory("Food", nil)
+
+	tests := []struct {
+		name      string
+		sourceID  uuid.UUID
+		into      string
+		wantErr   string
+		wantCount int
+	}{
+		{name: "merge into target", sourceID: source.ID, into: target.ID.String(), wantCount: 7},
+		{name: "missing target", sourceID: source.ID, into: "", wantErr: "validation failed"},
+		{name: "into itself", sourceID: source.ID, into: source.ID.String(), wantErr: "validation failed: cannot merge category into itself"},
+		{name: "unknown source", sourceID: uuid.New(), into: target.ID.String(), wantErr: "category not found"},
+		{name: "source has children", sourceID: parent.ID, into: target.ID.String(), wantErr: "category has child categories"},
+		{name: "unknown target", sourceID: source.ID, into: uuid.NewString(), wantErr: "target category not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			categories := &fakeCategoryTreeRepo{
+				fakeCategoryRepo: fakeCategoryRepo{categories: map[uuid.UUID]*model.Category{source.ID: source, target.ID: target, parent.ID: parent}},
+				children:         map[uuid.UUID]int{parent.ID: 1},
+				productCounts:    map[uuid.UUID]int{target.ID: 4},
+				moved:            3,
+			}
+			svc := NewCategoryService(&repository.Repository{Category: categories}, zap.NewNop())
+
+			resp, err := svc.Merge(context.Background(), tt.sourceID, category.MergeCategoryRequest{Into: tt.into})
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if categories.mergeCalls != 0 {
+					t.Error("merge called on rejected request")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp.Category.ID != target.ID.String() {
+				t.Errorf("category = %s, want target %s", resp.Category.ID, target.ID)
+			}
+			if resp.MovedProducts != 3 || resp.ProductCount != tt.wantCount {
+				t.Errorf("moved = %d, count = %d, want 3, %d", resp.MovedProducts, resp.ProductCount, tt.wantCount)
+			}
+		})
+	}
+}
+
+// ========== TREE ==========
+
+func TestBuildCategoryTree(t *testing.T) {
+	food := newCategory("Food", nil)
+	snacks := newCategory("Snacks", &food.ID)
+	chips := newCategory("Chips", &snacks.ID)
+	fruit := newCategory("Fruit", &food.ID)
+	deletedParent := uuid.New()
+	orphan := newCategory("Orphan", &deletedParent)
+
+	tree := buildCategoryTree([]model.Category{*food, *snacks, *orphan, *chips, *fruit})
+
+	// Root dan children mengikuti urutan input, orphan jadi root
+	if len(tree) != 2 || tree[0].Name != "Food" || tree[1].Name != "Orphan" {
+		t.Fatalf("roots = %+v, want Food, Orphan", tree)
+	}
+	children := tree[0].Children
+	if len(children) != 2 || children[0].Name != "Snacks" || children[1].Name != "Fruit" {
+		t.Fatalf("Food children = %+v, want Snacks, Fruit", children)
+	}
+	if len(children[0].Children) != 1 || children[0].Children[0].Name != "Chips" {
+		t.Errorf("Snacks children = %+v, want Chips", children[0].Children)
+	}
+	if tree[1].Children == nil || len(tree[1].Children) != 0 {
+		t.Errorf("leaf children = %#v, want empty list", tree[1].Children)
+	}
+
+	if empty := buildCategoryTree(nil); empty == nil || len(empty) != 0 {
+		t.Errorf("buildCategoryTree(nil) = %#v, want empty list", empty)
+	}
+}
+
+func TestCategoryCheckParentCycle(t *testing.T) {
+	root := newCategory("Root", nil)
+	child := newCategory("Child", &root.ID)
+	grandchild := newCategory("Grandchild", &child.ID)
+
+	svc := &categoryService{repo: &repository.Repository{Category: &fakeCategoryRepo{categories: map[uuid.UUID]*model.Category{
+		root.ID: root, child.ID: child, grandchild.ID: grandchild,
+	}}}}
+
+	tests := []struct {
+		name     string
+		id       uuid.UUID
+		parentID uuid.UUID
+		wantErr  string
+	}{
+		{name: "valid parent", id: grandchild.ID, parentID: root.ID},
+		{name: "self parent", id: root.ID, parentID: root.ID, wantErr: "validation failed: category cannot be its own ancestor"},
+		{name: "descendant as parent", id: root.ID, parentID: grandchild.ID, wantErr: "validation failed: category cannot be its own ancestor"},
+		{name: "missing ancestor ends chain", id: root.ID, parentID: uuid.New()},
+	}
+
+	for _, tt := range tests {
+		err := svc.checkParentCycle(context.Background(), tt.id, tt.parentID)
+		if (tt.wantErr == "" && err != nil) || (tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr)) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/dto/report"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"inventory-system/utils"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+type DashboardService interface {
+	GetSummary(ctx context.Context, actor *model.User) (*report.DashboardResponse, error)
+}
+
+type dashboardService struct {
+	repo *repository.Repository
+	log  *zap.Logger
+}
+
+func NewDashboardService(repo *repository.Repository, log *zap.Logger) DashboardService {
+	return &dashboardService{repo: repo, log: log}
+}
+
+// GET SUMMARY
+// Reuse agregasi ReportRepo, dijalankan paralel dengan errgroup
+func (ds *dashboardService) GetSummary(ctx context.Context, actor *model.User) (*report.DashboardResponse, error) {
+	if actor == nil {
+		return nil, fmt.Errorf("permission denied")
+	}
+
+	var (
+		productReport *report.ProductReportResponse
+		salesReport   *report.SalesReportResponse
+	)
+
+	g, gctx := errgroup.WithContext(ctx)
+
+	// Ringkasan inventory (total, low stock, out of stock, value)
+	g.Go(func() error {
+		res, err := ds.repo.Report.GetProductInventoryReport(gctx)
+		if err != nil {
+			return err
+		}
+		productReport = res
+		return nil
+	})
+
+	// Penjualan hari ini (00:00 sampai sekarang)
+	g.Go(func() error {
+		now := time.Now()
+		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		res, err := ds.repo.Report.GetSalesReport(gctx, startOfDay, now, nil, nil)
+		if err != nil {
+			return err
+		}
+		salesReport = res
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to build dashboard summary", zap.Error(err))
+		return nil, fmt.Errorf("failed to get dashboard summary")
+	}
+
+	response := &report.DashboardResponse{
+		TotalProducts:   productReport.TotalProducts,
+		LowStockCount:   productReport.LowStockCount,
+		OutOfStockCount: productReport.OutOfStockCount,
+		TodaySalesCount: salesReport.TotalSales,
+		Currency:        utils.Currency(),
+	}
+
+	// Nominal hanya untuk admin/super_admin, staff dapat versi redacted
+	if actor.CanAccessRevenueReport() {
+		todayRevenue := utils.RoundMoney(salesReport.TotalRevenue)
+		inventoryValue := utils.RoundMoney(productReport.TotalValue)
+		response.TodayRevenue = &todayRevenue
+		response.InventoryValue = &inventoryValue
+	} else {
+		response.Redacted = true
+	}
+
+	return response, nil
+}
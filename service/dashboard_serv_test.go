@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"inventory-system/dto/report"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKES ==========
+
+// fakeDashboardReportRepo - fakeReportRepo plus ringkasan inventory
+type fakeDashboardReportRepo struct {
+	fakeReportRepo
+	inventoryErr error
+}
+
+func (f *fakeDashboardReportRepo) GetProductInventoryReport(ctx context.Context) (*report.ProductReportResponse, error) {
+	if f.inventoryErr != nil {
+		return nil, f.inventoryErr
+	}
+	return &report.ProductReportResponse{TotalProducts: 12, TotalValue: 2500.456, LowStockCount: 3, OutOfStockCount: 1}, nil
+}
+
+// ========== GET SUMMARY ==========
+
+func TestDashboardGetSummary(t *testing.T) {
+	newUser := func(role model.UserRole) *model.User {
+		return &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: role}
+	}
+
+	tests := []struct {
+		name         string
+		actor        *model.User
+		wantRedacted bool
+	}{
+		{name: "super admin sees financials", actor: newUser(model.RoleSuperAdmin)},
+		{name: "admin sees financials", actor: newUser(model.RoleAdmin)},
+		{name: "staff gets redacted summary", actor: newUser(model.RoleStaff), wantRedacted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := &fakeDashboardReportRepo{}
+			svc := NewDashboardService(&repository.Repository{Report: reports}, zap.NewNop())
+
+			resp, err := svc.GetSummary(context.Background(), tt.actor)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Penjualan hari ini tidak difilter per kasir
+			if reports.userID != nil || reports.categoryID != nil {
+				t.Errorf("sales filters = %v/%v, want none", reports.userID, reports.categoryID)
+			}
+
+			// Shape payload: semua key selalu ada, nominal null untuk staff
+			raw, err := json.Marshal(resp)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			var payload map[string]interface{}
+			if err := json.Unmarshal(raw, &payload); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			for _, key := range []string{"total_products", "low_stock_count", "out_of_stock_count", "today_sales_count", "today_revenue", "inventory_value", "currency", "redacted"} {
+				if _, ok := payload[key]; !ok {
+					t.Errorf("payload missing %q: %s", key, raw)
+				}
+			}
+			if payload["total_products"] != 12.0 || payload["low_stock_count"] != 3.0 || payload["out_of_stock_count"] != 1.0 || payload["today_sales_count"] != 3.0 {
+				t.Errorf("counts = %s", raw)
+			}
+
+			if payload["redacted"] != tt.wantRedacted {
+				t.Errorf("redacted = %v, want %v", payload["redacted"], tt.wantRedacted)
+			}
+			if tt.wantRedacted {
+				if payload["today_revenue"] != nil || payload["inventory_value"] != nil {
+					t.Errorf("staff payload leaks financials: %s", raw)
+				}
+				return
+			}
+			if payload["today_revenue"] != 100.01 || payload["inventory_value"] != 2500.46 {
+				t.Errorf("financials = %v/%v, want rounded 100.01/2500.46", payload["today_revenue"], payload["inventory_value"])
+			}
+		})
+	}
+}
+
+func TestDashboardGetSummaryErrors(t *testing.T) {
+	svc := NewDashboardService(&repository.Repository{Report: &fakeDashboardReportRepo{}}, zap.NewNop())
+	if _, err := svc.GetSummary(context.Background(), nil); err == nil || err.Error() != "permission denied" {
+		t.Errorf("nil actor error = %v, want permission denied", err)
+	}
+
+	reports := &fakeDashboardReportRepo{inventoryErr: fmt.Errorf("connection reset")}
+	svc = NewDashboardService(&repository.Repository{Report: reports}, zap.NewNop())
+	admin := &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: model.RoleAdmin}
+	if _, err := svc.GetSummary(context.Background(), admin); err == nil || err.Error() != "failed to get dashboard summary" {
+		t.Errorf("repository error = %v, want failed to get dashboard summary", err)
+	}
+}
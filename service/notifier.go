@@ -0,0 +1,111 @@
+package service
+
+import (
+	"inventory-system/dto/sale"
+	"inventory-system/model"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// EventType jenis event yang dikirim ke Notifier
+type EventType string
+
+const (
+	EventLowStock          EventType = "low_stock"
+	EventSaleCreated       EventType = "sale.created"
+	EventSaleStatusChanged EventType = "sale.status_changed"
+)
+
+// Event payload notifikasi (email/webhook/log)
+type Event struct {
+	Type       EventType              `json:"type"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// Notifier hook untuk mengirim event ke luar (email, webhook, dll)
+// Emit tidak boleh blocking lama karena dipanggil di request path
+type Notifier interface {
+	Emit(event Event)
+}
+
+// ========== NO-OP ==========
+type noopNotifier struct{}
+
+// NewNoopNotifier notifier default yang tidak melakukan apa-apa
+func NewNoopNotifier() Notifier {
+	return noopNotifier{}
+}
+
+func (noopNotifier) Emit(event Event) {}
+
+// ========== LOGGING ==========
+type logNotifier struct {
+	log *zap.Logger
+}
+
+// NewLogNotifier notifier yang mencatat event ke Zap logger
+func NewLogNotifier(log *zap.Logger) Notifier {
+	return &logNotifier{log: log}
+}
+
+// Emit hanya catat identitas event, payload lengkap (customer, item) cukup lewat webhook yang ditandatangani
+func (ln *logNotifier) Emit(event Event) {
+	fields := []zap.Field{
+		zap.String("type", string(event.Type)),
+		zap.Time("occurred_at", event.OccurredAt),
+	}
+	if s, ok := event.Data["sale"].(*sale.SaleResponse); ok && s != nil {
+		fields = append(fields,
+			zap.String("sale_id", s.ID),
+			zap.String("invoice_number", s.InvoiceNumber),
+			zap.String("status", s.Status))
+	}
+	if previous, ok := event.Data["previous_status"].(string); ok {
+		fields = append(fields, zap.String("previous_status", previous))
+	}
+	if productID, ok := event.Data["product_id"].(string); ok {
+		fields = append(fields, zap.String("product_id", productID))
+	}
+
+	ln.log.Info("Notification event", fields...)
+}
+
+// ========== MULTI ==========
+type multiNotifier []Notifier
+
+// NewMultiNotifier teruskan event ke beberapa notifier sekaligus (mis. log + webhook)
+func NewMultiNotifier(notifiers ...Notifier) Notifier {
+	return multiNotifier(notifiers)
+}
+
+func (mn multiNotifier) Emit(event Event) {
+	for _, n := range mn {
+		n.Emit(event)
+	}
+}
+
+// ========== HELPER ==========
+
+// emitLowStockIfCrossed kirim event low_stock hanya saat stock turun melewati
+// batas minimum (sebelumnya aman, sekarang <= min), bukan di setiap update
+func emitLowStockIfCrossed(n Notifier, p *model.Product, oldStock, oldMinLevel int) {
+	wasLow := oldStock <= oldMinLevel
+	isLow := p.StockQuantity <= p.MinStockLevel
+	if wasLow || !isLow {
+		return
+	}
+
+	n.Emit(Event{
+		Type:       EventLowStock,
+		OccurredAt: time.Now(),
+		Data: map[string]interface{}{
+			"product_id":      p.ID.String(),
+			"product_name":    p.Name,
+			"stock_quantity":  p.StockQuantity,
+			"min_stock_level": p.MinStockLevel,
+			"previous_stock":  oldStock,
+		},
+	})
+}
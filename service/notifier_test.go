@@ -0,0 +1,58 @@
+package service
+
+import (
+	"inventory-system/dto/sale"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// ========== LOGGING ==========
+
+func TestLogNotifierOmitsSalePayload(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	name, phone := "Budi Santoso", "081234567890"
+	resp := &sale.SaleResponse{
+		ID:            "sale-1",
+		InvoiceNumber: "INV-001",
+		Status:        "completed",
+		CustomerName:  &name,
+		CustomerPhone: &phone,
+		Items:         []sale.SaleItemResponse{{ProductName: "Coffee", Quantity: 2}},
+	}
+
+	NewLogNotifier(zap.New(core)).Emit(Event{
+		Type:       EventSaleStatusChanged,
+		OccurredAt: time.Now(),
+		Data:       map[string]interface{}{"previous_status": "pending", "sale": resp},
+	})
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("log entries = %d, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	want := map[string]string{
+		"type":            string(EventSaleStatusChanged),
+		"sale_id":         "sale-1",
+		"invoice_number":  "INV-001",
+		"status":          "completed",
+		"previous_status": "pending",
+	}
+	for key, value := range want {
+		if fields[key] != value {
+			t.Errorf("%s = %v, want %s", key, fields[key], value)
+		}
+	}
+	if _, ok := fields["data"]; ok {
+		t.Error("full payload logged")
+	}
+	for key, value := range fields {
+		if text, ok := value.(string); ok && (strings.Contains(text, name) || strings.Contains(text, phone) || strings.Contains(text, "Coffee")) {
+			t.Errorf("%s leaks sale payload: %s", key, text)
+		}
+	}
+}
@@ -1,12 +1,18 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"inventory-system/dto/product"
 	"inventory-system/model"
 	"inventory-system/repository"
 	"inventory-system/utils"
+	"io"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -14,28 +20,164 @@ import (
 
 type ProductService interface {
 	Create(ctx context.Context, req product.CreateProductRequest) (*product.ProductResponse, error)
+	Validate(ctx context.Context, req product.CreateProductRequest) (*product.ProductValidationResponse, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*product.ProductResponse, error)
+	FindLocation(ctx context.Context, id uuid.UUID) (*product.ProductLocationResponse, error)
 	FindByCategoryID(ctx context.Context, categoryID uuid.UUID) ([]product.ProductResponse, error)
 	FindByShelfID(ctx context.Context, shelfID uuid.UUID) ([]product.ProductResponse, error)
-	FindAll(ctx context.Context, page int, limit int) ([]product.ProductResponse, utils.Pagination, error)
-	FindLowStock(ctx context.Context) ([]product.ProductResponse, error)
+	FindAll(ctx context.Context, page int, limit int, includeDeleted bool) ([]product.ProductResponse, utils.Pagination, error)
+	FindLowStock(ctx context.Context) ([]product.LowStockProductResponse, error)
+	FindCriticalStock(ctx context.Context, req product.CriticalStockRequest) ([]product.LowStockProductResponse, error)
+	FindExpiring(ctx context.Context, req product.ExpiringProductsRequest) ([]product.ExpiringProductResponse, error)
+	FindStaleStock(ctx context.Context, req product.StaleStockRequest) ([]product.StaleStockProductResponse, error)
+	FindNewlyLowStock(ctx context.Context, req product.NewlyLowStockRequest) ([]product.NewlyLowStockProductResponse, error)
 	Update(ctx context.Context, id uuid.UUID, req product.UpdateProductRequest) (*product.ProductResponse, error)
 	UpdateStock(ctx context.Context, id uuid.UUID, req product.UpdateStockRequest) (*product.ProductResponse, error)
+	FindStockLocations(ctx context.Context, id uuid.UUID) (*product.ProductStockLocationsResponse, error)
+	SetLocationStock(ctx context.Context, id uuid.UUID, req product.SetLocationStockRequest) (*product.ProductStockLocationsResponse, error)
+	TransferStock(ctx context.Context, req product.StockTransferRequest) (*product.StockTransferResponse, error)
+	GetMovementSummary(ctx context.Context, id uuid.UUID, req product.MovementSummaryRequest) (*product.MovementSummaryResponse, error)
+	GetStockAt(ctx context.Context, id uuid.UUID, req product.StockAtRequest) (*product.StockAtResponse, error)
+	GetSKUHistory(ctx context.Context, id uuid.UUID) (*product.SKUHistoryResponse, error)
+	Discontinue(ctx context.Context, id uuid.UUID, req product.DiscontinueProductRequest) (*product.ProductResponse, error)
+	Duplicate(ctx context.Context, id uuid.UUID, req product.DuplicateProductRequest) (*product.ProductResponse, error)
+	Recategorize(ctx context.Context, req product.RecategorizeProductsRequest) (*product.RecategorizeProductsResponse, error)
+	AdjustPrices(ctx context.Context, req product.PriceAdjustRequest) (*product.PriceAdjustResponse, error)
+	BulkUpdateMinStock(ctx context.Context, req product.BulkMinStockRequest) (*product.BulkMinStockResponse, error)
+	BulkUpdateStatus(ctx context.Context, req product.BulkStatusRequest) (*product.BulkStatusResponse, error)
+	UploadImage(ctx context.Context, id uuid.UUID, file io.Reader, size int64) (*product.ProductResponse, error)
 	CheckStock(ctx context.Context, id uuid.UUID, requiredQuantity int) (*model.Product, error)
+	CheckStockBatch(ctx context.Context, req product.CheckStockBatchRequest) (*product.CheckStockBatchResponse, error)
+	FindByIDs(ctx context.Context, req product.BatchProductsRequest) (*product.BatchProductsResponse, error)
+	StockCount(ctx context.Context, req product.StockCountRequest, apply bool) (*product.StockCountResponse, error)
+	Restock(ctx context.Context, req product.RestockRequest) (*product.RestockResponse, error)
+	GetMovementFeed(ctx context.Context, req product.MovementFeedRequest, page, limit int) ([]product.MovementFeedEntry, utils.Pagination, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type productService struct {
-	repo *repository.Repository
-	log  *zap.Logger
+	repo     *repository.Repository
+	log      *zap.Logger
+	notifier Notifier
+	opts     ProductOptions
 }
 
-func NewProductService(repo *repository.Repository, log *zap.Logger) ProductService {
-	return &productService{repo: repo, log: log}
+// ProductOptions - pengaturan product service dari config
+type ProductOptions struct {
+	Storage          FileStorage // nil = upload gambar nonaktif
+	MaxImageSize     int64       // bytes, 0 = tanpa batas
+	MaxMinStockLevel int         // batas atas min_stock_level, 0 = tanpa batas
+	DefaultMinStock  int         // dipakai saat min_stock_level tidak diisi, 0 = fallback 5
+	MaxBatchIDs      int         // batas ids per batch fetch, 0 = fallback defaultMaxBatchIDs
+}
+
+// Fallback default min stock level sesuai requirement awal
+const defaultMinStockLevel = 5
+
+// Fallback batas ids per batch fetch (cegah query raksasa)
+const defaultMaxBatchIDs = 100
+
+// Format gambar yang diterima, dicek dari isi file (bukan header dari client)
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+func NewProductService(repo *repository.Repository, log *zap.Logger, notifier Notifier, opts ProductOptions) ProductService {
+	if opts.MaxBatchIDs <= 0 {
+		opts.MaxBatchIDs = defaultMaxBatchIDs
+	}
+	return &productService{repo: repo, log: log, notifier: notifier, opts: opts}
 }
 
 // ========== CREATE ==========
 func (ps *productService) Create(ctx context.Context, req product.CreateProductRequest) (*product.ProductResponse, error) {
+	newProduct, err := ps.prepareProduct(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if newProduct.MinStockLevel > newProduct.StockQuantity {
+		// Boleh, tapi produk langsung masuk low stock
+		utils.LoggerFromContext(ctx).Warn("Product created with min stock level above current stock",
+			zap.String("name", newProduct.Name),
+			zap.Int("stock_quantity", newProduct.StockQuantity),
+			zap.Int("min_stock_level", newProduct.MinStockLevel))
+	}
+
+	// Save to db
+	if err := ps.repo.Product.Create(ctx, newProduct); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to create product", zap.Error(err))
+		if err.Error() == "product sku already exists" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create product")
+	}
+
+	// Response
+	response := ps.convertToResponse(newProduct)
+
+	utils.LoggerFromContext(ctx).Info("Product created",
+		zap.String("product_id", newProduct.ID.String()),
+		zap.String("category_id", newProduct.CategoryID.String()),
+		zap.String("shelf_id", newProduct.ShelfID.String()),
+	)
+	return response, nil
+}
+
+// ========== VALIDATE (DRY RUN CREATE) ==========
+// Jalur validasi sama persis dengan Create (prepareProduct), tidak ada yang disimpan
+func (ps *productService) Validate(ctx context.Context, req product.CreateProductRequest) (*product.ProductValidationResponse, error) {
+	_, err := ps.prepareProduct(ctx, req)
+	if err == nil {
+		return &product.ProductValidationResponse{Valid: true}, nil
+	}
+
+	fields, ok := productValidationFields(err)
+	if !ok {
+		// Error sistem (DB), bukan hasil validasi
+		return nil, err
+	}
+
+	return &product.ProductValidationResponse{Valid: false, Errors: fields}, nil
+}
+
+// productFieldError error validasi prepareProduct untuk satu field request
+// Error() tetap pesan aslinya, jadi respon Create tidak berubah
+type productFieldError struct {
+	field string
+	err   error
+}
+
+func (e *productFieldError) Error() string { return e.err.Error() }
+func (e *productFieldError) Unwrap() error { return e.err }
+
+// fieldError tandai err sebagai error validasi field (nil tetap nil)
+func fieldError(field string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &productFieldError{field: field, err: err}
+}
+
+// productValidationFields error per field dari hasil prepareProduct, ok=false untuk error sistem
+func productValidationFields(err error) (map[string]string, bool) {
+	var validationErr *utils.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Fields, true
+	}
+	var fieldErr *productFieldError
+	if errors.As(err, &fieldErr) {
+		return map[string]string{fieldErr.field: fieldErr.Error()}, true
+	}
+	return nil, false
+}
+
+// prepareProduct validasi request create & bangun model (belum disimpan)
+// Dipakai Create dan Validate supaya aturan validasinya tidak pernah beda
+func (ps *productService) prepareProduct(ctx context.Context, req product.CreateProductRequest) (*model.Product, error) {
 	// Validate input
 	if err := utils.ValidateStruct(req); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
@@ -44,53 +186,111 @@ func (ps *productService) Create(ctx context.Context, req product.CreateProductR
 	// Check Category ID
 	categoryID, err := uuid.Parse(req.CategoryID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid category ID format")
+		return nil, fieldError("CategoryID", fmt.Errorf("invalid category ID format"))
 	}
 	if _, err := ps.repo.Category.FindByID(ctx, categoryID); err != nil {
-		return nil, fmt.Errorf("category not found")
+		return nil, fieldError("CategoryID", fmt.Errorf("category not found"))
 	}
 
 	// Check Shelf ID
 	shelfID, err := uuid.Parse(req.ShelfID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid shelf ID format")
+		return nil, fieldError("ShelfID", fmt.Errorf("invalid shelf ID format"))
 	}
-	if _, err := ps.repo.Shelf.FindByID(ctx, shelfID); err != nil {
-		return nil, fmt.Errorf("shelf not found")
+	if err := ps.checkShelfAvailable(ctx, shelfID); err != nil {
+		return nil, fieldError("ShelfID", err)
+	}
+
+	expiryDate, err := parseExpiryDate(req.ExpiryDate)
+	if err != nil {
+		return nil, fieldError("ExpiryDate", err)
+	}
+
+	sku := normalizeSKU(req.SKU)
+	if err := ps.checkSKUAvailable(ctx, sku, uuid.Nil); err != nil {
+		return nil, fieldError("SKU", err)
 	}
 
 	// Prepare product object
 	newProduct := &model.Product{
-		CategoryID:    categoryID,
-		ShelfID:       shelfID,
-		Name:          req.Name,
-		Description:   req.Description,
-		UnitPrice:     req.UnitPrice,
-		CostPrice:     req.CostPrice,
-		StockQuantity: req.StockQuantity,
-		MinStockLevel: req.MinStockLevel,
+		CategoryID:      categoryID,
+		ShelfID:         shelfID,
+		SKU:             sku,
+		Name:            req.Name,
+		Description:     req.Description,
+		UnitPrice:       req.UnitPrice,
+		CostPrice:       req.CostPrice,
+		StockQuantity:   req.StockQuantity,
+		MinStockLevel:   req.MinStockLevel,
+		ReorderQuantity: req.ReorderQuantity,
+		ImageURL:        req.ImageURL,
+		ExpiryDate:      expiryDate,
 	}
 
 	// Set default min stock level
 	if newProduct.MinStockLevel == 0 {
-		newProduct.MinStockLevel = 5 // DEFAULT sesuai requirement
+		newProduct.MinStockLevel = ps.opts.DefaultMinStock
+		if newProduct.MinStockLevel <= 0 {
+			newProduct.MinStockLevel = defaultMinStockLevel
+		}
+	}
+	if err := ps.validateMinStockLevel(newProduct.MinStockLevel); err != nil {
+		return nil, fieldError("MinStockLevel", err)
 	}
 
-	// Save to db
-	if err := ps.repo.Product.Create(ctx, newProduct); err != nil {
-		ps.log.Error("Failed to create product", zap.Error(err))
-		return nil, fmt.Errorf("failed to create product")
+	return newProduct, nil
+}
+
+// ========== DUPLICATE ==========
+// Clone product: category/shelf/harga/min stock sama, ID baru, stock 0
+func (ps *productService) Duplicate(ctx context.Context, id uuid.UUID, req product.DuplicateProductRequest) (*product.ProductResponse, error) {
+	// Validate input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Response
-	response := ps.convertToResponse(newProduct)
+	source, err := ps.repo.Product.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	// Rak source bisa saja sudah di warehouse nonaktif, aturan sama dengan Create
+	if err := ps.checkShelfAvailable(ctx, source.ShelfID); err != nil {
+		return nil, err
+	}
+
+	// Default name: "<source> (Copy)", dipotong agar muat di kolom name (200)
+	name := source.Name + " (Copy)"
+	if req.Name != nil {
+		name = *req.Name
+	} else if len([]rune(name)) > 200 {
+		name = string([]rune(source.Name)[:200-len(" (Copy)")]) + " (Copy)"
+	}
+
+	newProduct := &model.Product{
+		CategoryID:      source.CategoryID,
+		ShelfID:         source.ShelfID,
+		Name:            name,
+		Description:     source.Description,
+		UnitPrice:       source.UnitPrice,
+		CostPrice:       source.CostPrice,
+		StockQuantity:   0,
+		MinStockLevel:   source.MinStockLevel,
+		ReorderQuantity: source.ReorderQuantity,
+		ImageURL:        source.ImageURL,
+	}
 
-	ps.log.Info("Product created",
+	// Save to db (metadata baru di-generate di repository)
+	if err := ps.repo.Product.Create(ctx, newProduct); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to duplicate product", zap.Error(err))
+		return nil, fmt.Errorf("failed to duplicate product")
+	}
+
+	utils.LoggerFromContext(ctx).Info("Product duplicated",
+		zap.String("source_id", source.ID.String()),
 		zap.String("product_id", newProduct.ID.String()),
-		zap.String("category_id", newProduct.CategoryID.String()),
-		zap.String("shelf_id", newProduct.ShelfID.String()),
 	)
-	return response, nil
+	return ps.convertToResponse(newProduct), nil
 }
 
 // ========== FIND BY ID ==========
@@ -103,6 +303,40 @@ func (ps *productService) FindByID(ctx context.Context, id uuid.UUID) (*product.
 	return ps.convertToResponse(foundProduct), nil
 }
 
+// ========== FIND LOCATION ==========
+func (ps *productService) FindLocation(ctx context.Context, id uuid.UUID) (*product.ProductLocationResponse, error) {
+	location, err := ps.repo.Product.FindLocation(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	response := &product.ProductLocationResponse{
+		ProductID:     location.ProductID.String(),
+		ProductName:   location.ProductName,
+		WarehouseCode: location.WarehouseCode,
+		WarehouseName: location.WarehouseName,
+		ShelfCode:     location.ShelfCode,
+		ShelfName:     location.ShelfName,
+		Orphaned:      location.ShelfID == nil || location.WarehouseID == nil,
+	}
+
+	// Breadcrumb hanya dari bagian yang masih ada
+	var parts []string
+	if location.WarehouseID != nil {
+		id := location.WarehouseID.String()
+		response.WarehouseID = &id
+		parts = append(parts, *location.WarehouseName)
+	}
+	if location.ShelfID != nil {
+		id := location.ShelfID.String()
+		response.ShelfID = &id
+		parts = append(parts, *location.ShelfName)
+	}
+	response.Path = strings.Join(parts, " / ")
+
+	return response, nil
+}
+
 // ========== FIND BY CATEGORY ==========
 func (ps *productService) FindByCategoryID(ctx context.Context, categoryID uuid.UUID) ([]product.ProductResponse, error) {
 	// Validate category exists
@@ -148,18 +382,18 @@ func (ps *productService) FindByShelfID(ctx context.Context, shelfID uuid.UUID)
 }
 
 // ========== FIND ALL WITH PAGINATION ==========
-func (ps *productService) FindAll(ctx context.Context, page int, limit int) ([]product.ProductResponse, utils.Pagination, error) {
+func (ps *productService) FindAll(ctx context.Context, page int, limit int, includeDeleted bool) ([]product.ProductResponse, utils.Pagination, error) {
 	// Setup pagination
 	pagination := utils.NewPagination(page, limit)
 
 	// Get data with pagination
-	products, err := ps.repo.Product.FindAll(ctx, pagination.Limit, pagination.Offset())
+	products, err := ps.repo.Product.FindAll(ctx, pagination.Limit, pagination.Offset(), includeDeleted)
 	if err != nil {
 		return nil, pagination, fmt.Errorf("failed to get products")
 	}
 
 	// Get total count
-	total, err := ps.repo.Product.CountAll(ctx)
+	total, err := ps.repo.Product.CountAll(ctx, includeDeleted)
 	if err != nil {
 		return nil, pagination, fmt.Errorf("failed to count products")
 	}
@@ -177,24 +411,160 @@ func (ps *productService) FindAll(ctx context.Context, page int, limit int) ([]p
 }
 
 // ========== FIND LOW STOCK ==========
-func (ps *productService) FindLowStock(ctx context.Context) ([]product.ProductResponse, error) {
+// Sertakan deficit & saran jumlah order (reorder_quantity - stok sekarang)
+func (ps *productService) FindLowStock(ctx context.Context) ([]product.LowStockProductResponse, error) {
 	products, err := ps.repo.Product.FindLowStock(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get low stock products")
 	}
 
 	// Convert to response
-	responses := make([]product.ProductResponse, 0, len(products))
+	responses := make([]product.LowStockProductResponse, 0, len(products))
 	for _, p := range products {
-		responses = append(responses, *ps.convertToResponse(&p))
+		responses = append(responses, product.LowStockProductResponse{
+			ProductResponse: *ps.convertToResponse(&p),
+			StockDeficit:    stockDeficit(p.MinStockLevel, p.StockQuantity),
+			SuggestedOrder:  suggestedOrder(p.ReorderQuantity, p.StockQuantity),
+		})
+	}
+
+	utils.LoggerFromContext(ctx).Info("Low stock products fetched", zap.Int("count", len(responses)))
+	return responses, nil
+}
+
+// ========== FIND CRITICAL STOCK ==========
+// Lebih ketat dari low stock: stok <= min_stock_level * percent, paling kritis dulu
+func (ps *productService) FindCriticalStock(ctx context.Context, req product.CriticalStockRequest) ([]product.LowStockProductResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: percent must be greater than 0 and at most 1")
+	}
+
+	products, err := ps.repo.Product.FindCriticalStock(ctx, req.Percent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get critical stock products")
+	}
+
+	responses := make([]product.LowStockProductResponse, 0, len(products))
+	for _, p := range products {
+		responses = append(responses, product.LowStockProductResponse{
+			ProductResponse: *ps.convertToResponse(&p),
+			StockDeficit:    stockDeficit(p.MinStockLevel, p.StockQuantity),
+			SuggestedOrder:  suggestedOrder(p.ReorderQuantity, p.StockQuantity),
+		})
+	}
+
+	utils.LoggerFromContext(ctx).Info("Critical stock products fetched",
+		zap.Float64("percent", req.Percent),
+		zap.Int("count", len(responses)))
+	return responses, nil
+}
+
+// ========== FIND EXPIRING ==========
+// Produk yang kedaluwarsa sampai tanggal before (inclusive), yang sudah lewat ikut ditampilkan
+func (ps *productService) FindExpiring(ctx context.Context, req product.ExpiringProductsRequest) ([]product.ExpiringProductResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	before, err := time.Parse("2006-01-02", req.Before)
+	if err != nil {
+		return nil, fmt.Errorf("invalid before date format. Use YYYY-MM-DD")
+	}
+
+	products, err := ps.repo.Product.FindExpiringBefore(ctx, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expiring products")
+	}
+
+	today := time.Now()
+
+	responses := make([]product.ExpiringProductResponse, 0, len(products))
+	for _, p := range products {
+		daysLeft := daysUntil(today, *p.ExpiryDate)
+		responses = append(responses, product.ExpiringProductResponse{
+			ProductResponse: *ps.convertToResponse(&p),
+			DaysUntilExpiry: daysLeft,
+			IsExpired:       daysLeft < 0,
+		})
+	}
+
+	utils.LoggerFromContext(ctx).Info("Expiring products fetched",
+		zap.String("before", req.Before),
+		zap.Int("count", len(responses)))
+	return responses, nil
+}
+
+// ========== FIND STALE STOCK ==========
+// Produk yang updated_at-nya sebelum tanggal before (awal hari), paling lama tidak disentuh dulu
+func (ps *productService) FindStaleStock(ctx context.Context, req product.StaleStockRequest) ([]product.StaleStockProductResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	before, err := time.Parse("2006-01-02", req.Before)
+	if err != nil {
+		return nil, fmt.Errorf("invalid before date format. Use YYYY-MM-DD")
+	}
+
+	products, err := ps.repo.Product.FindStaleStock(ctx, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stale stock products")
+	}
+
+	today := time.Now()
+
+	responses := make([]product.StaleStockProductResponse, 0, len(products))
+	for _, p := range products {
+		responses = append(responses, product.StaleStockProductResponse{
+			ProductResponse: *ps.convertToResponse(&p),
+			DaysSinceUpdate: daysUntil(p.UpdatedAt, today),
+		})
+	}
+
+	return responses, nil
+}
+
+// ========== FIND NEWLY LOW STOCK ==========
+// Read-only preview sebelum ganti min stock: produk yang belum low tapi jadi low dengan threshold baru
+// Threshold dibatasi sama seperti min_stock_level (INVENTORY_MAX_MIN_STOCK_LEVEL)
+func (ps *productService) FindNewlyLowStock(ctx context.Context, req product.NewlyLowStockRequest) ([]product.NewlyLowStockProductResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	threshold := *req.Threshold
+	if err := ps.validateMinStockLevel(threshold); err != nil {
+		return nil, fmt.Errorf("validation failed: threshold must not exceed %d", ps.opts.MaxMinStockLevel)
+	}
+
+	products, err := ps.repo.Product.FindStockUpTo(ctx, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get newly low stock products")
+	}
+
+	responses := make([]product.NewlyLowStockProductResponse, 0, len(products))
+	for _, p := range products {
+		if !isNewlyLowStock(&p, threshold) {
+			continue
+		}
+		responses = append(responses, product.NewlyLowStockProductResponse{
+			ProductResponse:  *ps.convertToResponse(&p),
+			Threshold:        threshold,
+			ThresholdDeficit: threshold - p.StockQuantity,
+		})
 	}
 
-	ps.log.Info("Low stock products fetched", zap.Int("count", len(responses)))
 	return responses, nil
 }
 
 // ========== UPDATE ==========
+// Full update hanya untuk admin & super_admin, staff cukup lewat UpdateStock
+// Role dicek di sini juga (user dari context), tidak bergantung pada routing
 func (ps *productService) Update(ctx context.Context, id uuid.UUID, req product.UpdateProductRequest) (*product.ProductResponse, error) {
+	actor := utils.GetUserFromContext(ctx)
+	if actor == nil || !actor.CanManageMasterData() {
+		return nil, fmt.Errorf("permission denied: only admin can update product details")
+	}
+
 	// Validate input
 	if err := utils.ValidateStruct(req); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
@@ -207,6 +577,7 @@ func (ps *productService) Update(ctx context.Context, id uuid.UUID, req product.
 	}
 
 	updated := false
+	oldStock, oldMinLevel := productToUpdate.StockQuantity, productToUpdate.MinStockLevel
 
 	// Check and update category ID if provided
 	if req.CategoryID != nil {
@@ -235,11 +606,30 @@ func (ps *productService) Update(ctx context.Context, id uuid.UUID, req product.
 			return nil, fmt.Errorf("shelf not found")
 		}
 		if shelfID != productToUpdate.ShelfID {
+			// Pindah rak: warehouse tujuan harus aktif
+			if err := ps.checkShelfAvailable(ctx, shelfID); err != nil {
+				return nil, err
+			}
 			productToUpdate.ShelfID = shelfID
 			updated = true
 		}
 	}
 
+	// SKU disimpan lewat setProductSKU (di UpdateWithChanges) supaya perubahan tercatat di sku_history
+	// Dicek di awal supaya konflik SKU tidak meninggalkan update setengah jadi
+	var newSKU *string
+	skuChanged := false
+	if req.SKU != nil {
+		newSKU = normalizeSKU(*req.SKU)
+		if formatSKU(newSKU) != formatSKU(productToUpdate.SKU) {
+			if err := ps.checkSKUAvailable(ctx, newSKU, id); err != nil {
+				return nil, err
+			}
+			skuChanged = true
+			updated = true
+		}
+	}
+
 	// Update other fields
 	if req.Name != nil && *req.Name != productToUpdate.Name {
 		productToUpdate.Name = *req.Name
@@ -257,25 +647,98 @@ func (ps *productService) Update(ctx context.Context, id uuid.UUID, req product.
 		productToUpdate.CostPrice = *req.CostPrice
 		updated = true
 	}
+	// Stock disimpan lewat setProductStock (di UpdateWithChanges) supaya stok per lokasi & ledger ikut tersinkron
+	newStock := oldStock
 	if req.StockQuantity != nil && *req.StockQuantity != productToUpdate.StockQuantity {
-		productToUpdate.StockQuantity = *req.StockQuantity
+		newStock = *req.StockQuantity
 		updated = true
 	}
+	// Stok bertambah masuk ke rak produk (rak baru jika pindah), warehouse-nya harus aktif
+	if newStock > oldStock {
+		if err := ps.checkShelfAvailable(ctx, productToUpdate.ShelfID); err != nil {
+			return nil, err
+		}
+	}
 	if req.MinStockLevel != nil && *req.MinStockLevel != productToUpdate.MinStockLevel {
+		if err := ps.validateMinStockLevel(*req.MinStockLevel); err != nil {
+			return nil, err
+		}
 		productToUpdate.MinStockLevel = *req.MinStockLevel
 		updated = true
 	}
+	if req.ReorderQuantity != nil && *req.ReorderQuantity != productToUpdate.ReorderQuantity {
+		productToUpdate.ReorderQuantity = *req.ReorderQuantity
+		updated = true
+	}
+	if req.ImageURL != nil && *req.ImageURL != productToUpdate.ImageURL {
+		productToUpdate.ImageURL = *req.ImageURL
+		updated = true
+	}
+	if req.ExpiryDate != nil {
+		expiryDate, err := parseExpiryDate(*req.ExpiryDate)
+		if err != nil {
+			return nil, err
+		}
+		if formatExpiryDate(expiryDate) != formatExpiryDate(productToUpdate.ExpiryDate) {
+			productToUpdate.ExpiryDate = expiryDate
+			updated = true
+		}
+	}
 
 	// Save if changes were made
+	// Detail, stok & SKU dalam satu transaction: gagal di tengah = tidak ada yang berubah
 	if updated {
-		if err := ps.repo.Product.Update(ctx, productToUpdate); err != nil {
+		changes := repository.ProductUpdate{
+			Product:    productToUpdate,
+			SKUChanged: skuChanged,
+			SKU:        newSKU,
+			ChangedBy:  &actor.ID,
+		}
+		if newStock != oldStock {
+			changes.Stock = &newStock
+			changes.Movement = model.StockMovement{MovementType: model.StockMovementAdjustment}
+		}
+
+		if err := ps.repo.Product.UpdateWithChanges(ctx, changes); err != nil {
+			if err.Error() == "product sku already exists" || err.Error() == "product not found" {
+				return nil, err
+			}
 			return nil, fmt.Errorf("failed to update product")
 		}
+
+		productToUpdate.StockQuantity = newStock
+		if skuChanged {
+			productToUpdate.SKU = newSKU
+		}
+		emitLowStockIfCrossed(ps.notifier, productToUpdate, oldStock, oldMinLevel)
 	}
 
 	return ps.convertToResponse(productToUpdate), nil
 }
 
+// ========== DISCONTINUE ==========
+// Discontinued: tidak bisa dijual lagi, tapi tetap ada di histori & report
+func (ps *productService) Discontinue(ctx context.Context, id uuid.UUID, req product.DiscontinueProductRequest) (*product.ProductResponse, error) {
+	existingProduct, err := ps.repo.Product.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	status := model.ProductStatusDiscontinued
+	if req.Discontinued != nil && !*req.Discontinued {
+		status = model.ProductStatusActive
+	}
+
+	if existingProduct.Status != status {
+		if err := ps.repo.Product.UpdateStatus(ctx, id, status); err != nil {
+			return nil, fmt.Errorf("failed to update product status")
+		}
+		existingProduct.Status = status
+	}
+
+	return ps.convertToResponse(existingProduct), nil
+}
+
 // ========== UPDATE STOCK ========== (UNTUK STAFF)
 func (ps *productService) UpdateStock(ctx context.Context, id uuid.UUID, req product.UpdateStockRequest) (*product.ProductResponse, error) {
 	// Validate DTO
@@ -289,8 +752,21 @@ func (ps *productService) UpdateStock(ctx context.Context, id uuid.UUID, req pro
 		return nil, fmt.Errorf("product not found")
 	}
 
+	// Stok bertambah = restock (barang diterima), berkurang = adjustment
+	movement := model.StockMovement{MovementType: model.StockMovementAdjustment}
+	if req.Quantity > existingProduct.StockQuantity {
+		// Stok baru masuk ke rak utama, warehouse-nya harus aktif
+		if err := ps.checkShelfAvailable(ctx, existingProduct.ShelfID); err != nil {
+			return nil, err
+		}
+		movement.MovementType = model.StockMovementRestock
+	}
+	if req.Notes != "" {
+		movement.Notes = &req.Notes
+	}
+
 	// Update stock in database
-	if err := ps.repo.Product.UpdateStock(ctx, id, req.Quantity); err != nil {
+	if err := ps.repo.Product.UpdateStock(ctx, id, req.Quantity, movement); err != nil {
 		return nil, fmt.Errorf("failed to update stock")
 	}
 
@@ -302,7 +778,7 @@ func (ps *productService) UpdateStock(ctx context.Context, id uuid.UUID, req pro
 
 	// Log stock change for audit trail
 	change := req.Quantity - existingProduct.StockQuantity
-	ps.log.Info("Product stock updated",
+	utils.LoggerFromContext(ctx).Info("Product stock updated",
 		zap.String("product_id", id.String()),
 		zap.String("product_name", existingProduct.Name),
 		zap.Int("old_stock", existingProduct.StockQuantity),
@@ -310,56 +786,1096 @@ func (ps *productService) UpdateStock(ctx context.Context, id uuid.UUID, req pro
 		zap.Int("change", change),
 		zap.String("notes", req.Notes))
 
+	emitLowStockIfCrossed(ps.notifier, updatedProduct, existingProduct.StockQuantity, existingProduct.MinStockLevel)
+
 	return ps.convertToResponse(updatedProduct), nil
 }
 
-// ========== CHECK STOCK ========== (UNTUK SALE VALIDATION)
-func (ps *productService) CheckStock(ctx context.Context, id uuid.UUID, requiredQuantity int) (*model.Product, error) {
-	if requiredQuantity <= 0 {
-		return nil, fmt.Errorf("required quantity must be positive")
+// ========== STOCK LOCATIONS (MULTI WAREHOUSE) ==========
+// Produk single location (belum punya baris lokasi) ditampilkan sebagai satu rak utama dengan seluruh stok
+func (ps *productService) FindStockLocations(ctx context.Context, id uuid.UUID) (*product.ProductStockLocationsResponse, error) {
+	existingProduct, err := ps.repo.Product.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
 	}
 
-	product, err := ps.repo.Product.CheckStock(ctx, id, requiredQuantity)
+	locations, err := ps.repo.StockLocation.FindByProduct(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("stock check failed: %w", err)
+		return nil, fmt.Errorf("failed to get stock locations")
 	}
 
-	return product, nil
+	if len(locations) == 0 {
+		primary, err := ps.repo.Product.FindLocation(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("product not found")
+		}
+		locations = append(locations, model.ProductStockLocation{
+			ProductID:     id,
+			ShelfID:       existingProduct.ShelfID,
+			ShelfCode:     primary.ShelfCode,
+			ShelfName:     primary.ShelfName,
+			WarehouseID:   primary.WarehouseID,
+			WarehouseCode: primary.WarehouseCode,
+			WarehouseName: primary.WarehouseName,
+			Quantity:      existingProduct.StockQuantity,
+		})
+	}
+
+	response := &product.ProductStockLocationsResponse{
+		ProductID:   existingProduct.ID.String(),
+		ProductName: existingProduct.Name,
+		TotalStock:  existingProduct.StockQuantity,
+		Locations:   make([]product.StockLocationResponse, 0, len(locations)),
+	}
+	for _, location := range locations {
+		item := product.StockLocationResponse{
+			ShelfID:       location.ShelfID.String(),
+			ShelfCode:     location.ShelfCode,
+			ShelfName:     location.ShelfName,
+			WarehouseCode: location.WarehouseCode,
+			WarehouseName: location.WarehouseName,
+			Quantity:      location.Quantity,
+			IsPrimary:     location.ShelfID == existingProduct.ShelfID,
+		}
+		if location.WarehouseID != nil {
+			warehouseID := location.WarehouseID.String()
+			item.WarehouseID = &warehouseID
+		}
+		response.Locations = append(response.Locations, item)
+	}
+
+	return response, nil
 }
 
-// ========== DELETE ==========
-func (ps *productService) Delete(ctx context.Context, id uuid.UUID) error {
-	// Check if product exists
-	if _, err := ps.repo.Product.FindByID(ctx, id); err != nil {
-		return fmt.Errorf("product not found")
+// SetLocationStock set stok di satu rak, total produk = jumlah semua rak
+func (ps *productService) SetLocationStock(ctx context.Context, id uuid.UUID, req product.SetLocationStockRequest) (*product.ProductStockLocationsResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Delete product
-	if err := ps.repo.Product.Delete(ctx, id); err != nil {
-		return fmt.Errorf("failed to delete product")
+	shelfID, err := uuid.Parse(req.ShelfID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shelf ID format")
+	}
+	if err := ps.checkShelfAvailable(ctx, shelfID); err != nil {
+		return nil, err
 	}
 
-	ps.log.Info("Product deleted", zap.String("product_id", id.String()))
-	return nil
+	existingProduct, err := ps.repo.Product.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	movement := model.StockMovement{MovementType: model.StockMovementAdjustment}
+	if req.Notes != "" {
+		movement.Notes = &req.Notes
+	}
+
+	total, err := ps.repo.StockLocation.SetQuantity(ctx, id, shelfID, *req.Quantity, movement)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update location stock")
+	}
+
+	utils.LoggerFromContext(ctx).Info("Product location stock updated",
+		zap.String("product_id", id.String()),
+		zap.String("shelf_id", shelfID.String()),
+		zap.Int("quantity", *req.Quantity),
+		zap.Int("old_total", existingProduct.StockQuantity),
+		zap.Int("new_total", total),
+		zap.String("notes", req.Notes))
+
+	oldStock := existingProduct.StockQuantity
+	existingProduct.StockQuantity = total
+	emitLowStockIfCrossed(ps.notifier, existingProduct, oldStock, existingProduct.MinStockLevel)
+
+	return ps.FindStockLocations(ctx, id)
 }
 
-// ========== HELPER: CONVERT TO RESPONSE ==========
-func (ps *productService) convertToResponse(p *model.Product) *product.ProductResponse {
-	// Calculate if low stock
-	isLowStock := p.StockQuantity <= p.MinStockLevel
+// TransferStock pindah stok antar rak (boleh beda warehouse), total produk tidak berubah
+func (ps *productService) TransferStock(ctx context.Context, req product.StockTransferRequest) (*product.StockTransferResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	productID, err := uuid.Parse(req.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product ID format")
+	}
+	fromShelfID, err := uuid.Parse(req.FromShelfID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source shelf ID format")
+	}
+	toShelfID, err := uuid.Parse(req.ToShelfID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination shelf ID format")
+	}
+
+	if _, err := ps.repo.Product.FindByID(ctx, productID); err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+	if _, err := ps.repo.Shelf.FindByID(ctx, fromShelfID); err != nil {
+		return nil, fmt.Errorf("source shelf not found")
+	}
+	// Rak tujuan menerima stok baru, warehouse-nya harus aktif
+	if err := ps.checkShelfAvailable(ctx, toShelfID); err != nil {
+		if err.Error() == "shelf not found" {
+			return nil, fmt.Errorf("destination shelf not found")
+		}
+		return nil, err
+	}
+
+	// Pasangan movement keluar/masuk dihubungkan lewat reference_id yang sama
+	transferID := uuid.New()
+	movement := model.StockMovement{ReferenceID: &transferID}
+	if req.Notes != "" {
+		movement.Notes = &req.Notes
+	}
+
+	if _, err := ps.repo.StockLocation.Transfer(ctx, productID, fromShelfID, toShelfID, req.Quantity, movement); err != nil {
+		if err.Error() == "product not found" || err.Error() == "insufficient stock at source shelf" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to transfer stock")
+	}
+
+	stock, err := ps.FindStockLocations(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &product.StockTransferResponse{
+		TransferID:  transferID.String(),
+		FromShelfID: fromShelfID.String(),
+		ToShelfID:   toShelfID.String(),
+		Quantity:    req.Quantity,
+		Stock:       *stock,
+	}, nil
+}
+
+// ========== STOCK MOVEMENT SUMMARY ==========
+// Total ledger stok per jenis movement dalam periode (untuk trend analysis)
+func (ps *productService) GetMovementSummary(ctx context.Context, id uuid.UUID, req product.MovementSummaryRequest) (*product.MovementSummaryResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+	}
+
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+	}
+
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start date cannot be after end date")
+	}
+
+	// Sama dengan report: max 1 tahun
+	if endDate.Sub(startDate) > 365*24*time.Hour {
+		return nil, fmt.Errorf("date range cannot exceed 1 year")
+	}
+
+	existingProduct, err := ps.repo.Product.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	summaries, err := ps.repo.StockMovement.SummaryByType(ctx, id, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock movement summary")
+	}
+
+	byType := make(map[model.StockMovementType]model.StockMovementSummary, len(summaries))
+	for _, summary := range summaries {
+		byType[summary.MovementType] = summary
+	}
+
+	response := &product.MovementSummaryResponse{
+		ProductID:   existingProduct.ID.String(),
+		ProductName: existingProduct.Name,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Movements:   make([]product.MovementTypeSummary, 0, len(model.StockMovementTypes)),
+	}
+
+	// Urutan tetap, jenis tanpa movement tetap muncul dengan nilai 0
+	for _, movementType := range model.StockMovementTypes {
+		summary := byType[movementType]
+		response.Movements = append(response.Movements, product.MovementTypeSummary{
+			MovementType:  string(movementType),
+			MovementCount: summary.MovementCount,
+			QuantityIn:    summary.QuantityIn,
+			QuantityOut:   summary.QuantityOut,
+			NetQuantity:   summary.QuantityIn - summary.QuantityOut,
+		})
+		response.TotalIn += summary.QuantityIn
+		response.TotalOut += summary.QuantityOut
+	}
+	response.NetChange = response.TotalIn - response.TotalOut
+
+	return response, nil
+}
+
+// ========== SKU HISTORY ==========
+// Riwayat perubahan SKU (terbaru dulu), sale & ledger tetap refer ke product id
+func (ps *productService) GetSKUHistory(ctx context.Context, id uuid.UUID) (*product.SKUHistoryResponse, error) {
+	existingProduct, err := ps.repo.Product.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	history, err := ps.repo.Product.FindSKUHistory(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sku history")
+	}
+
+	response := &product.SKUHistoryResponse{
+		ProductID:   existingProduct.ID.String(),
+		ProductName: existingProduct.Name,
+		CurrentSKU:  existingProduct.SKU,
+		History:     make([]product.SKUHistoryEntry, 0, len(history)),
+	}
+	for _, entry := range history {
+		var changedBy *string
+		if entry.ChangedBy != nil {
+			userID := entry.ChangedBy.String()
+			changedBy = &userID
+		}
+		response.History = append(response.History, product.SKUHistoryEntry{
+			OldSKU:            entry.OldSKU,
+			NewSKU:            entry.NewSKU,
+			ChangedBy:         changedBy,
+			ChangedByUsername: entry.ChangedByUsername,
+			ChangedAt:         entry.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+// ========== STOCK AT DATE ==========
+// Stok di akhir tanggal tertentu (audit), direkonstruksi mundur dari ledger stok
+func (ps *productService) GetStockAt(ctx context.Context, id uuid.UUID, req product.StockAtRequest) (*product.StockAtResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date format. Use YYYY-MM-DD")
+	}
+
+	existingProduct, err := ps.repo.Product.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	response := &product.StockAtResponse{
+		ProductID:    existingProduct.ID.String(),
+		ProductName:  existingProduct.Name,
+		Date:         req.Date,
+		CurrentStock: existingProduct.StockQuantity,
+	}
+
+	// Posisi akhir hari = sebelum awal hari berikutnya
+	endOfDay := date.AddDate(0, 0, 1)
+
+	// Produk belum ada di tanggal tersebut, stok awal saat create tidak tercatat di ledger
+	if !endOfDay.After(existingProduct.CreatedAt) {
+		return response, nil
+	}
+
+	if !endOfDay.Before(time.Now()) {
+		response.Quantity = existingProduct.StockQuantity
+		return response, nil
+	}
+
+	quantity, err := ps.repo.StockMovement.StockAtTime(ctx, id, endOfDay)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get stock at date")
+	}
+	response.Quantity = quantity
+
+	return response, nil
+}
+
+// ========== RECATEGORIZE (BULK) ==========
+func (ps *productService) Recategorize(ctx context.Context, req product.RecategorizeProductsRequest) (*product.RecategorizeProductsResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	categoryID, err := uuid.Parse(req.CategoryID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid category ID format")
+	}
+	if _, err := ps.repo.Category.FindByID(ctx, categoryID); err != nil {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	// Dedup ID supaya jumlah row ter-update bisa dibandingkan
+	seen := make(map[uuid.UUID]bool, len(req.ProductIDs))
+	ids := make([]uuid.UUID, 0, len(req.ProductIDs))
+	for _, idStr := range req.ProductIDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID format")
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	updated, err := ps.repo.Product.UpdateCategoryBatch(ctx, ids, categoryID)
+	if err != nil {
+		if err.Error() == "one or more products not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update product categories")
+	}
+
+	return &product.RecategorizeProductsResponse{
+		CategoryID: categoryID.String(),
+		Updated:    updated,
+	}, nil
+}
+
+// ========== PRICE ADJUST (PER CATEGORY) ==========
+// Semua produk category berubah atau tidak sama sekali, tiap perubahan tercatat di price_history
+func (ps *productService) AdjustPrices(ctx context.Context, req product.PriceAdjustRequest) (*product.PriceAdjustResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	var unitPercent, costPercent float64
+	if req.UnitPricePercent != nil {
+		unitPercent = *req.UnitPricePercent
+	}
+	if req.CostPricePercent != nil {
+		costPercent = *req.CostPricePercent
+	}
+	if unitPercent == 0 && costPercent == 0 {
+		return nil, fmt.Errorf("validation failed: unit_price_percent or cost_price_percent is required")
+	}
+
+	categoryID, err := uuid.Parse(req.CategoryID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid category ID format")
+	}
+	if _, err := ps.repo.Category.FindByID(ctx, categoryID); err != nil {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	var changedBy *uuid.UUID
+	if actor := utils.GetUserFromContext(ctx); actor != nil {
+		changedBy = &actor.ID
+	}
+
+	updated, err := ps.repo.Product.AdjustPricesByCategory(ctx, categoryID, unitPercent, costPercent, changedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to adjust product prices")
+	}
+
+	utils.LoggerFromContext(ctx).Info("Product prices adjusted",
+		zap.String("category_id", categoryID.String()),
+		zap.Float64("unit_price_percent", unitPercent),
+		zap.Float64("cost_price_percent", costPercent),
+		zap.Int("updated", updated))
+
+	return &product.PriceAdjustResponse{
+		CategoryID:       categoryID.String(),
+		UnitPricePercent: unitPercent,
+		CostPricePercent: costPercent,
+		Updated:          updated,
+	}, nil
+}
+
+// ========== BULK STATUS ==========
+// Satu transaction: ada produk tidak ditemukan = tidak ada yang berubah
+func (ps *productService) BulkUpdateStatus(ctx context.Context, req product.BulkStatusRequest) (*product.BulkStatusResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Dedup ID supaya jumlah produk bisa dibandingkan
+	seen := make(map[uuid.UUID]bool, len(req.ProductIDs))
+	ids := make([]uuid.UUID, 0, len(req.ProductIDs))
+	for _, idStr := range req.ProductIDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID format")
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	status := model.ProductStatus(req.Status)
+	updated, err := ps.repo.Product.UpdateStatusBatch(ctx, ids, status)
+	if err != nil {
+		if err.Error() == "one or more products not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update product statuses")
+	}
+
+	// Belum ada tabel audit, jejak perubahan dicatat di log
+	utils.LoggerFromContext(ctx).Info("Product statuses updated",
+		zap.String("status", req.Status),
+		zap.Strings("product_ids", req.ProductIDs),
+		zap.Int("updated", updated))
+
+	return &product.BulkStatusResponse{
+		Status:    req.Status,
+		Requested: len(ids),
+		Updated:   updated,
+		Unchanged: len(ids) - updated,
+	}, nil
+}
+
+// ========== BULK MIN STOCK ==========
+// Level invalid = seluruh request ditolak, produk tidak ditemukan = gagal per item
+func (ps *productService) BulkUpdateMinStock(ctx context.Context, req product.BulkMinStockRequest) (*product.BulkMinStockResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// ID yang sama muncul lebih dari sekali: nilai terakhir yang dipakai
+	levels := make(map[uuid.UUID]int, len(req.Items))
+	ids := make([]uuid.UUID, len(req.Items))
+	for i, item := range req.Items {
+		id, err := uuid.Parse(item.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID format")
+		}
+		if err := ps.validateMinStockLevel(*item.MinStockLevel); err != nil {
+			return nil, err
+		}
+		ids[i] = id
+		levels[id] = *item.MinStockLevel
+	}
+
+	updated, err := ps.repo.Product.UpdateMinStockBatch(ctx, levels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update min stock levels")
+	}
+
+	response := &product.BulkMinStockResponse{
+		Results: make([]product.BulkMinStockResult, 0, len(req.Items)),
+	}
+	for i, id := range ids {
+		result := product.BulkMinStockResult{
+			ProductID:     id.String(),
+			MinStockLevel: *req.Items[i].MinStockLevel,
+			Success:       updated[id],
+		}
+		if result.Success {
+			response.Updated++
+		} else {
+			result.Error = "product not found"
+			response.Failed++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	return response, nil
+}
+
+// ========== CHECK STOCK (BATCH) ==========
+// Read-only, tidak reserve stok: hasil bisa berubah sebelum sale dibuat
+// Hasil per item sesuai urutan request, produk yang muncul lebih dari sekali
+// dicek terhadap total quantity-nya (stok yang sama tidak dihitung dua kali)
+func (ps *productService) CheckStockBatch(ctx context.Context, req product.CheckStockBatchRequest) (*product.CheckStockBatchResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	ids := make([]uuid.UUID, len(req.Items))
+	uniqueIDs := make([]uuid.UUID, 0, len(req.Items))
+	totalRequested := make(map[uuid.UUID]int, len(req.Items))
+	for i, item := range req.Items {
+		id, err := uuid.Parse(item.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID format")
+		}
+		ids[i] = id
+		if _, seen := totalRequested[id]; !seen {
+			uniqueIDs = append(uniqueIDs, id)
+		}
+		totalRequested[id] += item.Quantity
+	}
+
+	products, err := ps.repo.Product.FindStockByIDs(ctx, uniqueIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check stock")
+	}
+
+	byID := make(map[uuid.UUID]model.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	response := &product.CheckStockBatchResponse{
+		AllAvailable: true,
+		Items:        make([]product.StockCheckResult, 0, len(req.Items)),
+	}
+	for i, item := range req.Items {
+		result := product.StockCheckResult{
+			ProductID: ids[i].String(),
+			Requested: item.Quantity,
+		}
+
+		p, found := byID[ids[i]]
+		switch {
+		case !found:
+			result.Reason = "not_found"
+		case p.Status == model.ProductStatusDiscontinued:
+			result.ProductName = p.Name
+			result.CurrentStock = p.StockQuantity
+			result.Reason = "discontinued"
+		default:
+			result.ProductName = p.Name
+			result.CurrentStock = p.StockQuantity
+			result.Available = p.StockQuantity >= totalRequested[ids[i]]
+			if !result.Available {
+				result.Reason = "insufficient_stock"
+			}
+		}
+
+		if !result.Available {
+			response.AllAvailable = false
+		}
+		response.Items = append(response.Items, result)
+	}
+
+	return response, nil
+}
+
+// ========== BATCH FETCH ==========
+// Urutan response mengikuti urutan ids di request, ID duplikat hanya dikembalikan sekali
+func (ps *productService) FindByIDs(ctx context.Context, req product.BatchProductsRequest) (*product.BatchProductsResponse, error) {
+	if len(req.IDs) > ps.opts.MaxBatchIDs {
+		return nil, fmt.Errorf("too many ids: batch cannot have more than %d ids", ps.opts.MaxBatchIDs)
+	}
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.IDs))
+	seen := make(map[uuid.UUID]bool, len(req.IDs))
+	for _, raw := range req.IDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID format")
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	products, err := ps.repo.Product.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products")
+	}
+
+	byID := make(map[uuid.UUID]*model.Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+
+	response := &product.BatchProductsResponse{
+		Products:   make([]product.ProductResponse, 0, len(products)),
+		MissingIDs: make([]string, 0),
+	}
+	for _, id := range ids {
+		p, found := byID[id]
+		if !found {
+			response.MissingIDs = append(response.MissingIDs, id.String())
+			continue
+		}
+		response.Products = append(response.Products, *ps.convertToResponse(p))
+	}
+
+	return response, nil
+}
+
+// ========== STOCK COUNT (STOCK OPNAME) ==========
+// Bandingkan hasil hitung fisik dengan stok sistem
+// apply=true: stok di-set ke hasil hitung dalam satu transaction, selisih dicatat sebagai movement adjustment
+func (ps *productService) StockCount(ctx context.Context, req product.StockCountRequest, apply bool) (*product.StockCountResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// ID yang sama muncul lebih dari sekali: nilai terakhir yang dipakai
+	counts := make(map[uuid.UUID]int, len(req.Items))
+	ids := make([]uuid.UUID, len(req.Items))
+	for i, item := range req.Items {
+		id, err := uuid.Parse(item.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID format")
+		}
+		ids[i] = id
+		counts[id] = *item.Counted
+	}
+
+	products, err := ps.repo.Product.FindStockByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product stock")
+	}
+
+	byID := make(map[uuid.UUID]model.Product, len(products))
+	expected := make(map[uuid.UUID]int, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+		expected[p.ID] = p.StockQuantity
+	}
+
+	if apply {
+		// Hitungan di atas stok sistem = stok masuk ke rak utama produk, warehouse-nya harus aktif
+		checkedShelves := make(map[uuid.UUID]bool)
+		for id, counted := range counts {
+			p, found := byID[id]
+			if !found || counted <= p.StockQuantity || checkedShelves[p.ShelfID] {
+				continue
+			}
+			if err := ps.checkShelfAvailable(ctx, p.ShelfID); err != nil {
+				return nil, err
+			}
+			checkedShelves[p.ShelfID] = true
+		}
+
+		notes := "stock count"
+		movement := model.StockMovement{MovementType: model.StockMovementAdjustment, Notes: &notes}
+
+		// Expected diambil dari stok saat row di-lock, bukan hasil baca di atas
+		expected, err = ps.repo.Product.ApplyStockCounts(ctx, counts, movement)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply stock count")
+		}
+	}
+
+	response := &product.StockCountResponse{
+		Currency: utils.Currency(),
+		Applied:  apply,
+		Items:    make([]product.StockCountResult, 0, len(counts)),
+	}
+	seen := make(map[uuid.UUID]bool, len(counts))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		result := product.StockCountResult{
+			ProductID: id.String(),
+			Counted:   counts[id],
+		}
+
+		p, found := byID[id]
+		stock, locked := expected[id]
+		if !found || !locked {
+			result.Error = "product not found"
+			response.Items = append(response.Items, result)
+			continue
+		}
+
+		result.Found = true
+		result.ProductName = p.Name
+		result.Expected = stock
+		result.Variance = result.Counted - result.Expected
+		result.VarianceValue = utils.RoundMoney(float64(result.Variance) * p.CostPrice)
+
+		response.TotalVariance += result.Variance
+		response.TotalVarianceValue = utils.RoundMoney(response.TotalVarianceValue + result.VarianceValue)
+		if apply && result.Variance != 0 {
+			response.Adjusted++
+		}
+		response.Items = append(response.Items, result)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Stock count processed",
+		zap.Int("products", len(counts)),
+		zap.Bool("applied", apply),
+		zap.Int("total_variance", response.TotalVariance))
+
+	return response, nil
+}
+
+// ========== RESTOCK (TERIMA BARANG) ==========
+// Semua item masuk atau tidak sama sekali, tiap produk tercatat sebagai movement restock dengan notes = reference
+func (ps *productService) Restock(ctx context.Context, req product.RestockRequest) (*product.RestockResponse, error) {
+	req.Reference = strings.TrimSpace(req.Reference)
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Produk yang sama muncul lebih dari sekali: quantity dijumlahkan
+	quantities := make(map[uuid.UUID]int, len(req.Items))
+	ids := make([]uuid.UUID, 0, len(req.Items))
+	for _, item := range req.Items {
+		id, err := uuid.Parse(item.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID format")
+		}
+		if _, seen := quantities[id]; !seen {
+			ids = append(ids, id)
+		}
+		quantities[id] += item.Quantity
+	}
+
+	products, err := ps.repo.Product.FindStockByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product stock")
+	}
+	if len(products) != len(ids) {
+		return nil, fmt.Errorf("one or more products not found")
+	}
+
+	// Stok restock masuk ke rak utama masing-masing produk, warehouse-nya harus aktif
+	names := make(map[uuid.UUID]string, len(products))
+	checkedShelves := make(map[uuid.UUID]bool)
+	for _, p := range products {
+		names[p.ID] = p.Name
+		if checkedShelves[p.ShelfID] {
+			continue
+		}
+		if err := ps.checkShelfAvailable(ctx, p.ShelfID); err != nil {
+			return nil, err
+		}
+		checkedShelves[p.ShelfID] = true
+	}
+
+	reference := req.Reference
+	movement := model.StockMovement{MovementType: model.StockMovementRestock, Notes: &reference}
+
+	// Stok lama diambil saat row di-lock, bukan hasil baca di atas
+	oldStocks, err := ps.repo.Product.RestockBatch(ctx, quantities, movement)
+	if err != nil {
+		if err.Error() == "one or more products not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to restock products")
+	}
+
+	response := &product.RestockResponse{
+		Reference: reference,
+		Items:     make([]product.RestockResult, 0, len(ids)),
+	}
+	for _, id := range ids {
+		result := product.RestockResult{
+			ProductID:   id.String(),
+			ProductName: names[id],
+			Quantity:    quantities[id],
+			OldStock:    oldStocks[id],
+			NewStock:    oldStocks[id] + quantities[id],
+		}
+		response.TotalQuantity += result.Quantity
+		response.Items = append(response.Items, result)
+	}
+
+	utils.LoggerFromContext(ctx).Info("Restock received",
+		zap.String("reference", reference),
+		zap.Int("products", len(ids)),
+		zap.Int("total_quantity", response.TotalQuantity))
+
+	return response, nil
+}
+
+// ========== STOCK MOVEMENT FEED ==========
+// Activity feed movement semua produk terbaru dulu, filter tanggal & jenis opsional
+func (ps *productService) GetMovementFeed(ctx context.Context, req product.MovementFeedRequest, page, limit int) ([]product.MovementFeedEntry, utils.Pagination, error) {
+	pagination := utils.NewPagination(page, limit)
+
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, pagination, fmt.Errorf("validation failed: %w", err)
+	}
+
+	var startDate, endDate *time.Time
+	if req.StartDate != "" {
+		date, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, pagination, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+		}
+		startDate = &date
+	}
+	if req.EndDate != "" {
+		date, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, pagination, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+		}
+		endDate = &date
+	}
+	if startDate != nil && endDate != nil && startDate.After(*endDate) {
+		return nil, pagination, fmt.Errorf("start date cannot be after end date")
+	}
+
+	movements, err := ps.repo.StockMovement.FindAll(ctx, startDate, endDate, req.Type, pagination.Limit, pagination.Offset())
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to get stock movements")
+	}
+
+	total, err := ps.repo.StockMovement.CountAll(ctx, startDate, endDate, req.Type)
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to count stock movements")
+	}
+	pagination.SetTotal(total)
+
+	entries := make([]product.MovementFeedEntry, 0, len(movements))
+	for _, m := range movements {
+		entry := product.MovementFeedEntry{
+			ID:           m.ID.String(),
+			ProductID:    m.ProductID.String(),
+			ProductName:  m.ProductName,
+			ProductSKU:   m.ProductSKU,
+			MovementType: string(m.MovementType),
+			Quantity:     m.Quantity,
+			StockAfter:   m.StockAfter,
+			Notes:        m.Notes,
+			Username:     m.Username,
+			CreatedAt:    m.CreatedAt,
+		}
+		if m.ReferenceID != nil {
+			referenceID := m.ReferenceID.String()
+			entry.ReferenceID = &referenceID
+		}
+		if m.CreatedBy != nil {
+			userID := m.CreatedBy.String()
+			entry.UserID = &userID
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, pagination, nil
+}
+
+// ========== UPLOAD IMAGE ==========
+// Simpan file ke storage lalu set image_url produk
+func (ps *productService) UploadImage(ctx context.Context, id uuid.UUID, file io.Reader, size int64) (*product.ProductResponse, error) {
+	if ps.opts.Storage == nil {
+		return nil, fmt.Errorf("image upload is not configured")
+	}
+
+	if size <= 0 {
+		return nil, fmt.Errorf("validation failed: image file is empty")
+	}
+	if ps.opts.MaxImageSize > 0 && size > ps.opts.MaxImageSize {
+		return nil, fmt.Errorf("validation failed: image exceeds maximum size of %d bytes", ps.opts.MaxImageSize)
+	}
+
+	existingProduct, err := ps.repo.Product.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	// Sniff content type dari 512 byte pertama
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read image")
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	ext, ok := allowedImageTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("validation failed: unsupported image type %s", contentType)
+	}
+
+	// Nama file unik per upload, supaya cache client tidak pakai gambar lama
+	name := fmt.Sprintf("product-%s-%s%s", id, uuid.New(), ext)
+	url, err := ps.opts.Storage.Save(ctx, name, io.MultiReader(bytes.NewReader(head), file))
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to store product image", zap.String("product_id", id.String()), zap.Error(err))
+		return nil, fmt.Errorf("failed to store image")
+	}
+
+	existingProduct.ImageURL = url
+	if err := ps.repo.Product.Update(ctx, existingProduct); err != nil {
+		return nil, fmt.Errorf("failed to update product")
+	}
+
+	utils.LoggerFromContext(ctx).Info("Product image uploaded",
+		zap.String("product_id", id.String()),
+		zap.String("image_url", url),
+		zap.Int64("size", size))
+
+	return ps.convertToResponse(existingProduct), nil
+}
+
+// ========== CHECK STOCK ========== (UNTUK SALE VALIDATION)
+func (ps *productService) CheckStock(ctx context.Context, id uuid.UUID, requiredQuantity int) (*model.Product, error) {
+	if requiredQuantity <= 0 {
+		return nil, fmt.Errorf("required quantity must be positive")
+	}
+
+	product, err := ps.repo.Product.CheckStock(ctx, id, requiredQuantity)
+	if err != nil {
+		return nil, fmt.Errorf("stock check failed: %w", err)
+	}
+
+	return product, nil
+}
+
+// ========== DELETE ==========
+func (ps *productService) Delete(ctx context.Context, id uuid.UUID) error {
+	// Check if product exists
+	if _, err := ps.repo.Product.FindByID(ctx, id); err != nil {
+		return fmt.Errorf("product not found")
+	}
+
+	// Delete product
+	if err := ps.repo.Product.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete product")
+	}
+
+	utils.LoggerFromContext(ctx).Info("Product deleted", zap.String("product_id", id.String()))
+	return nil
+}
+
+// ========== HELPER: VALIDATE MIN STOCK LEVEL ==========
+// Cegah nilai absurd yang bikin produk low stock selamanya
+func (ps *productService) validateMinStockLevel(level int) error {
+	if ps.opts.MaxMinStockLevel > 0 && level > ps.opts.MaxMinStockLevel {
+		return fmt.Errorf("validation failed: min_stock_level must not exceed %d", ps.opts.MaxMinStockLevel)
+	}
+	return nil
+}
+
+// checkShelfAvailable shelf harus ada & warehouse-nya aktif (stok baru tidak masuk warehouse yang ditutup)
+func (ps *productService) checkShelfAvailable(ctx context.Context, shelfID uuid.UUID) error {
+	shelf, err := ps.repo.Shelf.FindByID(ctx, shelfID)
+	if err != nil {
+		return fmt.Errorf("shelf not found")
+	}
+
+	warehouse, err := ps.repo.Warehouse.FindByID(ctx, shelf.WarehouseID)
+	if err != nil {
+		return fmt.Errorf("shelf not found")
+	}
+	if !warehouse.IsActive {
+		return fmt.Errorf("shelf belongs to an inactive warehouse")
+	}
+
+	return nil
+}
+
+// stockDeficit = min_stock_level - stock_quantity
+// Stock == min tetap masuk low stock (<=) dengan deficit 0, tidak pernah negatif
+func stockDeficit(minStockLevel, stockQuantity int) int {
+	return max(0, minStockLevel-stockQuantity)
+}
+
+// suggestedOrder jumlah yang perlu dipesan supaya stok kembali ke reorder_quantity
+// reorder_quantity 0 = tidak ada target, jadi tidak ada saran
+func suggestedOrder(reorderQuantity, stockQuantity int) int {
+	return max(0, reorderQuantity-stockQuantity)
+}
+
+// checkSKUAvailable SKU belum dipakai produk aktif lain (excludeID = produk yang sedang di-update)
+// sku nil selalu lolos
+func (ps *productService) checkSKUAvailable(ctx context.Context, sku *string, excludeID uuid.UUID) error {
+	if sku == nil {
+		return nil
+	}
+	if existing, _ := ps.repo.Product.FindBySKU(ctx, *sku); existing != nil && existing.ID != excludeID {
+		return fmt.Errorf("product sku already exists")
+	}
+	return nil
+}
+
+// normalizeSKU trim spasi, string kosong = tanpa SKU (nil)
+func normalizeSKU(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// formatSKU kebalikan normalizeSKU, nil = string kosong
+func formatSKU(sku *string) string {
+	if sku == nil {
+		return ""
+	}
+	return *sku
+}
+
+// parseExpiryDate YYYY-MM-DD ke tanggal, string kosong = tanpa tanggal kedaluwarsa (nil)
+func parseExpiryDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expiry date format. Use YYYY-MM-DD")
+	}
+	return &date, nil
+}
+
+// formatExpiryDate kebalikan parseExpiryDate, nil = string kosong
+func formatExpiryDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format("2006-01-02")
+}
+
+// daysUntil selisih hari kalender from -> to (negatif jika to sudah lewat)
+func daysUntil(from, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
+
+// isNewlyLowStock belum low stock dengan min_stock_level sekarang (stok > min) tapi low dengan threshold baru (stok <= threshold)
+func isNewlyLowStock(p *model.Product, threshold int) bool {
+	return p.StockQuantity > p.MinStockLevel && p.StockQuantity <= threshold
+}
+
+// ========== HELPER: CONVERT TO RESPONSE ==========
+func (ps *productService) convertToResponse(p *model.Product) *product.ProductResponse {
+	// Calculate if low stock
+	isLowStock := p.StockQuantity <= p.MinStockLevel
+
+	var expiryDate *string
+	if p.ExpiryDate != nil {
+		formatted := formatExpiryDate(p.ExpiryDate)
+		expiryDate = &formatted
+	}
 
 	return &product.ProductResponse{
-		ID:            p.ID.String(),
-		CategoryID:    p.CategoryID.String(),
-		ShelfID:       p.ShelfID.String(),
-		Name:          p.Name,
-		Description:   p.Description,
-		UnitPrice:     p.UnitPrice,
-		CostPrice:     p.CostPrice,
-		StockQuantity: p.StockQuantity,
-		MinStockLevel: p.MinStockLevel,
-		IsLowStock:    isLowStock, // Calculated field
-		CreatedAt:     p.CreatedAt,
-		UpdatedAt:     p.UpdatedAt,
+		ID:              p.ID.String(),
+		CategoryID:      p.CategoryID.String(),
+		ShelfID:         p.ShelfID.String(),
+		SKU:             formatSKU(p.SKU),
+		Name:            p.Name,
+		Description:     p.Description,
+		UnitPrice:       p.UnitPrice,
+		CostPrice:       p.CostPrice,
+		Currency:        utils.Currency(),
+		StockQuantity:   p.StockQuantity,
+		MinStockLevel:   p.MinStockLevel,
+		ReorderQuantity: p.ReorderQuantity,
+		ImageURL:        p.ImageURL,
+		ExpiryDate:      expiryDate,
+		Status:          string(p.Status),
+		IsLowStock:      isLowStock, // Calculated field
+		CreatedAt:       p.CreatedAt,
+		UpdatedAt:       p.UpdatedAt,
+		DeletedAt:       p.DeletedAt,
 	}
 }
@@ -0,0 +1,2444 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/dto/product"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"inventory-system/utils"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKES ==========
+
+// fakeProductRepo simpan produk di memori, method lain dari interface tidak dipakai
+type fakeProductRepo struct {
+	repository.ProductRepo
+
+	products map[uuid.UUID]*model.Product
+	created  []*model.Product
+
+	updates   []repository.ProductUpdate
+	updateErr error
+
+	restocked  map[uuid.UUID]int
+	restockErr error
+
+	// appliedCounts hitungan yang dikirim ke ApplyStockCounts (nil = tidak dipanggil)
+	appliedCounts map[uuid.UUID]int
+
+	statusUpdates  int
+	stockUpdates   int
+	statusBatches  [][]uuid.UUID
+	stockLookups   [][]uuid.UUID
+	minStockCalls  int
+	recategorized  []uuid.UUID
+	locations      map[uuid.UUID]*model.ProductLocation
+	staleBefore    *time.Time
+	criticalCalls  int
+	batchLookups   [][]uuid.UUID
+	priceAdjusts   []priceAdjustCall
+	stockUpToCalls int
+}
+
+// priceAdjustCall argumen yang dikirim ke AdjustPricesByCategory
+type priceAdjustCall struct {
+	categoryID  uuid.UUID
+	unitPercent float64
+	costPercent float64
+	changedBy   *uuid.UUID
+}
+
+func newFakeProductRepo(products ...*model.Product) *fakeProductRepo {
+	repo := &fakeProductRepo{products: make(map[uuid.UUID]*model.Product)}
+	for _, p := range products {
+		repo.products[p.ID] = p
+	}
+	return repo
+}
+
+func (f *fakeProductRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Product, error) {
+	p, ok := f.products[id]
+	if !ok {
+		return nil, fmt.Errorf("product not found")
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (f *fakeProductRepo) FindBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	for _, p := range f.products {
+		if p.SKU != nil && *p.SKU == sku {
+			copied := *p
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("product not found")
+}
+
+func (f *fakeProductRepo) Create(ctx context.Context, p *model.Product) error {
+	p.ID = uuid.New()
+	f.created = append(f.created, p)
+	return nil
+}
+
+func (f *fakeProductRepo) Update(ctx context.Context, p *model.Product) error {
+	copied := *p
+	f.products[p.ID] = &copied
+	return nil
+}
+
+func (f *fakeProductRepo) UpdateWithChanges(ctx context.Context, u repository.ProductUpdate) error {
+	// Simpan salinan, service masih mengubah model setelah update
+	copied := *u.Product
+	u.Product = &copied
+	f.updates = append(f.updates, u)
+	return f.updateErr
+}
+
+// CheckStock meniru query repo: stok cukup dulu, status discontinued ditolak
+func (f *fakeProductRepo) CheckStock(ctx context.Context, id uuid.UUID, requiredQuantity int) (*model.Product, error) {
+	p, ok := f.products[id]
+	if !ok || p.StockQuantity < requiredQuantity {
+		return nil, fmt.Errorf("insufficient stock or product not found")
+	}
+	if p.Status == model.ProductStatusDiscontinued {
+		return nil, fmt.Errorf("product is discontinued")
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (f *fakeProductRepo) FindStockByIDs(ctx context.Context, ids []uuid.UUID) ([]model.Product, error) {
+	f.stockLookups = append(f.stockLookups, ids)
+	var found []model.Product
+	for _, id := range ids {
+		if p, ok := f.products[id]; ok {
+			found = append(found, *p)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeProductRepo) RestockBatch(ctx context.Context, quantities map[uuid.UUID]int, movement model.StockMovement) (map[uuid.UUID]int, error) {
+	if f.restockErr != nil {
+		return nil, f.restockErr
+	}
+	f.restocked = quantities
+	oldStocks := make(map[uuid.UUID]int, len(quantities))
+	for id := range quantities {
+		oldStocks[id] = f.products[id].StockQuantity
+	}
+	return oldStocks, nil
+}
+
+// ApplyStockCounts meniru repo: set stok, return stok lama hanya untuk produk yang ada
+func (f *fakeProductRepo) ApplyStockCounts(ctx context.Context, counts map[uuid.UUID]int, movement model.StockMovement) (map[uuid.UUID]int, error) {
+	f.appliedCounts = counts
+	expected := make(map[uuid.UUID]int, len(counts))
+	for id, counted := range counts {
+		if p, ok := f.products[id]; ok {
+			expected[id] = p.StockQuantity
+			p.StockQuantity = counted
+		}
+	}
+	return expected, nil
+}
+
+func (f *fakeProductRepo) UpdateStock(ctx context.Context, id uuid.UUID, quantity int, movement model.StockMovement) error {
+	f.stockUpdates++
+	f.products[id].StockQuantity = quantity
+	return nil
+}
+
+func (f *fakeProductRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status model.ProductStatus) error {
+	f.statusUpdates++
+	f.products[id].Status = status
+	return nil
+}
+
+func (f *fakeProductRepo) FindLocation(ctx context.Context, productID uuid.UUID) (*model.ProductLocation, error) {
+	if l, ok := f.locations[productID]; ok {
+		return l, nil
+	}
+	return nil, fmt.Errorf("product not found: no rows in result set")
+}
+
+// UpdateMinStockBatch meniru repo: ID yang tidak ada dilewati, bukan error
+func (f *fakeProductRepo) UpdateMinStockBatch(ctx context.Context, levels map[uuid.UUID]int) (map[uuid.UUID]bool, error) {
+	f.minStockCalls++
+	updated := make(map[uuid.UUID]bool, len(levels))
+	for id, level := range levels {
+		if p, ok := f.products[id]; ok {
+			p.MinStockLevel = level
+			updated[id] = true
+		}
+	}
+	return updated, nil
+}
+
+// UpdateCategoryBatch meniru repo: semua ID harus ada, kalau tidak tidak ada yang berubah
+func (f *fakeProductRepo) UpdateCategoryBatch(ctx context.Context, ids []uuid.UUID, categoryID uuid.UUID) (int, error) {
+	f.recategorized = ids
+	for _, id := range ids {
+		if _, ok := f.products[id]; !ok {
+			return 0, fmt.Errorf("one or more products not found")
+		}
+	}
+	for _, id := range ids {
+		f.products[id].CategoryID = categoryID
+	}
+	return len(ids), nil
+}
+
+// UpdateStatusBatch meniru repo: semua ID harus ada, produk yang status-nya sama tidak dihitung
+func (f *fakeProductRepo) UpdateStatusBatch(ctx context.Context, ids []uuid.UUID, status model.ProductStatus) (int, error) {
+	f.statusBatches = append(f.statusBatches, ids)
+	for _, id := range ids {
+		if _, ok := f.products[id]; !ok {
+			return 0, fmt.Errorf("one or more products not found")
+		}
+	}
+	updated := 0
+	for _, id := range ids {
+		if f.products[id].Status != status {
+			f.products[id].Status = status
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+// FindStaleStock meniru repo: updated_at < before (exclusive), paling lama dulu
+func (f *fakeProductRepo) FindStaleStock(ctx context.Context, before time.Time) ([]model.Product, error) {
+	f.staleBefore = &before
+	stale := make([]model.Product, 0)
+	for _, p := range f.products {
+		if p.UpdatedAt.Before(before) {
+			stale = append(stale, *p)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].UpdatedAt.Before(stale[j].UpdatedAt) })
+	return stale, nil
+}
+
+// FindByIDs meniru WHERE id = ANY($1): urutan hasil tidak mengikuti ids, ID yang tidak ada dilewati
+func (f *fakeProductRepo) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]model.Product, error) {
+	f.batchLookups = append(f.batchLookups, ids)
+	found := make([]model.Product, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		if p, ok := f.products[ids[i]]; ok {
+			found = append(found, *p)
+		}
+	}
+	return found, nil
+}
+
+// FindCriticalStock meniru repo: min_stock_level > 0 dan stock <= min * percent, rasio stok terkecil dulu
+func (f *fakeProductRepo) FindCriticalStock(ctx context.Context, percent float64) ([]model.Product, error) {
+	f.criticalCalls++
+	critical := make([]model.Product, 0)
+	for _, p := range f.products {
+		if p.MinStockLevel > 0 && float64(p.StockQuantity) <= float64(p.MinStockLevel)*percent {
+			critical = append(critical, *p)
+		}
+	}
+	ratio := func(p model.Product) float64 { return float64(p.StockQuantity) / float64(p.MinStockLevel) }
+	sort.Slice(critical, func(i, j int) bool { return ratio(critical[i]) < ratio(critical[j]) })
+	return critical, nil
+}
+
+// FindStockUpTo meniru repo: stok <= threshold, stok paling sedikit lalu nama
+func (f *fakeProductRepo) FindStockUpTo(ctx context.Context, threshold int) ([]model.Product, error) {
+	f.stockUpToCalls++
+	products := make([]model.Product, 0)
+	for _, p := range f.products {
+		if p.DeletedAt == nil && p.StockQuantity <= threshold {
+			products = append(products, *p)
+		}
+	}
+	sort.Slice(products, func(i, j int) bool {
+		if products[i].StockQuantity != products[j].StockQuantity {
+			return products[i].StockQuantity < products[j].StockQuantity
+		}
+		return products[i].Name < products[j].Name
+	})
+	return products, nil
+}
+
+// AdjustPricesByCategory meniru repo: harga produk category dikali persen, produk yang tidak berubah tidak dihitung
+func (f *fakeProductRepo) AdjustPricesByCategory(ctx context.Context, categoryID uuid.UUID, unitPercent, costPercent float64, changedBy *uuid.UUID) (int, error) {
+	f.priceAdjusts = append(f.priceAdjusts, priceAdjustCall{categoryID, unitPercent, costPercent, changedBy})
+	updated := 0
+	for _, p := range f.products {
+		if p.CategoryID != categoryID || p.DeletedAt != nil {
+			continue
+		}
+		unit := math.Round(p.UnitPrice*(1+unitPercent/100)*100) / 100
+		cost := math.Round(p.CostPrice*(1+costPercent/100)*100) / 100
+		if unit != p.UnitPrice || cost != p.CostPrice {
+			p.UnitPrice, p.CostPrice = unit, cost
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+type fakeShelfRepo struct {
+	repository.ShelfRepo
+	shelves map[uuid.UUID]*model.Shelf
+}
+
+func (f *fakeShelfRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Shelf, error) {
+	if s, ok := f.shelves[id]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("shelf not found")
+}
+
+type fakeStockMovementRepo struct {
+	repository.StockMovementRepo
+
+	entries []model.StockMovementFeedEntry
+	total   int
+
+	startDate, endDate *time.Time
+	movementType       string
+	limit, offset      int
+
+	summaries []model.StockMovementSummary
+
+	// ledger movement produk, dipakai StockAtTime (stockAtCalls = jumlah rekonstruksi)
+	ledger       []model.StockMovement
+	currentStock map[uuid.UUID]int
+	stockAtCalls int
+}
+
+// StockAtTime meniru repo: stok sekarang dikurangi movement sejak at
+func (f *fakeStockMovementRepo) StockAtTime(ctx context.Context, productID uuid.UUID, at time.Time) (int, error) {
+	f.stockAtCalls++
+	stock := f.currentStock[productID]
+	for _, m := range f.ledger {
+		if m.ProductID == productID && !m.CreatedAt.Before(at) {
+			stock -= m.Quantity
+		}
+	}
+	return stock, nil
+}
+
+func (f *fakeStockMovementRepo) SummaryByType(ctx context.Context, productID uuid.UUID, startDate, endDate time.Time) ([]model.StockMovementSummary, error) {
+	f.startDate, f.endDate = &startDate, &endDate
+	return f.summaries, nil
+}
+
+func (f *fakeStockMovementRepo) FindAll(ctx context.Context, startDate, endDate *time.Time, movementType string, limit, offset int) ([]model.StockMovementFeedEntry, error) {
+	f.startDate, f.endDate, f.movementType, f.limit, f.offset = startDate, endDate, movementType, limit, offset
+	return f.entries, nil
+}
+
+func (f *fakeStockMovementRepo) CountAll(ctx context.Context, startDate, endDate *time.Time, movementType string) (int, error) {
+	return f.total, nil
+}
+
+// fakeStockLocationRepo stok per rak di memori, Transfer meniru cek stok rak asal di repo
+type fakeStockLocationRepo struct {
+	repository.StockLocationRepo
+	locations map[uuid.UUID]int
+	transfers []model.StockMovement
+}
+
+func (f *fakeStockLocationRepo) FindByProduct(ctx context.Context, productID uuid.UUID) ([]model.ProductStockLocation, error) {
+	result := make([]model.ProductStockLocation, 0, len(f.locations))
+	for shelfID, qty := range f.locations {
+		result = append(result, model.ProductStockLocation{ProductID: productID, ShelfID: shelfID, Quantity: qty})
+	}
+	return result, nil
+}
+
+func (f *fakeStockLocationRepo) Transfer(ctx context.Context, productID, fromShelfID, toShelfID uuid.UUID, quantity int, movement model.StockMovement) (int, error) {
+	if f.locations[fromShelfID] < quantity {
+		return 0, fmt.Errorf("insufficient stock at source shelf")
+	}
+	f.locations[fromShelfID] -= quantity
+	f.locations[toShelfID] += quantity
+	f.transfers = append(f.transfers, movement)
+
+	total := 0
+	for _, qty := range f.locations {
+		total += qty
+	}
+	return total, nil
+}
+
+// memoryStorage simpan file upload di memori, URL mengikuti localStorage dengan base "/uploads"
+type memoryStorage struct {
+	files map[string][]byte
+}
+
+func (m *memoryStorage) Save(ctx context.Context, name string, r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	m.files[name] = data
+	return "/uploads/" + name, nil
+}
+
+// productFixture satu produk di rak aktif & rak di warehouse nonaktif
+type productFixture struct {
+	product       *model.Product
+	category      *model.Category
+	shelf         *model.Shelf
+	inactiveShelf *model.Shelf
+
+	products   *fakeProductRepo
+	shelves    *fakeShelfRepo
+	warehouses *fakeWarehouseRepo
+	movements  *fakeStockMovementRepo
+	locations  *fakeStockLocationRepo
+	notifier   *recordingNotifier
+	service    *productService
+}
+
+func newProductFixture(opts ProductOptions) *productFixture {
+	active := &model.Warehouse{BaseModel: model.BaseModel{ID: uuid.New()}, IsActive: true}
+	inactive := &model.Warehouse{BaseModel: model.BaseModel{ID: uuid.New()}, IsActive: false}
+	shelf := &model.Shelf{BaseModel: model.BaseModel{ID: uuid.New()}, WarehouseID: active.ID}
+	inactiveShelf := &model.Shelf{BaseModel: model.BaseModel{ID: uuid.New()}, WarehouseID: inactive.ID}
+	category := &model.Category{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Drinks"}
+
+	sku := "SKU-1"
+	p := &model.Product{
+		BaseModel:     model.BaseModel{ID: uuid.New()},
+		CategoryID:    category.ID,
+		ShelfID:       shelf.ID,
+		SKU:           &sku,
+		Name:          "Coffee",
+		UnitPrice:     10,
+		StockQuantity: 20,
+		MinStockLevel: 5,
+	}
+
+	f := &productFixture{
+		product:       p,
+		category:      category,
+		shelf:         shelf,
+		inactiveShelf: inactiveShelf,
+		products:      newFakeProductRepo(p),
+		shelves:       &fakeShelfRepo{shelves: map[uuid.UUID]*model.Shelf{shelf.ID: shelf, inactiveShelf.ID: inactiveShelf}},
+		warehouses:    &fakeWarehouseRepo{warehouses: map[uuid.UUID]*model.Warehouse{active.ID: active, inactive.ID: inactive}},
+		movements:     &fakeStockMovementRepo{},
+		locations:     &fakeStockLocationRepo{locations: map[uuid.UUID]int{shelf.ID: p.StockQuantity}},
+		notifier:      &recordingNotifier{},
+	}
+
+	repo := &repository.Repository{
+		Product:       f.products,
+		Shelf:         f.shelves,
+		Warehouse:     f.warehouses,
+		Category:      &fakeCategoryRepo{categories: map[uuid.UUID]*model.Category{category.ID: category}},
+		StockMovement: f.movements,
+		StockLocation: f.locations,
+	}
+	f.service = NewProductService(repo, zap.NewNop(), f.notifier, opts).(*productService)
+	return f
+}
+
+func adminContext() context.Context {
+	return utils.SetUserToContext(context.Background(), &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: model.RoleAdmin})
+}
+
+func staffContext() context.Context {
+	return utils.SetUserToContext(context.Background(), &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: model.RoleStaff})
+}
+
+// ========== DUPLICATE ==========
+
+func TestProductDuplicate(t *testing.T) {
+	longName := strings.Repeat("a", 200)
+
+	tests := []struct {
+		name     string
+		setup    func(f *productFixture)
+		missing  bool
+		req      product.DuplicateProductRequest
+		wantErr  string
+		wantName string
+	}{
+		{name: "default copy name", wantName: "Coffee (Copy)"},
+		{name: "custom name", req: product.DuplicateProductRequest{Name: stringPtr("Decaf")}, wantName: "Decaf"},
+		{
+			name:     "long name truncated to fit",
+			setup:    func(f *productFixture) { f.product.Name = longName },
+			wantName: longName[:193] + " (Copy)",
+		},
+		{name: "source not found", missing: true, wantErr: "product not found"},
+		{
+			name:    "source shelf deleted",
+			setup:   func(f *productFixture) { f.product.ShelfID = uuid.New() },
+			wantErr: "shelf not found",
+		},
+		{
+			name:    "source shelf in inactive warehouse",
+			setup:   func(f *productFixture) { f.product.ShelfID = f.inactiveShelf.ID },
+			wantErr: "shelf belongs to an inactive warehouse",
+		},
+		{name: "name too short", req: product.DuplicateProductRequest{Name: stringPtr("ab")}, wantErr: "validation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newProductFixture(ProductOptions{})
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+			id := f.product.ID
+			if tt.missing {
+				id = uuid.New()
+			}
+
+			resp, err := f.service.Duplicate(adminContext(), id, tt.req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if len(f.products.created) != 0 {
+					t.Error("product created on error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			created := f.products.created[0]
+			if created.Name != tt.wantName || resp.Name != tt.wantName {
+				t.Errorf("name = %q, want %q", created.Name, tt.wantName)
+			}
+			// Clone tanpa stok & SKU
+			if created.StockQuantity != 0 || created.SKU != nil {
+				t.Errorf("clone stock = %d sku = %v, want 0 and nil", created.StockQuantity, created.SKU)
+			}
+			if created.ShelfID != f.product.ShelfID || created.MinStockLevel != f.product.MinStockLevel {
+				t.Error("clone does not keep shelf and min stock level")
+			}
+		})
+	}
+}
+
+// ========== UPDATE ==========
+
+func TestProductUpdate(t *testing.T) {
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		setup      func(f *productFixture)
+		req        func(f *productFixture) product.UpdateProductRequest
+		updateErr  error
+		wantErr    string
+		wantUpdate bool
+		check      func(t *testing.T, f *productFixture, u repository.ProductUpdate)
+	}{
+		{
+			name: "staff cannot update details",
+			ctx:  staffContext(),
+			req: func(f *productFixture) product.UpdateProductRequest {
+				return product.UpdateProductRequest{Name: stringPtr("Tea")}
+			},
+			wantErr: "permission denied",
+		},
+		{
+			name: "no changes skips repository",
+			req: func(f *productFixture) product.UpdateProductRequest {
+				return product.UpdateProductRequest{Name: stringPtr("Coffee"), SKU: stringPtr(" SKU-1 ")}
+			},
+		},
+		{
+			name: "detail only",
+			req: func(f *productFixture) product.UpdateProductRequest {
+				return product.UpdateProductRequest{Name: stringPtr("Tea")}
+			},
+			wantUpdate: true,
+			check: func(t *testing.T, f *productFixture, u repository.ProductUpdate) {
+				if u.Product.Name != "Tea" || u.Stock != nil || u.SKUChanged {
+					t.Errorf("update = %+v, want name only", u)
+				}
+			},
+		},
+		{
+			name: "stock sku and detail in one update",
+			req: func(f *productFixture) product.UpdateProductRequest {
+				return product.UpdateProductRequest{Name: stringPtr("Tea"), StockQuantity: intPtr(3), SKU: stringPtr("SKU-2")}
+			},
+			wantUpdate: true,
+			check: func(t *testing.T, f *productFixture, u repository.ProductUpdate) {
+				if u.Stock == nil || *u.Stock != 3 {
+					t.Errorf("stock = %v, want 3", u.Stock)
+				}
+				if u.Movement.MovementType != model.StockMovementAdjustment {
+					t.Errorf("movement type = %s, want adjustment", u.Movement.MovementType)
+				}
+				if !u.SKUChanged || formatSKU(u.SKU) != "SKU-2" {
+					t.Errorf("sku change = %v %q, want SKU-2", u.SKUChanged, formatSKU(u.SKU))
+				}
+				// Stok lama tetap di model, stok ditulis lewat Stock (ledger)
+				if u.Product.StockQuantity != 20 {
+					t.Errorf("product stock = %d, want unchanged 20", u.Product.StockQuantity)
+				}
+				if u.ChangedBy == nil {
+					t.Error("changed_by not set")
+				}
+			},
+		},
+		{
+			name: "sku cleared",
+			req: func(f *productFixture) product.UpdateProductRequest {
+				return product.UpdateProductRequest{SKU: stringPtr("")}
+			},
+			wantUpdate: true,
+			check: func(t *testing.T, f *productFixture, u repository.ProductUpdate) {
+				if !u.SKUChanged || u.SKU != nil {
+					t.Errorf("sku change = %v %v, want cleared", u.SKUChanged, u.SKU)
+				}
+			},
+		},
+		{
+			name: "sku used by other product",
+			setup: func(f *productFixture) {
+				other := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, SKU: stringPtr("TAKEN")}
+				f.products.products[other.ID] = other
+			},
+			req: func(f *productFixture) product.UpdateProductRequest {
+				return product.UpdateProductRequest{SKU: stringPtr("TAKEN")}
+			},
+			wantErr: "product sku already exists",
+		},
+		{
+			name: "move to inactive warehouse",
+			req: func(f *productFixture) product.UpdateProductRequest {
+				return product.UpdateProductRequest{ShelfID: stringPtr(f.inactiveShelf.ID.String())}
+			},
+			wantErr: "shelf belongs to an inactive warehouse",
+		},
+		{
+			name: "unknown category",
+			req: func(f *productFixture) product.UpdateProductRequest {
+				return product.UpdateProductRequest{CategoryID: stringPtr(uuid.NewString())}
+			},
+			wantErr: "category not found",
+		},
+		{
+			name: "invalid expiry date",
+			req: func(f *productFixture) product.UpdateProductRequest {
+				return product.UpdateProductRequest{ExpiryDate: stringPtr("01-02-2026")}
+			},
+			wantErr: "invalid expiry date format",
+		},
+		{
+			name: "sku conflict from repository kept",
+			req: func(f *productFixture) product.UpdateProductRequest {
+				return product.UpdateProductRequest{SKU: stringPtr("SKU-9")}
+			},
+			updateErr:  fmt.Errorf("product sku already exists"),
+			wantErr:    "product sku already exists",
+			wantUpdate: true,
+		},
+		{
+			name: "repository failure hidden",
+			req: func(f *productFixture) product.UpdateProductRequest {
+				return product.UpdateProductRequest{Name: stringPtr("Tea")}
+			},
+			updateErr:  fmt.Errorf("update product failed: boom"),
+			wantErr:    "failed to update product",
+			wantUpdate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newProductFixture(ProductOptions{})
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+			f.products.updateErr = tt.updateErr
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = adminContext()
+			}
+
+			_, err := f.service.Update(ctx, f.product.ID, tt.req(f))
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := len(f.products.updates) == 1; got != tt.wantUpdate {
+				t.Fatalf("repository updated = %v, want %v", got, tt.wantUpdate)
+			}
+			if tt.check != nil {
+				tt.check(t, f, f.products.updates[0])
+			}
+		})
+	}
+}
+
+// Role dicek di service, tidak bergantung pada routing
+func TestProductUpdateRoles(t *testing.T) {
+	userContext := func(role model.UserRole) context.Context {
+		return utils.SetUserToContext(context.Background(), &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: role, IsActive: true})
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		allowed bool
+	}{
+		{name: "staff rejected", ctx: userContext(model.RoleStaff)},
+		{name: "no user rejected", ctx: context.Background()},
+		{name: "admin allowed", ctx: userContext(model.RoleAdmin), allowed: true},
+		{name: "super admin allowed", ctx: userContext(model.RoleSuperAdmin), allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newProductFixture(ProductOptions{})
+
+			resp, err := f.service.Update(tt.ctx, f.product.ID, product.UpdateProductRequest{Name: stringPtr("Tea")})
+			if !tt.allowed {
+				if err == nil || err.Error() != "permission denied: only admin can update product details" {
+					t.Fatalf("error = %v, want permission denied", err)
+				}
+				if len(f.products.updates) != 0 {
+					t.Error("repository updated despite permission denied")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Name != "Tea" || len(f.products.updates) != 1 {
+				t.Errorf("name/updates = %s/%d, want Tea/1", resp.Name, len(f.products.updates))
+			}
+		})
+	}
+
+	// Update stok tetap terbuka untuk staff
+	t.Run("staff can update stock", func(t *testing.T) {
+		f := newProductFixture(ProductOptions{})
+
+		if _, err := f.service.UpdateStock(userContext(model.RoleStaff), f.product.ID, product.UpdateStockRequest{Quantity: 15}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if f.products.stockUpdates != 1 || f.products.products[f.product.ID].StockQuantity != 15 {
+			t.Errorf("stock updates/quantity = %d/%d, want 1/15", f.products.stockUpdates, f.products.products[f.product.ID].StockQuantity)
+		}
+	})
+}
+
+func TestProductUpdateEmitsLowStock(t *testing.T) {
+	f := newProductFixture(ProductOptions{})
+
+	if _, err := f.service.Update(adminContext(), f.product.ID, product.UpdateProductRequest{StockQuantity: intPtr(5)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.notifier.events) != 1 || f.notifier.events[0].Type != EventLowStock {
+		t.Errorf("events = %+v, want one low_stock", f.notifier.events)
+	}
+}
+
+// ========== VALIDATE / CREATE ==========
+
+func TestProductValidate(t *testing.T) {
+	f := newProductFixture(ProductOptions{MaxMinStockLevel: 100})
+
+	valid := func() product.CreateProductRequest {
+		return product.CreateProductRequest{
+			CategoryID:    f.category.ID.String(),
+			ShelfID:       f.shelf.ID.String(),
+			Name:          "Green Tea",
+			UnitPrice:     12,
+			CostPrice:     8,
+			StockQuantity: 10,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(req *product.CreateProductRequest)
+		wantValid bool
+		wantField string
+	}{
+		{name: "valid", wantValid: true},
+		{name: "unknown category", modify: func(req *product.CreateProductRequest) { req.CategoryID = uuid.NewString() }, wantField: "CategoryID"},
+		{name: "inactive warehouse", modify: func(req *product.CreateProductRequest) { req.ShelfID = f.inactiveShelf.ID.String() }, wantField: "ShelfID"},
+		{name: "min stock too high", modify: func(req *product.CreateProductRequest) { req.MinStockLevel = 101 }, wantField: "MinStockLevel"},
+		{name: "bad expiry date", modify: func(req *product.CreateProductRequest) { req.ExpiryDate = "2026/01/01" }, wantField: "ExpiryDate"},
+		{name: "sku taken", modify: func(req *product.CreateProductRequest) { req.SKU = "SKU-1" }, wantField: "SKU"},
+		{name: "struct validation", modify: func(req *product.CreateProductRequest) { req.Name = "" }, wantField: "Name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			if tt.modify != nil {
+				tt.modify(&req)
+			}
+
+			resp, err := f.service.Validate(context.Background(), req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Valid != tt.wantValid {
+				t.Fatalf("valid = %v, want %v (errors %v)", resp.Valid, tt.wantValid, resp.Errors)
+			}
+			if tt.wantField != "" {
+				if _, ok := resp.Errors[tt.wantField]; !ok || len(resp.Errors) != 1 {
+					t.Errorf("errors = %v, want only field %s", resp.Errors, tt.wantField)
+				}
+			}
+			if len(f.products.created) != 0 {
+				t.Error("validate must not create product")
+			}
+		})
+	}
+}
+
+func TestProductValidationFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantOK     bool
+		wantFields map[string]string
+	}{
+		{name: "field error", err: fieldError("ShelfID", fmt.Errorf("shelf not found")), wantOK: true, wantFields: map[string]string{"ShelfID": "shelf not found"}},
+		{name: "struct validation", err: fmt.Errorf("validation failed: %w", &utils.ValidationError{Fields: map[string]string{"Name": "Name is required"}}), wantOK: true, wantFields: map[string]string{"Name": "Name is required"}},
+		// Pesan yang kebetulan menyebut category/sku tetap error sistem
+		{name: "system error mentioning category", err: fmt.Errorf("query category failed: connection reset"), wantOK: false},
+		{name: "system error mentioning sku", err: fmt.Errorf("scan sku failed"), wantOK: false},
+	}
+
+	for _, tt := range tests {
+		fields, ok := productValidationFields(tt.err)
+		if ok != tt.wantOK || fmt.Sprint(fields) != fmt.Sprint(tt.wantFields) {
+			t.Errorf("%s: fields/ok = %v/%v, want %v/%v", tt.name, fields, ok, tt.wantFields, tt.wantOK)
+		}
+	}
+
+	// Pesan error Create tidak berubah karena dibungkus
+	if err := fieldError("CategoryID", fmt.Errorf("category not found")); err.Error() != "category not found" {
+		t.Errorf("error = %q, want category not found", err)
+	}
+}
+
+func TestProductCreateDefaultMinStock(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         ProductOptions
+		minStock     int
+		wantMinStock int
+	}{
+		{name: "fallback default", wantMinStock: defaultMinStockLevel},
+		{name: "configured default", opts: ProductOptions{DefaultMinStock: 12}, wantMinStock: 12},
+		{name: "explicit value kept", opts: ProductOptions{DefaultMinStock: 12}, minStock: 3, wantMinStock: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newProductFixture(tt.opts)
+			resp, err := f.service.Create(context.Background(), product.CreateProductRequest{
+				CategoryID:    f.category.ID.String(),
+				ShelfID:       f.shelf.ID.String(),
+				Name:          "Green Tea",
+				UnitPrice:     12,
+				CostPrice:     8,
+				MinStockLevel: tt.minStock,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.MinStockLevel != tt.wantMinStock {
+				t.Errorf("min_stock_level = %d, want %d", resp.MinStockLevel, tt.wantMinStock)
+			}
+		})
+	}
+}
+
+func TestProductMinStockLevelCap(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     ProductOptions
+		minStock int
+		wantErr  string
+	}{
+		{name: "exactly at cap", opts: ProductOptions{MaxMinStockLevel: 100}, minStock: 100},
+		{name: "one above cap", opts: ProductOptions{MaxMinStockLevel: 100}, minStock: 101, wantErr: "validation failed: min_stock_level must not exceed 100"},
+		{name: "no cap configured", minStock: 1000000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Run("create", func(t *testing.T) {
+				f := newProductFixture(tt.opts)
+				_, err := f.service.Create(context.Background(), product.CreateProductRequest{
+					CategoryID:    f.category.ID.String(),
+					ShelfID:       f.shelf.ID.String(),
+					Name:          "Green Tea",
+					UnitPrice:     12,
+					CostPrice:     8,
+					MinStockLevel: tt.minStock,
+				})
+				checkMinStockErr(t, err, tt.wantErr)
+				if wantCreated := tt.wantErr == ""; (len(f.products.created) == 1) != wantCreated {
+					t.Errorf("created = %d, want created %v", len(f.products.created), wantCreated)
+				}
+			})
+
+			t.Run("update", func(t *testing.T) {
+				f := newProductFixture(tt.opts)
+				_, err := f.service.Update(adminContext(), f.product.ID, product.UpdateProductRequest{MinStockLevel: intPtr(tt.minStock)})
+				checkMinStockErr(t, err, tt.wantErr)
+				if wantUpdated := tt.wantErr == ""; (len(f.products.updates) == 1) != wantUpdated {
+					t.Errorf("updates = %d, want updated %v", len(f.products.updates), wantUpdated)
+				}
+			})
+		})
+	}
+}
+
+func checkMinStockErr(t *testing.T, err error, wantErr string) {
+	t.Helper()
+	if wantErr == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	if err == nil || err.Error() != wantErr {
+		t.Fatalf("error = %v, want %q", err, wantErr)
+	}
+}
+
+// ========== RESTOCK ==========
+
+func TestProductRestock(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       func(f *productFixture) product.RestockRequest
+		wantErr   string
+		wantTotal int
+		wantQty   int
+	}{
+		{
+			name: "duplicate lines merged",
+			req: func(f *productFixture) product.RestockRequest {
+				return product.RestockRequest{Reference: " PO-1 ", Items: []product.RestockItem{
+					{ProductID: f.product.ID.String(), Quantity: 4},
+					{ProductID: f.product.ID.String(), Quantity: 6},
+				}}
+			},
+			wantTotal: 10,
+			wantQty:   10,
+		},
+		{
+			name: "unknown product",
+			req: func(f *productFixture) product.RestockRequest {
+				return product.RestockRequest{Reference: "PO-1", Items: []product.RestockItem{
+					{ProductID: f.product.ID.String(), Quantity: 1},
+					{ProductID: uuid.NewString(), Quantity: 1},
+				}}
+			},
+			wantErr: "one or more products not found",
+		},
+		{
+			name: "blank reference",
+			req: func(f *productFixture) product.RestockRequest {
+				return product.RestockRequest{Reference: "  ", Items: []product.RestockItem{{ProductID: f.product.ID.String(), Quantity: 1}}}
+			},
+			wantErr: "validation failed",
+		},
+		{
+			name: "zero quantity",
+			req: func(f *productFixture) product.RestockRequest {
+				return product.RestockRequest{Reference: "PO-1", Items: []product.RestockItem{{ProductID: f.product.ID.String(), Quantity: 0}}}
+			},
+			wantErr: "validation failed",
+		},
+		{
+			name:    "no items",
+			req:     func(f *productFixture) product.RestockRequest { return product.RestockRequest{Reference: "PO-1"} },
+			wantErr: "validation failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newProductFixture(ProductOptions{})
+
+			resp, err := f.service.Restock(context.Background(), tt.req(f))
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if f.products.restocked != nil {
+					t.Error("stock changed on error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp.Reference != "PO-1" {
+				t.Errorf("reference = %q, want trimmed PO-1", resp.Reference)
+			}
+			if resp.TotalQuantity != tt.wantTotal || len(resp.Items) != 1 {
+				t.Fatalf("total = %d items = %d, want %d and 1", resp.TotalQuantity, len(resp.Items), tt.wantTotal)
+			}
+			item := resp.Items[0]
+			if item.Quantity != tt.wantQty || item.OldStock != 20 || item.NewStock != 20+tt.wantQty {
+				t.Errorf("item = %+v", item)
+			}
+			if f.products.restocked[f.product.ID] != tt.wantQty {
+				t.Errorf("restocked quantity = %d, want %d", f.products.restocked[f.product.ID], tt.wantQty)
+			}
+		})
+	}
+}
+
+func TestProductRestockRepositoryErrors(t *testing.T) {
+	tests := []struct {
+		err     error
+		wantErr string
+	}{
+		{err: fmt.Errorf("one or more products not found"), wantErr: "one or more products not found"},
+		{err: fmt.Errorf("restock failed: boom"), wantErr: "failed to restock products"},
+	}
+
+	for _, tt := range tests {
+		f := newProductFixture(ProductOptions{})
+		f.products.restockErr = tt.err
+
+		_, err := f.service.Restock(context.Background(), product.RestockRequest{
+			Reference: "PO-1",
+			Items:     []product.RestockItem{{ProductID: f.product.ID.String(), Quantity: 1}},
+		})
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("error = %v, want %q", err, tt.wantErr)
+		}
+	}
+}
+
+func TestProductStockWritesRejectInactiveWarehouse(t *testing.T) {
+	quantity := 5
+
+	tests := []struct {
+		name string
+		run  func(f *productFixture) error
+	}{
+		{
+			name: "set location stock on inactive shelf",
+			run: func(f *productFixture) error {
+				_, err := f.service.SetLocationStock(context.Background(), f.product.ID, product.SetLocationStockRequest{ShelfID: f.inactiveShelf.ID.String(), Quantity: &quantity})
+				return err
+			},
+		},
+		{
+			name: "restock product on inactive shelf",
+			run: func(f *productFixture) error {
+				f.product.ShelfID = f.inactiveShelf.ID
+				_, err := f.service.UpdateStock(context.Background(), f.product.ID, product.UpdateStockRequest{Quantity: 30})
+				return err
+			},
+		},
+		{
+			name: "bulk restock product on inactive shelf",
+			run: func(f *productFixture) error {
+				f.product.ShelfID = f.inactiveShelf.ID
+				_, err := f.service.Restock(context.Background(), product.RestockRequest{
+					Reference: "PO-1",
+					Items:     []product.RestockItem{{ProductID: f.product.ID.String(), Quantity: 1}},
+				})
+				return err
+			},
+		},
+		{
+			name: "raise stock through product update on inactive shelf",
+			run: func(f *productFixture) error {
+				f.product.ShelfID = f.inactiveShelf.ID
+				_, err := f.service.Update(adminContext(), f.product.ID, product.UpdateProductRequest{StockQuantity: intPtr(30)})
+				return err
+			},
+		},
+		{
+			name: "apply stock count above expected on inactive shelf",
+			run: func(f *productFixture) error {
+				f.product.ShelfID = f.inactiveShelf.ID
+				_, err := f.service.StockCount(context.Background(), product.StockCountRequest{Items: []product.StockCountItem{
+					{ProductID: f.product.ID.String(), Counted: intPtr(25)},
+				}}, true)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// SetQuantity tidak di-fake: sampai ke repo = panic, jadi harus ditolak sebelum menulis
+			f := newProductFixture(ProductOptions{})
+
+			err := tt.run(f)
+			if err == nil || err.Error() != "shelf belongs to an inactive warehouse" {
+				t.Fatalf("error = %v, want shelf belongs to an inactive warehouse", err)
+			}
+			if f.products.restocked != nil || f.products.stockUpdates != 0 || len(f.products.updates) != 0 || f.products.appliedCounts != nil {
+				t.Error("stock changed on rejected write")
+			}
+		})
+	}
+}
+
+// Stok turun / tidak bertambah di rak warehouse nonaktif tetap boleh
+func TestProductStockDecreaseAllowedOnInactiveWarehouse(t *testing.T) {
+	t.Run("lower stock through product update", func(t *testing.T) {
+		f := newProductFixture(ProductOptions{})
+		f.product.ShelfID = f.inactiveShelf.ID
+
+		if _, err := f.service.Update(adminContext(), f.product.ID, product.UpdateProductRequest{StockQuantity: intPtr(10)}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(f.products.updates) != 1 || *f.products.updates[0].Stock != 10 {
+			t.Errorf("updates = %+v, want stock 10", f.products.updates)
+		}
+	})
+
+	t.Run("apply stock count at or below expected", func(t *testing.T) {
+		f := newProductFixture(ProductOptions{})
+		f.product.ShelfID = f.inactiveShelf.ID
+
+		_, err := f.service.StockCount(context.Background(), product.StockCountRequest{Items: []product.StockCountItem{
+			{ProductID: f.product.ID.String(), Counted: intPtr(12)},
+		}}, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if f.products.appliedCounts[f.product.ID] != 12 {
+			t.Errorf("applied counts = %v, want 12", f.products.appliedCounts)
+		}
+	})
+
+	t.Run("report-only stock count above expected", func(t *testing.T) {
+		f := newProductFixture(ProductOptions{})
+		f.product.ShelfID = f.inactiveShelf.ID
+
+		resp, err := f.service.StockCount(context.Background(), product.StockCountRequest{Items: []product.StockCountItem{
+			{ProductID: f.product.ID.String(), Counted: intPtr(25)},
+		}}, false)
+		if err != nil || resp.TotalVariance != 5 {
+			t.Fatalf("resp/err = %+v/%v, want variance 5", resp, err)
+		}
+	})
+}
+
+// ========== TRANSFER ==========
+
+func TestProductTransferStock(t *testing.T) {
+	tests := []struct {
+		name     string
+		to       func(f *productFixture) uuid.UUID
+		quantity int
+		wantErr  string
+	}{
+		{name: "cross warehouse", to: addOtherWarehouseShelf, quantity: 8},
+		{name: "more than source holds", to: addOtherWarehouseShelf, quantity: 21, wantErr: "insufficient stock at source shelf"},
+		{name: "destination in inactive warehouse", to: func(f *productFixture) uuid.UUID { return f.inactiveShelf.ID }, quantity: 1, wantErr: "shelf belongs to an inactive warehouse"},
+		{name: "unknown destination", to: func(f *productFixture) uuid.UUID { return uuid.New() }, quantity: 1, wantErr: "destination shelf not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newProductFixture(ProductOptions{})
+			toShelfID := tt.to(f)
+
+			resp, err := f.service.TransferStock(context.Background(), product.StockTransferRequest{
+				ProductID:   f.product.ID.String(),
+				FromShelfID: f.shelf.ID.String(),
+				ToShelfID:   toShelfID.String(),
+				Quantity:    tt.quantity,
+			})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if len(f.locations.transfers) != 0 || f.locations.locations[f.shelf.ID] != 20 {
+					t.Errorf("locations = %v, want untouched", f.locations.locations)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Pasangan movement di repo pakai reference_id yang sama dengan transfer_id di response
+			if len(f.locations.transfers) != 1 || f.locations.transfers[0].ReferenceID == nil || f.locations.transfers[0].ReferenceID.String() != resp.TransferID {
+				t.Fatalf("transfer movement = %+v, want reference %s", f.locations.transfers, resp.TransferID)
+			}
+
+			total := 0
+			for _, location := range resp.Stock.Locations {
+				total += location.Quantity
+			}
+			if total != 20 || f.locations.locations[f.shelf.ID] != 12 || f.locations.locations[toShelfID] != 8 {
+				t.Errorf("locations = %v (sum %d), want 12/8 with total 20", f.locations.locations, total)
+			}
+		})
+	}
+}
+
+// addOtherWarehouseShelf rak di warehouse aktif kedua (transfer lintas warehouse)
+func addOtherWarehouseShelf(f *productFixture) uuid.UUID {
+	other := &model.Warehouse{BaseModel: model.BaseModel{ID: uuid.New()}, IsActive: true}
+	shelf := &model.Shelf{BaseModel: model.BaseModel{ID: uuid.New()}, WarehouseID: other.ID}
+	f.warehouses.warehouses[other.ID] = other
+	f.shelves.shelves[shelf.ID] = shelf
+	return shelf.ID
+}
+
+// ========== IMAGE UPLOAD ==========
+
+func TestProductUploadImage(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 100)...)
+
+	tests := []struct {
+		name    string
+		file    []byte
+		size    int64
+		wantErr string
+	}{
+		{name: "png stored", file: png, size: int64(len(png))},
+		{name: "plain text rejected", file: []byte("hello, not an image"), size: 19, wantErr: "validation failed: unsupported image type text/plain; charset=utf-8"},
+		{name: "html rejected", file: []byte("<html><body>x</body></html>"), size: 27, wantErr: "validation failed: unsupported image type text/html; charset=utf-8"},
+		{name: "over size limit", file: png, size: 1025, wantErr: "validation failed: image exceeds maximum size of 1024 bytes"},
+		{name: "empty file", file: nil, size: 0, wantErr: "validation failed: image file is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &memoryStorage{files: map[string][]byte{}}
+			f := newProductFixture(ProductOptions{Storage: storage, MaxImageSize: 1024})
+
+			resp, err := f.service.UploadImage(context.Background(), f.product.ID, strings.NewReader(string(tt.file)), tt.size)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if len(storage.files) != 0 || f.products.products[f.product.ID].ImageURL != "" {
+					t.Error("file stored or product updated on rejected upload")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Nama file: product-<id>-<uuid>.png, isi utuh termasuk 512 byte yang di-sniff
+			if len(storage.files) != 1 {
+				t.Fatalf("stored files = %d, want 1", len(storage.files))
+			}
+			pattern := regexp.MustCompile(`^product-` + f.product.ID.String() + `-[0-9a-f-]{36}\.png$`)
+			for name, data := range storage.files {
+				if !pattern.MatchString(name) || string(data) != string(tt.file) {
+					t.Errorf("stored %q (%d bytes), want pattern %s with full content", name, len(data), pattern)
+				}
+				if resp.ImageURL != "/uploads/"+name || f.products.products[f.product.ID].ImageURL != resp.ImageURL {
+					t.Errorf("image_url = %q, stored = %q", resp.ImageURL, f.products.products[f.product.ID].ImageURL)
+				}
+			}
+
+			// URL relatif hasil upload harus lolos validasi saat dikirim balik lewat update produk
+			if err := utils.ValidateStruct(product.UpdateProductRequest{ImageURL: &resp.ImageURL}); err != nil {
+				t.Errorf("uploaded image_url rejected by update validation: %v", err)
+			}
+		})
+	}
+
+	f := newProductFixture(ProductOptions{})
+	if _, err := f.service.UploadImage(context.Background(), f.product.ID, strings.NewReader(string(png)), int64(len(png))); err == nil || err.Error() != "image upload is not configured" {
+		t.Errorf("no storage error = %v, want image upload is not configured", err)
+	}
+}
+
+// ========== MOVEMENT FEED ==========
+
+func TestProductGetMovementFeed(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        product.MovementFeedRequest
+		page       int
+		limit      int
+		wantErr    string
+		wantStart  string
+		wantEnd    string
+		wantType   string
+		wantLimit  int
+		wantOffset int
+	}{
+		{name: "no filters defaults", wantLimit: utils.DefaultPageLimit},
+		{
+			name:      "date range and type",
+			req:       product.MovementFeedRequest{StartDate: "2026-01-01", EndDate: "2026-01-31", Type: "restock"},
+			page:      3,
+			limit:     20,
+			wantStart: "2026-01-01",
+			wantEnd:   "2026-01-31",
+			wantType:  "restock",
+			wantLimit: 20, wantOffset: 40,
+		},
+		{name: "limit capped", limit: 1000, wantLimit: utils.MaxPageLimit},
+		{name: "start after end", req: product.MovementFeedRequest{StartDate: "2026-02-01", EndDate: "2026-01-01"}, wantErr: "start date cannot be after end date"},
+		{name: "bad date format", req: product.MovementFeedRequest{StartDate: "01/01/2026"}, wantErr: "validation failed"},
+		{name: "unknown type", req: product.MovementFeedRequest{Type: "theft"}, wantErr: "validation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newProductFixture(ProductOptions{})
+			f.movements.total = 45
+
+			_, pagination, err := f.service.GetMovementFeed(context.Background(), tt.req, tt.page, tt.limit)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := formatExpiryDate(f.movements.startDate); got != tt.wantStart {
+				t.Errorf("start date = %q, want %q", got, tt.wantStart)
+			}
+			if got := formatExpiryDate(f.movements.endDate); got != tt.wantEnd {
+				t.Errorf("end date = %q, want %q", got, tt.wantEnd)
+			}
+			if f.movements.movementType != tt.wantType {
+				t.Errorf("type = %q, want %q", f.movements.movementType, tt.wantType)
+			}
+			if f.movements.limit != tt.wantLimit || f.movements.offset != tt.wantOffset {
+				t.Errorf("limit/offset = %d/%d, want %d/%d", f.movements.limit, f.movements.offset, tt.wantLimit, tt.wantOffset)
+			}
+			if pagination.Total != 45 {
+				t.Errorf("total = %d, want 45", pagination.Total)
+			}
+		})
+	}
+}
+
+func TestProductGetMovementFeedEntries(t *testing.T) {
+	f := newProductFixture(ProductOptions{})
+	saleID := uuid.New()
+	userID := uuid.New()
+	f.movements.entries = []model.StockMovementFeedEntry{
+		{StockMovement: model.StockMovement{ProductID: f.product.ID, MovementType: model.StockMovementSale, Quantity: -2, StockAfter: 18, ReferenceID: &saleID, CreatedBy: &userID}},
+		{StockMovement: model.StockMovement{ProductID: f.product.ID, MovementType: model.StockMovementAdjustment, Quantity: 5, StockAfter: 25}},
+	}
+
+	entries, _, err := f.service.GetMovementFeed(context.Background(), product.MovementFeedRequest{}, 1, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(entries))
+	}
+	if entries[0].ReferenceID == nil || *entries[0].ReferenceID != saleID.String() || entries[0].UserID == nil || *entries[0].UserID != userID.String() {
+		t.Errorf("entry 0 = %+v, want reference & user set", entries[0])
+	}
+	// Movement lama tanpa user: user_id null
+	if entries[1].ReferenceID != nil || entries[1].UserID != nil {
+		t.Errorf("entry 1 = %+v, want reference & user nil", entries[1])
+	}
+}
+
+// ========== DISCONTINUE ==========
+
+func TestProductDiscontinue(t *testing.T) {
+	tests := []struct {
+		name        string
+		from        model.ProductStatus
+		req         product.DiscontinueProductRequest
+		wantStatus  model.ProductStatus
+		wantUpdates int
+	}{
+		{name: "discontinue by default", from: model.ProductStatusActive, wantStatus: model.ProductStatusDiscontinued, wantUpdates: 1},
+		{name: "reactivate", from: model.ProductStatusDiscontinued, req: product.DiscontinueProductRequest{Discontinued: boolPtr(false)}, wantStatus: model.ProductStatusActive, wantUpdates: 1},
+		{name: "already discontinued", from: model.ProductStatusDiscontinued, wantStatus: model.ProductStatusDiscontinued},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newProductFixture(ProductOptions{})
+			f.product.Status = tt.from
+
+			resp, err := f.service.Discontinue(context.Background(), f.product.ID, tt.req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Status != string(tt.wantStatus) || f.products.statusUpdates != tt.wantUpdates {
+				t.Errorf("status/updates = %s/%d, want %s/%d", resp.Status, f.products.statusUpdates, tt.wantStatus, tt.wantUpdates)
+			}
+
+			// Produk discontinued tetap bisa dibaca untuk histori
+			if found, err := f.service.FindByID(context.Background(), f.product.ID); err != nil || found.Status != string(tt.wantStatus) {
+				t.Errorf("lookup after status change = %v/%v, want %s", found, err, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// ========== MOVEMENT SUMMARY ==========
+
+func TestProductGetMovementSummary(t *testing.T) {
+	t.Run("each type aggregated separately", func(t *testing.T) {
+		f := newProductFixture(ProductOptions{})
+		// Urutan dari repo bebas (GROUP BY tanpa ORDER BY)
+		f.movements.summaries = []model.StockMovementSummary{
+			{MovementType: model.StockMovementAdjustment, MovementCount: 2, QuantityIn: 2, QuantityOut: 6},
+			{MovementType: model.StockMovementSale, MovementCount: 2, QuantityOut: 7},
+			{MovementType: model.StockMovementRestock, MovementCount: 2, QuantityIn: 25},
+		}
+
+		resp, err := f.service.GetMovementSummary(context.Background(), f.product.ID, product.MovementSummaryRequest{StartDate: "2024-03-01", EndDate: "2024-03-05"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Movements) != len(model.StockMovementTypes) {
+			t.Fatalf("movements = %+v, want all %d types", resp.Movements, len(model.StockMovementTypes))
+		}
+
+		want := map[string]product.MovementTypeSummary{
+			"sale":                 {MovementType: "sale", MovementCount: 2, QuantityOut: 7, NetQuantity: -7},
+			"restock":              {MovementType: "restock", MovementCount: 2, QuantityIn: 25, NetQuantity: 25},
+			"adjustment":           {MovementType: "adjustment", MovementCount: 2, QuantityIn: 2, QuantityOut: 6, NetQuantity: -4},
+			"cancellation_restore": {MovementType: "cancellation_restore"},
+			"transfer":             {MovementType: "transfer"},
+		}
+		for i, m := range resp.Movements {
+			if m.MovementType != string(model.StockMovementTypes[i]) {
+				t.Errorf("movements[%d] = %s, want %s", i, m.MovementType, model.StockMovementTypes[i])
+			}
+			if m != want[m.MovementType] {
+				t.Errorf("%s = %+v, want %+v", m.MovementType, m, want[m.MovementType])
+			}
+		}
+		if resp.TotalIn != 27 || resp.TotalOut != 13 || resp.NetChange != 14 {
+			t.Errorf("in/out/net = %d/%d/%d, want 27/13/14", resp.TotalIn, resp.TotalOut, resp.NetChange)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		req     product.MovementSummaryRequest
+		missing bool
+		wantErr string
+	}{
+		{name: "start after end", req: product.MovementSummaryRequest{StartDate: "2024-03-05", EndDate: "2024-03-01"}, wantErr: "start date cannot be after end date"},
+		{name: "range over a year", req: product.MovementSummaryRequest{StartDate: "2023-01-01", EndDate: "2024-03-01"}, wantErr: "date range cannot exceed 1 year"},
+		{name: "missing dates", req: product.MovementSummaryRequest{}, wantErr: "validation failed"},
+		{name: "product missing", req: product.MovementSummaryRequest{StartDate: "2024-03-01", EndDate: "2024-03-05"}, missing: true, wantErr: "product not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newProductFixture(ProductOptions{})
+			id := f.product.ID
+			if tt.missing {
+				id = uuid.New()
+			}
+
+			_, err := f.service.GetMovementSummary(context.Background(), id, tt.req)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if f.movements.startDate != nil {
+				t.Error("ledger queried on rejected request")
+			}
+		})
+	}
+}
+
+// ========== STOCK AT DATE ==========
+
+func TestProductGetStockAt(t *testing.T) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days, hour int) time.Time { return today.AddDate(0, 0, -days).Add(time.Duration(hour) * time.Hour) }
+	date := func(days int) string { return today.AddDate(0, 0, -days).Format("2006-01-02") }
+
+	// Dibuat 10 hari lalu dengan stok 10 (tidak di ledger), stok sekarang 10 + 20 - 5 - 3 = 22
+	setup := func() *productFixture {
+		f := newProductFixture(ProductOptions{})
+		f.product.CreatedAt = daysAgo(10, 9)
+		f.product.StockQuantity = 22
+		f.movements.currentStock = map[uuid.UUID]int{f.product.ID: 22}
+		f.movements.ledger = []model.StockMovement{
+			{ProductID: f.product.ID, MovementType: model.StockMovementRestock, Quantity: 20, CreatedAt: daysAgo(8, 10)},
+			{ProductID: f.product.ID, MovementType: model.StockMovementSale, Quantity: -5, CreatedAt: daysAgo(5, 14)},
+			{ProductID: f.product.ID, MovementType: model.StockMovementSale, Quantity: -3, CreatedAt: daysAgo(3, 0)},
+		}
+		return f
+	}
+
+	tests := []struct {
+		name          string
+		date          string
+		want          int
+		wantLedgerHit bool
+	}{
+		{name: "before product was created", date: date(11), want: 0},
+		{name: "creation day before any movement", date: date(10), want: 10, wantLedgerHit: true},
+		{name: "after restock", date: date(8), want: 30, wantLedgerHit: true},
+		{name: "after first sale", date: date(5), want: 25, wantLedgerHit: true},
+		{name: "movement at midnight belongs to that day", date: date(3), want: 22, wantLedgerHit: true},
+		{name: "past date after last movement", date: date(1), want: 22, wantLedgerHit: true},
+		{name: "today uses current stock", date: date(0), want: 22},
+		{name: "future date uses current stock", date: date(-3), want: 22},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setup()
+
+			resp, err := f.service.GetStockAt(context.Background(), f.product.ID, product.StockAtRequest{Date: tt.date})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Quantity != tt.want || resp.CurrentStock != 22 || resp.Date != tt.date {
+				t.Errorf("quantity/current/date = %d/%d/%s, want %d/22/%s", resp.Quantity, resp.CurrentStock, resp.Date, tt.want, tt.date)
+			}
+			if hit := f.movements.stockAtCalls > 0; hit != tt.wantLedgerHit {
+				t.Errorf("ledger queried = %v, want %v", hit, tt.wantLedgerHit)
+			}
+		})
+	}
+
+	t.Run("invalid date", func(t *testing.T) {
+		f := setup()
+		if _, err := f.service.GetStockAt(context.Background(), f.product.ID, product.StockAtRequest{Date: "yesterday"}); err == nil || !strings.HasPrefix(err.Error(), "validation failed") {
+			t.Errorf("error = %v, want validation failed", err)
+		}
+	})
+
+	t.Run("product missing", func(t *testing.T) {
+		f := setup()
+		if _, err := f.service.GetStockAt(context.Background(), uuid.New(), product.StockAtRequest{Date: date(3)}); err == nil || err.Error() != "product not found" {
+			t.Errorf("error = %v, want product not found", err)
+		}
+	})
+}
+
+// ========== LOCATION ==========
+
+func TestProductFindLocation(t *testing.T) {
+	warehouseID, shelfID := uuid.New(), uuid.New()
+	location := func(shelf, warehouse bool) *model.ProductLocation {
+		l := &model.ProductLocation{ProductName: "Coffee"}
+		if shelf {
+			l.ShelfID, l.ShelfCode, l.ShelfName = &shelfID, stringPtr("B3"), stringPtr("Shelf B3")
+		}
+		if warehouse {
+			l.WarehouseID, l.WarehouseCode, l.WarehouseName = &warehouseID, stringPtr("WH-A"), stringPtr("Warehouse A")
+		}
+		return l
+	}
+
+	tests := []struct {
+		name         string
+		location     *model.ProductLocation
+		wantErr      string
+		wantPath     string
+		wantOrphaned bool
+	}{
+		{name: "shelf and warehouse", location: location(true, true), wantPath: "Warehouse A / Shelf B3"},
+		{name: "warehouse deleted", location: location(true, false), wantPath: "Shelf B3", wantOrphaned: true},
+		{name: "shelf deleted", location: location(false, false), wantPath: "", wantOrphaned: true},
+		{name: "product missing", wantErr: "product not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newProductFixture(ProductOptions{})
+			f.products.locations = map[uuid.UUID]*model.ProductLocation{}
+			if tt.location != nil {
+				tt.location.ProductID = f.product.ID
+				f.products.locations[f.product.ID] = tt.location
+			}
+
+			resp, err := f.service.FindLocation(context.Background(), f.product.ID)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Path != tt.wantPath || resp.Orphaned != tt.wantOrphaned {
+				t.Errorf("path/orphaned = %q/%v, want %q/%v", resp.Path, resp.Orphaned, tt.wantPath, tt.wantOrphaned)
+			}
+			if (resp.ShelfID == nil) != (tt.location.ShelfID == nil) || (resp.WarehouseID == nil) != (tt.location.WarehouseID == nil) {
+				t.Errorf("shelf/warehouse ids = %v/%v, want nil only for deleted parts", resp.ShelfID, resp.WarehouseID)
+			}
+		})
+	}
+}
+
+// ========== BATCH FETCH ==========
+
+func TestProductFindByIDs(t *testing.T) {
+	coffee := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Coffee"}
+	tea := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Tea"}
+	milk := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Milk"}
+	missing := uuid.New()
+
+	ids := func(n int) []string {
+		list := make([]string, n)
+		for i := range list {
+			list[i] = uuid.NewString()
+		}
+		return list
+	}
+
+	tests := []struct {
+		name        string
+		maxIDs      int
+		ids         []string
+		wantNames   string
+		wantMissing []string
+		wantLookup  int
+		wantErr     string
+	}{
+		{
+			name:      "request order preserved",
+			ids:       []string{milk.ID.String(), coffee.ID.String(), tea.ID.String()},
+			wantNames: "Milk,Coffee,Tea", wantMissing: []string{}, wantLookup: 3,
+		},
+		{
+			name:      "missing ids reported in request order",
+			ids:       []string{tea.ID.String(), missing.String(), coffee.ID.String()},
+			wantNames: "Tea,Coffee", wantMissing: []string{missing.String()}, wantLookup: 3,
+		},
+		{
+			name:      "duplicates returned once",
+			ids:       []string{coffee.ID.String(), coffee.ID.String(), tea.ID.String()},
+			wantNames: "Coffee,Tea", wantMissing: []string{}, wantLookup: 2,
+		},
+		{
+			name:      "exactly at configured cap",
+			maxIDs:    3,
+			ids:       []string{coffee.ID.String(), tea.ID.String(), milk.ID.String()},
+			wantNames: "Coffee,Tea,Milk", wantMissing: []string{}, wantLookup: 3,
+		},
+		{name: "over configured cap", maxIDs: 3, ids: ids(4), wantErr: "too many ids: batch cannot have more than 3 ids"},
+		{name: "over default cap", ids: ids(defaultMaxBatchIDs + 1), wantErr: fmt.Sprintf("too many ids: batch cannot have more than %d ids", defaultMaxBatchIDs)},
+		{name: "empty", ids: []string{}, wantErr: "validation failed"},
+		{name: "invalid id", ids: []string{"not-a-uuid"}, wantErr: "validation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products := newFakeProductRepo(coffee, tea, milk)
+			svc := NewProductService(&repository.Repository{Product: products}, zap.NewNop(), nil, ProductOptions{MaxBatchIDs: tt.maxIDs})
+
+			resp, err := svc.FindByIDs(context.Background(), product.BatchProductsRequest{IDs: tt.ids})
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if len(products.batchLookups) != 0 {
+					t.Error("repo queried for rejected batch")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			names := make([]string, 0, len(resp.Products))
+			for _, p := range resp.Products {
+				names = append(names, p.Name)
+			}
+			if got := strings.Join(names, ","); got != tt.wantNames {
+				t.Errorf("products = %s, want %s", got, tt.wantNames)
+			}
+			if strings.Join(resp.MissingIDs, ",") != strings.Join(tt.wantMissing, ",") || resp.MissingIDs == nil {
+				t.Errorf("missing = %v, want %v", resp.MissingIDs, tt.wantMissing)
+			}
+			if len(products.batchLookups) != 1 || len(products.batchLookups[0]) != tt.wantLookup {
+				t.Errorf("lookups = %v, want one query with %d ids", products.batchLookups, tt.wantLookup)
+			}
+		})
+	}
+}
+
+// ========== CRITICAL STOCK ==========
+
+func TestProductFindCriticalStock(t *testing.T) {
+	stocked := func(name string, stock, minLevel int) *model.Product {
+		return &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, Name: name, StockQuantity: stock, MinStockLevel: minLevel}
+	}
+	newRepo := func() *fakeProductRepo {
+		return newFakeProductRepo(
+			stocked("At half", 5, 10),
+			stocked("Just above half", 6, 10),
+			stocked("Empty", 0, 10),
+			stocked("At min", 4, 4),
+			stocked("No min level", 0, 0),
+		)
+	}
+
+	tests := []struct {
+		name      string
+		percent   float64
+		wantNames string
+		wantErr   bool
+	}{
+		{name: "exactly at threshold included", percent: 0.5, wantNames: "Empty,At half"},
+		{name: "percent one equals low stock", percent: 1, wantNames: "Empty,At half,Just above half,At min"},
+		{name: "zero percent rejected", percent: 0, wantErr: true},
+		{name: "negative percent rejected", percent: -0.5, wantErr: true},
+		{name: "above one rejected", percent: 1.01, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products := newRepo()
+			svc := NewProductService(&repository.Repository{Product: products}, zap.NewNop(), nil, ProductOptions{})
+
+			resp, err := svc.FindCriticalStock(context.Background(), product.CriticalStockRequest{Percent: tt.percent})
+			if tt.wantErr {
+				if err == nil || !strings.HasPrefix(err.Error(), "validation failed") {
+					t.Fatalf("error = %v, want validation failed", err)
+				}
+				if products.criticalCalls != 0 {
+					t.Error("repo queried with invalid percent")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			names := make([]string, 0, len(resp))
+			for _, p := range resp {
+				names = append(names, p.Name)
+				if p.StockDeficit != stockDeficit(p.MinStockLevel, p.StockQuantity) {
+					t.Errorf("%s deficit = %d, want %d", p.Name, p.StockDeficit, stockDeficit(p.MinStockLevel, p.StockQuantity))
+				}
+			}
+			if got := strings.Join(names, ","); got != tt.wantNames {
+				t.Errorf("products = %s, want %s", got, tt.wantNames)
+			}
+		})
+	}
+}
+
+// ========== STALE STOCK ==========
+
+func TestProductFindStaleStock(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, _ := time.Parse("2006-01-02 15:04", value)
+		return parsed
+	}
+	stale := func(name string, updatedAt time.Time) *model.Product {
+		return &model.Product{BaseModel: model.BaseModel{ID: uuid.New(), UpdatedAt: updatedAt}, Name: name}
+	}
+
+	t.Run("only products updated before the cutoff, oldest first", func(t *testing.T) {
+		products := newFakeProductRepo(
+			stale("Cutoff", at("2024-06-01 00:00")),
+			stale("Recent", at("2024-07-01 09:00")),
+			stale("Day before", at("2024-05-31 23:59")),
+			stale("Oldest", at("2024-01-15 08:00")),
+		)
+		svc := NewProductService(&repository.Repository{Product: products}, zap.NewNop(), nil, ProductOptions{})
+
+		resp, err := svc.FindStaleStock(context.Background(), product.StaleStockRequest{Before: "2024-06-01"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if products.staleBefore == nil || !products.staleBefore.Equal(at("2024-06-01 00:00")) {
+			t.Errorf("cutoff = %v, want start of 2024-06-01", products.staleBefore)
+		}
+
+		var names []string
+		for _, p := range resp {
+			names = append(names, p.Name)
+			if want := daysUntil(at("2024-01-15 08:00"), time.Now()); p.Name == "Oldest" && p.DaysSinceUpdate != want {
+				t.Errorf("days since update = %d, want %d", p.DaysSinceUpdate, want)
+			}
+		}
+		if got := strings.Join(names, ","); got != "Oldest,Day before" {
+			t.Errorf("products = %s, want Oldest,Day before", got)
+		}
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		products := newFakeProductRepo()
+		svc := NewProductService(&repository.Repository{Product: products}, zap.NewNop(), nil, ProductOptions{})
+
+		_, err := svc.FindStaleStock(context.Background(), product.StaleStockRequest{Before: "01-06-2024"})
+		if err == nil || !strings.HasPrefix(err.Error(), "validation failed") {
+			t.Fatalf("error = %v, want validation failed", err)
+		}
+		if products.staleBefore != nil {
+			t.Error("repo queried with invalid date")
+		}
+	})
+}
+
+// ========== NEWLY LOW STOCK ==========
+
+func TestProductFindNewlyLowStock(t *testing.T) {
+	// Coffee fixture stok 20 min 5
+	setup := func(opts ProductOptions) *productFixture {
+		f := newProductFixture(opts)
+		for _, p := range []*model.Product{
+			{Name: "At threshold", StockQuantity: 12, MinStockLevel: 5},
+			{Name: "Just above min", StockQuantity: 6, MinStockLevel: 5},
+			{Name: "Already low", StockQuantity: 5, MinStockLevel: 5},
+			{Name: "Out of stock", StockQuantity: 0, MinStockLevel: 0},
+			{Name: "Above threshold", StockQuantity: 13, MinStockLevel: 2},
+		} {
+			p.ID = uuid.New()
+			f.products.products[p.ID] = p
+		}
+		return f
+	}
+
+	t.Run("compares stock against threshold and current min", func(t *testing.T) {
+		f := setup(ProductOptions{})
+
+		resp, err := f.service.FindNewlyLowStock(context.Background(), product.NewlyLowStockRequest{Threshold: intPtr(12)})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var names []string
+		for _, r := range resp {
+			names = append(names, r.Name)
+		}
+		// Stok == threshold ikut, stok <= min (sudah low) dan stok > threshold tidak
+		if got, want := strings.Join(names, ","), "Just above min,At threshold"; got != want {
+			t.Fatalf("products = %s, want %s", got, want)
+		}
+		if resp[0].Threshold != 12 || resp[0].ThresholdDeficit != 6 || resp[1].ThresholdDeficit != 0 {
+			t.Errorf("threshold/deficits = %d/%d/%d, want 12/6/0", resp[0].Threshold, resp[0].ThresholdDeficit, resp[1].ThresholdDeficit)
+		}
+		if resp[0].IsLowStock {
+			t.Error("is_low_stock should reflect the current min level")
+		}
+	})
+
+	t.Run("threshold zero", func(t *testing.T) {
+		f := setup(ProductOptions{})
+
+		resp, err := f.service.FindNewlyLowStock(context.Background(), product.NewlyLowStockRequest{Threshold: intPtr(0)})
+		if err != nil || len(resp) != 0 {
+			t.Fatalf("resp/err = %+v/%v, want empty", resp, err)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		opts      ProductOptions
+		threshold *int
+		wantErr   string
+	}{
+		{name: "missing threshold", wantErr: "validation failed"},
+		{name: "negative threshold", threshold: intPtr(-1), wantErr: "validation failed"},
+		{name: "above max min stock", opts: ProductOptions{MaxMinStockLevel: 100}, threshold: intPtr(101), wantErr: "validation failed: threshold must not exceed 100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setup(tt.opts)
+
+			_, err := f.service.FindNewlyLowStock(context.Background(), product.NewlyLowStockRequest{Threshold: tt.threshold})
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if f.products.stockUpToCalls != 0 {
+				t.Error("repository called on invalid threshold")
+			}
+		})
+	}
+}
+
+// ========== RECATEGORIZE ==========
+
+func TestProductRecategorize(t *testing.T) {
+	target := &model.Category{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Snacks"}
+
+	tests := []struct {
+		name         string
+		categoryID   string
+		extraIDs     []string
+		wantErr      string
+		wantUpdated  int
+		wantRepoCall bool
+	}{
+		{name: "moved to target", categoryID: target.ID.String(), wantUpdated: 1, wantRepoCall: true},
+		{name: "duplicate ids counted once", categoryID: target.ID.String(), extraIDs: []string{"self"}, wantUpdated: 1, wantRepoCall: true},
+		{name: "unknown target category", categoryID: uuid.NewString(), wantErr: "category not found"},
+		{name: "missing product", categoryID: target.ID.String(), extraIDs: []string{uuid.NewString()}, wantErr: "one or more products not found", wantRepoCall: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newProductFixture(ProductOptions{})
+			f.service.repo.Category = &fakeCategoryRepo{categories: map[uuid.UUID]*model.Category{f.category.ID: f.category, target.ID: target}}
+
+			ids := []string{f.product.ID.String()}
+			for _, id := range tt.extraIDs {
+				if id == "self" {
+					id = f.product.ID.String()
+				}
+				ids = append(ids, id)
+			}
+
+			resp, err := f.service.Recategorize(context.Background(), product.RecategorizeProductsRequest{ProductIDs: ids, CategoryID: tt.categoryID})
+			if (f.products.recategorized != nil) != tt.wantRepoCall {
+				t.Errorf("repo called = %v, want %v", f.products.recategorized != nil, tt.wantRepoCall)
+			}
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if f.products.products[f.product.ID].CategoryID != f.category.ID {
+					t.Error("category changed on rejected request")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Updated != tt.wantUpdated || resp.CategoryID != target.ID.String() {
+				t.Errorf("response = %+v, want %d updated to %s", resp, tt.wantUpdated, target.ID)
+			}
+			if f.products.products[f.product.ID].CategoryID != target.ID {
+				t.Error("product category not updated")
+			}
+		})
+	}
+}
+
+// ========== PRICE ADJUST ==========
+
+func TestProductAdjustPrices(t *testing.T) {
+	t.Run("category prices adjusted", func(t *testing.T) {
+		f := newProductFixture(ProductOptions{})
+		f.product.CostPrice = 8
+		other := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, CategoryID: uuid.New(), Name: "Bread", UnitPrice: 5, CostPrice: 3}
+		f.products.products[other.ID] = other
+
+		ctx := adminContext()
+		resp, err := f.service.AdjustPrices(ctx, product.PriceAdjustRequest{
+			CategoryID:       f.category.ID.String(),
+			UnitPricePercent: floatPtr(5),
+			CostPricePercent: floatPtr(-2.5),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Updated != 1 || resp.CategoryID != f.category.ID.String() || resp.UnitPricePercent != 5 || resp.CostPricePercent != -2.5 {
+			t.Errorf("response = %+v, want 1 updated at 5%%/-2.5%%", resp)
+		}
+		if f.product.UnitPrice != 10.5 || f.product.CostPrice != 7.8 {
+			t.Errorf("unit/cost = %v/%v, want 10.5/7.8", f.product.UnitPrice, f.product.CostPrice)
+		}
+		if other.UnitPrice != 5 || other.CostPrice != 3 {
+			t.Error("product from another category changed")
+		}
+
+		// Actor dari context tercatat sebagai changed_by price history
+		if len(f.products.priceAdjusts) != 1 {
+			t.Fatalf("repo calls = %d, want 1", len(f.products.priceAdjusts))
+		}
+		call := f.products.priceAdjusts[0]
+		if actor := utils.GetUserFromContext(ctx); call.changedBy == nil || *call.changedBy != actor.ID {
+			t.Errorf("changed_by = %v, want %v", call.changedBy, actor.ID)
+		}
+	})
+
+	t.Run("omitted percent leaves that price", func(t *testing.T) {
+		f := newProductFixture(ProductOptions{})
+		f.product.CostPrice = 8
+
+		if _, err := f.service.AdjustPrices(context.Background(), product.PriceAdjustRequest{
+			CategoryID:       f.category.ID.String(),
+			CostPricePercent: floatPtr(10),
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if f.product.UnitPrice != 10 || f.product.CostPrice != 8.8 {
+			t.Errorf("unit/cost = %v/%v, want 10/8.8", f.product.UnitPrice, f.product.CostPrice)
+		}
+		if call := f.products.priceAdjusts[0]; call.unitPercent != 0 || call.changedBy != nil {
+			t.Errorf("call = %+v, want unit 0%% and no actor", call)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		categoryID string
+		unit, cost *float64
+		wantErr    string
+	}{
+		{name: "no percent", wantErr: "validation failed: unit_price_percent or cost_price_percent is required"},
+		{name: "zero percents", unit: floatPtr(0), cost: floatPtr(0), wantErr: "validation failed: unit_price_percent or cost_price_percent is required"},
+		{name: "price to zero", unit: floatPtr(-100), wantErr: "validation failed"},
+		{name: "percent too high", cost: floatPtr(1000.5), wantErr: "validation failed"},
+		{name: "invalid category id", categoryID: "not-a-uuid", unit: floatPtr(5), wantErr: "validation failed"},
+		{name: "unknown category", categoryID: uuid.NewString(), unit: floatPtr(5), wantErr: "category not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newProductFixture(ProductOptions{})
+			categoryID := tt.categoryID
+			if categoryID == "" {
+				categoryID = f.category.ID.String()
+			}
+
+			_, err := f.service.AdjustPrices(context.Background(), product.PriceAdjustRequest{CategoryID: categoryID, UnitPricePercent: tt.unit, CostPricePercent: tt.cost})
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if len(f.products.priceAdjusts) != 0 || f.product.UnitPrice != 10 {
+				t.Error("prices adjusted on rejected request")
+			}
+		})
+	}
+}
+
+// ========== BULK MIN STOCK ==========
+
+func TestProductBulkUpdateMinStock(t *testing.T) {
+	t.Run("valid and missing ids", func(t *testing.T) {
+		f := newProductFixture(ProductOptions{})
+		missing := uuid.NewString()
+
+		resp, err := f.service.BulkUpdateMinStock(context.Background(), product.BulkMinStockRequest{Items: []product.BulkMinStockItem{
+			{ProductID: f.product.ID.String(), MinStockLevel: intPtr(12)},
+			{ProductID: missing, MinStockLevel: intPtr(3)},
+		}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Updated != 1 || resp.Failed != 1 || len(resp.Results) != 2 {
+			t.Fatalf("updated/failed/results = %d/%d/%d, want 1/1/2", resp.Updated, resp.Failed, len(resp.Results))
+		}
+		if r := resp.Results[0]; !r.Success || r.MinStockLevel != 12 || r.Error != "" {
+			t.Errorf("results[0] = %+v, want success", r)
+		}
+		if r := resp.Results[1]; r.Success || r.ProductID != missing || r.Error != "product not found" {
+			t.Errorf("results[1] = %+v, want product not found", r)
+		}
+		if f.products.products[f.product.ID].MinStockLevel != 12 {
+			t.Error("min stock level not stored")
+		}
+	})
+
+	t.Run("negative level rejects whole request", func(t *testing.T) {
+		f := newProductFixture(ProductOptions{})
+
+		_, err := f.service.BulkUpdateMinStock(context.Background(), product.BulkMinStockRequest{Items: []product.BulkMinStockItem{
+			{ProductID: f.product.ID.String(), MinStockLevel: intPtr(12)},
+			{ProductID: uuid.NewString(), MinStockLevel: intPtr(-1)},
+		}})
+		if err == nil || !strings.HasPrefix(err.Error(), "validation failed") {
+			t.Fatalf("error = %v, want validation failed", err)
+		}
+		if f.products.minStockCalls != 0 {
+			t.Error("repository called on invalid request")
+		}
+	})
+}
+
+// ========== BULK STATUS ==========
+
+func TestProductBulkUpdateStatus(t *testing.T) {
+	t.Run("toggle several products", func(t *testing.T) {
+		f := newProductFixture(ProductOptions{})
+		f.product.Status = model.ProductStatusActive
+		second := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Tea", Status: model.ProductStatusActive}
+		third := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Milk", Status: model.ProductStatusDiscontinued}
+		f.products.products[second.ID], f.products.products[third.ID] = second, third
+		ids := []string{f.product.ID.String(), second.ID.String(), third.ID.String(), second.ID.String()}
+
+		resp, err := f.service.BulkUpdateStatus(context.Background(), product.BulkStatusRequest{ProductIDs: ids, Status: "discontinued"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// ID duplikat dihitung sekali, produk yang sudah discontinued tidak berubah
+		if resp.Requested != 3 || resp.Updated != 2 || resp.Unchanged != 1 {
+			t.Errorf("requested/updated/unchanged = %d/%d/%d, want 3/2/1", resp.Requested, resp.Updated, resp.Unchanged)
+		}
+		for _, p := range []*model.Product{f.product, second, third} {
+			if f.products.products[p.ID].Status != model.ProductStatusDiscontinued {
+				t.Errorf("%s status = %s, want discontinued", p.Name, f.products.products[p.ID].Status)
+			}
+		}
+
+		// Toggle balik ke active
+		resp, err = f.service.BulkUpdateStatus(context.Background(), product.BulkStatusRequest{ProductIDs: ids, Status: "active"})
+		if err != nil || resp.Updated != 3 {
+			t.Fatalf("reactivate = %+v/%v, want 3 updated", resp, err)
+		}
+		for _, p := range []*model.Product{f.product, second, third} {
+			if f.products.products[p.ID].Status != model.ProductStatusActive {
+				t.Errorf("%s status = %s, want active", p.Name, f.products.products[p.ID].Status)
+			}
+		}
+	})
+
+	t.Run("missing product changes nothing", func(t *testing.T) {
+		f := newProductFixture(ProductOptions{})
+		f.product.Status = model.ProductStatusActive
+
+		_, err := f.service.BulkUpdateStatus(context.Background(), product.BulkStatusRequest{
+			ProductIDs: []string{f.product.ID.String(), uuid.NewString()},
+			Status:     "discontinued",
+		})
+		if err == nil || err.Error() != "one or more products not found" {
+			t.Fatalf("error = %v, want one or more products not found", err)
+		}
+		if f.products.products[f.product.ID].Status != model.ProductStatusActive {
+			t.Error("status changed despite missing product")
+		}
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		f := newProductFixture(ProductOptions{})
+
+		_, err := f.service.BulkUpdateStatus(context.Background(), product.BulkStatusRequest{
+			ProductIDs: []string{f.product.ID.String()},
+			Status:     "archived",
+		})
+		if err == nil || !strings.HasPrefix(err.Error(), "validation failed") {
+			t.Fatalf("error = %v, want validation failed", err)
+		}
+		if len(f.products.statusBatches) != 0 {
+			t.Error("repository called on invalid request")
+		}
+	})
+}
+
+// ========== STOCK COUNT ==========
+
+func TestProductStockCount(t *testing.T) {
+	// Coffee stok 20 cost 4, Tea stok 10 cost 2.5, Milk stok 7 (hitungan sama)
+	setup := func() (*productFixture, *model.Product, *model.Product) {
+		f := newProductFixture(ProductOptions{})
+		f.product.CostPrice = 4
+		tea := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Tea", StockQuantity: 10, CostPrice: 2.5, ShelfID: f.shelf.ID}
+		milk := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Milk", StockQuantity: 7, CostPrice: 1, ShelfID: f.shelf.ID}
+		f.products.products[tea.ID], f.products.products[milk.ID] = tea, milk
+		return f, tea, milk
+	}
+	request := func(f *productFixture, tea, milk *model.Product, missing string) product.StockCountRequest {
+		return product.StockCountRequest{Items: []product.StockCountItem{
+			{ProductID: f.product.ID.String(), Counted: intPtr(99)},
+			{ProductID: tea.ID.String(), Counted: intPtr(13)},
+			{ProductID: milk.ID.String(), Counted: intPtr(7)},
+			{ProductID: missing, Counted: intPtr(1)},
+			// Duplikat: nilai terakhir yang dipakai
+			{ProductID: f.product.ID.String(), Counted: intPtr(18)},
+		}}
+	}
+	checkItems := func(t *testing.T, resp *product.StockCountResponse, f *productFixture, tea, milk *model.Product, missing string) {
+		t.Helper()
+		// Urutan sesuai request, duplikat muncul sekali
+		want := []product.StockCountResult{
+			{ProductID: f.product.ID.String(), ProductName: "Coffee", Expected: 20, Counted: 18, Variance: -2, VarianceValue: -8, Found: true},
+			{ProductID: tea.ID.String(), ProductName: "Tea", Expected: 10, Counted: 13, Variance: 3, VarianceValue: 7.5, Found: true},
+			{ProductID: milk.ID.String(), ProductName: "Milk", Expected: 7, Counted: 7, Found: true},
+			{ProductID: missing, Counted: 1, Error: "product not found"},
+		}
+		if len(resp.Items) != len(want) {
+			t.Fatalf("items = %+v, want %d items", resp.Items, len(want))
+		}
+		for i, w := range want {
+			if resp.Items[i] != w {
+				t.Errorf("items[%d] = %+v, want %+v", i, resp.Items[i], w)
+			}
+		}
+		if resp.TotalVariance != 1 || resp.TotalVarianceValue != -0.5 {
+			t.Errorf("total variance/value = %d/%v, want 1/-0.5", resp.TotalVariance, resp.TotalVarianceValue)
+		}
+	}
+
+	t.Run("report only", func(t *testing.T) {
+		f, tea, milk := setup()
+		missing := uuid.NewString()
+
+		resp, err := f.service.StockCount(context.Background(), request(f, tea, milk, missing), false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		checkItems(t, resp, f, tea, milk, missing)
+		if resp.Applied || resp.Adjusted != 0 {
+			t.Errorf("applied/adjusted = %v/%d, want false/0", resp.Applied, resp.Adjusted)
+		}
+		if f.products.appliedCounts != nil {
+			t.Error("ApplyStockCounts called without apply")
+		}
+		if f.products.products[f.product.ID].StockQuantity != 20 || tea.StockQuantity != 10 {
+			t.Error("stock changed without apply")
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		f, tea, milk := setup()
+		missing := uuid.NewString()
+
+		resp, err := f.service.StockCount(context.Background(), request(f, tea, milk, missing), true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		checkItems(t, resp, f, tea, milk, missing)
+		// Hanya produk yang stoknya berubah dihitung adjusted
+		if !resp.Applied || resp.Adjusted != 2 {
+			t.Errorf("applied/adjusted = %v/%d, want true/2", resp.Applied, resp.Adjusted)
+		}
+		if len(f.products.appliedCounts) != 4 || f.products.appliedCounts[f.product.ID] != 18 {
+			t.Errorf("applied counts = %v, want 4 products with Coffee 18", f.products.appliedCounts)
+		}
+		if f.products.products[f.product.ID].StockQuantity != 18 || tea.StockQuantity != 13 || milk.StockQuantity != 7 {
+			t.Errorf("stocks = %d/%d/%d, want 18/13/7", f.products.products[f.product.ID].StockQuantity, tea.StockQuantity, milk.StockQuantity)
+		}
+	})
+
+	t.Run("negative count rejected", func(t *testing.T) {
+		f, _, _ := setup()
+
+		_, err := f.service.StockCount(context.Background(), product.StockCountRequest{Items: []product.StockCountItem{
+			{ProductID: f.product.ID.String(), Counted: intPtr(-1)},
+		}}, true)
+		if err == nil || !strings.HasPrefix(err.Error(), "validation failed") {
+			t.Fatalf("error = %v, want validation failed", err)
+		}
+		if f.products.appliedCounts != nil {
+			t.Error("ApplyStockCounts called on invalid request")
+		}
+	})
+}
+
+// ========== CHECK STOCK (BATCH) ==========
+
+func TestProductCheckStockBatch(t *testing.T) {
+	f := newProductFixture(ProductOptions{}) // Coffee, stok 20
+	retired := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Old Tea", StockQuantity: 50, Status: model.ProductStatusDiscontinued}
+	f.products.products[retired.ID] = retired
+	coffee, missing := f.product.ID.String(), uuid.NewString()
+
+	tests := []struct {
+		name          string
+		items         []product.StockCheckItem
+		wantAvailable []bool
+		wantReasons   []string
+		wantLookup    int
+	}{
+		{
+			name:          "sufficient and insufficient",
+			items:         []product.StockCheckItem{{ProductID: coffee, Quantity: 20}, {ProductID: retired.ID.String(), Quantity: 1}, {ProductID: missing, Quantity: 1}},
+			wantAvailable: []bool{true, false, false},
+			wantReasons:   []string{"", "discontinued", "not_found"},
+			wantLookup:    3,
+		},
+		{
+			name:          "duplicates within stock",
+			items:         []product.StockCheckItem{{ProductID: coffee, Quantity: 5}, {ProductID: coffee, Quantity: 5}},
+			wantAvailable: []bool{true, true},
+			wantReasons:   []string{"", ""},
+			wantLookup:    1,
+		},
+		{
+			name:          "duplicates over stock combined",
+			items:         []product.StockCheckItem{{ProductID: coffee, Quantity: 12}, {ProductID: coffee, Quantity: 12}},
+			wantAvailable: []bool{false, false},
+			wantReasons:   []string{"insufficient_stock", "insufficient_stock"},
+			wantLookup:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f.products.stockLookups = nil
+
+			resp, err := f.service.CheckStockBatch(context.Background(), product.CheckStockBatchRequest{Items: tt.items})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// Satu query untuk semua item, ID duplikat hanya dikirim sekali
+			if len(f.products.stockLookups) != 1 || len(f.products.stockLookups[0]) != tt.wantLookup {
+				t.Errorf("lookups = %v, want one query with %d ids", f.products.stockLookups, tt.wantLookup)
+			}
+			if len(resp.Items) != len(tt.items) {
+				t.Fatalf("items = %d, want %d", len(resp.Items), len(tt.items))
+			}
+
+			allAvailable := true
+			for i, item := range resp.Items {
+				allAvailable = allAvailable && tt.wantAvailable[i]
+				if item.Available != tt.wantAvailable[i] || item.Reason != tt.wantReasons[i] || item.Requested != tt.items[i].Quantity {
+					t.Errorf("items[%d] = %+v, want available %v reason %q", i, item, tt.wantAvailable[i], tt.wantReasons[i])
+				}
+			}
+			if resp.AllAvailable != allAvailable {
+				t.Errorf("all_available = %v, want %v", resp.AllAvailable, allAvailable)
+			}
+		})
+	}
+}
+
+// ========== HELPERS ==========
+
+func TestStockDeficit(t *testing.T) {
+	tests := []struct {
+		name          string
+		min, quantity int
+		want          int
+	}{
+		{name: "below min", min: 10, quantity: 4, want: 6},
+		{name: "at min", min: 10, quantity: 10, want: 0},
+		{name: "above min", min: 10, quantity: 15, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := stockDeficit(tt.min, tt.quantity); got != tt.want {
+			t.Errorf("%s: stockDeficit(%d, %d) = %d, want %d", tt.name, tt.min, tt.quantity, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestedOrder(t *testing.T) {
+	tests := []struct {
+		name             string
+		reorder, current int
+		want             int
+	}{
+		{name: "below target", reorder: 50, current: 20, want: 30},
+		{name: "no reorder target", reorder: 0, current: 20, want: 0},
+		{name: "above target", reorder: 50, current: 60, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := suggestedOrder(tt.reorder, tt.current); got != tt.want {
+			t.Errorf("%s: suggestedOrder(%d, %d) = %d, want %d", tt.name, tt.reorder, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeSKU(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		isNil bool
+	}{
+		{input: "", isNil: true},
+		{input: "   ", isNil: true},
+		{input: " ABC-1 ", want: "ABC-1"},
+	}
+
+	for _, tt := range tests {
+		got := normalizeSKU(tt.input)
+		if (got == nil) != tt.isNil || formatSKU(got) != tt.want {
+			t.Errorf("normalizeSKU(%q) = %v, want %q (nil %v)", tt.input, got, tt.want, tt.isNil)
+		}
+	}
+}
+
+func TestParseExpiryDate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "", want: ""},
+		{input: "2026-03-31", want: "2026-03-31"},
+		{input: "2026-02-30", wantErr: true},
+		{input: "31-03-2026", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseExpiryDate(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseExpiryDate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if formatExpiryDate(got) != tt.want {
+			t.Errorf("parseExpiryDate(%q) = %q, want %q", tt.input, formatExpiryDate(got), tt.want)
+		}
+	}
+}
+
+func TestDaysUntil(t *testing.T) {
+	from := time.Date(2026, 3, 10, 23, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		to   time.Time
+		want int
+	}{
+		{to: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), want: 0},
+		{to: time.Date(2026, 3, 11, 0, 1, 0, 0, time.UTC), want: 1},
+		{to: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), want: -9},
+	}
+
+	for _, tt := range tests {
+		if got := daysUntil(from, tt.to); got != tt.want {
+			t.Errorf("daysUntil(%v) = %d, want %d", tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestEmitLowStockIfCrossed(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldStock int
+		oldMin   int
+		newStock int
+		newMin   int
+		wantEmit bool
+	}{
+		{name: "crossed down", oldStock: 10, oldMin: 5, newStock: 5, newMin: 5, wantEmit: true},
+		{name: "still above", oldStock: 10, oldMin: 5, newStock: 6, newMin: 5},
+		{name: "already low", oldStock: 4, oldMin: 5, newStock: 2, newMin: 5},
+		{name: "min raised over stock", oldStock: 10, oldMin: 5, newStock: 10, newMin: 12, wantEmit: true},
+		{name: "restocked out of low", oldStock: 2, oldMin: 5, newStock: 20, newMin: 5},
+	}
+
+	for _, tt := range tests {
+		notifier := &recordingNotifier{}
+		p := &model.Product{StockQuantity: tt.newStock, MinStockLevel: tt.newMin}
+		emitLowStockIfCrossed(notifier, p, tt.oldStock, tt.oldMin)
+
+		if got := len(notifier.events) == 1; got != tt.wantEmit {
+			t.Errorf("%s: emitted = %v, want %v", tt.name, got, tt.wantEmit)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/dto/replenishment"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"inventory-system/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ReplenishmentService interface {
+	Create(ctx context.Context, productID uuid.UUID, req replenishment.CreateReplenishmentRequest, userID uuid.UUID) (*replenishment.ReplenishmentResponse, error)
+	FindAll(ctx context.Context, status string, page int, limit int) ([]replenishment.ReplenishmentResponse, utils.Pagination, error)
+	Review(ctx context.Context, id uuid.UUID, req replenishment.ReviewReplenishmentRequest, reviewerID uuid.UUID) (*replenishment.ReplenishmentResponse, error)
+}
+
+type replenishmentService struct {
+	repo *repository.Repository
+	log  *zap.Logger
+}
+
+func NewReplenishmentService(repo *repository.Repository, log *zap.Logger) ReplenishmentService {
+	return &replenishmentService{repo: repo, log: log}
+}
+
+func (rs *replenishmentService) Create(ctx context.Context, productID uuid.UUID, req replenishment.CreateReplenishmentRequest, userID uuid.UUID) (*replenishment.ReplenishmentResponse, error) {
+	// Validate input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	product, err := rs.repo.Product.FindByID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	// Produk discontinued tidak perlu di-restock
+	if product.Status == model.ProductStatusDiscontinued {
+		return nil, fmt.Errorf("product is discontinued")
+	}
+
+	newRequest := &model.ReplenishmentRequest{
+		ProductID:         productID,
+		RequestedBy:       userID,
+		RequestedQuantity: req.RequestedQuantity,
+	}
+	if req.Notes != "" {
+		newRequest.Notes = &req.Notes
+	}
+
+	if err := rs.repo.Replenishment.Create(ctx, newRequest); err != nil {
+		return nil, fmt.Errorf("failed to create replenishment request")
+	}
+
+	return rs.convertToResponse(newRequest), nil
+}
+
+// FindAll status kosong = semua, selain itu harus pending/approved/rejected
+func (rs *replenishmentService) FindAll(ctx context.Context, status string, page int, limit int) ([]replenishment.ReplenishmentResponse, utils.Pagination, error) {
+	// Setup pagination
+	pagination := utils.NewPagination(page, limit)
+
+	switch model.ReplenishmentStatus(status) {
+	case "", model.ReplenishmentStatusPending, model.ReplenishmentStatusApproved, model.ReplenishmentStatusRejected:
+	default:
+		return nil, pagination, fmt.Errorf("invalid status filter")
+	}
+
+	requests, err := rs.repo.Replenishment.FindAll(ctx, status, pagination.Limit, pagination.Offset())
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to get replenishment requests")
+	}
+
+	total, err := rs.repo.Replenishment.CountAll(ctx, status)
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to count replenishment requests")
+	}
+
+	pagination.SetTotal(total)
+
+	responses := make([]replenishment.ReplenishmentResponse, 0, len(requests))
+	for _, request := range requests {
+		responses = append(responses, *rs.convertToResponse(&request))
+	}
+
+	return responses, pagination, nil
+}
+
+// Review approve/reject, hanya dari status pending
+// Approve tidak mengubah stok, stok ditambah lewat update stock saat barang diterima
+func (rs *replenishmentService) Review(ctx context.Context, id uuid.UUID, req replenishment.ReviewReplenishmentRequest, reviewerID uuid.UUID) (*replenishment.ReplenishmentResponse, error) {
+	// Validate input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	request, err := rs.repo.Replenishment.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("replenishment request not found")
+	}
+
+	if request.Status != model.ReplenishmentStatusPending {
+		return nil, fmt.Errorf("replenishment request already reviewed")
+	}
+
+	request.Status = model.ReplenishmentStatus(req.Status)
+	request.ReviewedBy = &reviewerID
+	if req.Notes != "" {
+		request.ReviewNotes = &req.Notes
+	}
+
+	if err := rs.repo.Replenishment.Review(ctx, request); err != nil {
+		// Bisa kalah race dengan admin lain
+		if err.Error() == "replenishment request already reviewed" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to review replenishment request")
+	}
+
+	return rs.convertToResponse(request), nil
+}
+
+func (rs *replenishmentService) convertToResponse(r *model.ReplenishmentRequest) *replenishment.ReplenishmentResponse {
+	response := &replenishment.ReplenishmentResponse{
+		ID:                r.ID.String(),
+		ProductID:         r.ProductID.String(),
+		RequestedBy:       r.RequestedBy.String(),
+		RequestedQuantity: r.RequestedQuantity,
+		Notes:             r.Notes,
+		Status:            string(r.Status),
+		ReviewedAt:        r.ReviewedAt,
+		ReviewNotes:       r.ReviewNotes,
+		CreatedAt:         r.CreatedAt,
+		UpdatedAt:         r.UpdatedAt,
+	}
+	if r.ReviewedBy != nil {
+		reviewedBy := r.ReviewedBy.String()
+		response.ReviewedBy = &reviewedBy
+	}
+
+	return response
+}
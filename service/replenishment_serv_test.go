@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/dto/replenishment"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKES ==========
+
+type fakeReplenishmentRepo struct {
+	repository.ReplenishmentRepo
+	requests  map[uuid.UUID]*model.ReplenishmentRequest
+	created   []*model.ReplenishmentRequest
+	reviewed  []*model.ReplenishmentRequest
+	reviewErr error
+	status    string
+	limit     int
+	offset    int
+}
+
+func newFakeReplenishmentRepo(requests ...*model.ReplenishmentRequest) *fakeReplenishmentRepo {
+	repo := &fakeReplenishmentRepo{requests: map[uuid.UUID]*model.ReplenishmentRequest{}}
+	for _, r := range requests {
+		repo.requests[r.ID] = r
+	}
+	return repo
+}
+
+func (f *fakeReplenishmentRepo) Create(ctx context.Context, r *model.ReplenishmentRequest) error {
+	r.ID = uuid.New()
+	r.Status = model.ReplenishmentStatusPending
+	f.created = append(f.created, r)
+	return nil
+}
+
+func (f *fakeReplenishmentRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.ReplenishmentRequest, error) {
+	r, ok := f.requests[id]
+	if !ok {
+		return nil, fmt.Errorf("replenishment request not found")
+	}
+	copied := *r
+	return &copied, nil
+}
+
+func (f *fakeReplenishmentRepo) FindAll(ctx context.Context, status string, limit int, offset int) ([]model.ReplenishmentRequest, error) {
+	f.status, f.limit, f.offset = status, limit, offset
+	var result []model.ReplenishmentRequest
+	for _, r := range f.requests {
+		if status == "" || string(r.Status) == status {
+			result = append(result, *r)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeReplenishmentRepo) CountAll(ctx context.Context, status string) (int, error) {
+	return len(f.requests), nil
+}
+
+func (f *fakeReplenishmentRepo) Review(ctx context.Context, r *model.ReplenishmentRequest) error {
+	if f.reviewErr != nil {
+		return f.reviewErr
+	}
+	f.reviewed = append(f.reviewed, r)
+	return nil
+}
+
+func newReplenishment(status model.ReplenishmentStatus) *model.ReplenishmentRequest {
+	return &model.ReplenishmentRequest{
+		BaseModel:         model.BaseModel{ID: uuid.New()},
+		ProductID:         uuid.New(),
+		RequestedBy:       uuid.New(),
+		RequestedQuantity: 10,
+		Status:            status,
+	}
+}
+
+// ========== CREATE ==========
+
+func TestReplenishmentCreate(t *testing.T) {
+	active := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, Status: model.ProductStatusActive}
+	discontinued := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, Status: model.ProductStatusDiscontinued}
+	staffID := uuid.New()
+
+	tests := []struct {
+		name      string
+		productID uuid.UUID
+		req       replenishment.CreateReplenishmentRequest
+		wantErr   string
+		wantNotes bool
+	}{
+		{name: "pending request", productID: active.ID, req: replenishment.CreateReplenishmentRequest{RequestedQuantity: 20}},
+		{name: "with notes", productID: active.ID, req: replenishment.CreateReplenishmentRequest{RequestedQuantity: 5, Notes: "stok menipis"}, wantNotes: true},
+		{name: "zero quantity", productID: active.ID, req: replenishment.CreateReplenishmentRequest{}, wantErr: "validation failed"},
+		{name: "unknown product", productID: uuid.New(), req: replenishment.CreateReplenishmentRequest{RequestedQuantity: 5}, wantErr: "product not found"},
+		{name: "discontinued product", productID: discontinued.ID, req: replenishment.CreateReplenishmentRequest{RequestedQuantity: 5}, wantErr: "product is discontinued"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := newFakeReplenishmentRepo()
+			repo := &repository.Repository{Product: newFakeProductRepo(active, discontinued), Replenishment: requests}
+			svc := NewReplenishmentService(repo, zap.NewNop())
+
+			resp, err := svc.Create(context.Background(), tt.productID, tt.req, staffID)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if len(requests.created) != 0 {
+					t.Error("request stored on rejected input")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp.Status != string(model.ReplenishmentStatusPending) || resp.RequestedBy != staffID.String() || resp.RequestedQuantity != tt.req.RequestedQuantity {
+				t.Errorf("response = %+v", resp)
+			}
+			if (resp.Notes != nil) != tt.wantNotes {
+				t.Errorf("notes = %v, want set %v", resp.Notes, tt.wantNotes)
+			}
+		})
+	}
+}
+
+// ========== LIST ==========
+
+func TestReplenishmentFindAll(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      string
+		page, limit int
+		wantErr     string
+		wantOffset  int
+	}{
+		{name: "all statuses", page: 1, limit: 10},
+		{name: "pending page two", status: "pending", page: 2, limit: 10, wantOffset: 10},
+		{name: "invalid status", status: "cancelled", page: 1, limit: 10, wantErr: "invalid status filter"},
+	}
+
+	for _, tt := range tests {
+		requests := newFakeReplenishmentRepo(newReplenishment(model.ReplenishmentStatusPending), newReplenishment(model.ReplenishmentStatusApproved))
+		svc := NewReplenishmentService(&repository.Repository{Replenishment: requests}, zap.NewNop())
+
+		resp, pagination, err := svc.FindAll(context.Background(), tt.status, tt.page, tt.limit)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if requests.status != tt.status || requests.offset != tt.wantOffset {
+			t.Errorf("%s: status/offset = %q/%d, want %q/%d", tt.name, requests.status, requests.offset, tt.status, tt.wantOffset)
+		}
+		if resp == nil || pagination.Total != 2 {
+			t.Errorf("%s: resp = %v, total = %d", tt.name, resp, pagination.Total)
+		}
+	}
+}
+
+// ========== REVIEW ==========
+
+func TestReplenishmentReview(t *testing.T) {
+	reviewerID := uuid.New()
+
+	tests := []struct {
+		name      string
+		status    model.ReplenishmentStatus
+		req       replenishment.ReviewReplenishmentRequest
+		unknown   bool
+		reviewErr error
+		wantErr   string
+	}{
+		{name: "approve", status: model.ReplenishmentStatusPending, req: replenishment.ReviewReplenishmentRequest{Status: "approved"}},
+		{name: "reject with notes", status: model.ReplenishmentStatusPending, req: replenishment.ReviewReplenishmentRequest{Status: "rejected", Notes: "budget habis"}},
+		{name: "invalid status", status: model.ReplenishmentStatusPending, req: replenishment.ReviewReplenishmentRequest{Status: "pending"}, wantErr: "validation failed"},
+		{name: "already reviewed", status: model.ReplenishmentStatusApproved, req: replenishment.ReviewReplenishmentRequest{Status: "rejected"}, wantErr: "replenishment request already reviewed"},
+		{name: "not found", unknown: true, req: replenishment.ReviewReplenishmentRequest{Status: "approved"}, wantErr: "replenishment request not found"},
+		{name: "lost race to other reviewer", status: model.ReplenishmentStatusPending, req: replenishment.ReviewReplenishmentRequest{Status: "approved"}, reviewErr: fmt.Errorf("replenishment request already reviewed"), wantErr: "replenishment request already reviewed"},
+		{name: "repository failure", status: model.ReplenishmentStatusPending, req: replenishment.ReviewReplenishmentRequest{Status: "approved"}, reviewErr: fmt.Errorf("connection reset"), wantErr: "failed to review replenishment request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := newReplenishment(tt.status)
+			requests := newFakeReplenishmentRepo(existing)
+			requests.reviewErr = tt.reviewErr
+			svc := NewReplenishmentService(&repository.Repository{Replenishment: requests}, zap.NewNop())
+
+			id := existing.ID
+			if tt.unknown {
+				id = uuid.New()
+			}
+
+			resp, err := svc.Review(context.Background(), id, tt.req, reviewerID)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp.Status != tt.req.Status || resp.ReviewedBy == nil || *resp.ReviewedBy != reviewerID.String() {
+				t.Errorf("response = %+v", resp)
+			}
+			if (resp.ReviewNotes != nil) != (tt.req.Notes != "") {
+				t.Errorf("review notes = %v, want %q", resp.ReviewNotes, tt.req.Notes)
+			}
+			if len(requests.reviewed) != 1 {
+				t.Errorf("Review calls = %d, want 1", len(requests.reviewed))
+			}
+		})
+	}
+}
@@ -4,10 +4,15 @@ import (
 	"context"
 	"fmt"
 	"inventory-system/dto/report"
+	"inventory-system/model"
 	"inventory-system/repository"
 	"inventory-system/utils"
+	"math"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -20,18 +25,79 @@ type ReportService interface {
 
 	// 3. Revenue report (pendapatan) - untuk admin/super_admin saja
 	GetRevenueReport(ctx context.Context, req report.RevenueReportRequest) (*report.RevenueReportResponse, error)
+
+	// 4. Inventory valuation (export per product) - untuk admin/super_admin saja
+	StreamInventoryValuation(ctx context.Context, fn func(row report.InventoryValuationRow) error) error
+
+	// 5. Sales per kategori - untuk admin/super_admin saja
+	GetSalesByCategory(ctx context.Context, req report.SalesByCategoryRequest) (*report.SalesByCategoryResponse, error)
+
+	// 6. Revenue periode ini vs periode sebelumnya - untuk admin/super_admin saja
+	CompareRevenue(ctx context.Context, req report.RevenueCompareRequest) (*report.RevenueComparisonResponse, error)
+
+	// 7. Inventory turnover per produk - untuk admin/super_admin saja
+	GetInventoryTurnover(ctx context.Context, req report.InventoryTurnoverRequest) (*report.InventoryTurnoverResponse, error)
+
+	// 8. Sales trend (time series untuk chart) - untuk admin/super_admin saja
+	GetSalesTrend(ctx context.Context, req report.SalesTrendRequest) (*report.SalesTrendResponse, error)
+
+	// 9. Inventory summary satu warehouse
+	GetWarehouseInventory(ctx context.Context, warehouseID uuid.UUID) (*report.WarehouseInventoryResponse, error)
+
+	// 10. Sales stats milik user sendiri (staff tanpa akses admin)
+	GetMySalesStats(ctx context.Context, userID uuid.UUID, req report.MySalesStatsRequest) (*report.MySalesStatsResponse, error)
+
+	// 11. Distribusi produk & stok per rak dalam satu warehouse
+	GetShelfDistribution(ctx context.Context, warehouseID uuid.UUID) (*report.ShelfDistributionResponse, error)
+
+	// 12. Penjualan per jam (staffing kasir) - untuk admin/super_admin saja
+	GetSalesByHour(ctx context.Context, req report.SalesByHourRequest) (*report.SalesByHourResponse, error)
+
+	// 13. Revenue per cara bayar - untuk admin/super_admin saja
+	GetRevenueByPaymentMethod(ctx context.Context, req report.RevenueByPaymentRequest) (*report.RevenueByPaymentResponse, error)
+
+	// 14. Customer dengan belanja terbesar - untuk admin/super_admin saja
+	GetTopCustomers(ctx context.Context, req report.TopCustomersRequest) (*report.TopCustomersResponse, error)
+
+	// 15. Durasi pending -> completed (workflow analysis) - untuk admin/super_admin saja
+	GetFulfillmentTime(ctx context.Context, req report.FulfillmentTimeRequest) (*report.FulfillmentTimeResponse, error)
+
+	// 16. Stok akhir bulan per produk (snapshot dari ledger) - untuk admin/super_admin saja
+	GetMonthlySnapshot(ctx context.Context, req report.MonthlySnapshotRequest) (*report.MonthlySnapshotResponse, error)
 }
 
 type reportService struct {
 	repo *repository.Repository
 	log  *zap.Logger
+	opts ReportOptions
+}
+
+// ReportOptions - pengaturan report service dari config
+type ReportOptions struct {
+	MaxRangeDays int // range maksimal sales & revenue report, 0 = fallback defaultReportMaxRangeDays
 }
 
-func NewReportService(repo *repository.Repository, log *zap.Logger) ReportService {
+// Fallback range maksimal report (1 tahun) sesuai requirement awal
+const defaultReportMaxRangeDays = 365
+
+func NewReportService(repo *repository.Repository, log *zap.Logger, opts ReportOptions) ReportService {
+	if opts.MaxRangeDays <= 0 {
+		opts.MaxRangeDays = defaultReportMaxRangeDays
+	}
 	return &reportService{
 		repo: repo,
 		log:  log,
+		opts: opts,
+	}
+}
+
+// checkMaxRange tolak range lebih dari MaxRangeDays (end_date inclusive, jadi start == end = 1 hari)
+func (rs *reportService) checkMaxRange(startDate, endDate time.Time) error {
+	days := int(endDate.Sub(startDate).Hours()/24) + 1
+	if days > rs.opts.MaxRangeDays {
+		return fmt.Errorf("date range cannot exceed %d days", rs.opts.MaxRangeDays)
 	}
+	return nil
 }
 
 // ========== 1. PRODUCT INVENTORY REPORT ==========
@@ -39,11 +105,14 @@ func (rs *reportService) GetProductReport(ctx context.Context) (*report.ProductR
 	// Langsung panggil repository
 	reportData, err := rs.repo.Report.GetProductInventoryReport(ctx)
 	if err != nil {
-		rs.log.Error("Failed to get product report", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to get product report", zap.Error(err))
 		return nil, fmt.Errorf("failed to get product report")
 	}
 
-	rs.log.Info("Product report generated")
+	reportData.TotalValue = utils.RoundMoney(reportData.TotalValue)
+	reportData.Currency = utils.Currency()
+
+	utils.LoggerFromContext(ctx).Info("Product report generated")
 	return reportData, nil
 }
 
@@ -70,24 +139,58 @@ func (rs *reportService) GetSalesReport(ctx context.Context, req report.SalesRep
 		return nil, fmt.Errorf("start date cannot be after end date")
 	}
 
-	// Batasi max range (opsional: 1 tahun)
-	maxRange := 365 * 24 * time.Hour
-	if endDate.Sub(startDate) > maxRange {
-		return nil, fmt.Errorf("date range cannot exceed 1 year")
+	// Batasi max range (REPORT_MAX_RANGE_DAYS)
+	if err := rs.checkMaxRange(startDate, endDate); err != nil {
+		return nil, err
+	}
+
+	// Optional filter: kasir
+	// Staff hanya boleh lihat penjualan sendiri, user_id dari query diabaikan
+	var userID *uuid.UUID
+	if actor := utils.GetUserFromContext(ctx); actor != nil && !actor.CanAccessRevenueReport() {
+		userID = &actor.ID
+	} else if req.UserID != nil {
+		id, err := uuid.Parse(*req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID format")
+		}
+		if _, err := rs.repo.User.FindByID(ctx, id); err != nil {
+			return nil, fmt.Errorf("user not found")
+		}
+		userID = &id
+	}
+
+	// Optional filter: kategori produk
+	var categoryID *uuid.UUID
+	if req.CategoryID != nil {
+		id, err := uuid.Parse(*req.CategoryID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid category ID format")
+		}
+		if _, err := rs.repo.Category.FindByID(ctx, id); err != nil {
+			return nil, fmt.Errorf("category not found")
+		}
+		categoryID = &id
 	}
 
 	// Panggil repository
-	reportData, err := rs.repo.Report.GetSalesReport(ctx, startDate, endDate)
+	reportData, err := rs.repo.Report.GetSalesReport(ctx, startDate, endDate, userID, categoryID)
 	if err != nil {
-		rs.log.Error("Failed to get sales report", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to get sales report", zap.Error(err))
 		return nil, fmt.Errorf("failed to get sales report")
 	}
 
-	rs.log.Info("Sales report generated",
+	utils.LoggerFromContext(ctx).Info("Sales report generated",
 		zap.Time("start_date", startDate),
 		zap.Time("end_date", endDate),
+		zap.Bool("filter_user", userID != nil),
+		zap.Bool("filter_category", categoryID != nil),
 		zap.Int("total_sales", reportData.TotalSales))
 
+	reportData.TotalRevenue = utils.RoundMoney(reportData.TotalRevenue)
+	reportData.AverageSale = utils.RoundMoney(reportData.AverageSale)
+	reportData.Currency = utils.Currency()
+
 	return reportData, nil
 }
 
@@ -114,17 +217,775 @@ func (rs *reportService) GetRevenueReport(ctx context.Context, req report.Revenu
 		return nil, fmt.Errorf("start date cannot be after end date")
 	}
 
+	// Batasi max range (REPORT_MAX_RANGE_DAYS), sama dengan sales report
+	if err := rs.checkMaxRange(startDate, endDate); err != nil {
+		return nil, err
+	}
+
 	// Panggil repository
-	reportData, err := rs.repo.Report.GetRevenueReport(ctx, startDate, endDate, req.GroupBy)
+	reportData, err := rs.repo.Report.GetRevenueReport(ctx, startDate, endDate, req.GroupBy, req.PaymentStatus)
 	if err != nil {
-		rs.log.Error("Failed to get revenue report", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to get revenue report", zap.Error(err))
 		return nil, fmt.Errorf("failed to get revenue report")
 	}
 
-	rs.log.Info("Revenue report generated",
+	utils.LoggerFromContext(ctx).Info("Revenue report generated",
 		zap.Time("start_date", startDate),
 		zap.Time("end_date", endDate),
-		zap.String("group_by", req.GroupBy))
+		zap.String("group_by", req.GroupBy),
+		zap.String("payment_status", req.PaymentStatus))
+
+	reportData.TotalRevenue = utils.RoundMoney(reportData.TotalRevenue)
+	reportData.AverageSale = utils.RoundMoney(reportData.AverageSale)
+	reportData.Currency = utils.Currency()
+	for _, periods := range [][]report.TimePeriodRevenue{reportData.DailyRevenue, reportData.WeeklyRevenue, reportData.MonthlyRevenue} {
+		for i := range periods {
+			periods[i].Revenue = utils.RoundMoney(periods[i].Revenue)
+		}
+	}
 
 	return reportData, nil
 }
+
+// ========== 4. INVENTORY VALUATION ==========
+func (rs *reportService) StreamInventoryValuation(ctx context.Context, fn func(row report.InventoryValuationRow) error) error {
+	if err := rs.repo.Report.GetInventoryValuation(ctx, fn); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to stream inventory valuation", zap.Error(err))
+		return fmt.Errorf("failed to get inventory valuation")
+	}
+
+	utils.LoggerFromContext(ctx).Info("Inventory valuation exported")
+	return nil
+}
+
+// ========== 5. SALES BY CATEGORY ==========
+func (rs *reportService) GetSalesByCategory(ctx context.Context, req report.SalesByCategoryRequest) (*report.SalesByCategoryResponse, error) {
+	// Validasi input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Parse tanggal
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+	}
+
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+	}
+
+	// Validasi range tanggal
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start date cannot be after end date")
+	}
+
+	maxRange := 365 * 24 * time.Hour
+	if endDate.Sub(startDate) > maxRange {
+		return nil, fmt.Errorf("date range cannot exceed 1 year")
+	}
+
+	// Panggil repository
+	categories, err := rs.repo.Report.GetSalesByCategory(ctx, startDate, endDate, req.IncludeEmpty)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get sales by category", zap.Error(err))
+		return nil, fmt.Errorf("failed to get sales by category")
+	}
+
+	utils.LoggerFromContext(ctx).Info("Sales by category report generated",
+		zap.Time("start_date", startDate),
+		zap.Time("end_date", endDate),
+		zap.Int("categories", len(categories)))
+
+	for i := range categories {
+		categories[i].Revenue = utils.RoundMoney(categories[i].Revenue)
+	}
+
+	return &report.SalesByCategoryResponse{
+		Currency:   utils.Currency(),
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Categories: categories,
+	}, nil
+}
+
+// ========== 6. REVENUE COMPARISON ==========
+// Periode berjalan (sampai sekarang) vs periode penuh sebelumnya
+func (rs *reportService) CompareRevenue(ctx context.Context, req report.RevenueCompareRequest) (*report.RevenueComparisonResponse, error) {
+	// Validasi input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	now := time.Now()
+	currentStart, previousStart := periodStarts(now, req.Period)
+
+	// BETWEEN inklusif, jadi akhir periode sebelumnya = 1ns sebelum periode berjalan
+	current, err := rs.repo.Report.GetSalesReport(ctx, currentStart, now, nil, nil)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get current period revenue", zap.Error(err))
+		return nil, fmt.Errorf("failed to get revenue comparison")
+	}
+	previous, err := rs.repo.Report.GetSalesReport(ctx, previousStart, currentStart.Add(-time.Nanosecond), nil, nil)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get previous period revenue", zap.Error(err))
+		return nil, fmt.Errorf("failed to get revenue comparison")
+	}
+
+	currentPeriod := toPeriodRevenue(current)
+	previousPeriod := toPeriodRevenue(previous)
+
+	utils.LoggerFromContext(ctx).Info("Revenue comparison generated",
+		zap.String("period", req.Period),
+		zap.Time("current_start", currentStart),
+		zap.Time("previous_start", previousStart))
+
+	return &report.RevenueComparisonResponse{
+		Period:               req.Period,
+		Currency:             utils.Currency(),
+		Current:              currentPeriod,
+		Previous:             previousPeriod,
+		RevenueChangePct:     percentChange(currentPeriod.Revenue, previousPeriod.Revenue),
+		SalesCountChangePct:  percentChange(float64(currentPeriod.SalesCount), float64(previousPeriod.SalesCount)),
+		AverageSaleChangePct: percentChange(currentPeriod.AverageSale, previousPeriod.AverageSale),
+	}, nil
+}
+
+// ========== 7. INVENTORY TURNOVER ==========
+func (rs *reportService) GetInventoryTurnover(ctx context.Context, req report.InventoryTurnoverRequest) (*report.InventoryTurnoverResponse, error) {
+	// Validasi input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Parse tanggal
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+	}
+
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+	}
+
+	// Validasi range tanggal
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start date cannot be after end date")
+	}
+
+	maxRange := 365 * 24 * time.Hour
+	if endDate.Sub(startDate) > maxRange {
+		return nil, fmt.Errorf("date range cannot exceed 1 year")
+	}
+
+	// Panggil repository
+	products, err := rs.repo.Report.GetInventoryTurnover(ctx, startDate, endDate)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get inventory turnover", zap.Error(err))
+		return nil, fmt.Errorf("failed to get inventory turnover")
+	}
+
+	for i := range products {
+		products[i].AverageStock, products[i].Turnover = inventoryTurnover(products[i].OpeningStock, products[i].ClosingStock, products[i].UnitsSold)
+	}
+
+	// Turnover tertinggi dulu, produk tanpa rasio (average stock 0) di akhir
+	sort.SliceStable(products, func(i, j int) bool {
+		a, b := products[i].Turnover, products[j].Turnover
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return *a > *b
+	})
+
+	utils.LoggerFromContext(ctx).Info("Inventory turnover report generated",
+		zap.Time("start_date", startDate),
+		zap.Time("end_date", endDate),
+		zap.Int("products", len(products)))
+
+	return &report.InventoryTurnoverResponse{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Products:  products,
+	}, nil
+}
+
+// ========== 8. SALES TREND ==========
+// Batas range per interval supaya jumlah bucket tetap wajar untuk chart
+var maxTrendRange = map[string]time.Duration{
+	"day":   366 * 24 * time.Hour,     // maks ~366 bucket
+	"week":  2 * 366 * 24 * time.Hour, // maks ~105 bucket
+	"month": 5 * 366 * 24 * time.Hour, // maks 60 bucket
+}
+
+func (rs *reportService) GetSalesTrend(ctx context.Context, req report.SalesTrendRequest) (*report.SalesTrendResponse, error) {
+	// Validasi input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Parse tanggal
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+	}
+
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+	}
+
+	// Validasi range tanggal
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start date cannot be after end date")
+	}
+	if endDate.Sub(startDate) > maxTrendRange[req.Interval] {
+		return nil, fmt.Errorf("date range too large for interval %s", req.Interval)
+	}
+
+	// Panggil repository
+	buckets, err := rs.repo.Report.GetSalesTimeSeries(ctx, startDate, endDate, req.Interval)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get sales trend", zap.Error(err))
+		return nil, fmt.Errorf("failed to get sales trend")
+	}
+
+	buckets = fillTrendBuckets(buckets, startDate, endDate, req.Interval)
+
+	utils.LoggerFromContext(ctx).Info("Sales trend generated",
+		zap.Time("start_date", startDate),
+		zap.Time("end_date", endDate),
+		zap.String("interval", req.Interval),
+		zap.Int("buckets", len(buckets)))
+
+	return &report.SalesTrendResponse{
+		Currency:  utils.Currency(),
+		Interval:  req.Interval,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Buckets:   buckets,
+	}, nil
+}
+
+// ========== 9. WAREHOUSE INVENTORY ==========
+func (rs *reportService) GetWarehouseInventory(ctx context.Context, warehouseID uuid.UUID) (*report.WarehouseInventoryResponse, error) {
+	warehouse, err := rs.repo.Warehouse.FindByID(ctx, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("warehouse not found")
+	}
+
+	summary, err := rs.repo.Report.GetWarehouseInventory(ctx, warehouseID)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get warehouse inventory", zap.Error(err))
+		return nil, fmt.Errorf("failed to get warehouse inventory")
+	}
+
+	summary.TotalValue = utils.RoundMoney(summary.TotalValue)
+	summary.Currency = utils.Currency()
+
+	return &report.WarehouseInventoryResponse{
+		WarehouseID:           warehouse.ID.String(),
+		WarehouseName:         warehouse.Name,
+		ProductReportResponse: *summary,
+	}, nil
+}
+
+// ========== 10. MY SALES STATS ==========
+// Selalu difilter ke userID pemanggil (diambil dari context di handler, bukan dari query)
+func (rs *reportService) GetMySalesStats(ctx context.Context, userID uuid.UUID, req report.MySalesStatsRequest) (*report.MySalesStatsResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Default: awal bulan berjalan s/d hari ini
+	now := time.Now()
+	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var err error
+	if req.StartDate != "" {
+		if startDate, err = time.Parse("2006-01-02", req.StartDate); err != nil {
+			return nil, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+		}
+	}
+	if req.EndDate != "" {
+		if endDate, err = time.Parse("2006-01-02", req.EndDate); err != nil {
+			return nil, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+		}
+	}
+
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start date cannot be after end date")
+	}
+	if endDate.Sub(startDate) > 365*24*time.Hour {
+		return nil, fmt.Errorf("date range cannot exceed 1 year")
+	}
+
+	// Repo yang sama dengan sales report (filter per kasir)
+	stats, err := rs.repo.Report.GetSalesReport(ctx, startDate, endDate, &userID, nil)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get user sales stats", zap.Error(err))
+		return nil, fmt.Errorf("failed to get sales stats")
+	}
+
+	stats.TotalRevenue = utils.RoundMoney(stats.TotalRevenue)
+	stats.AverageSale = utils.RoundMoney(stats.AverageSale)
+	stats.Currency = utils.Currency()
+
+	return &report.MySalesStatsResponse{
+		UserID:              userID.String(),
+		SalesReportResponse: *stats,
+	}, nil
+}
+
+// ========== 11. SHELF DISTRIBUTION ==========
+func (rs *reportService) GetShelfDistribution(ctx context.Context, warehouseID uuid.UUID) (*report.ShelfDistributionResponse, error) {
+	warehouse, err := rs.repo.Warehouse.FindByID(ctx, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("warehouse not found")
+	}
+
+	shelves, err := rs.repo.Report.GetShelfDistribution(ctx, warehouseID)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get shelf distribution", zap.Error(err))
+		return nil, fmt.Errorf("failed to get shelf distribution")
+	}
+
+	response := &report.ShelfDistributionResponse{
+		WarehouseID:   warehouse.ID.String(),
+		WarehouseName: warehouse.Name,
+		TotalShelves:  len(shelves),
+		Shelves:       shelves,
+	}
+	for _, shelf := range shelves {
+		if shelf.ProductCount == 0 {
+			response.EmptyShelves++
+		}
+	}
+
+	return response, nil
+}
+
+// ========== 12. SALES BY HOUR ==========
+func (rs *reportService) GetSalesByHour(ctx context.Context, req report.SalesByHourRequest) (*report.SalesByHourResponse, error) {
+	// Validasi input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Parse tanggal
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+	}
+
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+	}
+
+	// Validasi range tanggal (max 1 tahun, sama dengan sales report)
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start date cannot be after end date")
+	}
+	if endDate.Sub(startDate) > 365*24*time.Hour {
+		return nil, fmt.Errorf("date range cannot exceed 1 year")
+	}
+
+	hours, err := rs.repo.Report.GetSalesByHour(ctx, startDate, endDate)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get sales by hour", zap.Error(err))
+		return nil, fmt.Errorf("failed to get sales by hour")
+	}
+
+	filled, peakHour := fillHourlySales(hours)
+	return &report.SalesByHourResponse{
+		Currency:  utils.Currency(),
+		StartDate: startDate,
+		EndDate:   endDate,
+		PeakHour:  peakHour,
+		Hours:     filled,
+	}, nil
+}
+
+// ========== 13. REVENUE BY PAYMENT METHOD ==========
+func (rs *reportService) GetRevenueByPaymentMethod(ctx context.Context, req report.RevenueByPaymentRequest) (*report.RevenueByPaymentResponse, error) {
+	// Validasi input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Parse tanggal
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+	}
+
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+	}
+
+	// Validasi range tanggal (max 1 tahun, sama dengan sales report)
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start date cannot be after end date")
+	}
+	if endDate.Sub(startDate) > 365*24*time.Hour {
+		return nil, fmt.Errorf("date range cannot exceed 1 year")
+	}
+
+	rows, err := rs.repo.Report.GetRevenueByPaymentMethod(ctx, startDate, endDate)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get revenue by payment method", zap.Error(err))
+		return nil, fmt.Errorf("failed to get revenue by payment method")
+	}
+
+	byMethod := make(map[string]report.PaymentMethodRevenue, len(rows))
+	for _, row := range rows {
+		byMethod[row.PaymentMethod] = row
+	}
+
+	// Semua cara bayar selalu muncul dengan urutan tetap
+	response := &report.RevenueByPaymentResponse{
+		Currency:  utils.Currency(),
+		StartDate: startDate,
+		EndDate:   endDate,
+		Methods:   make([]report.PaymentMethodRevenue, 0, len(model.PaymentMethods)),
+	}
+	for _, method := range model.PaymentMethods {
+		row := byMethod[string(method)]
+		row.PaymentMethod = string(method)
+		row.Revenue = utils.RoundMoney(row.Revenue)
+		response.TotalRevenue += row.Revenue
+		response.Methods = append(response.Methods, row)
+	}
+	response.TotalRevenue = utils.RoundMoney(response.TotalRevenue)
+
+	return response, nil
+}
+
+// ========== 14. TOP CUSTOMERS ==========
+func (rs *reportService) GetTopCustomers(ctx context.Context, req report.TopCustomersRequest) (*report.TopCustomersResponse, error) {
+	// Validasi input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Parse tanggal
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+	}
+
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+	}
+
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start date cannot be after end date")
+	}
+	if err := rs.checkMaxRange(startDate, endDate); err != nil {
+		return nil, err
+	}
+
+	sales, err := rs.repo.Report.FindCustomerSales(ctx, startDate, endDate)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get top customers", zap.Error(err))
+		return nil, fmt.Errorf("failed to get top customers")
+	}
+
+	return &report.TopCustomersResponse{
+		Currency:  utils.Currency(),
+		StartDate: startDate,
+		EndDate:   endDate,
+		Customers: aggregateCustomers(sales, req.Limit),
+	}, nil
+}
+
+// ========== 15. FULFILLMENT TIME ==========
+func (rs *reportService) GetFulfillmentTime(ctx context.Context, req report.FulfillmentTimeRequest) (*report.FulfillmentTimeResponse, error) {
+	// Validasi input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Parse tanggal
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+	}
+
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+	}
+
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start date cannot be after end date")
+	}
+	if err := rs.checkMaxRange(startDate, endDate); err != nil {
+		return nil, err
+	}
+
+	sales, err := rs.repo.Report.FindFulfillmentTimes(ctx, startDate, endDate)
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get fulfillment time", zap.Error(err))
+		return nil, fmt.Errorf("failed to get fulfillment time")
+	}
+
+	average, median := fulfillmentStats(sales)
+
+	// Detik dibulatkan 2 desimal
+	return &report.FulfillmentTimeResponse{
+		StartDate:      startDate,
+		EndDate:        endDate,
+		SalesCount:     len(sales),
+		AverageSeconds: roundSeconds(average),
+		MedianSeconds:  roundSeconds(median),
+	}, nil
+}
+
+// ========== 16. MONTHLY SNAPSHOT ==========
+// Bulan yang sudah selesai: pakai snapshot tersimpan, belum ada = hitung dari ledger lalu simpan.
+// Bulan berjalan: dihitung live (posisi sekarang) dan tidak disimpan
+func (rs *reportService) GetMonthlySnapshot(ctx context.Context, req report.MonthlySnapshotRequest) (*report.MonthlySnapshotResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	month, err := time.Parse("2006-01", req.Month)
+	if err != nil {
+		return nil, fmt.Errorf("invalid month format. Use YYYY-MM")
+	}
+
+	now := time.Now()
+	if month.After(now) {
+		return nil, fmt.Errorf("invalid month: cannot be in the future")
+	}
+
+	// Akhir bulan = awal bulan berikutnya (movement tepat di waktu itu dianggap bulan berikutnya)
+	monthEnd := month.AddDate(0, 1, 0)
+	response := &report.MonthlySnapshotResponse{Month: req.Month}
+
+	var products []report.SnapshotProduct
+	if !monthEnd.Before(now) {
+		response.Source = "live"
+		products, err = rs.repo.Report.ComputeStockSnapshot(ctx, now)
+	} else {
+		response.Source = "stored"
+		products, err = rs.repo.Report.FindMonthlySnapshot(ctx, month)
+		if err == nil && len(products) == 0 {
+			response.Source = "computed"
+			if err = rs.repo.Report.SaveMonthlySnapshot(ctx, month, monthEnd); err == nil {
+				products, err = rs.repo.Report.FindMonthlySnapshot(ctx, month)
+			}
+		}
+	}
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to get monthly snapshot", zap.Error(err))
+		return nil, fmt.Errorf("failed to get monthly snapshot")
+	}
+
+	response.Products = products
+	response.ProductCount = len(products)
+	for _, p := range products {
+		response.TotalStock += p.StockQuantity
+	}
+
+	return response, nil
+}
+
+// inventoryTurnover average stock = (opening + closing) / 2, turnover = terjual / average stock
+// Average stock 0 tidak bisa dibagi, turnover nil (bukan 0 atau Inf)
+func inventoryTurnover(openingStock, closingStock, unitsSold int) (float64, *float64) {
+	average := float64(openingStock+closingStock) / 2
+	if average == 0 {
+		return 0, nil
+	}
+	turnover := math.Round(float64(unitsSold)/average*100) / 100
+	return math.Round(average*100) / 100, &turnover
+}
+
+// fillTrendBuckets semua bucket dari startDate s/d endDate (urut), bucket tanpa penjualan = 0
+// Awal bucket sama dengan date_trunc di repo (minggu mulai Senin, bulan tanggal 1)
+func fillTrendBuckets(found []report.SalesTrendBucket, startDate, endDate time.Time, interval string) []report.SalesTrendBucket {
+	byPeriod := make(map[string]report.SalesTrendBucket, len(found))
+	for _, b := range found {
+		byPeriod[b.Period] = b
+	}
+
+	first, _ := periodStarts(startDate, interval)
+	last, _ := periodStarts(endDate, interval)
+
+	buckets := make([]report.SalesTrendBucket, 0)
+	for period := first; !period.After(last); period = nextPeriod(period, interval) {
+		key := period.Format("2006-01-02")
+		bucket, ok := byPeriod[key]
+		if !ok {
+			bucket = report.SalesTrendBucket{Period: key}
+		}
+		bucket.Revenue = utils.RoundMoney(bucket.Revenue)
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+// aggregateCustomers total belanja per customer, urut total_spent lalu sales_count terbesar (max limit)
+// Key customer = nomor telepon kalau ada, selain itu nama (case-insensitive), sale walk-in diabaikan
+// Nama yang ditampilkan = nama di sale terbaru untuk key tersebut
+func aggregateCustomers(sales []report.CustomerSale, limit int) []report.CustomerSpend {
+	byKey := make(map[string]*report.CustomerSpend)
+	keys := make([]string, 0)
+	for _, s := range sales {
+		var key string
+		switch {
+		case s.CustomerPhone != nil:
+			key = "phone:" + *s.CustomerPhone
+		case s.CustomerName != nil:
+			key = "name:" + strings.ToLower(*s.CustomerName)
+		default:
+			continue
+		}
+
+		customer, ok := byKey[key]
+		if !ok {
+			customer = &report.CustomerSpend{CustomerPhone: s.CustomerPhone}
+			byKey[key] = customer
+			keys = append(keys, key)
+		}
+		if customer.SalesCount == 0 || !s.CreatedAt.Before(customer.LastPurchase) {
+			customer.CustomerName = derefString(s.CustomerName)
+			customer.LastPurchase = s.CreatedAt
+		}
+		customer.SalesCount++
+		customer.TotalSpent += s.TotalAmount
+	}
+
+	customers := make([]report.CustomerSpend, 0, len(keys))
+	for _, key := range keys {
+		customer := *byKey[key]
+		customer.TotalSpent = utils.RoundMoney(customer.TotalSpent)
+		customers = append(customers, customer)
+	}
+	sort.SliceStable(customers, func(i, j int) bool {
+		if customers[i].TotalSpent != customers[j].TotalSpent {
+			return customers[i].TotalSpent > customers[j].TotalSpent
+		}
+		return customers[i].SalesCount > customers[j].SalesCount
+	})
+
+	if len(customers) > limit {
+		customers = customers[:limit]
+	}
+	return customers
+}
+
+// fillHourlySales 24 bucket jam 0-23 (urut), jam tanpa penjualan = 0
+// Peak hour = sales_count terbanyak (jam paling awal jika seri), nil jika tidak ada penjualan
+func fillHourlySales(found []report.HourlySales) ([]report.HourlySales, *int) {
+	hours := make([]report.HourlySales, 24)
+	for hour := range hours {
+		hours[hour].Hour = hour
+	}
+	for _, h := range found {
+		if h.Hour >= 0 && h.Hour < 24 {
+			hours[h.Hour].SalesCount = h.SalesCount
+			hours[h.Hour].Revenue = utils.RoundMoney(h.Revenue)
+		}
+	}
+
+	var peakHour *int
+	peakCount := 0
+	for i := range hours {
+		if hours[i].SalesCount > peakCount {
+			peakCount = hours[i].SalesCount
+			peakHour = &hours[i].Hour
+		}
+	}
+	return hours, peakHour
+}
+
+// nextPeriod awal bucket berikutnya
+func nextPeriod(start time.Time, interval string) time.Time {
+	switch interval {
+	case "day":
+		return start.AddDate(0, 0, 1)
+	case "week":
+		return start.AddDate(0, 0, 7)
+	default: // month
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// fulfillmentStats rata-rata & median durasi created_at -> completed_at (detik), nil jika tidak ada sale
+// Median jumlah genap = rata-rata dua nilai tengah (sama dengan PERCENTILE_CONT(0.5))
+func fulfillmentStats(sales []report.SaleFulfillment) (*float64, *float64) {
+	if len(sales) == 0 {
+		return nil, nil
+	}
+
+	durations := make([]float64, 0, len(sales))
+	total := 0.0
+	for _, s := range sales {
+		seconds := s.CompletedAt.Sub(s.CreatedAt).Seconds()
+		durations = append(durations, seconds)
+		total += seconds
+	}
+	sort.Float64s(durations)
+
+	average := total / float64(len(durations))
+	middle := len(durations) / 2
+	median := durations[middle]
+	if len(durations)%2 == 0 {
+		median = (durations[middle-1] + durations[middle]) / 2
+	}
+	return &average, &median
+}
+
+// roundSeconds helper: bulatkan durasi ke 2 desimal, nil tetap nil
+func roundSeconds(value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	rounded := math.Round(*value*100) / 100
+	return &rounded
+}
+
+// periodStarts awal periode berjalan & periode sebelumnya (minggu mulai Senin)
+func periodStarts(now time.Time, period string) (time.Time, time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch period {
+	case "day":
+		return today, today.AddDate(0, 0, -1)
+	case "week":
+		offset := (int(today.Weekday()) + 6) % 7 // Senin = 0
+		start := today.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, -7)
+	default: // month
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(0, -1, 0)
+	}
+}
+
+func toPeriodRevenue(r *report.SalesReportResponse) report.PeriodRevenue {
+	return report.PeriodRevenue{
+		StartDate:   r.StartDate,
+		EndDate:     r.EndDate,
+		Revenue:     utils.RoundMoney(r.TotalRevenue),
+		SalesCount:  r.TotalSales,
+		AverageSale: utils.RoundMoney(r.AverageSale),
+	}
+}
+
+// percentChange (current - previous) / previous * 100, nil jika previous 0 (tidak bisa dibagi)
+func percentChange(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	pct := math.Round((current-previous)/previous*100*100) / 100
+	return &pct
+}
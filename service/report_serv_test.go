@@ -0,0 +1,979 @@
+package service
+
+import (
+	"context"
+	"inventory-system/dto/report"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"inventory-system/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKES ==========
+
+// fakeReportRepo catat filter yang diterima GetSalesReport
+type fakeReportRepo struct {
+	repository.ReportRepo
+
+	called             bool
+	startDate, endDate time.Time
+	userID, categoryID *uuid.UUID
+
+	includeEmpty bool
+	categories   []report.CategorySales
+	turnover     []report.ProductTurnover
+	trend        []report.SalesTrendBucket
+	warehouseID  uuid.UUID
+	shelves      []report.ShelfDistribution
+	hours        []report.HourlySales
+	payments     []report.PaymentMethodRevenue
+	customers    []report.CustomerSale
+	fulfillments []report.SaleFulfillment
+
+	// saleTotals total sale completed per kasir, dipakai GetSalesReport jika diisi
+	saleTotals map[uuid.UUID][]float64
+
+	// stock hasil hitung dari ledger, snapshots tabel inventory_snapshots per bulan
+	stock      []report.SnapshotProduct
+	snapshots  map[time.Time][]report.SnapshotProduct
+	computedAt []time.Time
+	savedAt    []time.Time
+}
+
+func (f *fakeReportRepo) GetSalesReport(ctx context.Context, startDate, endDate time.Time, userID, categoryID *uuid.UUID) (*report.SalesReportResponse, error) {
+	f.called = true
+	f.startDate, f.endDate, f.userID, f.categoryID = startDate, endDate, userID, categoryID
+	if f.saleTotals == nil {
+		return &report.SalesReportResponse{TotalSales: 3, TotalRevenue: 100.005, AverageSale: 33.335}, nil
+	}
+
+	// Meniru filter user_id di query repo
+	resp := &report.SalesReportResponse{}
+	for cashier, totals := range f.saleTotals {
+		if userID != nil && cashier != *userID {
+			continue
+		}
+		for _, total := range totals {
+			resp.TotalSales++
+			resp.TotalRevenue += total
+		}
+	}
+	if resp.TotalSales > 0 {
+		resp.AverageSale = resp.TotalRevenue / float64(resp.TotalSales)
+	}
+	return resp, nil
+}
+
+func (f *fakeReportRepo) GetRevenueReport(ctx context.Context, startDate, endDate time.Time, groupBy, paymentStatus string) (*report.RevenueReportResponse, error) {
+	f.called = true
+	f.startDate, f.endDate = startDate, endDate
+	return &report.RevenueReportResponse{}, nil
+}
+
+func (f *fakeReportRepo) GetSalesByCategory(ctx context.Context, startDate, endDate time.Time, includeEmpty bool) ([]report.CategorySales, error) {
+	f.called = true
+	f.startDate, f.endDate, f.includeEmpty = startDate, endDate, includeEmpty
+	return f.categories, nil
+}
+
+func (f *fakeReportRepo) GetInventoryTurnover(ctx context.Context, startDate, endDate time.Time) ([]report.ProductTurnover, error) {
+	f.called = true
+	f.startDate, f.endDate = startDate, endDate
+	return f.turnover, nil
+}
+
+func (f *fakeReportRepo) GetSalesTimeSeries(ctx context.Context, startDate, endDate time.Time, interval string) ([]report.SalesTrendBucket, error) {
+	f.called = true
+	f.startDate, f.endDate = startDate, endDate
+	return f.trend, nil
+}
+
+func (f *fakeReportRepo) GetShelfDistribution(ctx context.Context, warehouseID uuid.UUID) ([]report.ShelfDistribution, error) {
+	f.called = true
+	f.warehouseID = warehouseID
+	return f.shelves, nil
+}
+
+func (f *fakeReportRepo) GetSalesByHour(ctx context.Context, startDate, endDate time.Time) ([]report.HourlySales, error) {
+	f.called = true
+	f.startDate, f.endDate = startDate, endDate
+	return f.hours, nil
+}
+
+func (f *fakeReportRepo) GetRevenueByPaymentMethod(ctx context.Context, startDate, endDate time.Time) ([]report.PaymentMethodRevenue, error) {
+	f.called = true
+	f.startDate, f.endDate = startDate, endDate
+	return f.payments, nil
+}
+
+func (f *fakeReportRepo) FindCustomerSales(ctx context.Context, startDate, endDate time.Time) ([]report.CustomerSale, error) {
+	f.called = true
+	f.startDate, f.endDate = startDate, endDate
+	return f.customers, nil
+}
+
+func (f *fakeReportRepo) FindFulfillmentTimes(ctx context.Context, startDate, endDate time.Time) ([]report.SaleFulfillment, error) {
+	f.called = true
+	f.startDate, f.endDate = startDate, endDate
+	return f.fulfillments, nil
+}
+
+func (f *fakeReportRepo) ComputeStockSnapshot(ctx context.Context, at time.Time) ([]report.SnapshotProduct, error) {
+	f.computedAt = append(f.computedAt, at)
+	return f.stock, nil
+}
+
+// SaveMonthlySnapshot meniru ON CONFLICT DO NOTHING: snapshot yang sudah ada tidak ditimpa
+func (f *fakeReportRepo) SaveMonthlySnapshot(ctx context.Context, month, at time.Time) error {
+	f.savedAt = append(f.savedAt, at)
+	if f.snapshots == nil {
+		f.snapshots = make(map[time.Time][]report.SnapshotProduct)
+	}
+	if _, ok := f.snapshots[month]; !ok {
+		f.snapshots[month] = f.stock
+	}
+	return nil
+}
+
+func (f *fakeReportRepo) FindMonthlySnapshot(ctx context.Context, month time.Time) ([]report.SnapshotProduct, error) {
+	return f.snapshots[month], nil
+}
+
+func (f *fakeReportRepo) GetWarehouseInventory(ctx context.Context, warehouseID uuid.UUID) (*report.ProductReportResponse, error) {
+	f.called = true
+	f.warehouseID = warehouseID
+	return &report.ProductReportResponse{TotalProducts: 2, TotalStock: 13, TotalValue: 32.005}, nil
+}
+
+// ========== SALES REPORT ==========
+
+func TestGetSalesReportFilters(t *testing.T) {
+	cashier := &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: model.RoleStaff}
+	staff := &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: model.RoleStaff}
+	admin := &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: model.RoleAdmin}
+	category := &model.Category{BaseModel: model.BaseModel{ID: uuid.New()}}
+
+	withUser := func(u *model.User) context.Context {
+		return utils.SetUserToContext(context.Background(), u)
+	}
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		req          report.SalesReportRequest
+		wantErr      string
+		wantUser     *uuid.UUID
+		wantCategory *uuid.UUID
+	}{
+		{name: "admin without filters", ctx: withUser(admin)},
+		{name: "admin filters by cashier", ctx: withUser(admin), req: report.SalesReportRequest{UserID: stringPtr(cashier.ID.String())}, wantUser: &cashier.ID},
+		{name: "admin filters by category", ctx: withUser(admin), req: report.SalesReportRequest{CategoryID: stringPtr(category.ID.String())}, wantCategory: &category.ID},
+		{name: "admin unknown cashier", ctx: withUser(admin), req: report.SalesReportRequest{UserID: stringPtr(uuid.NewString())}, wantErr: "user not found"},
+		{name: "admin unknown category", ctx: withUser(admin), req: report.SalesReportRequest{CategoryID: stringPtr(uuid.NewString())}, wantErr: "category not found"},
+		{name: "staff forced to own sales", ctx: withUser(staff), wantUser: &staff.ID},
+		{name: "staff cannot query other cashier", ctx: withUser(staff), req: report.SalesReportRequest{UserID: stringPtr(cashier.ID.String())}, wantUser: &staff.ID},
+		{name: "staff keeps category filter", ctx: withUser(staff), req: report.SalesReportRequest{CategoryID: stringPtr(category.ID.String())}, wantUser: &staff.ID, wantCategory: &category.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := &fakeReportRepo{}
+			repo := &repository.Repository{
+				Report:   reports,
+				User:     &fakeUserRepo{users: map[uuid.UUID]*model.User{cashier.ID: cashier, staff.ID: staff, admin.ID: admin}},
+				Category: &fakeCategoryRepo{categories: map[uuid.UUID]*model.Category{category.ID: category}},
+			}
+			svc := NewReportService(repo, zap.NewNop(), ReportOptions{})
+
+			req := tt.req
+			req.StartDate, req.EndDate = "2026-01-01", "2026-01-31"
+			resp, err := svc.GetSalesReport(tt.ctx, req)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if reports.called {
+					t.Error("repository called on error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !equalUUID(reports.userID, tt.wantUser) {
+				t.Errorf("user filter = %v, want %v", reports.userID, tt.wantUser)
+			}
+			if !equalUUID(reports.categoryID, tt.wantCategory) {
+				t.Errorf("category filter = %v, want %v", reports.categoryID, tt.wantCategory)
+			}
+			if resp.TotalRevenue != 100.01 || resp.AverageSale != 33.34 || resp.Currency != utils.Currency() {
+				t.Errorf("response = %+v, want rounded totals with currency", resp)
+			}
+		})
+	}
+}
+
+// TestReportDateRange batas REPORT_MAX_RANGE_DAYS sama untuk sales & revenue report (end_date inclusive)
+func TestReportDateRange(t *testing.T) {
+	reports := map[string]func(svc ReportService, startDate, endDate string) error{
+		"sales": func(svc ReportService, startDate, endDate string) error {
+			_, err := svc.GetSalesReport(context.Background(), report.SalesReportRequest{StartDate: startDate, EndDate: endDate})
+			return err
+		},
+		"revenue": func(svc ReportService, startDate, endDate string) error {
+			_, err := svc.GetRevenueReport(context.Background(), report.RevenueReportRequest{StartDate: startDate, EndDate: endDate})
+			return err
+		},
+	}
+
+	tests := []struct {
+		name      string
+		maxDays   int
+		startDate string
+		endDate   string
+		wantErr   string
+	}{
+		{name: "single day", startDate: "2026-01-01", endDate: "2026-01-01"},
+		{name: "exactly default max", startDate: "2026-01-01", endDate: "2026-12-31"},
+		{name: "default max plus one", startDate: "2026-01-01", endDate: "2027-01-01", wantErr: "date range cannot exceed 365 days"},
+		{name: "exactly configured max", maxDays: 7, startDate: "2026-01-01", endDate: "2026-01-07"},
+		{name: "configured max plus one", maxDays: 7, startDate: "2026-01-01", endDate: "2026-01-08", wantErr: "date range cannot exceed 7 days"},
+		{name: "start after end", startDate: "2026-02-01", endDate: "2026-01-01", wantErr: "start date cannot be after end date"},
+		{name: "bad format", startDate: "2026/01/01", endDate: "2026-01-02", wantErr: "validation failed"},
+	}
+
+	for reportName, run := range reports {
+		for _, tt := range tests {
+			t.Run(reportName+"/"+tt.name, func(t *testing.T) {
+				repo := &fakeReportRepo{}
+				svc := NewReportService(&repository.Repository{Report: repo}, zap.NewNop(), ReportOptions{MaxRangeDays: tt.maxDays})
+
+				err := run(svc, tt.startDate, tt.endDate)
+				if tt.wantErr == "" {
+					if err != nil || !repo.called {
+						t.Errorf("error = %v, repo called = %v, want report generated", err, repo.called)
+					}
+					return
+				}
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				if repo.called {
+					t.Error("repo queried for rejected range")
+				}
+			})
+		}
+	}
+}
+
+// ========== MY SALES STATS ==========
+
+func TestGetMySalesStatsOnlyCaller(t *testing.T) {
+	me, other := uuid.New(), uuid.New()
+	reports := &fakeReportRepo{saleTotals: map[uuid.UUID][]float64{
+		me:    {10, 20},
+		other: {500, 700, 900},
+	}}
+	svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+	resp, err := svc.GetMySalesStats(context.Background(), me, report.MySalesStatsRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reports.userID == nil || *reports.userID != me || resp.UserID != me.String() {
+		t.Fatalf("user filter = %v, response user %s, want %s", reports.userID, resp.UserID, me)
+	}
+	if resp.TotalSales != 2 || resp.TotalRevenue != 30 || resp.AverageSale != 15 {
+		t.Errorf("sales/revenue/average = %d/%v/%v, want 2/30/15 (other cashier excluded)", resp.TotalSales, resp.TotalRevenue, resp.AverageSale)
+	}
+
+	// Default range: awal bulan berjalan s/d hari ini
+	now := time.Now()
+	if reports.startDate.Day() != 1 || reports.startDate.Month() != now.Month() || reports.endDate.Day() != now.Day() {
+		t.Errorf("default range = %s..%s, want current month to date", reports.startDate, reports.endDate)
+	}
+}
+
+// ========== SALES BY CATEGORY ==========
+
+func TestGetSalesByCategory(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          report.SalesByCategoryRequest
+		wantErr      string
+		wantEmpty    bool
+		wantRevenues []float64
+	}{
+		{
+			name:         "revenue rounded",
+			req:          report.SalesByCategoryRequest{StartDate: "2026-01-01", EndDate: "2026-01-31"},
+			wantRevenues: []float64{70.01, 0},
+		},
+		{
+			name:         "include empty passed to repository",
+			req:          report.SalesByCategoryRequest{StartDate: "2026-01-01", EndDate: "2026-01-31", IncludeEmpty: true},
+			wantEmpty:    true,
+			wantRevenues: []float64{70.01, 0},
+		},
+		{name: "start after end", req: report.SalesByCategoryRequest{StartDate: "2026-02-01", EndDate: "2026-01-01"}, wantErr: "start date cannot be after end date"},
+		{name: "range over a year", req: report.SalesByCategoryRequest{StartDate: "2025-01-01", EndDate: "2026-01-02"}, wantErr: "date range cannot exceed 1 year"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := &fakeReportRepo{categories: []report.CategorySales{
+				{CategoryID: "c-1", CategoryName: "Drinks", SalesCount: 2, Revenue: 70.005},
+				{CategoryID: "c-2", CategoryName: "Frozen"},
+			}}
+			svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+			resp, err := svc.GetSalesByCategory(context.Background(), tt.req)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if reports.called {
+					t.Error("repository called on error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if reports.includeEmpty != tt.wantEmpty {
+				t.Errorf("include_empty = %v, want %v", reports.includeEmpty, tt.wantEmpty)
+			}
+			if len(resp.Categories) != len(tt.wantRevenues) {
+				t.Fatalf("categories = %d, want %d", len(resp.Categories), len(tt.wantRevenues))
+			}
+			for i, want := range tt.wantRevenues {
+				if resp.Categories[i].Revenue != want {
+					t.Errorf("categories[%d].revenue = %v, want %v", i, resp.Categories[i].Revenue, want)
+				}
+			}
+		})
+	}
+}
+
+// ========== INVENTORY TURNOVER ==========
+
+func TestGetInventoryTurnover(t *testing.T) {
+	reports := &fakeReportRepo{turnover: []report.ProductTurnover{
+		// Urutan dari repo: nama
+		{ProductName: "Beans", UnitsSold: 0, OpeningStock: 0, ClosingStock: 0},
+		{ProductName: "Coffee", UnitsSold: 30, OpeningStock: 50, ClosingStock: 20},
+		{ProductName: "Tea", UnitsSold: 10, OpeningStock: 15, ClosingStock: 5},
+	}}
+	svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+	resp, err := svc.GetInventoryTurnover(context.Background(), report.InventoryTurnoverRequest{StartDate: "2026-01-01", EndDate: "2026-01-31"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Tea 10/10 = 1, Coffee 30/35 = 0.86, Beans tanpa stok = nil di akhir
+	want := []struct {
+		name     string
+		average  float64
+		turnover *float64
+	}{
+		{name: "Tea", average: 10, turnover: floatPtr(1)},
+		{name: "Coffee", average: 35, turnover: floatPtr(0.86)},
+		{name: "Beans", average: 0},
+	}
+	if len(resp.Products) != len(want) {
+		t.Fatalf("products = %d, want %d", len(resp.Products), len(want))
+	}
+	for i, w := range want {
+		got := resp.Products[i]
+		if got.ProductName != w.name || got.AverageStock != w.average {
+			t.Errorf("products[%d] = %s/%v, want %s/%v", i, got.ProductName, got.AverageStock, w.name, w.average)
+		}
+		if (got.Turnover == nil) != (w.turnover == nil) || (got.Turnover != nil && *got.Turnover != *w.turnover) {
+			t.Errorf("products[%d].turnover = %v, want %v", i, got.Turnover, w.turnover)
+		}
+	}
+
+	reports.called = false
+	if _, err := svc.GetInventoryTurnover(context.Background(), report.InventoryTurnoverRequest{StartDate: "2026-02-01", EndDate: "2026-01-01"}); err == nil || reports.called {
+		t.Errorf("start after end: error = %v, repo called = %v", err, reports.called)
+	}
+}
+
+// ========== SALES TREND ==========
+
+func TestGetSalesTrendZeroFill(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         report.SalesTrendRequest
+		found       []report.SalesTrendBucket
+		wantPeriods []string
+		wantCounts  []int
+	}{
+		{
+			name:        "missing days filled",
+			req:         report.SalesTrendRequest{StartDate: "2026-01-01", EndDate: "2026-01-05", Interval: "day"},
+			found:       []report.SalesTrendBucket{{Period: "2026-01-02", SalesCount: 2, Revenue: 30.004}, {Period: "2026-01-04", SalesCount: 1, Revenue: 5}},
+			wantPeriods: []string{"2026-01-01", "2026-01-02", "2026-01-03", "2026-01-04", "2026-01-05"},
+			wantCounts:  []int{0, 2, 0, 1, 0},
+		},
+		{
+			name:        "weeks start on monday",
+			req:         report.SalesTrendRequest{StartDate: "2026-01-01", EndDate: "2026-01-20", Interval: "week"},
+			found:       []report.SalesTrendBucket{{Period: "2026-01-12", SalesCount: 4, Revenue: 40}},
+			wantPeriods: []string{"2025-12-29", "2026-01-05", "2026-01-12", "2026-01-19"},
+			wantCounts:  []int{0, 0, 4, 0},
+		},
+		{
+			name:        "no sales at all",
+			req:         report.SalesTrendRequest{StartDate: "2026-01-15", EndDate: "2026-04-02", Interval: "month"},
+			wantPeriods: []string{"2026-01-01", "2026-02-01", "2026-03-01", "2026-04-01"},
+			wantCounts:  []int{0, 0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := &fakeReportRepo{trend: tt.found}
+			svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+			resp, err := svc.GetSalesTrend(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(resp.Buckets) != len(tt.wantPeriods) {
+				t.Fatalf("buckets = %+v, want periods %v", resp.Buckets, tt.wantPeriods)
+			}
+			for i, b := range resp.Buckets {
+				if b.Period != tt.wantPeriods[i] || b.SalesCount != tt.wantCounts[i] {
+					t.Errorf("buckets[%d] = %s/%d, want %s/%d", i, b.Period, b.SalesCount, tt.wantPeriods[i], tt.wantCounts[i])
+				}
+				if tt.wantCounts[i] == 0 && b.Revenue != 0 {
+					t.Errorf("buckets[%d].revenue = %v, want 0", i, b.Revenue)
+				}
+			}
+		})
+	}
+}
+
+// ========== WAREHOUSE INVENTORY ==========
+
+func TestGetWarehouseInventory(t *testing.T) {
+	north := &model.Warehouse{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "North"}
+	south := &model.Warehouse{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "South"}
+
+	reports := &fakeReportRepo{}
+	repo := &repository.Repository{
+		Report:    reports,
+		Warehouse: &fakeWarehouseRepo{warehouses: map[uuid.UUID]*model.Warehouse{north.ID: north, south.ID: south}},
+	}
+	svc := NewReportService(repo, zap.NewNop(), ReportOptions{})
+
+	resp, err := svc.GetWarehouseInventory(context.Background(), south.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reports.warehouseID != south.ID || resp.WarehouseID != south.ID.String() || resp.WarehouseName != "South" {
+		t.Errorf("scoped to %s, response %s/%s, want South", reports.warehouseID, resp.WarehouseID, resp.WarehouseName)
+	}
+	if resp.TotalValue != 32.01 || resp.TotalStock != 13 {
+		t.Errorf("value/stock = %v/%d, want 32.01/13", resp.TotalValue, resp.TotalStock)
+	}
+
+	reports.called = false
+	if _, err := svc.GetWarehouseInventory(context.Background(), uuid.New()); err == nil || err.Error() != "warehouse not found" || reports.called {
+		t.Errorf("unknown warehouse: error = %v, repo called = %v", err, reports.called)
+	}
+}
+
+// ========== SHELF DISTRIBUTION ==========
+
+func TestGetShelfDistribution(t *testing.T) {
+	north := &model.Warehouse{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "North"}
+
+	reports := &fakeReportRepo{shelves: []report.ShelfDistribution{
+		{ShelfCode: "A-01", ProductCount: 2, TotalStock: 15},
+		{ShelfCode: "A-02"},
+		{ShelfCode: "A-03", ProductCount: 1},
+	}}
+	repo := &repository.Repository{
+		Report:    reports,
+		Warehouse: &fakeWarehouseRepo{warehouses: map[uuid.UUID]*model.Warehouse{north.ID: north}},
+	}
+	svc := NewReportService(repo, zap.NewNop(), ReportOptions{})
+
+	resp, err := svc.GetShelfDistribution(context.Background(), north.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reports.warehouseID != north.ID || resp.WarehouseName != "North" {
+		t.Errorf("scoped to %s/%s, want North", reports.warehouseID, resp.WarehouseName)
+	}
+	// Rak kosong ikut dihitung di total, hanya yang tanpa produk dihitung kosong
+	if resp.TotalShelves != 3 || resp.EmptyShelves != 1 || len(resp.Shelves) != 3 {
+		t.Errorf("total/empty/shelves = %d/%d/%d, want 3/1/3", resp.TotalShelves, resp.EmptyShelves, len(resp.Shelves))
+	}
+
+	reports.called = false
+	if _, err := svc.GetShelfDistribution(context.Background(), uuid.New()); err == nil || err.Error() != "warehouse not found" || reports.called {
+		t.Errorf("unknown warehouse: error = %v, repo called = %v", err, reports.called)
+	}
+}
+
+// ========== SALES BY HOUR ==========
+
+func TestGetSalesByHour(t *testing.T) {
+	tests := []struct {
+		name        string
+		found       []report.HourlySales
+		wantCounts  map[int]int
+		wantRevenue map[int]float64
+		wantPeak    *int
+	}{
+		{
+			name:        "partial hours zero-filled",
+			found:       []report.HourlySales{{Hour: 0, SalesCount: 1, Revenue: 5}, {Hour: 9, SalesCount: 4, Revenue: 80.005}, {Hour: 17, SalesCount: 2, Revenue: 30}},
+			wantCounts:  map[int]int{0: 1, 9: 4, 17: 2},
+			wantRevenue: map[int]float64{0: 5, 9: 80.01, 17: 30},
+			wantPeak:    intPtr(9),
+		},
+		{
+			name:        "tie keeps earliest hour",
+			found:       []report.HourlySales{{Hour: 14, SalesCount: 3, Revenue: 10}, {Hour: 23, SalesCount: 3, Revenue: 99}},
+			wantCounts:  map[int]int{14: 3, 23: 3},
+			wantRevenue: map[int]float64{14: 10, 23: 99},
+			wantPeak:    intPtr(14),
+		},
+		{name: "no sales"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := &fakeReportRepo{hours: tt.found}
+			svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+			resp, err := svc.GetSalesByHour(context.Background(), report.SalesByHourRequest{StartDate: "2024-03-01", EndDate: "2024-03-31"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(resp.Hours) != 24 {
+				t.Fatalf("hours = %d, want 24", len(resp.Hours))
+			}
+			for i, h := range resp.Hours {
+				if h.Hour != i || h.SalesCount != tt.wantCounts[i] || h.Revenue != tt.wantRevenue[i] {
+					t.Errorf("hours[%d] = %+v, want hour %d with %d/%v", i, h, i, tt.wantCounts[i], tt.wantRevenue[i])
+				}
+			}
+			if (resp.PeakHour == nil) != (tt.wantPeak == nil) || (resp.PeakHour != nil && *resp.PeakHour != *tt.wantPeak) {
+				t.Errorf("peak hour = %v, want %v", resp.PeakHour, tt.wantPeak)
+			}
+		})
+	}
+
+	t.Run("invalid range", func(t *testing.T) {
+		reports := &fakeReportRepo{}
+		svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+		_, err := svc.GetSalesByHour(context.Background(), report.SalesByHourRequest{StartDate: "2024-03-31", EndDate: "2024-03-01"})
+		if err == nil || err.Error() != "start date cannot be after end date" || reports.called {
+			t.Errorf("error = %v, repo called = %v", err, reports.called)
+		}
+	})
+}
+
+// ========== REVENUE BY PAYMENT METHOD ==========
+
+func TestGetRevenueByPaymentMethod(t *testing.T) {
+	tests := []struct {
+		name      string
+		found     []report.PaymentMethodRevenue
+		want      []report.PaymentMethodRevenue
+		wantTotal float64
+	}{
+		{
+			name:      "missing methods zero-filled in fixed order",
+			found:     []report.PaymentMethodRevenue{{PaymentMethod: "transfer", SalesCount: 2, Revenue: 150.005}, {PaymentMethod: "cash", SalesCount: 3, Revenue: 45.5}},
+			want:      []report.PaymentMethodRevenue{{PaymentMethod: "cash", SalesCount: 3, Revenue: 45.5}, {PaymentMethod: "card"}, {PaymentMethod: "transfer", SalesCount: 2, Revenue: 150.01}},
+			wantTotal: 195.51,
+		},
+		{
+			name:      "no sales",
+			want:      []report.PaymentMethodRevenue{{PaymentMethod: "cash"}, {PaymentMethod: "card"}, {PaymentMethod: "transfer"}},
+			wantTotal: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := &fakeReportRepo{payments: tt.found}
+			svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+			resp, err := svc.GetRevenueByPaymentMethod(context.Background(), report.RevenueByPaymentRequest{StartDate: "2024-03-01", EndDate: "2024-03-31"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(resp.Methods) != len(tt.want) {
+				t.Fatalf("methods = %+v, want %d", resp.Methods, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if resp.Methods[i] != want {
+					t.Errorf("methods[%d] = %+v, want %+v", i, resp.Methods[i], want)
+				}
+			}
+			if resp.TotalRevenue != tt.wantTotal {
+				t.Errorf("total = %v, want %v", resp.TotalRevenue, tt.wantTotal)
+			}
+		})
+	}
+
+	t.Run("range over a year", func(t *testing.T) {
+		reports := &fakeReportRepo{}
+		svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+		_, err := svc.GetRevenueByPaymentMethod(context.Background(), report.RevenueByPaymentRequest{StartDate: "2023-01-01", EndDate: "2024-01-02"})
+		if err == nil || err.Error() != "date range cannot exceed 1 year" || reports.called {
+			t.Errorf("error = %v, repo called = %v", err, reports.called)
+		}
+	})
+}
+
+// ========== TOP CUSTOMERS ==========
+
+func TestGetTopCustomers(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 10, 0, 0, 0, time.UTC) }
+	sale := func(name, phone string, amount float64, at time.Time) report.CustomerSale {
+		s := report.CustomerSale{TotalAmount: amount, CreatedAt: at}
+		if name != "" {
+			s.CustomerName = &name
+		}
+		if phone != "" {
+			s.CustomerPhone = &phone
+		}
+		return s
+	}
+
+	reports := &fakeReportRepo{customers: []report.CustomerSale{
+		// Telepon sama, nama beda: satu customer, nama dari sale terbaru
+		sale("Budi", "0811", 100, day(1)),
+		sale("Budi Santoso", "0811", 50.005, day(5)),
+		// Tanpa telepon: dikelompokkan per nama tanpa beda huruf besar/kecil
+		sale("Siti", "", 40, day(2)),
+		sale("SITI", "", 30, day(3)),
+		// Nama sama dengan customer bertelepon tapi tanpa telepon: customer lain
+		sale("Budi", "", 20, day(4)),
+		// Hanya telepon
+		sale("", "0822", 70, day(6)),
+		// Walk-in diabaikan
+		sale("", "", 999, day(7)),
+	}}
+	svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+	resp, err := svc.GetTopCustomers(context.Background(), report.TopCustomersRequest{StartDate: "2024-03-01", EndDate: "2024-03-31", Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type customer struct {
+		name  string
+		phone string
+		count int
+		spent float64
+		last  time.Time
+	}
+	// Siti & 0822 sama-sama 70: dua sale lebih dulu
+	want := []customer{
+		{name: "Budi Santoso", phone: "0811", count: 2, spent: 150.01, last: day(5)},
+		{name: "SITI", count: 2, spent: 70, last: day(3)},
+		{name: "", phone: "0822", count: 1, spent: 70, last: day(6)},
+		{name: "Budi", count: 1, spent: 20, last: day(4)},
+	}
+	if len(resp.Customers) != len(want) {
+		t.Fatalf("customers = %+v, want %d", resp.Customers, len(want))
+	}
+	for i, w := range want {
+		got := resp.Customers[i]
+		if got.CustomerName != w.name || derefString(got.CustomerPhone) != w.phone || got.SalesCount != w.count || got.TotalSpent != w.spent || !got.LastPurchase.Equal(w.last) {
+			t.Errorf("customers[%d] = %+v (phone %q), want %+v", i, got, derefString(got.CustomerPhone), w)
+		}
+	}
+
+	t.Run("limit", func(t *testing.T) {
+		resp, err := svc.GetTopCustomers(context.Background(), report.TopCustomersRequest{StartDate: "2024-03-01", EndDate: "2024-03-31", Limit: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Customers) != 2 || resp.Customers[0].CustomerName != "Budi Santoso" || resp.Customers[1].CustomerName != "SITI" {
+			t.Errorf("customers = %+v, want top 2", resp.Customers)
+		}
+	})
+
+	t.Run("only walk-in sales", func(t *testing.T) {
+		reports := &fakeReportRepo{customers: []report.CustomerSale{sale("", "", 10, day(1))}}
+		svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+		resp, err := svc.GetTopCustomers(context.Background(), report.TopCustomersRequest{StartDate: "2024-03-01", EndDate: "2024-03-31", Limit: 10})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Customers == nil || len(resp.Customers) != 0 {
+			t.Errorf("customers = %#v, want empty list", resp.Customers)
+		}
+	})
+}
+
+// ========== FULFILLMENT TIME ==========
+
+func TestGetFulfillmentTime(t *testing.T) {
+	created := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	after := func(d time.Duration) report.SaleFulfillment {
+		return report.SaleFulfillment{CreatedAt: created, CompletedAt: created.Add(d)}
+	}
+
+	tests := []struct {
+		name        string
+		sales       []report.SaleFulfillment
+		wantAverage *float64
+		wantMedian  *float64
+	}{
+		{
+			name:        "odd count",
+			sales:       []report.SaleFulfillment{after(10 * time.Minute), after(time.Minute), after(2 * time.Minute)},
+			wantAverage: floatPtr(260),
+			wantMedian:  floatPtr(120),
+		},
+		{
+			name:        "even count median between middle values",
+			sales:       []report.SaleFulfillment{after(1000 * time.Second), after(time.Minute), after(10 * time.Minute), after(2 * time.Minute)},
+			wantAverage: floatPtr(445),
+			wantMedian:  floatPtr(360),
+		},
+		{
+			name:        "rounded to two decimals",
+			sales:       []report.SaleFulfillment{after(time.Second), after(2 * time.Second), after(2 * time.Second)},
+			wantAverage: floatPtr(1.67),
+			wantMedian:  floatPtr(2),
+		},
+		{
+			name:        "across days",
+			sales:       []report.SaleFulfillment{after(26 * time.Hour)},
+			wantAverage: floatPtr(93600),
+			wantMedian:  floatPtr(93600),
+		},
+		{name: "no completed sales"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := &fakeReportRepo{fulfillments: tt.sales}
+			svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+			resp, err := svc.GetFulfillmentTime(context.Background(), report.FulfillmentTimeRequest{StartDate: "2024-03-01", EndDate: "2024-03-31"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.SalesCount != len(tt.sales) {
+				t.Errorf("sales count = %d, want %d", resp.SalesCount, len(tt.sales))
+			}
+			if !equalFloat(resp.AverageSeconds, tt.wantAverage) || !equalFloat(resp.MedianSeconds, tt.wantMedian) {
+				t.Errorf("average/median = %v/%v, want %v/%v", derefFloat(resp.AverageSeconds), derefFloat(resp.MedianSeconds), derefFloat(tt.wantAverage), derefFloat(tt.wantMedian))
+			}
+		})
+	}
+}
+
+// ========== MONTHLY SNAPSHOT ==========
+
+func TestGetMonthlySnapshot(t *testing.T) {
+	january := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ledger := []report.SnapshotProduct{
+		{ProductID: "p1", ProductName: "Coffee", StockQuantity: 12},
+		{ProductID: "p2", ProductName: "Tea", StockQuantity: 3},
+	}
+
+	t.Run("past month without rows is computed and saved", func(t *testing.T) {
+		reports := &fakeReportRepo{stock: ledger}
+		svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+		resp, err := svc.GetMonthlySnapshot(context.Background(), report.MonthlySnapshotRequest{Month: "2024-01"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Source != "computed" || resp.ProductCount != 2 || resp.TotalStock != 15 {
+			t.Errorf("source/count/total = %s/%d/%d, want computed/2/15", resp.Source, resp.ProductCount, resp.TotalStock)
+		}
+		// Stok dihitung pada awal bulan berikutnya dan disimpan di bawah bulan yang diminta
+		if len(reports.savedAt) != 1 || !reports.savedAt[0].Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("saved at = %v, want [2024-02-01]", reports.savedAt)
+		}
+		if len(reports.snapshots[january]) != 2 {
+			t.Errorf("stored rows = %v, want 2 rows for 2024-01", reports.snapshots)
+		}
+		if len(reports.computedAt) != 0 {
+			t.Error("past month computed live")
+		}
+	})
+
+	t.Run("past month with rows uses stored rows", func(t *testing.T) {
+		stored := []report.SnapshotProduct{{ProductID: "p1", ProductName: "Coffee", StockQuantity: 7}}
+		reports := &fakeReportRepo{stock: ledger, snapshots: map[time.Time][]report.SnapshotProduct{january: stored}}
+		svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+		resp, err := svc.GetMonthlySnapshot(context.Background(), report.MonthlySnapshotRequest{Month: "2024-01"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Source != "stored" || resp.ProductCount != 1 || resp.TotalStock != 7 {
+			t.Errorf("source/count/total = %s/%d/%d, want stored/1/7", resp.Source, resp.ProductCount, resp.TotalStock)
+		}
+		if len(reports.savedAt) != 0 || len(reports.computedAt) != 0 {
+			t.Errorf("saved/computed = %d/%d, want stored rows only", len(reports.savedAt), len(reports.computedAt))
+		}
+	})
+
+	t.Run("current month is live and not saved", func(t *testing.T) {
+		reports := &fakeReportRepo{stock: ledger}
+		svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+		before := time.Now()
+		resp, err := svc.GetMonthlySnapshot(context.Background(), report.MonthlySnapshotRequest{Month: before.Format("2006-01")})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Source != "live" || resp.ProductCount != 2 || resp.TotalStock != 15 {
+			t.Errorf("source/count/total = %s/%d/%d, want live/2/15", resp.Source, resp.ProductCount, resp.TotalStock)
+		}
+		// Posisi sekarang, bukan akhir bulan
+		if len(reports.computedAt) != 1 || reports.computedAt[0].Before(before) || reports.computedAt[0].After(time.Now()) {
+			t.Errorf("computed at = %v, want now", reports.computedAt)
+		}
+		if len(reports.savedAt) != 0 || len(reports.snapshots) != 0 {
+			t.Error("current month snapshot saved")
+		}
+	})
+
+	for _, month := range []string{"2024-13", "01-2024", time.Now().AddDate(0, 2, 0).Format("2006-01")} {
+		t.Run("invalid "+month, func(t *testing.T) {
+			reports := &fakeReportRepo{stock: ledger}
+			svc := NewReportService(&repository.Repository{Report: reports}, zap.NewNop(), ReportOptions{})
+
+			if _, err := svc.GetMonthlySnapshot(context.Background(), report.MonthlySnapshotRequest{Month: month}); err == nil {
+				t.Fatal("expected error")
+			}
+			if len(reports.savedAt) != 0 || len(reports.computedAt) != 0 {
+				t.Error("repository called on invalid month")
+			}
+		})
+	}
+}
+
+// ========== HELPERS ==========
+
+func TestInventoryTurnover(t *testing.T) {
+	tests := []struct {
+		name                   string
+		opening, closing, sold int
+		wantAverage            float64
+		wantTurnover           *float64
+	}{
+		{name: "sold down", opening: 50, closing: 20, sold: 30, wantAverage: 35, wantTurnover: floatPtr(0.86)},
+		{name: "nothing sold", opening: 8, closing: 8, sold: 0, wantAverage: 8, wantTurnover: floatPtr(0)},
+		{name: "odd average", opening: 3, closing: 0, sold: 3, wantAverage: 1.5, wantTurnover: floatPtr(2)},
+		{name: "zero average stock", opening: 0, closing: 0, sold: 0, wantAverage: 0},
+	}
+
+	for _, tt := range tests {
+		average, turnover := inventoryTurnover(tt.opening, tt.closing, tt.sold)
+		if average != tt.wantAverage {
+			t.Errorf("%s: average = %v, want %v", tt.name, average, tt.wantAverage)
+		}
+		if (turnover == nil) != (tt.wantTurnover == nil) || (turnover != nil && *turnover != *tt.wantTurnover) {
+			t.Errorf("%s: turnover = %v, want %v", tt.name, turnover, tt.wantTurnover)
+		}
+	}
+}
+
+func TestPeriodStarts(t *testing.T) {
+	// Kamis 15 Jan 2026
+	now := time.Date(2026, 1, 15, 14, 30, 0, 0, time.UTC)
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		period       string
+		wantCurrent  time.Time
+		wantPrevious time.Time
+	}{
+		{period: "day", wantCurrent: date(2026, 1, 15), wantPrevious: date(2026, 1, 14)},
+		{period: "week", wantCurrent: date(2026, 1, 12), wantPrevious: date(2026, 1, 5)},
+		{period: "month", wantCurrent: date(2026, 1, 1), wantPrevious: date(2025, 12, 1)},
+	}
+
+	for _, tt := range tests {
+		current, previous := periodStarts(now, tt.period)
+		if !current.Equal(tt.wantCurrent) || !previous.Equal(tt.wantPrevious) {
+			t.Errorf("periodStarts(%s) = %v, %v, want %v, %v", tt.period, current, previous, tt.wantCurrent, tt.wantPrevious)
+		}
+	}
+
+	// Minggu: masih minggu yang dimulai Senin sebelumnya
+	current, _ := periodStarts(date(2026, 1, 18), "week")
+	if !current.Equal(date(2026, 1, 12)) {
+		t.Errorf("periodStarts(sunday, week) = %v, want 2026-01-12", current)
+	}
+}
+
+func TestPercentChange(t *testing.T) {
+	tests := []struct {
+		current, previous float64
+		want              *float64
+	}{
+		{current: 150, previous: 100, want: floatPtr(50)},
+		{current: 50, previous: 100, want: floatPtr(-50)},
+		{current: 1, previous: 3, want: floatPtr(-66.67)},
+		{current: 10, previous: 0, want: nil},
+	}
+
+	for _, tt := range tests {
+		got := percentChange(tt.current, tt.previous)
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("percentChange(%v, %v) = %v, want %v", tt.current, tt.previous, got, tt.want)
+		}
+	}
+}
+
+func TestRoundSeconds(t *testing.T) {
+	if got := roundSeconds(nil); got != nil {
+		t.Errorf("roundSeconds(nil) = %v, want nil", *got)
+	}
+	if got := roundSeconds(floatPtr(12.3456)); *got != 12.35 {
+		t.Errorf("roundSeconds(12.3456) = %v, want 12.35", *got)
+	}
+}
+
+// equalUUID bandingkan filter opsional (nil = tanpa filter)
+func equalUUID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func equalFloat(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func derefFloat(value *float64) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
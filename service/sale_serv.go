@@ -7,6 +7,8 @@ import (
 	"inventory-system/model"
 	"inventory-system/repository"
 	"inventory-system/utils"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,23 +18,55 @@ import (
 // SaleService defines business logic for sales
 type SaleService interface {
 	CreateSale(ctx context.Context, req sale.CreateSaleRequest, userID uuid.UUID) (*sale.SaleResponse, error)
+	DryRun(ctx context.Context, req sale.CreateSaleRequest) (*sale.SaleDryRunResponse, error)
 	GetSaleByID(ctx context.Context, id uuid.UUID) (*sale.SaleResponse, error)
-	GetAllSales(ctx context.Context, userID *uuid.UUID, page, limit int) ([]sale.SaleResponse, utils.Pagination, error)
+	GetSaleByInvoice(ctx context.Context, invoiceNumber string) (*sale.SaleResponse, error)
+	Reorder(ctx context.Context, sourceID uuid.UUID, userID uuid.UUID) (*sale.SaleResponse, error)
+	ExportSale(ctx context.Context, id uuid.UUID) (*sale.SaleExportResponse, error)
+	StreamFullExport(ctx context.Context, req sale.SaleFullExportRequest, fn func(export *sale.SaleExportResponse) error) error
+	GetAllSales(ctx context.Context, userID *uuid.UUID, req sale.SaleListRequest, page, limit int) ([]sale.SaleResponse, utils.Pagination, error)
+	CountSales(ctx context.Context, userID *uuid.UUID, req sale.SaleListRequest) (*sale.SaleCountResponse, error)
 	UpdateSaleStatus(ctx context.Context, id uuid.UUID, req sale.UpdateSaleStatusRequest) (*sale.SaleResponse, error)
+	GetProductSalesHistory(ctx context.Context, productID uuid.UUID, req sale.ProductSalesHistoryRequest, page, limit int) ([]sale.ProductSaleHistoryResponse, utils.Pagination, error)
+	GetSaleItemLedger(ctx context.Context, req sale.SaleItemLedgerRequest, page, limit int) ([]sale.SaleItemLedgerResponse, utils.Pagination, error)
+	RecalculateTotal(ctx context.Context, id uuid.UUID) (*sale.RecalculateSaleResponse, error)
+	ReassignUser(ctx context.Context, id uuid.UUID, req sale.ReassignSaleRequest, actorID uuid.UUID) (*sale.ReassignSaleResponse, error)
+	UpdatePayment(ctx context.Context, id uuid.UUID, req sale.UpdateSalePaymentRequest) (*sale.SaleResponse, error)
+	RecalculateAllTotals(ctx context.Context) (*sale.RecalculateAllSalesResponse, error)
+	GetMyDailySales(ctx context.Context, userID uuid.UUID, req sale.MyDailySalesRequest) (*sale.MyDailySalesResponse, error)
+	GetStatusBreakdown(ctx context.Context, req sale.SaleStatusBreakdownRequest) (*sale.SaleStatusBreakdownResponse, error)
 }
 
 type saleService struct {
-	repo *repository.Repository
-	log  *zap.Logger
+	repo     *repository.Repository
+	log      *zap.Logger
+	notifier Notifier
+	opts     SaleOptions
 }
 
+// SaleOptions - pengaturan sale service dari config
+type SaleOptions struct {
+	MaxItems int // batas jumlah item per sale, 0 = fallback defaultMaxSaleItems
+}
+
+// Fallback batas item per sale (cegah batch insert raksasa)
+const defaultMaxSaleItems = 500
+
 // NewSaleService creates new sale service instance
-func NewSaleService(repo *repository.Repository, log *zap.Logger) SaleService {
-	return &saleService{repo: repo, log: log}
+func NewSaleService(repo *repository.Repository, log *zap.Logger, notifier Notifier, opts SaleOptions) SaleService {
+	if opts.MaxItems <= 0 {
+		opts.MaxItems = defaultMaxSaleItems
+	}
+	return &saleService{repo: repo, log: log, notifier: notifier, opts: opts}
 }
 
 // CreateSale processes new sale transaction
 func (ss *saleService) CreateSale(ctx context.Context, req sale.CreateSaleRequest, userID uuid.UUID) (*sale.SaleResponse, error) {
+	// Batas jumlah item dicek duluan, sebelum validasi per item & query stok
+	if len(req.Items) > ss.opts.MaxItems {
+		return nil, fmt.Errorf("too many items: sale cannot have more than %d items", ss.opts.MaxItems)
+	}
+
 	// Validate request structure
 	if err := utils.ValidateStruct(req); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
@@ -47,6 +81,16 @@ func (ss *saleService) CreateSale(ctx context.Context, req sale.CreateSaleReques
 	var totalAmount float64 = 0
 	var saleItems []model.SaleItem
 
+	// Produk yang sama tidak boleh muncul dua kali (cek stok per item jadi tidak akurat)
+	// Ditolak, bukan digabung: client harus kirim satu item dengan total quantity
+	seenProducts := make(map[uuid.UUID]bool, len(req.Items))
+
+	// Item dengan shelf_id: stok diambil dari rak tersebut (multi warehouse)
+	itemShelves := make(map[uuid.UUID]uuid.UUID)
+
+	// Produk hasil CheckStock, dipakai untuk alert low stock setelah stok dipotong
+	products := make(map[uuid.UUID]*model.Product, len(req.Items))
+
 	for _, itemReq := range req.Items {
 		// Convert product ID string to UUID
 		productID, err := uuid.Parse(itemReq.ProductID)
@@ -54,15 +98,39 @@ func (ss *saleService) CreateSale(ctx context.Context, req sale.CreateSaleReques
 			return nil, fmt.Errorf("invalid product ID format: %s", itemReq.ProductID)
 		}
 
+		if seenProducts[productID] {
+			return nil, fmt.Errorf("duplicate product %s in sale items", itemReq.ProductID)
+		}
+		seenProducts[productID] = true
+
 		// Check if product has sufficient stock
 		product, err := ss.repo.Product.CheckStock(ctx, productID, itemReq.Quantity)
 		if err != nil {
+			if err.Error() == "product is discontinued" {
+				return nil, fmt.Errorf("product %s is discontinued", itemReq.ProductID)
+			}
 			return nil, fmt.Errorf("insufficient stock for product %s: %w", itemReq.ProductID, err)
 		}
+		products[productID] = product
 
-		// Calculate item total
-		itemTotal := product.UnitPrice * float64(itemReq.Quantity)
-		totalAmount += itemTotal
+		if itemReq.ShelfID != nil {
+			shelfID, err := uuid.Parse(*itemReq.ShelfID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid shelf ID format: %s", *itemReq.ShelfID)
+			}
+			available, err := ss.locationStock(ctx, productID, shelfID)
+			if err != nil {
+				return nil, err
+			}
+			if available < itemReq.Quantity {
+				return nil, fmt.Errorf("insufficient stock for product %s at shelf %s", itemReq.ProductID, *itemReq.ShelfID)
+			}
+			itemShelves[productID] = shelfID
+		}
+
+		// Calculate item total (dibulatkan sesuai MONEY_DECIMAL_PLACES)
+		itemTotal := saleItemTotal(product.UnitPrice, itemReq.Quantity)
+		totalAmount = utils.RoundMoney(totalAmount + itemTotal)
 
 		// Prepare sale item
 		saleItem := model.SaleItem{
@@ -70,6 +138,7 @@ func (ss *saleService) CreateSale(ctx context.Context, req sale.CreateSaleReques
 			Quantity:   itemReq.Quantity,
 			UnitPrice:  product.UnitPrice,
 			TotalPrice: itemTotal,
+			Notes:      itemReq.Notes,
 		}
 		saleItems = append(saleItems, saleItem)
 	}
@@ -83,54 +152,310 @@ func (ss *saleService) CreateSale(ctx context.Context, req sale.CreateSaleReques
 		UserID:        userID,
 		TotalAmount:   totalAmount,
 		Status:        model.SaleStatusCompleted,
+		PaymentMethod: model.PaymentMethod(req.PaymentMethod), // kosong = cash (default di repository)
+		CustomerName:  optionalString(req.CustomerName),
+		CustomerPhone: optionalString(req.CustomerPhone),
 	}
 
-	// Save sale to database
-	if err := ss.repo.Sale.CreateSale(ctx, newSale); err != nil {
+	// Sale, item & potong stok dalam satu transaction: stok gagal dipotong = sale tidak tersimpan
+	stockAfter, err := ss.repo.Sale.CreateSaleWithItems(ctx, newSale, saleItems, itemShelves)
+	if err != nil {
+		// Stok berubah di antara CheckStock & lock di repo
+		if strings.HasPrefix(err.Error(), "insufficient stock") {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to create sale: %w", err)
 	}
 
-	// Link sale ID to all items
-	for i := range saleItems {
-		saleItems[i].SaleID = newSale.ID
+	// Alert jika stock baru saja masuk level low stock
+	for _, item := range saleItems {
+		product := products[item.ProductID]
+		product.StockQuantity = stockAfter[item.ProductID]
+		emitLowStockIfCrossed(ss.notifier, product, product.StockQuantity+item.Quantity, product.MinStockLevel)
 	}
 
-	// Save sale items
-	if err := ss.repo.Sale.CreateSaleItems(ctx, saleItems); err != nil {
-		return nil, fmt.Errorf("failed to create sale items: %w", err)
+	// Get complete sale details for response
+	saleWithItems, err := ss.getSaleWithItems(ctx, newSale.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sale details: %w", err)
 	}
 
-	// Update product stock (deduct sold quantities)
-	for _, item := range saleItems {
-		product, err := ss.repo.Product.FindByID(ctx, item.ProductID)
+	utils.LoggerFromContext(ctx).Info("Sale created",
+		zap.String("invoice", newSale.InvoiceNumber),
+		zap.Float64("total", newSale.TotalAmount))
+
+	ss.notifier.Emit(Event{
+		Type:       EventSaleCreated,
+		OccurredAt: time.Now(),
+		Data:       map[string]interface{}{"sale": saleWithItems},
+	})
+
+	return saleWithItems, nil
+}
+
+// DryRun simulasi CreateSale: cek stok & hitung total tanpa menyimpan apapun / reserve stok
+// Error request (format, duplikat, jumlah item) sama dengan CreateSale,
+// masalah stok per item dilaporkan di response (semua sekaligus, bukan berhenti di item pertama)
+func (ss *saleService) DryRun(ctx context.Context, req sale.CreateSaleRequest) (*sale.SaleDryRunResponse, error) {
+	if len(req.Items) > ss.opts.MaxItems {
+		return nil, fmt.Errorf("too many items: sale cannot have more than %d items", ss.opts.MaxItems)
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	response := &sale.SaleDryRunResponse{
+		Fulfillable: true,
+		Currency:    utils.Currency(),
+		Items:       make([]sale.SaleDryRunItem, 0, len(req.Items)),
+	}
+
+	seenProducts := make(map[uuid.UUID]bool, len(req.Items))
+	for _, itemReq := range req.Items {
+		productID, err := uuid.Parse(itemReq.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID format: %s", itemReq.ProductID)
+		}
+
+		if seenProducts[productID] {
+			return nil, fmt.Errorf("duplicate product %s in sale items", itemReq.ProductID)
+		}
+		seenProducts[productID] = true
+
+		item := sale.SaleDryRunItem{
+			ProductID: itemReq.ProductID,
+			ShelfID:   itemReq.ShelfID,
+			Quantity:  itemReq.Quantity,
+		}
+
+		product, err := ss.repo.Product.FindByID(ctx, productID)
 		if err != nil {
-			ss.log.Error("Failed to get product for stock update", zap.Error(err))
+			item.Reason = "not_found"
+			response.Fulfillable = false
+			response.Items = append(response.Items, item)
 			continue
 		}
 
-		// Calculate new stock quantity
-		newStock := product.StockQuantity - item.Quantity
-		if newStock < 0 {
-			newStock = 0
+		item.ProductName = product.Name
+		item.UnitPrice = product.UnitPrice
+		item.TotalPrice = saleItemTotal(product.UnitPrice, itemReq.Quantity)
+		item.AvailableStock = product.StockQuantity
+
+		if itemReq.ShelfID != nil {
+			shelfID, err := uuid.Parse(*itemReq.ShelfID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid shelf ID format: %s", *itemReq.ShelfID)
+			}
+			if item.AvailableStock, err = ss.locationStock(ctx, productID, shelfID); err != nil {
+				return nil, err
+			}
+		}
+
+		switch {
+		case product.Status == model.ProductStatusDiscontinued:
+			item.Reason = "discontinued"
+		case item.AvailableStock < itemReq.Quantity:
+			item.Reason = "insufficient_stock"
+		default:
+			item.Available = true
 		}
 
-		// Update product stock
-		if err := ss.repo.Product.UpdateStock(ctx, item.ProductID, newStock); err != nil {
-			ss.log.Error("Failed to update product stock", zap.Error(err))
+		if !item.Available {
+			response.Fulfillable = false
 		}
+		// Total tetap dihitung dari semua item yang punya harga, supaya kasir tahu nilai keranjang
+		response.TotalAmount = utils.RoundMoney(response.TotalAmount + item.TotalPrice)
+		response.Items = append(response.Items, item)
 	}
 
-	// Get complete sale details for response
-	saleWithItems, err := ss.getSaleWithItems(ctx, newSale.ID)
+	return response, nil
+}
+
+// Reorder membuat sale baru dari item sale lama (invoice baru, harga sekarang, cek stok ulang)
+// Semua produk dicek dulu supaya semua yang tidak tersedia bisa dilaporkan sekaligus
+func (ss *saleService) Reorder(ctx context.Context, sourceID uuid.UUID, userID uuid.UUID) (*sale.SaleResponse, error) {
+	source, err := ss.repo.Sale.FindSaleByID(ctx, sourceID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sale details: %w", err)
+		return nil, fmt.Errorf("sale not found")
 	}
 
-	ss.log.Info("Sale created",
-		zap.String("invoice", newSale.InvoiceNumber),
-		zap.Float64("total", newSale.TotalAmount))
+	items, err := ss.repo.Sale.FindSaleItems(ctx, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sale items: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("sale must have at least one item")
+	}
 
-	return saleWithItems, nil
+	// Sale lama bisa punya produk yang sama di beberapa baris, digabung dulu
+	quantities := make(map[uuid.UUID]int, len(items))
+	var productIDs []uuid.UUID
+	for _, item := range items {
+		if _, ok := quantities[item.ProductID]; !ok {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+
+	// Cara bayar & customer ikut sale lama
+	req := sale.CreateSaleRequest{
+		Items:         make([]sale.SaleItemRequest, 0, len(productIDs)),
+		PaymentMethod: string(source.PaymentMethod),
+		CustomerName:  derefString(source.CustomerName),
+		CustomerPhone: derefString(source.CustomerPhone),
+	}
+	var unavailable []string
+	for _, productID := range productIDs {
+		quantity := quantities[productID]
+
+		product, err := ss.repo.Product.FindByID(ctx, productID)
+		switch {
+		case err != nil:
+			unavailable = append(unavailable, fmt.Sprintf("%s (not found)", productID))
+		case product.Status == model.ProductStatusDiscontinued:
+			unavailable = append(unavailable, fmt.Sprintf("%s (discontinued)", productID))
+		case product.StockQuantity < quantity:
+			unavailable = append(unavailable, fmt.Sprintf("%s (insufficient stock: %d available, %d needed)", productID, product.StockQuantity, quantity))
+		}
+
+		req.Items = append(req.Items, sale.SaleItemRequest{ProductID: productID.String(), Quantity: quantity})
+	}
+
+	if len(unavailable) > 0 {
+		return nil, fmt.Errorf("cannot reorder, unavailable products: %s", strings.Join(unavailable, ", "))
+	}
+
+	newSale, err := ss.CreateSale(ctx, req, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	utils.LoggerFromContext(ctx).Info("Sale reordered",
+		zap.String("source_sale_id", sourceID.String()),
+		zap.String("invoice", newSale.InvoiceNumber))
+
+	return newSale, nil
+}
+
+// ExportSale snapshot lengkap satu sale untuk arsip (2 query: sale+kasir, item+produk+kategori)
+func (ss *saleService) ExportSale(ctx context.Context, id uuid.UUID) (*sale.SaleExportResponse, error) {
+	saleData, err := ss.repo.Sale.FindSaleWithCashier(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("sale not found")
+	}
+
+	items, err := ss.repo.Sale.FindSaleItemDetails(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sale items: %w", err)
+	}
+
+	export := newSaleExport(saleData, time.Now(), len(items))
+	for _, item := range items {
+		appendExportItem(export, item)
+	}
+
+	return export, nil
+}
+
+// StreamFullExport semua sale + item dalam periode, fn dipanggil sekali per sale (item lengkap).
+// Validasi dilakukan sebelum query, jadi error validasi selalu terjadi sebelum fn pertama dipanggil
+func (ss *saleService) StreamFullExport(ctx context.Context, req sale.SaleFullExportRequest, fn func(export *sale.SaleExportResponse) error) error {
+	if err := utils.ValidateStruct(req); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+	}
+
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+	}
+
+	if startDate.After(endDate) {
+		return fmt.Errorf("start date cannot be after end date")
+	}
+	if endDate.Sub(startDate) > 365*24*time.Hour {
+		return fmt.Errorf("date range cannot exceed 1 year")
+	}
+
+	// Baris item satu sale selalu berurutan, sale dikirim saat sale berikutnya mulai
+	exportedAt := time.Now()
+	var current *sale.SaleExportResponse
+	count := 0
+	err = ss.repo.Sale.StreamSalesWithItems(ctx, startDate, endDate, func(s *model.SaleWithCashier, item model.SaleItemDetail) error {
+		if current != nil && current.SaleID != s.ID.String() {
+			if err := fn(current); err != nil {
+				return err
+			}
+			count++
+			current = nil
+		}
+		if current == nil {
+			current = newSaleExport(s, exportedAt, 0)
+		}
+		appendExportItem(current, item)
+		return nil
+	})
+	if err == nil && current != nil {
+		err = fn(current)
+		count++
+	}
+	if err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to stream sales export", zap.Error(err))
+		return fmt.Errorf("failed to export sales")
+	}
+
+	utils.LoggerFromContext(ctx).Info("Sales exported",
+		zap.String("start_date", req.StartDate),
+		zap.String("end_date", req.EndDate),
+		zap.Int("sales", count))
+	return nil
+}
+
+// newSaleExport helper: header export satu sale, item ditambah lewat appendExportItem
+func newSaleExport(saleData *model.SaleWithCashier, exportedAt time.Time, itemCapacity int) *sale.SaleExportResponse {
+	return &sale.SaleExportResponse{
+		ExportedAt:      exportedAt,
+		SaleID:          saleData.ID.String(),
+		InvoiceNumber:   saleData.InvoiceNumber,
+		Status:          string(saleData.Status),
+		PaymentMethod:   string(saleData.PaymentMethod),
+		CustomerName:    saleData.CustomerName,
+		CustomerPhone:   saleData.CustomerPhone,
+		PaymentStatus:   string(saleData.PaymentStatus),
+		AmountPaid:      utils.RoundMoney(saleData.AmountPaid),
+		CancelledReason: saleData.CancelledReason,
+		CancelledAt:     saleData.CancelledAt,
+		CreatedAt:       saleData.CreatedAt,
+		CashierID:       saleData.UserID.String(),
+		CashierUsername: saleData.CashierUsername,
+		CashierName:     saleData.CashierFullName,
+		Currency:        utils.Currency(),
+		TotalAmount:     utils.RoundMoney(saleData.TotalAmount),
+		Items:           make([]sale.SaleExportItem, 0, itemCapacity),
+	}
+}
+
+// appendExportItem helper: tambah satu item ke export & update total
+func appendExportItem(export *sale.SaleExportResponse, item model.SaleItemDetail) {
+	export.ItemCount++
+	export.TotalQuantity += item.Quantity
+	export.ItemsTotal = utils.RoundMoney(export.ItemsTotal + item.TotalPrice)
+	export.Items = append(export.Items, sale.SaleExportItem{
+		ItemID:       item.ID.String(),
+		ProductID:    item.ProductID.String(),
+		ProductName:  item.ProductName,
+		CategoryID:   item.CategoryID.String(),
+		CategoryName: item.CategoryName,
+		Quantity:     item.Quantity,
+		UnitPrice:    item.UnitPrice,
+		TotalPrice:   item.TotalPrice,
+		Notes:        item.Notes,
+	})
 }
 
 // GetSaleByID retrieves sale with all items
@@ -145,19 +470,35 @@ func (ss *saleService) GetSaleByID(ctx context.Context, id uuid.UUID) (*sale.Sal
 	return ss.getSaleWithItems(ctx, saleData.ID)
 }
 
+// GetSaleByInvoice retrieves sale with all items by invoice number
+func (ss *saleService) GetSaleByInvoice(ctx context.Context, invoiceNumber string) (*sale.SaleResponse, error) {
+	saleData, err := ss.repo.Sale.FindByInvoiceNumber(ctx, invoiceNumber)
+	if err != nil {
+		return nil, fmt.Errorf("sale not found")
+	}
+
+	return ss.getSaleWithItems(ctx, saleData.ID)
+}
+
 // GetAllSales retrieves sales list with pagination
-func (ss *saleService) GetAllSales(ctx context.Context, userID *uuid.UUID, page, limit int) ([]sale.SaleResponse, utils.Pagination, error) {
+// Filter opsional: status, date range (inclusive) & total_amount range
+func (ss *saleService) GetAllSales(ctx context.Context, userID *uuid.UUID, req sale.SaleListRequest, page, limit int) ([]sale.SaleResponse, utils.Pagination, error) {
 	// Initialize pagination
 	pagination := utils.NewPagination(page, limit)
 
+	filter, err := buildSaleListFilter(userID, req)
+	if err != nil {
+		return nil, pagination, err
+	}
+
 	// Get sales from repository
-	sales, err := ss.repo.Sale.FindAllSales(ctx, userID, pagination.Limit, pagination.Offset())
+	sales, err := ss.repo.Sale.FindAllSales(ctx, filter, pagination.Limit, pagination.Offset())
 	if err != nil {
 		return nil, pagination, fmt.Errorf("failed to get sales: %w", err)
 	}
 
 	// Get total count for pagination
-	total, err := ss.repo.Sale.CountAllSales(ctx, userID)
+	total, err := ss.repo.Sale.CountAllSales(ctx, filter)
 	if err != nil {
 		return nil, pagination, fmt.Errorf("failed to count sales: %w", err)
 	}
@@ -168,21 +509,28 @@ func (ss *saleService) GetAllSales(ctx context.Context, userID *uuid.UUID, page,
 	// Convert sales to response format
 	responses := make([]sale.SaleResponse, 0, len(sales))
 	for _, s := range sales {
-		response := sale.SaleResponse{
-			ID:            s.ID.String(),
-			InvoiceNumber: s.InvoiceNumber,
-			UserID:        s.UserID.String(),
-			TotalAmount:   s.TotalAmount,
-			Status:        string(s.Status),
-			CreatedAt:     s.CreatedAt,
-			UpdatedAt:     s.UpdatedAt,
-		}
-		responses = append(responses, response)
+		responses = append(responses, ss.convertToResponse(&s, nil))
 	}
 
 	return responses, pagination, nil
 }
 
+// CountSales jumlah sale dengan filter yang sama seperti GetAllSales (untuk badge)
+// userID nil = semua sale
+func (ss *saleService) CountSales(ctx context.Context, userID *uuid.UUID, req sale.SaleListRequest) (*sale.SaleCountResponse, error) {
+	filter, err := buildSaleListFilter(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := ss.repo.Sale.CountAllSales(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sales: %w", err)
+	}
+
+	return &sale.SaleCountResponse{Count: total}, nil
+}
+
 // UpdateSaleStatus changes sale status and handles stock restoration if cancelled
 func (ss *saleService) UpdateSaleStatus(ctx context.Context, id uuid.UUID, req sale.UpdateSaleStatusRequest) (*sale.SaleResponse, error) {
 	// Validate request
@@ -209,22 +557,450 @@ func (ss *saleService) UpdateSaleStatus(ctx context.Context, id uuid.UUID, req s
 		return nil, fmt.Errorf("invalid status: %s", req.Status)
 	}
 
-	// Update status in database
-	if err := ss.repo.Sale.UpdateSaleStatus(ctx, id, newStatus); err != nil {
-		return nil, fmt.Errorf("failed to update sale status: %w", err)
+	// Reason wajib untuk cancellation
+	var reason *string
+	if req.Reason != nil {
+		trimmed := strings.TrimSpace(*req.Reason)
+		if trimmed != "" {
+			reason = &trimmed
+		}
+	}
+	if newStatus == model.SaleStatusCancelled && reason == nil {
+		return nil, fmt.Errorf("validation failed: reason is required when cancelling a sale")
 	}
 
-	// If cancelling a completed sale, restore product stock
-	if existingSale.Status == model.SaleStatusCompleted && newStatus == model.SaleStatusCancelled {
-		if err := ss.restoreProductStock(ctx, id); err != nil {
-			ss.log.Error("Failed to restore stock after cancellation", zap.Error(err))
+	// Stok sale cancelled sudah dikembalikan, buka lagi = stok tidak terpotong
+	if existingSale.Status == model.SaleStatusCancelled && newStatus != model.SaleStatusCancelled {
+		return nil, fmt.Errorf("cannot change status of a cancelled sale")
+	}
+
+	if newStatus == model.SaleStatusCancelled {
+		// Cancel + restore stok (jika sebelumnya completed) atomic, gagal restore = status tidak berubah
+		previous, err := ss.repo.Sale.CancelSale(ctx, id, *reason)
+		if err != nil {
+			if err.Error() == "sale not found" {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to cancel sale: %w", err)
 		}
+		existingSale.Status = previous
+	} else if err := ss.repo.Sale.UpdateSaleStatus(ctx, id, newStatus, reason); err != nil {
+		// Update status in database, cek ulang di bawah lock bisa menolak sale yang baru saja dibatalkan
+		if err.Error() == "sale not found" || err.Error() == "cannot change status of a cancelled sale" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update sale status: %w", err)
 	}
 
 	// Get updated sale with items
+	updatedSale, err := ss.getSaleWithItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingSale.Status != newStatus {
+		ss.notifier.Emit(Event{
+			Type:       EventSaleStatusChanged,
+			OccurredAt: time.Now(),
+			Data: map[string]interface{}{
+				"previous_status": string(existingSale.Status),
+				"sale":            updatedSale,
+			},
+		})
+	}
+
+	return updatedSale, nil
+}
+
+// GetMyDailySales daily series of one user (default: current month, max 1 year)
+func (ss *saleService) GetMyDailySales(ctx context.Context, userID uuid.UUID, req sale.MyDailySalesRequest) (*sale.MyDailySalesResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	now := time.Now()
+	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var err error
+	if req.StartDate != "" {
+		if startDate, err = time.Parse("2006-01-02", req.StartDate); err != nil {
+			return nil, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+		}
+	}
+	if req.EndDate != "" {
+		if endDate, err = time.Parse("2006-01-02", req.EndDate); err != nil {
+			return nil, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+		}
+	}
+
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start date cannot be after end date")
+	}
+	if endDate.Sub(startDate) > 365*24*time.Hour {
+		return nil, fmt.Errorf("date range cannot exceed 1 year")
+	}
+
+	days, err := ss.repo.Sale.GetUserDailySales(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily sales: %w", err)
+	}
+
+	return &sale.MyDailySalesResponse{
+		UserID:    userID.String(),
+		StartDate: startDate.Format("2006-01-02"),
+		EndDate:   endDate.Format("2006-01-02"),
+		Currency:  utils.Currency(),
+		Days:      fillDailySales(days, startDate, endDate),
+	}, nil
+}
+
+// fillDailySales satu entry per hari dari startDate s/d endDate (urut), hari tanpa penjualan = 0
+func fillDailySales(found []model.DailySales, startDate, endDate time.Time) []sale.DailySalesResponse {
+	byDate := make(map[string]model.DailySales, len(found))
+	for _, day := range found {
+		byDate[day.Date.Format("2006-01-02")] = day
+	}
+
+	days := make([]sale.DailySalesResponse, 0)
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		key := date.Format("2006-01-02")
+		day := byDate[key]
+		days = append(days, sale.DailySalesResponse{
+			Date:       key,
+			SalesCount: day.SalesCount,
+			ItemsSold:  day.ItemsSold,
+			Revenue:    utils.RoundMoney(day.Revenue),
+		})
+	}
+	return days
+}
+
+// GetStatusBreakdown jumlah sale pending/completed/cancelled dalam periode (max 1 tahun)
+func (ss *saleService) GetStatusBreakdown(ctx context.Context, req sale.SaleStatusBreakdownRequest) (*sale.SaleStatusBreakdownResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+	}
+
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+	}
+
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start date cannot be after end date")
+	}
+	if endDate.Sub(startDate) > 365*24*time.Hour {
+		return nil, fmt.Errorf("date range cannot exceed 1 year")
+	}
+
+	counts, err := ss.repo.Sale.CountByStatus(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sales by status: %w", err)
+	}
+
+	byStatus := make(map[model.SaleStatus]int, len(counts))
+	total := 0
+	for _, count := range counts {
+		byStatus[count.Status] = count.Count
+		total += count.Count
+	}
+
+	response := &sale.SaleStatusBreakdownResponse{
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Total:     total,
+		Statuses:  make([]sale.SaleStatusCountResponse, 0, len(model.SaleStatuses)),
+	}
+
+	// Urutan tetap, status tanpa sale tetap muncul dengan nilai 0
+	for _, status := range model.SaleStatuses {
+		count := byStatus[status]
+		percentage := 0.0
+		if total > 0 {
+			percentage = math.Round(float64(count)/float64(total)*10000) / 100
+		}
+		response.Statuses = append(response.Statuses, sale.SaleStatusCountResponse{
+			Status:     string(status),
+			Count:      count,
+			Percentage: percentage,
+		})
+	}
+
+	return response, nil
+}
+
+// GetProductSalesHistory retrieves sales history of a single product
+func (ss *saleService) GetProductSalesHistory(ctx context.Context, productID uuid.UUID, req sale.ProductSalesHistoryRequest, page, limit int) ([]sale.ProductSaleHistoryResponse, utils.Pagination, error) {
+	pagination := utils.NewPagination(page, limit)
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, pagination, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Product must exist
+	if _, err := ss.repo.Product.FindByID(ctx, productID); err != nil {
+		return nil, pagination, fmt.Errorf("product not found")
+	}
+
+	// Parse optional date range
+	var startDate, endDate *time.Time
+	if req.StartDate != "" {
+		t, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, pagination, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+		}
+		startDate = &t
+	}
+	if req.EndDate != "" {
+		t, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, pagination, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+		}
+		endDate = &t
+	}
+	if startDate != nil && endDate != nil && startDate.After(*endDate) {
+		return nil, pagination, fmt.Errorf("start date cannot be after end date")
+	}
+
+	history, err := ss.repo.Sale.FindSalesByProduct(ctx, productID, startDate, endDate, req.IncludeCancelled, pagination.Limit, pagination.Offset())
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to get product sales: %w", err)
+	}
+
+	total, err := ss.repo.Sale.CountSalesByProduct(ctx, productID, startDate, endDate, req.IncludeCancelled)
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to count product sales: %w", err)
+	}
+
+	pagination.SetTotal(total)
+
+	responses := make([]sale.ProductSaleHistoryResponse, 0, len(history))
+	for _, h := range history {
+		responses = append(responses, sale.ProductSaleHistoryResponse{
+			SaleID:        h.SaleID.String(),
+			InvoiceNumber: h.InvoiceNumber,
+			Status:        string(h.Status),
+			Quantity:      h.Quantity,
+			UnitPrice:     h.UnitPrice,
+			TotalPrice:    h.TotalPrice,
+			SoldAt:        h.SoldAt,
+		})
+	}
+
+	return responses, pagination, nil
+}
+
+// GetSaleItemLedger retrieves every sale line across all sales (flat, for accounting)
+func (ss *saleService) GetSaleItemLedger(ctx context.Context, req sale.SaleItemLedgerRequest, page, limit int) ([]sale.SaleItemLedgerResponse, utils.Pagination, error) {
+	pagination := utils.NewPagination(page, limit)
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, pagination, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Parse optional date range
+	var startDate, endDate *time.Time
+	if req.StartDate != "" {
+		t, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, pagination, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
+		}
+		startDate = &t
+	}
+	if req.EndDate != "" {
+		t, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, pagination, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+		}
+		endDate = &t
+	}
+	if startDate != nil && endDate != nil && startDate.After(*endDate) {
+		return nil, pagination, fmt.Errorf("start date cannot be after end date")
+	}
+
+	entries, err := ss.repo.Sale.FindAllSaleItems(ctx, startDate, endDate, req.IncludeCancelled, pagination.Limit, pagination.Offset())
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to get sale items: %w", err)
+	}
+
+	total, err := ss.repo.Sale.CountAllSaleItems(ctx, startDate, endDate, req.IncludeCancelled)
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to count sale items: %w", err)
+	}
+
+	pagination.SetTotal(total)
+
+	responses := make([]sale.SaleItemLedgerResponse, 0, len(entries))
+	for _, e := range entries {
+		responses = append(responses, sale.SaleItemLedgerResponse{
+			ID:            e.ID.String(),
+			SaleID:        e.SaleID.String(),
+			InvoiceNumber: e.InvoiceNumber,
+			Status:        string(e.Status),
+			ProductID:     e.ProductID.String(),
+			ProductName:   e.ProductName,
+			Quantity:      e.Quantity,
+			UnitPrice:     e.UnitPrice,
+			TotalPrice:    e.TotalPrice,
+			SoldAt:        e.SoldAt,
+		})
+	}
+
+	return responses, pagination, nil
+}
+
+// RecalculateTotal re-sums sale items into total_amount and re-derives payment_status
+// Belum ada kolom discount/tax, jadi total = jumlah total_price item
+func (ss *saleService) RecalculateTotal(ctx context.Context, id uuid.UUID) (*sale.RecalculateSaleResponse, error) {
+	existingSale, err := ss.repo.Sale.FindSaleByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("sale not found")
+	}
+	if existingSale.Status == model.SaleStatusCancelled {
+		return nil, fmt.Errorf("cannot recalculate a cancelled sale")
+	}
+
+	changed, err := ss.repo.Sale.RecalculateSaleTotal(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recalculate sale total")
+	}
+
+	saleWithItems, err := ss.getSaleWithItems(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updated sale: %w", err)
+	}
+
+	if changed {
+		utils.LoggerFromContext(ctx).Warn("Sale total corrected",
+			zap.String("sale_id", id.String()),
+			zap.Float64("previous_total", existingSale.TotalAmount),
+			zap.Float64("new_total", saleWithItems.TotalAmount))
+	}
+
+	return &sale.RecalculateSaleResponse{
+		Sale:          *saleWithItems,
+		PreviousTotal: existingSale.TotalAmount,
+		Changed:       changed,
+	}, nil
+}
+
+// UpdatePayment catat jumlah yang sudah dibayar, payment_status diturunkan dari amount_paid vs total
+// Sale cancelled tidak bisa dicatat pembayarannya
+func (ss *saleService) UpdatePayment(ctx context.Context, id uuid.UUID, req sale.UpdateSalePaymentRequest) (*sale.SaleResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	existingSale, err := ss.repo.Sale.FindSaleByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("sale not found")
+	}
+	if existingSale.Status == model.SaleStatusCancelled {
+		return nil, fmt.Errorf("cannot record payment for a cancelled sale")
+	}
+
+	amountPaid := utils.RoundMoney(*req.AmountPaid)
+	total := utils.RoundMoney(existingSale.TotalAmount)
+	if amountPaid > total {
+		return nil, fmt.Errorf("amount paid cannot exceed total amount (%.2f)", total)
+	}
+
+	status := paymentStatusFor(amountPaid, total)
+	if err := ss.repo.Sale.UpdatePayment(ctx, id, amountPaid, status); err != nil {
+		// Total bisa berubah sejak dibaca (recalculate), pesan dari repo diteruskan apa adanya
+		if err.Error() == "sale not found" || strings.HasPrefix(err.Error(), "amount paid cannot exceed") {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update sale payment: %w", err)
+	}
+
 	return ss.getSaleWithItems(ctx, id)
 }
 
+// paymentStatusFor helper: 0 = unpaid, kurang dari total = partial, lunas = paid
+// Sale dengan total 0 dianggap paid
+func paymentStatusFor(amountPaid, total float64) model.PaymentStatus {
+	switch {
+	case amountPaid >= total:
+		return model.PaymentStatusPaid
+	case amountPaid <= 0:
+		return model.PaymentStatusUnpaid
+	default:
+		return model.PaymentStatusPartial
+	}
+}
+
+// ReassignUser ganti kasir sale yang tercatat di akun staff yang salah
+// Kasir tujuan harus ada & aktif. Belum ada tabel audit, jejak perubahan dicatat di log
+func (ss *saleService) ReassignUser(ctx context.Context, id uuid.UUID, req sale.ReassignSaleRequest, actorID uuid.UUID) (*sale.ReassignSaleResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format")
+	}
+
+	existingSale, err := ss.repo.Sale.FindSaleByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("sale not found")
+	}
+
+	targetUser, err := ss.repo.User.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if !targetUser.IsActive {
+		return nil, fmt.Errorf("user is inactive")
+	}
+
+	previousUserID := existingSale.UserID
+	if previousUserID != userID {
+		if err := ss.repo.Sale.UpdateSaleUser(ctx, id, userID); err != nil {
+			if err.Error() == "sale not found" {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to reassign sale")
+		}
+
+		utils.LoggerFromContext(ctx).Warn("Sale cashier reassigned",
+			zap.String("sale_id", id.String()),
+			zap.String("invoice_number", existingSale.InvoiceNumber),
+			zap.String("previous_user_id", previousUserID.String()),
+			zap.String("new_user_id", userID.String()),
+			zap.String("actor_id", actorID.String()))
+	}
+
+	saleWithItems, err := ss.getSaleWithItems(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updated sale: %w", err)
+	}
+
+	return &sale.ReassignSaleResponse{
+		Sale:           *saleWithItems,
+		PreviousUserID: previousUserID.String(),
+	}, nil
+}
+
+// RecalculateAllTotals fixes every sale whose total differs from its items
+func (ss *saleService) RecalculateAllTotals(ctx context.Context) (*sale.RecalculateAllSalesResponse, error) {
+	changed, err := ss.repo.Sale.RecalculateAllSaleTotals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recalculate sale totals")
+	}
+
+	return &sale.RecalculateAllSalesResponse{Changed: changed}, nil
+}
+
 // getSaleWithItems helper: retrieves sale with all items and product details
 func (ss *saleService) getSaleWithItems(ctx context.Context, saleID uuid.UUID) (*sale.SaleResponse, error) {
 	// Get sale details
@@ -251,20 +1027,13 @@ func (ss *saleService) getSaleWithItems(ctx context.Context, saleID uuid.UUID) (
 				Quantity:   item.Quantity,
 				UnitPrice:  item.UnitPrice,
 				TotalPrice: item.TotalPrice,
+				Notes:      item.Notes,
 				CreatedAt:  item.CreatedAt,
 			})
 		}
 
-		return &sale.SaleResponse{
-			ID:            saleData.ID.String(),
-			InvoiceNumber: saleData.InvoiceNumber,
-			UserID:        saleData.UserID.String(),
-			TotalAmount:   saleData.TotalAmount,
-			Status:        string(saleData.Status),
-			CreatedAt:     saleData.CreatedAt,
-			UpdatedAt:     saleData.UpdatedAt,
-			Items:         itemResponses,
-		}, nil
+		response := ss.convertToResponse(saleData, itemResponses)
+		return &response, nil
 	}
 
 	// Convert items with product names to response
@@ -277,53 +1046,127 @@ func (ss *saleService) getSaleWithItems(ctx context.Context, saleID uuid.UUID) (
 			Quantity:    item.Quantity,
 			UnitPrice:   item.UnitPrice,
 			TotalPrice:  item.TotalPrice,
+			Notes:       item.Notes,
 			CreatedAt:   item.CreatedAt,
 		})
 	}
 
-	return &sale.SaleResponse{
-		ID:            saleData.ID.String(),
-		InvoiceNumber: saleData.InvoiceNumber,
-		UserID:        saleData.UserID.String(),
-		TotalAmount:   saleData.TotalAmount,
-		Status:        string(saleData.Status),
-		CreatedAt:     saleData.CreatedAt,
-		UpdatedAt:     saleData.UpdatedAt,
-		Items:         itemResponses,
-	}, nil
+	response := ss.convertToResponse(saleData, itemResponses)
+	return &response, nil
 }
 
-// restoreProductStock helper: restores product stock when sale is cancelled
-func (ss *saleService) restoreProductStock(ctx context.Context, saleID uuid.UUID) error {
-	// Get all items from cancelled sale
-	items, err := ss.repo.Sale.FindSaleItems(ctx, saleID)
-	if err != nil {
-		return fmt.Errorf("failed to get sale items: %w", err)
+// buildSaleListFilter helper: validasi & parse filter list sales
+func buildSaleListFilter(userID *uuid.UUID, req sale.SaleListRequest) (repository.SaleListFilter, error) {
+	filter := repository.SaleListFilter{UserID: userID, MinAmount: req.MinAmount, MaxAmount: req.MaxAmount}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		return filter, fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Restore stock for each product
-	for _, item := range items {
-		product, err := ss.repo.Product.FindByID(ctx, item.ProductID)
+	if req.Status != "" {
+		status := model.SaleStatus(req.Status)
+		filter.Status = &status
+	}
+	if req.StartDate != "" {
+		startDate, err := time.Parse("2006-01-02", req.StartDate)
 		if err != nil {
-			ss.log.Error("Failed to get product", zap.Error(err))
-			continue
+			return filter, fmt.Errorf("invalid start date format. Use YYYY-MM-DD")
 		}
+		filter.StartDate = &startDate
+	}
+	if req.EndDate != "" {
+		endDate, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return filter, fmt.Errorf("invalid end date format. Use YYYY-MM-DD")
+		}
+		filter.EndDate = &endDate
+	}
 
-		// Calculate restored stock
-		newStock := product.StockQuantity + item.Quantity
+	if filter.StartDate != nil && filter.EndDate != nil && filter.StartDate.After(*filter.EndDate) {
+		return filter, fmt.Errorf("start date cannot be after end date")
+	}
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		return filter, fmt.Errorf("invalid amount range: min_amount cannot be greater than max_amount")
+	}
+
+	return filter, nil
+}
+
+// locationStock helper: stok produk di satu rak
+// Produk single location: hanya rak utama yang punya stok (seluruh total)
+func (ss *saleService) locationStock(ctx context.Context, productID, shelfID uuid.UUID) (int, error) {
+	locations, err := ss.repo.StockLocation.FindByProduct(ctx, productID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stock locations: %w", err)
+	}
 
-		// Update product stock
-		if err := ss.repo.Product.UpdateStock(ctx, item.ProductID, newStock); err != nil {
-			ss.log.Error("Failed to restore stock", zap.Error(err))
+	if len(locations) == 0 {
+		product, err := ss.repo.Product.FindByID(ctx, productID)
+		if err != nil {
+			return 0, fmt.Errorf("product not found")
+		}
+		if product.ShelfID == shelfID {
+			return product.StockQuantity, nil
 		}
+		return 0, nil
 	}
 
-	return nil
+	for _, location := range locations {
+		if location.ShelfID == shelfID {
+			return location.Quantity, nil
+		}
+	}
+	return 0, nil
+}
+
+// saleItemTotal harga satu baris (dibulatkan sesuai MONEY_DECIMAL_PLACES)
+// Dipakai CreateSale & DryRun supaya perhitungan total tidak pernah beda
+func saleItemTotal(unitPrice float64, quantity int) float64 {
+	return utils.RoundMoney(unitPrice * float64(quantity))
+}
+
+// convertToResponse helper: maps sale model (and optional items) to response
+func (ss *saleService) convertToResponse(s *model.Sale, items []sale.SaleItemResponse) sale.SaleResponse {
+	return sale.SaleResponse{
+		ID:              s.ID.String(),
+		InvoiceNumber:   s.InvoiceNumber,
+		UserID:          s.UserID.String(),
+		TotalAmount:     s.TotalAmount,
+		Currency:        utils.Currency(),
+		Status:          string(s.Status),
+		PaymentMethod:   string(s.PaymentMethod),
+		CustomerName:    s.CustomerName,
+		CustomerPhone:   s.CustomerPhone,
+		PaymentStatus:   string(s.PaymentStatus),
+		AmountPaid:      s.AmountPaid,
+		CancelledReason: s.CancelledReason,
+		CancelledAt:     s.CancelledAt,
+		CreatedAt:       s.CreatedAt,
+		UpdatedAt:       s.UpdatedAt,
+		Items:           items,
+	}
+}
+
+// optionalString trim spasi, string kosong = nil (kolom opsional seperti customer)
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// derefString kebalikan optionalString, nil = string kosong
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
 }
 
 // generateInvoiceNumber helper: creates unique invoice number
+// Format dari config (INVOICE_PREFIX, INVOICE_DATE_FORMAT), default INV-YYYYMMDD-NNNN
 func generateInvoiceNumber() string {
-	datePart := time.Now().Format("20060102")
-	randomPart := fmt.Sprintf("%04d", time.Now().Nanosecond()%10000)
-	return fmt.Sprintf("INV-%s-%s", datePart, randomPart)
+	now := time.Now()
+	return utils.FormatInvoiceNumber(now, now.Nanosecond())
 }
@@ -0,0 +1,1126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/dto/sale"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"inventory-system/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKES ==========
+
+// fakeSaleRepo simpan satu sale di memori, method lain dari interface tidak dipakai (panic jika terpanggil)
+type fakeSaleRepo struct {
+	repository.SaleRepo
+
+	sale *model.Sale
+	// deducted stok yang dipotong saat sale dibuat, restored yang sudah dikembalikan lewat CancelSale
+	deducted int
+	restored int
+
+	updateCalls int
+	cancelCalls int
+	// updateErr dipakai untuk simulasi status berubah di antara FindSaleByID & lock di repo
+	updateErr error
+	// createErr simulasi potong stok gagal di dalam transaction CreateSaleWithItems
+	createErr error
+	// paymentErr simulasi guard total di query UpdatePayment (total berubah lewat recalculate)
+	paymentErr error
+	// itemTotal jumlah total_price sale_items, dipakai RecalculateSaleTotal
+	itemTotal   float64
+	recalcCalls int
+
+	// items item sale sumber (FindSaleItems), created item yang disimpan CreateSaleWithItems
+	items   []model.SaleItem
+	created []model.SaleItem
+
+	// daily hari dengan penjualan per kasir (GetUserDailySales)
+	daily map[uuid.UUID][]model.DailySales
+
+	// ledger baris sale item (urut terbaru), ledgerFilter filter terakhir yang diterima FindAllSaleItems
+	ledger       []model.SaleItemLedgerEntry
+	ledgerFilter *ledgerFilter
+
+	// reassignCalls jumlah UpdateSaleUser yang benar-benar dijalankan
+	reassignCalls int
+
+	// statusCounts hasil CountByStatus, seperti GROUP BY hanya status yang punya sale
+	statusCounts []model.SaleStatusCount
+}
+
+type ledgerFilter struct {
+	startDate, endDate *time.Time
+	includeCancelled   bool
+	limit, offset      int
+}
+
+func (f *fakeSaleRepo) FindSaleByID(ctx context.Context, id uuid.UUID) (*model.Sale, error) {
+	if f.sale == nil || f.sale.ID != id {
+		return nil, fmt.Errorf("sale not found")
+	}
+	copied := *f.sale
+	return &copied, nil
+}
+
+func (f *fakeSaleRepo) FindSaleItemsWithProduct(ctx context.Context, saleID uuid.UUID) ([]model.SaleItemWithProduct, error) {
+	return nil, nil
+}
+
+func (f *fakeSaleRepo) FindSaleItems(ctx context.Context, saleID uuid.UUID) ([]model.SaleItem, error) {
+	return f.items, nil
+}
+
+func (f *fakeSaleRepo) UpdateSaleStatus(ctx context.Context, id uuid.UUID, status model.SaleStatus, reason *string) error {
+	f.updateCalls++
+	if f.updateErr != nil {
+		return f.updateErr
+	}
+	if f.sale.Status == model.SaleStatusCancelled && status != model.SaleStatusCancelled {
+		return fmt.Errorf("cannot change status of a cancelled sale")
+	}
+	f.sale.Status = status
+	return nil
+}
+
+func (f *fakeSaleRepo) CreateSaleWithItems(ctx context.Context, sale *model.Sale, items []model.SaleItem, shelves map[uuid.UUID]uuid.UUID) (map[uuid.UUID]int, error) {
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	sale.ID = uuid.New()
+	f.sale = sale
+	f.created = items
+	return map[uuid.UUID]int{}, nil
+}
+
+func (f *fakeSaleRepo) UpdatePayment(ctx context.Context, id uuid.UUID, amountPaid float64, status model.PaymentStatus) error {
+	if f.paymentErr != nil {
+		return f.paymentErr
+	}
+	f.sale.AmountPaid = amountPaid
+	f.sale.PaymentStatus = status
+	return nil
+}
+
+func (f *fakeSaleRepo) CancelSale(ctx context.Context, id uuid.UUID, reason string) (model.SaleStatus, error) {
+	f.cancelCalls++
+	previous := f.sale.Status
+	if previous != model.SaleStatusCancelled {
+		f.restored += f.deducted - f.restored
+	}
+	now := time.Now()
+	f.sale.Status = model.SaleStatusCancelled
+	f.sale.CancelledReason = &reason
+	f.sale.CancelledAt = &now
+	return previous, nil
+}
+
+func (f *fakeSaleRepo) RecalculateSaleTotal(ctx context.Context, id uuid.UUID) (bool, error) {
+	f.recalcCalls++
+	if f.sale.Status == model.SaleStatusCancelled || f.sale.TotalAmount == f.itemTotal {
+		return false, nil
+	}
+	f.sale.TotalAmount = f.itemTotal
+	f.sale.PaymentStatus = paymentStatusFor(f.sale.AmountPaid, f.itemTotal)
+	return true, nil
+}
+
+func (f *fakeSaleRepo) GetUserDailySales(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]model.DailySales, error) {
+	var days []model.DailySales
+	for _, day := range f.daily[userID] {
+		if !day.Date.Before(startDate) && !day.Date.After(endDate) {
+			days = append(days, day)
+		}
+	}
+	return days, nil
+}
+
+// FindAllSaleItems meniru repo: filter tanggal (endDate inclusive) lalu LIMIT/OFFSET
+func (f *fakeSaleRepo) FindAllSaleItems(ctx context.Context, startDate, endDate *time.Time, includeCancelled bool, limit, offset int) ([]model.SaleItemLedgerEntry, error) {
+	f.ledgerFilter = &ledgerFilter{startDate: startDate, endDate: endDate, includeCancelled: includeCancelled, limit: limit, offset: offset}
+	matched := f.filterLedger(startDate, endDate)
+	matched = matched[min(offset, len(matched)):]
+	return matched[:min(limit, len(matched))], nil
+}
+
+func (f *fakeSaleRepo) CountAllSaleItems(ctx context.Context, startDate, endDate *time.Time, includeCancelled bool) (int, error) {
+	return len(f.filterLedger(startDate, endDate)), nil
+}
+
+func (f *fakeSaleRepo) filterLedger(startDate, endDate *time.Time) []model.SaleItemLedgerEntry {
+	var matched []model.SaleItemLedgerEntry
+	for _, e := range f.ledger {
+		if startDate != nil && e.SoldAt.Before(*startDate) {
+			continue
+		}
+		if endDate != nil && !e.SoldAt.Before(endDate.AddDate(0, 0, 1)) {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	return matched
+}
+
+func (f *fakeSaleRepo) UpdateSaleUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	f.reassignCalls++
+	if f.sale == nil || f.sale.ID != id {
+		return fmt.Errorf("sale not found")
+	}
+	f.sale.UserID = userID
+	return nil
+}
+
+func (f *fakeSaleRepo) CountByStatus(ctx context.Context, startDate, endDate time.Time) ([]model.SaleStatusCount, error) {
+	return f.statusCounts, nil
+}
+
+type recordingNotifier struct {
+	events []Event
+}
+
+func (n *recordingNotifier) Emit(event Event) {
+	n.events = append(n.events, event)
+}
+
+func newTestSaleService(saleRepo *fakeSaleRepo) (*saleService, *recordingNotifier) {
+	notifier := &recordingNotifier{}
+	svc := NewSaleService(&repository.Repository{Sale: saleRepo}, zap.NewNop(), notifier, SaleOptions{})
+	return svc.(*saleService), notifier
+}
+
+// ========== UPDATE SALE STATUS ==========
+
+func TestUpdateSaleStatusTransitions(t *testing.T) {
+	reason := "customer request"
+
+	tests := []struct {
+		name         string
+		from         model.SaleStatus
+		to           string
+		wantErr      string
+		wantStatus   model.SaleStatus
+		wantUpdate   int
+		wantCancel   int
+		wantRestored int
+		wantEvent    bool
+	}{
+		{name: "pending to pending", from: model.SaleStatusPending, to: "pending", wantStatus: model.SaleStatusPending, wantUpdate: 1},
+		{name: "pending to completed", from: model.SaleStatusPending, to: "completed", wantStatus: model.SaleStatusCompleted, wantUpdate: 1, wantEvent: true},
+		{name: "pending to cancelled restores deducted stock", from: model.SaleStatusPending, to: "cancelled", wantStatus: model.SaleStatusCancelled, wantCancel: 1, wantRestored: 3, wantEvent: true},
+		{name: "completed to pending", from: model.SaleStatusCompleted, to: "pending", wantStatus: model.SaleStatusPending, wantUpdate: 1, wantEvent: true},
+		{name: "completed to completed", from: model.SaleStatusCompleted, to: "completed", wantStatus: model.SaleStatusCompleted, wantUpdate: 1},
+		{name: "completed to cancelled restores deducted stock", from: model.SaleStatusCompleted, to: "cancelled", wantStatus: model.SaleStatusCancelled, wantCancel: 1, wantRestored: 3, wantEvent: true},
+		{name: "cancelled to pending rejected", from: model.SaleStatusCancelled, to: "pending", wantErr: "cannot change status of a cancelled sale", wantStatus: model.SaleStatusCancelled, wantRestored: 3},
+		{name: "cancelled to completed rejected", from: model.SaleStatusCancelled, to: "completed", wantErr: "cannot change status of a cancelled sale", wantStatus: model.SaleStatusCancelled, wantRestored: 3},
+		{name: "cancelled to cancelled does not restore twice", from: model.SaleStatusCancelled, to: "cancelled", wantStatus: model.SaleStatusCancelled, wantCancel: 1, wantRestored: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.New()
+			repo := &fakeSaleRepo{
+				sale:     &model.Sale{BaseModel: model.BaseModel{ID: id}, Status: tt.from},
+				deducted: 3,
+			}
+			// Sale yang sudah cancelled stoknya sudah kembali
+			if tt.from == model.SaleStatusCancelled {
+				repo.restored = repo.deducted
+			}
+			svc, notifier := newTestSaleService(repo)
+
+			_, err := svc.UpdateSaleStatus(context.Background(), id, sale.UpdateSaleStatusRequest{Status: tt.to, Reason: &reason})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if repo.sale.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", repo.sale.Status, tt.wantStatus)
+			}
+			if repo.updateCalls != tt.wantUpdate {
+				t.Errorf("UpdateSaleStatus calls = %d, want %d", repo.updateCalls, tt.wantUpdate)
+			}
+			if repo.cancelCalls != tt.wantCancel {
+				t.Errorf("CancelSale calls = %d, want %d", repo.cancelCalls, tt.wantCancel)
+			}
+			if repo.restored != tt.wantRestored {
+				t.Errorf("restored stock = %d, want %d", repo.restored, tt.wantRestored)
+			}
+			if got := len(notifier.events) == 1; got != tt.wantEvent {
+				t.Errorf("status changed event emitted = %v, want %v", got, tt.wantEvent)
+			}
+		})
+	}
+}
+
+func TestUpdateSaleStatusErrors(t *testing.T) {
+	blank := "   "
+
+	tests := []struct {
+		name      string
+		missing   bool
+		req       sale.UpdateSaleStatusRequest
+		updateErr error
+		wantErr   string
+	}{
+		{name: "sale not found", missing: true, req: sale.UpdateSaleStatusRequest{Status: "completed"}, wantErr: "sale not found"},
+		{name: "cancel without reason", req: sale.UpdateSaleStatusRequest{Status: "cancelled"}, wantErr: "validation failed: reason is required when cancelling a sale"},
+		{name: "cancel with blank reason", req: sale.UpdateSaleStatusRequest{Status: "cancelled", Reason: &blank}, wantErr: "validation failed: reason is required when cancelling a sale"},
+		{
+			name:      "cancelled concurrently before lock",
+			req:       sale.UpdateSaleStatusRequest{Status: "completed"},
+			updateErr: fmt.Errorf("cannot change status of a cancelled sale"),
+			wantErr:   "cannot change status of a cancelled sale",
+		},
+		{
+			name:      "repository failure wrapped",
+			req:       sale.UpdateSaleStatusRequest{Status: "completed"},
+			updateErr: fmt.Errorf("update sale status failed: boom"),
+			wantErr:   "failed to update sale status: update sale status failed: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.New()
+			repo := &fakeSaleRepo{
+				sale:      &model.Sale{BaseModel: model.BaseModel{ID: id}, Status: model.SaleStatusPending},
+				updateErr: tt.updateErr,
+			}
+			if tt.missing {
+				repo.sale.ID = uuid.New()
+			}
+			svc, notifier := newTestSaleService(repo)
+
+			_, err := svc.UpdateSaleStatus(context.Background(), id, tt.req)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if repo.cancelCalls != 0 {
+				t.Errorf("CancelSale called %d times, want 0", repo.cancelCalls)
+			}
+			if len(notifier.events) != 0 {
+				t.Errorf("events emitted = %d, want 0", len(notifier.events))
+			}
+		})
+	}
+}
+
+func TestUpdateSaleStatusCancelReason(t *testing.T) {
+	id := uuid.New()
+	repo := &fakeSaleRepo{sale: &model.Sale{BaseModel: model.BaseModel{ID: id}, Status: model.SaleStatusCompleted}}
+	svc, _ := newTestSaleService(repo)
+
+	// Tanpa reason ditolak sebelum menyentuh repo
+	if _, err := svc.UpdateSaleStatus(context.Background(), id, sale.UpdateSaleStatusRequest{Status: "cancelled"}); err == nil {
+		t.Fatal("expected error when cancelling without reason")
+	}
+	if repo.cancelCalls != 0 || repo.sale.CancelledReason != nil {
+		t.Fatalf("cancel calls = %d reason = %v, want untouched", repo.cancelCalls, repo.sale.CancelledReason)
+	}
+
+	resp, err := svc.UpdateSaleStatus(context.Background(), id, sale.UpdateSaleStatusRequest{Status: "cancelled", Reason: stringPtr("  barang rusak  ")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Disimpan sudah di-trim
+	if repo.sale.CancelledReason == nil || *repo.sale.CancelledReason != "barang rusak" {
+		t.Errorf("stored reason = %v, want %q", repo.sale.CancelledReason, "barang rusak")
+	}
+	if resp.Status != string(model.SaleStatusCancelled) {
+		t.Errorf("status = %s, want cancelled", resp.Status)
+	}
+	if resp.CancelledReason == nil || *resp.CancelledReason != "barang rusak" {
+		t.Errorf("response reason = %v, want %q", resp.CancelledReason, "barang rusak")
+	}
+	if resp.CancelledAt == nil {
+		t.Error("response cancelled_at not set")
+	}
+}
+
+// ========== RECALCULATE TOTAL ==========
+
+func TestRecalculateTotal(t *testing.T) {
+	tests := []struct {
+		name              string
+		status            model.SaleStatus
+		total             float64
+		amountPaid        float64
+		paymentStatus     model.PaymentStatus
+		wantErr           string
+		wantChanged       bool
+		wantTotal         float64
+		wantPaymentStatus model.PaymentStatus
+	}{
+		{name: "inflated total now fully paid", status: model.SaleStatusCompleted, total: 999, amountPaid: 30, paymentStatus: model.PaymentStatusPartial, wantChanged: true, wantTotal: 30, wantPaymentStatus: model.PaymentStatusPaid},
+		{name: "deflated total no longer paid", status: model.SaleStatusCompleted, total: 20, amountPaid: 20, paymentStatus: model.PaymentStatusPaid, wantChanged: true, wantTotal: 30, wantPaymentStatus: model.PaymentStatusPartial},
+		{name: "consistent total untouched", status: model.SaleStatusPending, total: 30, amountPaid: 0, paymentStatus: model.PaymentStatusUnpaid, wantTotal: 30, wantPaymentStatus: model.PaymentStatusUnpaid},
+		{name: "cancelled sale rejected", status: model.SaleStatusCancelled, total: 999, paymentStatus: model.PaymentStatusUnpaid, wantErr: "cannot recalculate a cancelled sale"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSaleRepo{
+				sale: &model.Sale{
+					BaseModel: model.BaseModel{ID: uuid.New()}, Status: tt.status, TotalAmount: tt.total,
+					AmountPaid: tt.amountPaid, PaymentStatus: tt.paymentStatus,
+				},
+				itemTotal: 30,
+			}
+			svc, _ := newTestSaleService(repo)
+
+			resp, err := svc.RecalculateTotal(context.Background(), repo.sale.ID)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if repo.recalcCalls != 0 || repo.sale.TotalAmount != tt.total {
+					t.Errorf("recalc calls/total = %d/%v, want untouched", repo.recalcCalls, repo.sale.TotalAmount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Changed != tt.wantChanged || resp.PreviousTotal != tt.total {
+				t.Errorf("changed/previous = %v/%v, want %v/%v", resp.Changed, resp.PreviousTotal, tt.wantChanged, tt.total)
+			}
+			if resp.Sale.TotalAmount != tt.wantTotal || resp.Sale.PaymentStatus != string(tt.wantPaymentStatus) {
+				t.Errorf("sale = %v/%s, want %v/%s", resp.Sale.TotalAmount, resp.Sale.PaymentStatus, tt.wantTotal, tt.wantPaymentStatus)
+			}
+		})
+	}
+}
+
+// ========== CREATE SALE ==========
+
+func TestCreateSaleRejectsBeforeWriting(t *testing.T) {
+	available := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, StockQuantity: 5, UnitPrice: 10, Status: model.ProductStatusActive}
+	discontinued := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, StockQuantity: 5, Status: model.ProductStatusDiscontinued}
+
+	item := func(p *model.Product, quantity int) sale.SaleItemRequest {
+		return sale.SaleItemRequest{ProductID: p.ID.String(), Quantity: quantity}
+	}
+
+	tests := []struct {
+		name          string
+		items         []sale.SaleItemRequest
+		paymentMethod string
+		wantErr       string
+	}{
+		{name: "too many items", items: []sale.SaleItemRequest{item(available, 1), item(discontinued, 1), item(available, 1)}, wantErr: "too many items: sale cannot have more than 2 items"},
+		{name: "no items", items: nil, wantErr: "validation failed"},
+		{name: "duplicate product", items: []sale.SaleItemRequest{item(available, 1), item(available, 2)}, wantErr: "duplicate product " + available.ID.String()},
+		{name: "discontinued product", items: []sale.SaleItemRequest{item(discontinued, 1)}, wantErr: "product " + discontinued.ID.String() + " is discontinued"},
+		{name: "insufficient stock", items: []sale.SaleItemRequest{item(available, 6)}, wantErr: "insufficient stock for product " + available.ID.String()},
+		{name: "zero quantity", items: []sale.SaleItemRequest{item(available, 0)}, wantErr: "validation failed"},
+		{name: "unknown payment method", items: []sale.SaleItemRequest{item(available, 1)}, paymentMethod: "crypto", wantErr: "validation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repository.Repository{Sale: &fakeSaleRepo{}, Product: newFakeProductRepo(available, discontinued)}
+			svc := NewSaleService(repo, zap.NewNop(), &recordingNotifier{}, SaleOptions{MaxItems: 2})
+
+			// fakeSaleRepo tanpa CreateSale: sampai ke repo = panic, jadi semua kasus harus gagal sebelum menulis
+			_, err := svc.CreateSale(context.Background(), sale.CreateSaleRequest{Items: tt.items, PaymentMethod: tt.paymentMethod}, uuid.New())
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateSaleStockFailureFailsSale(t *testing.T) {
+	available := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, StockQuantity: 5, UnitPrice: 10, Status: model.ProductStatusActive}
+	items := []sale.SaleItemRequest{{ProductID: available.ID.String(), Quantity: 2}}
+
+	tests := []struct {
+		name      string
+		createErr error
+		wantErr   string
+	}{
+		{name: "stock taken concurrently", createErr: fmt.Errorf("insufficient stock for product %s", available.ID), wantErr: "insufficient stock for product " + available.ID.String()},
+		{name: "database failure", createErr: fmt.Errorf("deduct location stock failed: boom"), wantErr: "failed to create sale: deduct location stock failed: boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sales := &fakeSaleRepo{createErr: tt.createErr}
+			notifier := &recordingNotifier{}
+			repo := &repository.Repository{Sale: sales, Product: newFakeProductRepo(available)}
+			svc := NewSaleService(repo, zap.NewNop(), notifier, SaleOptions{})
+
+			_, err := svc.CreateSale(context.Background(), sale.CreateSaleRequest{Items: items}, uuid.New())
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if len(notifier.events) != 0 {
+				t.Errorf("events emitted = %d, want 0", len(notifier.events))
+			}
+		})
+	}
+}
+
+func TestCreateSaleItemNotes(t *testing.T) {
+	coffee := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, StockQuantity: 5, UnitPrice: 10, Status: model.ProductStatusActive}
+	tea := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, StockQuantity: 5, UnitPrice: 4, Status: model.ProductStatusActive}
+
+	t.Run("notes stored per item", func(t *testing.T) {
+		sales := &fakeSaleRepo{}
+		svc := NewSaleService(&repository.Repository{Sale: sales, Product: newFakeProductRepo(coffee, tea)}, zap.NewNop(), &recordingNotifier{}, SaleOptions{})
+
+		req := sale.CreateSaleRequest{Items: []sale.SaleItemRequest{
+			{ProductID: coffee.ID.String(), Quantity: 1, Notes: "damaged box, discounted"},
+			{ProductID: tea.ID.String(), Quantity: 2},
+		}}
+		if _, err := svc.CreateSale(context.Background(), req, uuid.New()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(sales.created) != 2 {
+			t.Fatalf("items = %d, want 2", len(sales.created))
+		}
+		if sales.created[0].Notes != "damaged box, discounted" || sales.created[1].Notes != "" {
+			t.Errorf("notes = %q/%q, want request notes per item", sales.created[0].Notes, sales.created[1].Notes)
+		}
+	})
+
+	t.Run("notes over 500 characters rejected", func(t *testing.T) {
+		sales := &fakeSaleRepo{}
+		svc := NewSaleService(&repository.Repository{Sale: sales, Product: newFakeProductRepo(coffee)}, zap.NewNop(), &recordingNotifier{}, SaleOptions{})
+
+		req := sale.CreateSaleRequest{Items: []sale.SaleItemRequest{
+			{ProductID: coffee.ID.String(), Quantity: 1, Notes: strings.Repeat("x", 501)},
+		}}
+		_, err := svc.CreateSale(context.Background(), req, uuid.New())
+		if err == nil || !strings.HasPrefix(err.Error(), "validation failed") {
+			t.Fatalf("error = %v, want validation failed", err)
+		}
+		if sales.created != nil {
+			t.Error("sale created with invalid notes")
+		}
+	})
+}
+
+// ========== REASSIGN ==========
+
+func TestReassignUser(t *testing.T) {
+	cashier := &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: model.RoleStaff, IsActive: true}
+	target := &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: model.RoleStaff, IsActive: true}
+	inactive := &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Role: model.RoleStaff, IsActive: false}
+	saleID := uuid.New()
+
+	tests := []struct {
+		name        string
+		saleID      uuid.UUID
+		userID      string
+		wantErr     string
+		wantUser    uuid.UUID
+		wantUpdates int
+	}{
+		{name: "reassigned to active user", saleID: saleID, userID: target.ID.String(), wantUser: target.ID, wantUpdates: 1},
+		{name: "same user is a no-op", saleID: saleID, userID: cashier.ID.String(), wantUser: cashier.ID},
+		{name: "unknown user", saleID: saleID, userID: uuid.NewString(), wantErr: "user not found"},
+		{name: "inactive user", saleID: saleID, userID: inactive.ID.String(), wantErr: "user is inactive"},
+		{name: "invalid user id", saleID: saleID, userID: "not-a-uuid", wantErr: "validation failed"},
+		{name: "missing sale", saleID: uuid.New(), userID: target.ID.String(), wantErr: "sale not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sales := &fakeSaleRepo{sale: &model.Sale{BaseModel: model.BaseModel{ID: saleID}, InvoiceNumber: "INV-1", UserID: cashier.ID, Status: model.SaleStatusCompleted}}
+			users := &fakeUserRepo{users: map[uuid.UUID]*model.User{cashier.ID: cashier, target.ID: target, inactive.ID: inactive}}
+			svc := NewSaleService(&repository.Repository{Sale: sales, User: users}, zap.NewNop(), &recordingNotifier{}, SaleOptions{})
+
+			resp, err := svc.ReassignUser(context.Background(), tt.saleID, sale.ReassignSaleRequest{UserID: tt.userID}, uuid.New())
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if sales.reassignCalls != 0 || sales.sale.UserID != cashier.ID {
+					t.Errorf("updates/user = %d/%s, want sale untouched", sales.reassignCalls, sales.sale.UserID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sales.reassignCalls != tt.wantUpdates || sales.sale.UserID != tt.wantUser {
+				t.Errorf("updates/user = %d/%s, want %d/%s", sales.reassignCalls, sales.sale.UserID, tt.wantUpdates, tt.wantUser)
+			}
+			if resp.Sale.UserID != tt.wantUser.String() || resp.PreviousUserID != cashier.ID.String() {
+				t.Errorf("user/previous = %s/%s, want %s/%s", resp.Sale.UserID, resp.PreviousUserID, tt.wantUser, cashier.ID)
+			}
+		})
+	}
+}
+
+// ========== DRY RUN ==========
+
+func TestDryRun(t *testing.T) {
+	coffee := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Coffee", StockQuantity: 5, UnitPrice: 12.5, Status: model.ProductStatusActive}
+	tea := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Tea", StockQuantity: 1, UnitPrice: 4, Status: model.ProductStatusActive}
+	retired := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Retired", StockQuantity: 9, UnitPrice: 7, Status: model.ProductStatusDiscontinued}
+	missing := uuid.New()
+
+	type itemResult struct {
+		available bool
+		reason    string
+		total     float64
+	}
+
+	tests := []struct {
+		name            string
+		items           []sale.SaleItemRequest
+		wantFulfillable bool
+		wantTotal       float64
+		want            []itemResult
+	}{
+		{
+			name:            "fulfillable basket",
+			items:           []sale.SaleItemRequest{{ProductID: coffee.ID.String(), Quantity: 2}, {ProductID: tea.ID.String(), Quantity: 1}},
+			wantFulfillable: true,
+			wantTotal:       29,
+			want:            []itemResult{{available: true, total: 25}, {available: true, total: 4}},
+		},
+		{
+			name: "unfulfillable basket reports every problem",
+			items: []sale.SaleItemRequest{
+				{ProductID: coffee.ID.String(), Quantity: 1},
+				{ProductID: tea.ID.String(), Quantity: 3},
+				{ProductID: retired.ID.String(), Quantity: 1},
+				{ProductID: missing.String(), Quantity: 1},
+			},
+			wantFulfillable: false,
+			wantTotal:       31.5,
+			want: []itemResult{
+				{available: true, total: 12.5},
+				{reason: "insufficient_stock", total: 12},
+				{reason: "discontinued", total: 7},
+				{reason: "not_found"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sales := &fakeSaleRepo{}
+			products := newFakeProductRepo(coffee, tea, retired)
+			svc := NewSaleService(&repository.Repository{Sale: sales, Product: products}, zap.NewNop(), &recordingNotifier{}, SaleOptions{})
+
+			resp, err := svc.DryRun(context.Background(), sale.CreateSaleRequest{Items: tt.items})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Fulfillable != tt.wantFulfillable || resp.TotalAmount != tt.wantTotal {
+				t.Errorf("fulfillable/total = %v/%v, want %v/%v", resp.Fulfillable, resp.TotalAmount, tt.wantFulfillable, tt.wantTotal)
+			}
+			if len(resp.Items) != len(tt.want) {
+				t.Fatalf("items = %d, want %d", len(resp.Items), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := resp.Items[i]
+				if got.Available != want.available || got.Reason != want.reason || got.TotalPrice != want.total {
+					t.Errorf("item %d = %+v, want %+v", i, got, want)
+				}
+			}
+
+			// Tidak ada yang disimpan / dipotong
+			if sales.sale != nil || sales.created != nil {
+				t.Error("dry run created a sale")
+			}
+			if products.stockUpdates != 0 || coffee.StockQuantity != 5 || tea.StockQuantity != 1 {
+				t.Errorf("stock updates/coffee/tea = %d/%d/%d, want untouched", products.stockUpdates, coffee.StockQuantity, tea.StockQuantity)
+			}
+		})
+	}
+}
+
+// ========== STATUS BREAKDOWN ==========
+
+func TestGetStatusBreakdown(t *testing.T) {
+	type statusCount struct {
+		status     string
+		count      int
+		percentage float64
+	}
+
+	tests := []struct {
+		name      string
+		counts    []model.SaleStatusCount
+		wantTotal int
+		want      []statusCount
+	}{
+		{
+			name:      "only completed",
+			counts:    []model.SaleStatusCount{{Status: model.SaleStatusCompleted, Count: 4}},
+			wantTotal: 4,
+			want:      []statusCount{{"pending", 0, 0}, {"completed", 4, 100}, {"cancelled", 0, 0}},
+		},
+		{
+			name:      "all statuses",
+			counts:    []model.SaleStatusCount{{Status: model.SaleStatusCancelled, Count: 1}, {Status: model.SaleStatusCompleted, Count: 1}, {Status: model.SaleStatusPending, Count: 1}},
+			wantTotal: 3,
+			want:      []statusCount{{"pending", 1, 33.33}, {"completed", 1, 33.33}, {"cancelled", 1, 33.33}},
+		},
+		{
+			name:      "no sales",
+			wantTotal: 0,
+			want:      []statusCount{{"pending", 0, 0}, {"completed", 0, 0}, {"cancelled", 0, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestSaleService(&fakeSaleRepo{statusCounts: tt.counts})
+
+			resp, err := svc.GetStatusBreakdown(context.Background(), sale.SaleStatusBreakdownRequest{StartDate: "2024-01-01", EndDate: "2024-01-31"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", resp.Total, tt.wantTotal)
+			}
+			if len(resp.Statuses) != len(tt.want) {
+				t.Fatalf("statuses = %+v, want %d entries", resp.Statuses, len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := resp.Statuses[i]
+				if got.Status != want.status || got.Count != want.count || got.Percentage != want.percentage {
+					t.Errorf("status %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetStatusBreakdownInvalidRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		wantErr    string
+	}{
+		{name: "missing end", start: "2024-01-01", wantErr: "validation failed"},
+		{name: "start after end", start: "2024-02-01", end: "2024-01-01", wantErr: "start date cannot be after end date"},
+		{name: "over a year", start: "2023-01-01", end: "2024-01-02", wantErr: "date range cannot exceed 1 year"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestSaleService(&fakeSaleRepo{})
+			_, err := svc.GetStatusBreakdown(context.Background(), sale.SaleStatusBreakdownRequest{StartDate: tt.start, EndDate: tt.end})
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateSaleCustomerOptional(t *testing.T) {
+	coffee := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, StockQuantity: 5, UnitPrice: 10, Status: model.ProductStatusActive}
+	items := []sale.SaleItemRequest{{ProductID: coffee.ID.String(), Quantity: 1}}
+
+	tests := []struct {
+		name      string
+		req       sale.CreateSaleRequest
+		wantName  *string
+		wantPhone *string
+	}{
+		{name: "walk-in without customer", req: sale.CreateSaleRequest{Items: items}},
+		{name: "blank customer treated as walk-in", req: sale.CreateSaleRequest{Items: items, CustomerName: "   ", CustomerPhone: " "}},
+		{name: "named customer", req: sale.CreateSaleRequest{Items: items, CustomerName: " Budi ", CustomerPhone: "0811"}, wantName: stringPtr("Budi"), wantPhone: stringPtr("0811")},
+		{name: "phone only", req: sale.CreateSaleRequest{Items: items, CustomerPhone: "0811"}, wantPhone: stringPtr("0811")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sales := &fakeSaleRepo{}
+			svc := NewSaleService(&repository.Repository{Sale: sales, Product: newFakeProductRepo(coffee)}, zap.NewNop(), &recordingNotifier{}, SaleOptions{})
+
+			resp, err := svc.CreateSale(context.Background(), tt.req, uuid.New())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if derefString(sales.sale.CustomerName) != derefString(tt.wantName) || (sales.sale.CustomerName == nil) != (tt.wantName == nil) {
+				t.Errorf("stored name = %v, want %v", sales.sale.CustomerName, tt.wantName)
+			}
+			if derefString(sales.sale.CustomerPhone) != derefString(tt.wantPhone) || (sales.sale.CustomerPhone == nil) != (tt.wantPhone == nil) {
+				t.Errorf("stored phone = %v, want %v", sales.sale.CustomerPhone, tt.wantPhone)
+			}
+			if derefString(resp.CustomerName) != derefString(tt.wantName) || derefString(resp.CustomerPhone) != derefString(tt.wantPhone) {
+				t.Errorf("response customer = %v/%v, want %v/%v", resp.CustomerName, resp.CustomerPhone, tt.wantName, tt.wantPhone)
+			}
+		})
+	}
+}
+
+// ========== REORDER ==========
+
+func TestReorder(t *testing.T) {
+	coffee := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, StockQuantity: 10, UnitPrice: 12, Status: model.ProductStatusActive}
+	tea := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, StockQuantity: 1, UnitPrice: 5, Status: model.ProductStatusActive}
+	retired := &model.Product{BaseModel: model.BaseModel{ID: uuid.New()}, StockQuantity: 10, UnitPrice: 7, Status: model.ProductStatusDiscontinued}
+
+	newSource := func(items ...model.SaleItem) *fakeSaleRepo {
+		return &fakeSaleRepo{
+			sale: &model.Sale{
+				BaseModel: model.BaseModel{ID: uuid.New()}, InvoiceNumber: "INV-OLD", Status: model.SaleStatusCompleted,
+				PaymentMethod: model.PaymentMethod("transfer"), CustomerName: stringPtr("Budi"),
+			},
+			items: items,
+		}
+	}
+
+	t.Run("new sale at current prices", func(t *testing.T) {
+		// Coffee tercatat dua baris (2 + 1) dengan harga lama 10
+		sales := newSource(
+			model.SaleItem{ProductID: coffee.ID, Quantity: 2, UnitPrice: 10},
+			model.SaleItem{ProductID: tea.ID, Quantity: 1, UnitPrice: 4},
+			model.SaleItem{ProductID: coffee.ID, Quantity: 1, UnitPrice: 10},
+		)
+		sourceID := sales.sale.ID
+		requester := uuid.New()
+		svc := NewSaleService(&repository.Repository{Sale: sales, Product: newFakeProductRepo(coffee, tea)}, zap.NewNop(), &recordingNotifier{}, SaleOptions{})
+
+		resp, err := svc.Reorder(context.Background(), sourceID, requester)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.ID == sourceID.String() || sales.sale.InvoiceNumber == "INV-OLD" || sales.sale.UserID != requester {
+			t.Errorf("sale = %s/%s/%s, want new sale for requester", resp.ID, sales.sale.InvoiceNumber, sales.sale.UserID)
+		}
+		if sales.sale.PaymentMethod != "transfer" || derefString(sales.sale.CustomerName) != "Budi" {
+			t.Errorf("payment/customer = %s/%v, want copied from source", sales.sale.PaymentMethod, sales.sale.CustomerName)
+		}
+		if len(sales.created) != 2 {
+			t.Fatalf("items = %d, want 2 merged items", len(sales.created))
+		}
+		if item := sales.created[0]; item.ProductID != coffee.ID || item.Quantity != 3 || item.UnitPrice != 12 {
+			t.Errorf("coffee item = %+v, want 3 at current price 12", item)
+		}
+		if sales.sale.TotalAmount != 41 {
+			t.Errorf("total = %v, want 41", sales.sale.TotalAmount)
+		}
+	})
+
+	t.Run("blocked by discontinued and out of stock products", func(t *testing.T) {
+		sales := newSource(
+			model.SaleItem{ProductID: retired.ID, Quantity: 1},
+			model.SaleItem{ProductID: tea.ID, Quantity: 2},
+			model.SaleItem{ProductID: coffee.ID, Quantity: 1},
+		)
+		svc := NewSaleService(&repository.Repository{Sale: sales, Product: newFakeProductRepo(coffee, tea, retired)}, zap.NewNop(), &recordingNotifier{}, SaleOptions{})
+
+		_, err := svc.Reorder(context.Background(), sales.sale.ID, uuid.New())
+		want := fmt.Sprintf("cannot reorder, unavailable products: %s (discontinued), %s (insufficient stock: 1 available, 2 needed)", retired.ID, tea.ID)
+		if err == nil || err.Error() != want {
+			t.Fatalf("error = %v, want %q", err, want)
+		}
+		if sales.created != nil || sales.sale.InvoiceNumber != "INV-OLD" {
+			t.Error("sale created on blocked reorder")
+		}
+	})
+}
+
+func TestNewSaleServiceDefaultMaxItems(t *testing.T) {
+	svc := NewSaleService(&repository.Repository{}, zap.NewNop(), nil, SaleOptions{}).(*saleService)
+	if svc.opts.MaxItems != defaultMaxSaleItems {
+		t.Errorf("MaxItems = %d, want %d", svc.opts.MaxItems, defaultMaxSaleItems)
+	}
+}
+
+// ========== PAYMENT ==========
+
+func TestUpdatePayment(t *testing.T) {
+	amount := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name       string
+		status     model.SaleStatus
+		amountPaid *float64
+		repoErr    error
+		wantErr    string
+		wantStatus model.PaymentStatus
+	}{
+		{name: "unpaid", status: model.SaleStatusCompleted, amountPaid: amount(0), wantStatus: model.PaymentStatusUnpaid},
+		{name: "partial", status: model.SaleStatusPending, amountPaid: amount(40), wantStatus: model.PaymentStatusPartial},
+		{name: "paid", status: model.SaleStatusCompleted, amountPaid: amount(100), wantStatus: model.PaymentStatusPaid},
+		{name: "rounded before compare", status: model.SaleStatusCompleted, amountPaid: amount(100.004), wantStatus: model.PaymentStatusPaid},
+		{name: "over total", status: model.SaleStatusCompleted, amountPaid: amount(100.5), wantErr: "amount paid cannot exceed total amount (100.00)"},
+		{name: "cancelled sale", status: model.SaleStatusCancelled, amountPaid: amount(10), wantErr: "cannot record payment for a cancelled sale"},
+		{name: "total lowered before write", status: model.SaleStatusCompleted, amountPaid: amount(100), repoErr: fmt.Errorf("amount paid cannot exceed total amount (90.00)"), wantErr: "amount paid cannot exceed total amount (90.00)"},
+		{name: "deleted before write", status: model.SaleStatusCompleted, amountPaid: amount(100), repoErr: fmt.Errorf("sale not found"), wantErr: "sale not found"},
+		{name: "missing amount", status: model.SaleStatusCompleted, wantErr: "validation failed"},
+		{name: "negative amount", status: model.SaleStatusCompleted, amountPaid: amount(-1), wantErr: "validation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.New()
+			repo := &fakeSaleRepo{sale: &model.Sale{BaseModel: model.BaseModel{ID: id}, Status: tt.status, TotalAmount: 100, PaymentStatus: model.PaymentStatusUnpaid}, paymentErr: tt.repoErr}
+			svc, _ := newTestSaleService(repo)
+
+			resp, err := svc.UpdatePayment(context.Background(), id, sale.UpdateSalePaymentRequest{AmountPaid: tt.amountPaid})
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.PaymentStatus != string(tt.wantStatus) {
+				t.Errorf("payment_status = %s, want %s", resp.PaymentStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPaymentStatusFor(t *testing.T) {
+	tests := []struct {
+		paid, total float64
+		want        model.PaymentStatus
+	}{
+		{paid: 0, total: 100, want: model.PaymentStatusUnpaid},
+		{paid: 0.01, total: 100, want: model.PaymentStatusPartial},
+		{paid: 100, total: 100, want: model.PaymentStatusPaid},
+		{paid: 0, total: 0, want: model.PaymentStatusPaid},
+	}
+
+	for _, tt := range tests {
+		if got := paymentStatusFor(tt.paid, tt.total); got != tt.want {
+			t.Errorf("paymentStatusFor(%v, %v) = %s, want %s", tt.paid, tt.total, got, tt.want)
+		}
+	}
+}
+
+// ========== LIST FILTER ==========
+
+func TestBuildSaleListFilter(t *testing.T) {
+	amount := func(v float64) *float64 { return &v }
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		req     sale.SaleListRequest
+		wantErr string
+		check   func(t *testing.T, f repository.SaleListFilter)
+	}{
+		{
+			name: "empty",
+			check: func(t *testing.T, f repository.SaleListFilter) {
+				if f.Status != nil || f.StartDate != nil || f.EndDate != nil || *f.UserID != userID {
+					t.Errorf("filter = %+v", f)
+				}
+			},
+		},
+		{
+			name: "all filters",
+			req:  sale.SaleListRequest{Status: "completed", StartDate: "2026-01-01", EndDate: "2026-01-31", MinAmount: amount(10), MaxAmount: amount(50)},
+			check: func(t *testing.T, f repository.SaleListFilter) {
+				if *f.Status != model.SaleStatusCompleted || !f.StartDate.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) ||
+					!f.EndDate.Equal(time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)) || *f.MinAmount != 10 || *f.MaxAmount != 50 {
+					t.Errorf("filter = %+v", f)
+				}
+			},
+		},
+		{name: "same day range", req: sale.SaleListRequest{StartDate: "2026-01-01", EndDate: "2026-01-01"}},
+		{name: "start after end", req: sale.SaleListRequest{StartDate: "2026-02-01", EndDate: "2026-01-01"}, wantErr: "start date cannot be after end date"},
+		{name: "min above max", req: sale.SaleListRequest{MinAmount: amount(50), MaxAmount: amount(10)}, wantErr: "invalid amount range"},
+		{name: "unknown status", req: sale.SaleListRequest{Status: "refunded"}, wantErr: "validation failed"},
+		{name: "negative amount", req: sale.SaleListRequest{MinAmount: amount(-1)}, wantErr: "validation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := buildSaleListFilter(&userID, tt.req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, filter)
+			}
+		})
+	}
+}
+
+// ========== MY DAILY SALES ==========
+
+func TestGetMyDailySales(t *testing.T) {
+	me, other := uuid.New(), uuid.New()
+	date := func(day int) time.Time { return time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC) }
+	repo := &fakeSaleRepo{daily: map[uuid.UUID][]model.DailySales{
+		me: {
+			{Date: date(2), SalesCount: 2, ItemsSold: 5, Revenue: 40.005},
+			{Date: date(4), SalesCount: 1, ItemsSold: 1, Revenue: 10},
+		},
+		other: {
+			{Date: date(1), SalesCount: 9, ItemsSold: 9, Revenue: 900},
+			{Date: date(3), SalesCount: 7, ItemsSold: 7, Revenue: 700},
+		},
+	}}
+	svc, _ := newTestSaleService(repo)
+
+	resp, err := svc.GetMyDailySales(context.Background(), me, sale.MyDailySalesRequest{StartDate: "2024-03-01", EndDate: "2024-03-05"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.UserID != me.String() {
+		t.Errorf("user = %s, want %s", resp.UserID, me)
+	}
+
+	// Semua hari muncul (endDate inclusive), hari tanpa penjualan = 0, penjualan kasir lain tidak ikut
+	want := []sale.DailySalesResponse{
+		{Date: "2024-03-01"},
+		{Date: "2024-03-02", SalesCount: 2, ItemsSold: 5, Revenue: 40.01},
+		{Date: "2024-03-03"},
+		{Date: "2024-03-04", SalesCount: 1, ItemsSold: 1, Revenue: 10},
+		{Date: "2024-03-05"},
+	}
+	if len(resp.Days) != len(want) {
+		t.Fatalf("days = %+v, want %d days", resp.Days, len(want))
+	}
+	for i := range want {
+		if resp.Days[i] != want[i] {
+			t.Errorf("days[%d] = %+v, want %+v", i, resp.Days[i], want[i])
+		}
+	}
+
+	// Kasir tanpa penjualan tetap dapat series penuh berisi 0
+	resp, err = svc.GetMyDailySales(context.Background(), uuid.New(), sale.MyDailySalesRequest{StartDate: "2024-03-01", EndDate: "2024-03-03"})
+	if err != nil || len(resp.Days) != 3 {
+		t.Fatalf("days/err = %v/%v, want 3 empty days", resp, err)
+	}
+	for _, day := range resp.Days {
+		if day.SalesCount != 0 || day.Revenue != 0 {
+			t.Errorf("day %s = %+v, want zero", day.Date, day)
+		}
+	}
+}
+
+// ========== SALE ITEM LEDGER ==========
+
+func TestGetSaleItemLedger(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 15, 0, 0, 0, time.UTC) }
+	repo := &fakeSaleRepo{}
+	for d := 5; d >= 1; d-- {
+		repo.ledger = append(repo.ledger, model.SaleItemLedgerEntry{
+			ID: uuid.New(), InvoiceNumber: fmt.Sprintf("INV-%d", d), ProductName: "Coffee",
+			Quantity: d, UnitPrice: 10, TotalPrice: float64(d * 10), SoldAt: day(d),
+		})
+	}
+	svc, _ := newTestSaleService(repo)
+
+	invoices := func(rows []sale.SaleItemLedgerResponse) string {
+		var names []string
+		for _, r := range rows {
+			names = append(names, r.InvoiceNumber)
+		}
+		return strings.Join(names, ",")
+	}
+
+	tests := []struct {
+		name        string
+		req         sale.SaleItemLedgerRequest
+		page, limit int
+		want        string
+		wantTotal   int
+		wantPages   int
+		wantOffset  int
+	}{
+		{name: "first page", page: 1, limit: 2, want: "INV-5,INV-4", wantTotal: 5, wantPages: 3},
+		{name: "second page", page: 2, limit: 2, want: "INV-3,INV-2", wantTotal: 5, wantPages: 3, wantOffset: 2},
+		{name: "past last page", page: 4, limit: 2, want: "", wantTotal: 5, wantPages: 3, wantOffset: 6},
+		{name: "date range inclusive", req: sale.SaleItemLedgerRequest{StartDate: "2024-03-02", EndDate: "2024-03-04"}, page: 1, limit: 10, want: "INV-4,INV-3,INV-2", wantTotal: 3, wantPages: 1},
+		{name: "start date only", req: sale.SaleItemLedgerRequest{StartDate: "2024-03-04"}, page: 1, limit: 10, want: "INV-5,INV-4", wantTotal: 2, wantPages: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, pagination, err := svc.GetSaleItemLedger(context.Background(), tt.req, tt.page, tt.limit)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := invoices(rows); got != tt.want {
+				t.Errorf("invoices = %q, want %q", got, tt.want)
+			}
+			if pagination.Total != tt.wantTotal || pagination.TotalPages != tt.wantPages {
+				t.Errorf("total/pages = %d/%d, want %d/%d", pagination.Total, pagination.TotalPages, tt.wantTotal, tt.wantPages)
+			}
+			if repo.ledgerFilter.offset != tt.wantOffset || repo.ledgerFilter.limit != tt.limit {
+				t.Errorf("limit/offset = %d/%d, want %d/%d", repo.ledgerFilter.limit, repo.ledgerFilter.offset, tt.limit, tt.wantOffset)
+			}
+		})
+	}
+
+	t.Run("line fields", func(t *testing.T) {
+		rows, _, err := svc.GetSaleItemLedger(context.Background(), sale.SaleItemLedgerRequest{StartDate: "2024-03-03", EndDate: "2024-03-03"}, 1, 10)
+		if err != nil || len(rows) != 1 {
+			t.Fatalf("rows/err = %v/%v, want one line", rows, err)
+		}
+		if r := rows[0]; r.InvoiceNumber != "INV-3" || r.ProductName != "Coffee" || r.Quantity != 3 || r.UnitPrice != 10 || r.TotalPrice != 30 {
+			t.Errorf("line = %+v, want INV-3 Coffee 3 x 10 = 30", r)
+		}
+	})
+
+	invalid := []struct {
+		name    string
+		req     sale.SaleItemLedgerRequest
+		wantErr string
+	}{
+		{name: "start after end", req: sale.SaleItemLedgerRequest{StartDate: "2024-03-05", EndDate: "2024-03-01"}, wantErr: "start date cannot be after end date"},
+		{name: "bad date format", req: sale.SaleItemLedgerRequest{StartDate: "03/01/2024"}, wantErr: "validation failed"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.ledgerFilter = nil
+			_, _, err := svc.GetSaleItemLedger(context.Background(), tt.req, 1, 10)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if repo.ledgerFilter != nil {
+				t.Error("repository queried on invalid range")
+			}
+		})
+	}
+}
+
+func TestSaleItemTotal(t *testing.T) {
+	defer utils.SetMoneyConfig(utils.MoneyConfig{Currency: "IDR", DecimalPlaces: 2})
+
+	tests := []struct {
+		decimals  int
+		unitPrice float64
+		quantity  int
+		want      float64
+	}{
+		{decimals: 2, unitPrice: 0.335, quantity: 3, want: 1.01},
+		{decimals: 2, unitPrice: 19.99, quantity: 2, want: 39.98},
+		{decimals: 0, unitPrice: 1500.5, quantity: 1, want: 1501},
+	}
+
+	for _, tt := range tests {
+		utils.SetMoneyConfig(utils.MoneyConfig{DecimalPlaces: tt.decimals})
+		if got := saleItemTotal(tt.unitPrice, tt.quantity); got != tt.want {
+			t.Errorf("saleItemTotal(%v, %d) = %v, want %v", tt.unitPrice, tt.quantity, got, tt.want)
+		}
+	}
+}
@@ -7,25 +7,40 @@ import (
 )
 
 type Service struct {
-	Auth      AuthService
-	User      UserService
-	Warehouse WarehouseService
-	Category  CategoryService
-	Shelf     ShelfService
-	Product   ProductService
-	Sale      SaleService
-	Report    ReportService
+	Auth          AuthService
+	User          UserService
+	Warehouse     WarehouseService
+	Category      CategoryService
+	Shelf         ShelfService
+	Product       ProductService
+	Sale          SaleService
+	Report        ReportService
+	Dashboard     DashboardService
+	Webhook       WebhookService
+	Replenishment ReplenishmentService
+	Alert         AlertService
 }
 
-func NewService(repo *repository.Repository, log *zap.Logger) *Service {
+// notifier nil = no-op (tidak ada notifikasi)
+func NewService(repo *repository.Repository, log *zap.Logger, notifier Notifier, productOpts ProductOptions, saleOpts SaleOptions, reportOpts ReportOptions, authOpts AuthOptions) *Service {
+	if notifier == nil {
+		notifier = NewNoopNotifier()
+	}
+
+	authService := NewAuthService(repo, log, authOpts)
+
 	return &Service{
-		Auth:      NewAuthService(repo, log),
-		User:      NewUserService(repo, log),
-		Warehouse: NewWarehouseService(repo, log),
-		Category:  NewCategoryService(repo, log),
-		Shelf:     NewShelfService(repo, log),
-		Product:   NewProductService(repo, log),
-		Sale:      NewSaleService(repo, log),
-		Report:    NewReportService(repo, log),
+		Auth:          authService,
+		User:          NewUserService(repo, log, authService),
+		Warehouse:     NewWarehouseService(repo, log),
+		Category:      NewCategoryService(repo, log),
+		Shelf:         NewShelfService(repo, log),
+		Product:       NewProductService(repo, log, notifier, productOpts),
+		Sale:          NewSaleService(repo, log, notifier, saleOpts),
+		Report:        NewReportService(repo, log, reportOpts),
+		Dashboard:     NewDashboardService(repo, log),
+		Webhook:       NewWebhookService(repo, log),
+		Replenishment: NewReplenishmentService(repo, log),
+		Alert:         NewAlertService(repo, log),
 	}
 }
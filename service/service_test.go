@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/model"
+	"inventory-system/repository"
+
+	"github.com/google/uuid"
+)
+
+type fakeCategoryRepo struct {
+	repository.CategoryRepo
+	categories map[uuid.UUID]*model.Category
+}
+
+func (f *fakeCategoryRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
+	if c, ok := f.categories[id]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("category not found")
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
@@ -7,6 +7,7 @@ import (
 	"inventory-system/model"
 	"inventory-system/repository"
 	"inventory-system/utils"
+	"strings"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -15,8 +16,10 @@ import (
 type ShelfService interface {
 	Create(ctx context.Context, req shelf.CreateShelfRequest) (*shelf.ShelfResponse, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*shelf.ShelfResponse, error)
-	FindAll(ctx context.Context, page int, limit int) ([]shelf.ShelfResponse, utils.Pagination, error)
+	FindAll(ctx context.Context, page int, limit int, includeDeleted bool) ([]shelf.ShelfResponse, utils.Pagination, error)
 	FindByWarehouseID(ctx context.Context, warehouseID uuid.UUID) ([]shelf.ShelfResponse, error)
+	FindByWarehouseIDPaginated(ctx context.Context, warehouseID uuid.UUID, page int, limit int, search string) ([]shelf.ShelfResponse, utils.Pagination, error)
+	BulkCreate(ctx context.Context, warehouseID uuid.UUID, req shelf.BulkCreateShelfRequest) ([]shelf.ShelfResponse, error)
 	Update(ctx context.Context, id uuid.UUID, req shelf.UpdateShelfRequest) (*shelf.ShelfResponse, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -46,28 +49,31 @@ func (ss *shelfService) Create(ctx context.Context, req shelf.CreateShelfRequest
 		return nil, fmt.Errorf("warehouse not found")
 	}
 
+	// Check code uniqueness within warehouse
+	if existing, _ := ss.repo.Shelf.FindByWarehouseAndCode(ctx, warehouseID, req.Code); existing != nil {
+		return nil, fmt.Errorf("shelf code already exists in warehouse")
+	}
+
 	// prepare warehouse object
 	newShelf := &model.Shelf{
 		WarehouseID: warehouseID,
+		Code:        req.Code,
 		Name:        req.Name,
 	}
 
 	// Save to database
 	if err := ss.repo.Shelf.Create(ctx, newShelf); err != nil {
-		ss.log.Error("Failed to create shelf", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to create shelf", zap.Error(err))
+		if strings.Contains(err.Error(), "already exists") {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to create shelf")
 	}
 
 	// prepare response
-	response := &shelf.ShelfResponse{
-		ID:          newShelf.ID.String(),
-		WarehouseID: newShelf.WarehouseID.String(),
-		Name:        newShelf.Name,
-		CreatedAt:   newShelf.CreatedAt,
-		UpdatedAt:   newShelf.UpdatedAt,
-	}
+	response := ss.convertToResponse(newShelf)
 
-	ss.log.Info("Shelf created",
+	utils.LoggerFromContext(ctx).Info("Shelf created",
 		zap.String("shelf_id", newShelf.ID.String()),
 		zap.String("warehouse_id", newShelf.WarehouseID.String()))
 	return response, nil
@@ -79,27 +85,28 @@ func (ss *shelfService) FindByID(ctx context.Context, id uuid.UUID) (*shelf.Shel
 		return nil, fmt.Errorf("shelf not found")
 	}
 
-	return &shelf.ShelfResponse{
-		ID:          foundShelf.ID.String(),
-		WarehouseID: foundShelf.WarehouseID.String(),
-		Name:        foundShelf.Name,
-		CreatedAt:   foundShelf.CreatedAt,
-		UpdatedAt:   foundShelf.UpdatedAt,
-	}, nil
+	// Single shelf cukup pakai count langsung
+	count, err := ss.repo.Shelf.CountProducts(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count shelf products")
+	}
+	foundShelf.ProductCount = count
+
+	return ss.convertToResponse(foundShelf), nil
 }
 
-func (ss *shelfService) FindAll(ctx context.Context, page int, limit int) ([]shelf.ShelfResponse, utils.Pagination, error) {
+func (ss *shelfService) FindAll(ctx context.Context, page int, limit int, includeDeleted bool) ([]shelf.ShelfResponse, utils.Pagination, error) {
 	// Setup pagination
 	pagination := utils.NewPagination(page, limit)
 
 	// Get data with pagination
-	shelves, err := ss.repo.Shelf.FindAll(ctx, pagination.Limit, pagination.Offset())
+	shelves, err := ss.repo.Shelf.FindAll(ctx, pagination.Limit, pagination.Offset(), includeDeleted)
 	if err != nil {
 		return nil, pagination, fmt.Errorf("failed to get shelves")
 	}
 
 	// Get total count
-	total, err := ss.repo.Shelf.CountAll(ctx)
+	total, err := ss.repo.Shelf.CountAll(ctx, includeDeleted)
 	if err != nil {
 		return nil, pagination, fmt.Errorf("failed to count shelves")
 	}
@@ -113,7 +120,7 @@ func (ss *shelfService) FindAll(ctx context.Context, page int, limit int) ([]she
 		responses = append(responses, *ss.convertToResponse(&s))
 	}
 
-	ss.log.Info("Shelves fetched with pagination",
+	utils.LoggerFromContext(ctx).Info("Shelves fetched with pagination",
 		zap.Int("page", page),
 		zap.Int("limit", limit),
 		zap.Int("total", total))
@@ -136,15 +143,98 @@ func (ss *shelfService) FindByWarehouseID(ctx context.Context, warehouseID uuid.
 	// Convert to response
 	var responses []shelf.ShelfResponse
 	for _, s := range shelves {
-		responses = append(responses, shelf.ShelfResponse{
-			ID:          s.ID.String(),
-			WarehouseID: s.WarehouseID.String(),
-			Name:        s.Name,
-			CreatedAt:   s.CreatedAt,
-			UpdatedAt:   s.UpdatedAt,
+		responses = append(responses, *ss.convertToResponse(&s))
+	}
+
+	return responses, nil
+}
+
+// FindByWarehouseIDPaginated - list rak di warehouse dengan pagination & filter nama
+func (ss *shelfService) FindByWarehouseIDPaginated(ctx context.Context, warehouseID uuid.UUID, page int, limit int, search string) ([]shelf.ShelfResponse, utils.Pagination, error) {
+	// Setup pagination
+	pagination := utils.NewPagination(page, limit)
+
+	// Check warehouse exists
+	if _, err := ss.repo.Warehouse.FindByID(ctx, warehouseID); err != nil {
+		return nil, pagination, fmt.Errorf("warehouse not found")
+	}
+
+	search = strings.TrimSpace(search)
+
+	shelves, err := ss.repo.Shelf.FindByWarehouseIDPaginated(ctx, warehouseID, search, pagination.Limit, pagination.Offset())
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to get shelves")
+	}
+
+	total, err := ss.repo.Shelf.CountByWarehouseID(ctx, warehouseID, search)
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to count shelves")
+	}
+	pagination.SetTotal(total)
+
+	// Convert to response (empty list = [], bukan null)
+	responses := make([]shelf.ShelfResponse, 0, len(shelves))
+	for _, s := range shelves {
+		responses = append(responses, *ss.convertToResponse(&s))
+	}
+
+	return responses, pagination, nil
+}
+
+// BulkCreate - buat banyak rak sekaligus di satu warehouse (transaction)
+func (ss *shelfService) BulkCreate(ctx context.Context, warehouseID uuid.UUID, req shelf.BulkCreateShelfRequest) ([]shelf.ShelfResponse, error) {
+	// Validate input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Check warehouse exists (sekali saja)
+	if _, err := ss.repo.Warehouse.FindByID(ctx, warehouseID); err != nil {
+		return nil, fmt.Errorf("warehouse not found")
+	}
+
+	// Kode yang sudah dipakai di warehouse ini
+	existing, err := ss.repo.Shelf.FindByWarehouseID(ctx, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shelves")
+	}
+	usedCodes := make(map[string]bool, len(existing)+len(req.Shelves))
+	for _, s := range existing {
+		usedCodes[s.Code] = true
+	}
+
+	// Reject duplikat di dalam request maupun yang sudah ada di DB
+	newShelves := make([]model.Shelf, 0, len(req.Shelves))
+	for _, item := range req.Shelves {
+		if usedCodes[item.Code] {
+			return nil, fmt.Errorf("shelf code already exists in warehouse: %s", item.Code)
+		}
+		usedCodes[item.Code] = true
+
+		newShelves = append(newShelves, model.Shelf{
+			WarehouseID: warehouseID,
+			Code:        item.Code,
+			Name:        item.Name,
 		})
 	}
 
+	// Save all in one transaction
+	if err := ss.repo.Shelf.CreateBatch(ctx, newShelves); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to bulk create shelves", zap.Error(err))
+		if strings.Contains(err.Error(), "already exists") {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create shelves")
+	}
+
+	responses := make([]shelf.ShelfResponse, 0, len(newShelves))
+	for _, s := range newShelves {
+		responses = append(responses, *ss.convertToResponse(&s))
+	}
+
+	utils.LoggerFromContext(ctx).Info("Shelves bulk created",
+		zap.String("warehouse_id", warehouseID.String()),
+		zap.Int("count", len(newShelves)))
 	return responses, nil
 }
 
@@ -154,7 +244,13 @@ func (ss *shelfService) Update(ctx context.Context, id uuid.UUID, req shelf.Upda
 		return nil, fmt.Errorf("shelf not found")
 	}
 
+	// Validate input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
 	updated := false
+	codeChanged := false
 
 	// Check and update warehouse ID if provided
 	if req.WarehouseID != nil {
@@ -171,6 +267,20 @@ func (ss *shelfService) Update(ctx context.Context, id uuid.UUID, req shelf.Upda
 		if warehouseIDStr != shelfToUpdate.WarehouseID {
 			shelfToUpdate.WarehouseID = warehouseIDStr
 			updated = true
+			codeChanged = true // kode harus unik di warehouse tujuan
+		}
+	}
+
+	if req.Code != nil && *req.Code != shelfToUpdate.Code {
+		shelfToUpdate.Code = *req.Code
+		updated = true
+		codeChanged = true
+	}
+
+	// Check code uniqueness within (new) warehouse
+	if codeChanged {
+		if existing, _ := ss.repo.Shelf.FindByWarehouseAndCode(ctx, shelfToUpdate.WarehouseID, shelfToUpdate.Code); existing != nil && existing.ID != shelfToUpdate.ID {
+			return nil, fmt.Errorf("shelf code already exists in warehouse")
 		}
 	}
 
@@ -182,17 +292,19 @@ func (ss *shelfService) Update(ctx context.Context, id uuid.UUID, req shelf.Upda
 	// Save if change were made
 	if updated {
 		if err := ss.repo.Shelf.Update(ctx, shelfToUpdate); err != nil {
-			return nil, fmt.Errorf("failed to update warehouse")
+			if strings.Contains(err.Error(), "already exists") {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to update shelf")
 		}
 	}
 
-	return &shelf.ShelfResponse{
-		ID:          shelfToUpdate.ID.String(),
-		WarehouseID: shelfToUpdate.WarehouseID.String(),
-		Name:        shelfToUpdate.Name,
-		CreatedAt:   shelfToUpdate.CreatedAt,
-		UpdatedAt:   shelfToUpdate.UpdatedAt,
-	}, nil
+	// Isi jumlah produk supaya response konsisten dengan FindByID
+	if count, err := ss.repo.Shelf.CountProducts(ctx, id); err == nil {
+		shelfToUpdate.ProductCount = count
+	}
+
+	return ss.convertToResponse(shelfToUpdate), nil
 }
 
 func (ss *shelfService) Delete(ctx context.Context, id uuid.UUID) error {
@@ -204,16 +316,19 @@ func (ss *shelfService) Delete(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("failed to delete shelf")
 	}
 
-	ss.log.Info("Shelf deleted", zap.String("shelf_id", id.String()))
+	utils.LoggerFromContext(ctx).Info("Shelf deleted", zap.String("shelf_id", id.String()))
 	return nil
 }
 
 func (ss *shelfService) convertToResponse(s *model.Shelf) *shelf.ShelfResponse {
 	return &shelf.ShelfResponse{
-		ID:          s.ID.String(),
-		WarehouseID: s.WarehouseID.String(),
-		Name:        s.Name,
-		CreatedAt:   s.CreatedAt,
-		UpdatedAt:   s.UpdatedAt,
+		ID:           s.ID.String(),
+		WarehouseID:  s.WarehouseID.String(),
+		Code:         s.Code,
+		Name:         s.Name,
+		ProductCount: s.ProductCount,
+		CreatedAt:    s.CreatedAt,
+		UpdatedAt:    s.UpdatedAt,
+		DeletedAt:    s.DeletedAt,
 	}
 }
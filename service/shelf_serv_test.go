@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/dto/shelf"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKES ==========
+
+// fakeShelfStore - fakeShelfRepo plus list per warehouse & batch insert
+type fakeShelfStore struct {
+	fakeShelfRepo
+	batchErr    error
+	batches     [][]model.Shelf
+	search      string
+	limit       int
+	offset      int
+	countSearch string
+}
+
+func (f *fakeShelfStore) FindByWarehouseID(ctx context.Context, warehouseID uuid.UUID) ([]model.Shelf, error) {
+	var result []model.Shelf
+	for _, s := range f.shelves {
+		if s.WarehouseID == warehouseID {
+			result = append(result, *s)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeShelfStore) FindByWarehouseIDPaginated(ctx context.Context, warehouseID uuid.UUID, search string, limit int, offset int) ([]model.Shelf, error) {
+	f.search, f.limit, f.offset = search, limit, offset
+	return nil, nil
+}
+
+func (f *fakeShelfStore) CountByWarehouseID(ctx context.Context, warehouseID uuid.UUID, search string) (int, error) {
+	f.countSearch = search
+	return 25, nil
+}
+
+func (f *fakeShelfStore) CreateBatch(ctx context.Context, shelves []model.Shelf) error {
+	if f.batchErr != nil {
+		return f.batchErr
+	}
+	f.batches = append(f.batches, shelves)
+	return nil
+}
+
+// ========== BULK CREATE ==========
+
+func TestShelfBulkCreate(t *testing.T) {
+	wh := newWarehouse("WH-01", true)
+	otherWh := newWarehouse("WH-02", true)
+	existing := &model.Shelf{BaseModel: model.BaseModel{ID: uuid.New()}, WarehouseID: wh.ID, Code: "A-01", Name: "Rak A1"}
+	// Kode yang sama di warehouse lain tidak bentrok
+	elsewhere := &model.Shelf{BaseModel: model.BaseModel{ID: uuid.New()}, WarehouseID: otherWh.ID, Code: "B-01", Name: "Rak B1"}
+
+	items := func(codes ...string) shelf.BulkCreateShelfRequest {
+		req := shelf.BulkCreateShelfRequest{}
+		for _, code := range codes {
+			req.Shelves = append(req.Shelves, shelf.BulkShelfItem{Code: code, Name: "Rak " + code})
+		}
+		return req
+	}
+
+	tests := []struct {
+		name        string
+		warehouseID uuid.UUID
+		req         shelf.BulkCreateShelfRequest
+		batchErr    error
+		wantErr     string
+		wantCodes   []string
+	}{
+		{name: "creates all in one batch", warehouseID: wh.ID, req: items("A-02", "B-01"), wantCodes: []string{"A-02", "B-01"}},
+		{name: "code already in warehouse", warehouseID: wh.ID, req: items("A-02", "A-01"), wantErr: "shelf code already exists in warehouse: A-01"},
+		{name: "duplicate inside request", warehouseID: wh.ID, req: items("C-01", "C-01"), wantErr: "shelf code already exists in warehouse: C-01"},
+		{name: "unknown warehouse", warehouseID: uuid.New(), req: items("A-02"), wantErr: "warehouse not found"},
+		{name: "empty request", warehouseID: wh.ID, req: shelf.BulkCreateShelfRequest{}, wantErr: "validation failed"},
+		{name: "concurrent insert conflict", warehouseID: wh.ID, req: items("D-01"), batchErr: fmt.Errorf("shelf code already exists in warehouse: D-01"), wantErr: "shelf code already exists in warehouse: D-01"},
+		{name: "repository failure", warehouseID: wh.ID, req: items("D-01"), batchErr: fmt.Errorf("tx aborted"), wantErr: "failed to create shelves"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shelves := &fakeShelfStore{
+				fakeShelfRepo: fakeShelfRepo{shelves: map[uuid.UUID]*model.Shelf{existing.ID: existing, elsewhere.ID: elsewhere}},
+				batchErr:      tt.batchErr,
+			}
+			repo := &repository.Repository{Shelf: shelves, Warehouse: newWarehouseStore(wh, otherWh)}
+			svc := NewShelfService(repo, zap.NewNop())
+
+			resp, err := svc.BulkCreate(context.Background(), tt.warehouseID, tt.req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if len(shelves.batches) != 0 {
+					t.Error("batch written on rejected request")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(shelves.batches) != 1 || len(shelves.batches[0]) != len(tt.wantCodes) {
+				t.Fatalf("batches = %v, want one batch of %d", shelves.batches, len(tt.wantCodes))
+			}
+			for i, code := range tt.wantCodes {
+				if resp[i].Code != code || shelves.batches[0][i].WarehouseID != tt.warehouseID {
+					t.Errorf("shelf[%d] = %+v, want code %s in warehouse", i, resp[i], code)
+				}
+			}
+		})
+	}
+}
+
+// ========== LIST BY WAREHOUSE ==========
+
+func TestShelfFindByWarehouseIDPaginated(t *testing.T) {
+	wh := newWarehouse("WH-01", true)
+
+	tests := []struct {
+		name        string
+		warehouseID uuid.UUID
+		page, limit int
+		search      string
+		wantErr     string
+		wantSearch  string
+		wantOffset  int
+	}{
+		{name: "first page", warehouseID: wh.ID, page: 1, limit: 10},
+		{name: "search trimmed and offset", warehouseID: wh.ID, page: 3, limit: 5, search: "  rak a ", wantSearch: "rak a", wantOffset: 10},
+		{name: "unknown warehouse", warehouseID: uuid.New(), page: 1, limit: 10, wantErr: "warehouse not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shelves := &fakeShelfStore{}
+			repo := &repository.Repository{Shelf: shelves, Warehouse: newWarehouseStore(wh)}
+			svc := NewShelfService(repo, zap.NewNop())
+
+			resp, pagination, err := svc.FindByWarehouseIDPaginated(context.Background(), tt.warehouseID, tt.page, tt.limit, tt.search)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp == nil {
+				t.Error("empty result must be [], not nil")
+			}
+			if shelves.search != tt.wantSearch || shelves.countSearch != tt.wantSearch {
+				t.Errorf("search = %q / %q, want %q", shelves.search, shelves.countSearch, tt.wantSearch)
+			}
+			if shelves.limit != tt.limit || shelves.offset != tt.wantOffset {
+				t.Errorf("limit/offset = %d/%d, want %d/%d", shelves.limit, shelves.offset, tt.limit, tt.wantOffset)
+			}
+			if pagination.Total != 25 {
+				t.Errorf("pagination total = %d, want 25", pagination.Total)
+			}
+		})
+	}
+}
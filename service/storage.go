@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStorage - tempat menyimpan file upload, mengembalikan URL publik
+// Implementasi saat ini hanya disk lokal; S3-compatible cukup implement interface ini
+type FileStorage interface {
+	Save(ctx context.Context, name string, r io.Reader) (string, error)
+}
+
+type localStorage struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalStorage(dir, baseURL string) FileStorage {
+	return &localStorage{dir: dir, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (ls *localStorage) Save(ctx context.Context, name string, r io.Reader) (string, error) {
+	if err := os.MkdirAll(ls.dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create upload dir: %w", err)
+	}
+
+	// name dibuat oleh service, tapi tetap buang komponen path
+	name = filepath.Base(name)
+
+	// Tulis ke file sementara lalu rename, supaya tidak ada file setengah jadi
+	tmp, err := os.CreateTemp(ls.dir, ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(ls.dir, name)); err != nil {
+		return "", fmt.Errorf("failed to save file: %w", err)
+	}
+
+	return ls.baseURL + "/" + name, nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorageSave(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "uploads")
+	storage := NewLocalStorage(dir, "/uploads/")
+
+	url, err := storage.Save(context.Background(), "../product-1.png", strings.NewReader("PNGDATA"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Komponen path dibuang: file selalu di dalam dir, URL = base tanpa slash ganda
+	if url != "/uploads/product-1.png" {
+		t.Errorf("url = %q, want /uploads/product-1.png", url)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "product-1.png"))
+	if err != nil || string(data) != "PNGDATA" {
+		t.Errorf("stored file = %q, %v", data, err)
+	}
+
+	// Tidak ada file sementara yang tertinggal
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("files in dir = %d, want 1", len(entries))
+	}
+}
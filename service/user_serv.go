@@ -7,39 +7,55 @@ import (
 	"inventory-system/model"
 	"inventory-system/repository"
 	"inventory-system/utils"
+	"strings"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type UserService interface {
-	Create(ctx context.Context, req user.CreateUserRequest) (*user.UserResponse, error)
+	Create(ctx context.Context, req user.CreateUserRequest, actor *model.User) (*user.UserResponse, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*user.UserResponse, error)
+	FindByEmail(ctx context.Context, req user.FindByEmailRequest) (*user.UserResponse, error)
 	FindAll(ctx context.Context, page int, limit int) ([]user.UserResponse, utils.Pagination, error)
-	Update(ctx context.Context, id uuid.UUID, req user.UpdateUserRequest) (*user.UserResponse, error)
-	Delete(ctx context.Context, id uuid.UUID) error
+	GetLoginHistory(ctx context.Context, id uuid.UUID, page int, limit int) ([]user.LoginHistoryEntry, utils.Pagination, error)
+	Update(ctx context.Context, id uuid.UUID, req user.UpdateUserRequest, actor *model.User) (*user.UserResponse, error)
+	Delete(ctx context.Context, id uuid.UUID, actor *model.User) error
+	Restore(ctx context.Context, id uuid.UUID, actor *model.User) (*user.UserResponse, error)
+	StreamExport(ctx context.Context, fn func(row user.UserExportRow) error) error
 }
 
 type userService struct {
 	repo *repository.Repository
 	log  *zap.Logger
+	auth AuthService // untuk revoke session saat user dihapus
 }
 
-func NewUserService(repo *repository.Repository, log *zap.Logger) UserService {
+func NewUserService(repo *repository.Repository, log *zap.Logger, auth AuthService) UserService {
 	return &userService{
 		repo: repo,
 		log:  log,
+		auth: auth,
 	}
 }
 
 // CREATE USER
-// Business logic: validate, hash password, save to db
-func (us *userService) Create(ctx context.Context, req user.CreateUserRequest) (*user.UserResponse, error) {
+// Business logic: validate, check role permission, hash password, save to db
+func (us *userService) Create(ctx context.Context, req user.CreateUserRequest, actor *model.User) (*user.UserResponse, error) {
+	// Email disimpan lowercase supaya User@X.com & user@x.com tidak jadi dua akun
+	req.Email = normalizeEmail(req.Email)
+
 	// 1. Validate input format (pure validation)
 	if err := utils.ValidateStruct(req); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
+	// Enforce role creation rules (tidak bergantung pada routing)
+	if actor == nil || !actor.CanCreateUserWithRole(model.UserRole(req.Role)) {
+		us.logRoleDenied(ctx, actor, req.Role)
+		return nil, fmt.Errorf("permission denied: cannot create user with role %s", req.Role)
+	}
+
 	// 2. Check email uniqueness (business rule)
 	if existing, _ := us.repo.User.FindByEmail(ctx, req.Email); existing != nil {
 		return nil, fmt.Errorf("email already exists")
@@ -60,14 +76,14 @@ func (us *userService) Create(ctx context.Context, req user.CreateUserRequest) (
 
 	// 5. Save to database
 	if err := us.repo.User.Create(ctx, newUser); err != nil {
-		us.log.Error("Failed to create user", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to create user", zap.Error(err))
 		return nil, fmt.Errorf("failed to create user")
 	}
 
 	// 6. Return response DTO
 	response := us.convertToResponse(newUser)
 
-	us.log.Info("User created", zap.String("user_id", newUser.ID.String()))
+	utils.LoggerFromContext(ctx).Info("User created", zap.String("user_id", newUser.ID.String()))
 	return response, nil
 }
 
@@ -81,6 +97,21 @@ func (us *userService) FindByID(ctx context.Context, id uuid.UUID) (*user.UserRe
 	return us.convertToResponse(foundUser), nil
 }
 
+// FIND USER BY EMAIL (admin lookup, tanpa list semua user)
+func (us *userService) FindByEmail(ctx context.Context, req user.FindByEmailRequest) (*user.UserResponse, error) {
+	req.Email = normalizeEmail(req.Email)
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	foundUser, err := us.repo.User.FindByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	return us.convertToResponse(foundUser), nil
+}
+
 // FIND ALL USERS
 func (us *userService) FindAll(ctx context.Context, page int, limit int) ([]user.UserResponse, utils.Pagination, error) {
 	// Setup pagination
@@ -107,7 +138,7 @@ func (us *userService) FindAll(ctx context.Context, page int, limit int) ([]user
 		responses = append(responses, *us.convertToResponse(&u))
 	}
 
-	us.log.Info("Users fetched with pagination",
+	utils.LoggerFromContext(ctx).Info("Users fetched with pagination",
 		zap.Int("page", page),
 		zap.Int("limit", limit),
 		zap.Int("total", total))
@@ -115,15 +146,56 @@ func (us *userService) FindAll(ctx context.Context, page int, limit int) ([]user
 	return responses, pagination, nil
 }
 
+// LOGIN HISTORY
+// Semua session user (aktif, revoked, expired) terbaru dulu, untuk review security
+func (us *userService) GetLoginHistory(ctx context.Context, id uuid.UUID, page int, limit int) ([]user.LoginHistoryEntry, utils.Pagination, error) {
+	pagination := utils.NewPagination(page, limit)
+
+	if _, err := us.repo.User.FindByID(ctx, id); err != nil {
+		return nil, pagination, fmt.Errorf("user not found")
+	}
+
+	sessions, err := us.repo.Session.FindHistoryByUserID(ctx, id, pagination.Limit, pagination.Offset())
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to get login history")
+	}
+
+	total, err := us.repo.Session.CountByUserID(ctx, id)
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to count login history")
+	}
+	pagination.SetTotal(total)
+
+	entries := make([]user.LoginHistoryEntry, 0, len(sessions))
+	for _, s := range sessions {
+		entries = append(entries, user.LoginHistoryEntry{
+			SessionID: s.ID.String(),
+			IPAddress: s.IPAddress,
+			UserAgent: s.UserAgent,
+			CreatedAt: s.CreatedAt,
+			ExpiresAt: s.ExpiresAt,
+			RevokedAt: s.RevokedAt,
+			IsActive:  s.IsValid(),
+		})
+	}
+
+	return entries, pagination, nil
+}
+
 // UPDATE USER
-// Business logic: validate, update fields
-func (us *userService) Update(ctx context.Context, id uuid.UUID, req user.UpdateUserRequest) (*user.UserResponse, error) {
+// Business logic: validate, check role permission, update fields
+func (us *userService) Update(ctx context.Context, id uuid.UUID, req user.UpdateUserRequest, actor *model.User) (*user.UserResponse, error) {
 	// Get existing user
 	userToUpdate, err := us.repo.User.FindByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("user not found")
 	}
 
+	// Target harus role yang boleh dibuat actor (admin tidak boleh sentuh super_admin sama sekali)
+	if err := us.checkTargetRole(ctx, actor, userToUpdate, "modify"); err != nil {
+		return nil, err
+	}
+
 	updated := false
 
 	// Update fields if provided and different
@@ -132,9 +204,16 @@ func (us *userService) Update(ctx context.Context, id uuid.UUID, req user.Update
 		updated = true
 	}
 
-	if req.Email != nil && *req.Email != userToUpdate.Email {
-		userToUpdate.Email = *req.Email
-		updated = true
+	if req.Email != nil {
+		email := normalizeEmail(*req.Email)
+		if email != userToUpdate.Email {
+			// Email baru tidak boleh dipakai user lain (case-insensitive)
+			if existing, _ := us.repo.User.FindByEmail(ctx, email); existing != nil && existing.ID != userToUpdate.ID {
+				return nil, fmt.Errorf("email already exists")
+			}
+			userToUpdate.Email = email
+			updated = true
+		}
 	}
 
 	if req.FullName != nil && *req.FullName != userToUpdate.FullName {
@@ -142,8 +221,12 @@ func (us *userService) Update(ctx context.Context, id uuid.UUID, req user.Update
 		updated = true
 	}
 
-	// Role sudah divalidasi di handler, kita trust saja
+	// Perubahan role harus mengikuti aturan yang sama dengan create
 	if req.Role != nil && model.UserRole(*req.Role) != userToUpdate.Role {
+		if actor == nil || !actor.CanCreateUserWithRole(model.UserRole(*req.Role)) {
+			us.logRoleDenied(ctx, actor, *req.Role)
+			return nil, fmt.Errorf("permission denied: cannot assign role %s", *req.Role)
+		}
 		userToUpdate.Role = model.UserRole(*req.Role)
 		updated = true
 	}
@@ -164,20 +247,110 @@ func (us *userService) Update(ctx context.Context, id uuid.UUID, req user.Update
 }
 
 // DELETE USER
-// Business logic: mark as deleted
-func (us *userService) Delete(ctx context.Context, id uuid.UUID) error {
-	if _, err := us.repo.User.FindByID(ctx, id); err != nil {
+// Business logic: mark as deleted, aturan role target sama dengan update
+func (us *userService) Delete(ctx context.Context, id uuid.UUID, actor *model.User) error {
+	userToDelete, err := us.repo.User.FindByID(ctx, id)
+	if err != nil {
 		return fmt.Errorf("user not found")
 	}
+	if err := us.checkTargetRole(ctx, actor, userToDelete, "delete"); err != nil {
+		return err
+	}
 
 	if err := us.repo.User.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete user")
 	}
 
-	us.log.Info("User deleted", zap.String("user_id", id.String()))
+	// Revoke semua session supaya token user terhapus langsung tidak berlaku
+	if err := us.auth.LogoutAllUserSessions(ctx, id); err != nil {
+		// User sudah terhapus, token tetap ditolak oleh ValidateToken (user not found)
+		utils.LoggerFromContext(ctx).Error("Failed to revoke sessions of deleted user",
+			zap.Error(err),
+			zap.String("user_id", id.String()))
+	}
+
+	utils.LoggerFromContext(ctx).Info("User deleted", zap.String("user_id", id.String()))
 	return nil
 }
 
+// RESTORE USER
+// Hanya super_admin, session lama tetap revoked (user harus login ulang)
+func (us *userService) Restore(ctx context.Context, id uuid.UUID, actor *model.User) (*user.UserResponse, error) {
+	if actor == nil || !actor.IsSuperAdmin() {
+		return nil, fmt.Errorf("permission denied: only super_admin can restore users")
+	}
+
+	if err := us.repo.User.Restore(ctx, id); err != nil {
+		if err.Error() == "deleted user not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to restore user")
+	}
+
+	restored, err := us.repo.User.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restored user")
+	}
+
+	utils.LoggerFromContext(ctx).Info("User restored",
+		zap.String("user_id", id.String()),
+		zap.String("actor_id", actor.ID.String()))
+	return us.convertToResponse(restored), nil
+}
+
+// EXPORT USERS (roster CSV/JSON)
+func (us *userService) StreamExport(ctx context.Context, fn func(row user.UserExportRow) error) error {
+	err := us.repo.User.FindAllForExport(ctx, func(u model.User) error {
+		return fn(user.UserExportRow{
+			ID:        u.ID.String(),
+			Username:  u.Username,
+			Email:     u.Email,
+			FullName:  u.FullName,
+			Role:      string(u.Role),
+			IsActive:  u.IsActive,
+			CreatedAt: u.CreatedAt,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export users: %w", err)
+	}
+
+	return nil
+}
+
+// HELPER actor hanya boleh mengubah/menghapus user dengan role yang boleh dia buat
+func (us *userService) checkTargetRole(ctx context.Context, actor *model.User, target *model.User, action string) error {
+	if actor != nil && actor.CanCreateUserWithRole(target.Role) {
+		return nil
+	}
+
+	fields := []zap.Field{
+		zap.String("action", action),
+		zap.String("target_id", target.ID.String()),
+		zap.String("target_role", string(target.Role)),
+	}
+	if actor != nil {
+		fields = append(fields,
+			zap.String("actor_id", actor.ID.String()),
+			zap.String("actor_role", string(actor.Role)),
+		)
+	}
+	utils.LoggerFromContext(ctx).Warn("User change denied", fields...)
+	return fmt.Errorf("permission denied: cannot %s %s user", action, target.Role)
+}
+
+// HELPER log percobaan pelanggaran role
+func (us *userService) logRoleDenied(ctx context.Context, actor *model.User, role string) {
+	fields := []zap.Field{zap.String("requested_role", role)}
+	if actor != nil {
+		fields = append(fields,
+			zap.String("actor_id", actor.ID.String()),
+			zap.String("actor_role", string(actor.Role)),
+		)
+	}
+	utils.LoggerFromContext(ctx).Warn("Role assignment denied", fields...)
+}
+
 // HELPER Method Response
 func (us *userService) convertToResponse(u *model.User) *user.UserResponse {
 	return &user.UserResponse{
@@ -191,3 +364,8 @@ func (us *userService) convertToResponse(u *model.User) *user.UserResponse {
 		UpdatedAt: u.UpdatedAt,
 	}
 }
+
+// normalizeEmail trim spasi & lowercase, dipakai sebelum cek unik, simpan & login
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
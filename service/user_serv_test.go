@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/dto/user"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"inventory-system/utils"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// ========== FAKES ==========
+
+type fakeUserRepo struct {
+	repository.UserRepo
+	users map[uuid.UUID]*model.User
+}
+
+func (f *fakeUserRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	if u, ok := f.users[id]; ok {
+		return u, nil
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (f *fakeUserRepo) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	for _, u := range f.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (f *fakeUserRepo) Create(ctx context.Context, u *model.User) error {
+	u.ID = uuid.New()
+	f.users[u.ID] = u
+	return nil
+}
+
+func (f *fakeUserRepo) Update(ctx context.Context, u *model.User) error {
+	f.users[u.ID] = u
+	return nil
+}
+
+func (f *fakeUserRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(f.users, id)
+	return nil
+}
+
+// fakeSessionRevoker - AuthService yang hanya catat force logout
+type fakeSessionRevoker struct {
+	AuthService
+	revoked []uuid.UUID
+}
+
+func (f *fakeSessionRevoker) LogoutAllUserSessions(ctx context.Context, userID uuid.UUID) error {
+	f.revoked = append(f.revoked, userID)
+	return nil
+}
+
+func newRoleUser(role model.UserRole) *model.User {
+	return &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Email: string(role) + "@example.com", Role: role, IsActive: true}
+}
+
+// ========== CREATE ==========
+
+func TestUserCreate(t *testing.T) {
+	superAdmin := newRoleUser(model.RoleSuperAdmin)
+	admin := newRoleUser(model.RoleAdmin)
+	staff := newRoleUser(model.RoleStaff)
+
+	request := func(email, role string) user.CreateUserRequest {
+		return user.CreateUserRequest{Username: "kasir", Email: email, Password: "secret123", FullName: "Kasir Satu", Role: role}
+	}
+
+	tests := []struct {
+		name      string
+		actor     *model.User
+		req       user.CreateUserRequest
+		wantErr   string
+		wantEmail string
+	}{
+		{name: "super admin creates super admin", actor: superAdmin, req: request("new@example.com", "super_admin"), wantEmail: "new@example.com"},
+		{name: "admin creates staff", actor: admin, req: request("new@example.com", "staff"), wantEmail: "new@example.com"},
+		{name: "admin creates admin", actor: admin, req: request("new@example.com", "admin"), wantEmail: "new@example.com"},
+		{name: "admin cannot create super admin", actor: admin, req: request("new@example.com", "super_admin"), wantErr: "permission denied: cannot create user with role super_admin"},
+		{name: "staff cannot create users", actor: staff, req: request("new@example.com", "staff"), wantErr: "permission denied: cannot create user with role staff"},
+		{name: "missing actor", actor: nil, req: request("new@example.com", "staff"), wantErr: "permission denied"},
+		{name: "email normalized", actor: admin, req: request("  New@Example.COM ", "staff"), wantEmail: "new@example.com"},
+		{name: "duplicate email case-insensitive", actor: admin, req: request("Admin@Example.com", "staff"), wantErr: "email already exists"},
+		{name: "invalid role", actor: superAdmin, req: request("new@example.com", "owner"), wantErr: "validation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepo{users: map[uuid.UUID]*model.User{superAdmin.ID: superAdmin, admin.ID: admin, staff.ID: staff}}
+			svc := NewUserService(&repository.Repository{User: users}, zap.NewNop(), &fakeSessionRevoker{})
+
+			resp, err := svc.Create(context.Background(), tt.req, tt.actor)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if len(users.users) != 3 {
+					t.Error("user stored on rejected request")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			created := users.users[uuid.MustParse(resp.ID)]
+			if created == nil {
+				t.Fatal("user not stored")
+			}
+			if created.Email != tt.wantEmail || resp.Email != tt.wantEmail {
+				t.Errorf("email = %q, want %q", created.Email, tt.wantEmail)
+			}
+			if created.Role != model.UserRole(tt.req.Role) || !created.IsActive {
+				t.Errorf("user = %+v, want active %s", created, tt.req.Role)
+			}
+			if created.PasswordHash == tt.req.Password {
+				t.Error("password stored in plain text")
+			}
+		})
+	}
+}
+
+func TestUserCreateDeniedLogsRequestID(t *testing.T) {
+	admin := newRoleUser(model.RoleAdmin)
+	core, logs := observer.New(zap.WarnLevel)
+	ctx := utils.WithLogger(context.Background(), zap.New(core).With(zap.String("request_id", "req-7")))
+	svc := NewUserService(&repository.Repository{User: &fakeUserRepo{users: map[uuid.UUID]*model.User{}}}, zap.NewNop(), &fakeSessionRevoker{})
+
+	req := user.CreateUserRequest{Username: "kasir", Email: "new@example.com", Password: "secret123", FullName: "Kasir Satu", Role: "super_admin"}
+	if _, err := svc.Create(ctx, req, admin); err == nil {
+		t.Fatal("expected permission denied")
+	}
+
+	entries := logs.FilterMessage("Role assignment denied").All()
+	if len(entries) != 1 {
+		t.Fatalf("log entries = %d, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["request_id"] != "req-7" || fields["actor_id"] != admin.ID.String() {
+		t.Errorf("fields = %v, want request_id req-7 and actor_id", fields)
+	}
+}
+
+// ========== FIND BY EMAIL ==========
+
+func TestUserFindByEmail(t *testing.T) {
+	admin := newRoleUser(model.RoleAdmin)
+	staff := newRoleUser(model.RoleStaff)
+	users := &fakeUserRepo{users: map[uuid.UUID]*model.User{admin.ID: admin, staff.ID: staff}}
+	svc := NewUserService(&repository.Repository{User: users}, zap.NewNop(), &fakeSessionRevoker{})
+
+	tests := []struct {
+		name    string
+		email   string
+		wantID  uuid.UUID
+		wantErr string
+	}{
+		{name: "found", email: "staff@example.com", wantID: staff.ID},
+		{name: "found case-insensitive", email: "  Staff@Example.COM ", wantID: staff.ID},
+		{name: "not found", email: "nobody@example.com", wantErr: "user not found"},
+		{name: "invalid email", email: "not-an-email", wantErr: "validation failed"},
+		{name: "empty email", email: "", wantErr: "validation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := svc.FindByEmail(context.Background(), user.FindByEmailRequest{Email: tt.email})
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.ID != tt.wantID.String() {
+				t.Errorf("user = %s, want %s", resp.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+// ========== UPDATE ==========
+
+func TestUserUpdate(t *testing.T) {
+	admin := newRoleUser(model.RoleAdmin)
+	superAdmin := newRoleUser(model.RoleSuperAdmin)
+
+	tests := []struct {
+		name       string
+		actor      *model.User
+		targetRole model.UserRole // kosong = staff
+		req        user.UpdateUserRequest
+		wantErr    string
+		wantEmail  string
+		wantRole   model.UserRole
+	}{
+		{name: "no changes", actor: admin, req: user.UpdateUserRequest{}, wantEmail: "target@example.com", wantRole: model.RoleStaff},
+		{name: "same email different case", actor: admin, req: user.UpdateUserRequest{Email: stringPtr("Target@Example.com")}, wantEmail: "target@example.com", wantRole: model.RoleStaff},
+		{name: "new email normalized", actor: admin, req: user.UpdateUserRequest{Email: stringPtr(" Moved@Example.com")}, wantEmail: "moved@example.com", wantRole: model.RoleStaff},
+		{name: "email taken by other user", actor: admin, req: user.UpdateUserRequest{Email: stringPtr("ADMIN@example.com")}, wantErr: "email already exists"},
+		{name: "admin promotes to admin", actor: admin, req: user.UpdateUserRequest{Role: stringPtr("admin")}, wantEmail: "target@example.com", wantRole: model.RoleAdmin},
+		{name: "admin cannot promote to super admin", actor: admin, req: user.UpdateUserRequest{Role: stringPtr("super_admin")}, wantErr: "permission denied: cannot assign role super_admin"},
+		{name: "super admin promotes to super admin", actor: superAdmin, req: user.UpdateUserRequest{Role: stringPtr("super_admin")}, wantEmail: "target@example.com", wantRole: model.RoleSuperAdmin},
+		{name: "unchanged role skips assignment check", actor: admin, req: user.UpdateUserRequest{Role: stringPtr("staff")}, wantEmail: "target@example.com", wantRole: model.RoleStaff},
+		{name: "missing actor", actor: nil, req: user.UpdateUserRequest{FullName: stringPtr("Renamed")}, wantErr: "permission denied: cannot modify staff user"},
+		{name: "admin cannot demote super admin", actor: admin, targetRole: model.RoleSuperAdmin, req: user.UpdateUserRequest{Role: stringPtr("staff")}, wantErr: "permission denied: cannot modify super_admin user"},
+		{name: "admin cannot deactivate super admin", actor: admin, targetRole: model.RoleSuperAdmin, req: user.UpdateUserRequest{IsActive: boolPtr(false)}, wantErr: "permission denied: cannot modify super_admin user"},
+		{name: "admin cannot change super admin email", actor: admin, targetRole: model.RoleSuperAdmin, req: user.UpdateUserRequest{Email: stringPtr("taken-over@example.com")}, wantErr: "permission denied: cannot modify super_admin user"},
+		{name: "super admin demotes super admin", actor: superAdmin, targetRole: model.RoleSuperAdmin, req: user.UpdateUserRequest{Role: stringPtr("admin")}, wantEmail: "target@example.com", wantRole: model.RoleAdmin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := tt.targetRole
+			if role == "" {
+				role = model.RoleStaff
+			}
+			target := &model.User{BaseModel: model.BaseModel{ID: uuid.New()}, Email: "target@example.com", Role: role, IsActive: true}
+			original := *target
+			users := &fakeUserRepo{users: map[uuid.UUID]*model.User{admin.ID: admin, superAdmin.ID: superAdmin, target.ID: target}}
+			svc := NewUserService(&repository.Repository{User: users}, zap.NewNop(), &fakeSessionRevoker{})
+
+			resp, err := svc.Update(context.Background(), target.ID, tt.req, tt.actor)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if *users.users[target.ID] != original {
+					t.Errorf("target = %+v, want unchanged", *users.users[target.ID])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Email != tt.wantEmail || resp.Role != string(tt.wantRole) {
+				t.Errorf("response = %s/%s, want %s/%s", resp.Email, resp.Role, tt.wantEmail, tt.wantRole)
+			}
+		})
+	}
+
+	svc := NewUserService(&repository.Repository{User: &fakeUserRepo{}}, zap.NewNop(), &fakeSessionRevoker{})
+	if _, err := svc.Update(context.Background(), uuid.New(), user.UpdateUserRequest{}, admin); err == nil || err.Error() != "user not found" {
+		t.Errorf("unknown user error = %v, want user not found", err)
+	}
+}
+
+// ========== DELETE / RESTORE ==========
+
+func TestUserDeleteRevokesSessions(t *testing.T) {
+	target := newRoleUser(model.RoleStaff)
+	revoker := &fakeSessionRevoker{}
+	users := &fakeUserRepo{users: map[uuid.UUID]*model.User{target.ID: target}}
+	svc := NewUserService(&repository.Repository{User: users}, zap.NewNop(), revoker)
+
+	if err := svc.Delete(context.Background(), target.ID, newRoleUser(model.RoleAdmin)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(revoker.revoked) != 1 || revoker.revoked[0] != target.ID {
+		t.Errorf("revoked = %v, want sessions of %s", revoker.revoked, target.ID)
+	}
+
+	// User sudah terhapus: tidak ada session yang di-revoke lagi
+	if err := svc.Delete(context.Background(), target.ID, newRoleUser(model.RoleAdmin)); err == nil || err.Error() != "user not found" {
+		t.Errorf("second delete error = %v, want user not found", err)
+	}
+	if len(revoker.revoked) != 1 {
+		t.Errorf("revoked = %d times, want 1", len(revoker.revoked))
+	}
+}
+
+func TestUserDeletePermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		actor      *model.User
+		targetRole model.UserRole
+		wantErr    string
+	}{
+		{name: "admin deletes staff", actor: newRoleUser(model.RoleAdmin), targetRole: model.RoleStaff},
+		{name: "super admin deletes super admin", actor: newRoleUser(model.RoleSuperAdmin), targetRole: model.RoleSuperAdmin},
+		{name: "admin cannot delete super admin", actor: newRoleUser(model.RoleAdmin), targetRole: model.RoleSuperAdmin, wantErr: "permission denied: cannot delete super_admin user"},
+		{name: "missing actor", targetRole: model.RoleStaff, wantErr: "permission denied: cannot delete staff user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := newRoleUser(tt.targetRole)
+			revoker := &fakeSessionRevoker{}
+			users := &fakeUserRepo{users: map[uuid.UUID]*model.User{target.ID: target}}
+			svc := NewUserService(&repository.Repository{User: users}, zap.NewNop(), revoker)
+
+			err := svc.Delete(context.Background(), target.ID, tt.actor)
+			if tt.wantErr == "" {
+				if err != nil || users.users[target.ID] != nil {
+					t.Fatalf("err = %v, deleted = %v, want deleted", err, users.users[target.ID] == nil)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if users.users[target.ID] == nil || len(revoker.revoked) != 0 {
+				t.Error("user deleted or sessions revoked despite denial")
+			}
+		})
+	}
+}
+
+func TestUserRestorePermission(t *testing.T) {
+	tests := []struct {
+		name  string
+		actor *model.User
+	}{
+		{name: "admin", actor: newRoleUser(model.RoleAdmin)},
+		{name: "staff", actor: newRoleUser(model.RoleStaff)},
+		{name: "missing actor", actor: nil},
+	}
+
+	for _, tt := range tests {
+		svc := NewUserService(&repository.Repository{User: &fakeUserRepo{}}, zap.NewNop(), &fakeSessionRevoker{})
+		_, err := svc.Restore(context.Background(), uuid.New(), tt.actor)
+		if err == nil || err.Error() != "permission denied: only super_admin can restore users" {
+			t.Errorf("%s: error = %v, want permission denied", tt.name, err)
+		}
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := map[string]string{
+		"user@example.com":        "user@example.com",
+		"  User@Example.COM\t":    "user@example.com",
+		"":                        "",
+		"MiXeD.Case+Tag@Mail.Com": "mixed.case+tag@mail.com",
+	}
+
+	for input, want := range tests {
+		if got := normalizeEmail(input); got != want {
+			t.Errorf("normalizeEmail(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+// ========== MODEL PERMISSIONS ==========
+
+func TestCanCreateUserWithRole(t *testing.T) {
+	roles := []model.UserRole{model.RoleSuperAdmin, model.RoleAdmin, model.RoleStaff}
+	allowed := map[model.UserRole][]bool{
+		model.RoleSuperAdmin: {true, true, true},
+		model.RoleAdmin:      {false, true, true},
+		model.RoleStaff:      {false, false, false},
+	}
+
+	for actorRole, want := range allowed {
+		actor := newRoleUser(actorRole)
+		for i, role := range roles {
+			if got := actor.CanCreateUserWithRole(role); got != want[i] {
+				t.Errorf("%s creating %s = %v, want %v", actorRole, role, got, want[i])
+			}
+		}
+	}
+}
@@ -15,8 +15,9 @@ import (
 type WarehouseService interface {
 	Create(ctx context.Context, req warehouse.CreateWarehouseRequest) (*warehouse.WarehouseResponse, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*warehouse.WarehouseResponse, error)
-	FindAll(ctx context.Context, page int, limit int) ([]warehouse.WarehouseResponse, utils.Pagination, error)
+	FindAll(ctx context.Context, page int, limit int, includeDeleted bool) ([]warehouse.WarehouseResponse, utils.Pagination, error)
 	Update(ctx context.Context, id uuid.UUID, req warehouse.UpdateWarehouseRequest) (*warehouse.WarehouseResponse, error)
+	Deactivate(ctx context.Context, id uuid.UUID, req warehouse.DeactivateWarehouseRequest) (*warehouse.WarehouseResponse, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
@@ -35,28 +36,32 @@ func (ws *warehouseService) Create(ctx context.Context, req warehouse.CreateWare
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
+	// Check code uniqueness
+	if existing, _ := ws.repo.Warehouse.FindByCode(ctx, req.Code); existing != nil {
+		return nil, fmt.Errorf("warehouse code already exists")
+	}
+
 	// prepare warehouse object
 	newWarehouse := &model.Warehouse{
-		Name:    req.Name,
-		Address: req.Address,
+		Code:     req.Code,
+		Name:     req.Name,
+		Address:  req.Address,
+		IsActive: true,
 	}
 
 	// Save to database
 	if err := ws.repo.Warehouse.Create(ctx, newWarehouse); err != nil {
-		ws.log.Error("Failed to create warehouse", zap.Error(err))
+		utils.LoggerFromContext(ctx).Error("Failed to create warehouse", zap.Error(err))
+		if err.Error() == "warehouse code already exists" {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to create warehouse")
 	}
 
 	// prepare response
-	response := &warehouse.WarehouseResponse{
-		ID:        newWarehouse.ID.String(),
-		Name:      newWarehouse.Name,
-		Address:   newWarehouse.Address,
-		CreatedAt: newWarehouse.CreatedAt,
-		UpdatedAt: newWarehouse.UpdatedAt,
-	}
+	response := ws.convertToResponse(newWarehouse)
 
-	ws.log.Info("Warehouse created", zap.String("warehouse_id", newWarehouse.ID.String()))
+	utils.LoggerFromContext(ctx).Info("Warehouse created", zap.String("warehouse_id", newWarehouse.ID.String()))
 	return response, nil
 }
 
@@ -66,27 +71,21 @@ func (ws *warehouseService) FindByID(ctx context.Context, id uuid.UUID) (*wareho
 		return nil, fmt.Errorf("warehouse not found")
 	}
 
-	return &warehouse.WarehouseResponse{
-		ID:        foundWarehouse.ID.String(),
-		Name:      foundWarehouse.Name,
-		Address:   foundWarehouse.Address,
-		CreatedAt: foundWarehouse.CreatedAt,
-		UpdatedAt: foundWarehouse.UpdatedAt,
-	}, nil
+	return ws.convertToResponse(foundWarehouse), nil
 }
 
-func (ws *warehouseService) FindAll(ctx context.Context, page int, limit int) ([]warehouse.WarehouseResponse, utils.Pagination, error) {
+func (ws *warehouseService) FindAll(ctx context.Context, page int, limit int, includeDeleted bool) ([]warehouse.WarehouseResponse, utils.Pagination, error) {
 	// Setup pagination
 	pagination := utils.NewPagination(page, limit)
 
 	// Get data with pagination
-	warehouses, err := ws.repo.Warehouse.FindAll(ctx, pagination.Limit, pagination.Offset())
+	warehouses, err := ws.repo.Warehouse.FindAll(ctx, pagination.Limit, pagination.Offset(), includeDeleted)
 	if err != nil {
 		return nil, pagination, fmt.Errorf("failed to get warehouses")
 	}
 
 	// Get total count
-	total, err := ws.repo.Warehouse.CountAll(ctx)
+	total, err := ws.repo.Warehouse.CountAll(ctx, includeDeleted)
 	if err != nil {
 		return nil, pagination, fmt.Errorf("failed to count warehouses")
 	}
@@ -100,7 +99,7 @@ func (ws *warehouseService) FindAll(ctx context.Context, page int, limit int) ([
 		responses = append(responses, *ws.convertToResponse(&w))
 	}
 
-	ws.log.Info("Warehouses fetched with pagination",
+	utils.LoggerFromContext(ctx).Info("Warehouses fetched with pagination",
 		zap.Int("page", page),
 		zap.Int("limit", limit),
 		zap.Int("total", total))
@@ -109,6 +108,11 @@ func (ws *warehouseService) FindAll(ctx context.Context, page int, limit int) ([
 }
 
 func (ws *warehouseService) Update(ctx context.Context, id uuid.UUID, req warehouse.UpdateWarehouseRequest) (*warehouse.WarehouseResponse, error) {
+	// Validate input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
 	warehouseToUpdate, err := ws.repo.Warehouse.FindByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("warehouse not found")
@@ -116,6 +120,15 @@ func (ws *warehouseService) Update(ctx context.Context, id uuid.UUID, req wareho
 
 	updated := false
 
+	// Code harus tetap unik kalau diganti
+	if req.Code != nil && *req.Code != warehouseToUpdate.Code {
+		if existing, _ := ws.repo.Warehouse.FindByCode(ctx, *req.Code); existing != nil {
+			return nil, fmt.Errorf("warehouse code already exists")
+		}
+		warehouseToUpdate.Code = *req.Code
+		updated = true
+	}
+
 	// update fields if provided and different
 	if req.Name != nil && *req.Name != warehouseToUpdate.Name {
 		warehouseToUpdate.Name = *req.Name
@@ -130,17 +143,40 @@ func (ws *warehouseService) Update(ctx context.Context, id uuid.UUID, req wareho
 	// Save if change were made
 	if updated {
 		if err := ws.repo.Warehouse.Update(ctx, warehouseToUpdate); err != nil {
+			if err.Error() == "warehouse code already exists" {
+				return nil, err
+			}
 			return nil, fmt.Errorf("failed to update warehouse")
 		}
 	}
 
-	return &warehouse.WarehouseResponse{
-		ID:        warehouseToUpdate.ID.String(),
-		Name:      warehouseToUpdate.Name,
-		Address:   warehouseToUpdate.Address,
-		CreatedAt: warehouseToUpdate.CreatedAt,
-		UpdatedAt: warehouseToUpdate.UpdatedAt,
-	}, nil
+	return ws.convertToResponse(warehouseToUpdate), nil
+}
+
+// Deactivate tutup sementara warehouse (default), body { "active": true } untuk buka lagi
+// Shelf di warehouse tidak aktif tidak bisa dipakai untuk produk baru
+func (ws *warehouseService) Deactivate(ctx context.Context, id uuid.UUID, req warehouse.DeactivateWarehouseRequest) (*warehouse.WarehouseResponse, error) {
+	existingWarehouse, err := ws.repo.Warehouse.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("warehouse not found")
+	}
+
+	active := false
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	if existingWarehouse.IsActive != active {
+		if err := ws.repo.Warehouse.SetActive(ctx, id, active); err != nil {
+			if err.Error() == "warehouse not found" {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to update warehouse status")
+		}
+		existingWarehouse.IsActive = active
+	}
+
+	return ws.convertToResponse(existingWarehouse), nil
 }
 
 func (ws *warehouseService) Delete(ctx context.Context, id uuid.UUID) error {
@@ -152,16 +188,19 @@ func (ws *warehouseService) Delete(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("failed to delete warehouse")
 	}
 
-	ws.log.Info("Warehouse deleted", zap.String("warehouse_id", id.String()))
+	utils.LoggerFromContext(ctx).Info("Warehouse deleted", zap.String("warehouse_id", id.String()))
 	return nil
 }
 
 func (ws *warehouseService) convertToResponse(w *model.Warehouse) *warehouse.WarehouseResponse {
 	return &warehouse.WarehouseResponse{
 		ID:        w.ID.String(),
+		Code:      w.Code,
 		Name:      w.Name,
 		Address:   w.Address,
+		IsActive:  w.IsActive,
 		CreatedAt: w.CreatedAt,
 		UpdatedAt: w.UpdatedAt,
+		DeletedAt: w.DeletedAt,
 	}
 }
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/dto/warehouse"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ========== FAKES ==========
+
+type fakeWarehouseRepo struct {
+	repository.WarehouseRepo
+	warehouses map[uuid.UUID]*model.Warehouse
+}
+
+func (f *fakeWarehouseRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Warehouse, error) {
+	if w, ok := f.warehouses[id]; ok {
+		return w, nil
+	}
+	return nil, fmt.Errorf("warehouse not found")
+}
+
+// fakeWarehouseStore - fakeWarehouseRepo plus write & lookup by code
+type fakeWarehouseStore struct {
+	fakeWarehouseRepo
+	createErr  error // simulasi unique violation saat dua request create bersamaan
+	writes     int
+	activeSets []bool
+}
+
+func (f *fakeWarehouseStore) FindByCode(ctx context.Context, code string) (*model.Warehouse, error) {
+	for _, w := range f.warehouses {
+		if w.Code == code {
+			return w, nil
+		}
+	}
+	return nil, fmt.Errorf("warehouse not found")
+}
+
+func (f *fakeWarehouseStore) Create(ctx context.Context, w *model.Warehouse) error {
+	if f.createErr != nil {
+		return f.createErr
+	}
+	f.writes++
+	w.ID = uuid.New()
+	f.warehouses[w.ID] = w
+	return nil
+}
+
+func (f *fakeWarehouseStore) Update(ctx context.Context, w *model.Warehouse) error {
+	f.writes++
+	return nil
+}
+
+func (f *fakeWarehouseStore) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	f.activeSets = append(f.activeSets, active)
+	return nil
+}
+
+func newWarehouseStore(warehouses ...*model.Warehouse) *fakeWarehouseStore {
+	store := &fakeWarehouseStore{fakeWarehouseRepo: fakeWarehouseRepo{warehouses: map[uuid.UUID]*model.Warehouse{}}}
+	for _, w := range warehouses {
+		store.warehouses[w.ID] = w
+	}
+	return store
+}
+
+func newWarehouse(code string, active bool) *model.Warehouse {
+	return &model.Warehouse{BaseModel: model.BaseModel{ID: uuid.New()}, Code: code, Name: "Gudang " + code, IsActive: active}
+}
+
+// ========== CREATE / UPDATE ==========
+
+func TestWarehouseCreate(t *testing.T) {
+	existing := newWarehouse("WH-01", true)
+
+	tests := []struct {
+		name      string
+		req       warehouse.CreateWarehouseRequest
+		createErr error
+		wantErr   string
+	}{
+		{name: "created active", req: warehouse.CreateWarehouseRequest{Code: "WH-02", Name: "Gudang Dua"}},
+		{name: "duplicate code", req: warehouse.CreateWarehouseRequest{Code: "WH-01", Name: "Gudang Lain"}, wantErr: "warehouse code already exists"},
+		{name: "concurrent duplicate from repository", req: warehouse.CreateWarehouseRequest{Code: "WH-03", Name: "Gudang Tiga"}, createErr: fmt.Errorf("warehouse code already exists"), wantErr: "warehouse code already exists"},
+		{name: "repository failure", req: warehouse.CreateWarehouseRequest{Code: "WH-03", Name: "Gudang Tiga"}, createErr: fmt.Errorf("connection reset"), wantErr: "failed to create warehouse"},
+		{name: "validation", req: warehouse.CreateWarehouseRequest{Code: "W", Name: "Gudang"}, wantErr: "validation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newWarehouseStore(existing)
+			store.createErr = tt.createErr
+			svc := NewWarehouseService(&repository.Repository{Warehouse: store}, zap.NewNop())
+
+			resp, err := svc.Create(context.Background(), tt.req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Code != tt.req.Code || !resp.IsActive {
+				t.Errorf("response = %+v, want active %s", resp, tt.req.Code)
+			}
+		})
+	}
+}
+
+func TestWarehouseUpdateCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       string
+		wantErr    string
+		wantWrites int
+	}{
+		{name: "new unique code", code: "WH-09", wantWrites: 1},
+		{name: "same code is no-op", code: "WH-01"},
+		{name: "code taken", code: "WH-02", wantErr: "warehouse code already exists"},
+	}
+
+	for _, tt := range tests {
+		target := newWarehouse("WH-01", true)
+		store := newWarehouseStore(target, newWarehouse("WH-02", true))
+		svc := NewWarehouseService(&repository.Repository{Warehouse: store}, zap.NewNop())
+
+		_, err := svc.Update(context.Background(), target.ID, warehouse.UpdateWarehouseRequest{Code: &tt.code})
+		if (tt.wantErr == "" && err != nil) || (tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr)) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+		if store.writes != tt.wantWrites {
+			t.Errorf("%s: writes = %d, want %d", tt.name, store.writes, tt.wantWrites)
+		}
+	}
+}
+
+// ========== DEACTIVATE ==========
+
+func TestWarehouseDeactivate(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name        string
+		active      bool
+		req         warehouse.DeactivateWarehouseRequest
+		wantActive  bool
+		wantSetCall bool
+	}{
+		{name: "empty body deactivates", active: true, wantActive: false, wantSetCall: true},
+		{name: "explicit deactivate", active: true, req: warehouse.DeactivateWarehouseRequest{Active: &no}, wantActive: false, wantSetCall: true},
+		{name: "reactivate", active: false, req: warehouse.DeactivateWarehouseRequest{Active: &yes}, wantActive: true, wantSetCall: true},
+		{name: "already inactive is no-op", active: false, wantActive: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := newWarehouse("WH-01", tt.active)
+			store := newWarehouseStore(target)
+			svc := NewWarehouseService(&repository.Repository{Warehouse: store}, zap.NewNop())
+
+			resp, err := svc.Deactivate(context.Background(), target.ID, tt.req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.IsActive != tt.wantActive {
+				t.Errorf("is_active = %v, want %v", resp.IsActive, tt.wantActive)
+			}
+			if (len(store.activeSets) == 1) != tt.wantSetCall {
+				t.Errorf("SetActive calls = %v, want called %v", store.activeSets, tt.wantSetCall)
+			}
+		})
+	}
+
+	svc := NewWarehouseService(&repository.Repository{Warehouse: newWarehouseStore()}, zap.NewNop())
+	if _, err := svc.Deactivate(context.Background(), uuid.New(), warehouse.DeactivateWarehouseRequest{}); err == nil || err.Error() != "warehouse not found" {
+		t.Errorf("unknown warehouse error = %v, want warehouse not found", err)
+	}
+}
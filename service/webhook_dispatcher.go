@@ -0,0 +1,131 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	webhookTimeout     = 5 * time.Second
+	webhookMaxAttempts = 3
+	webhookBackoff     = 2 * time.Second
+)
+
+// webhookDispatcher - Notifier yang POST event ke subscriber webhook
+// Dispatch async (goroutine) supaya response API tidak ter-block
+type webhookDispatcher struct {
+	repo   *repository.Repository
+	log    *zap.Logger
+	client *http.Client
+}
+
+func NewWebhookDispatcher(repo *repository.Repository, log *zap.Logger) Notifier {
+	return &webhookDispatcher{
+		repo:   repo,
+		log:    log,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func (wd *webhookDispatcher) Emit(event Event) {
+	go wd.dispatch(event)
+}
+
+// dispatch cari subscriber sesuai event type lalu kirim ke masing-masing
+func (wd *webhookDispatcher) dispatch(event Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	subscribers, err := wd.repo.Webhook.FindActiveByEvent(ctx, string(event.Type))
+	cancel()
+	if err != nil {
+		wd.log.Error("Failed to load webhook subscribers",
+			zap.String("event", string(event.Type)),
+			zap.Error(err))
+		return
+	}
+	if len(subscribers) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		wd.log.Error("Failed to marshal webhook payload", zap.Error(err))
+		return
+	}
+
+	for _, sub := range subscribers {
+		go wd.deliver(sub, event.Type, body)
+	}
+}
+
+// deliver kirim payload dengan retry (backoff linear)
+func (wd *webhookDispatcher) deliver(sub model.Webhook, eventType EventType, body []byte) {
+	var lastErr error
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		lastErr = wd.send(sub, eventType, body)
+		if lastErr == nil {
+			wd.log.Info("Webhook delivered",
+				zap.String("webhook_id", sub.ID.String()),
+				zap.String("event", string(eventType)),
+				zap.Int("attempt", attempt))
+			return
+		}
+
+		wd.log.Warn("Webhook delivery failed",
+			zap.String("webhook_id", sub.ID.String()),
+			zap.String("event", string(eventType)),
+			zap.Int("attempt", attempt),
+			zap.Error(lastErr))
+
+		if attempt < webhookMaxAttempts {
+			time.Sleep(webhookBackoff * time.Duration(attempt))
+		}
+	}
+
+	wd.log.Error("Webhook delivery gave up",
+		zap.String("webhook_id", sub.ID.String()),
+		zap.String("url", sub.URL),
+		zap.String("event", string(eventType)),
+		zap.Error(lastErr))
+}
+
+func (wd *webhookDispatcher) send(sub model.Webhook, eventType EventType, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", string(eventType))
+	req.Header.Set("X-Signature", signWebhookPayload(sub.Secret, body))
+
+	resp, err := wd.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// signWebhookPayload HMAC-SHA256 dari body, format: "sha256=<hex>"
+func signWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
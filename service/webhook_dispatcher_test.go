@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type fakeWebhookRepo struct {
+	repository.WebhookRepo
+	subscribers map[string][]model.Webhook
+}
+
+func (f *fakeWebhookRepo) FindActiveByEvent(ctx context.Context, eventType string) ([]model.Webhook, error) {
+	return f.subscribers[eventType], nil
+}
+
+// receivedWebhook - request yang diterima server subscriber
+type receivedWebhook struct {
+	event     string
+	signature string
+	body      []byte
+}
+
+func newWebhookServer(t *testing.T, status int) (*httptest.Server, chan receivedWebhook) {
+	received := make(chan receivedWebhook, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedWebhook{event: r.Header.Get("X-Webhook-Event"), signature: r.Header.Get("X-Signature"), body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	got := signWebhookPayload("topsecret", []byte(`{"type":"low_stock"}`))
+	want := "sha256=e14b55f73387d8e75876349865bd4e61a7bbfcf04e9ae87fd5740a7d43307eb0"
+	if got != want {
+		t.Errorf("signature = %s, want %s", got, want)
+	}
+	if signWebhookPayload("other", []byte(`{"type":"low_stock"}`)) == want {
+		t.Error("signature must depend on secret")
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr string
+	}{
+		{name: "accepted", status: http.StatusOK},
+		{name: "no content", status: http.StatusNoContent},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: "unexpected status 500"},
+		{name: "non-2xx treated as failure", status: http.StatusNotModified, wantErr: "unexpected status 304"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, received := newWebhookServer(t, tt.status)
+			wd := NewWebhookDispatcher(&repository.Repository{}, zap.NewNop()).(*webhookDispatcher)
+			sub := model.Webhook{URL: server.URL, Secret: "topsecret"}
+			body := []byte(`{"type":"low_stock"}`)
+
+			err := wd.send(sub, EventLowStock, body)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+
+			req := <-received
+			if req.event != "low_stock" || req.signature != signWebhookPayload("topsecret", body) || string(req.body) != string(body) {
+				t.Errorf("request = %+v, want signed low_stock payload", req)
+			}
+		})
+	}
+}
+
+func TestWebhookDispatchToSubscribers(t *testing.T) {
+	server, received := newWebhookServer(t, http.StatusOK)
+	repo := &repository.Repository{Webhook: &fakeWebhookRepo{subscribers: map[string][]model.Webhook{
+		string(EventSaleCreated): {
+			{BaseModel: model.BaseModel{ID: uuid.New()}, URL: server.URL, Secret: "a"},
+			{BaseModel: model.BaseModel{ID: uuid.New()}, URL: server.URL, Secret: "b"},
+		},
+	}}}
+	wd := NewWebhookDispatcher(repo, zap.NewNop())
+
+	// Event tanpa subscriber tidak mengirim apa-apa
+	wd.Emit(Event{Type: EventLowStock, OccurredAt: time.Now()})
+	wd.Emit(Event{Type: EventSaleCreated, OccurredAt: time.Now(), Data: map[string]interface{}{"invoice_number": "INV-1"}})
+
+	signatures := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case req := <-received:
+			if req.event != string(EventSaleCreated) {
+				t.Errorf("event = %s, want %s", req.event, EventSaleCreated)
+			}
+			var payload Event
+			if err := json.Unmarshal(req.body, &payload); err != nil || payload.Data["invoice_number"] != "INV-1" {
+				t.Errorf("payload = %s, err %v", req.body, err)
+			}
+			signatures[req.signature] = true
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d deliveries, want 2", i)
+		}
+	}
+	if len(signatures) != 2 {
+		t.Error("each subscriber must be signed with its own secret")
+	}
+
+	select {
+	case req := <-received:
+		t.Errorf("unexpected delivery %s", req.event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMultiNotifier(t *testing.T) {
+	first, second := &recordingNotifier{}, &recordingNotifier{}
+	NewMultiNotifier(first, NewNoopNotifier(), second).Emit(Event{Type: EventLowStock})
+
+	if len(first.events) != 1 || len(second.events) != 1 {
+		t.Errorf("events = %d, %d, want 1 each", len(first.events), len(second.events))
+	}
+}
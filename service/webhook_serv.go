@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"inventory-system/dto/webhook"
+	"inventory-system/model"
+	"inventory-system/repository"
+	"inventory-system/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WebhookService interface {
+	Create(ctx context.Context, req webhook.CreateWebhookRequest) (*webhook.WebhookResponse, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*webhook.WebhookResponse, error)
+	FindAll(ctx context.Context, page int, limit int) ([]webhook.WebhookResponse, utils.Pagination, error)
+	Update(ctx context.Context, id uuid.UUID, req webhook.UpdateWebhookRequest) (*webhook.WebhookResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type webhookService struct {
+	repo *repository.Repository
+	log  *zap.Logger
+}
+
+func NewWebhookService(repo *repository.Repository, log *zap.Logger) WebhookService {
+	return &webhookService{repo: repo, log: log}
+}
+
+func (ws *webhookService) Create(ctx context.Context, req webhook.CreateWebhookRequest) (*webhook.WebhookResponse, error) {
+	// Validate input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	newWebhook := &model.Webhook{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+		IsActive:   true,
+	}
+	if req.IsActive != nil {
+		newWebhook.IsActive = *req.IsActive
+	}
+
+	if err := ws.repo.Webhook.Create(ctx, newWebhook); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to create webhook", zap.Error(err))
+		return nil, fmt.Errorf("failed to create webhook")
+	}
+
+	utils.LoggerFromContext(ctx).Info("Webhook created", zap.String("webhook_id", newWebhook.ID.String()))
+	return ws.convertToResponse(newWebhook), nil
+}
+
+func (ws *webhookService) FindByID(ctx context.Context, id uuid.UUID) (*webhook.WebhookResponse, error) {
+	foundWebhook, err := ws.repo.Webhook.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("webhook not found")
+	}
+
+	return ws.convertToResponse(foundWebhook), nil
+}
+
+func (ws *webhookService) FindAll(ctx context.Context, page int, limit int) ([]webhook.WebhookResponse, utils.Pagination, error) {
+	// Setup pagination
+	pagination := utils.NewPagination(page, limit)
+
+	webhooks, err := ws.repo.Webhook.FindAll(ctx, pagination.Limit, pagination.Offset())
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to get webhooks")
+	}
+
+	total, err := ws.repo.Webhook.CountAll(ctx)
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to count webhooks")
+	}
+
+	pagination.SetTotal(total)
+
+	responses := make([]webhook.WebhookResponse, 0, len(webhooks))
+	for _, wh := range webhooks {
+		responses = append(responses, *ws.convertToResponse(&wh))
+	}
+
+	return responses, pagination, nil
+}
+
+func (ws *webhookService) Update(ctx context.Context, id uuid.UUID, req webhook.UpdateWebhookRequest) (*webhook.WebhookResponse, error) {
+	// Validate input
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	webhookToUpdate, err := ws.repo.Webhook.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("webhook not found")
+	}
+
+	updated := false
+
+	if req.URL != nil && *req.URL != webhookToUpdate.URL {
+		webhookToUpdate.URL = *req.URL
+		updated = true
+	}
+	if req.EventTypes != nil {
+		webhookToUpdate.EventTypes = req.EventTypes
+		updated = true
+	}
+	if req.Secret != nil && *req.Secret != webhookToUpdate.Secret {
+		webhookToUpdate.Secret = *req.Secret
+		updated = true
+	}
+	if req.IsActive != nil && *req.IsActive != webhookToUpdate.IsActive {
+		webhookToUpdate.IsActive = *req.IsActive
+		updated = true
+	}
+
+	if updated {
+		if err := ws.repo.Webhook.Update(ctx, webhookToUpdate); err != nil {
+			return nil, fmt.Errorf("failed to update webhook")
+		}
+	}
+
+	return ws.convertToResponse(webhookToUpdate), nil
+}
+
+func (ws *webhookService) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := ws.repo.Webhook.FindByID(ctx, id); err != nil {
+		return fmt.Errorf("webhook not found")
+	}
+
+	if err := ws.repo.Webhook.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook")
+	}
+
+	utils.LoggerFromContext(ctx).Info("Webhook deleted", zap.String("webhook_id", id.String()))
+	return nil
+}
+
+func (ws *webhookService) convertToResponse(wh *model.Webhook) *webhook.WebhookResponse {
+	return &webhook.WebhookResponse{
+		ID:         wh.ID.String(),
+		URL:        wh.URL,
+		EventTypes: wh.EventTypes,
+		IsActive:   wh.IsActive,
+		CreatedAt:  wh.CreatedAt,
+		UpdatedAt:  wh.UpdatedAt,
+	}
+}
@@ -1,6 +1,11 @@
 package utils
 
 import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
@@ -11,7 +16,19 @@ type Configuration struct {
 	Debug       bool
 	Limit       int
 	PathLogging string
+	LogLevel    string // debug, info, warn, error
+	LogFormat   string // json, console
 	DB          DatabaseConfig
+	Security    SecurityConfig
+	RateLimit   RateLimitConfig
+	Upload      UploadConfig
+	Inventory   InventoryConfig
+	Money       MoneyConfig
+	Invoice     InvoiceConfig
+	Sale        SaleConfig
+	Report      ReportConfig
+	Session     SessionConfig
+	VerboseLog  VerboseLogConfig
 }
 
 type DatabaseConfig struct {
@@ -21,6 +38,111 @@ type DatabaseConfig struct {
 	Host     string
 	Port     string
 	MaxConn  int32
+
+	SlowQueryThreshold  time.Duration // 0 = slow query log nonaktif
+	HealthCheckInterval time.Duration // interval ping readiness DB
+}
+
+// SecurityConfig - toggle untuk CORS & security headers
+type SecurityConfig struct {
+	CORSAllowedOrigins []string // kosong = CORS nonaktif
+	NoSniff            bool     // X-Content-Type-Options: nosniff
+	FrameDeny          bool     // X-Frame-Options: DENY
+	ReferrerPolicy     string   // kosong = header tidak di-set
+	HSTSEnabled        bool     // aktifkan hanya jika TLS terminate di upstream
+	HSTSMaxAge         int      // detik
+
+	// IP/CIDR reverse proxy yang boleh set X-Forwarded-For / X-Real-IP
+	// Kosong = header diabaikan, IP client = IP koneksi (tidak bisa dipalsukan untuk bypass rate limit)
+	TrustedProxies []string
+}
+
+// Validate dipanggil saat startup (main.go), error = config tidak valid
+func (c SecurityConfig) Validate() error {
+	for _, proxy := range c.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("invalid TRUSTED_PROXIES entry %q: must be an IP or CIDR", proxy)
+			}
+		}
+	}
+	return nil
+}
+
+// RateLimitConfig - requests per menit, 0 = nonaktif
+type RateLimitConfig struct {
+	AuthenticatedRPM int // per user, untuk route yang butuh login
+	PublicRPM        int // per IP, untuk route publik (login)
+}
+
+// UploadConfig - penyimpanan file upload (gambar produk) di disk lokal
+type UploadConfig struct {
+	Dir       string // folder tujuan, dibuat otomatis jika belum ada
+	BaseURL   string // prefix URL publik, contoh "/uploads" atau "https://cdn.example.com"
+	MaxSizeMB int    // batas ukuran file per upload
+}
+
+// InventoryConfig - batasan bisnis untuk data produk
+type InventoryConfig struct {
+	MaxMinStockLevel     int // batas atas min_stock_level, 0 = tanpa batas
+	DefaultMinStockLevel int // min_stock_level saat product dibuat tanpa nilai
+	MaxBatchIDs          int // jumlah ID maksimal per POST /api/products/batch
+}
+
+// SaleConfig - batasan transaksi penjualan
+type SaleConfig struct {
+	MaxItems int // jumlah item maksimal per sale
+}
+
+// ReportConfig - batasan query report
+type ReportConfig struct {
+	MaxRangeDays int // range tanggal maksimal sales & revenue report
+}
+
+// Validate dipanggil saat startup (main.go), error = config tidak valid
+func (c ReportConfig) Validate() error {
+	if c.MaxRangeDays < 1 || c.MaxRangeDays > 3660 {
+		return fmt.Errorf("invalid REPORT_MAX_RANGE_DAYS %d: must be between 1 and 3660", c.MaxRangeDays)
+	}
+	return nil
+}
+
+// SessionConfig - masa berlaku token login
+// Sliding: expiry diperpanjang saat sisa waktunya < SlideThreshold (tidak write DB tiap request)
+type SessionConfig struct {
+	TTL            time.Duration
+	SlidingEnabled bool
+	SlideThreshold time.Duration
+	ActiveAlert    int // threshold session aktif per user untuk monitoring (GET /api/admin/sessions/active-counts)
+}
+
+// Validate dipanggil saat startup (main.go), error = config tidak valid
+func (c SessionConfig) Validate() error {
+	if c.TTL <= 0 {
+		return fmt.Errorf("invalid SESSION_TTL_HOURS: must be greater than 0")
+	}
+	if c.SlidingEnabled && (c.SlideThreshold <= 0 || c.SlideThreshold >= c.TTL) {
+		return fmt.Errorf("invalid SESSION_SLIDE_THRESHOLD_MINUTES: must be greater than 0 and less than SESSION_TTL_HOURS")
+	}
+	if c.ActiveAlert < 0 {
+		return fmt.Errorf("invalid SESSION_ACTIVE_ALERT_THRESHOLD %d: must not be negative", c.ActiveAlert)
+	}
+	return nil
+}
+
+// VerboseLogConfig - log body request & status response untuk mutasi di route admin (debugging)
+// Field sensitif (password, token, secret) selalu di-redact
+type VerboseLogConfig struct {
+	Enabled      bool
+	MaxBodyBytes int // body lebih besar dari ini tidak di-log (hanya ukurannya)
+}
+
+// Validate dipanggil saat startup (main.go), error = config tidak valid
+func (c VerboseLogConfig) Validate() error {
+	if c.Enabled && c.MaxBodyBytes < 1 {
+		return fmt.Errorf("invalid LOG_VERBOSE_ADMIN_MAX_BODY_BYTES %d: must be greater than 0", c.MaxBodyBytes)
+	}
+	return nil
 }
 
 func ReadConfiguration() (Configuration, error) {
@@ -28,6 +150,58 @@ func ReadConfiguration() (Configuration, error) {
 	viper.SetConfigFile(".env")
 	viper.SetConfigType("env")
 
+	// default security headers (bisa dimatikan lewat env)
+	viper.SetDefault("SECURITY_NOSNIFF", true)
+	viper.SetDefault("SECURITY_FRAME_DENY", true)
+	viper.SetDefault("SECURITY_REFERRER_POLICY", "strict-origin-when-cross-origin")
+	viper.SetDefault("SECURITY_HSTS_ENABLED", false)
+	viper.SetDefault("SECURITY_HSTS_MAX_AGE", 31536000)
+
+	// default slow query threshold (ms)
+	viper.SetDefault("DATABASE_SLOW_QUERY_MS", 200)
+
+	// default interval health check DB (detik)
+	viper.SetDefault("DATABASE_HEALTH_CHECK_SECONDS", 15)
+
+	// default rate limit
+	viper.SetDefault("RATE_LIMIT_AUTHENTICATED_RPM", 120)
+	viper.SetDefault("RATE_LIMIT_PUBLIC_RPM", 10)
+
+	// default upload
+	viper.SetDefault("UPLOAD_DIR", "uploads")
+	viper.SetDefault("UPLOAD_BASE_URL", "/uploads")
+	viper.SetDefault("UPLOAD_MAX_SIZE_MB", 5)
+
+	// default mata uang & pembulatan
+	viper.SetDefault("MONEY_CURRENCY", "IDR")
+	viper.SetDefault("MONEY_DECIMAL_PLACES", 2)
+
+	// default nomor invoice (INV-YYYYMMDD-NNNN)
+	viper.SetDefault("INVOICE_PREFIX", "INV")
+	viper.SetDefault("INVOICE_DATE_FORMAT", "YYYYMMDD")
+	viper.SetDefault("INVOICE_SEQUENCE_DIGITS", 4)
+
+	// default batas item per sale
+	viper.SetDefault("SALE_MAX_ITEMS", 500)
+
+	// default batas min stock level
+	viper.SetDefault("INVENTORY_MAX_MIN_STOCK_LEVEL", 10000)
+	viper.SetDefault("INVENTORY_DEFAULT_MIN_STOCK_LEVEL", 5)
+	viper.SetDefault("INVENTORY_MAX_BATCH_IDS", 100)
+
+	// default range report (1 tahun)
+	viper.SetDefault("REPORT_MAX_RANGE_DAYS", 365)
+
+	// default session (24 jam, sliding nonaktif)
+	viper.SetDefault("SESSION_TTL_HOURS", 24)
+	viper.SetDefault("SESSION_SLIDING_ENABLED", false)
+	viper.SetDefault("SESSION_SLIDE_THRESHOLD_MINUTES", 60)
+	viper.SetDefault("SESSION_ACTIVE_ALERT_THRESHOLD", 5)
+
+	// default verbose log admin (nonaktif, max body 8KB)
+	viper.SetDefault("LOG_VERBOSE_ADMIN", false)
+	viper.SetDefault("LOG_VERBOSE_ADMIN_MAX_BODY_BYTES", 8192)
+
 	err := viper.ReadInConfig()
 	if err != nil {
 		return Configuration{}, err
@@ -41,12 +215,27 @@ func ReadConfiguration() (Configuration, error) {
 	pflag.Parse()
 	viper.BindPFlags(pflag.CommandLine)
 
+	// LOG_LEVEL / LOG_FORMAT kosong = ikut DEBUG (debug+console, atau info+json)
+	debug := viper.GetBool("DEBUG")
+	logLevel, logFormat := "info", "json"
+	if debug {
+		logLevel, logFormat = "debug", "console"
+	}
+	if v := viper.GetString("LOG_LEVEL"); v != "" {
+		logLevel = v
+	}
+	if v := viper.GetString("LOG_FORMAT"); v != "" {
+		logFormat = strings.ToLower(v)
+	}
+
 	return Configuration{
 		AppName:     viper.GetString("APP_NAME"),
 		Port:        viper.GetString("PORT"),
-		Debug:       viper.GetBool("DEBUG"),
+		Debug:       debug,
 		Limit:       viper.GetInt("LIMIT"),
 		PathLogging: viper.GetString("PATH_LOGGING"),
+		LogLevel:    logLevel,
+		LogFormat:   logFormat,
 		DB: DatabaseConfig{
 			Name:     viper.GetString("DATABASE_NAME"),
 			Username: viper.GetString("DATABASE_USERNAME"),
@@ -54,7 +243,69 @@ func ReadConfiguration() (Configuration, error) {
 			Host:     viper.GetString("DATABASE_HOST"),
 			Port:     viper.GetString("DATABASE_PORT"),
 			MaxConn:  viper.GetInt32("DATABASE_MAX_CONN"),
+
+			SlowQueryThreshold:  time.Duration(viper.GetInt("DATABASE_SLOW_QUERY_MS")) * time.Millisecond,
+			HealthCheckInterval: time.Duration(max(1, viper.GetInt("DATABASE_HEALTH_CHECK_SECONDS"))) * time.Second,
+		},
+		Security: SecurityConfig{
+			CORSAllowedOrigins: splitList(viper.GetString("CORS_ALLOWED_ORIGINS")),
+			NoSniff:            viper.GetBool("SECURITY_NOSNIFF"),
+			FrameDeny:          viper.GetBool("SECURITY_FRAME_DENY"),
+			ReferrerPolicy:     viper.GetString("SECURITY_REFERRER_POLICY"),
+			HSTSEnabled:        viper.GetBool("SECURITY_HSTS_ENABLED"),
+			HSTSMaxAge:         viper.GetInt("SECURITY_HSTS_MAX_AGE"),
+			TrustedProxies:     splitList(viper.GetString("TRUSTED_PROXIES")),
+		},
+		RateLimit: RateLimitConfig{
+			AuthenticatedRPM: viper.GetInt("RATE_LIMIT_AUTHENTICATED_RPM"),
+			PublicRPM:        viper.GetInt("RATE_LIMIT_PUBLIC_RPM"),
+		},
+		Upload: UploadConfig{
+			Dir:       viper.GetString("UPLOAD_DIR"),
+			BaseURL:   viper.GetString("UPLOAD_BASE_URL"),
+			MaxSizeMB: viper.GetInt("UPLOAD_MAX_SIZE_MB"),
+		},
+		Money: MoneyConfig{
+			Currency:      viper.GetString("MONEY_CURRENCY"),
+			DecimalPlaces: viper.GetInt("MONEY_DECIMAL_PLACES"),
+		},
+		Invoice: InvoiceConfig{
+			Prefix:         viper.GetString("INVOICE_PREFIX"),
+			DateFormat:     viper.GetString("INVOICE_DATE_FORMAT"),
+			SequenceDigits: viper.GetInt("INVOICE_SEQUENCE_DIGITS"),
+		},
+		Sale: SaleConfig{
+			MaxItems: viper.GetInt("SALE_MAX_ITEMS"),
+		},
+		Inventory: InventoryConfig{
+			MaxMinStockLevel:     viper.GetInt("INVENTORY_MAX_MIN_STOCK_LEVEL"),
+			DefaultMinStockLevel: viper.GetInt("INVENTORY_DEFAULT_MIN_STOCK_LEVEL"),
+			MaxBatchIDs:          viper.GetInt("INVENTORY_MAX_BATCH_IDS"),
+		},
+		Report: ReportConfig{
+			MaxRangeDays: viper.GetInt("REPORT_MAX_RANGE_DAYS"),
+		},
+		Session: SessionConfig{
+			TTL:            time.Duration(viper.GetInt("SESSION_TTL_HOURS")) * time.Hour,
+			SlidingEnabled: viper.GetBool("SESSION_SLIDING_ENABLED"),
+			SlideThreshold: time.Duration(viper.GetInt("SESSION_SLIDE_THRESHOLD_MINUTES")) * time.Minute,
+			ActiveAlert:    viper.GetInt("SESSION_ACTIVE_ALERT_THRESHOLD"),
+		},
+		VerboseLog: VerboseLogConfig{
+			Enabled:      viper.GetBool("LOG_VERBOSE_ADMIN"),
+			MaxBodyBytes: viper.GetInt("LOG_VERBOSE_ADMIN_MAX_BODY_BYTES"),
 		},
 	}, nil
 
 }
+
+// splitList parse "a, b,c" jadi []string{"a","b","c"}
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
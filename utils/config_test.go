@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportConfigValidate(t *testing.T) {
+	tests := []struct {
+		days    int
+		wantErr bool
+	}{
+		{days: -30, wantErr: true},
+		{days: 0, wantErr: true},
+		{days: 1},
+		{days: 366},
+		{days: 3660},
+		{days: 3661, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := ReportConfig{MaxRangeDays: tt.days}.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("MaxRangeDays %d: error = %v, wantErr %v", tt.days, err, tt.wantErr)
+		}
+		// Startup gagal dengan pesan yang menyebut env var-nya
+		if err != nil && !strings.Contains(err.Error(), "REPORT_MAX_RANGE_DAYS") {
+			t.Errorf("MaxRangeDays %d: error = %v, want mention of REPORT_MAX_RANGE_DAYS", tt.days, err)
+		}
+	}
+}
+
+func TestSessionConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     SessionConfig
+		wantErr string
+	}{
+		{name: "valid fixed", cfg: SessionConfig{TTL: 24 * time.Hour}},
+		{name: "valid sliding", cfg: SessionConfig{TTL: 24 * time.Hour, SlidingEnabled: true, SlideThreshold: time.Hour, ActiveAlert: 5}},
+		{name: "zero ttl", cfg: SessionConfig{}, wantErr: "SESSION_TTL_HOURS"},
+		{name: "threshold not below ttl", cfg: SessionConfig{TTL: time.Hour, SlidingEnabled: true, SlideThreshold: time.Hour}, wantErr: "SESSION_SLIDE_THRESHOLD_MINUTES"},
+		{name: "threshold ignored when sliding disabled", cfg: SessionConfig{TTL: time.Hour, SlideThreshold: 2 * time.Hour}},
+		{name: "negative active alert", cfg: SessionConfig{TTL: time.Hour, ActiveAlert: -1}, wantErr: "SESSION_ACTIVE_ALERT_THRESHOLD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerboseLogConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     VerboseLogConfig
+		wantErr bool
+	}{
+		{name: "disabled ignores size", cfg: VerboseLogConfig{}},
+		{name: "enabled with size", cfg: VerboseLogConfig{Enabled: true, MaxBodyBytes: 4096}},
+		{name: "enabled without size", cfg: VerboseLogConfig{Enabled: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSecurityConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []string
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "ip and cidr", proxies: []string{"10.0.0.1", "172.16.0.0/12", "::1"}},
+		{name: "hostname", proxies: []string{"proxy.internal"}, wantErr: true},
+		{name: "bad cidr", proxies: []string{"10.0.0.0/33"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := SecurityConfig{TrustedProxies: tt.proxies}.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{input: "", want: nil},
+		{input: "*", want: []string{"*"}},
+		{input: "https://a.test, https://b.test,,", want: []string{"https://a.test", "https://b.test"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitList(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitList(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// InvoiceConfig - format nomor invoice, contoh default "INV-20240115-0007"
+type InvoiceConfig struct {
+	Prefix         string // contoh "INV" atau "BR1"
+	DateFormat     string // token YYYY, YY, MM, DD, contoh "YYYYMMDD" atau "YYMMDD"
+	SequenceDigits int    // jumlah digit nomor urut di belakang
+}
+
+// Default sama dengan format lama (INV-YYYYMMDD-NNNN)
+var invoice = InvoiceConfig{Prefix: "INV", DateFormat: "YYYYMMDD", SequenceDigits: 4}
+
+// layout Go hasil konversi DateFormat
+var invoiceDateLayout = "20060102"
+
+// Prefix dipakai di URL (/api/sales/invoice/{invoice_number}), jadi tanpa "/" & spasi
+var invoicePrefixPattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,20}$`)
+
+// Panjang maksimal kolom sales.invoice_number
+const maxInvoiceLength = 50
+
+// SetInvoiceConfig dipanggil sekali saat startup (main.go), error = config tidak valid
+// Field kosong / 0 pakai default
+func SetInvoiceConfig(cfg InvoiceConfig) error {
+	if cfg.Prefix == "" {
+		cfg.Prefix = invoice.Prefix
+	}
+	if cfg.DateFormat == "" {
+		cfg.DateFormat = invoice.DateFormat
+	}
+	if cfg.SequenceDigits == 0 {
+		cfg.SequenceDigits = invoice.SequenceDigits
+	}
+
+	if !invoicePrefixPattern.MatchString(cfg.Prefix) {
+		return fmt.Errorf("invalid invoice prefix %q: use 1-20 letters, digits or underscore", cfg.Prefix)
+	}
+
+	layout, err := invoiceLayout(cfg.DateFormat)
+	if err != nil {
+		return err
+	}
+
+	if cfg.SequenceDigits < 1 || cfg.SequenceDigits > 9 {
+		return fmt.Errorf("invalid invoice sequence digits %d: must be between 1 and 9", cfg.SequenceDigits)
+	}
+
+	// prefix + "-" + tanggal + "-" + nomor urut harus muat di kolom
+	if length := len(cfg.Prefix) + len(layout) + cfg.SequenceDigits + 2; length > maxInvoiceLength {
+		return fmt.Errorf("invoice number too long (%d characters, max %d)", length, maxInvoiceLength)
+	}
+
+	invoice = cfg
+	invoiceDateLayout = layout
+	return nil
+}
+
+// FormatInvoiceNumber render nomor invoice: PREFIX-TANGGAL-NOMOR (nomor di-pad nol)
+// Nomor dipotong ke SequenceDigits digit terakhir
+func FormatInvoiceNumber(date time.Time, sequence int) string {
+	modulo := 1
+	for i := 0; i < invoice.SequenceDigits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%s-%s-%0*d", invoice.Prefix, date.Format(invoiceDateLayout), invoice.SequenceDigits, sequence%modulo)
+}
+
+// invoiceLayout ubah token YYYY/YY/MM/DD ke layout Go
+// Hanya token & pemisah "." / "_" yang diterima, "-" sudah dipakai pemisah bagian invoice
+func invoiceLayout(format string) (string, error) {
+	tokens := []string{"YYYY", "2006", "YY", "06", "MM", "01", "DD", "02"}
+
+	// Sisa setelah token dibuang hanya boleh pemisah
+	rest := format
+	for i := 0; i < len(tokens); i += 2 {
+		rest = strings.ReplaceAll(rest, tokens[i], "")
+	}
+	if strings.Trim(rest, "._") != "" {
+		return "", fmt.Errorf("invalid invoice date format %q: use YYYY, YY, MM, DD with optional . or _", format)
+	}
+
+	layout := strings.NewReplacer(tokens...).Replace(format)
+
+	// Tanpa tahun/bulan/hari nomor invoice cepat bentrok
+	if !strings.Contains(layout, "06") || !strings.Contains(layout, "01") || !strings.Contains(layout, "02") {
+		return "", fmt.Errorf("invalid invoice date format %q: must contain year, month and day", format)
+	}
+
+	return layout, nil
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetInvoiceConfig(t *testing.T) {
+	defer resetInvoiceConfig()
+
+	date := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		cfg      InvoiceConfig
+		sequence int
+		want     string
+		wantErr  string
+	}{
+		{name: "defaults", cfg: InvoiceConfig{}, sequence: 7, want: "INV-20240115-0007"},
+		{name: "custom prefix and short year", cfg: InvoiceConfig{Prefix: "BR1", DateFormat: "YYMMDD", SequenceDigits: 3}, sequence: 42, want: "BR1-240115-042"},
+		{name: "separator in date", cfg: InvoiceConfig{DateFormat: "YYYY.MM.DD"}, sequence: 1, want: "INV-2024.01.15-0001"},
+		{name: "sequence truncated to digits", cfg: InvoiceConfig{SequenceDigits: 2}, sequence: 1234, want: "INV-20240115-34"},
+		{name: "prefix with slash", cfg: InvoiceConfig{Prefix: "A/B"}, wantErr: "invalid invoice prefix"},
+		{name: "prefix too long", cfg: InvoiceConfig{Prefix: strings.Repeat("A", 21)}, wantErr: "invalid invoice prefix"},
+		{name: "dash in date format", cfg: InvoiceConfig{DateFormat: "YYYY-MM-DD"}, wantErr: "invalid invoice date format"},
+		{name: "date without day", cfg: InvoiceConfig{DateFormat: "YYYYMM"}, wantErr: "must contain year, month and day"},
+		{name: "too many digits", cfg: InvoiceConfig{SequenceDigits: 10}, wantErr: "invalid invoice sequence digits"},
+		{name: "negative digits", cfg: InvoiceConfig{SequenceDigits: -1}, wantErr: "invalid invoice sequence digits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetInvoiceConfig()
+
+			err := SetInvoiceConfig(tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				// Config lama tetap dipakai jika config baru tidak valid
+				if got := FormatInvoiceNumber(date, 7); got != "INV-20240115-0007" {
+					t.Errorf("invalid config applied: %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := FormatInvoiceNumber(date, tt.sequence); got != tt.want {
+				t.Errorf("FormatInvoiceNumber() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func resetInvoiceConfig() {
+	invoice = InvoiceConfig{Prefix: "INV", DateFormat: "YYYYMMDD", SequenceDigits: 4}
+	invoiceDateLayout = "20060102"
+}
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -11,19 +14,64 @@ import (
 
 var Logger *zap.Logger // Global logger variable
 
-func InitLogger(path string, debug bool) (*zap.Logger, error) {
+// logLevel dipakai semua core, bisa diubah saat runtime lewat SetLogLevel
+var logLevel = zap.NewAtomicLevelAt(zap.InfoLevel)
+
+// ParseLogLevel hanya terima debug/info/warn/error (case-insensitive)
+func ParseLogLevel(level string) (zapcore.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return zap.DebugLevel, nil
+	case "info":
+		return zap.InfoLevel, nil
+	case "warn", "warning":
+		return zap.WarnLevel, nil
+	case "error":
+		return zap.ErrorLevel, nil
+	}
+	return zap.InfoLevel, fmt.Errorf("invalid log level %q (debug, info, warn, error)", level)
+}
+
+// SetLogLevel ubah verbosity logger tanpa restart
+func SetLogLevel(level string) error {
+	parsed, err := ParseLogLevel(level)
+	if err != nil {
+		return err
+	}
+	logLevel.SetLevel(parsed)
+	return nil
+}
+
+// CurrentLogLevel level aktif saat ini
+func CurrentLogLevel() string {
+	return logLevel.Level().String()
+}
+
+// InitLogger format "json" atau "console"
+func InitLogger(path string, level string, format string) (*zap.Logger, error) {
+	parsedLevel, err := ParseLogLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	logLevel.SetLevel(parsedLevel)
+
 	// Encoder config
 	encoderConfig := zap.NewProductionEncoderConfig()
-	if debug {
+	if format == "console" {
 		encoderConfig = zap.NewDevelopmentEncoderConfig()
 	}
 	encoderConfig.TimeKey = "timestamp"
 	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 
 	// set format log
-	encoder := zapcore.NewJSONEncoder(encoderConfig)
-	if debug {
+	var encoder zapcore.Encoder
+	switch format {
+	case "json":
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case "console":
 		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("invalid log format %q (json, console)", format)
 	}
 
 	// File sink dengan rotasi log
@@ -40,8 +88,8 @@ func InitLogger(path string, debug bool) (*zap.Logger, error) {
 
 	// Gabungkan ke dalam satu core
 	core := zapcore.NewTee(
-		zapcore.NewCore(encoder, fileWriter, zap.InfoLevel),
-		zapcore.NewCore(encoder, consoleWriter, zap.InfoLevel),
+		zapcore.NewCore(encoder, fileWriter, logLevel),
+		zapcore.NewCore(encoder, consoleWriter, logLevel),
 	)
 
 	// Buat logger
@@ -53,3 +101,23 @@ func InitLogger(path string, debug bool) (*zap.Logger, error) {
 
 	return logger, nil
 }
+
+type loggerCtxKey struct{}
+
+// WithLogger simpan logger (biasanya child dengan request_id) ke context
+func WithLogger(ctx context.Context, log *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, log)
+}
+
+// LoggerFromContext ambil logger request-scoped, fallback ke global Logger
+func LoggerFromContext(ctx context.Context) *zap.Logger {
+	if ctx != nil {
+		if log, ok := ctx.Value(loggerCtxKey{}).(*zap.Logger); ok && log != nil {
+			return log
+		}
+	}
+	if Logger != nil {
+		return Logger
+	}
+	return zap.L()
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    zapcore.Level
+		wantErr bool
+	}{
+		{input: "debug", want: zap.DebugLevel},
+		{input: " INFO ", want: zap.InfoLevel},
+		{input: "warning", want: zap.WarnLevel},
+		{input: "warn", want: zap.WarnLevel},
+		{input: "Error", want: zap.ErrorLevel},
+		{input: "fatal", want: zap.InfoLevel, wantErr: true},
+		{input: "", want: zap.InfoLevel, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLogLevel(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLogLevel(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseLogLevel(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSetLogLevel(t *testing.T) {
+	defer logLevel.SetLevel(zap.InfoLevel)
+
+	if err := SetLogLevel("debug"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := CurrentLogLevel(); got != "debug" {
+		t.Errorf("CurrentLogLevel() = %q, want debug", got)
+	}
+
+	// Level tidak valid tidak mengubah level aktif
+	if err := SetLogLevel("verbose"); err == nil {
+		t.Fatal("expected error for invalid level")
+	}
+	if got := CurrentLogLevel(); got != "debug" {
+		t.Errorf("CurrentLogLevel() = %q after invalid level, want debug", got)
+	}
+}
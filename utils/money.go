@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// MoneyConfig - mata uang & pembulatan nominal
+type MoneyConfig struct {
+	Currency      string // kode ISO 4217, contoh "IDR"
+	DecimalPlaces int    // jumlah digit di belakang koma
+}
+
+// Default sama dengan skala kolom DECIMAL(15,2) di database
+var money = MoneyConfig{Currency: "IDR", DecimalPlaces: 2}
+
+// SetMoneyConfig dipanggil sekali saat startup (main.go)
+func SetMoneyConfig(cfg MoneyConfig) {
+	if cfg.Currency != "" {
+		money.Currency = cfg.Currency
+	}
+	if cfg.DecimalPlaces >= 0 {
+		money.DecimalPlaces = cfg.DecimalPlaces
+	}
+}
+
+// Currency kode mata uang untuk field "currency" di response
+func Currency() string {
+	return money.Currency
+}
+
+// DecimalPlaces jumlah digit di belakang koma untuk nominal
+func DecimalPlaces() int {
+	return money.DecimalPlaces
+}
+
+// RoundMoney bulatkan half-up (menjauhi nol) ke DecimalPlaces
+// Pakai representasi desimal terpendek supaya 1.005 jadi 1.01, bukan 1.00
+func RoundMoney(value float64) float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return value
+	}
+
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(value, 'f', -1, 64))
+	if !ok {
+		return value
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(money.DecimalPlaces)), nil)
+	r.Mul(r, new(big.Rat).SetInt(scale))
+
+	half := big.NewRat(1, 2)
+	if r.Sign() < 0 {
+		r.Sub(r, half)
+	} else {
+		r.Add(r, half)
+	}
+
+	// Quo truncate ke arah nol, setelah +/- 0.5 hasilnya half-up
+	rounded := new(big.Int).Quo(r.Num(), r.Denom())
+	result, _ := new(big.Rat).SetFrac(rounded, scale).Float64()
+	return result
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRoundMoney(t *testing.T) {
+	defer SetMoneyConfig(MoneyConfig{Currency: "IDR", DecimalPlaces: 2})
+
+	tests := []struct {
+		name     string
+		decimals int
+		value    float64
+		want     float64
+	}{
+		{name: "half up", decimals: 2, value: 1.005, want: 1.01},
+		{name: "round down", decimals: 2, value: 1.004, want: 1.00},
+		{name: "negative away from zero", decimals: 2, value: -1.005, want: -1.01},
+		{name: "already rounded", decimals: 2, value: 19.99, want: 19.99},
+		{name: "zero decimals", decimals: 0, value: 2.5, want: 3},
+		{name: "three decimals", decimals: 3, value: 0.0005, want: 0.001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetMoneyConfig(MoneyConfig{DecimalPlaces: tt.decimals})
+			if got := RoundMoney(tt.value); got != tt.want {
+				t.Errorf("RoundMoney(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundMoneyNonFinite(t *testing.T) {
+	if got := RoundMoney(math.Inf(1)); !math.IsInf(got, 1) {
+		t.Errorf("RoundMoney(+Inf) = %v", got)
+	}
+	if got := RoundMoney(math.NaN()); !math.IsNaN(got) {
+		t.Errorf("RoundMoney(NaN) = %v", got)
+	}
+}
+
+func TestSetMoneyConfig(t *testing.T) {
+	defer SetMoneyConfig(MoneyConfig{Currency: "IDR", DecimalPlaces: 2})
+
+	tests := []struct {
+		name         string
+		cfg          MoneyConfig
+		wantCurrency string
+		wantDecimals int
+	}{
+		{name: "custom", cfg: MoneyConfig{Currency: "USD", DecimalPlaces: 3}, wantCurrency: "USD", wantDecimals: 3},
+		{name: "empty currency keeps previous", cfg: MoneyConfig{DecimalPlaces: 0}, wantCurrency: "USD", wantDecimals: 0},
+		{name: "negative decimals ignored", cfg: MoneyConfig{Currency: "EUR", DecimalPlaces: -1}, wantCurrency: "EUR", wantDecimals: 0},
+	}
+
+	for _, tt := range tests {
+		SetMoneyConfig(tt.cfg)
+		if Currency() != tt.wantCurrency || DecimalPlaces() != tt.wantDecimals {
+			t.Errorf("%s: got %s/%d, want %s/%d", tt.name, Currency(), DecimalPlaces(), tt.wantCurrency, tt.wantDecimals)
+		}
+	}
+}
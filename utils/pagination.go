@@ -1,5 +1,11 @@
 package utils
 
+// Batas limit per halaman, dipakai juga oleh GET /api/config
+const (
+	DefaultPageLimit = 10
+	MaxPageLimit     = 100
+)
+
 type Pagination struct {
 	Page       int `json:"page" query:"page"`
 	Limit      int `json:"limit" query:"limit"`
@@ -12,10 +18,10 @@ func NewPagination(page, limit int) Pagination {
 		page = 1
 	}
 	if limit < 1 {
-		limit = 10 // default limit
+		limit = DefaultPageLimit
 	}
-	if limit > 100 {
-		limit = 100 // max limit
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
 	}
 
 	return Pagination{
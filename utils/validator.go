@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"net/url"
 	"reflect"
 	"regexp"
 	"strings"
@@ -11,6 +12,16 @@ import (
 
 var validate *validator.Validate
 
+// ValidationError error validasi per field (key = nama field struct)
+// Error() tetap "validation failed: map[...]" supaya pengecekan string lama tidak berubah
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation failed: %v", e.Fields)
+}
+
 // InitValidator inialisasi validator
 func InitValidator() {
 	validate = validator.New()
@@ -86,6 +97,23 @@ func registerCustomValidations() {
 		matched, _ := regexp.MatchString(pattern, strings.ToLower(uuidStr))
 		return matched
 	})
+
+	// 6. Image URL - URL http(s) absolut atau path relatif dari upload lokal (contoh "/uploads/x.png")
+	validate.RegisterValidation("image_url", func(fl validator.FieldLevel) bool {
+		return isImageURL(fl.Field().String())
+	})
+}
+
+func isImageURL(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil || strings.ContainsAny(value, " \t\n") {
+		return false
+	}
+	if u.IsAbs() {
+		return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+	}
+	// Path relatif harus dari root, "//host" (protocol relative) ditolak
+	return strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "//")
 }
 
 // formatValidationErrors konversi error validator ke format yang lebih readable
@@ -115,12 +143,14 @@ func formatValidationErrors(err error) error {
 				errors[field] = fmt.Sprintf("%s must be a valid UUID v4", field)
 			case "positive":
 				errors[field] = fmt.Sprintf("%s must be positive number", field)
+			case "image_url":
+				errors[field] = fmt.Sprintf("%s must be an http(s) URL or a path starting with /", field)
 			default:
 				errors[field] = fmt.Sprintf("%s failed %s validation", field, tag)
 			}
 		}
 
-		return fmt.Errorf("validation failed: %v", errors)
+		return &ValidationError{Fields: errors}
 	}
 
 	return err
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImageURLValidation(t *testing.T) {
+	type request struct {
+		ImageURL string `validate:"omitempty,image_url"`
+	}
+
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "", want: true},
+		{value: "https://cdn.example.com/p/1.png", want: true},
+		{value: "http://localhost:8080/uploads/1.png", want: true},
+		{value: "/uploads/product-1.png", want: true},
+		{value: "uploads/product-1.png", want: false},
+		{value: "//evil.example.com/1.png", want: false},
+		{value: "javascript:alert(1)", want: false},
+		{value: "ftp://example.com/1.png", want: false},
+		{value: "https:///no-host.png", want: false},
+		{value: "/uploads/with space.png", want: false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateStruct(request{ImageURL: tt.value})
+		if (err == nil) != tt.want {
+			t.Errorf("%q: error = %v, want valid %v", tt.value, err, tt.want)
+		}
+		if err != nil && !strings.Contains(err.Error(), "path starting with /") {
+			t.Errorf("%q: message = %v", tt.value, err)
+		}
+	}
+}